@@ -413,6 +413,34 @@ func validateKMSURIsAgainstFullBackup(
 	return defaultKMSInfo, nil
 }
 
+// resolveKMSInfoForDecryption returns the KMSInfo to be used to decrypt a
+// backup, which is that of the first of the provided KMS URIs whose master key
+// was used when encrypting the base BACKUP.
+//
+// Unlike validateKMSURIsAgainstFullBackup, KMS URIs which were not used during
+// the base BACKUP, or which can no longer be opened, are skipped. This allows
+// a RESTORE to be given both the retired and the new KMS URI while a master
+// key is being rotated, and to succeed against backups encrypted with either.
+func resolveKMSInfoForDecryption(
+	kmsURIs []string, kmsMasterKeyIDToDataKey *encryptedDataKeyMap, kmsEnv cloud.KMSEnv,
+) (*jobspb.BackupEncryptionOptions_KMSInfo, error) {
+	var combinedErr error
+	for _, kmsURI := range kmsURIs {
+		kmsInfo, err := validateKMSURIsAgainstFullBackup(
+			[]string{kmsURI}, kmsMasterKeyIDToDataKey, kmsEnv)
+		if err == nil {
+			return kmsInfo, nil
+		}
+		redactedURI, redactErr := cloudimpl.RedactKMSURI(kmsURI)
+		if redactErr != nil {
+			return nil, redactErr
+		}
+		combinedErr = errors.CombineErrors(combinedErr, errors.Wrapf(err, "KMS URI %s", redactedURI))
+	}
+	return nil, errors.Wrap(combinedErr,
+		"none of the provided KMS URIs can be used to decrypt the BACKUP")
+}
+
 // annotatedBackupStatement is a tree.Backup, optionally
 // annotated with the scheduling information.
 type annotatedBackupStatement struct {
@@ -500,6 +528,37 @@ func checkPrivilegesForBackup(
 	return nil
 }
 
+// checkPrivilegesForKMSURIs checks that none of the KMS URIs used to encrypt or
+// decrypt a backup rely on implicit access, unless the user has the admin role.
+func checkPrivilegesForKMSURIs(
+	ctx context.Context, p sql.PlanHookState, kmsURIs []string, op string,
+) error {
+	knobs := p.ExecCfg().BackupRestoreTestingKnobs
+	if knobs != nil && knobs.AllowImplicitAccess {
+		return nil
+	}
+	hasAdmin, err := p.HasAdminRole(ctx)
+	if err != nil {
+		return err
+	}
+	if hasAdmin {
+		return nil
+	}
+	for _, uri := range kmsURIs {
+		hasExplicitAuth, uriScheme, err := cloudimpl.KMSAccessIsWithExplicitAuth(uri)
+		if err != nil {
+			return err
+		}
+		if !hasExplicitAuth {
+			return pgerror.Newf(
+				pgcode.InsufficientPrivilege,
+				"only users with the admin role are allowed to %s with the specified %s KMS URI",
+				op, uriScheme)
+		}
+	}
+	return nil
+}
+
 // backupPlanHook implements PlanHookFn.
 func backupPlanHook(
 	ctx context.Context, stmt tree.Statement, p sql.PlanHookState,
@@ -680,6 +739,9 @@ func backupPlanHook(
 			if err != nil {
 				return err
 			}
+			if err := checkPrivilegesForKMSURIs(ctx, p, encryptionParams.kmsURIs, "BACKUP"); err != nil {
+				return err
+			}
 			if err := requireEnterprise("encryption"); err != nil {
 				return err
 			}
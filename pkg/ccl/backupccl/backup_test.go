@@ -4291,6 +4291,51 @@ func TestRegionalKMSEncryptedBackup(t *testing.T) {
 	})
 }
 
+// TestFileKMSEncryptedBackupKeyRotation performs encrypted BACKUPs using a
+// retired and a new local keyfile KMS, and checks that a RESTORE given both KMS
+// URIs succeeds against the backups encrypted with either of them.
+func TestFileKMSEncryptedBackupKeyRotation(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	const numAccounts = 10
+	_, _, sqlDB, _, cleanupFn := BackupRestoreTestSetup(t, singleNode, numAccounts, InitNone)
+	defer cleanupFn()
+
+	keyDir, cleanupKeyDir := testutils.TempDir(t)
+	defer cleanupKeyDir()
+	writeKey := func(name string, b byte) (string, string) {
+		path := filepath.Join(keyDir, name)
+		require.NoError(t, ioutil.WriteFile(path, bytes.Repeat([]byte{b}, 32), 0600))
+		return path, "file://" + path
+	}
+	oldKeyPath, oldURI := writeKey("old", 1)
+	_, newURI := writeKey("new", 2)
+
+	oldBackup, newBackup := LocalFoo+"/old", LocalFoo+"/new"
+	sqlDB.Exec(t, `BACKUP DATABASE data TO $1 WITH kms=$2`, oldBackup, oldURI)
+	sqlDB.Exec(t, `BACKUP DATABASE data TO $1 WITH kms=$2`, newBackup, newURI)
+	before := sqlDB.QueryStr(t, `SHOW EXPERIMENTAL_FINGERPRINTS FROM TABLE data.bank`)
+
+	for _, backup := range []string{oldBackup, newBackup} {
+		sqlDB.Exec(t, `DROP DATABASE data CASCADE`)
+		sqlDB.Exec(t, `RESTORE DATABASE data FROM $1 WITH kms=($2, $3)`, backup, oldURI, newURI)
+		sqlDB.CheckQueryResults(t, `SHOW EXPERIMENTAL_FINGERPRINTS FROM TABLE data.bank`, before)
+	}
+
+	sqlDB.ExpectErr(t, `none of the provided KMS URIs can be used to decrypt the BACKUP`,
+		`RESTORE DATABASE data FROM $1 WITH kms=$2`, oldBackup, newURI)
+
+	// Once the retired key is gone, backups encrypted with the new key can still
+	// be restored without changing the RESTORE statement.
+	require.NoError(t, os.Remove(oldKeyPath))
+	sqlDB.Exec(t, `DROP DATABASE data CASCADE`)
+	sqlDB.Exec(t, `RESTORE DATABASE data FROM $1 WITH kms=($2, $3)`, newBackup, oldURI, newURI)
+	sqlDB.CheckQueryResults(t, `SHOW EXPERIMENTAL_FINGERPRINTS FROM TABLE data.bank`, before)
+	sqlDB.ExpectErr(t, `reading file kms key`,
+		`RESTORE DATABASE data FROM $1 WITH kms=$2`, oldBackup, oldURI)
+}
+
 type testKMSEnv struct {
 	settings         *cluster.Settings
 	externalIOConfig *base.ExternalIODirConfig
@@ -4559,6 +4604,127 @@ func TestRestoreInto(t *testing.T) {
 	sqlDB.CheckQueryResults(t, `SELECT * FROM "data 2".bank`, expected)
 }
 
+func TestRestoreTableWithNewName(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	const numAccounts = 10
+	_, tc, sqlDB, _, cleanupFn := BackupRestoreTestSetup(t, singleNode, numAccounts, InitNone)
+	defer cleanupFn()
+
+	t.Run("same-db", func(t *testing.T) {
+		const dest = LocalFoo + "/same-db"
+		sqlDB.Exec(t, `BACKUP DATABASE data TO $1`, dest)
+		expected := sqlDB.QueryStr(t, `SELECT * FROM data.bank ORDER BY id`)
+		sqlDB.Exec(t, `UPDATE data.bank SET balance = balance + 1`)
+
+		sqlDB.Exec(t, `RESTORE TABLE data.bank AS data.bank_restored FROM $1`, dest)
+		sqlDB.CheckQueryResults(t, `SELECT * FROM data.bank_restored ORDER BY id`, expected)
+		sqlDB.Exec(t, `RESTORE TABLE data.bank AS bank_restored_2 FROM $1`, dest)
+		sqlDB.CheckQueryResults(t, `SELECT * FROM data.bank_restored_2 ORDER BY id`, expected)
+		sqlDB.Exec(t, `RESTORE TABLE data.bank AS data.public.bank_restored_3 FROM $1`, dest)
+		sqlDB.CheckQueryResults(t, `SELECT * FROM data.bank_restored_3 ORDER BY id`, expected)
+
+		sqlDB.ExpectErr(t, `relation "bank" already exists`,
+			`RESTORE TABLE data.bank AS data.bank FROM $1`, dest)
+		sqlDB.ExpectErr(t, `cannot use "into_db" option when restoring a table under a new name`,
+			`RESTORE TABLE data.bank AS data.bank_restored_4 FROM $1 WITH into_db = 'data'`, dest)
+		sqlDB.ExpectErr(t, `cannot restore table "bank" into schema "sc"`,
+			`RESTORE TABLE data.bank AS data.sc.bank_restored_4 FROM $1`, dest)
+	})
+
+	t.Run("other-db", func(t *testing.T) {
+		const dest = LocalFoo + "/other-db"
+		sqlDB.Exec(t, `BACKUP DATABASE data TO $1`, dest)
+		sqlDB.Exec(t, `CREATE DATABASE other`)
+		sqlDB.Exec(t, `RESTORE TABLE data.bank AS other.bank_restored FROM $1`, dest)
+		sqlDB.CheckQueryResults(t, `SELECT * FROM other.bank_restored ORDER BY id`,
+			sqlDB.QueryStr(t, `SELECT * FROM data.bank ORDER BY id`))
+	})
+
+	t.Run("references", func(t *testing.T) {
+		const dest = LocalFoo + "/references"
+		sqlDB.Exec(t, `CREATE DATABASE store`)
+		sqlDB.Exec(t, `CREATE SEQUENCE store.order_ids`)
+		sqlDB.Exec(t, `CREATE TYPE store.status AS ENUM ('open', 'closed')`)
+		sqlDB.Exec(t, `CREATE TABLE store.customers (id INT PRIMARY KEY)`)
+		sqlDB.Exec(t, `CREATE TABLE store.orders (
+			id INT PRIMARY KEY DEFAULT nextval('store.order_ids'),
+			customer INT REFERENCES store.customers,
+			status store.status
+		)`)
+		sqlDB.Exec(t, `INSERT INTO store.customers VALUES (1), (2)`)
+		sqlDB.Exec(t, `INSERT INTO store.orders (customer, status) VALUES (1, 'open'), (2, 'closed')`)
+		sqlDB.Exec(t, `BACKUP DATABASE store TO $1`, dest)
+		sqlDB.Exec(t, `DELETE FROM store.orders WHERE customer = 2`)
+
+		sqlDB.Exec(t, `RESTORE TABLE store.orders AS store.orders_restored FROM $1`, dest)
+		sqlDB.CheckQueryResults(t, `SELECT * FROM store.orders_restored ORDER BY id`,
+			[][]string{{"1", "1", "open"}, {"2", "2", "closed"}})
+
+		// The restored table uses the existing sequence, type and table.
+		sqlDB.Exec(t, `INSERT INTO store.orders_restored (customer, status) VALUES (2, 'open')`)
+		sqlDB.CheckQueryResults(t, `SELECT id FROM store.orders_restored WHERE id > 2`,
+			[][]string{{"3"}})
+		sqlDB.CheckQueryResults(t, `SELECT nextval('store.order_ids')`, [][]string{{"4"}})
+		sqlDB.ExpectErr(t, "violates foreign key constraint",
+			`INSERT INTO store.orders_restored (customer, status) VALUES (3, 'open')`)
+		sqlDB.CheckQueryResults(t, `SELECT validated FROM [SHOW CONSTRAINTS FROM store.orders_restored]
+			WHERE constraint_type = 'FOREIGN KEY'`, [][]string{{"false"}})
+		sqlDB.ExpectErr(t, "violates foreign key constraint",
+			`DELETE FROM store.customers WHERE id = 1`)
+		sqlDB.ExpectErr(t, "cannot drop sequence order_ids because other objects depend on it",
+			`DROP SEQUENCE store.order_ids`)
+		sqlDB.ExpectErr(t, "cannot drop type",
+			`DROP TYPE store.status`)
+
+		// The back references are removed when the restored table is dropped.
+		sqlDB.Exec(t, `DROP TABLE store.orders_restored`)
+		sqlDB.Exec(t, `DROP TABLE store.orders`)
+		sqlDB.Exec(t, `DROP SEQUENCE store.order_ids`)
+		sqlDB.Exec(t, `DELETE FROM store.customers WHERE id = 1`)
+
+		// Without the referenced table and sequence, the table can only be
+		// restored with the options that skip them.
+		sqlDB.ExpectErr(t, "without referenced sequence",
+			`RESTORE TABLE store.orders AS store.orders_restored FROM $1`, dest)
+		sqlDB.Exec(t, `RESTORE TABLE store.orders AS store.orders_restored FROM $1
+			WITH skip_missing_sequences`, dest)
+		sqlDB.Exec(t, `DROP TABLE store.orders_restored`)
+		sqlDB.Exec(t, `DROP TABLE store.customers`)
+		sqlDB.ExpectErr(t, "without referenced table",
+			`RESTORE TABLE store.orders AS store.orders_restored FROM $1
+				WITH skip_missing_sequences`, dest)
+	})
+
+	t.Run("rollback", func(t *testing.T) {
+		const dest = LocalFoo + "/rollback"
+		sqlDB.Exec(t, `CREATE DATABASE rollback`)
+		sqlDB.Exec(t, `CREATE SEQUENCE rollback.ids`)
+		sqlDB.Exec(t, `CREATE TABLE rollback.t (id INT PRIMARY KEY DEFAULT nextval('rollback.ids'))`)
+		sqlDB.Exec(t, `BACKUP DATABASE rollback TO $1`, dest)
+
+		registry := tc.Server(0).JobRegistry().(*jobs.Registry)
+		registry.TestingResumerCreationKnobs = map[jobspb.Type]func(raw jobs.Resumer) jobs.Resumer{
+			jobspb.TypeRestore: func(raw jobs.Resumer) jobs.Resumer {
+				r := raw.(*restoreResumer)
+				r.testingKnobs.afterOfflineTableCreation = func() error {
+					return errors.New("injected error")
+				}
+				return r
+			},
+		}
+		defer func() { registry.TestingResumerCreationKnobs = nil }()
+		sqlDB.ExpectErr(t, "injected error",
+			`RESTORE TABLE rollback.t AS rollback.t_restored FROM $1`, dest)
+
+		// The back reference from the existing sequence to the restored table was
+		// removed along with it.
+		sqlDB.Exec(t, `DROP TABLE rollback.t`)
+		sqlDB.Exec(t, `DROP SEQUENCE rollback.ids`)
+	})
+}
+
 func TestRestoreDatabaseVersusTable(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 	defer log.Scope(t).Close(t)
@@ -6699,3 +6865,70 @@ func flipBitInManifests(t *testing.T, rawDir string) {
 		t.Fatal("found no manifest")
 	}
 }
+
+// TestRestoreFunctionDependencies checks that a table which functions depend
+// on can be restored without them, since functions are not restored.
+func TestRestoreFunctionDependencies(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	const numAccounts = 0
+	_, _, sqlDB, _, cleanupFn := BackupRestoreTestSetup(t, singleNode, numAccounts, InitNone)
+	defer cleanupFn()
+
+	sqlDB.Exec(t, `CREATE DATABASE d`)
+	sqlDB.Exec(t, `CREATE TABLE d.t (a INT)`)
+	sqlDB.Exec(t, `CREATE FUNCTION d.public.count_t() RETURNS INT LANGUAGE SQL
+		AS 'SELECT count(*)::INT FROM d.t'`)
+	sqlDB.Exec(t, `INSERT INTO d.t VALUES (1)`)
+	sqlDB.Exec(t, `BACKUP DATABASE d TO $1`, LocalFoo)
+	sqlDB.Exec(t, `DROP DATABASE d CASCADE`)
+
+	sqlDB.Exec(t, `RESTORE DATABASE d FROM $1`, LocalFoo)
+	sqlDB.CheckQueryResults(t, `SELECT * FROM d.t`, [][]string{{"1"}})
+	sqlDB.Exec(t, `DROP TABLE d.t`)
+}
+
+// TestRestoreTriggers checks that a table with triggers can only be restored
+// with the skip_triggers option, since the functions which the triggers call
+// are not restored, and that a notice lists the triggers which were removed.
+func TestRestoreTriggers(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	const numAccounts = 0
+	ctx, tc, sqlDB, _, cleanupFn := BackupRestoreTestSetup(t, singleNode, numAccounts, InitNone)
+	defer cleanupFn()
+
+	sqlDB.Exec(t, `CREATE DATABASE d`)
+	sqlDB.Exec(t, `CREATE TABLE d.t (a INT)`)
+	sqlDB.Exec(t, `CREATE TABLE d.log (a INT)`)
+	sqlDB.Exec(t, `CREATE FUNCTION d.public.log_a(a INT) RETURNS INT LANGUAGE SQL
+		AS 'INSERT INTO d.log VALUES ($1) RETURNING a'`)
+	sqlDB.Exec(t, `CREATE TRIGGER tr AFTER INSERT ON d.t
+		FOR EACH ROW EXECUTE FUNCTION d.public.log_a(new.a)`)
+	sqlDB.Exec(t, `BACKUP DATABASE d TO $1`, LocalFoo)
+	sqlDB.Exec(t, `DROP DATABASE d CASCADE`)
+
+	sqlDB.ExpectErr(t, `cannot restore table "t" because it has triggers \(or "skip_triggers" option\)`,
+		`RESTORE DATABASE d FROM $1`, LocalFoo)
+
+	pgURL, cleanup := sqlutils.PGUrl(t, tc.Server(0).ServingSQLAddr(),
+		"TestRestoreTriggers", url.User(security.RootUser))
+	defer cleanup()
+	connCfg, err := pgx.ParseConnectionString(pgURL.String())
+	require.NoError(t, err)
+	var notices []string
+	connCfg.OnNotice = func(_ *pgx.Conn, n *pgx.Notice) {
+		notices = append(notices, n.Message)
+	}
+	conn, err := pgx.Connect(connCfg)
+	require.NoError(t, err)
+	defer func() { _ = conn.Close() }()
+	_, err = conn.ExecEx(ctx, `RESTORE DATABASE d FROM $1 WITH skip_triggers`, nil /* options */, LocalFoo)
+	require.NoError(t, err)
+	require.Equal(t, []string{`trigger "tr" on table "t" is not restored`}, notices)
+
+	sqlDB.Exec(t, `INSERT INTO d.t VALUES (1)`)
+	sqlDB.CheckQueryResults(t, `SELECT count(*) FROM d.log`, [][]string{{"0"}})
+}
@@ -0,0 +1,433 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package backupccl
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/build"
+	"github.com/cockroachdb/cockroach/pkg/jobs"
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/kv"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/server/telemetry"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/covering"
+	"github.com/cockroachdb/cockroach/pkg/sql/execinfrapb"
+	"github.com/cockroachdb/cockroach/pkg/sql/physicalplan"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/storage/cloud"
+	"github.com/cockroachdb/cockroach/pkg/util/ctxgroup"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/tracing"
+	"github.com/cockroachdb/cockroach/pkg/util/uuid"
+	"github.com/cockroachdb/errors"
+	"github.com/cockroachdb/logtags"
+	gogotypes "github.com/gogo/protobuf/types"
+)
+
+type compactBackupResumer struct {
+	job *jobs.Job
+}
+
+var _ jobs.Resumer = &compactBackupResumer{}
+
+// Resume is part of the jobs.Resumer interface.
+func (r *compactBackupResumer) Resume(
+	ctx context.Context, execCtx interface{}, resultsCh chan<- tree.Datums,
+) error {
+	details := r.job.Details().(jobspb.CompactBackupDetails)
+	p := execCtx.(sql.JobExecContext)
+
+	backupManifests, err := loadBackupManifests(ctx, details.URIs,
+		p.User(), p.ExecCfg().DistSQLSrv.ExternalStorageFromURI, details.Encryption)
+	if err != nil {
+		return err
+	}
+
+	destStore, err := p.ExecCfg().DistSQLSrv.ExternalStorageFromURI(ctx, details.Destination, p.User())
+	if err != nil {
+		return errors.Wrapf(err, "export configuration")
+	}
+	defer destStore.Close()
+
+	if details.EncryptionInfo != nil {
+		if err := writeEncryptionInfoIfNotExists(ctx, details.EncryptionInfo, destStore); err != nil {
+			return err
+		}
+	}
+	if err := createCheckpointIfNotExists(ctx, p.ExecCfg().Settings, destStore,
+		details.Encryption); err != nil {
+		return err
+	}
+
+	compacted, err := makeCompactedBackupManifest(p, backupManifests)
+	if err != nil {
+		return err
+	}
+	entries, err := makeCompactBackupImportSpans(backupManifests, details.BackupLocalityInfo,
+		compacted.Spans, compacted.MVCCFilter, p.User())
+	if err != nil {
+		return err
+	}
+
+	pkIDs := make(map[uint64]bool)
+	for _, b := range backupManifests {
+		for i := range b.Descriptors {
+			if t := descpb.TableFromDescriptor(&b.Descriptors[i], hlc.Timestamp{}); t != nil {
+				pkIDs[roachpb.BulkOpSummaryID(uint64(t.ID), uint64(t.PrimaryIndex.ID))] = true
+			}
+		}
+	}
+
+	files, err := compactBackupFiles(ctx, p, r.job, entries, pkIDs, details.Destination,
+		details.Encryption, roachpb.MVCCFilter(compacted.MVCCFilter))
+	if err != nil {
+		return err
+	}
+	compacted.Files = files
+	for _, f := range files {
+		compacted.EntryCounts.add(f.EntryCounts)
+	}
+
+	if err := writeBackupManifest(ctx, p.ExecCfg().Settings, destStore, backupManifestName,
+		details.Encryption, &compacted); err != nil {
+		return err
+	}
+
+	// The statistics of the most recent backup in the chain are the most recent
+	// ones for all of the tables of the compacted backup.
+	if err := func() error {
+		last := backupManifests[len(backupManifests)-1]
+		lastStore, err := p.ExecCfg().DistSQLSrv.ExternalStorage(ctx, last.Dir)
+		if err != nil {
+			return err
+		}
+		defer lastStore.Close()
+		tableStatistics, err := getStatisticsFromBackup(ctx, lastStore, details.Encryption, last)
+		if err != nil {
+			return err
+		}
+		return writeTableStatistics(ctx, destStore, backupStatisticsFileName, details.Encryption,
+			&StatsTable{Statistics: tableStatistics})
+	}(); err != nil {
+		return errors.Wrap(err, "copying table statistics")
+	}
+
+	r.deleteCheckpoint(ctx, p.ExecCfg(), p.User())
+
+	resultsCh <- tree.Datums{
+		tree.NewDInt(tree.DInt(*r.job.ID())),
+		tree.NewDString(string(jobs.StatusSucceeded)),
+		tree.NewDFloat(tree.DFloat(1.0)),
+		tree.NewDInt(tree.DInt(compacted.EntryCounts.Rows)),
+		tree.NewDInt(tree.DInt(compacted.EntryCounts.IndexEntries)),
+		tree.NewDInt(tree.DInt(compacted.EntryCounts.DataSize)),
+	}
+	telemetry.Count("compact-backup.total.succeeded")
+	return nil
+}
+
+// OnFailOrCancel is part of the jobs.Resumer interface. It removes the
+// checkpoint from the destination, so that it can be used again.
+func (r *compactBackupResumer) OnFailOrCancel(ctx context.Context, execCtx interface{}) error {
+	telemetry.Count("compact-backup.total.failed")
+	p := execCtx.(sql.JobExecContext)
+	r.deleteCheckpoint(ctx, p.ExecCfg(), p.User())
+	return nil
+}
+
+func (r *compactBackupResumer) deleteCheckpoint(
+	ctx context.Context, cfg *sql.ExecutorConfig, user security.SQLUsername,
+) {
+	if err := func() error {
+		details := r.job.Details().(jobspb.CompactBackupDetails)
+		exportStore, err := cfg.DistSQLSrv.ExternalStorageFromURI(ctx, details.Destination, user)
+		if err != nil {
+			return err
+		}
+		defer exportStore.Close()
+		return exportStore.Delete(ctx, backupManifestCheckpointName)
+	}(); err != nil {
+		log.Warningf(ctx, "unable to delete checkpointed backup descriptor: %+v", err)
+	}
+}
+
+// makeCompactedBackupManifest returns the manifest of the full backup that
+// results from compacting the given chain of backups, without its files. The
+// compacted backup covers the same time as the whole chain, and includes the
+// revision history of the chain only if every backup in it does.
+func makeCompactedBackupManifest(
+	p sql.JobExecContext, backupManifests []BackupManifest,
+) (BackupManifest, error) {
+	first, last := backupManifests[0], backupManifests[len(backupManifests)-1]
+
+	mvccFilter := MVCCFilter_All
+	for i := range backupManifests {
+		if backupManifests[i].MVCCFilter != MVCCFilter_All {
+			mvccFilter = MVCCFilter_Latest
+			break
+		}
+	}
+
+	nodeID, err := p.ExecCfg().NodeID.OptionalNodeIDErr(47970)
+	if err != nil {
+		return BackupManifest{}, err
+	}
+
+	statsFiles := make(map[descpb.ID]string)
+	for i := range last.Descriptors {
+		if t := descpb.TableFromDescriptor(&last.Descriptors[i], hlc.Timestamp{}); t != nil {
+			statsFiles[t.ID] = backupStatisticsFileName
+		}
+	}
+
+	compacted := BackupManifest{
+		StartTime:           first.StartTime,
+		EndTime:             last.EndTime,
+		MVCCFilter:          mvccFilter,
+		Descriptors:         last.Descriptors,
+		Tenants:             last.Tenants,
+		CompleteDbs:         last.CompleteDbs,
+		Spans:               last.Spans,
+		FormatVersion:       BackupFormatDescriptorTrackingVersion,
+		BuildInfo:           build.GetInfo(),
+		NodeID:              nodeID,
+		ClusterID:           last.ClusterID,
+		StatisticsFilenames: statsFiles,
+		DescriptorCoverage:  last.DescriptorCoverage,
+		ID:                  uuid.MakeV4(),
+	}
+	if mvccFilter == MVCCFilter_All {
+		// Restoring to a time in the middle of the chain may need the data of
+		// spans that are no longer backed up by its end, e.g. of dropped tables,
+		// so the compacted backup keeps the data of every span in the chain.
+		var spans roachpb.Spans
+		for i := range backupManifests {
+			spans = append(spans, backupManifests[i].Spans...)
+			compacted.DescriptorChanges = append(compacted.DescriptorChanges,
+				backupManifests[i].DescriptorChanges...)
+		}
+		compacted.Spans, _ = roachpb.MergeSpans(spans)
+		compacted.RevisionStartTime = first.RevisionStartTime
+	}
+	return compacted, nil
+}
+
+// makeCompactBackupImportSpans groups the files of the given chain of backups
+// by the spans they cover, the same way a RESTORE of the chain would.
+func makeCompactBackupImportSpans(
+	backupManifests []BackupManifest,
+	backupLocalityInfo []jobspb.RestoreDetails_BackupLocalityInfo,
+	spans roachpb.Spans,
+	mvccFilter MVCCFilter,
+	user security.SQLUsername,
+) ([]execinfrapb.RestoreSpanEntry, error) {
+	onMissing := errOnMissingRange
+	if mvccFilter == MVCCFilter_All {
+		// Spans that are no longer backed up by the end of the chain are only
+		// covered by the backups that include them.
+		lastSpans := backupManifests[len(backupManifests)-1].Spans
+		onMissing = func(span covering.Range, start, end hlc.Timestamp) error {
+			missing := roachpb.Span{Key: span.Start, EndKey: span.End}
+			for _, s := range lastSpans {
+				if s.Overlaps(missing) {
+					return errOnMissingRange(span, start, end)
+				}
+			}
+			return nil
+		}
+	}
+	entries, _, err := makeImportSpans(spans, backupManifests, backupLocalityInfo,
+		nil /* lowWaterMark */, user, onMissing)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid backup chain")
+	}
+	return entries, nil
+}
+
+// compactBackupFiles runs a distributed flow that merges the files of the given
+// import spans into new files in the destination, and returns the new files,
+// updating the progress of the job as spans are compacted.
+func compactBackupFiles(
+	ctx context.Context,
+	execCtx sql.JobExecContext,
+	job *jobs.Job,
+	entries []execinfrapb.RestoreSpanEntry,
+	pkIDs map[uint64]bool,
+	destination string,
+	encryption *jobspb.BackupEncryptionOptions,
+	mvccFilter roachpb.MVCCFilter,
+) ([]BackupManifest_File, error) {
+	if len(entries) == 0 {
+		return nil, nil
+	}
+
+	g := ctxgroup.WithContext(ctx)
+	spanFinishedCh := make(chan struct{}, len(entries)) // enough buffer to never block
+	progressLogger := jobs.NewChunkProgressLogger(job, len(entries), job.FractionCompleted(), nil)
+	g.GoCtx(func(ctx context.Context) error {
+		ctx, progressSpan := tracing.ChildSpan(ctx, "progress-log")
+		defer progressSpan.Finish()
+		return progressLogger.Loop(ctx, spanFinishedCh)
+	})
+
+	var files []BackupManifest_File
+	metaFn := func(_ context.Context, meta *execinfrapb.ProducerMetadata) error {
+		if meta.BulkProcessorProgress == nil {
+			return nil
+		}
+		var progDetails BackupManifest_Progress
+		if err := gogotypes.UnmarshalAny(&meta.BulkProcessorProgress.ProgressDetails, &progDetails); err != nil {
+			return err
+		}
+		files = append(files, progDetails.Files...)
+		spanFinishedCh <- struct{}{}
+		return nil
+	}
+
+	g.GoCtx(func(ctx context.Context) error {
+		defer close(spanFinishedCh)
+		return distCompactBackup(ctx, execCtx, entries, pkIDs, destination, encryption,
+			mvccFilter, metaFn)
+	})
+
+	if err := g.Wait(); err != nil {
+		return nil, errors.Wrapf(err, "compacting %d spans", len(entries))
+	}
+	return files, nil
+}
+
+// distCompactBackup plans a one stage distSQL flow for a COMPACT BACKUP, which
+// spreads the spans to compact across all the compatible nodes in the cluster.
+// metaFn is called with the metadata emitted for every compacted span.
+func distCompactBackup(
+	ctx context.Context,
+	execCtx sql.JobExecContext,
+	entries []execinfrapb.RestoreSpanEntry,
+	pkIDs map[uint64]bool,
+	destination string,
+	encryption *jobspb.BackupEncryptionOptions,
+	mvccFilter roachpb.MVCCFilter,
+	metaFn func(context.Context, *execinfrapb.ProducerMetadata) error,
+) error {
+	ctx = logtags.AddTag(ctx, "compact-backup-distsql", nil)
+	var noTxn *kv.Txn
+
+	dsp := execCtx.DistSQLPlanner()
+	evalCtx := execCtx.ExtendedEvalContext()
+
+	if encryption != nil && encryption.Mode == jobspb.EncryptionMode_KMS {
+		kms, err := cloud.KMSFromURI(encryption.KMSInfo.Uri, &backupKMSEnv{
+			settings: execCtx.ExecCfg().Settings,
+			conf:     &execCtx.ExecCfg().ExternalIODirConfig,
+		})
+		if err != nil {
+			return err
+		}
+
+		encryption.Key, err = kms.Decrypt(ctx, encryption.KMSInfo.EncryptedDataKey)
+		if err != nil {
+			return errors.Wrap(err,
+				"failed to decrypt data key before starting CompactBackupDataProcessor")
+		}
+	}
+	var fileEncryption *roachpb.FileEncryptionOptions
+	if encryption != nil {
+		fileEncryption = &roachpb.FileEncryptionOptions{Key: encryption.Key}
+	}
+
+	planCtx, _, err := dsp.SetupAllNodesPlanning(ctx, evalCtx, execCtx.ExecCfg())
+	if err != nil {
+		return err
+	}
+	nodes := getAllCompatibleNodes(planCtx)
+
+	specs := makeCompactBackupDataSpecs(nodes, entries, pkIDs, destination, fileEncryption,
+		mvccFilter, execCtx.User())
+
+	p := planCtx.NewPhysicalPlan()
+
+	// Setup a one-stage plan with one proc per input spec.
+	corePlacement := make([]physicalplan.ProcessorCorePlacement, 0, len(specs))
+	for node, spec := range specs {
+		corePlacement = append(corePlacement, physicalplan.ProcessorCorePlacement{
+			NodeID: node,
+			Core:   execinfrapb.ProcessorCoreUnion{CompactBackupData: spec},
+		})
+	}
+
+	// All of the progress information is sent through the metadata stream, so we
+	// have an empty result stream.
+	p.AddNoInputStage(corePlacement, execinfrapb.PostProcessSpec{}, compactBackupOutputTypes, execinfrapb.Ordering{})
+	p.PlanToStreamColMap = []int{}
+
+	dsp.FinalizePlan(planCtx, p)
+
+	rowResultWriter := sql.NewRowResultWriter(nil)
+	recv := sql.MakeDistSQLReceiver(
+		ctx,
+		sql.NewMetadataCallbackWriter(rowResultWriter, metaFn),
+		tree.Rows,
+		nil,   /* rangeCache */
+		noTxn, /* txn - the flow does not read or write the database */
+		func(ts hlc.Timestamp) {},
+		evalCtx.Tracing,
+	)
+	defer recv.Release()
+
+	// Copy the evalCtx, as dsp.Run() might change it.
+	evalCtxCopy := *evalCtx
+	dsp.Run(planCtx, noTxn, p, recv, &evalCtxCopy, nil /* finishedSetupFn */)()
+	return rowResultWriter.Err()
+}
+
+// makeCompactBackupDataSpecs returns a map from nodeID to the CompactBackupData
+// spec that should be planned on that node. It round-robin distributes the
+// import spans amongst the given nodes.
+func makeCompactBackupDataSpecs(
+	nodes []roachpb.NodeID,
+	entries []execinfrapb.RestoreSpanEntry,
+	pkIDs map[uint64]bool,
+	destination string,
+	encryption *roachpb.FileEncryptionOptions,
+	mvccFilter roachpb.MVCCFilter,
+	user security.SQLUsername,
+) map[roachpb.NodeID]*execinfrapb.CompactBackupDataSpec {
+	specsByNodes := make(map[roachpb.NodeID]*execinfrapb.CompactBackupDataSpec)
+	for i := range entries {
+		node := nodes[i%len(nodes)]
+		spec, ok := specsByNodes[node]
+		if !ok {
+			spec = &execinfrapb.CompactBackupDataSpec{
+				Encryption:     encryption,
+				DestinationURI: destination,
+				MVCCFilter:     mvccFilter,
+				PKIDs:          pkIDs,
+				UserProto:      user.EncodeProto(),
+			}
+			specsByNodes[node] = spec
+		}
+		spec.Entries = append(spec.Entries, entries[i])
+	}
+	return specsByNodes
+}
+
+func init() {
+	jobs.RegisterConstructor(
+		jobspb.TypeCompactBackup,
+		func(job *jobs.Job, _ *cluster.Settings) jobs.Resumer {
+			return &compactBackupResumer{job: job}
+		},
+	)
+}
@@ -0,0 +1,305 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package backupccl
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/ccl/storageccl"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/execinfra"
+	"github.com/cockroachdb/cockroach/pkg/sql/execinfrapb"
+	"github.com/cockroachdb/cockroach/pkg/sql/rowexec"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/builtins"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/storage"
+	"github.com/cockroachdb/cockroach/pkg/storage/cloud"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/retry"
+	"github.com/cockroachdb/cockroach/pkg/util/tracing"
+	"github.com/cockroachdb/errors"
+	gogotypes "github.com/gogo/protobuf/types"
+)
+
+// Progress is streamed to the coordinator through metadata.
+var compactBackupOutputTypes = []*types.T{}
+
+// compactBackupDataProcessor merges the files of a chain of backups during a
+// COMPACT BACKUP. It is assigned a set of spans, along with the files of every
+// backup in the chain that cover each of them. For every span, it merges the
+// files the same way an ImportRequest does during a RESTORE, writes the result
+// to new files in the destination, and streams back the new files through the
+// metadata channel provided by DistSQL.
+type compactBackupDataProcessor struct {
+	flowCtx *execinfra.FlowCtx
+	spec    execinfrapb.CompactBackupDataSpec
+	output  execinfra.RowReceiver
+}
+
+var _ execinfra.Processor = &compactBackupDataProcessor{}
+
+func (cp *compactBackupDataProcessor) OutputTypes() []*types.T {
+	return compactBackupOutputTypes
+}
+
+func newCompactBackupDataProcessor(
+	flowCtx *execinfra.FlowCtx,
+	processorID int32,
+	spec execinfrapb.CompactBackupDataSpec,
+	output execinfra.RowReceiver,
+) (execinfra.Processor, error) {
+	cp := &compactBackupDataProcessor{
+		flowCtx: flowCtx,
+		spec:    spec,
+		output:  output,
+	}
+	return cp, nil
+}
+
+func (cp *compactBackupDataProcessor) Run(ctx context.Context) {
+	ctx, span := tracing.ChildSpan(ctx, "compactBackupDataProcessor")
+	defer span.Finish()
+	defer cp.output.ProducerDone()
+
+	if err := runCompactBackupProcessor(ctx, cp.flowCtx, &cp.spec, cp.output); err != nil {
+		cp.output.Push(nil, &execinfrapb.ProducerMetadata{Err: err})
+	}
+}
+
+func runCompactBackupProcessor(
+	ctx context.Context,
+	flowCtx *execinfra.FlowCtx,
+	spec *execinfrapb.CompactBackupDataSpec,
+	output execinfra.RowReceiver,
+) error {
+	dest, err := flowCtx.Cfg.ExternalStorageFromURI(ctx, spec.DestinationURI, spec.User())
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := dest.Close(); err != nil {
+			log.Warningf(ctx, "close export storage failed %v", err)
+		}
+	}()
+
+	targetFileSize := storageccl.ExportRequestTargetFileSize.Get(&flowCtx.Cfg.Settings.SV)
+	for i := range spec.Entries {
+		entry := &spec.Entries[i]
+		log.VEventf(ctx, 1 /* level */, "compacting span %v", entry.Span)
+		files, err := compactBackupSpan(ctx, flowCtx, spec, dest, entry, targetFileSize)
+		if err != nil {
+			return errors.Wrapf(err, "compacting span %v", entry.Span)
+		}
+
+		var prog execinfrapb.RemoteProducerMetadata_BulkProcessorProgress
+		progDetails := BackupManifest_Progress{Files: files}
+		details, err := gogotypes.MarshalAny(&progDetails)
+		if err != nil {
+			return err
+		}
+		prog.ProgressDetails = *details
+		if cs := output.Push(nil, &execinfrapb.ProducerMetadata{BulkProcessorProgress: &prog}); cs != execinfra.NeedMoreRows {
+			return nil
+		}
+	}
+	return nil
+}
+
+// compactBackupSpan merges the files covering the span of the given entry into
+// new files in dest, each of roughly the target size, and returns them. Only
+// the latest revision of every key is kept, and deleted keys are dropped,
+// unless the spec asks for all revisions to be kept.
+func compactBackupSpan(
+	ctx context.Context,
+	flowCtx *execinfra.FlowCtx,
+	spec *execinfrapb.CompactBackupDataSpec,
+	dest cloud.ExternalStorage,
+	entry *execinfrapb.RestoreSpanEntry,
+	targetFileSize int64,
+) ([]BackupManifest_File, error) {
+	// The sstables only contain MVCC data and no intents, so using an MVCC
+	// iterator is sufficient.
+	var iters []storage.SimpleMVCCIterator
+	for _, file := range entry.Files {
+		log.VEventf(ctx, 2, "compact file %s %s", file.Path, entry.Span)
+
+		dir, err := flowCtx.Cfg.ExternalStorage(ctx, file.Dir)
+		if err != nil {
+			return nil, err
+		}
+		defer func() {
+			if err := dir.Close(); err != nil {
+				log.Warningf(ctx, "close export storage failed %v", err)
+			}
+		}()
+
+		fileContents, err := storageccl.ReadImportFile(ctx, dir, file, spec.Encryption)
+		if err != nil {
+			return nil, err
+		}
+		iter, err := storage.NewMemSSTIterator(fileContents, false)
+		if err != nil {
+			return nil, err
+		}
+		defer iter.Close()
+		iters = append(iters, iter)
+	}
+
+	allRevisions := spec.MVCCFilter == roachpb.MVCCFilter_All
+	w := compactBackupFileWriter{
+		ctx:     ctx,
+		flowCtx: flowCtx,
+		spec:    spec,
+		dest:    dest,
+	}
+	defer w.close()
+	w.reset(entry.Span.Key)
+
+	startKeyMVCC, endKeyMVCC := storage.MVCCKey{Key: entry.Span.Key}, storage.MVCCKey{Key: entry.Span.EndKey}
+	iter := storage.MakeMultiIterator(iters)
+	defer iter.Close()
+	var curKey roachpb.Key
+	for iter.SeekGE(startKeyMVCC); ; {
+		ok, err := iter.Valid()
+		if err != nil {
+			return nil, err
+		}
+		if !ok || !iter.UnsafeKey().Less(endKeyMVCC) {
+			break
+		}
+		unsafeKey := iter.UnsafeKey()
+		unsafeValue := iter.UnsafeValue()
+
+		if !allRevisions && len(unsafeValue) == 0 {
+			// The latest revision of the key is a deletion, which a full backup
+			// without revision history does not include.
+			iter.NextKey()
+			continue
+		}
+
+		// Only start a new file between keys, so that all of the revisions of
+		// a key end up in the same file.
+		if !unsafeKey.Key.Equal(curKey) {
+			if targetFileSize > 0 && w.rows.DataSize >= targetFileSize {
+				if err := w.flush(unsafeKey.Key); err != nil {
+					return nil, err
+				}
+			}
+			curKey = append(curKey[:0], unsafeKey.Key...)
+		}
+		if err := w.put(unsafeKey, unsafeValue); err != nil {
+			return nil, err
+		}
+
+		if allRevisions {
+			iter.Next()
+		} else {
+			iter.NextKey()
+		}
+	}
+	if err := w.flush(entry.Span.EndKey); err != nil {
+		return nil, err
+	}
+	return w.files, nil
+}
+
+// compactBackupFileWriter buffers the keys of a file of a compacted backup,
+// and writes the file to the destination when it is flushed.
+type compactBackupFileWriter struct {
+	ctx     context.Context
+	flowCtx *execinfra.FlowCtx
+	spec    *execinfrapb.CompactBackupDataSpec
+	dest    cloud.ExternalStorage
+
+	start   roachpb.Key
+	sstFile *storage.MemFile
+	sst     storage.SSTWriter
+	rows    storage.RowCounter
+
+	files []BackupManifest_File
+}
+
+func (w *compactBackupFileWriter) reset(start roachpb.Key) {
+	w.start = start
+	w.sstFile = &storage.MemFile{}
+	w.sst = storage.MakeBackupSSTWriter(w.sstFile)
+	w.rows = storage.RowCounter{}
+}
+
+func (w *compactBackupFileWriter) close() {
+	w.sst.Close()
+}
+
+func (w *compactBackupFileWriter) put(key storage.MVCCKey, value []byte) error {
+	if err := w.rows.Count(key.Key); err != nil {
+		return errors.Wrapf(err, "decoding %s", key)
+	}
+	if key.Timestamp.IsEmpty() {
+		if err := w.sst.PutUnversioned(key.Key, value); err != nil {
+			return errors.Wrapf(err, "adding key %s", key)
+		}
+	} else {
+		if err := w.sst.PutMVCC(key, value); err != nil {
+			return errors.Wrapf(err, "adding key %s", key)
+		}
+	}
+	w.rows.DataSize += int64(len(key.Key) + len(value))
+	return nil
+}
+
+// flush writes the keys added since the last flush, if any, to a new file that
+// covers the span from the end of the previous file to the given key.
+func (w *compactBackupFileWriter) flush(end roachpb.Key) error {
+	if w.rows.DataSize == 0 {
+		w.reset(end)
+		return nil
+	}
+	if err := w.sst.Finish(); err != nil {
+		return err
+	}
+	data := w.sstFile.Data()
+
+	// As in an ExportRequest, the checksum is of the unencrypted file.
+	checksum, err := storageccl.SHA512ChecksumData(data)
+	if err != nil {
+		return err
+	}
+	if w.spec.Encryption != nil {
+		data, err = storageccl.EncryptFile(data, w.spec.Encryption.Key)
+		if err != nil {
+			return err
+		}
+	}
+
+	path := fmt.Sprintf("%d.sst", builtins.GenerateUniqueInt(w.flowCtx.EvalCtx.NodeID.SQLInstanceID()))
+	const maxAttempts = 3
+	if err := retry.WithMaxAttempts(w.ctx, base.DefaultRetryOptions(), maxAttempts, func() error {
+		return w.dest.WriteFile(w.ctx, path, bytes.NewReader(data))
+	}); err != nil {
+		return errors.Wrapf(err, "writing %q", path)
+	}
+
+	w.files = append(w.files, BackupManifest_File{
+		Span:        roachpb.Span{Key: w.start, EndKey: end},
+		Path:        path,
+		Sha512:      checksum,
+		EntryCounts: countRows(w.rows.BulkOpSummary, w.spec.PKIDs),
+		FileSize:    int64(len(data)),
+	})
+	w.sst.Close()
+	w.reset(end)
+	return nil
+}
+
+func init() {
+	rowexec.NewCompactBackupDataProcessor = newCompactBackupDataProcessor
+}
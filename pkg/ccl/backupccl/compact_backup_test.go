@@ -0,0 +1,147 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package backupccl
+
+import (
+	gosql "database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompactBackup(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	const numAccounts = 100
+	_, tc, sqlDB, dir, cleanupFn := BackupRestoreTestSetup(t, MultiNode, numAccounts, InitNone)
+	defer cleanupFn()
+
+	t.Run("latest", func(t *testing.T) {
+		const full, inc1, inc2, compacted = LocalFoo + "/latest", LocalFoo + "/latest-inc1",
+			LocalFoo + "/latest-inc2", LocalFoo + "/latest-compacted"
+		sqlDB.Exec(t, `BACKUP DATABASE data TO $1`, full)
+		sqlDB.Exec(t, `UPDATE data.bank SET balance = balance + 1 WHERE id < 10`)
+		sqlDB.Exec(t, `DELETE FROM data.bank WHERE id >= 90`)
+		sqlDB.Exec(t, `BACKUP DATABASE data TO $1 INCREMENTAL FROM $2`, inc1, full)
+		sqlDB.Exec(t, `UPSERT INTO data.bank VALUES (95, 95, 'new'), (5, 5, 'updated')`)
+		sqlDB.Exec(t, `BACKUP DATABASE data TO $1 INCREMENTAL FROM $2, $3`, inc2, full, inc1)
+
+		var jobID int64
+		var status string
+		var fractionCompleted float64
+		var rows, indexEntries, bytes int
+		sqlDB.QueryRow(t, `COMPACT BACKUP FROM $1, $2, $3 TO $4`, full, inc1, inc2, compacted).Scan(
+			&jobID, &status, &fractionCompleted, &rows, &indexEntries, &bytes)
+		require.Equal(t, "succeeded", status)
+		require.Equal(t, 91, rows)
+
+		// The checkpoint is removed once the compacted backup is complete.
+		_, err := os.Stat(filepath.Join(dir, "foo", "latest-compacted", backupManifestCheckpointName))
+		require.True(t, os.IsNotExist(err))
+
+		sqlDB.Exec(t, `CREATE DATABASE latest`)
+		sqlDB.Exec(t, `RESTORE data.bank FROM $1 WITH into_db = 'latest'`, compacted)
+		sqlDB.CheckQueryResults(t, `SELECT * FROM latest.bank ORDER BY id`,
+			sqlDB.QueryStr(t, `SELECT * FROM data.bank ORDER BY id`))
+
+		// The compacted backup can be used as the base of an incremental backup.
+		sqlDB.Exec(t, `BACKUP DATABASE data TO $1 INCREMENTAL FROM $2`,
+			LocalFoo+"/latest-compacted-inc", compacted)
+
+		sqlDB.ExpectErr(t, "already contains a BACKUP_MANIFEST file",
+			`COMPACT BACKUP FROM $1, $2 TO $3`, full, inc1, compacted)
+	})
+
+	t.Run("revision_history", func(t *testing.T) {
+		const full, inc, compacted = LocalFoo + "/revs", LocalFoo + "/revs-inc",
+			LocalFoo + "/revs-compacted"
+		sqlDB.Exec(t, `BACKUP DATABASE data TO $1 WITH revision_history`, full)
+		sqlDB.Exec(t, `UPDATE data.bank SET balance = balance + 1 WHERE id < 10`)
+		var ts string
+		sqlDB.QueryRow(t, `SELECT cluster_logical_timestamp()`).Scan(&ts)
+		expected := sqlDB.QueryStr(t,
+			fmt.Sprintf(`SELECT * FROM data.bank AS OF SYSTEM TIME %s ORDER BY id`, ts))
+		sqlDB.Exec(t, `DELETE FROM data.bank WHERE id < 20`)
+		sqlDB.Exec(t, `BACKUP DATABASE data TO $1 INCREMENTAL FROM $2 WITH revision_history`,
+			inc, full)
+
+		sqlDB.Exec(t, `COMPACT BACKUP FROM $1, $2 TO $3`, full, inc, compacted)
+
+		// The compacted backup can be restored to a time in the middle of the
+		// chain, as well as to its end.
+		sqlDB.Exec(t, `CREATE DATABASE revs`)
+		sqlDB.Exec(t, fmt.Sprintf(
+			`RESTORE data.bank FROM $1 AS OF SYSTEM TIME %s WITH into_db = 'revs'`, ts), compacted)
+		sqlDB.CheckQueryResults(t, `SELECT * FROM revs.bank ORDER BY id`, expected)
+
+		sqlDB.Exec(t, `CREATE DATABASE revs_latest`)
+		sqlDB.Exec(t, `RESTORE data.bank FROM $1 WITH into_db = 'revs_latest'`, compacted)
+		sqlDB.CheckQueryResults(t, `SELECT * FROM revs_latest.bank ORDER BY id`,
+			sqlDB.QueryStr(t, `SELECT * FROM data.bank ORDER BY id`))
+	})
+
+	t.Run("encrypted", func(t *testing.T) {
+		const full, inc, compacted = LocalFoo + "/encrypted", LocalFoo + "/encrypted-inc",
+			LocalFoo + "/encrypted-compacted"
+		sqlDB.Exec(t, `BACKUP DATABASE data TO $1 WITH encryption_passphrase = 'abcdefg'`, full)
+		sqlDB.Exec(t, `UPDATE data.bank SET balance = balance + 1 WHERE id < 10`)
+		sqlDB.Exec(t, `BACKUP DATABASE data TO $1 INCREMENTAL FROM $2
+			WITH encryption_passphrase = 'abcdefg'`, inc, full)
+
+		sqlDB.ExpectErr(t, "file appears encrypted",
+			`COMPACT BACKUP FROM $1, $2 TO $3`, full, inc, compacted)
+		var jobID int64
+		var status string
+		var fractionCompleted float64
+		var rows, indexEntries, bytes int
+		sqlDB.QueryRow(t, `COMPACT BACKUP FROM $1, $2 TO $3 WITH encryption_passphrase = 'abcdefg'`,
+			full, inc, compacted).Scan(&jobID, &status, &fractionCompleted, &rows, &indexEntries, &bytes)
+		require.Equal(t, "succeeded", status)
+
+		var description string
+		sqlDB.QueryRow(t, `SELECT description FROM [SHOW JOBS] WHERE job_id = $1`, jobID).Scan(&description)
+		require.Contains(t, description, "encryption_passphrase = 'redacted'")
+
+		sqlDB.Exec(t, `CREATE DATABASE encrypted`)
+		sqlDB.ExpectErr(t, "file appears encrypted",
+			`RESTORE data.bank FROM $1 WITH into_db = 'encrypted'`, compacted)
+		sqlDB.Exec(t, `RESTORE data.bank FROM $1
+			WITH into_db = 'encrypted', encryption_passphrase = 'abcdefg'`, compacted)
+		sqlDB.CheckQueryResults(t, `SELECT * FROM encrypted.bank ORDER BY id`,
+			sqlDB.QueryStr(t, `SELECT * FROM data.bank ORDER BY id`))
+	})
+
+	t.Run("detached", func(t *testing.T) {
+		const full, compacted = LocalFoo + "/detached", LocalFoo + "/detached-compacted"
+		sqlDB.Exec(t, `BACKUP DATABASE data TO $1`, full)
+		db := sqlDB.DB.(*gosql.DB)
+
+		var jobID int64
+		tx, err := db.Begin()
+		require.NoError(t, err)
+		err = tx.QueryRow(`COMPACT BACKUP FROM $1 TO $2`, full, compacted).Scan(&jobID)
+		require.True(t, testutils.IsError(err,
+			"COMPACT BACKUP cannot be used inside a transaction without DETACHED option"))
+		require.NoError(t, tx.Rollback())
+
+		tx, err = db.Begin()
+		require.NoError(t, err)
+		require.NoError(t, tx.QueryRow(`COMPACT BACKUP FROM $1 TO $2 WITH detached`,
+			full, compacted).Scan(&jobID))
+		require.NoError(t, tx.Commit())
+		waitForSuccessfulJob(t, tc, jobID)
+	})
+}
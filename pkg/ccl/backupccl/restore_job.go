@@ -753,15 +753,15 @@ func getStatisticsFromBackup(
 // from those they had in the backed up database to what they should be
 // in the restored database.
 // It also selects only the statistics which belong to one of the tables
-// being restored. If the descriptorRewrites can re-write the table ID, then that
-// table is being restored.
+// being restored. If the descriptorRewrites can re-write the table ID to a
+// table that doesn't exist yet, then that table is being restored.
 func remapRelevantStatistics(
 	tableStatistics []*stats.TableStatisticProto, descriptorRewrites DescRewriteMap,
 ) []*stats.TableStatisticProto {
 	relevantTableStatistics := make([]*stats.TableStatisticProto, 0, len(tableStatistics))
 
 	for _, stat := range tableStatistics {
-		if tableRewrite, ok := descriptorRewrites[stat.TableID]; ok {
+		if tableRewrite, ok := descriptorRewrites[stat.TableID]; ok && !tableRewrite.ToExisting {
 			// Statistics imported only when table re-write is present.
 			stat.TableID = tableRewrite.ID
 			relevantTableStatistics = append(relevantTableStatistics, stat)
@@ -848,9 +848,16 @@ func createImportingDescriptors(
 	var mutableTables []*tabledesc.Mutable
 	var mutableDatabases []*dbdesc.Mutable
 
+	// Tables and sequences that are remapped to existing ones aren't restored,
+	// but the restored tables may need back references on them.
+	existingTableIDs := make(map[descpb.ID]struct{})
 	for _, desc := range sqlDescs {
 		switch desc := desc.(type) {
 		case catalog.TableDescriptor:
+			if rewrite := details.DescriptorRewrites[desc.GetID()]; rewrite.ToExisting {
+				existingTableIDs[rewrite.ID] = struct{}{}
+				continue
+			}
 			mut := tabledesc.NewCreatedMutable(*desc.TableDesc())
 			tables = append(tables, mut)
 			mutableTables = append(mutableTables, mut)
@@ -899,9 +906,23 @@ func createImportingDescriptors(
 	); err != nil {
 		return nil, nil, nil, err
 	}
+	// Foreign keys to existing tables are only added once the restored tables
+	// are published, since the existing tables would otherwise reference offline
+	// tables in the meantime. The descriptors recorded in the job details keep
+	// them, so that they can be added then.
 	tableDescs := make([]*descpb.TableDescriptor, len(mutableTables))
 	for i, table := range mutableTables {
 		tableDescs[i] = table.TableDesc()
+		var fks []descpb.ForeignKeyConstraint
+		for _, fk := range table.OutboundFKs {
+			if _, ok := existingTableIDs[fk.ReferencedTableID]; !ok {
+				fks = append(fks, fk)
+			}
+		}
+		if len(fks) != len(table.OutboundFKs) {
+			tableDescs[i] = protoutil.Clone(table.TableDesc()).(*descpb.TableDescriptor)
+			table.OutboundFKs = fks
+		}
 	}
 
 	// For each type, we might be writing the type in the backup, or we could be
@@ -973,6 +994,7 @@ func createImportingDescriptors(
 	}
 
 	if !details.PrepareCompleted {
+		changedSequenceIDs := make(map[descpb.ID]struct{})
 		err := descs.Txn(
 			ctx, p.ExecCfg().Settings, p.ExecCfg().LeaseManager,
 			p.ExecCfg().InternalExecutor, p.ExecCfg().DB, func(
@@ -1049,6 +1071,42 @@ func createImportingDescriptors(
 						}
 					}
 				}
+				// Likewise, existing sequences used by the tables being restored need
+				// back references to them.
+				for _, table := range mutableTables {
+					for i := range table.Columns {
+						col := &table.Columns[i]
+						for _, seqID := range col.UsesSequenceIds {
+							if _, ok := existingTableIDs[seqID]; !ok {
+								continue
+							}
+							seqDesc, err := descsCol.GetMutableTableVersionByID(ctx, seqID, txn)
+							if err != nil {
+								return err
+							}
+							refIdx := -1
+							for i, ref := range seqDesc.DependedOnBy {
+								if ref.ID == table.GetID() {
+									refIdx = i
+								}
+							}
+							if refIdx == -1 {
+								seqDesc.DependedOnBy = append(seqDesc.DependedOnBy, descpb.TableDescriptor_Reference{
+									ID:        table.GetID(),
+									ColumnIDs: []descpb.ColumnID{col.ID},
+								})
+							} else {
+								seqDesc.DependedOnBy[refIdx].ColumnIDs = append(seqDesc.DependedOnBy[refIdx].ColumnIDs, col.ID)
+							}
+							if err := descsCol.WriteDescToBatch(
+								ctx, false /* kvTrace */, seqDesc, b,
+							); err != nil {
+								return err
+							}
+							changedSequenceIDs[seqID] = struct{}{}
+						}
+					}
+				}
 				if err := txn.Run(ctx, b); err != nil {
 					return err
 				}
@@ -1088,6 +1146,11 @@ func createImportingDescriptors(
 				return nil, nil, nil, err
 			}
 		}
+		for existing := range changedSequenceIDs {
+			if err := sql.WaitToUpdateLeases(ctx, p.ExecCfg().LeaseManager, existing); err != nil {
+				return nil, nil, nil, err
+			}
+		}
 	}
 
 	return tables, oldTableIDs, spans, nil
@@ -1362,6 +1425,14 @@ func (r *restoreResumer) publishDescriptors(
 			read.GetID(), read.GetVersion(), exp)
 	}
 
+	existingDescIDs := make(map[descpb.ID]struct{})
+	for _, rewrite := range details.DescriptorRewrites {
+		if rewrite.ToExisting {
+			existingDescIDs[rewrite.ID] = struct{}{}
+		}
+	}
+	existingTables := make(map[descpb.ID]*tabledesc.Mutable)
+
 	// Write the new TableDescriptors and flip state over to public so they can be
 	// accessed.
 	for _, tbl := range details.TableDescs {
@@ -1372,6 +1443,21 @@ func (r *restoreResumer) publishDescriptors(
 		if err := checkVersion(mutTable, tbl.Version); err != nil {
 			return newDescriptorChangeJobs, err
 		}
+		// Add the foreign keys to existing tables that were left out of the
+		// descriptor while it was offline, along with their back references.
+		for i := range tbl.OutboundFKs {
+			fk := tbl.OutboundFKs[i]
+			if _, ok := existingDescIDs[fk.ReferencedTableID]; !ok {
+				continue
+			}
+			referenced, err := descsCol.GetMutableTableVersionByID(ctx, fk.ReferencedTableID, txn)
+			if err != nil {
+				return newDescriptorChangeJobs, err
+			}
+			mutTable.OutboundFKs = append(mutTable.OutboundFKs, fk)
+			referenced.InboundFKs = append(referenced.InboundFKs, fk)
+			existingTables[referenced.GetID()] = referenced
+		}
 		allMutDescs = append(allMutDescs, mutTable)
 		newTables = append(newTables, mutTable.TableDesc())
 		// For cluster restores, all the jobs are restored directly from the jobs
@@ -1450,6 +1536,13 @@ func (r *restoreResumer) publishDescriptors(
 			return newDescriptorChangeJobs, err
 		}
 	}
+	for _, desc := range existingTables {
+		if err := descsCol.WriteDescToBatch(
+			ctx, false /* kvTrace */, desc, b,
+		); err != nil {
+			return newDescriptorChangeJobs, err
+		}
+	}
 
 	if err := txn.Run(ctx, b); err != nil {
 		return newDescriptorChangeJobs, errors.Wrap(err, "publishing tables")
@@ -1544,6 +1637,14 @@ func (r *restoreResumer) dropDescriptors(
 		return err
 	}
 
+	// Remove any back references installed from existing tables and sequences
+	// to tables being restored.
+	if err := r.removeExistingTableBackReferences(
+		ctx, txn, descsCol, b, mutableTables, &details,
+	); err != nil {
+		return err
+	}
+
 	// Drop the table descriptors that were created at the start of the restore.
 	tablesToGC := make([]descpb.ID, 0, len(details.TableDescs))
 	for i := range mutableTables {
@@ -1705,6 +1806,85 @@ func (r *restoreResumer) dropDescriptors(
 	return nil
 }
 
+// removeExistingTableBackReferences removes the foreign key back references
+// from existing tables, and the back references from existing sequences, to
+// tables restored. It is used when rolling back from a failed restore.
+func (r *restoreResumer) removeExistingTableBackReferences(
+	ctx context.Context,
+	txn *kv.Txn,
+	descsCol *descs.Collection,
+	b *kv.Batch,
+	restoredTables []*tabledesc.Mutable,
+	details *jobspb.RestoreDetails,
+) error {
+	existingDescIDs := make(map[descpb.ID]struct{})
+	for _, rewrite := range details.DescriptorRewrites {
+		if rewrite.ToExisting {
+			existingDescIDs[rewrite.ID] = struct{}{}
+		}
+	}
+	existingTables := make(map[descpb.ID]*tabledesc.Mutable)
+	lookup := func(id descpb.ID) (*tabledesc.Mutable, error) {
+		if existing, ok := existingTables[id]; ok {
+			return existing, nil
+		}
+		existing, err := descsCol.GetMutableTableVersionByID(ctx, id, txn)
+		if err != nil {
+			return nil, err
+		}
+		existingTables[id] = existing
+		return existing, nil
+	}
+
+	for _, tbl := range restoredTables {
+		for i := range tbl.OutboundFKs {
+			fk := &tbl.OutboundFKs[i]
+			if _, ok := existingDescIDs[fk.ReferencedTableID]; !ok {
+				continue
+			}
+			referenced, err := lookup(fk.ReferencedTableID)
+			if err != nil {
+				return err
+			}
+			inboundFKs := referenced.InboundFKs[:0]
+			for _, backref := range referenced.InboundFKs {
+				if backref.OriginTableID != tbl.ID || backref.Name != fk.Name {
+					inboundFKs = append(inboundFKs, backref)
+				}
+			}
+			referenced.InboundFKs = inboundFKs
+		}
+		for i := range tbl.Columns {
+			for _, seqID := range tbl.Columns[i].UsesSequenceIds {
+				if _, ok := existingDescIDs[seqID]; !ok {
+					continue
+				}
+				seqDesc, err := lookup(seqID)
+				if err != nil {
+					return err
+				}
+				dependedOnBy := seqDesc.DependedOnBy[:0]
+				for _, ref := range seqDesc.DependedOnBy {
+					if ref.ID != tbl.ID {
+						dependedOnBy = append(dependedOnBy, ref)
+					}
+				}
+				seqDesc.DependedOnBy = dependedOnBy
+			}
+		}
+	}
+
+	// Now write any changed existing tables.
+	for _, existing := range existingTables {
+		if err := descsCol.WriteDescToBatch(
+			ctx, false /* kvTrace */, existing, b,
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // removeExistingTypeBackReferences removes back references from types that
 // exist in the cluster to tables restored. It is used when rolling back from
 // a failed restore.
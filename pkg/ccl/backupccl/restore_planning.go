@@ -40,6 +40,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/parser"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgnotice"
 	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
 	"github.com/cockroachdb/cockroach/pkg/sql/roleoption"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
@@ -64,6 +65,7 @@ const (
 	restoreOptSkipMissingSequences      = "skip_missing_sequences"
 	restoreOptSkipMissingSequenceOwners = "skip_missing_sequence_owners"
 	restoreOptSkipMissingViews          = "skip_missing_views"
+	restoreOptSkipTriggers              = "skip_triggers"
 
 	// The temporary database system tables will be restored into for full
 	// cluster backups.
@@ -160,11 +162,43 @@ func maybeFilterMissingViews(
 	return filteredTablesByID, nil
 }
 
+// checkTriggers returns an error if any of the tables to restore has triggers,
+// unless the skipTriggers option is set. The functions called by the triggers
+// are not restored, so the triggers are removed by RewriteTableDescs(), and a
+// notice lists the triggers which are removed.
+func checkTriggers(
+	ctx context.Context,
+	p sql.PlanHookState,
+	tablesByID map[descpb.ID]*tabledesc.Mutable,
+	skipTriggers bool,
+) error {
+	for _, table := range tablesByID {
+		if len(table.Triggers) == 0 {
+			continue
+		}
+		if !skipTriggers {
+			return pgerror.Newf(pgcode.FeatureNotSupported,
+				"cannot restore table %q because it has triggers (or %q option)",
+				table.Name, restoreOptSkipTriggers,
+			)
+		}
+		for i := range table.Triggers {
+			p.ExtendedEvalContext().ClientNoticeSender.BufferClientNotice(ctx, pgnotice.Newf(
+				"trigger %q on table %q is not restored", table.Triggers[i].Name, table.Name,
+			))
+		}
+	}
+	return nil
+}
+
 // allocateDescriptorRewrites determines the new ID and parentID (a "DescriptorRewrite")
 // for each table in sqlDescs and returns a mapping from old ID to said
 // DescriptorRewrite. It first validates that the provided sqlDescs can be restored
 // into their original database (or the database specified in opts) to avoid
-// leaking table IDs if we can be sure the restore would fail.
+// leaking table IDs if we can be sure the restore would fail. Tables and
+// sequences that are not restored but are remapped to existing ones by
+// existingRewrites satisfy the references to them, and their rewrites are
+// included in the returned mapping.
 func allocateDescriptorRewrites(
 	ctx context.Context,
 	p sql.PlanHookState,
@@ -176,8 +210,12 @@ func allocateDescriptorRewrites(
 	descriptorCoverage tree.DescriptorCoverage,
 	opts tree.RestoreOptions,
 	intoDB string,
+	existingRewrites DescRewriteMap,
 ) (DescRewriteMap, error) {
 	descriptorRewrites := make(DescRewriteMap)
+	for id, rewrite := range existingRewrites {
+		descriptorRewrites[id] = rewrite
+	}
 	var overrideDB string
 	var renaming bool
 	if opts.IntoDB != nil {
@@ -208,7 +246,7 @@ func allocateDescriptorRewrites(
 		for i := range table.OutboundFKs {
 			fk := &table.OutboundFKs[i]
 			if _, ok := tablesByID[fk.ReferencedTableID]; !ok {
-				if !opts.SkipMissingFKs {
+				if _, ok := existingRewrites[fk.ReferencedTableID]; !ok && !opts.SkipMissingFKs {
 					return nil, errors.Errorf(
 						"cannot restore table %q without referenced table %d (or %q option)",
 						table.Name, fk.ReferencedTableID, restoreOptSkipMissingFKs,
@@ -233,7 +271,7 @@ func allocateDescriptorRewrites(
 			}
 			for _, seqID := range col.UsesSequenceIds {
 				if _, ok := tablesByID[seqID]; !ok {
-					if !opts.SkipMissingSequences {
+					if _, ok := existingRewrites[seqID]; !ok && !opts.SkipMissingSequences {
 						return nil, errors.Errorf(
 							"cannot restore table %q without referenced sequence %d (or %q option)",
 							table.Name, seqID, restoreOptSkipMissingSequences,
@@ -711,6 +749,185 @@ func resolveTargetDB(
 	return database.Name, nil
 }
 
+// resolveRestoreNewTableDB returns the name of the database that a table
+// restored under the given new name is restored into. The table keeps the
+// schema it had in the backup, so the new name can only be qualified with that
+// schema; a two-part name is otherwise qualified with a database.
+func resolveRestoreNewTableDB(
+	newName *tree.UnresolvedObjectName,
+	table *tabledesc.Mutable,
+	databasesByID map[descpb.ID]*dbdesc.Mutable,
+	schemasByID map[descpb.ID]*schemadesc.Mutable,
+) (string, error) {
+	database, ok := databasesByID[table.GetParentID()]
+	if !ok {
+		return "", errors.Errorf("no database with ID %d in backup for table %q",
+			table.GetParentID(), table.Name)
+	}
+	schemaName := tree.PublicSchema
+	if sc, ok := schemasByID[table.GetParentSchemaID()]; ok {
+		schemaName = sc.GetName()
+	}
+
+	dbName := database.GetName()
+	switch newName.NumParts {
+	case 2:
+		if newName.Schema() != schemaName {
+			dbName = newName.Schema()
+		}
+	case 3:
+		if newName.Schema() != schemaName {
+			return "", errors.Errorf("cannot restore table %q into schema %q, it can only be restored into schema %q",
+				table.Name, newName.Schema(), schemaName)
+		}
+		dbName = newName.Catalog()
+	}
+	return dbName, nil
+}
+
+// remapRenamedTableReferences returns the rewrites that remap the tables that
+// the foreign keys of a table restored under a new name reference, and the
+// sequences that its columns use, to the tables and sequences that already
+// exist in the cluster under the names they had in the backup. Only the ones
+// that are not restored along with the table are remapped, and only if they
+// are compatible with the references to them. This is what allows a table to
+// be restored next to the one it was backed up from.
+func remapRenamedTableReferences(
+	ctx context.Context,
+	p sql.PlanHookState,
+	table *tabledesc.Mutable,
+	tablesByID map[descpb.ID]*tabledesc.Mutable,
+	backupDescs []catalog.Descriptor,
+	intoDB string,
+) (DescRewriteMap, error) {
+	codec := p.ExecCfg().Codec
+	backupDescsByID := make(map[descpb.ID]catalog.Descriptor, len(backupDescs))
+	for _, desc := range backupDescs {
+		backupDescsByID[desc.GetID()] = desc
+	}
+
+	// lookupExisting returns the backed up table with the given ID, along with
+	// the table in the cluster that has the same name and schema, in the
+	// database the restored table goes into if they shared a database in the
+	// backup, or in its own database otherwise. The existing table is nil if
+	// there is none.
+	lookupExisting := func(
+		txn *kv.Txn, id descpb.ID,
+	) (backupTable, existing catalog.TableDescriptor, _ error) {
+		backupTable, ok := backupDescsByID[id].(catalog.TableDescriptor)
+		if !ok {
+			return nil, nil, nil
+		}
+		dbName := intoDB
+		if backupTable.GetParentID() != table.GetParentID() {
+			db, ok := backupDescsByID[backupTable.GetParentID()]
+			if !ok {
+				return backupTable, nil, nil
+			}
+			dbName = db.GetName()
+		}
+		found, dbID, err := catalogkv.LookupDatabaseID(ctx, txn, codec, dbName)
+		if err != nil || !found {
+			return backupTable, nil, err
+		}
+		schemaID := backupTable.GetParentSchemaID()
+		if schemaID != keys.PublicSchemaID {
+			sc, ok := backupDescsByID[schemaID]
+			if !ok {
+				return backupTable, nil, nil
+			}
+			found, schemaID, err = catalogkv.ResolveSchemaID(ctx, txn, codec, dbID, sc.GetName())
+			if err != nil || !found {
+				return backupTable, nil, err
+			}
+		}
+		found, existingID, err := catalogkv.LookupObjectID(
+			ctx, txn, codec, dbID, schemaID, backupTable.GetName())
+		if err != nil || !found {
+			return backupTable, nil, err
+		}
+		desc, err := catalogkv.GetAnyDescriptorByID(ctx, txn, codec, existingID, catalogkv.Immutable)
+		if err != nil {
+			return backupTable, nil, err
+		}
+		existing, _ = desc.(catalog.TableDescriptor)
+		return backupTable, existing, nil
+	}
+
+	var rewrites DescRewriteMap
+	if err := p.ExecCfg().DB.Txn(ctx, func(ctx context.Context, txn *kv.Txn) error {
+		rewrites = make(DescRewriteMap)
+		for i := range table.OutboundFKs {
+			fk := &table.OutboundFKs[i]
+			if _, ok := tablesByID[fk.ReferencedTableID]; ok {
+				continue
+			}
+			backupTable, existing, err := lookupExisting(txn, fk.ReferencedTableID)
+			if err != nil {
+				return err
+			}
+			if existing == nil {
+				continue
+			}
+			if !existing.IsTable() {
+				return errors.Errorf("cannot restore table %q: %q referenced by foreign key %q is not a table",
+					table.Name, existing.GetName(), fk.Name)
+			}
+			// The foreign key keeps the IDs of the columns it references, so
+			// they have to be the same in the existing table.
+			for _, colID := range fk.ReferencedColumnIDs {
+				col, err := backupTable.FindColumnByID(colID)
+				if err != nil {
+					return err
+				}
+				existingCol, _, err := existing.FindColumnByName(tree.Name(col.Name))
+				if err != nil || existingCol.ID != colID {
+					return errors.Errorf(
+						"cannot restore table %q: column %q referenced by foreign key %q does not match a column of table %q",
+						table.Name, col.Name, fk.Name, existing.GetName())
+				}
+			}
+			if _, err := tabledesc.FindFKReferencedIndex(existing, fk.ReferencedColumnIDs); err != nil {
+				return errors.Wrapf(err, "cannot restore table %q: foreign key %q", table.Name, fk.Name)
+			}
+			rewrites[fk.ReferencedTableID] = &jobspb.RestoreDetails_DescriptorRewrite{
+				ID:         existing.GetID(),
+				ParentID:   existing.GetParentID(),
+				ToExisting: true,
+			}
+		}
+
+		for i := range table.Columns {
+			col := &table.Columns[i]
+			for _, seqID := range col.UsesSequenceIds {
+				if _, ok := tablesByID[seqID]; ok {
+					continue
+				}
+				_, existing, err := lookupExisting(txn, seqID)
+				if err != nil {
+					return err
+				}
+				if existing == nil {
+					continue
+				}
+				if !existing.IsSequence() {
+					return errors.Errorf("cannot restore table %q: %q used by column %q is not a sequence",
+						table.Name, existing.GetName(), col.Name)
+				}
+				rewrites[seqID] = &jobspb.RestoreDetails_DescriptorRewrite{
+					ID:         existing.GetID(),
+					ParentID:   existing.GetParentID(),
+					ToExisting: true,
+				}
+			}
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return rewrites, nil
+}
+
 // maybeUpgradeTableDescsInBackupManifests updates the backup descriptors'
 // table descriptors to use the newer 19.2-style foreign key representation,
 // if they are not already upgraded. This requires resolving cross-table FK
@@ -914,6 +1131,9 @@ func RewriteTableDescs(
 		table.ID = tableRewrite.ID
 		table.UnexposedParentSchemaID = maybeRewriteSchemaID(table.GetParentSchemaID(), descriptorRewrites)
 		table.ParentID = tableRewrite.ParentID
+		if tableRewrite.NewName != "" {
+			table.Name = tableRewrite.NewName
+		}
 
 		// Remap type IDs in all serialized expressions within the TableDescriptor.
 		// TODO (rohany): This needs tests once partial indexes are ready.
@@ -966,6 +1186,11 @@ func RewriteTableDescs(
 			if indexRewrite, ok := descriptorRewrites[to]; ok {
 				fk.ReferencedTableID = indexRewrite.ID
 				fk.OriginTableID = tableRewrite.ID
+				// The restored rows were never checked against the existing table,
+				// so the constraint has to be validated again.
+				if indexRewrite.ToExisting {
+					fk.Validity = descpb.ConstraintValidity_Unvalidated
+				}
 			} else {
 				// If indexRewrite doesn't exist, the user has specified
 				// restoreOptSkipMissingFKs. Error checking in the case the user hasn't has
@@ -973,9 +1198,6 @@ func RewriteTableDescs(
 				continue
 			}
 
-			// TODO(dt): if there is an existing (i.e. non-restoring) table with
-			// a db and name matching the one the FK pointed to at backup, should
-			// we update the FK to point to it?
 			table.OutboundFKs = append(table.OutboundFKs, *fk)
 		}
 
@@ -983,7 +1205,8 @@ func RewriteTableDescs(
 		table.InboundFKs = nil
 		for i := range origInboundFks {
 			ref := &origInboundFks[i]
-			if refRewrite, ok := descriptorRewrites[ref.OriginTableID]; ok {
+			// An existing table doesn't have the foreign key that this refers to.
+			if refRewrite, ok := descriptorRewrites[ref.OriginTableID]; ok && !refRewrite.ToExisting {
 				ref.ReferencedTableID = tableRewrite.ID
 				ref.OriginTableID = refRewrite.ID
 				table.InboundFKs = append(table.InboundFKs, *ref)
@@ -1009,6 +1232,13 @@ func RewriteTableDescs(
 				table.DependedOnBy = append(table.DependedOnBy, ref)
 			}
 		}
+		// Functions are not restored, so the references from the functions
+		// which depend on the table are removed, and so are the triggers which
+		// call them. To get here, the user must have specified 'skip_triggers'
+		// if there are any triggers, otherwise we would have errored out in
+		// checkTriggers().
+		table.DependedOnByFunctions = nil
+		table.Triggers = nil
 
 		if table.IsSequence() && table.SequenceOpts.HasOwner() {
 			if ownerRewrite, ok := descriptorRewrites[table.SequenceOpts.SequenceOwner.OwnerTableID]; ok {
@@ -1049,7 +1279,7 @@ func RewriteTableDescs(
 				// If the owned sequence is not being restored, the user must have
 				// specified 'skip_missing_sequence_owners' to get here, otherwise
 				// we would have errored out in allocateDescriptorRewrites.
-				if rewrite, ok := descriptorRewrites[seqID]; ok {
+				if rewrite, ok := descriptorRewrites[seqID]; ok && !rewrite.ToExisting {
 					newOwnedSeqRefs = append(newOwnedSeqRefs, rewrite.ID)
 				}
 			}
@@ -1116,6 +1346,7 @@ func resolveOptionsForRestoreJobDescription(
 		SkipMissingSequences:      opts.SkipMissingSequences,
 		SkipMissingSequenceOwners: opts.SkipMissingSequenceOwners,
 		SkipMissingViews:          opts.SkipMissingViews,
+		SkipTriggers:              opts.SkipTriggers,
 		Detached:                  opts.Detached,
 	}
 
@@ -1150,6 +1381,7 @@ func restoreJobDescription(
 		DescriptorCoverage: restore.DescriptorCoverage,
 		AsOf:               restore.AsOf,
 		Targets:            restore.Targets,
+		NewTableName:       restore.NewTableName,
 		From:               make([]tree.StringOrPlaceholderOptList, len(restore.From)),
 	}
 
@@ -1223,6 +1455,10 @@ func restorePlanHook(
 
 	var intoDBFn func() (string, error)
 	if restoreStmt.Options.IntoDB != nil {
+		if restoreStmt.NewTableName != nil {
+			return nil, nil, nil, false, errors.Errorf(
+				"cannot use %q option when restoring a table under a new name", restoreOptIntoDB)
+		}
 		intoDBFn, err = p.TypeAsString(ctx, restoreStmt.Options.IntoDB, "RESTORE")
 		if err != nil {
 			return nil, nil, nil, false, err
@@ -1299,6 +1535,9 @@ func restorePlanHook(
 			if err != nil {
 				return err
 			}
+			if err := checkPrivilegesForKMSURIs(ctx, p, kms, "RESTORE"); err != nil {
+				return err
+			}
 		}
 
 		var intoDB string
@@ -1414,7 +1653,7 @@ func doRestorePlan(
 			return err
 		}
 		ioConf := baseStores[0].ExternalIOConf()
-		defaultKMSInfo, err := validateKMSURIsAgainstFullBackup(kms,
+		defaultKMSInfo, err := resolveKMSInfoForDecryption(kms,
 			newEncryptedDataKeyMapFromProtoMap(opts.EncryptedDataKeyByKMSMasterKeyID), &backupKMSEnv{
 				baseStores[0].Settings(),
 				&ioConf,
@@ -1514,6 +1753,42 @@ func doRestorePlan(
 	if err != nil {
 		return err
 	}
+	if err := checkTriggers(
+		ctx, p, filteredTablesByID, restoreStmt.Options.SkipTriggers,
+	); err != nil {
+		return err
+	}
+
+	// A table restored under a new name is restored into the database that the
+	// new name is qualified with, or into its own database, the same way as if
+	// that database had been specified with the into_db option. The tables and
+	// sequences it references are remapped to the existing ones in there.
+	allocateOpts := restoreStmt.Options
+	var existingRewrites DescRewriteMap
+	var renamedTable *tabledesc.Mutable
+	if restoreStmt.NewTableName != nil {
+		if len(filteredTablesByID) != 1 {
+			return errors.Errorf("RESTORE TABLE ... AS can only restore a single table")
+		}
+		for _, table := range filteredTablesByID {
+			renamedTable = table
+		}
+		intoDB, err = resolveRestoreNewTableDB(
+			restoreStmt.NewTableName, renamedTable, databasesByID, schemasByID)
+		if err != nil {
+			return err
+		}
+		allocateOpts.IntoDB = tree.NewDString(intoDB)
+		backupDescs, _ := loadSQLDescsFromBackupsAtTime(mainBackupManifests, endTime)
+		existingRewrites, err = remapRenamedTableReferences(
+			ctx, p, renamedTable, filteredTablesByID, backupDescs, intoDB)
+		if err != nil {
+			return err
+		}
+		// The new name is the one checked for collisions below.
+		renamedTable.Name = restoreStmt.NewTableName.Object()
+	}
+
 	descriptorRewrites, err := allocateDescriptorRewrites(
 		ctx,
 		p,
@@ -1523,12 +1798,16 @@ func doRestorePlan(
 		typesByID,
 		restoreDBs,
 		restoreStmt.DescriptorCoverage,
-		restoreStmt.Options,
+		allocateOpts,
 		intoDB,
+		existingRewrites,
 	)
 	if err != nil {
 		return err
 	}
+	if renamedTable != nil {
+		descriptorRewrites[renamedTable.ID].NewName = renamedTable.Name
+	}
 	description, err := restoreJobDescription(p, restoreStmt, from, restoreStmt.Options, intoDB, kms)
 	if err != nil {
 		return err
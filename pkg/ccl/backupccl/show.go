@@ -10,8 +10,10 @@ package backupccl
 
 import (
 	"context"
+	"fmt"
 	"net/url"
 	"path"
+	"sort"
 	"strings"
 	"time"
 
@@ -29,6 +31,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
 	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/storage/cloud"
 	"github.com/cockroachdb/cockroach/pkg/storage/cloudimpl"
 	"github.com/cockroachdb/cockroach/pkg/util/encoding"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
@@ -55,6 +58,10 @@ func showBackupPlanHook(
 		return showBackupsInCollectionPlanHook(ctx, backup, p)
 	}
 
+	if backup.Details == tree.BackupDiffDetails {
+		return showBackupDiffPlanHook(ctx, backup, p)
+	}
+
 	toFn, err := p.TypeAsString(ctx, backup.Path, "SHOW BACKUP")
 	if err != nil {
 		return nil, nil, nil, false, err
@@ -121,30 +128,9 @@ func showBackupPlanHook(
 		}
 		defer store.Close()
 
-		var encryption *jobspb.BackupEncryptionOptions
-		if passphrase, ok := opts[backupOptEncPassphrase]; ok {
-			opts, err := readEncryptionOptions(ctx, store)
-			if err != nil {
-				return err
-			}
-			encryptionKey := storageccl.GenerateKey([]byte(passphrase), opts.Salt)
-			encryption = &jobspb.BackupEncryptionOptions{Mode: jobspb.EncryptionMode_Passphrase,
-				Key: encryptionKey}
-		} else if kms, ok := opts[backupOptEncKMS]; ok {
-			opts, err := readEncryptionOptions(ctx, store)
-			if err != nil {
-				return err
-			}
-
-			env := &backupKMSEnv{p.ExecCfg().Settings, &p.ExecCfg().ExternalIODirConfig}
-			defaultKMSInfo, err := validateKMSURIsAgainstFullBackup([]string{kms},
-				newEncryptedDataKeyMapFromProtoMap(opts.EncryptedDataKeyByKMSMasterKeyID), env)
-			if err != nil {
-				return err
-			}
-			encryption = &jobspb.BackupEncryptionOptions{
-				Mode:    jobspb.EncryptionMode_KMS,
-				KMSInfo: defaultKMSInfo}
+		encryption, err := resolveShowBackupEncryption(ctx, p, store, opts)
+		if err != nil {
+			return err
 		}
 
 		incPaths, err := findPriorBackupNames(ctx, store)
@@ -200,6 +186,39 @@ func showBackupPlanHook(
 	return fn, shower.header, nil, false, nil
 }
 
+// resolveShowBackupEncryption returns the encryption options needed to read the
+// backup in store, as specified by the options of a SHOW BACKUP statement. It
+// returns nil if the backup is not encrypted.
+func resolveShowBackupEncryption(
+	ctx context.Context, p sql.PlanHookState, store cloud.ExternalStorage, opts map[string]string,
+) (*jobspb.BackupEncryptionOptions, error) {
+	if passphrase, ok := opts[backupOptEncPassphrase]; ok {
+		encOpts, err := readEncryptionOptions(ctx, store)
+		if err != nil {
+			return nil, err
+		}
+		encryptionKey := storageccl.GenerateKey([]byte(passphrase), encOpts.Salt)
+		return &jobspb.BackupEncryptionOptions{Mode: jobspb.EncryptionMode_Passphrase,
+			Key: encryptionKey}, nil
+	} else if kms, ok := opts[backupOptEncKMS]; ok {
+		encOpts, err := readEncryptionOptions(ctx, store)
+		if err != nil {
+			return nil, err
+		}
+
+		env := &backupKMSEnv{p.ExecCfg().Settings, &p.ExecCfg().ExternalIODirConfig}
+		defaultKMSInfo, err := validateKMSURIsAgainstFullBackup([]string{kms},
+			newEncryptedDataKeyMapFromProtoMap(encOpts.EncryptedDataKeyByKMSMasterKeyID), env)
+		if err != nil {
+			return nil, err
+		}
+		return &jobspb.BackupEncryptionOptions{
+			Mode:    jobspb.EncryptionMode_KMS,
+			KMSInfo: defaultKMSInfo}, nil
+	}
+	return nil, nil
+}
+
 type backupShower struct {
 	header colinfo.ResultColumns
 	fn     func([]BackupManifest) ([]tree.Datums, error)
@@ -234,39 +253,8 @@ func backupShowerDefault(
 		fn: func(manifests []BackupManifest) ([]tree.Datums, error) {
 			var rows []tree.Datums
 			for _, manifest := range manifests {
-				// Map database ID to descriptor name.
-				dbIDToName := make(map[descpb.ID]string)
-				schemaIDToName := make(map[descpb.ID]string)
-				schemaIDToName[keys.PublicSchemaID] = sessiondata.PublicSchemaName
-				for i := range manifest.Descriptors {
-					descriptor := &manifest.Descriptors[i]
-					if descriptor.GetDatabase() != nil {
-						id := descpb.GetDescriptorID(descriptor)
-						if _, ok := dbIDToName[id]; !ok {
-							dbIDToName[id] = descpb.GetDescriptorName(descriptor)
-						}
-					} else if descriptor.GetSchema() != nil {
-						id := descpb.GetDescriptorID(descriptor)
-						if _, ok := schemaIDToName[id]; !ok {
-							schemaIDToName[id] = descpb.GetDescriptorName(descriptor)
-						}
-					}
-				}
-				descSizes := make(map[descpb.ID]RowCount)
-				for _, file := range manifest.Files {
-					// TODO(dan): This assumes each file in the backup only contains
-					// data from a single table, which is usually but not always
-					// correct. It does not account for interleaved tables or if a
-					// BACKUP happened to catch a newly created table that hadn't yet
-					// been split into its own range.
-					_, tableID, err := encoding.DecodeUvarintAscending(file.Span.Key)
-					if err != nil {
-						continue
-					}
-					s := descSizes[descpb.ID(tableID)]
-					s.add(file.EntryCounts)
-					descSizes[descpb.ID(tableID)] = s
-				}
+				dbIDToName, schemaIDToName := backupManifestDescNames(&manifest)
+				descSizes := backupManifestTableSizes(&manifest)
 				start := tree.DNull
 				end, err := tree.MakeDTimestamp(timeutil.Unix(0, manifest.EndTime.WallTime), time.Nanosecond)
 				if err != nil {
@@ -372,6 +360,52 @@ func backupShowerDefault(
 	}
 }
 
+// backupManifestDescNames maps the IDs of the databases and schemas in the
+// manifest to their names.
+func backupManifestDescNames(
+	manifest *BackupManifest,
+) (dbIDToName map[descpb.ID]string, schemaIDToName map[descpb.ID]string) {
+	dbIDToName = make(map[descpb.ID]string)
+	schemaIDToName = make(map[descpb.ID]string)
+	schemaIDToName[keys.PublicSchemaID] = sessiondata.PublicSchemaName
+	for i := range manifest.Descriptors {
+		descriptor := &manifest.Descriptors[i]
+		if descriptor.GetDatabase() != nil {
+			id := descpb.GetDescriptorID(descriptor)
+			if _, ok := dbIDToName[id]; !ok {
+				dbIDToName[id] = descpb.GetDescriptorName(descriptor)
+			}
+		} else if descriptor.GetSchema() != nil {
+			id := descpb.GetDescriptorID(descriptor)
+			if _, ok := schemaIDToName[id]; !ok {
+				schemaIDToName[id] = descpb.GetDescriptorName(descriptor)
+			}
+		}
+	}
+	return dbIDToName, schemaIDToName
+}
+
+// backupManifestTableSizes sums the entry counts of the files in the manifest
+// by table ID.
+func backupManifestTableSizes(manifest *BackupManifest) map[descpb.ID]RowCount {
+	descSizes := make(map[descpb.ID]RowCount)
+	for _, file := range manifest.Files {
+		// TODO(dan): This assumes each file in the backup only contains
+		// data from a single table, which is usually but not always
+		// correct. It does not account for interleaved tables or if a
+		// BACKUP happened to catch a newly created table that hadn't yet
+		// been split into its own range.
+		_, tableID, err := encoding.DecodeUvarintAscending(file.Span.Key)
+		if err != nil {
+			continue
+		}
+		s := descSizes[descpb.ID(tableID)]
+		s.add(file.EntryCounts)
+		descSizes[descpb.ID(tableID)] = s
+	}
+	return descSizes
+}
+
 func nullIfEmpty(s string) tree.Datum {
 	if s == "" {
 		return tree.DNull
@@ -475,6 +509,349 @@ var backupShowerFiles = backupShower{
 	},
 }
 
+// showBackupDiffPlanHook implements PlanHookFn for SHOW BACKUP DIFF, which
+// compares the backups at two locations, e.g. two backups in the same chain.
+// The incremental backups appended to a location are read along with the
+// backup at the location, and the latest of them is compared.
+func showBackupDiffPlanHook(
+	ctx context.Context, backup *tree.ShowBackup, p sql.PlanHookState,
+) (sql.PlanHookRowFn, colinfo.ResultColumns, []sql.PlanNode, bool, error) {
+	fromFn, err := p.TypeAsString(ctx, backup.Path, "SHOW BACKUP DIFF")
+	if err != nil {
+		return nil, nil, nil, false, err
+	}
+	toFn, err := p.TypeAsString(ctx, backup.DiffPath, "SHOW BACKUP DIFF")
+	if err != nil {
+		return nil, nil, nil, false, err
+	}
+
+	expected := map[string]sql.KVStringOptValidate{
+		backupOptEncPassphrase: sql.KVStringOptRequireValue,
+		backupOptEncKMS:        sql.KVStringOptRequireValue,
+	}
+	optsFn, err := p.TypeAsStringOpts(ctx, backup.Options, expected)
+	if err != nil {
+		return nil, nil, nil, false, err
+	}
+
+	fn := func(ctx context.Context, _ []sql.PlanNode, resultsCh chan<- tree.Datums) error {
+		ctx, span := tracing.ChildSpan(ctx, backup.StatementTag())
+		defer span.Finish()
+
+		opts, err := optsFn()
+		if err != nil {
+			return err
+		}
+
+		chains := make([][]BackupManifest, 2)
+		for i, uriFn := range []func() (string, error){fromFn, toFn} {
+			uri, err := uriFn()
+			if err != nil {
+				return err
+			}
+			chains[i], err = readBackupChainForShow(ctx, p, uri, opts)
+			if err != nil {
+				return err
+			}
+			if err := maybeUpgradeTableDescsInBackupManifests(ctx, chains[i], true); err != nil {
+				return err
+			}
+		}
+
+		for _, row := range diffBackupChains(ctx, chains[0], chains[1]) {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case resultsCh <- row:
+			}
+		}
+		return nil
+	}
+	return fn, backupDiffHeader, nil, false, nil
+}
+
+// readBackupChainForShow reads the manifest of the backup at uri, followed by
+// those of the incremental backups appended to it.
+func readBackupChainForShow(
+	ctx context.Context, p sql.PlanHookState, uri string, opts map[string]string,
+) ([]BackupManifest, error) {
+	store, err := p.ExecCfg().DistSQLSrv.ExternalStorageFromURI(ctx, uri, p.User())
+	if err != nil {
+		return nil, errors.Wrapf(err, "make storage")
+	}
+	defer store.Close()
+
+	encryption, err := resolveShowBackupEncryption(ctx, p, store, opts)
+	if err != nil {
+		return nil, err
+	}
+	incPaths, err := findPriorBackupNames(ctx, store)
+	if err != nil {
+		if !errors.Is(err, cloudimpl.ErrListingUnsupported) {
+			return nil, err
+		}
+		log.Warningf(ctx, "storage sink %T does not support listing, only resolving the base backup", store)
+		incPaths = nil
+	}
+
+	manifests := make([]BackupManifest, len(incPaths)+1)
+	if manifests[0], err = readBackupManifestFromStore(ctx, store, encryption); err != nil {
+		return nil, err
+	}
+	for i := range incPaths {
+		if manifests[i+1], err = readBackupManifest(ctx, store, incPaths[i], encryption); err != nil {
+			return nil, err
+		}
+	}
+	// Blank the stats to prevent memory blowup.
+	for i := range manifests {
+		manifests[i].DeprecatedStatistics = nil
+	}
+	return manifests, nil
+}
+
+var backupDiffHeader = colinfo.ResultColumns{
+	{Name: "database_name", Typ: types.String},
+	{Name: "parent_schema_name", Typ: types.String},
+	{Name: "object_name", Typ: types.String},
+	{Name: "object_type", Typ: types.String},
+	{Name: "change", Typ: types.String},
+	{Name: "details", Typ: types.String},
+	{Name: "rows_before", Typ: types.Int},
+	{Name: "rows_after", Typ: types.Int},
+	{Name: "size_bytes_before", Typ: types.Int},
+	{Name: "size_bytes_after", Typ: types.Int},
+}
+
+// The values of the change column of SHOW BACKUP DIFF.
+const (
+	backupDiffAdded     = "added"
+	backupDiffDropped   = "dropped"
+	backupDiffAltered   = "altered"
+	backupDiffUnchanged = "unchanged"
+)
+
+// backupDiffObject is a descriptor in one of the backups compared by SHOW
+// BACKUP DIFF.
+type backupDiffObject struct {
+	desc       catalog.Descriptor
+	dbName     string
+	schemaName string
+	// size is only set for tables, and adds up the backups of the chain. It is
+	// nil if the chain doesn't start with a full backup, as the size of the
+	// table in the backups it is incremental to isn't known.
+	size *RowCount
+}
+
+func (o *backupDiffObject) qualifiedName() string {
+	var parts []string
+	for _, part := range []string{o.dbName, o.schemaName, o.desc.GetName()} {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return strings.Join(parts, ".")
+}
+
+// backupDiffObjects returns the descriptors in the latest manifest of a chain
+// of backups which are not dropped, keyed by ID.
+func backupDiffObjects(
+	ctx context.Context, chain []BackupManifest,
+) map[descpb.ID]*backupDiffObject {
+	manifest := &chain[len(chain)-1]
+	dbIDToName, schemaIDToName := backupManifestDescNames(manifest)
+	var descSizes map[descpb.ID]RowCount
+	if chain[0].StartTime.IsEmpty() {
+		descSizes = make(map[descpb.ID]RowCount)
+		for i := range chain {
+			for id, size := range backupManifestTableSizes(&chain[i]) {
+				s := descSizes[id]
+				s.add(size)
+				descSizes[id] = s
+			}
+		}
+	}
+	objects := make(map[descpb.ID]*backupDiffObject)
+	for i := range manifest.Descriptors {
+		desc := catalogkv.UnwrapDescriptorRaw(ctx, &manifest.Descriptors[i])
+		if desc.Dropped() {
+			continue
+		}
+		o := &backupDiffObject{desc: desc}
+		switch desc := desc.(type) {
+		case catalog.SchemaDescriptor:
+			o.dbName = dbIDToName[desc.GetParentID()]
+		case catalog.TypeDescriptor:
+			o.dbName = dbIDToName[desc.GetParentID()]
+			o.schemaName = schemaIDToName[desc.GetParentSchemaID()]
+		case catalog.TableDescriptor:
+			o.dbName = dbIDToName[desc.GetParentID()]
+			o.schemaName = schemaIDToName[desc.GetParentSchemaID()]
+			if descSizes != nil {
+				size := descSizes[desc.GetID()]
+				o.size = &size
+			}
+		}
+		objects[desc.GetID()] = o
+	}
+	return objects
+}
+
+// diffBackupChains compares the descriptors and the per-table entry counts of
+// two chains of backups. Descriptors are matched by ID, so that renamed
+// objects are reported as altered rather than as dropped and added.
+func diffBackupChains(ctx context.Context, before, after []BackupManifest) []tree.Datums {
+	beforeObjects := backupDiffObjects(ctx, before)
+	afterObjects := backupDiffObjects(ctx, after)
+
+	var ids []descpb.ID
+	for id := range beforeObjects {
+		ids = append(ids, id)
+	}
+	for id := range afterObjects {
+		if _, ok := beforeObjects[id]; !ok {
+			ids = append(ids, id)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	rows := make([]tree.Datums, 0, len(ids))
+	for _, id := range ids {
+		b, a := beforeObjects[id], afterObjects[id]
+		var change string
+		var details []string
+		o := a
+		switch {
+		case b == nil:
+			change = backupDiffAdded
+		case a == nil:
+			change, o = backupDiffDropped, b
+		default:
+			details = describeBackupDiffChanges(b, a)
+			change = backupDiffUnchanged
+			if len(details) > 0 || a.desc.GetVersion() != b.desc.GetVersion() {
+				change = backupDiffAltered
+			}
+		}
+
+		objectType := "unknown"
+		rowsBefore, rowsAfter := tree.DNull, tree.DNull
+		sizeBefore, sizeAfter := tree.DNull, tree.DNull
+		switch o.desc.(type) {
+		case catalog.DatabaseDescriptor:
+			objectType = "database"
+		case catalog.SchemaDescriptor:
+			objectType = "schema"
+		case catalog.TypeDescriptor:
+			objectType = "type"
+		case catalog.TableDescriptor:
+			objectType = "table"
+			if b != nil && b.size != nil {
+				rowsBefore = tree.NewDInt(tree.DInt(b.size.Rows))
+				sizeBefore = tree.NewDInt(tree.DInt(b.size.DataSize))
+			}
+			if a != nil && a.size != nil {
+				rowsAfter = tree.NewDInt(tree.DInt(a.size.Rows))
+				sizeAfter = tree.NewDInt(tree.DInt(a.size.DataSize))
+			}
+		}
+
+		rows = append(rows, tree.Datums{
+			nullIfEmpty(o.dbName),
+			nullIfEmpty(o.schemaName),
+			tree.NewDString(o.desc.GetName()),
+			tree.NewDString(objectType),
+			tree.NewDString(change),
+			nullIfEmpty(strings.Join(details, "; ")),
+			rowsBefore,
+			rowsAfter,
+			sizeBefore,
+			sizeAfter,
+		})
+	}
+	return rows
+}
+
+// describeBackupDiffChanges lists the differences between two versions of the
+// same descriptor which SHOW BACKUP DIFF knows how to describe: renames and,
+// for tables, added, dropped and renamed columns and indexes.
+func describeBackupDiffChanges(before, after *backupDiffObject) []string {
+	var changes []string
+	if beforeName := before.qualifiedName(); beforeName != after.qualifiedName() {
+		changes = append(changes, fmt.Sprintf("renamed from %s", beforeName))
+	}
+	beforeTable, ok := before.desc.(catalog.TableDescriptor)
+	if !ok {
+		return changes
+	}
+	afterTable, ok := after.desc.(catalog.TableDescriptor)
+	if !ok {
+		return changes
+	}
+
+	var beforeCols, afterCols []backupDiffElement
+	for _, col := range beforeTable.GetPublicColumns() {
+		beforeCols = append(beforeCols, backupDiffElement{id: uint32(col.ID), name: col.Name})
+	}
+	for _, col := range afterTable.GetPublicColumns() {
+		afterCols = append(afterCols, backupDiffElement{id: uint32(col.ID), name: col.Name})
+	}
+	changes = append(changes, describeBackupDiffElements("column", beforeCols, afterCols)...)
+
+	var beforeIdxs, afterIdxs []backupDiffElement
+	for _, idx := range beforeTable.AllNonDropIndexes() {
+		beforeIdxs = append(beforeIdxs, backupDiffElement{id: uint32(idx.ID), name: idx.Name})
+	}
+	for _, idx := range afterTable.AllNonDropIndexes() {
+		afterIdxs = append(afterIdxs, backupDiffElement{id: uint32(idx.ID), name: idx.Name})
+	}
+	changes = append(changes, describeBackupDiffElements("index", beforeIdxs, afterIdxs)...)
+	return changes
+}
+
+// backupDiffElement is a column or an index of a table compared by SHOW BACKUP
+// DIFF.
+type backupDiffElement struct {
+	id   uint32
+	name string
+}
+
+// describeBackupDiffElements lists the elements of the given kind which were
+// added, dropped or renamed between two versions of a table.
+func describeBackupDiffElements(kind string, before, after []backupDiffElement) []string {
+	beforeNames := make(map[uint32]string, len(before))
+	for _, e := range before {
+		beforeNames[e.id] = e.name
+	}
+	afterIDs := make(map[uint32]struct{}, len(after))
+	var added, dropped, renamed []string
+	for _, e := range after {
+		afterIDs[e.id] = struct{}{}
+		if name, ok := beforeNames[e.id]; !ok {
+			added = append(added, e.name)
+		} else if name != e.name {
+			renamed = append(renamed, fmt.Sprintf("%s to %s", name, e.name))
+		}
+	}
+	for _, e := range before {
+		if _, ok := afterIDs[e.id]; !ok {
+			dropped = append(dropped, e.name)
+		}
+	}
+
+	var changes []string
+	if len(added) > 0 {
+		changes = append(changes, fmt.Sprintf("added %s %s", kind, strings.Join(added, ", ")))
+	}
+	if len(dropped) > 0 {
+		changes = append(changes, fmt.Sprintf("dropped %s %s", kind, strings.Join(dropped, ", ")))
+	}
+	if len(renamed) > 0 {
+		changes = append(changes, fmt.Sprintf("renamed %s %s", kind, strings.Join(renamed, ", ")))
+	}
+	return changes
+}
+
 // showBackupPlanHook implements PlanHookFn.
 func showBackupsInCollectionPlanHook(
 	ctx context.Context, backup *tree.ShowBackup, p sql.PlanHookState,
@@ -451,3 +451,91 @@ func TestShowBackupTenants(t *testing.T) {
 		{"/Tenant/10", "/Tenant/11"},
 	}, res)
 }
+
+func TestShowBackupDiff(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	const numAccounts = 11
+	_, _, sqlDB, _, cleanupFn := BackupRestoreTestSetup(t, singleNode, numAccounts, InitNone)
+	defer cleanupFn()
+
+	sqlDB.Exec(t, `
+CREATE TABLE data.t1 (a INT, b INT);
+INSERT INTO data.t1 VALUES (1, 1), (2, 2), (3, 3);
+CREATE TABLE data.t2 (a INT);
+CREATE TABLE data.t3 (a INT);
+`)
+	const before, after = LocalFoo + "/before", LocalFoo + "/after"
+	sqlDB.Exec(t, `BACKUP DATABASE data TO $1`, before)
+
+	sqlDB.Exec(t, `
+ALTER TABLE data.t1 ADD COLUMN c INT;
+ALTER TABLE data.t1 DROP COLUMN b;
+ALTER TABLE data.t1 RENAME COLUMN a TO x;
+CREATE INDEX idx ON data.t1 (x);
+INSERT INTO data.t1 VALUES (4, 4), (5, 5);
+DROP TABLE data.t2;
+ALTER TABLE data.t3 RENAME TO data.t3_new;
+CREATE TABLE data.t4 (a INT);
+`)
+	sqlDB.Exec(t, `BACKUP DATABASE data TO $1`, after)
+
+	const query = `
+SELECT
+  database_name, parent_schema_name, object_name, change, details, rows_before, rows_after
+FROM
+  [SHOW BACKUP DIFF $1 AND $2]
+WHERE
+  object_type = 'table'
+ORDER BY object_name`
+	require.Equal(t, [][]string{
+		{"data", "public", "bank", "unchanged", "NULL", strconv.Itoa(numAccounts), strconv.Itoa(numAccounts)},
+		{"data", "public", "t1", "altered",
+			"added column c; dropped column b; renamed column a to x; added index idx", "3", "5"},
+		{"data", "public", "t2", "dropped", "NULL", "0", "NULL"},
+		{"data", "public", "t3_new", "altered", "renamed from data.public.t3", "0", "0"},
+		{"data", "public", "t4", "added", "NULL", "NULL", "0"},
+	}, sqlDB.QueryStr(t, query, before, after))
+
+	// Comparing the backups the other way around reverses the changes.
+	require.Equal(t, [][]string{
+		{"data", "public", "bank", "unchanged", "NULL", strconv.Itoa(numAccounts), strconv.Itoa(numAccounts)},
+		{"data", "public", "t1", "altered",
+			"added column b; dropped column c; renamed column x to a; dropped index idx", "5", "3"},
+		{"data", "public", "t2", "added", "NULL", "NULL", "0"},
+		{"data", "public", "t3", "altered", "renamed from data.public.t3_new", "0", "0"},
+		{"data", "public", "t4", "dropped", "NULL", "0", "NULL"},
+	}, sqlDB.QueryStr(t, query, after, before))
+
+	sqlDB.ExpectErr(t, `file appears encrypted|could not find or read encryption information`,
+		`SHOW BACKUP DIFF $1 AND $2 WITH encryption_passphrase = 'abc'`, before, after)
+
+	// The incremental backups appended to a full backup are read along with it,
+	// and the sizes add up the chain. The sizes of an incremental backup whose
+	// full backup is at another location are unknown.
+	const collection, incremental = LocalFoo + "/collection", LocalFoo + "/incremental"
+	sqlDB.Exec(t, `BACKUP DATABASE data INTO $1`, collection)
+	var subdir string
+	sqlDB.QueryRow(t, `SELECT path FROM [SHOW BACKUPS IN $1]`, collection).Scan(&subdir)
+	sqlDB.Exec(t, `INSERT INTO data.t4 VALUES (1), (2)`)
+	sqlDB.Exec(t, `BACKUP DATABASE data INTO LATEST IN $1`, collection)
+	sqlDB.Exec(t, `BACKUP DATABASE data TO $1 INCREMENTAL FROM $2`, incremental, after)
+
+	const sizeQuery = `
+SELECT
+  object_name, change, rows_before, rows_after
+FROM
+  [SHOW BACKUP DIFF $1 AND $2]
+WHERE
+  object_name IN ('t1', 't4')
+ORDER BY object_name`
+	require.Equal(t, [][]string{
+		{"t1", "unchanged", "5", "5"},
+		{"t4", "unchanged", "0", "2"},
+	}, sqlDB.QueryStr(t, sizeQuery, after, collection+"/"+subdir))
+	require.Equal(t, [][]string{
+		{"t1", "unchanged", "5", "NULL"},
+		{"t4", "unchanged", "0", "NULL"},
+	}, sqlDB.QueryStr(t, sizeQuery, after, incremental))
+}
@@ -0,0 +1,301 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package backupccl
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/cockroachdb/cockroach/pkg/jobs"
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/kv"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/sql/execinfrapb"
+	"github.com/cockroachdb/cockroach/pkg/sql/physicalplan"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/storage/cloud"
+	"github.com/cockroachdb/cockroach/pkg/storage/cloudimpl"
+	"github.com/cockroachdb/cockroach/pkg/util/ctxgroup"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/cockroach/pkg/util/tracing"
+	"github.com/cockroachdb/errors"
+	"github.com/cockroachdb/logtags"
+)
+
+// maxReportedVerifyBackupProblems is the maximum number of bad files that are
+// listed in the error of a failed VERIFY BACKUP job.
+const maxReportedVerifyBackupProblems = 10
+
+type verifyBackupResumer struct {
+	job *jobs.Job
+}
+
+var _ jobs.Resumer = &verifyBackupResumer{}
+
+// verifyBackupResult summarizes the files checked by a VERIFY BACKUP job.
+type verifyBackupResult struct {
+	files    int64
+	bytes    int64
+	problems []string
+}
+
+// Resume is part of the jobs.Resumer interface.
+func (r *verifyBackupResumer) Resume(
+	ctx context.Context, execCtx interface{}, resultsCh chan<- tree.Datums,
+) error {
+	details := r.job.Details().(jobspb.VerifyBackupDetails)
+	p := execCtx.(sql.JobExecContext)
+
+	backupManifests, err := loadBackupManifests(ctx, details.URIs,
+		p.User(), p.ExecCfg().DistSQLSrv.ExternalStorageFromURI, details.Encryption)
+	if err != nil {
+		return err
+	}
+	files, err := makeVerifyBackupFiles(backupManifests, details.BackupLocalityInfo, p.User())
+	if err != nil {
+		return err
+	}
+
+	res, err := verifyBackupFiles(ctx, p, r.job, files, details.Encryption, details.CheckContents)
+	if err != nil {
+		return err
+	}
+	if len(res.problems) > 0 {
+		msg := strings.Join(res.problems, "; ")
+		if len(res.problems) > maxReportedVerifyBackupProblems {
+			msg = fmt.Sprintf("%s; and %d more",
+				strings.Join(res.problems[:maxReportedVerifyBackupProblems], "; "),
+				len(res.problems)-maxReportedVerifyBackupProblems)
+		}
+		return errors.Newf("%d of %d backup files failed verification: %s",
+			len(res.problems), res.files, msg)
+	}
+
+	resultsCh <- tree.Datums{
+		tree.NewDInt(tree.DInt(*r.job.ID())),
+		tree.NewDString(string(jobs.StatusSucceeded)),
+		tree.NewDInt(tree.DInt(res.files)),
+		tree.NewDInt(tree.DInt(res.bytes)),
+	}
+	return nil
+}
+
+// OnFailOrCancel is part of the jobs.Resumer interface. There is nothing to
+// clean up, as verifying a backup does not write anything.
+func (r *verifyBackupResumer) OnFailOrCancel(context.Context, interface{}) error {
+	return nil
+}
+
+// makeVerifyBackupFiles lists every file referenced by the given chain of
+// backup manifests, resolving the location of the files of partitioned backups
+// the same way RESTORE does.
+func makeVerifyBackupFiles(
+	backupManifests []BackupManifest,
+	backupLocalityInfo []jobspb.RestoreDetails_BackupLocalityInfo,
+	user security.SQLUsername,
+) ([]execinfrapb.VerifyBackupDataSpec_File, error) {
+	var files []execinfrapb.VerifyBackupDataSpec_File
+	for i, b := range backupManifests {
+		var storesByLocalityKV map[string]roachpb.ExternalStorage
+		if backupLocalityInfo != nil && backupLocalityInfo[i].URIsByOriginalLocalityKV != nil {
+			storesByLocalityKV = make(map[string]roachpb.ExternalStorage)
+			for kv, uri := range backupLocalityInfo[i].URIsByOriginalLocalityKV {
+				conf, err := cloudimpl.ExternalStorageConfFromURI(uri, user)
+				if err != nil {
+					return nil, err
+				}
+				storesByLocalityKV[kv] = conf
+			}
+		}
+		for _, f := range b.Files {
+			dir := b.Dir
+			if storesByLocalityKV != nil {
+				if newDir, ok := storesByLocalityKV[f.LocalityKV]; ok {
+					dir = newDir
+				}
+			}
+			files = append(files, execinfrapb.VerifyBackupDataSpec_File{
+				Dir:      dir,
+				Path:     f.Path,
+				Sha512:   f.Sha512,
+				Span:     f.Span,
+				FileSize: f.FileSize,
+			})
+		}
+	}
+	return files, nil
+}
+
+// verifyBackupFiles runs a distributed flow that checks the given files and
+// gathers the results, updating the progress of the job as files are checked.
+func verifyBackupFiles(
+	ctx context.Context,
+	execCtx sql.JobExecContext,
+	job *jobs.Job,
+	files []execinfrapb.VerifyBackupDataSpec_File,
+	encryption *jobspb.BackupEncryptionOptions,
+	checkContents bool,
+) (verifyBackupResult, error) {
+	var res verifyBackupResult
+	if len(files) == 0 {
+		return res, nil
+	}
+
+	g := ctxgroup.WithContext(ctx)
+	fileFinishedCh := make(chan struct{}, len(files)) // enough buffer to never block
+	progressLogger := jobs.NewChunkProgressLogger(job, len(files), job.FractionCompleted(), nil)
+	g.GoCtx(func(ctx context.Context) error {
+		ctx, progressSpan := tracing.ChildSpan(ctx, "progress-log")
+		defer progressSpan.Finish()
+		return progressLogger.Loop(ctx, fileFinishedCh)
+	})
+
+	var mu syncutil.Mutex
+	rowFn := func(_ context.Context, row tree.Datums) error {
+		mu.Lock()
+		defer mu.Unlock()
+		res.files++
+		res.bytes += int64(tree.MustBeDInt(row[1]))
+		if row[2] != tree.DNull {
+			res.problems = append(res.problems,
+				fmt.Sprintf("%s: %s", tree.MustBeDString(row[0]), tree.MustBeDString(row[2])))
+		}
+		fileFinishedCh <- struct{}{}
+		return nil
+	}
+
+	g.GoCtx(func(ctx context.Context) error {
+		defer close(fileFinishedCh)
+		return distVerifyBackup(ctx, execCtx, files, encryption, checkContents, rowFn)
+	})
+
+	if err := g.Wait(); err != nil {
+		return verifyBackupResult{}, errors.Wrapf(err, "verifying %d backup files", len(files))
+	}
+	return res, nil
+}
+
+// distVerifyBackup plans a one stage distSQL flow for a VERIFY BACKUP, which
+// spreads the files to check across all the compatible nodes in the cluster.
+// rowFn is called with the row emitted for every file that was checked.
+func distVerifyBackup(
+	ctx context.Context,
+	execCtx sql.JobExecContext,
+	files []execinfrapb.VerifyBackupDataSpec_File,
+	encryption *jobspb.BackupEncryptionOptions,
+	checkContents bool,
+	rowFn func(context.Context, tree.Datums) error,
+) error {
+	ctx = logtags.AddTag(ctx, "verify-backup-distsql", nil)
+	var noTxn *kv.Txn
+
+	dsp := execCtx.DistSQLPlanner()
+	evalCtx := execCtx.ExtendedEvalContext()
+
+	if encryption != nil && encryption.Mode == jobspb.EncryptionMode_KMS {
+		kms, err := cloud.KMSFromURI(encryption.KMSInfo.Uri, &backupKMSEnv{
+			settings: execCtx.ExecCfg().Settings,
+			conf:     &execCtx.ExecCfg().ExternalIODirConfig,
+		})
+		if err != nil {
+			return err
+		}
+
+		encryption.Key, err = kms.Decrypt(ctx, encryption.KMSInfo.EncryptedDataKey)
+		if err != nil {
+			return errors.Wrap(err,
+				"failed to decrypt data key before starting VerifyBackupDataProcessor")
+		}
+	}
+	var fileEncryption *roachpb.FileEncryptionOptions
+	if encryption != nil {
+		fileEncryption = &roachpb.FileEncryptionOptions{Key: encryption.Key}
+	}
+
+	planCtx, _, err := dsp.SetupAllNodesPlanning(ctx, evalCtx, execCtx.ExecCfg())
+	if err != nil {
+		return err
+	}
+	nodes := getAllCompatibleNodes(planCtx)
+
+	specs := makeVerifyBackupDataSpecs(nodes, files, fileEncryption, checkContents, execCtx.User())
+
+	p := planCtx.NewPhysicalPlan()
+
+	// Setup a one-stage plan with one proc per input spec.
+	corePlacement := make([]physicalplan.ProcessorCorePlacement, 0, len(specs))
+	for node, spec := range specs {
+		corePlacement = append(corePlacement, physicalplan.ProcessorCorePlacement{
+			NodeID: node,
+			Core:   execinfrapb.ProcessorCoreUnion{VerifyBackupData: spec},
+		})
+	}
+	p.AddNoInputStage(corePlacement, execinfrapb.PostProcessSpec{}, verifyBackupOutputTypes, execinfrapb.Ordering{})
+	p.PlanToStreamColMap = []int{0, 1, 2}
+
+	dsp.FinalizePlan(planCtx, p)
+
+	rowResultWriter := sql.NewCallbackResultWriter(rowFn)
+	recv := sql.MakeDistSQLReceiver(
+		ctx,
+		rowResultWriter,
+		tree.Rows,
+		nil,   /* rangeCache */
+		noTxn, /* txn - the flow does not read or write the database */
+		func(ts hlc.Timestamp) {},
+		evalCtx.Tracing,
+	)
+	defer recv.Release()
+
+	// Copy the evalCtx, as dsp.Run() might change it.
+	evalCtxCopy := *evalCtx
+	dsp.Run(planCtx, noTxn, p, recv, &evalCtxCopy, nil /* finishedSetupFn */)()
+	return rowResultWriter.Err()
+}
+
+// makeVerifyBackupDataSpecs returns a map from nodeID to the VerifyBackupData
+// spec that should be planned on that node. It round-robin distributes the
+// files amongst the given nodes.
+func makeVerifyBackupDataSpecs(
+	nodes []roachpb.NodeID,
+	files []execinfrapb.VerifyBackupDataSpec_File,
+	encryption *roachpb.FileEncryptionOptions,
+	checkContents bool,
+	user security.SQLUsername,
+) map[roachpb.NodeID]*execinfrapb.VerifyBackupDataSpec {
+	specsByNodes := make(map[roachpb.NodeID]*execinfrapb.VerifyBackupDataSpec)
+	for i := range files {
+		node := nodes[i%len(nodes)]
+		spec, ok := specsByNodes[node]
+		if !ok {
+			spec = &execinfrapb.VerifyBackupDataSpec{
+				Encryption:    encryption,
+				CheckContents: checkContents,
+				UserProto:     user.EncodeProto(),
+			}
+			specsByNodes[node] = spec
+		}
+		spec.Files = append(spec.Files, files[i])
+	}
+	return specsByNodes
+}
+
+func init() {
+	jobs.RegisterConstructor(
+		jobspb.TypeVerifyBackup,
+		func(job *jobs.Job, _ *cluster.Settings) jobs.Resumer {
+			return &verifyBackupResumer{job: job}
+		},
+	)
+}
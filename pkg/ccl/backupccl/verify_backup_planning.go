@@ -0,0 +1,291 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package backupccl
+
+import (
+	"context"
+	"net/url"
+	"path"
+
+	"github.com/cockroachdb/cockroach/pkg/ccl/storageccl"
+	"github.com/cockroachdb/cockroach/pkg/ccl/utilccl"
+	"github.com/cockroachdb/cockroach/pkg/jobs"
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/kv"
+	"github.com/cockroachdb/cockroach/pkg/server/telemetry"
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/colinfo"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/storage/cloud"
+	"github.com/cockroachdb/cockroach/pkg/storage/cloudimpl"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/tracing"
+	"github.com/cockroachdb/errors"
+)
+
+const (
+	verifyBackupOptCheckContents = "check_contents"
+	verifyBackupOptDetached      = "detached"
+)
+
+var verifyBackupOptionExpectValues = map[string]sql.KVStringOptValidate{
+	backupOptEncPassphrase:       sql.KVStringOptRequireValue,
+	backupOptEncKMS:              sql.KVStringOptRequireValue,
+	verifyBackupOptCheckContents: sql.KVStringOptRequireNoValue,
+	verifyBackupOptDetached:      sql.KVStringOptRequireNoValue,
+}
+
+// verifyBackupResultHeader is the header of the result of a VERIFY BACKUP
+// that waits for its job to complete.
+var verifyBackupResultHeader = colinfo.ResultColumns{
+	{Name: "job_id", Typ: types.Int},
+	{Name: "status", Typ: types.String},
+	{Name: "files", Typ: types.Int},
+	{Name: "bytes", Typ: types.Int},
+}
+
+// verifyBackupPlanHook implements sql.PlanHookFn.
+func verifyBackupPlanHook(
+	ctx context.Context, stmt tree.Statement, p sql.PlanHookState,
+) (sql.PlanHookRowFn, colinfo.ResultColumns, []sql.PlanNode, bool, error) {
+	verifyStmt, ok := stmt.(*tree.VerifyBackup)
+	if !ok {
+		return nil, nil, nil, false, nil
+	}
+
+	if err := p.RequireAdminRole(ctx, "VERIFY BACKUP"); err != nil {
+		return nil, nil, nil, false, err
+	}
+
+	fromFns := make([]func() ([]string, error), len(verifyStmt.From))
+	for i := range verifyStmt.From {
+		fromFn, err := p.TypeAsStringArray(ctx, tree.Exprs(verifyStmt.From[i]), "VERIFY BACKUP")
+		if err != nil {
+			return nil, nil, nil, false, err
+		}
+		fromFns[i] = fromFn
+	}
+
+	subdirFn := func() (string, error) { return "", nil }
+	if verifyStmt.Subdir != nil {
+		var err error
+		subdirFn, err = p.TypeAsString(ctx, verifyStmt.Subdir, "VERIFY BACKUP")
+		if err != nil {
+			return nil, nil, nil, false, err
+		}
+	}
+
+	optsFn, err := p.TypeAsStringOpts(ctx, verifyStmt.Options, verifyBackupOptionExpectValues)
+	if err != nil {
+		return nil, nil, nil, false, err
+	}
+	opts, err := optsFn()
+	if err != nil {
+		return nil, nil, nil, false, err
+	}
+	_, detached := opts[verifyBackupOptDetached]
+
+	fn := func(ctx context.Context, _ []sql.PlanNode, resultsCh chan<- tree.Datums) error {
+		// TODO(dan): Move this span into sql.
+		ctx, span := tracing.ChildSpan(ctx, stmt.StatementTag())
+		defer span.Finish()
+
+		if !(p.ExtendedEvalContext().TxnImplicit || detached) {
+			return errors.Errorf("VERIFY BACKUP cannot be used inside a transaction without DETACHED option")
+		}
+
+		subdir, err := subdirFn()
+		if err != nil {
+			return err
+		}
+
+		from := make([][]string, len(fromFns))
+		for i := range fromFns {
+			from[i], err = fromFns[i]()
+			if err != nil {
+				return err
+			}
+		}
+		if subdir != "" {
+			if len(from) != 1 {
+				return errors.Errorf("VERIFY BACKUP FROM ... IN can only by used against a single collection path (per-locality)")
+			}
+			for i := range from[0] {
+				parsed, err := url.Parse(from[0][i])
+				if err != nil {
+					return err
+				}
+				parsed.Path = path.Join(parsed.Path, subdir)
+				from[0][i] = parsed.String()
+			}
+		}
+
+		return doVerifyBackupPlan(ctx, p, from, opts, detached, resultsCh)
+	}
+
+	if detached {
+		return fn, utilccl.DetachedJobExecutionResultHeader, nil, false, nil
+	}
+	return fn, verifyBackupResultHeader, nil, false, nil
+}
+
+func doVerifyBackupPlan(
+	ctx context.Context,
+	p sql.PlanHookState,
+	from [][]string,
+	opts map[string]string,
+	detached bool,
+	resultsCh chan<- tree.Datums,
+) error {
+	if len(from) < 1 || len(from[0]) < 1 {
+		return errors.New("invalid base backup specified")
+	}
+	baseStores := make([]cloud.ExternalStorage, len(from[0]))
+	for i := range from[0] {
+		store, err := p.ExecCfg().DistSQLSrv.ExternalStorageFromURI(ctx, from[0][i], p.User())
+		if err != nil {
+			return errors.Wrapf(err, "failed to open backup storage location")
+		}
+		defer store.Close()
+		baseStores[i] = store
+	}
+
+	var encryption *jobspb.BackupEncryptionOptions
+	var kmsURIs []string
+	if passphrase, ok := opts[backupOptEncPassphrase]; ok {
+		encOpts, err := readEncryptionOptions(ctx, baseStores[0])
+		if err != nil {
+			return err
+		}
+		encryptionKey := storageccl.GenerateKey([]byte(passphrase), encOpts.Salt)
+		encryption = &jobspb.BackupEncryptionOptions{Mode: jobspb.EncryptionMode_Passphrase,
+			Key: encryptionKey}
+	} else if kms, ok := opts[backupOptEncKMS]; ok {
+		encOpts, err := readEncryptionOptions(ctx, baseStores[0])
+		if err != nil {
+			return err
+		}
+		kmsURIs = []string{kms}
+		env := &backupKMSEnv{p.ExecCfg().Settings, &p.ExecCfg().ExternalIODirConfig}
+		defaultKMSInfo, err := validateKMSURIsAgainstFullBackup(kmsURIs,
+			newEncryptedDataKeyMapFromProtoMap(encOpts.EncryptedDataKeyByKMSMasterKeyID), env)
+		if err != nil {
+			return err
+		}
+		encryption = &jobspb.BackupEncryptionOptions{
+			Mode:    jobspb.EncryptionMode_KMS,
+			KMSInfo: defaultKMSInfo}
+	}
+
+	// Resolving the manifests checks up front that every backup in the chain,
+	// and the locality-specific parts of partitioned backups, can be read.
+	defaultURIs, _, localityInfo, err := resolveBackupManifests(
+		ctx, baseStores, p.ExecCfg().DistSQLSrv.ExternalStorageFromURI, from,
+		hlc.Timestamp{} /* endTime */, encryption, p.User(),
+	)
+	if err != nil {
+		return err
+	}
+
+	description, err := verifyBackupJobDescription(p, from, opts, kmsURIs)
+	if err != nil {
+		return err
+	}
+
+	_, checkContents := opts[verifyBackupOptCheckContents]
+	jr := jobs.Record{
+		Description: description,
+		Username:    p.User(),
+		Details: jobspb.VerifyBackupDetails{
+			URIs:               defaultURIs,
+			BackupLocalityInfo: localityInfo,
+			Encryption:         encryption,
+			CheckContents:      checkContents,
+		},
+		Progress: jobspb.VerifyBackupProgress{},
+	}
+
+	if detached {
+		// When running in detached mode, we simply create the job record.
+		// We do not wait for the job to finish.
+		aj, err := p.ExecCfg().JobRegistry.CreateAdoptableJobWithTxn(
+			ctx, jr, p.ExtendedEvalContext().Txn)
+		if err != nil {
+			return err
+		}
+		resultsCh <- tree.Datums{tree.NewDInt(tree.DInt(*aj.ID()))}
+		telemetry.Count("verify-backup.total.started")
+		return nil
+	}
+
+	var sj *jobs.StartableJob
+	if err := p.ExecCfg().DB.Txn(ctx, func(ctx context.Context, txn *kv.Txn) (err error) {
+		sj, err = p.ExecCfg().JobRegistry.CreateStartableJobWithTxn(ctx, jr, txn, resultsCh)
+		return err
+	}); err != nil {
+		if sj != nil {
+			if cleanupErr := sj.CleanupOnRollback(ctx); cleanupErr != nil {
+				log.Warningf(ctx, "failed to cleanup StartableJob: %v", cleanupErr)
+			}
+		}
+		return err
+	}
+
+	telemetry.Count("verify-backup.total.started")
+	return sj.Run(ctx)
+}
+
+// verifyBackupJobDescription returns the statement of a VERIFY BACKUP job with
+// its URIs sanitized and its secret options redacted.
+func verifyBackupJobDescription(
+	p sql.PlanHookState, from [][]string, opts map[string]string, kmsURIs []string,
+) (string, error) {
+	v := &tree.VerifyBackup{
+		From: make([]tree.StringOrPlaceholderOptList, len(from)),
+	}
+	for i, backup := range from {
+		v.From[i] = make(tree.StringOrPlaceholderOptList, len(backup))
+		for j, uri := range backup {
+			sf, err := cloudimpl.SanitizeExternalStorageURI(uri, nil /* extraParams */)
+			if err != nil {
+				return "", err
+			}
+			v.From[i][j] = tree.NewDString(sf)
+		}
+	}
+
+	if _, ok := opts[backupOptEncPassphrase]; ok {
+		v.Options = append(v.Options, tree.KVOption{
+			Key: backupOptEncPassphrase, Value: tree.NewDString("redacted"),
+		})
+	}
+	for _, uri := range kmsURIs {
+		redactedURI, err := cloudimpl.RedactKMSURI(uri)
+		if err != nil {
+			return "", err
+		}
+		v.Options = append(v.Options, tree.KVOption{
+			Key: backupOptEncKMS, Value: tree.NewDString(redactedURI),
+		})
+	}
+	for _, key := range []string{verifyBackupOptCheckContents, verifyBackupOptDetached} {
+		if _, ok := opts[key]; ok {
+			v.Options = append(v.Options, tree.KVOption{Key: tree.Name(key)})
+		}
+	}
+
+	ann := p.ExtendedEvalContext().Annotations
+	return tree.AsStringWithFQNames(v, ann), nil
+}
+
+func init() {
+	sql.AddPlanHook(verifyBackupPlanHook)
+}
@@ -0,0 +1,152 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package backupccl
+
+import (
+	gosql "database/sql"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifyBackup(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	const numAccounts = 100
+	_, tc, sqlDB, dir, cleanupFn := BackupRestoreTestSetup(t, MultiNode, numAccounts, InitNone)
+	defer cleanupFn()
+
+	// walkSSTs calls fn with the path of every data file of the backup in the
+	// given subdirectory of LocalFoo.
+	walkSSTs := func(t *testing.T, subdir string, fn func(path string) error) {
+		t.Helper()
+		require.NoError(t, filepath.Walk(filepath.Join(dir, "foo", subdir),
+			func(path string, info os.FileInfo, err error) error {
+				if err != nil {
+					return err
+				}
+				if !strings.HasSuffix(path, ".sst") {
+					return nil
+				}
+				return fn(path)
+			}))
+	}
+
+	t.Run("valid", func(t *testing.T) {
+		const full, inc = LocalFoo + "/valid", LocalFoo + "/valid-inc"
+		sqlDB.Exec(t, `BACKUP DATABASE data TO $1`, full)
+		sqlDB.Exec(t, `UPDATE data.bank SET balance = balance + 1 WHERE id < 10`)
+		sqlDB.Exec(t, `BACKUP DATABASE data TO $1 INCREMENTAL FROM $2`, inc, full)
+
+		var fullFiles, fullBytes int
+		walkSSTs(t, "valid", func(path string) error {
+			info, err := os.Stat(path)
+			if err != nil {
+				return err
+			}
+			fullFiles++
+			fullBytes += int(info.Size())
+			return nil
+		})
+		var jobID int64
+		var status string
+		var files, bytes int
+		sqlDB.QueryRow(t, `VERIFY BACKUP FROM $1`, full).Scan(&jobID, &status, &files, &bytes)
+		require.Equal(t, "succeeded", status)
+		require.Equal(t, fullFiles, files)
+		require.Equal(t, fullBytes, bytes)
+
+		// Verifying an incremental backup checks the whole chain.
+		sqlDB.QueryRow(t, `VERIFY BACKUP FROM $1, $2 WITH check_contents`, full, inc).Scan(
+			&jobID, &status, &files, &bytes)
+		require.Equal(t, "succeeded", status)
+		require.Greater(t, files, fullFiles)
+
+		var description string
+		sqlDB.QueryRow(t, `SELECT description FROM [SHOW JOBS] WHERE job_id = $1`, jobID).Scan(&description)
+		require.Contains(t, description, "WITH check_contents")
+	})
+
+	t.Run("encrypted", func(t *testing.T) {
+		const backup = LocalFoo + "/encrypted"
+		sqlDB.Exec(t, `BACKUP DATABASE data TO $1 WITH encryption_passphrase = 'abcdefg'`, backup)
+		sqlDB.ExpectErr(t, "file appears encrypted",
+			`VERIFY BACKUP FROM $1`, backup)
+		sqlDB.ExpectErr(t, "cipher: message authentication failed",
+			`VERIFY BACKUP FROM $1 WITH encryption_passphrase = 'wrong'`, backup)
+		var jobID int64
+		var status string
+		var files, bytes int
+		sqlDB.QueryRow(t, `VERIFY BACKUP FROM $1 WITH encryption_passphrase = 'abcdefg', check_contents`,
+			backup).Scan(&jobID, &status, &files, &bytes)
+		require.Equal(t, "succeeded", status)
+
+		var description string
+		sqlDB.QueryRow(t, `SELECT description FROM [SHOW JOBS] WHERE job_id = $1`, jobID).Scan(&description)
+		require.Contains(t, description, "encryption_passphrase = 'redacted'")
+	})
+
+	t.Run("corrupted", func(t *testing.T) {
+		const backup = LocalFoo + "/corrupted"
+		sqlDB.Exec(t, `BACKUP DATABASE data TO $1`, backup)
+
+		// Flip a byte in the first data file and remove the second one.
+		var corrupted, removed bool
+		walkSSTs(t, "corrupted", func(path string) error {
+			switch {
+			case !corrupted:
+				corrupted = true
+				contents, err := ioutil.ReadFile(path)
+				if err != nil {
+					return err
+				}
+				contents[len(contents)/2] ^= 0xff
+				return ioutil.WriteFile(path, contents, 0644)
+			case !removed:
+				removed = true
+				return os.Remove(path)
+			}
+			return nil
+		})
+		require.True(t, corrupted && removed, "expected at least two data files")
+
+		sqlDB.ExpectErr(t, `2 of \d+ backup files failed verification: .*`,
+			`VERIFY BACKUP FROM $1`, backup)
+		sqlDB.ExpectErr(t, `checksum mismatch`, `VERIFY BACKUP FROM $1`, backup)
+		sqlDB.ExpectErr(t, `file does not exist`, `VERIFY BACKUP FROM $1`, backup)
+	})
+
+	t.Run("detached", func(t *testing.T) {
+		const backup = LocalFoo + "/detached"
+		sqlDB.Exec(t, `BACKUP DATABASE data TO $1`, backup)
+		db := sqlDB.DB.(*gosql.DB)
+
+		var jobID int64
+		tx, err := db.Begin()
+		require.NoError(t, err)
+		err = tx.QueryRow(`VERIFY BACKUP FROM $1`, backup).Scan(&jobID)
+		require.True(t, testutils.IsError(err,
+			"VERIFY BACKUP cannot be used inside a transaction without DETACHED option"))
+		require.NoError(t, tx.Rollback())
+
+		tx, err = db.Begin()
+		require.NoError(t, err)
+		require.NoError(t, tx.QueryRow(`VERIFY BACKUP FROM $1 WITH detached`, backup).Scan(&jobID))
+		require.NoError(t, tx.Commit())
+		waitForSuccessfulJob(t, tc, jobID)
+	})
+}
@@ -0,0 +1,400 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package changefeedccl
+
+import (
+	"context"
+	"net/url"
+	"sort"
+
+	"github.com/cockroachdb/cockroach/pkg/ccl/backupccl"
+	"github.com/cockroachdb/cockroach/pkg/ccl/changefeedccl/changefeedbase"
+	"github.com/cockroachdb/cockroach/pkg/ccl/utilccl"
+	"github.com/cockroachdb/cockroach/pkg/featureflag"
+	"github.com/cockroachdb/cockroach/pkg/jobs"
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/kv"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/server/telemetry"
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/catalogkv"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/colinfo"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/resolver"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
+	"github.com/cockroachdb/cockroach/pkg/sql/roleoption"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/tracing"
+	"github.com/cockroachdb/cockroach/pkg/util/uuid"
+	"github.com/cockroachdb/errors"
+)
+
+// alterChangefeedOptSink is the pseudo-option with which ALTER CHANGEFEED
+// changes the sink of a changefeed.
+const alterChangefeedOptSink = `sink`
+
+// alterChangefeedOptionExpectValues are the options which ALTER CHANGEFEED can
+// SET. Those which only affect how a changefeed starts can't be changed.
+var alterChangefeedOptionExpectValues = func() map[string]sql.KVStringOptValidate {
+	opts := map[string]sql.KVStringOptValidate{
+		alterChangefeedOptSink: sql.KVStringOptRequireValue,
+	}
+	for k, v := range changefeedbase.ChangefeedOptionExpectValues {
+		switch k {
+		case changefeedbase.OptCursor, changefeedbase.OptInitialScan,
+			changefeedbase.OptNoInitialScan, changefeedbase.OptInitialScanOnly:
+			continue
+		}
+		opts[k] = v
+	}
+	return opts
+}()
+
+func init() {
+	sql.AddPlanHook(alterChangefeedPlanHook)
+}
+
+// alterChangefeedPlanHook implements sql.PlanHookFn.
+func alterChangefeedPlanHook(
+	ctx context.Context, stmt tree.Statement, p sql.PlanHookState,
+) (sql.PlanHookRowFn, colinfo.ResultColumns, []sql.PlanNode, bool, error) {
+	alterChangefeedStmt, ok := stmt.(*tree.AlterChangefeed)
+	if !ok {
+		return nil, nil, nil, false, nil
+	}
+
+	if err := featureflag.CheckEnabled(featureChangefeedEnabled,
+		&p.ExecCfg().Settings.SV,
+		"CHANGEFEED",
+	); err != nil {
+		return nil, nil, nil, false, err
+	}
+
+	typedJobExpr, err := tree.TypeCheckAndRequire(
+		ctx, alterChangefeedStmt.Jobs, p.SemaCtx(), types.Int, `ALTER CHANGEFEED`)
+	if err != nil {
+		return nil, nil, nil, false, err
+	}
+
+	var setOpts tree.KVOptions
+	for _, cmd := range alterChangefeedStmt.Cmds {
+		if cmd, ok := cmd.(*tree.AlterChangefeedSetOption); ok {
+			setOpts = append(setOpts, cmd.Option)
+		}
+	}
+	optsFn, err := p.TypeAsStringOpts(ctx, setOpts, alterChangefeedOptionExpectValues)
+	if err != nil {
+		return nil, nil, nil, false, err
+	}
+
+	fn := func(ctx context.Context, _ []sql.PlanNode, _ chan<- tree.Datums) error {
+		ctx, span := tracing.ChildSpan(ctx, stmt.StatementTag())
+		defer span.Finish()
+
+		ok, err := p.HasRoleOption(ctx, roleoption.CONTROLCHANGEFEED)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return pgerror.New(pgcode.InsufficientPrivilege, "permission denied to alter changefeed")
+		}
+		if err := utilccl.CheckEnterpriseEnabled(
+			p.ExecCfg().Settings, p.ExecCfg().ClusterID(), p.ExecCfg().Organization(), "CHANGEFEED",
+		); err != nil {
+			return err
+		}
+
+		jobIDDatum, err := typedJobExpr.Eval(&p.ExtendedEvalContext().EvalContext)
+		if err != nil {
+			return err
+		}
+		if jobIDDatum == tree.DNull {
+			return errors.New(`changefeed job ID cannot be NULL`)
+		}
+		jobID := int64(tree.MustBeDInt(jobIDDatum))
+
+		opts, err := optsFn()
+		if err != nil {
+			return err
+		}
+
+		txn := p.ExtendedEvalContext().Txn
+		job, err := p.ExecCfg().JobRegistry.LoadJobWithTxn(ctx, jobID, txn)
+		if err != nil {
+			return err
+		}
+		if _, ok := job.Details().(jobspb.ChangefeedDetails); !ok {
+			return errors.Errorf(`job %d is not a changefeed job`, jobID)
+		}
+
+		statementTime := hlc.Timestamp{
+			WallTime: p.ExtendedEvalContext().GetStmtTimestamp().UnixNano(),
+		}
+		return job.WithTxn(txn).Update(ctx, func(
+			txn *kv.Txn, md jobs.JobMetadata, ju *jobs.JobUpdater,
+		) error {
+			if md.Status != jobs.StatusPaused {
+				return errors.Errorf(`job %d is not paused`, jobID)
+			}
+			details := md.Payload.GetChangefeed()
+			changefeedProgress := md.Progress.GetChangefeed()
+
+			prevTargets := details.Targets
+			newTargets, err := alterChangefeedTargets(
+				ctx, p, jobID, *details, alterChangefeedStmt.Cmds, statementTime)
+			if err != nil {
+				return err
+			}
+			details.Targets = newTargets
+
+			if sinkURI, ok := opts[alterChangefeedOptSink]; ok {
+				if sinkURI == `` {
+					return errors.Errorf(`changefeed %d must have a sink`, jobID)
+				}
+				details.SinkURI = sinkURI
+				delete(opts, alterChangefeedOptSink)
+			}
+			if details.Opts == nil {
+				details.Opts = make(map[string]string, len(opts))
+			}
+			for k, v := range opts {
+				details.Opts[k] = v
+			}
+			parsedSink, err := url.Parse(details.SinkURI)
+			if err != nil {
+				return err
+			}
+			if *details, err = validateDetailsForSink(*details, parsedSink); err != nil {
+				return err
+			}
+			if err := checkCanarySink(ctx, p, *details); err != nil {
+				return err
+			}
+
+			var added []descpb.ID
+			for id := range newTargets {
+				if _, ok := prevTargets[id]; !ok {
+					added = append(added, id)
+				}
+			}
+			if len(added) > 0 {
+				if err := scanAddedTargets(
+					ctx, p, txn, jobID, details, md.Progress, changefeedProgress, prevTargets,
+				); err != nil {
+					return err
+				}
+			}
+
+			targetsChanged := len(added) > 0 || len(newTargets) != len(prevTargets)
+			if md.Payload.Description, err = alterChangefeedJobDescription(
+				ctx, p, txn, md.Payload.Description, *details, targetsChanged,
+			); err != nil {
+				return err
+			}
+			if targetsChanged {
+				md.Payload.DescriptorIDs = md.Payload.DescriptorIDs[:0]
+				for id := range newTargets {
+					md.Payload.DescriptorIDs = append(md.Payload.DescriptorIDs, id)
+				}
+				sort.Slice(md.Payload.DescriptorIDs, func(i, j int) bool {
+					return md.Payload.DescriptorIDs[i] < md.Payload.DescriptorIDs[j]
+				})
+			}
+
+			telemetry.Count(`changefeed.alter`)
+			ju.UpdatePayload(md.Payload)
+			ju.UpdateProgress(md.Progress)
+			return nil
+		})
+	}
+
+	return fn, nil, nil, false, nil
+}
+
+// alterChangefeedTargets returns the targets of the changefeed after applying
+// the ADD and DROP commands of an ALTER CHANGEFEED.
+func alterChangefeedTargets(
+	ctx context.Context,
+	p sql.PlanHookState,
+	jobID int64,
+	details jobspb.ChangefeedDetails,
+	cmds tree.AlterChangefeedCmds,
+	statementTime hlc.Timestamp,
+) (jobspb.ChangefeedTargets, error) {
+	targets := make(jobspb.ChangefeedTargets, len(details.Targets))
+	for id, target := range details.Targets {
+		targets[id] = target
+	}
+	resolveTable := func(pattern tree.TablePattern) (catalog.TableDescriptor, error) {
+		if details.DatabaseID != descpb.InvalidID || details.Select != `` {
+			return nil, errors.Errorf(
+				`cannot add or drop tables of changefeed %d, which doesn't target a list of tables`, jobID)
+		}
+		normalized, err := pattern.NormalizeTablePattern()
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := normalized.(*tree.TableName); !ok {
+			return nil, errors.Errorf(`CHANGEFEED cannot target %s`, tree.AsString(pattern))
+		}
+		descs, _, err := backupccl.ResolveTargetsToDescriptors(
+			ctx, p, statementTime, &tree.TargetList{Tables: tree.TablePatterns{normalized}})
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to resolve targets in the ALTER CHANGEFEED stmt")
+		}
+		for _, desc := range descs {
+			if table, ok := desc.(catalog.TableDescriptor); ok {
+				return table, nil
+			}
+		}
+		return nil, errors.Errorf(`%s is not a table`, tree.AsString(pattern))
+	}
+	for _, cmd := range cmds {
+		switch cmd := cmd.(type) {
+		case *tree.AlterChangefeedAddTarget:
+			table, err := resolveTable(cmd.Table)
+			if err != nil {
+				return nil, err
+			}
+			if _, ok := targets[table.GetID()]; ok {
+				return nil, errors.Errorf(`table %q is already a target of changefeed %d`,
+					table.GetName(), jobID)
+			}
+			if err := p.CheckPrivilege(ctx, table, privilege.SELECT); err != nil {
+				return nil, err
+			}
+			targets[table.GetID()] = jobspb.ChangefeedTarget{StatementTimeName: table.GetName()}
+			if err := validateChangefeedTable(targets, table); err != nil {
+				return nil, err
+			}
+		case *tree.AlterChangefeedDropTarget:
+			table, err := resolveTable(cmd.Table)
+			if err != nil {
+				return nil, err
+			}
+			if _, ok := targets[table.GetID()]; !ok {
+				return nil, errors.Errorf(`table %q is not a target of changefeed %d`,
+					table.GetName(), jobID)
+			}
+			delete(targets, table.GetID())
+		}
+	}
+	if len(targets) == 0 {
+		return nil, errors.Errorf(`cannot drop all of the targets of changefeed %d`, jobID)
+	}
+	return targets, nil
+}
+
+// scanAddedTargets arranges for the tables added to a changefeed to get an
+// initial scan when it's resumed, without scanning the previous targets again.
+// The changefeed then resumes without a high-water, with the previous targets
+// already covered by the checkpoint of the initial scan, and its statement time
+// moved up to the high-water it had.
+func scanAddedTargets(
+	ctx context.Context,
+	p sql.PlanHookState,
+	txn *kv.Txn,
+	jobID int64,
+	details *jobspb.ChangefeedDetails,
+	progress *jobspb.Progress,
+	changefeedProgress *jobspb.ChangefeedProgress,
+	prevTargets jobspb.ChangefeedTargets,
+) error {
+	highWater := progress.GetHighWater()
+	if highWater == nil || highWater.IsEmpty() {
+		if len(changefeedProgress.Checkpoint.Spans) > 0 || initialScanFromOptions(details.Opts) {
+			// The initial scan hasn't finished, and will include the added tables.
+			return nil
+		}
+		// The changefeed hasn't started, and will only scan the added tables.
+		changefeedProgress.Checkpoint.Spans = makeTargetSpans(p.ExecCfg().Codec, prevTargets)
+		return nil
+	}
+
+	details.StatementTime = *highWater
+	progress.Progress = &jobspb.Progress_HighWater{HighWater: &hlc.Timestamp{}}
+	changefeedProgress.Checkpoint.Spans = makeTargetSpans(p.ExecCfg().Codec, prevTargets)
+
+	// Protect the data that the scan of the added tables needs, as when creating
+	// a changefeed.
+	pts := p.ExecCfg().ProtectedTimestampProvider
+	if changefeedProgress.ProtectedTimestampRecord != uuid.Nil {
+		if err := pts.Release(ctx, txn, changefeedProgress.ProtectedTimestampRecord); err != nil {
+			return err
+		}
+		changefeedProgress.ProtectedTimestampRecord = uuid.Nil
+	}
+	return createProtectedTimestampRecord(
+		ctx, pts, txn, jobID, details.Targets, details.StatementTime, changefeedProgress)
+}
+
+// makeTargetSpans returns the spans of the tables of the given targets.
+func makeTargetSpans(codec keys.SQLCodec, targets jobspb.ChangefeedTargets) []roachpb.Span {
+	spans := make([]roachpb.Span, 0, len(targets))
+	for id := range targets {
+		tablePrefix := codec.TablePrefix(uint32(id))
+		spans = append(spans, roachpb.Span{Key: tablePrefix, EndKey: tablePrefix.PrefixEnd()})
+	}
+	return spans
+}
+
+// alterChangefeedJobDescription returns the description of an altered
+// changefeed job, which is that of an equivalent CREATE CHANGEFEED statement.
+// If the targets changed, they're listed with their current names.
+func alterChangefeedJobDescription(
+	ctx context.Context,
+	p sql.PlanHookState,
+	txn *kv.Txn,
+	prevDescription string,
+	details jobspb.ChangefeedDetails,
+	targetsChanged bool,
+) (string, error) {
+	prevStmt, err := parser.ParseOne(prevDescription)
+	if err != nil {
+		return "", err
+	}
+	createStmt, ok := prevStmt.AST.(*tree.CreateChangefeed)
+	if !ok {
+		return "", errors.AssertionFailedf(`unexpected changefeed description %q`, prevDescription)
+	}
+	if targetsChanged {
+		codec := p.ExecCfg().Codec
+		var names []tree.TableName
+		for id := range details.Targets {
+			table, err := catalogkv.MustGetTableDescByID(ctx, txn, codec, id)
+			if err != nil {
+				return "", err
+			}
+			db, err := catalogkv.MustGetDatabaseDescByID(ctx, txn, codec, table.GetParentID())
+			if err != nil {
+				return "", err
+			}
+			schema, err := resolver.ResolveSchemaNameByID(
+				ctx, txn, codec, table.GetParentID(), table.GetParentSchemaID())
+			if err != nil {
+				return "", err
+			}
+			names = append(names, tree.MakeTableNameWithSchema(
+				tree.Name(db.GetName()), tree.Name(schema), tree.Name(table.GetName())))
+		}
+		sort.Slice(names, func(i, j int) bool { return names[i].FQString() < names[j].FQString() })
+		createStmt.Targets = tree.TargetList{}
+		for i := range names {
+			createStmt.Targets.Tables = append(createStmt.Targets.Tables, &names[i])
+		}
+	}
+	return changefeedJobDescription(p, createStmt, details.SinkURI, details.Opts)
+}
@@ -0,0 +1,117 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package changefeedccl
+
+import (
+	"context"
+	gosql "database/sql"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/ccl/changefeedccl/cdctest"
+	"github.com/cockroachdb/cockroach/pkg/jobs"
+	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/retry"
+	"github.com/cockroachdb/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAlterChangefeed(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	defer jobs.TestingSetAdoptAndCancelIntervals(10*time.Millisecond, 10*time.Millisecond)()
+
+	testFn := func(t *testing.T, db *gosql.DB, f cdctest.TestFeedFactory) {
+		sqlDB := sqlutils.MakeSQLRunner(db)
+		sqlDB.Exec(t, `CREATE TABLE foo (a INT PRIMARY KEY)`)
+		sqlDB.Exec(t, `CREATE TABLE bar (b INT PRIMARY KEY)`)
+		sqlDB.Exec(t, `INSERT INTO foo VALUES (1)`)
+		sqlDB.Exec(t, `INSERT INTO bar VALUES (1)`)
+
+		foo := feed(t, f, `CREATE CHANGEFEED FOR foo WITH resolved`).(*cdctest.TableFeed)
+		defer closeFeed(t, foo)
+
+		assertPayloads(t, foo, []string{
+			`foo: [1]->{"after": {"a": 1}}`,
+		})
+		// Wait for the high-water mark on the job to be updated after the initial
+		// scan, so that altering the feed doesn't rescan foo.
+		expectResolvedTimestamp(t, foo)
+
+		sqlDB.ExpectErr(t, `job \d+ is not paused`,
+			`ALTER CHANGEFEED $1 ADD bar`, foo.JobID)
+
+		pauseJob := func() {
+			t.Helper()
+			sqlDB.Exec(t, `PAUSE JOB $1`, foo.JobID)
+			// PAUSE JOB only requests the job to be paused. Block until it's paused.
+			opts := retry.Options{
+				InitialBackoff: 1 * time.Millisecond,
+				MaxBackoff:     time.Second,
+				Multiplier:     2,
+			}
+			if err := retry.WithMaxAttempts(context.Background(), opts, 10, func() error {
+				var status string
+				sqlDB.QueryRow(t, `SELECT status FROM system.jobs WHERE id = $1`, foo.JobID).Scan(&status)
+				if jobs.Status(status) != jobs.StatusPaused {
+					return errors.New("could not pause job")
+				}
+				return nil
+			}); err != nil {
+				t.Fatal(err)
+			}
+		}
+		pauseJob()
+
+		sqlDB.ExpectErr(t, `table "foo" is already a target of changefeed \d+`,
+			`ALTER CHANGEFEED $1 ADD foo`, foo.JobID)
+		sqlDB.ExpectErr(t, `table "bar" is not a target of changefeed \d+`,
+			`ALTER CHANGEFEED $1 DROP bar`, foo.JobID)
+		sqlDB.ExpectErr(t, `cannot drop all of the targets`,
+			`ALTER CHANGEFEED $1 DROP foo`, foo.JobID)
+		sqlDB.ExpectErr(t, `invalid option "cursor"`,
+			`ALTER CHANGEFEED $1 SET cursor = '1'`, foo.JobID)
+		sqlDB.ExpectErr(t, `job 1 is not a changefeed job|job with ID 1 does not exist`,
+			`ALTER CHANGEFEED 1 ADD bar`)
+
+		sqlDB.Exec(t, `INSERT INTO foo VALUES (2)`)
+		sqlDB.Exec(t, `ALTER CHANGEFEED $1 ADD bar, SET diff`, foo.JobID)
+
+		var description string
+		sqlDB.QueryRow(t, `SELECT description FROM [SHOW JOBS] WHERE job_id = $1`,
+			foo.JobID).Scan(&description)
+		require.Contains(t, description, `CREATE CHANGEFEED FOR TABLE d.public.bar, d.public.foo`)
+		require.Contains(t, description, `diff`)
+
+		// Forget what the feed has emitted so far, so that any rescan of foo
+		// shows up as a duplicate.
+		foo.ResetSeen()
+		sqlDB.Exec(t, `RESUME JOB $1`, foo.JobID)
+		// bar gets an initial scan, while foo picks up where it left off.
+		assertPayloads(t, foo, []string{
+			`bar: [1]->{"after": {"b": 1}, "before": null}`,
+			`foo: [2]->{"after": {"a": 2}, "before": null}`,
+		})
+
+		pauseJob()
+		sqlDB.Exec(t, `ALTER CHANGEFEED $1 DROP foo`, foo.JobID)
+		sqlDB.Exec(t, `RESUME JOB $1`, foo.JobID)
+		sqlDB.Exec(t, `INSERT INTO foo VALUES (3)`)
+		sqlDB.Exec(t, `INSERT INTO bar VALUES (2)`)
+		assertPayloads(t, foo, []string{
+			`bar: [2]->{"after": {"b": 2}, "before": null}`,
+		})
+	}
+
+	// Only the enterprise version uses jobs.
+	t.Run(`enterprise`, enterpriseTest(testFn))
+}
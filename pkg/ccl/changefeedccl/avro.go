@@ -471,6 +471,47 @@ func tableToAvroSchema(
 	return schema, nil
 }
 
+// containerFileToAvroSchema returns the avro record schema of the rows in the
+// object container files written by a cloud storage format=avro changefeed:
+// the fields are the columns returned by containerFileColumns, so the table's
+// columns are followed by the event type and, optionally, updated timestamp.
+func containerFileToAvroSchema(
+	tableDesc catalog.TableDescriptor, updatedField bool,
+) (*avroDataRecord, error) {
+	schema := &avroDataRecord{
+		avroRecord: avroRecord{
+			Name:       SQLNameToAvroName(tableDesc.GetName()),
+			SchemaType: `record`,
+		},
+		fieldIdxByName:   make(map[string]int),
+		colIdxByFieldIdx: make(map[int]int),
+	}
+	columns := tableDesc.GetPublicColumns()
+	names, typs := containerFileColumns(tableDesc, updatedField)
+	for i := range names {
+		col := &descpb.ColumnDescriptor{Name: names[i], Type: typs[i], Nullable: true}
+		if i < len(columns) {
+			col = &columns[i]
+		}
+		field, err := columnDescToAvroSchema(col)
+		if err != nil {
+			return nil, err
+		}
+		schema.colIdxByFieldIdx[len(schema.Fields)] = i
+		schema.fieldIdxByName[field.Name] = len(schema.Fields)
+		schema.Fields = append(schema.Fields, field)
+	}
+	schemaJSON, err := json.Marshal(schema)
+	if err != nil {
+		return nil, err
+	}
+	schema.codec, err = goavro.NewCodec(string(schemaJSON))
+	if err != nil {
+		return nil, err
+	}
+	return schema, nil
+}
+
 // textualFromRow encodes the given row data into avro's defined JSON format.
 func (r *avroDataRecord) textualFromRow(row rowenc.EncDatumRow) ([]byte, error) {
 	native, err := r.nativeFromRow(row)
@@ -310,7 +310,7 @@ func emitEntries(
 				}
 			}
 			if input.resolved != nil {
-				boundaryReached = boundaryReached || input.resolved.BoundaryReached
+				boundaryReached = boundaryReached || input.resolved.BoundaryType != jobspb.ResolvedSpan_NONE
 				_ = sf.Forward(input.resolved.Span, input.resolved.Timestamp)
 				resolvedSpans = append(resolvedSpans, *input.resolved)
 			}
@@ -428,5 +428,6 @@ func initialScanFromOptions(opts map[string]string) bool {
 	_, cursor := opts[changefeedbase.OptCursor]
 	_, initialScan := opts[changefeedbase.OptInitialScan]
 	_, noInitialScan := opts[changefeedbase.OptNoInitialScan]
-	return (cursor && initialScan) || (!cursor && !noInitialScan)
+	_, initialScanOnly := opts[changefeedbase.OptInitialScanOnly]
+	return (cursor && (initialScan || initialScanOnly)) || (!cursor && !noInitialScan)
 }
@@ -10,18 +10,25 @@ package changefeedccl
 
 import (
 	"context"
+	"time"
 
+	"github.com/cockroachdb/cockroach/pkg/ccl/changefeedccl/changefeedbase"
+	"github.com/cockroachdb/cockroach/pkg/ccl/changefeedccl/schemafeed"
+	"github.com/cockroachdb/cockroach/pkg/jobs"
 	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
 	"github.com/cockroachdb/cockroach/pkg/keys"
 	"github.com/cockroachdb/cockroach/pkg/kv"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/catalogkv"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/execinfrapb"
 	"github.com/cockroachdb/cockroach/pkg/sql/physicalplan"
 	"github.com/cockroachdb/cockroach/pkg/sql/rowexec"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util/ctxgroup"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 )
 
@@ -60,6 +67,11 @@ var changefeedResultTypes = []*types.T{
 // timestamp is emitted into the changefeed sink (or returned to the gateway if
 // there is no sink) whenever it advances. ChangeFrontier also updates the
 // progress of the changefeed's corresponding system job.
+//
+// With the initial_scan_only option, the ChangeAggregators stop after the
+// initial scan instead of starting rangefeeds and resolve their spans at the
+// scan timestamp. Once ChangeFrontier sees the whole scan resolved, it emits a
+// final resolved timestamp and the flow returns, which completes the job.
 func distChangefeedFlow(
 	ctx context.Context,
 	execCtx sql.JobExecContext,
@@ -74,6 +86,11 @@ func distChangefeedFlow(
 		return err
 	}
 
+	var checkpoint jobspb.ChangefeedProgress_Checkpoint
+	if cp := progress.GetChangefeed(); cp != nil {
+		checkpoint = cp.Checkpoint
+	}
+
 	// NB: A non-empty high water indicates that we have checkpointed a resolved
 	// timestamp. Skipping the initial scan is equivalent to starting the
 	// changefeed from a checkpoint at its start time. Initialize the progress
@@ -83,8 +100,10 @@ func distChangefeedFlow(
 		noHighWater := (h == nil || h.IsEmpty())
 		// We want to set the highWater and thus avoid an initial scan if either
 		// this is a cursor and there was no request for one, or we don't have a
-		// cursor but we have a request to not have an initial scan.
-		if noHighWater && !initialScanFromOptions(details.Opts) {
+		// cursor but we have a request to not have an initial scan. A checkpoint
+		// means that the scan is needed after all, for the spans it doesn't
+		// cover, such as those of tables added by ALTER CHANGEFEED.
+		if noHighWater && len(checkpoint.Spans) == 0 && !initialScanFromOptions(details.Opts) {
 			// If there is a cursor, the statement time has already been set to it.
 			progress.Progress = &jobspb.Progress_HighWater{HighWater: &details.StatementTime}
 		}
@@ -100,6 +119,22 @@ func distChangefeedFlow(
 	}
 
 	execCfg := execCtx.ExecCfg()
+	if details.DatabaseID != descpb.InvalidID && !initialHighWater.IsEmpty() {
+		// Tables may have been added to or dropped from the watched database
+		// since the changefeed last started. The changefeed restarts at the
+		// timestamp preceding such changes, so they're visible at the next one.
+		spansTS = initialHighWater.Next()
+		details.Targets, err = fetchDatabaseTargets(
+			ctx, execCfg.DB, execCfg.Codec, details.DatabaseID, details.Targets, spansTS)
+		if err != nil {
+			return err
+		}
+		if len(details.Targets) == 0 {
+			// All tables in the watched database were dropped, so there's nothing
+			// to watch until one is created.
+			return waitForDatabaseTables(ctx, execCfg, jobID, details, initialHighWater)
+		}
+	}
 	trackedSpans, err := fetchSpansForTargets(ctx, execCfg.DB, execCfg.Codec, details.Targets, spansTS)
 	if err != nil {
 		return err
@@ -127,6 +162,11 @@ func distChangefeedFlow(
 		}
 	}
 
+	// The memory limit of the changefeed is split evenly among its aggregators
+	// so that a feed buffers no more than the limit across the cluster.
+	memLimit := changefeedbase.PerChangefeedMemLimit.Get(&execCtx.ExecCfg().Settings.SV)
+	aggregatorMemLimit := memLimit / int64(len(spanPartitions))
+
 	corePlacement := make([]physicalplan.ProcessorCorePlacement, len(spanPartitions))
 	for i, sp := range spanPartitions {
 		// TODO(dan): Merge these watches with the span-level resolved
@@ -141,15 +181,17 @@ func distChangefeedFlow(
 
 		corePlacement[i].NodeID = sp.Node
 		corePlacement[i].Core.ChangeAggregator = &execinfrapb.ChangeAggregatorSpec{
-			Watches:   watches,
-			Feed:      details,
-			UserProto: execCtx.User().EncodeProto(),
+			Watches:    watches,
+			Feed:       details,
+			UserProto:  execCtx.User().EncodeProto(),
+			Checkpoint: checkpoint,
+			MemLimit:   aggregatorMemLimit,
 		}
 	}
 	// NB: This SpanFrontier processor depends on the set of tracked spans being
-	// static. Currently there is no way for them to change after the changefeed
-	// is created, even if it is paused and unpaused, but #28982 describes some
-	// ways that this might happen in the future.
+	// static. The tables of a changefeed on a database can change, but the
+	// flow is restarted with a new set of spans whenever they do. #28982
+	// describes some other ways that this might happen in the future.
 	changeFrontierSpec := execinfrapb.ChangeFrontierSpec{
 		TrackedSpans: trackedSpans,
 		Feed:         details,
@@ -223,6 +265,112 @@ func fetchSpansForTargets(
 	return spans, err
 }
 
+// fetchDatabaseTargets returns the targets of a changefeed on the given
+// database as of the given timestamp. Tables which were already targets keep
+// their statement time names.
+func fetchDatabaseTargets(
+	ctx context.Context,
+	db *kv.DB,
+	codec keys.SQLCodec,
+	databaseID descpb.ID,
+	prevTargets jobspb.ChangefeedTargets,
+	ts hlc.Timestamp,
+) (jobspb.ChangefeedTargets, error) {
+	var targets jobspb.ChangefeedTargets
+	err := db.Txn(ctx, func(ctx context.Context, txn *kv.Txn) error {
+		targets = make(jobspb.ChangefeedTargets)
+		txn.SetFixedTimestamp(ctx, ts)
+		descs, err := catalogkv.GetAllDescriptors(ctx, txn, codec, true /* validate */)
+		if err != nil {
+			return err
+		}
+		for _, desc := range descs {
+			table, ok := desc.(catalog.TableDescriptor)
+			if !ok || !isWatchedDatabaseTable(table, databaseID) {
+				continue
+			}
+			if t, ok := prevTargets[table.GetID()]; ok {
+				targets[table.GetID()] = t
+			} else {
+				targets[table.GetID()] = jobspb.ChangefeedTarget{StatementTimeName: table.GetName()}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return targets, nil
+}
+
+// waitForDatabaseTables runs in place of the flow of a changefeed on a database
+// which has no tables left. It emits nothing and keeps checkpointing the
+// high-water until a table is created in the database, at which point it
+// returns a tableSetChangedError to restart the changefeed from just before
+// the table was added.
+func waitForDatabaseTables(
+	ctx context.Context,
+	execCfg *sql.ExecutorConfig,
+	jobID int64,
+	details jobspb.ChangefeedDetails,
+	highWater hlc.Timestamp,
+) error {
+	// Sinkless changefeeds have no job, so their high-water is only kept by the
+	// caller.
+	var job *jobs.Job
+	if jobID != 0 {
+		var err error
+		if job, err = execCfg.JobRegistry.LoadJob(ctx, jobID); err != nil {
+			return err
+		}
+	}
+
+	sf := schemafeed.New(schemafeed.Config{
+		DB:       execCfg.DB,
+		Clock:    execCfg.Clock,
+		Settings: execCfg.Settings,
+		Targets:  details.Targets,
+		SchemaChangeEvents: changefeedbase.SchemaChangeEventClass(
+			details.Opts[changefeedbase.OptSchemaChangeEvents]),
+		DatabaseID:       details.DatabaseID,
+		InitialHighWater: highWater,
+		LeaseManager:     execCfg.LeaseManager,
+	})
+	g := ctxgroup.WithContext(ctx)
+	g.GoCtx(sf.Run)
+	g.GoCtx(func(ctx context.Context) error {
+		for {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(changefeedbase.TableDescriptorPollInterval.Get(&execCfg.Settings.SV)):
+			}
+			ts := execCfg.Clock.Now()
+			events, err := sf.Peek(ctx, ts)
+			if err != nil {
+				return err
+			}
+			if len(events) > 0 {
+				// The only events of a database without targets are added tables.
+				ts = events[0].Timestamp().Prev()
+			}
+			if job != nil {
+				if err := job.HighWaterProgressed(ctx, func(
+					context.Context, *kv.Txn, jobspb.ProgressDetails,
+				) (hlc.Timestamp, error) {
+					return ts, nil
+				}); err != nil {
+					return err
+				}
+			}
+			if len(events) > 0 {
+				return MarkRetryableError(&tableSetChangedError{highWater: ts})
+			}
+		}
+	})
+	return g.Wait()
+}
+
 // changefeedResultWriter implements the `rowexec.resultWriter` that sends
 // the received rows back over the given channel.
 type changefeedResultWriter struct {
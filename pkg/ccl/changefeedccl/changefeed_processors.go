@@ -53,6 +53,8 @@ type changeAggregator struct {
 	// kvFeedDoneCh is closed when the kvfeed exits.
 	kvFeedDoneCh chan struct{}
 	kvFeedMemMon *mon.BytesMonitor
+	// untrackMemMon de-registers kvFeedMemMon from the changefeed metrics.
+	untrackMemMon func()
 
 	// encoder is the Encoder to use for key and value serialization.
 	encoder Encoder
@@ -137,7 +139,7 @@ func newChangeAggregatorProcessor(
 	}
 
 	var err error
-	if ca.encoder, err = getEncoder(ca.spec.Feed.Opts); err != nil {
+	if ca.encoder, err = getEncoder(ca.spec.Feed.Opts, ca.spec.Feed.SinkURI); err != nil {
 		return nil, err
 	}
 
@@ -188,17 +190,25 @@ func (ca *changeAggregator) Start(ctx context.Context) context.Context {
 		knobs = *cfKnobs
 	}
 
-	// It seems like we should also be able to use `ca.ProcessorBase.MemMonitor`
-	// for the poller, but there is a race between the flow's MemoryMonitor
-	// getting Stopped and `changeAggregator.Close`, which causes panics. Not sure
-	// what to do about this yet.
-	kvFeedMemMonCapacity := kvfeed.MemBufferDefaultCapacity
+	// Each aggregator gets its share of the changefeed's memory budget, carved
+	// out of the root SQL monitor, for the events buffered between the KV feed
+	// and the sink. This isn't the processor's own monitor because there is a
+	// race between the flow's MemoryMonitor getting Stopped and
+	// `changeAggregator.Close`, which causes panics.
+	memLimit := ca.spec.MemLimit
 	if knobs.MemBufferCapacity != 0 {
-		kvFeedMemMonCapacity = knobs.MemBufferCapacity
+		memLimit = knobs.MemBufferCapacity
 	}
-	kvFeedMemMon := mon.NewMonitorInheritWithLimit("kvFeed", math.MaxInt64, ca.ProcessorBase.MemMonitor)
-	kvFeedMemMon.Start(ctx, nil /* pool */, mon.MakeStandaloneBudget(kvFeedMemMonCapacity))
+	if knobs.OnAggregatorMemLimit != nil {
+		knobs.OnAggregatorMemLimit(memLimit)
+	}
+	kvFeedMemMon := mon.NewMonitorWithLimit(
+		"changefeed", mon.MemoryResource, memLimit, metrics.MemoryCurrentBytes,
+		nil /* maxHist */, -1 /* increment */, math.MaxInt64 /* noteworthy */, ca.flowCtx.Cfg.Settings,
+	)
+	kvFeedMemMon.Start(ctx, ca.flowCtx.Cfg.ParentMemoryMonitor, mon.BoundAccount{})
 	ca.kvFeedMemMon = kvFeedMemMon
+	ca.untrackMemMon = metrics.trackMemMonitor(kvFeedMemMon)
 
 	buf := kvfeed.MakeChanBuffer()
 	leaseMgr := ca.flowCtx.Cfg.LeaseManager.(*lease.Manager)
@@ -207,6 +217,17 @@ func (ca *changeAggregator) Start(ctx context.Context) context.Context {
 		spans, withDiff, buf, metrics)
 	cfg := ca.flowCtx.Cfg
 	rowsFn := kvsToRows(ctx, cfg.Codec, cfg.Settings, cfg.DB, leaseMgr, cfg.HydratedTables, ca.spec.Feed, buf.Get)
+	if ca.spec.Feed.Select != `` {
+		// Rows are filtered and projected as they're decoded, before anything is
+		// encoded or sent to the sink.
+		var selectEval *selectEvaluator
+		if selectEval, err = newSelectEvaluator(ca.flowCtx.EvalCtx, ca.spec.Feed); err != nil {
+			ca.MoveToDraining(err)
+			ca.cancel()
+			return ctx
+		}
+		rowsFn = selectEval.filterRows(rowsFn)
+	}
 	ca.tickFn = emitEntries(ca.flowCtx.Cfg.Settings, ca.spec.Feed,
 		kvfeedCfg.InitialHighWater, sf, ca.encoder, ca.sink, rowsFn, knobs, metrics)
 	ca.startKVFeed(ctx, kvfeedCfg)
@@ -250,6 +271,7 @@ func makeKVFeedCfg(
 	schemaChangePolicy := changefeedbase.SchemaChangePolicy(
 		spec.Feed.Opts[changefeedbase.OptSchemaChangePolicy])
 	initialHighWater, needsInitialScan := getKVFeedInitialParameters(spec)
+	_, initialScanOnly := spec.Feed.Opts[changefeedbase.OptInitialScanOnly]
 	kvfeedCfg := kvfeed.Config{
 		Sink:               buf,
 		Settings:           cfg.Settings,
@@ -259,12 +281,15 @@ func makeKVFeedCfg(
 		Gossip:             cfg.Gossip,
 		Spans:              spans,
 		Targets:            spec.Feed.Targets,
+		DatabaseID:         spec.Feed.DatabaseID,
 		LeaseMgr:           leaseMgr,
 		Metrics:            &metrics.KVFeedMetrics,
 		MM:                 mm,
 		InitialHighWater:   initialHighWater,
 		WithDiff:           withDiff,
 		NeedsInitialScan:   needsInitialScan,
+		Checkpoint:         spec.Checkpoint.Spans,
+		InitialScanOnly:    initialScanOnly,
 		SchemaChangeEvents: schemaChangeEvents,
 		SchemaChangePolicy: schemaChangePolicy,
 	}
@@ -337,6 +362,9 @@ func (ca *changeAggregator) close() {
 			}
 		}
 		ca.memAcc.Close(ca.Ctx)
+		if ca.untrackMemMon != nil {
+			ca.untrackMemMon()
+		}
 		if ca.kvFeedMemMon != nil {
 			ca.kvFeedMemMon.Stop(ca.Ctx)
 		}
@@ -446,10 +474,25 @@ type changeFrontier struct {
 	// which schema change events lead to a schemaChangeBoundary is controlled
 	// by the KV feed based on OptSchemaChangeEvents and OptSchemaChangePolicy.
 	schemaChangeBoundary hlc.Timestamp
+	// schemaChangeBoundaryType is the type of the schemaChangeBoundary. A
+	// RESTART boundary means that tables were added to or dropped from the
+	// watched database, and the changefeed restarts when it reaches it.
+	schemaChangeBoundaryType jobspb.ResolvedSpan_BoundaryType
+	// initialScanDone is set once an initial-scan-only changefeed has emitted
+	// its final resolved timestamp.
+	initialScanDone bool
 
 	// jobProgressedFn, if non-nil, is called to checkpoint the changefeed's
 	// progress in the corresponding system job entry.
 	jobProgressedFn func(context.Context, jobs.HighWaterProgressedFn) error
+	// job is the changefeed's job, which is nil for sinkless changefeeds.
+	job *jobs.Job
+	// inInitialScan is set if the changefeed started without a high-water and
+	// is performing an initial scan, the progress of which is checkpointed.
+	inInitialScan bool
+	// lastCheckpoint is the last time the spans covered by the initial scan
+	// were checkpointed.
+	lastCheckpoint time.Time
 	// highWaterAtStart is the greater of the job high-water and the timestamp the
 	// CHANGEFEED statement was run at. It's used in an assertion that we never
 	// regress the job high-water.
@@ -520,7 +563,7 @@ func newChangeFrontierProcessor(
 	}
 
 	var err error
-	if cf.encoder, err = getEncoder(spec.Feed.Opts); err != nil {
+	if cf.encoder, err = getEncoder(spec.Feed.Opts, spec.Feed.SinkURI); err != nil {
 		return nil, err
 	}
 
@@ -570,12 +613,27 @@ func (cf *changeFrontier) Start(ctx context.Context) context.Context {
 			cf.MoveToDraining(err)
 			return ctx
 		}
+		cf.job = job
 		cf.jobProgressedFn = job.HighWaterProgressed
 
 		p := job.Progress()
-		if ts := p.GetHighWater(); ts != nil {
+		ts := p.GetHighWater()
+		if ts != nil {
 			cf.highWaterAtStart.Forward(*ts)
 		}
+		var checkpoint jobspb.ChangefeedProgress_Checkpoint
+		if cp := p.GetChangefeed(); cp != nil {
+			checkpoint = cp.Checkpoint
+		}
+		// See the corresponding logic in distChangefeedFlow.
+		cf.inInitialScan = (ts == nil || ts.IsEmpty()) &&
+			(len(checkpoint.Spans) > 0 || initialScanFromOptions(cf.spec.Feed.Opts))
+		// The checkpointed spans have been scanned at the statement time and
+		// won't be scanned again.
+		for _, sp := range checkpoint.Spans {
+			cf.sf.Forward(sp, cf.spec.Feed.StatementTime)
+		}
+		cf.lastCheckpoint = timeutil.Now()
 	}
 
 	cf.metrics.mu.Lock()
@@ -643,10 +701,23 @@ func (cf *changeFrontier) shouldFailOnSchemaChange() bool {
 // shouldFailOnSchemaChange checks the job's spec to determine whether it should
 // install protected timestamps when encountering scan boundaries.
 func (cf *changeFrontier) shouldProtectBoundaries() bool {
+	if cf.initialScanOnly() {
+		// The only boundary is the end of the changefeed, so there is nothing
+		// left to protect.
+		return false
+	}
 	policy := changefeedbase.SchemaChangePolicy(cf.spec.Feed.Opts[changefeedbase.OptSchemaChangePolicy])
 	return policy == changefeedbase.OptSchemaChangePolicyBackfill
 }
 
+// initialScanOnly checks the job's spec to determine whether the changefeed
+// should complete once the initial scan is done. The kvfeed marks the end of
+// the scan as a boundary.
+func (cf *changeFrontier) initialScanOnly() bool {
+	_, ok := cf.spec.Feed.Opts[changefeedbase.OptInitialScanOnly]
+	return ok
+}
+
 // Next is part of the RowSource interface.
 func (cf *changeFrontier) Next() (rowenc.EncDatumRow, *execinfrapb.ProducerMetadata) {
 	for cf.State == execinfra.StateRunning {
@@ -656,6 +727,16 @@ func (cf *changeFrontier) Next() (rowenc.EncDatumRow, *execinfrapb.ProducerMetad
 			return cf.ProcessRowHelper(cf.resolvedBuf.Pop()), nil
 		}
 
+		if cf.schemaChangeBoundaryReached() &&
+			cf.schemaChangeBoundaryType == jobspb.ResolvedSpan_RESTART {
+			// The high-water has been checkpointed at the boundary, so the
+			// changefeed picks up where it left off with the new set of tables.
+			cf.MoveToDraining(MarkRetryableError(&tableSetChangedError{
+				highWater: cf.schemaChangeBoundary,
+			}))
+			break
+		}
+
 		if cf.schemaChangeBoundaryReached() && cf.shouldFailOnSchemaChange() {
 			// TODO(ajwerner): make this more useful by at least informing the client
 			// of which tables changed.
@@ -664,6 +745,23 @@ func (cf *changeFrontier) Next() (rowenc.EncDatumRow, *execinfrapb.ProducerMetad
 			break
 		}
 
+		if cf.schemaChangeBoundaryReached() && cf.initialScanOnly() {
+			if cf.initialScanDone {
+				cf.MoveToDraining(nil /* err */)
+				break
+			}
+			// Every span has been scanned and flushed to the sink by the
+			// aggregators, so all that's left is the final resolved timestamp.
+			// Sinkless feeds buffer it, so it's returned on the next iteration
+			// before draining.
+			if err := cf.emitFinalResolved(); err != nil {
+				cf.MoveToDraining(err)
+				break
+			}
+			cf.initialScanDone = true
+			continue
+		}
+
 		row, meta := cf.input.Next()
 		if meta != nil {
 			if meta.Err != nil {
@@ -723,12 +821,15 @@ func (cf *changeFrontier) noteResolvedSpan(d rowenc.EncDatum) error {
 
 	// We want to ensure that we mark the schemaChangeBoundary and then we want to detect when
 	// the frontier reaches to or past the schemaChangeBoundary.
-	if resolved.BoundaryReached && (cf.schemaChangeBoundary.IsEmpty() || resolved.Timestamp.Less(cf.schemaChangeBoundary)) {
+	boundaryReached := resolved.BoundaryType != jobspb.ResolvedSpan_NONE
+	if boundaryReached && (cf.schemaChangeBoundary.IsEmpty() || resolved.Timestamp.Less(cf.schemaChangeBoundary)) {
 		cf.schemaChangeBoundary = resolved.Timestamp
+		cf.schemaChangeBoundaryType = resolved.BoundaryType
 	}
 	// If we've moved past a schemaChangeBoundary, make sure to clear it.
-	if !resolved.BoundaryReached && !cf.schemaChangeBoundary.IsEmpty() && cf.schemaChangeBoundary.Less(resolved.Timestamp) {
+	if !boundaryReached && !cf.schemaChangeBoundary.IsEmpty() && cf.schemaChangeBoundary.Less(resolved.Timestamp) {
 		cf.schemaChangeBoundary = hlc.Timestamp{}
+		cf.schemaChangeBoundaryType = jobspb.ResolvedSpan_NONE
 	}
 
 	frontierChanged := cf.sf.Forward(resolved.Span, resolved.Timestamp)
@@ -738,9 +839,63 @@ func (cf *changeFrontier) noteResolvedSpan(d rowenc.EncDatum) error {
 			return err
 		}
 	}
+	return cf.maybeCheckpointInitialScan()
+}
+
+// maybeCheckpointInitialScan periodically records the spans which the initial
+// scan has completed in the job progress, so that a changefeed which restarts
+// before the scan is done doesn't scan them again. The checkpoint is cleared
+// when the scan is done and the high-water is checkpointed.
+func (cf *changeFrontier) maybeCheckpointInitialScan() error {
+	if !cf.inInitialScan || !cf.sf.Frontier().IsEmpty() {
+		return nil
+	}
+	sv := &cf.flowCtx.Cfg.Settings.SV
+	freq := changefeedbase.FrontierCheckpointFrequency.Get(sv)
+	if freq == 0 || timeutil.Since(cf.lastCheckpoint) < freq {
+		return nil
+	}
+	checkpoint := cf.getCheckpoint(changefeedbase.FrontierCheckpointMaxBytes.Get(sv))
+	if err := cf.job.Update(cf.Ctx, func(
+		_ *kv.Txn, md jobs.JobMetadata, ju *jobs.JobUpdater,
+	) error {
+		if err := md.CheckRunningOrReverting(); err != nil {
+			return err
+		}
+		md.Progress.GetChangefeed().Checkpoint = checkpoint
+		ju.UpdateProgress(md.Progress)
+		return nil
+	}); err != nil {
+		return err
+	}
+	cf.lastCheckpoint = timeutil.Now()
 	return nil
 }
 
+// getCheckpoint returns the checkpoint of the initial scan, which is made of
+// the spans that have been resolved while the frontier is still empty. As the
+// aggregators only start their rangefeeds once their spans have been scanned,
+// those are exactly the spans which the scan has completed. The checkpoint is
+// truncated once the keys of its spans exceed maxBytes.
+func (cf *changeFrontier) getCheckpoint(maxBytes int64) jobspb.ChangefeedProgress_Checkpoint {
+	var spans []roachpb.Span
+	cf.sf.Entries(func(sp roachpb.Span, ts hlc.Timestamp) {
+		if !ts.IsEmpty() {
+			spans = append(spans, sp)
+		}
+	})
+	spans, _ = roachpb.MergeSpans(spans)
+	var used int64
+	for i, sp := range spans {
+		used += int64(len(sp.Key) + len(sp.EndKey))
+		if used > maxBytes {
+			spans = spans[:i]
+			break
+		}
+	}
+	return jobspb.ChangefeedProgress_Checkpoint{Spans: spans}
+}
+
 func (cf *changeFrontier) handleFrontierChanged(isBehind bool) error {
 	newResolved := cf.sf.Frontier()
 	cf.metrics.mu.Lock()
@@ -778,6 +933,9 @@ func (cf *changeFrontier) checkpointResolvedTimestamp(
 		if err := cf.manageProtectedTimestamps(ctx, progress, txn, resolved, isBehind); err != nil {
 			return hlc.Timestamp{}, err
 		}
+		// Once there's a high-water, the initial scan is over and its checkpoint
+		// is no longer needed.
+		progress.Checkpoint = jobspb.ChangefeedProgress_Checkpoint{}
 		return resolved, nil
 	})
 }
@@ -856,7 +1014,9 @@ func (cf *changeFrontier) maybeProtectTimestamp(
 }
 
 func (cf *changeFrontier) maybeEmitResolved(newResolved hlc.Timestamp) error {
-	if cf.freqEmitResolved == emitNoResolved {
+	// Initial-scan-only changefeeds emit exactly one resolved timestamp, once
+	// the scan is complete. See emitFinalResolved.
+	if cf.freqEmitResolved == emitNoResolved || cf.initialScanOnly() {
 		return nil
 	}
 	sinceEmitted := newResolved.GoTime().Sub(cf.lastEmitResolved)
@@ -873,6 +1033,17 @@ func (cf *changeFrontier) maybeEmitResolved(newResolved hlc.Timestamp) error {
 	return nil
 }
 
+// emitFinalResolved emits the resolved timestamp at which an initial-scan-only
+// changefeed completes and waits for the sink to acknowledge it. It's emitted
+// regardless of the resolved option, since it's how consumers learn that the
+// scan is complete.
+func (cf *changeFrontier) emitFinalResolved() error {
+	if err := emitResolvedTimestamp(cf.Ctx, cf.encoder, cf.sink, cf.sf.Frontier()); err != nil {
+		return err
+	}
+	return cf.sink.Flush(cf.Ctx)
+}
+
 // Potentially log the most behind span in the frontier for debugging. The
 // returned boolean will be true if the resolved timestamp lags far behind the
 // present as defined by the current configuration.
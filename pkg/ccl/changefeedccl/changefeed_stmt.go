@@ -165,13 +165,8 @@ func changefeedPlanHook(
 			statementTime = initialHighWater
 		}
 
-		// For now, disallow targeting a database or wildcard table selection.
-		// Getting it right as tables enter and leave the set over time is
-		// tricky.
-		if len(changefeedStmt.Targets.Databases) > 0 {
-			return errors.Errorf(`CHANGEFEED cannot target %s`,
-				tree.AsString(&changefeedStmt.Targets))
-		}
+		// For now, disallow targeting a wildcard table selection. Databases are
+		// supported, see below.
 		for _, t := range changefeedStmt.Targets.Tables {
 			p, err := t.NormalizeTablePattern()
 			if err != nil {
@@ -183,11 +178,30 @@ func changefeedPlanHook(
 		}
 
 		// This grabs table descriptors once to get their ids.
-		targetDescs, _, err := backupccl.ResolveTargetsToDescriptors(
+		targetDescs, expandedDBs, err := backupccl.ResolveTargetsToDescriptors(
 			ctx, p, statementTime, &changefeedStmt.Targets)
 		if err != nil {
 			return errors.Wrap(err, "failed to resolve targets in the CHANGEFEED stmt")
 		}
+		var databaseID descpb.ID
+		if len(changefeedStmt.Targets.Databases) > 0 {
+			// A changefeed on a database watches the tables in it, which are
+			// recomputed whenever tables are added or dropped, so only the
+			// database and its tables are relevant here.
+			databaseID = expandedDBs[0]
+			var dbDescs []catalog.Descriptor
+			for _, desc := range targetDescs {
+				switch desc := desc.(type) {
+				case catalog.DatabaseDescriptor:
+					dbDescs = append(dbDescs, desc)
+				case catalog.TableDescriptor:
+					if isWatchedDatabaseTable(desc, databaseID) {
+						dbDescs = append(dbDescs, desc)
+					}
+				}
+			}
+			targetDescs = dbDescs
+		}
 		for _, desc := range targetDescs {
 			if err := p.CheckPrivilege(ctx, desc, privilege.SELECT); err != nil {
 				return err
@@ -204,12 +218,23 @@ func changefeedPlanHook(
 				}
 			}
 		}
+		if databaseID != descpb.InvalidID && len(targets) == 0 {
+			return errors.Errorf(`CHANGEFEED cannot target %s: it has no tables`,
+				tree.AsString(&changefeedStmt.Targets))
+		}
 
 		details := jobspb.ChangefeedDetails{
 			Targets:       targets,
 			Opts:          opts,
 			SinkURI:       sinkURI,
 			StatementTime: statementTime,
+			DatabaseID:    databaseID,
+		}
+		if changefeedStmt.Select != nil {
+			if err := validateChangefeedSelect(ctx, p, targetDescs, changefeedStmt.Select); err != nil {
+				return err
+			}
+			details.Select = tree.AsString(changefeedStmt.Select)
 		}
 		progress := jobspb.Progress{
 			Progress: &jobspb.Progress_HighWater{},
@@ -247,17 +272,10 @@ func changefeedPlanHook(
 		if err != nil {
 			return err
 		}
-		if details, err = validateDetails(details); err != nil {
+		if details, err = validateDetailsForSink(details, parsedSink); err != nil {
 			return err
 		}
 
-		if _, err := getEncoder(details.Opts); err != nil {
-			return err
-		}
-		if isCloudStorageSink(parsedSink) {
-			details.Opts[changefeedbase.OptKeyInValue] = ``
-		}
-
 		// Feature telemetry
 		telemetrySink := parsedSink.Scheme
 		if telemetrySink == `` {
@@ -266,10 +284,23 @@ func changefeedPlanHook(
 		telemetry.Count(`changefeed.create.sink.` + telemetrySink)
 		telemetry.Count(`changefeed.create.format.` + details.Opts[changefeedbase.OptFormat])
 		telemetry.CountBucketed(`changefeed.create.num_tables`, int64(len(targets)))
+		if databaseID != descpb.InvalidID {
+			telemetry.Count(`changefeed.create.database`)
+		}
 
 		if details.SinkURI == `` {
 			telemetry.Count(`changefeed.create.core`)
-			err := distChangefeedFlow(ctx, p, 0 /* jobID */, details, progress, resultsCh)
+			var err error
+			for {
+				err = distChangefeedFlow(ctx, p, 0 /* jobID */, details, progress, resultsCh)
+				// Sinkless changefeeds have no job to retry them, so they're
+				// restarted here when the tables in a watched database change.
+				var tableSetChanged *tableSetChangedError
+				if !errors.As(err, &tableSetChanged) {
+					break
+				}
+				progress.Progress = &jobspb.Progress_HighWater{HighWater: &tableSetChanged.highWater}
+			}
 			if err != nil {
 				telemetry.Count(`changefeed.core.error`)
 			}
@@ -296,22 +327,8 @@ func changefeedPlanHook(
 		// that the user has not made any obvious errors when specifying the sink in
 		// the CREATE CHANGEFEED statement. To do this, we create a "canary" sink,
 		// which will be immediately closed, only to check for errors.
-		{
-			nodeID, err := p.ExtendedEvalContext().NodeID.OptionalNodeIDErr(48274)
-			if err != nil {
-				return err
-			}
-			var nilOracle timestampLowerBoundOracle
-			canarySink, err := getSink(
-				ctx, details.SinkURI, nodeID, details.Opts, details.Targets,
-				settings, nilOracle, p.ExecCfg().DistSQLSrv.ExternalStorageFromURI, p.User(),
-			)
-			if err != nil {
-				return MaybeStripRetryableErrorMarker(err)
-			}
-			if err := canarySink.Close(); err != nil {
-				return err
-			}
+		if err := checkCanarySink(ctx, p, details); err != nil {
+			return err
 		}
 
 		// Make a channel for runChangefeedFlow to signal once everything has
@@ -415,6 +432,7 @@ func changefeedJobDescription(
 	c := &tree.CreateChangefeed{
 		Targets: changefeed.Targets,
 		SinkURI: tree.NewDString(cleanedSinkURI),
+		Select:  changefeed.Select,
 	}
 	for k, v := range opts {
 		opt := tree.KVOption{Key: tree.Name(k)}
@@ -428,6 +446,78 @@ func changefeedJobDescription(
 	return tree.AsStringWithFQNames(c, ann), nil
 }
 
+// validateDetailsForSink runs validateDetails and then checks that the
+// changefeed's options are supported by its sink, turning on the ones that the
+// sink requires.
+func validateDetailsForSink(
+	details jobspb.ChangefeedDetails, parsedSink *url.URL,
+) (jobspb.ChangefeedDetails, error) {
+	details, err := validateDetails(details)
+	if err != nil {
+		return jobspb.ChangefeedDetails{}, err
+	}
+
+	if _, err := getEncoder(details.Opts, details.SinkURI); err != nil {
+		return jobspb.ChangefeedDetails{}, err
+	}
+	if details.Select != `` &&
+		changefeedbase.FormatType(details.Opts[changefeedbase.OptFormat]) == changefeedbase.OptFormatAvro {
+		return jobspb.ChangefeedDetails{}, errors.Errorf(
+			`%s=%s is not supported with CREATE CHANGEFEED ... AS SELECT`,
+			changefeedbase.OptFormat, changefeedbase.OptFormatAvro)
+	}
+	if changefeedbase.FormatType(details.Opts[changefeedbase.OptFormat]) == changefeedbase.OptFormatParquet {
+		if !isCloudStorageSink(parsedSink) {
+			return jobspb.ChangefeedDetails{}, errors.Errorf(
+				`%s=%s is only supported by cloud storage sinks`,
+				changefeedbase.OptFormat, changefeedbase.OptFormatParquet)
+		}
+		if details.Select != `` {
+			return jobspb.ChangefeedDetails{}, errors.Errorf(
+				`%s=%s is not supported with CREATE CHANGEFEED ... AS SELECT`,
+				changefeedbase.OptFormat, changefeedbase.OptFormatParquet)
+		}
+	}
+	if isCloudStorageSink(parsedSink) {
+		details.Opts[changefeedbase.OptKeyInValue] = ``
+	}
+	if isWebhookSink(parsedSink) {
+		// Like cloud storage, the webhook sink only delivers values, so the key
+		// has to be in the value for DELETEs to be usable.
+		details.Opts[changefeedbase.OptKeyInValue] = ``
+	}
+	if isPubsubSink(parsedSink) {
+		if changefeedbase.FormatType(details.Opts[changefeedbase.OptFormat]) == changefeedbase.OptFormatAvro {
+			return jobspb.ChangefeedDetails{}, errors.Errorf(`%s=%s is not supported by %s sinks`,
+				changefeedbase.OptFormat, changefeedbase.OptFormatAvro, changefeedbase.SinkSchemeGCPubsub)
+		}
+		// Pub/Sub messages only carry the key as an ordering key, which may be
+		// hashed, so the key also goes in the value.
+		details.Opts[changefeedbase.OptKeyInValue] = ``
+	}
+	return details, nil
+}
+
+// checkCanarySink creates a sink for the changefeed and immediately closes it,
+// only to check for errors in its configuration and connectivity.
+func checkCanarySink(
+	ctx context.Context, p sql.PlanHookState, details jobspb.ChangefeedDetails,
+) error {
+	nodeID, err := p.ExtendedEvalContext().NodeID.OptionalNodeIDErr(48274)
+	if err != nil {
+		return err
+	}
+	var nilOracle timestampLowerBoundOracle
+	canarySink, err := getSink(
+		ctx, details.SinkURI, nodeID, details.Opts, details.Targets,
+		p.ExecCfg().Settings, nilOracle, p.ExecCfg().DistSQLSrv.ExternalStorageFromURI, p.User(),
+	)
+	if err != nil {
+		return MaybeStripRetryableErrorMarker(err)
+	}
+	return canarySink.Close()
+}
+
 func validateDetails(details jobspb.ChangefeedDetails) (jobspb.ChangefeedDetails, error) {
 	if details.Opts == nil {
 		// The proto MarshalTo method omits the Opts field if the map is empty.
@@ -480,6 +570,11 @@ func validateDetails(details jobspb.ChangefeedDetails) (jobspb.ChangefeedDetails
 				`cannot specify both %s and %s`, changefeedbase.OptInitialScan,
 				changefeedbase.OptNoInitialScan)
 		}
+		if _, initialScanOnly := details.Opts[changefeedbase.OptInitialScanOnly]; initialScanOnly && noInitialScan {
+			return jobspb.ChangefeedDetails{}, errors.Errorf(
+				`cannot specify both %s and %s`, changefeedbase.OptInitialScanOnly,
+				changefeedbase.OptNoInitialScan)
+		}
 	}
 	{
 		const opt = changefeedbase.OptEnvelope
@@ -500,7 +595,7 @@ func validateDetails(details jobspb.ChangefeedDetails) (jobspb.ChangefeedDetails
 		switch v := changefeedbase.FormatType(details.Opts[opt]); v {
 		case ``, changefeedbase.OptFormatJSON:
 			details.Opts[opt] = string(changefeedbase.OptFormatJSON)
-		case changefeedbase.OptFormatAvro:
+		case changefeedbase.OptFormatAvro, changefeedbase.OptFormatParquet:
 			// No-op.
 		default:
 			return jobspb.ChangefeedDetails{}, errors.Errorf(
@@ -510,6 +605,29 @@ func validateDetails(details jobspb.ChangefeedDetails) (jobspb.ChangefeedDetails
 	return details, nil
 }
 
+// validateChangefeedSelect checks that the SELECT clause of a CREATE
+// CHANGEFEED ... AS SELECT statement can be evaluated against every changed row
+// of its table.
+func validateChangefeedSelect(
+	ctx context.Context, p sql.PlanHookState, targetDescs []catalog.Descriptor, sel *tree.SelectClause,
+) error {
+	for _, desc := range targetDescs {
+		if table, isTable := desc.(catalog.TableDescriptor); isTable {
+			_, err := compileSelect(ctx, &p.ExtendedEvalContext().EvalContext, p.SemaCtx(), table, sel)
+			return err
+		}
+	}
+	return errors.AssertionFailedf(`no table to evaluate %s against`, tree.AsString(sel))
+}
+
+// isWatchedDatabaseTable returns whether a changefeed on the given database
+// watches the given table. Tables are watched once they're public, but views
+// and sequences never are.
+func isWatchedDatabaseTable(table catalog.TableDescriptor, databaseID descpb.ID) bool {
+	return table.GetParentID() == databaseID && table.Public() &&
+		!table.IsView() && !table.IsSequence() && !table.IsVirtualTable()
+}
+
 func validateChangefeedTable(
 	targets jobspb.ChangefeedTargets, tableDesc catalog.TableDescriptor,
 ) error {
@@ -630,9 +748,13 @@ func (b *changefeedResumer) Resume(
 			return err
 		}
 
-		log.Warningf(ctx, `CHANGEFEED job %d encountered retryable error: %v`, jobID, err)
-		if metrics, ok := execCfg.JobRegistry.MetricsStruct().Changefeed.(*Metrics); ok {
-			metrics.ErrorRetries.Inc(1)
+		if errors.HasType(err, (*tableSetChangedError)(nil)) {
+			log.Infof(ctx, `CHANGEFEED job %d restarting: %v`, jobID, err)
+		} else {
+			log.Warningf(ctx, `CHANGEFEED job %d encountered retryable error: %v`, jobID, err)
+			if metrics, ok := execCfg.JobRegistry.MetricsStruct().Changefeed.(*Metrics); ok {
+				metrics.ErrorRetries.Inc(1)
+			}
 		}
 		// Re-load the job in order to update our progress object, which may have
 		// been updated by the changeFrontier processor since the flow started.
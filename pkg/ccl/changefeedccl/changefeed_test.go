@@ -14,6 +14,8 @@ import (
 	"fmt"
 	"math"
 	"net/url"
+	"os"
+	"path/filepath"
 	"regexp"
 	"sort"
 	"strconv"
@@ -56,6 +58,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
 	"github.com/cockroachdb/cockroach/pkg/util/retry"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
 	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
 	"github.com/cockroachdb/cockroach/pkg/util/uuid"
 	"github.com/cockroachdb/errors"
@@ -155,6 +158,124 @@ func TestChangefeedDiff(t *testing.T) {
 	t.Run(`cloudstorage`, cloudStorageTest(testFn))
 }
 
+func TestChangefeedSelect(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	testFn := func(t *testing.T, db *gosql.DB, f cdctest.TestFeedFactory) {
+		sqlDB := sqlutils.MakeSQLRunner(db)
+		sqlDB.Exec(t, `CREATE TABLE foo (a INT PRIMARY KEY, b STRING, c INT)`)
+		sqlDB.Exec(t, `INSERT INTO foo VALUES (0, 'active', 1), (1, 'inactive', 2)`)
+
+		foo := feed(t, f, `CREATE CHANGEFEED AS SELECT a, c * 10 AS c10 FROM foo WHERE b = 'active'`)
+		defer closeFeed(t, foo)
+
+		assertPayloads(t, foo, []string{
+			`foo: [0]->{"after": {"a": 0, "c10": 10}}`,
+		})
+
+		sqlDB.Exec(t, `INSERT INTO foo VALUES (2, 'inactive', 3), (3, 'active', 4)`)
+		sqlDB.Exec(t, `UPDATE foo SET b = 'active' WHERE a = 1`)
+		assertPayloads(t, foo, []string{
+			`foo: [3]->{"after": {"a": 3, "c10": 40}}`,
+			`foo: [1]->{"after": {"a": 1, "c10": 20}}`,
+		})
+
+		// Without diff, there's no way to tell whether a deleted row matched, so
+		// every deletion is emitted.
+		sqlDB.Exec(t, `DELETE FROM foo WHERE a IN (2, 3)`)
+		assertPayloads(t, foo, []string{
+			`foo: [2]->{"after": null}`,
+			`foo: [3]->{"after": null}`,
+		})
+	}
+
+	t.Run(`sinkless`, sinklessTest(testFn))
+	t.Run(`enterprise`, enterpriseTest(testFn))
+	t.Run(`cloudstorage`, cloudStorageTest(testFn))
+}
+
+func TestChangefeedSelectDiff(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	testFn := func(t *testing.T, db *gosql.DB, f cdctest.TestFeedFactory) {
+		sqlDB := sqlutils.MakeSQLRunner(db)
+		sqlDB.Exec(t, `CREATE TABLE foo (a INT PRIMARY KEY, b STRING)`)
+		sqlDB.Exec(t, `INSERT INTO foo VALUES (0, 'active'), (1, 'inactive')`)
+
+		foo := feed(t, f, `CREATE CHANGEFEED WITH diff AS SELECT * FROM foo WHERE b = 'active'`)
+		defer closeFeed(t, foo)
+
+		assertPayloads(t, foo, []string{
+			`foo: [0]->{"after": {"a": 0, "b": "active"}, "before": null}`,
+		})
+
+		// A row that stops matching is emitted as a deletion and a deleted row
+		// is only emitted if it matched.
+		sqlDB.Exec(t, `UPDATE foo SET b = 'inactive' WHERE a = 0`)
+		sqlDB.Exec(t, `DELETE FROM foo WHERE a = 1`)
+		sqlDB.Exec(t, `INSERT INTO foo VALUES (2, 'active')`)
+		sqlDB.Exec(t, `DELETE FROM foo WHERE a = 2`)
+		assertPayloads(t, foo, []string{
+			`foo: [0]->{"after": null, "before": {"a": 0, "b": "active"}}`,
+			`foo: [2]->{"after": {"a": 2, "b": "active"}, "before": null}`,
+			`foo: [2]->{"after": null, "before": {"a": 2, "b": "active"}}`,
+		})
+	}
+
+	t.Run(`sinkless`, sinklessTest(testFn))
+	t.Run(`enterprise`, enterpriseTest(testFn))
+	t.Run(`cloudstorage`, cloudStorageTest(testFn))
+}
+
+func TestChangefeedSelectErrors(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	s, db, _ := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop(ctx)
+	sqlDB := sqlutils.MakeSQLRunner(db)
+	sqlDB.Exec(t, `SET CLUSTER SETTING kv.rangefeed.enabled = true`)
+	sqlDB.Exec(t, `CREATE TABLE foo (a INT PRIMARY KEY, b STRING)`)
+
+	sqlDB.ExpectErr(
+		t, `column "nope" does not exist`,
+		`EXPERIMENTAL CHANGEFEED AS SELECT nope FROM foo`,
+	)
+	sqlDB.ExpectErr(
+		t, `argument of WHERE must be type bool, not type string`,
+		`EXPERIMENTAL CHANGEFEED AS SELECT a FROM foo WHERE b`,
+	)
+	sqlDB.ExpectErr(
+		t, `volatile functions are not allowed in CHANGEFEED`,
+		`EXPERIMENTAL CHANGEFEED AS SELECT a FROM foo WHERE random() > 0.5`,
+	)
+	sqlDB.ExpectErr(
+		t, `volatile functions are not allowed in CHANGEFEED`,
+		`EXPERIMENTAL CHANGEFEED AS SELECT a, gen_random_uuid() FROM foo`,
+	)
+	sqlDB.ExpectErr(
+		t, `subqueries are not allowed in CHANGEFEED`,
+		`EXPERIMENTAL CHANGEFEED AS SELECT a FROM foo WHERE a IN (SELECT 1)`,
+	)
+	sqlDB.ExpectErr(
+		t, `aggregate functions are not allowed in CHANGEFEED`,
+		`EXPERIMENTAL CHANGEFEED AS SELECT count(a) FROM foo`,
+	)
+	sqlDB.ExpectErr(
+		t, `format=experimental_avro is not supported with CREATE CHANGEFEED ... AS SELECT`,
+		`EXPERIMENTAL CHANGEFEED WITH format=$1, confluent_schema_registry=$2 AS SELECT a FROM foo`,
+		changefeedbase.OptFormatAvro, `bar`,
+	)
+	sqlDB.ExpectErr(
+		t, `format=parquet is not supported with CREATE CHANGEFEED ... AS SELECT`,
+		`CREATE CHANGEFEED INTO $1 WITH format=$2 AS SELECT a FROM foo`,
+		`experimental-nodelocal://0/bar`, changefeedbase.OptFormatParquet,
+	)
+}
+
 func TestChangefeedEnvelope(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 	defer log.Scope(t).Close(t)
@@ -419,6 +540,106 @@ func TestChangefeedInitialScan(t *testing.T) {
 	t.Run(`enterprise`, enterpriseTest(testFn))
 }
 
+func TestChangefeedInitialScanOnly(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	testFn := func(t *testing.T, db *gosql.DB, f cdctest.TestFeedFactory) {
+		sqlDB := sqlutils.MakeSQLRunner(db)
+		sqlDB.Exec(t, `CREATE TABLE foo (a INT PRIMARY KEY)`)
+		sqlDB.Exec(t, `INSERT INTO foo VALUES (1), (2), (3)`)
+		var tsStr string
+		sqlDB.QueryRow(t, `SELECT cluster_logical_timestamp()`).Scan(&tsStr)
+		sqlDB.Exec(t, `INSERT INTO foo VALUES (4)`)
+
+		// The scan happens at the cursor, so the last row isn't included.
+		foo := feed(t, f, `CREATE CHANGEFEED FOR foo WITH initial_scan_only, cursor=$1`, tsStr)
+		defer closeFeed(t, foo)
+		assertPayloads(t, foo, []string{
+			`foo: [1]->{"after": {"a": 1}}`,
+			`foo: [2]->{"after": {"a": 2}}`,
+			`foo: [3]->{"after": {"a": 3}}`,
+		})
+		// The final resolved timestamp is emitted even though the resolved
+		// option wasn't specified, and nothing follows it.
+		expectResolvedTimestamp(t, foo)
+
+		var numJobs int
+		sqlDB.QueryRow(t, `SELECT count(*) FROM [SHOW JOBS] WHERE job_type = 'CHANGEFEED'`).Scan(&numJobs)
+		if numJobs == 0 {
+			// Sinkless changefeeds return from the statement.
+			m, err := foo.Next()
+			require.NoError(t, err)
+			require.Nil(t, m)
+			return
+		}
+		testutils.SucceedsSoon(t, func() error {
+			var status string
+			sqlDB.QueryRow(t, `SELECT status FROM [SHOW JOBS] WHERE job_type = 'CHANGEFEED'`).Scan(&status)
+			if jobs.Status(status) != jobs.StatusSucceeded {
+				return errors.Errorf(`expected job to succeed, got %s`, status)
+			}
+			return nil
+		})
+	}
+
+	t.Run(`sinkless`, sinklessTest(testFn))
+	t.Run(`enterprise`, enterpriseTest(testFn))
+	t.Run(`cloudstorage`, cloudStorageTest(testFn))
+}
+
+func TestChangefeedDatabase(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	testFn := func(t *testing.T, db *gosql.DB, f cdctest.TestFeedFactory) {
+		sqlDB := sqlutils.MakeSQLRunner(db)
+		sqlDB.Exec(t, `CREATE TABLE foo (a INT PRIMARY KEY)`)
+		sqlDB.Exec(t, `CREATE VIEW vw AS SELECT a FROM foo`)
+		sqlDB.Exec(t, `INSERT INTO foo VALUES (1)`)
+
+		feed := feed(t, f, `CREATE CHANGEFEED FOR DATABASE d`)
+		defer closeFeed(t, feed)
+		assertPayloads(t, feed, []string{
+			`foo: [1]->{"after": {"a": 1}}`,
+		})
+
+		// Tables created after the changefeed are picked up, with an initial
+		// scan of the rows they already have.
+		sqlDB.Exec(t, `CREATE TABLE bar (b INT PRIMARY KEY)`)
+		sqlDB.Exec(t, `INSERT INTO bar VALUES (1)`)
+		sqlDB.Exec(t, `INSERT INTO foo VALUES (2)`)
+		assertPayloads(t, feed, []string{
+			`bar: [1]->{"after": {"b": 1}}`,
+			`foo: [2]->{"after": {"a": 2}}`,
+		})
+		sqlDB.Exec(t, `INSERT INTO bar VALUES (2)`)
+		assertPayloads(t, feed, []string{
+			`bar: [2]->{"after": {"b": 2}}`,
+		})
+
+		// Dropped tables stop emitting without failing the changefeed.
+		sqlDB.Exec(t, `DROP VIEW vw`)
+		sqlDB.Exec(t, `DROP TABLE foo`)
+		sqlDB.Exec(t, `INSERT INTO bar VALUES (3)`)
+		assertPayloads(t, feed, []string{
+			`bar: [3]->{"after": {"b": 3}}`,
+		})
+
+		// Once every table is dropped, the changefeed waits for new ones.
+		sqlDB.Exec(t, `DROP TABLE bar`)
+		sqlDB.Exec(t, `CREATE TABLE baz (c INT PRIMARY KEY)`)
+		sqlDB.Exec(t, `INSERT INTO baz VALUES (1)`)
+		assertPayloads(t, feed, []string{
+			`baz: [1]->{"after": {"c": 1}}`,
+		})
+	}
+
+	t.Run(`sinkless`, sinklessTest(testFn))
+	t.Run(`enterprise`, enterpriseTest(testFn))
+	t.Run(`cloudstorage`, cloudStorageTest(testFn))
+}
+
 func TestChangefeedUserDefinedTypes(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 	testFn := func(t *testing.T, db *gosql.DB, f cdctest.TestFeedFactory) {
@@ -1860,6 +2081,62 @@ func TestChangefeedSchemaTTL(t *testing.T) {
 	t.Run("enterprise", enterpriseTest(testFn))
 }
 
+// TestChangefeedAvroContainerFiles verifies that the avro object container
+// files written by a cloud storage changefeed can be loaded with IMPORT.
+func TestChangefeedAvroContainerFiles(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	dir, dirCleanupFn := testutils.TempDir(t)
+	defer dirCleanupFn()
+	s, db, _ := serverutils.StartServer(t, base.TestServerArgs{
+		UseDatabase:   `d`,
+		ExternalIODir: dir,
+	})
+	defer s.Stopper().Stop(ctx)
+	sqlDB := sqlutils.MakeSQLRunner(db)
+	sqlDB.Exec(t, `SET CLUSTER SETTING kv.rangefeed.enabled = true`)
+	sqlDB.Exec(t, `SET CLUSTER SETTING kv.closed_timestamp.target_duration = '1s'`)
+	sqlDB.Exec(t, `CREATE DATABASE d`)
+
+	const columns = `a INT PRIMARY KEY, b STRING, c DECIMAL(10, 2), d DATE, e TIMESTAMPTZ, f JSONB`
+	sqlDB.Exec(t, `CREATE TABLE foo (`+columns+`)`)
+	sqlDB.Exec(t, `INSERT INTO foo VALUES
+		(1, 'x', 1.25, '2021-01-02', '2021-01-02 03:04:05.678+00', '{"k": [1]}'),
+		(2, NULL, NULL, NULL, NULL, NULL)`)
+
+	var jobID int64
+	sqlDB.QueryRow(t, `CREATE CHANGEFEED FOR foo INTO $1 WITH format='experimental_avro', resolved`,
+		`experimental-nodelocal://0/feed`).Scan(&jobID)
+
+	var files []string
+	testutils.SucceedsSoon(t, func() error {
+		files = files[:0]
+		root := filepath.Join(dir, `feed`)
+		if err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+			if err == nil && strings.HasSuffix(path, `.avro`) {
+				files = append(files, `nodelocal://0/feed/`+strings.TrimPrefix(path, root+`/`))
+			}
+			return err
+		}); err != nil {
+			return err
+		}
+		if len(files) == 0 {
+			return errors.New(`no avro files written yet`)
+		}
+		return nil
+	})
+	sqlDB.Exec(t, `CANCEL JOB $1`, jobID)
+
+	sqlDB.Exec(t, `CREATE TABLE bar (`+columns+`)`)
+	for _, file := range files {
+		sqlDB.Exec(t, `IMPORT INTO bar AVRO DATA ($1)`, file)
+	}
+	sqlDB.CheckQueryResults(t, `SELECT * FROM bar ORDER BY a`,
+		sqlDB.QueryStr(t, `SELECT * FROM foo ORDER BY a`))
+}
+
 func TestChangefeedErrors(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 	defer log.Scope(t).Close(t)
@@ -1945,6 +2222,11 @@ func TestChangefeedErrors(t *testing.T) {
 		t, `CHANGEFEED cannot target views: vw`,
 		`EXPERIMENTAL CHANGEFEED FOR vw`,
 	)
+	sqlDB.Exec(t, `CREATE DATABASE empty`)
+	sqlDB.ExpectErr(
+		t, `CHANGEFEED cannot target DATABASE empty: it has no tables`,
+		`EXPERIMENTAL CHANGEFEED FOR DATABASE empty`,
+	)
 	// Backup has the same bad error message #28170.
 	sqlDB.ExpectErr(
 		t, `"information_schema.tables" does not exist`,
@@ -1993,6 +2275,25 @@ func TestChangefeedErrors(t *testing.T) {
 		`CREATE CHANGEFEED FOR foo INTO $1`, `kafka://nope/?kafka_topic_prefix=foo`,
 	)
 
+	// Sanity check gcpubsub parameters.
+	sqlDB.ExpectErr(
+		t, `a project id is required`,
+		`CREATE CHANGEFEED FOR foo INTO $1`, `gcpubsub:///?emulator_host=nope:1`,
+	)
+	sqlDB.ExpectErr(
+		t, `unknown sink query parameter: kafka_topic_prefix`,
+		`CREATE CHANGEFEED FOR foo INTO $1`, `gcpubsub://p/?kafka_topic_prefix=foo`,
+	)
+	sqlDB.ExpectErr(
+		t, `AUTH is set to 'specified', but CREDENTIALS is not set`,
+		`CREATE CHANGEFEED FOR foo INTO $1`, `gcpubsub://p/?AUTH=specified`,
+	)
+	sqlDB.ExpectErr(
+		t, `format=experimental_avro is not supported by gcpubsub sinks`,
+		`CREATE CHANGEFEED FOR foo INTO $1 WITH format=$2, confluent_schema_registry=$3`,
+		`gcpubsub://p`, changefeedbase.OptFormatAvro, `bar`,
+	)
+
 	// schema_topic will be implemented but isn't yet.
 	sqlDB.ExpectErr(
 		t, `schema_topic is not yet supported`,
@@ -2079,17 +2380,45 @@ func TestChangefeedErrors(t *testing.T) {
 	)
 
 	// The cloudStorageSink is particular about the options it will work with.
+	// It writes avro as object container files, which embed their schema.
 	sqlDB.ExpectErr(
-		t, `this sink is incompatible with format=experimental_avro`,
+		t, `confluent_schema_registry is not supported by cloud storage sinks`,
 		`CREATE CHANGEFEED FOR foo INTO $1 WITH format='experimental_avro', confluent_schema_registry=$2`,
 		`experimental-nodelocal://0/bar`, `schemareg-nope`,
 	)
+	sqlDB.ExpectErr(
+		t, `diff is not supported with format=experimental_avro`,
+		`CREATE CHANGEFEED FOR foo INTO $1 WITH format='experimental_avro', diff`,
+		`experimental-nodelocal://0/bar`,
+	)
+	sqlDB.ExpectErr(
+		t, `unsupported compression codec "gzip"`,
+		`CREATE CHANGEFEED FOR foo INTO $1 WITH format='experimental_avro', compression='gzip'`,
+		`experimental-nodelocal://0/bar`,
+	)
 	sqlDB.ExpectErr(
 		t, `this sink is incompatible with envelope=key_only`,
 		`CREATE CHANGEFEED FOR foo INTO $1 WITH envelope='key_only'`,
 		`experimental-nodelocal://0/bar`,
 	)
 
+	// Parquet files can only be written by the cloudStorageSink, and have no
+	// room for the previous value of a row.
+	sqlDB.ExpectErr(
+		t, `format=parquet is only supported by cloud storage sinks`,
+		`CREATE CHANGEFEED FOR foo INTO $1 WITH format='parquet'`, `kafka://nope`,
+	)
+	sqlDB.ExpectErr(
+		t, `diff is not supported with format=parquet`,
+		`CREATE CHANGEFEED FOR foo INTO $1 WITH format='parquet', diff`,
+		`experimental-nodelocal://0/bar`,
+	)
+	sqlDB.ExpectErr(
+		t, `unsupported compression codec "lz4"`,
+		`CREATE CHANGEFEED FOR foo INTO $1 WITH format='parquet', compression='lz4'`,
+		`experimental-nodelocal://0/bar`,
+	)
+
 	// WITH key_in_value requires envelope=wrapped
 	sqlDB.ExpectErr(
 		t, `key_in_value is only usable with envelope=wrapped`,
@@ -2119,6 +2448,10 @@ func TestChangefeedErrors(t *testing.T) {
 		t, `cannot specify both initial_scan and no_initial_scan`,
 		`CREATE CHANGEFEED FOR foo INTO $1 WITH no_initial_scan, initial_scan`, `kafka://nope`,
 	)
+	sqlDB.ExpectErr(
+		t, `cannot specify both initial_scan_only and no_initial_scan`,
+		`CREATE CHANGEFEED FOR foo INTO $1 WITH initial_scan_only, no_initial_scan`, `kafka://nope`,
+	)
 }
 
 func TestChangefeedDescription(t *testing.T) {
@@ -2218,6 +2551,100 @@ func TestChangefeedPauseUnpause(t *testing.T) {
 	t.Run(`enterprise`, enterpriseTest(testFn))
 }
 
+func TestChangefeedCheckpointInitialScan(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	defer jobs.TestingSetAdoptAndCancelIntervals(10*time.Millisecond, 10*time.Millisecond)()
+
+	testFn := func(t *testing.T, db *gosql.DB, f cdctest.TestFeedFactory) {
+		sqlDB := sqlutils.MakeSQLRunner(db)
+		// Scan one range at a time, and checkpoint whenever one is done.
+		sqlDB.Exec(t, `SET CLUSTER SETTING kv.bulk_io_write.concurrent_export_requests = 1`)
+		sqlDB.Exec(t, `SET CLUSTER SETTING changefeed.frontier_checkpoint_frequency = '1us'`)
+		sqlDB.Exec(t, `CREATE TABLE foo (a INT PRIMARY KEY)`)
+		sqlDB.Exec(t, `INSERT INTO foo SELECT * FROM generate_series(1, 100)`)
+		sqlDB.Exec(t, `ALTER TABLE foo SPLIT AT SELECT * FROM generate_series(10, 90, 10)`)
+		var tableID uint32
+		sqlDB.QueryRow(t, `SELECT table_id FROM crdb_internal.tables WHERE name = 'foo'`).Scan(&tableID)
+
+		// Block the changefeed halfway through the initial scan, until it's
+		// paused.
+		var emitted int64
+		knobs := f.Server().(*server.TestServer).Cfg.TestingKnobs.
+			DistSQL.(*execinfra.TestingKnobs).
+			Changefeed.(*TestingKnobs)
+		knobs.BeforeEmitRow = func(ctx context.Context) error {
+			if atomic.AddInt64(&emitted, 1) > 50 {
+				<-ctx.Done()
+				return ctx.Err()
+			}
+			return nil
+		}
+
+		foo := feed(t, f, `CREATE CHANGEFEED FOR foo`).(*cdctest.TableFeed)
+		defer closeFeed(t, foo)
+
+		ctx := context.Background()
+		jr := f.Server().DistSQLServer().(*distsql.ServerImpl).ServerConfig.JobRegistry
+		var checkpoint jobspb.ChangefeedProgress_Checkpoint
+		testutils.SucceedsSoon(t, func() error {
+			j, err := jr.LoadJob(ctx, foo.JobID)
+			if err != nil {
+				return err
+			}
+			progress := j.Progress()
+			checkpoint = progress.GetChangefeed().Checkpoint
+			if len(checkpoint.Spans) == 0 {
+				return errors.New("expected a checkpoint")
+			}
+			return nil
+		})
+		require.NoError(t, foo.Pause())
+		j, err := jr.LoadJob(ctx, foo.JobID)
+		require.NoError(t, err)
+		progress := j.Progress()
+		checkpoint = progress.GetChangefeed().Checkpoint
+
+		// Once resumed, the changefeed only scans the rows which aren't covered
+		// by the checkpoint. The rows emitted before the pause may still be
+		// read from the sink, so the rows are counted as they're emitted.
+		var expected int64
+		for i := 1; i <= 100; i++ {
+			key := encoding.EncodeVarintAscending(keys.SystemSQLCodec.IndexPrefix(tableID, 1), int64(i))
+			scanned := false
+			for _, sp := range checkpoint.Spans {
+				scanned = scanned || sp.ContainsKey(key)
+			}
+			if !scanned {
+				expected++
+			}
+		}
+		require.Less(t, expected, int64(100))
+
+		atomic.StoreInt64(&emitted, math.MinInt64)
+		require.NoError(t, foo.Resume())
+
+		// The checkpoint is cleared once the scan is done.
+		testutils.SucceedsSoon(t, func() error {
+			j, err := jr.LoadJob(ctx, foo.JobID)
+			if err != nil {
+				return err
+			}
+			progress := j.Progress()
+			if hw := progress.GetHighWater(); hw == nil || hw.IsEmpty() {
+				return errors.New("expected a high-water")
+			}
+			require.Empty(t, progress.GetChangefeed().Checkpoint.Spans)
+			return nil
+		})
+		require.Equal(t, expected, atomic.LoadInt64(&emitted)-math.MinInt64)
+	}
+
+	// Only the enterprise version uses jobs.
+	t.Run(`enterprise`, enterpriseTest(testFn))
+}
+
 func TestChangefeedPauseUnpauseCursorAndInitialScan(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 	defer log.Scope(t).Close(t)
@@ -2782,15 +3209,16 @@ func TestChangefeedMemBufferCapacity(t *testing.T) {
 		// don't see how to do that without a refactor.
 		knobs.MemBufferCapacity = 20000
 		beforeEmitRowCh := make(chan struct{}, 1)
+		unblockCh := make(chan struct{})
 		knobs.BeforeEmitRow = func(ctx context.Context) error {
 			select {
 			case <-ctx.Done():
 				return ctx.Err()
 			case <-beforeEmitRowCh:
+			case <-unblockCh:
 			}
 			return nil
 		}
-		defer close(beforeEmitRowCh)
 
 		sqlDB := sqlutils.MakeSQLRunner(db)
 		sqlDB.Exec(t, `CREATE TABLE foo (a INT PRIMARY KEY, b STRING)`)
@@ -2805,12 +3233,32 @@ func TestChangefeedMemBufferCapacity(t *testing.T) {
 			`foo: [0]->{"after": {"a": 0, "b": "small"}}`,
 		})
 
-		// Put enough data in to overflow the buffer and verify that at some point
-		// we get the "memory budget exceeded" error.
+		// Put enough data in to overflow the buffer and verify that the kvfeed
+		// waits for room instead of failing the changefeed.
+		metrics := f.Server().JobRegistry().(*jobs.Registry).MetricsStruct().Changefeed.(*Metrics)
 		sqlDB.Exec(t, `INSERT INTO foo SELECT i, 'foofoofoo' FROM generate_series(1, $1) AS g(i)`, 1000)
-		if _, err := foo.Next(); !testutils.IsError(err, `memory budget exceeded`) {
-			t.Fatalf(`expected "memory budget exceeded" error got: %v`, err)
+		testutils.SucceedsSoon(t, func() error {
+			// Keep unblocking emits until the buffer is full and the kvfeed
+			// has to wait for it to drain.
+			select {
+			case beforeEmitRowCh <- struct{}{}:
+			default:
+			}
+			if metrics.KVFeedMetrics.BufferPushbackNanos.Count() == 0 {
+				return errors.New(`expected the buffer to push back`)
+			}
+			if metrics.MemoryMaxFeedBytes.Value() == 0 {
+				return errors.New(`expected the feed's memory usage to be tracked`)
+			}
+			return nil
+		})
+		close(unblockCh)
+
+		var expected []string
+		for i := 1; i <= 1000; i++ {
+			expected = append(expected, fmt.Sprintf(`foo: [%d]->{"after": {"a": %d, "b": "foofoofoo"}}`, i, i))
 		}
+		assertPayloads(t, foo, expected)
 	}
 
 	// The mem buffer is only used with RangeFeed.
@@ -2818,6 +3266,88 @@ func TestChangefeedMemBufferCapacity(t *testing.T) {
 	t.Run(`enterprise`, enterpriseTest(testFn))
 }
 
+func TestChangefeedMemLimitSplitAcrossAggregators(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+	flushCh := make(chan struct{}, 1)
+	defer close(flushCh)
+
+	skip.UnderRace(t, "Takes too long with race enabled")
+
+	var mu struct {
+		syncutil.Mutex
+		limits []int64
+	}
+	knobs := base.TestingKnobs{DistSQL: &execinfra.TestingKnobs{
+		Changefeed: &TestingKnobs{
+			AfterSinkFlush: func() error {
+				select {
+				case flushCh <- struct{}{}:
+				default:
+				}
+				return nil
+			},
+			OnAggregatorMemLimit: func(limit int64) {
+				mu.Lock()
+				defer mu.Unlock()
+				mu.limits = append(mu.limits, limit)
+			},
+		},
+	}}
+
+	sinkDir, cleanupFn := testutils.TempDir(t)
+	defer cleanupFn()
+
+	tc := serverutils.StartNewTestCluster(t, 3, base.TestClusterArgs{
+		ServerArgs: base.TestServerArgs{
+			UseDatabase:   "test",
+			Knobs:         knobs,
+			ExternalIODir: sinkDir,
+		}})
+	defer tc.Stopper().Stop(context.Background())
+
+	db := tc.ServerConn(0)
+	sqlDB := sqlutils.MakeSQLRunner(db)
+	sqlDB.Exec(t, `SET CLUSTER SETTING kv.rangefeed.enabled = true`)
+	sqlDB.Exec(t, `SET CLUSTER SETTING kv.closed_timestamp.target_duration = '1s'`)
+	sqlDB.Exec(t, `SET CLUSTER SETTING changefeed.experimental_poll_interval = '10ms'`)
+	const memLimit = 30 << 20
+	sqlDB.Exec(t, `SET CLUSTER SETTING changefeed.memory.per_changefeed_limit = $1`, memLimit)
+
+	sqlutils.CreateTable(
+		t, db, "foo",
+		"k INT PRIMARY KEY, v INT",
+		3,
+		sqlutils.ToRowFn(sqlutils.RowIdxFn, sqlutils.RowModuloFn(2)),
+	)
+
+	// Put the lease of each of the 3 ranges of the table on a different node,
+	// so that the changefeed runs an aggregator on every node.
+	sqlDB.Exec(t, `ALTER TABLE foo SPLIT AT VALUES (2), (3)`)
+	testutils.SucceedsSoon(t, func() error {
+		_, err := db.Exec(
+			`ALTER TABLE foo EXPERIMENTAL_RELOCATE LEASE VALUES (1, 1), (2, 2), (3, 3)`)
+		return err
+	})
+
+	f := cdctest.MakeCloudFeedFactory(tc.Server(1), tc.ServerConn(0), sinkDir, flushCh)
+	foo := feed(t, f, `CREATE CHANGEFEED FOR foo`)
+	defer closeFeed(t, foo)
+	assertPayloads(t, foo, []string{
+		`foo: [1]->{"after": {"k": 1, "v": 1}}`,
+		`foo: [2]->{"after": {"k": 2, "v": 0}}`,
+		`foo: [3]->{"after": {"k": 3, "v": 1}}`,
+	})
+
+	// Every aggregator gets an even share of the changefeed's memory limit.
+	mu.Lock()
+	defer mu.Unlock()
+	require.GreaterOrEqual(t, len(mu.limits), 3)
+	for _, limit := range mu.limits {
+		require.Equal(t, int64(memLimit/3), limit)
+	}
+}
+
 // Regression test for #41694.
 func TestChangefeedRestartDuringBackfill(t *testing.T) {
 	defer leaktest.AfterTest(t)()
@@ -24,3 +24,29 @@ var TableDescriptorPollInterval = settings.RegisterNonNegativeDurationSetting(
 	"polling interval for the table descriptors",
 	1*time.Second,
 )
+
+// PerChangefeedMemLimit controls how much memory each changefeed's buffers may
+// use before the KV feed is backpressured. The limit is split evenly among the
+// change aggregators of the changefeed.
+var PerChangefeedMemLimit = settings.RegisterByteSizeSetting(
+	"changefeed.memory.per_changefeed_limit",
+	"controls amount of data that can be buffered per changefeed, across all of its nodes",
+	1<<30,
+)
+
+// FrontierCheckpointFrequency controls how often the changefeed frontier
+// records the spans which the initial scan has completed in the job progress.
+// Zero disables the checkpoint.
+var FrontierCheckpointFrequency = settings.RegisterNonNegativeDurationSetting(
+	"changefeed.frontier_checkpoint_frequency",
+	"controls how often a changefeed checkpoints the spans its initial scan has completed; 0 disables",
+	10*time.Second,
+)
+
+// FrontierCheckpointMaxBytes controls the maximum size of the checkpoint, as
+// a total of the keys of its spans.
+var FrontierCheckpointMaxBytes = settings.RegisterByteSizeSetting(
+	"changefeed.frontier_checkpoint_max_bytes",
+	"controls the maximum size of the initial scan checkpoint of a changefeed",
+	1<<20, // 1 MiB
+)
@@ -24,6 +24,8 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/rowenc"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util/encoding"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/httputil"
 	"github.com/cockroachdb/cockroach/pkg/util/json"
@@ -61,6 +63,12 @@ type encodeRow struct {
 	// prevTableDesc is a TableDescriptor for the table containing `prevDatums`.
 	// It's valid for interpreting the row at `updated.Prev()`.
 	prevTableDesc catalog.TableDescriptor
+	// projection, if non-nil, is the target list of a CREATE CHANGEFEED ... AS
+	// SELECT statement evaluated against `datums`. It's encoded as the value
+	// in place of the full row.
+	projection *projectedRow
+	// prevProjection is the same as projection, but for `prevDatums`.
+	prevProjection *projectedRow
 }
 
 // Encoder turns a row into a serialized changefeed key, value, or resolved
@@ -82,12 +90,24 @@ type Encoder interface {
 	EncodeResolvedTimestamp(context.Context, string, hlc.Timestamp) ([]byte, error)
 }
 
-func getEncoder(opts map[string]string) (Encoder, error) {
+// getEncoder returns the Encoder for the format in opts. Cloud storage sinks
+// write avro as object container files, which embed their schema instead of
+// registering it, so the sink URI also determines which avro Encoder is used.
+func getEncoder(opts map[string]string, sinkURI string) (Encoder, error) {
 	switch changefeedbase.FormatType(opts[changefeedbase.OptFormat]) {
 	case ``, changefeedbase.OptFormatJSON:
 		return makeJSONEncoder(opts)
 	case changefeedbase.OptFormatAvro:
+		if u, err := url.Parse(sinkURI); err == nil && isCloudStorageSink(u) {
+			if _, ok := opts[changefeedbase.OptConfluentSchemaRegistry]; ok {
+				return nil, errors.Errorf(`%s is not supported by cloud storage sinks`,
+					changefeedbase.OptConfluentSchemaRegistry)
+			}
+			return newContainerFileEncoder(opts)
+		}
 		return newConfluentAvroEncoder(opts)
+	case changefeedbase.OptFormatParquet:
+		return newContainerFileEncoder(opts)
 	default:
 		return nil, errors.Errorf(`unknown %s: %s`, changefeedbase.OptFormat, opts[changefeedbase.OptFormat])
 	}
@@ -169,7 +189,12 @@ func (e *jsonEncoder) EncodeValue(_ context.Context, row encodeRow) ([]byte, err
 	}
 
 	var after map[string]interface{}
-	if !row.deleted {
+	if row.projection != nil {
+		var err error
+		if after, err = row.projection.asJSONEntries(); err != nil {
+			return nil, err
+		}
+	} else if !row.deleted {
 		columns := row.tableDesc.GetPublicColumns()
 		after = make(map[string]interface{}, len(columns))
 		for i := range columns {
@@ -187,7 +212,12 @@ func (e *jsonEncoder) EncodeValue(_ context.Context, row encodeRow) ([]byte, err
 	}
 
 	var before map[string]interface{}
-	if row.prevDatums != nil && !row.prevDeleted {
+	if row.prevProjection != nil {
+		var err error
+		if before, err = row.prevProjection.asJSONEntries(); err != nil {
+			return nil, err
+		}
+	} else if row.prevDatums != nil && !row.prevDeleted {
 		columns := row.prevTableDesc.GetPublicColumns()
 		before = make(map[string]interface{}, len(columns))
 		for i := range columns {
@@ -267,6 +297,145 @@ func (e *jsonEncoder) EncodeResolvedTimestamp(
 	return gojson.Marshal(jsonEntries)
 }
 
+// Columns added after the table's columns in the self-describing files
+// written by format=parquet and cloud storage format=avro changefeeds, and the
+// values of the event type column.
+const (
+	containerFileEventTypeColumn = `__crdb__event_type`
+	containerFileUpdatedColumn   = `__crdb__updated`
+
+	containerFileEventTypeUpsert = `upsert`
+	containerFileEventTypeDelete = `delete`
+)
+
+// containerFileColumns returns the names and types of the columns of the
+// self-describing files written for rows of the given table.
+func containerFileColumns(
+	tableDesc catalog.TableDescriptor, updatedField bool,
+) ([]string, []*types.T) {
+	columns := tableDesc.GetPublicColumns()
+	names := make([]string, 0, len(columns)+2)
+	typs := make([]*types.T, 0, len(columns)+2)
+	for i := range columns {
+		names = append(names, columns[i].Name)
+		typs = append(typs, columns[i].Type)
+	}
+	names = append(names, containerFileEventTypeColumn)
+	typs = append(typs, types.String)
+	if updatedField {
+		names = append(names, containerFileUpdatedColumn)
+		typs = append(typs, types.String)
+	}
+	return names, typs
+}
+
+// containerFileEncoder encodes changefeed entries for the self-describing
+// files (parquet files, or avro object container files) written by the cloud
+// storage sink. Such a file embeds the schema of its rows, which the sink
+// derives from the row's table, so a value is not a standalone message: it's
+// the datums of the columns returned by containerFileColumns, each
+// value-encoded one after the other, which the sink decodes with
+// decodeContainerFileRow and buffers into the file for the row's table. Keys
+// and resolved timestamps are encoded as JSON.
+type containerFileEncoder struct {
+	json         *jsonEncoder
+	format       changefeedbase.FormatType
+	updatedField bool
+
+	alloc   rowenc.DatumAlloc
+	buf     []byte
+	scratch []byte
+}
+
+var _ Encoder = &containerFileEncoder{}
+
+func newContainerFileEncoder(opts map[string]string) (*containerFileEncoder, error) {
+	format := changefeedbase.FormatType(opts[changefeedbase.OptFormat])
+	if changefeedbase.EnvelopeType(opts[changefeedbase.OptEnvelope]) != changefeedbase.OptEnvelopeWrapped {
+		return nil, errors.Errorf(`%s=%s is not supported with %s=%s`,
+			changefeedbase.OptEnvelope, opts[changefeedbase.OptEnvelope],
+			changefeedbase.OptFormat, format)
+	}
+	if _, ok := opts[changefeedbase.OptDiff]; ok {
+		return nil, errors.Errorf(`%s is not supported with %s=%s`,
+			changefeedbase.OptDiff, changefeedbase.OptFormat, format)
+	}
+	json, err := makeJSONEncoder(opts)
+	if err != nil {
+		return nil, err
+	}
+	e := &containerFileEncoder{json: json, format: format}
+	_, e.updatedField = opts[changefeedbase.OptUpdatedTimestamps]
+	return e, nil
+}
+
+// EncodeKey implements the Encoder interface.
+func (e *containerFileEncoder) EncodeKey(ctx context.Context, row encodeRow) ([]byte, error) {
+	return e.json.EncodeKey(ctx, row)
+}
+
+// EncodeValue implements the Encoder interface.
+func (e *containerFileEncoder) EncodeValue(_ context.Context, row encodeRow) ([]byte, error) {
+	if row.projection != nil {
+		return nil, errors.AssertionFailedf(`%s=%s does not support projections`,
+			changefeedbase.OptFormat, e.format)
+	}
+	e.buf = e.buf[:0]
+	columns := row.tableDesc.GetPublicColumns()
+	for i := range columns {
+		datum := row.datums[i]
+		if err := datum.EnsureDecoded(columns[i].Type, &e.alloc); err != nil {
+			return nil, err
+		}
+		if err := e.appendDatum(datum.Datum); err != nil {
+			return nil, err
+		}
+	}
+	eventType := containerFileEventTypeUpsert
+	if row.deleted {
+		eventType = containerFileEventTypeDelete
+	}
+	if err := e.appendDatum(tree.NewDString(eventType)); err != nil {
+		return nil, err
+	}
+	if e.updatedField {
+		if err := e.appendDatum(tree.NewDString(row.updated.AsOfSystemTime())); err != nil {
+			return nil, err
+		}
+	}
+	return e.buf, nil
+}
+
+func (e *containerFileEncoder) appendDatum(d tree.Datum) error {
+	var err error
+	e.buf, err = rowenc.EncodeTableValue(e.buf, descpb.ColumnID(encoding.NoColumnID), d, e.scratch)
+	return err
+}
+
+// EncodeResolvedTimestamp implements the Encoder interface.
+func (e *containerFileEncoder) EncodeResolvedTimestamp(
+	ctx context.Context, topic string, resolved hlc.Timestamp,
+) ([]byte, error) {
+	return e.json.EncodeResolvedTimestamp(ctx, topic, resolved)
+}
+
+// decodeContainerFileRow decodes a value encoded by containerFileEncoder into
+// datums, which must have the same length as typs.
+func decodeContainerFileRow(
+	alloc *rowenc.DatumAlloc, typs []*types.T, value []byte, datums tree.Datums,
+) error {
+	for i := range typs {
+		var err error
+		if datums[i], value, err = rowenc.DecodeTableValue(alloc, typs[i], value); err != nil {
+			return err
+		}
+	}
+	if len(value) != 0 {
+		return errors.AssertionFailedf(`%d trailing bytes in row`, len(value))
+	}
+	return nil
+}
+
 // confluentAvroEncoder encodes changefeed entries as Avro's binary or textual
 // JSON format. Keys are the primary key columns in a record. Values are all
 // columns in a record.
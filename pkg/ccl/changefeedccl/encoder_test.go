@@ -214,7 +214,7 @@ func TestEncoders(t *testing.T) {
 				t.Fatalf(`unknown format: %s`, o[changefeedbase.OptFormat])
 			}
 
-			e, err := getEncoder(o)
+			e, err := getEncoder(o, ``)
 			if len(expected.err) > 0 {
 				require.EqualError(t, err, expected.err)
 				return
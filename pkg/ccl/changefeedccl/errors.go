@@ -13,6 +13,7 @@ import (
 	"reflect"
 	"strings"
 
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/errors"
 )
 
@@ -86,3 +87,15 @@ func MaybeStripRetryableErrorMarker(err error) error {
 }
 
 var retryableErrorType = reflect.TypeOf((*retryableError)(nil))
+
+// tableSetChangedError is returned by the changeFrontier of a changefeed on a
+// database when tables were added to or dropped from the database. The
+// changefeed is then restarted from highWater with the new set of tables.
+type tableSetChangedError struct {
+	highWater hlc.Timestamp
+}
+
+// Error implements the error interface.
+func (e *tableSetChangedError) Error() string {
+	return fmt.Sprintf("tables in the watched database changed at %s", e.highWater.Next())
+}
@@ -18,8 +18,8 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/rowcontainer"
 	"github.com/cockroachdb/cockroach/pkg/sql/rowenc"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlerrors"
 	"github.com/cockroachdb/cockroach/pkg/sql/types"
-	"github.com/cockroachdb/cockroach/pkg/util/envutil"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/cockroach/pkg/util/mon"
@@ -42,7 +42,7 @@ type EventBufferReader interface {
 // EventBufferWriter is the write portion of the EventBuffer interface.
 type EventBufferWriter interface {
 	AddKV(ctx context.Context, kv roachpb.KeyValue, prevVal roachpb.Value, backfillTimestamp hlc.Timestamp) error
-	AddResolved(ctx context.Context, span roachpb.Span, ts hlc.Timestamp, boundaryReached bool) error
+	AddResolved(ctx context.Context, span roachpb.Span, ts hlc.Timestamp, boundaryType jobspb.ResolvedSpan_BoundaryType) error
 	Close(ctx context.Context)
 }
 
@@ -165,9 +165,12 @@ func (b *chanBuffer) AddKV(
 
 // AddResolved inserts a Resolved timestamp notification in the buffer.
 func (b *chanBuffer) AddResolved(
-	ctx context.Context, span roachpb.Span, ts hlc.Timestamp, boundaryReached bool,
+	ctx context.Context,
+	span roachpb.Span,
+	ts hlc.Timestamp,
+	boundaryType jobspb.ResolvedSpan_BoundaryType,
 ) error {
-	return b.addEvent(ctx, Event{resolved: &jobspb.ResolvedSpan{Span: span, Timestamp: ts, BoundaryReached: boundaryReached}})
+	return b.addEvent(ctx, Event{resolved: &jobspb.ResolvedSpan{Span: span, Timestamp: ts, BoundaryType: boundaryType}})
 }
 
 func (b *chanBuffer) Close(_ context.Context) {
@@ -195,14 +198,6 @@ func (b *chanBuffer) Get(ctx context.Context) (Event, error) {
 	}
 }
 
-// MemBufferDefaultCapacity is the default capacity for a memBuffer for a single
-// changefeed.
-//
-// TODO(dan): It would be better if all changefeeds shared a single capacity
-// that was given by the operater at startup, like we do for RocksDB and SQL.
-var MemBufferDefaultCapacity = envutil.EnvOrDefaultBytes(
-	"COCKROACH_CHANGEFEED_BUFFER_CAPACITY", 1<<30) // 1GB
-
 var memBufferColTypes = []*types.T{
 	types.Bytes, // KV.Key
 	types.Bytes, // KV.Value
@@ -216,7 +211,9 @@ var memBufferColTypes = []*types.T{
 
 // memBuffer is an in-memory buffer for changed KV and Resolved timestamp
 // events. It's size is limited only by the BoundAccount passed to the
-// constructor. memBuffer is only for use with single-producer single-consumer.
+// constructor. When that account runs out of budget, the producer blocks until
+// the consumer has drained enough of the buffer to make room. memBuffer is only
+// for use with single-producer single-consumer.
 type memBuffer struct {
 	metrics *Metrics
 
@@ -227,6 +224,9 @@ type memBuffer struct {
 	// signalCh can be selected on to learn when an entry is written to
 	// mu.entries.
 	signalCh chan struct{}
+	// popCh can be selected on to learn when an entry is removed from
+	// mu.entries.
+	popCh chan struct{}
 
 	allocMu struct {
 		syncutil.Mutex
@@ -238,6 +238,7 @@ func makeMemBuffer(acc mon.BoundAccount, metrics *Metrics) *memBuffer {
 	b := &memBuffer{
 		metrics:  metrics,
 		signalCh: make(chan struct{}, 1),
+		popCh:    make(chan struct{}, 1),
 	}
 	b.mu.entries.Init(acc, colinfo.ColTypeInfoFromColTypes(memBufferColTypes), 0 /* rowCapacity */)
 	return b
@@ -275,7 +276,10 @@ func (b *memBuffer) AddKV(
 
 // AddResolved inserts a Resolved timestamp notification in the buffer.
 func (b *memBuffer) AddResolved(
-	ctx context.Context, span roachpb.Span, ts hlc.Timestamp, boundaryReached bool,
+	ctx context.Context,
+	span roachpb.Span,
+	ts hlc.Timestamp,
+	boundaryType jobspb.ResolvedSpan_BoundaryType,
 ) error {
 	b.allocMu.Lock()
 	row := tree.Datums{
@@ -331,16 +335,53 @@ func (b *memBuffer) Get(ctx context.Context) (Event, error) {
 }
 
 func (b *memBuffer) addRow(ctx context.Context, row tree.Datums) error {
-	b.mu.Lock()
-	_, err := b.mu.entries.AddRow(ctx, row)
-	b.mu.Unlock()
+	if err := b.addRowWithBackpressure(ctx, row); err != nil {
+		return err
+	}
 	b.metrics.BufferEntriesIn.Inc(1)
 	select {
 	case b.signalCh <- struct{}{}:
 	default:
 		// Already signaled, don't need to signal again.
 	}
-	return err
+	return nil
+}
+
+// addRowWithBackpressure adds the row to the buffer, waiting for the consumer
+// to remove entries whenever the buffer's memory budget is exhausted. An out of
+// memory error is only returned if the row doesn't fit in an empty buffer.
+func (b *memBuffer) addRowWithBackpressure(ctx context.Context, row tree.Datums) error {
+	var waitStart time.Time
+	defer func() {
+		if !waitStart.IsZero() {
+			b.metrics.BufferPushbackNanos.Inc(timeutil.Since(waitStart).Nanoseconds())
+		}
+	}()
+	for {
+		b.mu.Lock()
+		_, err := b.mu.entries.AddRow(ctx, row)
+		empty := b.mu.entries.Len() == 0
+		if err != nil && empty {
+			// Memory for popped rows is released a chunk at a time, so an empty
+			// buffer may still be holding on to some of its budget. Release all
+			// of it and try once more before giving up.
+			b.mu.entries.Clear(ctx)
+			_, err = b.mu.entries.AddRow(ctx, row)
+		}
+		b.mu.Unlock()
+		if err == nil || !sqlerrors.IsOutOfMemoryError(err) || empty {
+			return err
+		}
+
+		if waitStart.IsZero() {
+			waitStart = timeutil.Now()
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-b.popCh:
+		}
+	}
 }
 
 func (b *memBuffer) getRow(ctx context.Context) (tree.Datums, error) {
@@ -354,6 +395,11 @@ func (b *memBuffer) getRow(ctx context.Context) (tree.Datums, error) {
 		b.mu.Unlock()
 		if row != nil {
 			b.metrics.BufferEntriesOut.Inc(1)
+			select {
+			case b.popCh <- struct{}{}:
+			default:
+				// Already signaled, don't need to signal again.
+			}
 			return row, nil
 		}
 
@@ -25,6 +25,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/kv/kvclient/kvcoord"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/lease"
 	"github.com/cockroachdb/cockroach/pkg/util/ctxgroup"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
@@ -43,6 +44,7 @@ type Config struct {
 	Gossip             gossip.OptionalGossip
 	Spans              []roachpb.Span
 	Targets            jobspb.ChangefeedTargets
+	DatabaseID         descpb.ID
 	Sink               EventBufferWriter
 	LeaseMgr           *lease.Manager
 	Metrics            *Metrics
@@ -57,6 +59,15 @@ type Config struct {
 	// been seen.
 	NeedsInitialScan bool
 
+	// Checkpoint is the set of spans which the initial scan has already
+	// covered, and so skips.
+	Checkpoint []roachpb.Span
+
+	// If true, the feed stops after the initial scan instead of starting the
+	// rangefeed. All spans are resolved at the scan timestamp as a boundary, so
+	// the higher layers can detect that the scan is complete.
+	InitialScanOnly bool
+
 	// InitialHighWater is the timestamp from which new events are guaranteed to
 	// be produced.
 	InitialHighWater hlc.Timestamp
@@ -92,9 +103,9 @@ func Run(ctx context.Context, cfg Config) error {
 		return makeMemBuffer(cfg.MM.MakeBoundAccount(), cfg.Metrics)
 	}
 	f := newKVFeed(
-		cfg.Sink, cfg.Spans,
+		cfg.Sink, cfg.Spans, cfg.Checkpoint,
 		cfg.SchemaChangeEvents, cfg.SchemaChangePolicy,
-		cfg.NeedsInitialScan, cfg.WithDiff,
+		cfg.NeedsInitialScan, cfg.WithDiff, cfg.InitialScanOnly,
 		cfg.InitialHighWater,
 		cfg.Codec,
 		sf, sc, pff, bf)
@@ -108,10 +119,19 @@ func Run(ctx context.Context, cfg Config) error {
 		log.Infof(ctx, "stopping changefeed due to schema change at %v", scErr.ts)
 		<-ctx.Done()
 		err = nil
+	} else if errors.Is(err, errInitialScanComplete) {
+		log.Infof(ctx, "stopping changefeed after initial scan")
+		<-ctx.Done()
+		err = nil
 	}
 	return err
 }
 
+// errInitialScanComplete is a sentinel error to indicate to Run() that the
+// initial scan of an initial-scan-only feed is done. Like
+// schemaChangeDetectedError, it is handled entirely in this package.
+var errInitialScanComplete = errors.New("initial scan complete")
+
 // schemaChangeDetectedError is a sentinel error to indicate to Run() that the
 // schema change is stopping due to a schema change. This is handy to trigger
 // the context group to stop; the error is handled entirely in this package.
@@ -130,8 +150,10 @@ type schemaFeed interface {
 
 type kvFeed struct {
 	spans               []roachpb.Span
+	checkpoint          []roachpb.Span
 	withDiff            bool
 	withInitialBackfill bool
+	initialScanOnly     bool
 	initialHighWater    hlc.Timestamp
 	sink                EventBufferWriter
 	codec               keys.SQLCodec
@@ -148,10 +170,10 @@ type kvFeed struct {
 
 func newKVFeed(
 	sink EventBufferWriter,
-	spans []roachpb.Span,
+	spans, checkpoint []roachpb.Span,
 	schemaChangeEvents changefeedbase.SchemaChangeEventClass,
 	schemaChangePolicy changefeedbase.SchemaChangePolicy,
-	withInitialBackfill, withDiff bool,
+	withInitialBackfill, withDiff, initialScanOnly bool,
 	initialHighWater hlc.Timestamp,
 	codec keys.SQLCodec,
 	tf schemaFeed,
@@ -162,8 +184,10 @@ func newKVFeed(
 	return &kvFeed{
 		sink:                sink,
 		spans:               spans,
+		checkpoint:          checkpoint,
 		withInitialBackfill: withInitialBackfill,
 		withDiff:            withDiff,
+		initialScanOnly:     initialScanOnly,
 		initialHighWater:    initialHighWater,
 		schemaChangeEvents:  schemaChangeEvents,
 		schemaChangePolicy:  schemaChangePolicy,
@@ -179,6 +203,9 @@ func (f *kvFeed) run(ctx context.Context) (err error) {
 	// highWater represents the point in time at or before which we know
 	// we've seen all events or is the initial starting time of the feed.
 	highWater := f.initialHighWater
+	if f.initialScanOnly {
+		return f.runInitialScanOnly(ctx, highWater)
+	}
 	for i := 0; ; i++ {
 		initialScan := i == 0
 		if err = f.scanIfShould(ctx, initialScan, highWater); err != nil {
@@ -189,11 +216,27 @@ func (f *kvFeed) run(ctx context.Context) (err error) {
 			return err
 		}
 
+		// If a table was added to or dropped from a watched database, the
+		// changefeed has to be restarted with the new set of tables. Resolve all
+		// of the spans as a boundary to restart from, regardless of the policy.
+		restart, err := f.tableSetChanged(ctx, highWater.Next())
+		if err != nil {
+			return err
+		}
+		if restart {
+			for _, span := range f.spans {
+				if err := f.sink.AddResolved(ctx, span, highWater, jobspb.ResolvedSpan_RESTART); err != nil {
+					return err
+				}
+			}
+			return schemaChangeDetectedError{highWater.Next()}
+		}
+
 		// Resolve all of the spans as a boundary if the policy indicates that
 		// we should do so.
 		if f.schemaChangePolicy != changefeedbase.OptSchemaChangePolicyNoBackfill {
 			for _, span := range f.spans {
-				if err := f.sink.AddResolved(ctx, span, highWater, true); err != nil {
+				if err := f.sink.AddResolved(ctx, span, highWater, jobspb.ResolvedSpan_BACKFILL); err != nil {
 					return err
 				}
 			}
@@ -205,6 +248,56 @@ func (f *kvFeed) run(ctx context.Context) (err error) {
 	}
 }
 
+// runInitialScanOnly performs the initial scan, if one is still needed, and
+// then resolves all of the spans at the scan timestamp as a boundary instead of
+// starting the rangefeed. If the feed is resumed after the scan completed,
+// only the boundary is resolved again.
+func (f *kvFeed) runInitialScanOnly(ctx context.Context, highWater hlc.Timestamp) error {
+	if f.withInitialBackfill {
+		if err := f.scanIfShould(ctx, true /* initialScan */, highWater); err != nil {
+			return err
+		}
+	}
+	for _, span := range f.spans {
+		if err := f.sink.AddResolved(ctx, span, highWater, jobspb.ResolvedSpan_BACKFILL); err != nil {
+			return err
+		}
+	}
+	return errInitialScanComplete
+}
+
+// tableSetChanged returns whether the table events at ts drop a watched table
+// or add a table which isn't watched yet. Both are only emitted by the
+// schemafeed of a changefeed on a database.
+func (f *kvFeed) tableSetChanged(ctx context.Context, ts hlc.Timestamp) (bool, error) {
+	events, err := f.tableFeed.Peek(ctx, ts)
+	if err != nil {
+		return false, err
+	}
+	for _, ev := range events {
+		if ev.After.Dropped() {
+			return true, nil
+		}
+		if ev.Before == nil && len(f.spansForTable(ev.After.ID)) == 0 {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// spansForTable returns the watched spans which overlap the given table.
+func (f *kvFeed) spansForTable(id descpb.ID) []roachpb.Span {
+	tablePrefix := f.codec.TablePrefix(uint32(id))
+	tableSpan := roachpb.Span{Key: tablePrefix, EndKey: tablePrefix.PrefixEnd()}
+	var spans []roachpb.Span
+	for _, sp := range f.spans {
+		if tableSpan.Overlaps(sp) {
+			spans = append(spans, sp)
+		}
+	}
+	return spans
+}
+
 func (f *kvFeed) scanIfShould(
 	ctx context.Context, initialScan bool, highWater hlc.Timestamp,
 ) error {
@@ -221,22 +314,21 @@ func (f *kvFeed) scanIfShould(
 	var spansToBackfill []roachpb.Span
 	if isInitialScan {
 		scanTime = highWater
-		spansToBackfill = f.spans
+		spansToBackfill = roachpb.SubtractSpans(append([]roachpb.Span(nil), f.spans...), f.checkpoint)
 	} else if len(events) > 0 {
 		// Only backfill for the tables which have events which may not be all
 		// of the targets.
 		for _, ev := range events {
-			tablePrefix := f.codec.TablePrefix(uint32(ev.After.ID))
-			tableSpan := roachpb.Span{Key: tablePrefix, EndKey: tablePrefix.PrefixEnd()}
-			for _, sp := range f.spans {
-				if tableSpan.Overlaps(sp) {
-					spansToBackfill = append(spansToBackfill, sp)
-				}
-			}
 			if !scanTime.Equal(ev.After.ModificationTime) {
 				log.Fatalf(ctx, "found event in shouldScan which did not occur at the scan time %v: %v",
 					scanTime, ev)
 			}
+			// A table added to a watched database gets its initial scan at the
+			// time it was added, whatever the schema change policy.
+			if ev.Before != nil && f.schemaChangePolicy == changefeedbase.OptSchemaChangePolicyNoBackfill {
+				continue
+			}
+			spansToBackfill = append(spansToBackfill, f.spansForTable(ev.After.ID)...)
 		}
 	} else {
 		return nil
@@ -247,7 +339,7 @@ func (f *kvFeed) scanIfShould(
 		return err
 	}
 
-	if !isInitialScan && f.schemaChangePolicy == changefeedbase.OptSchemaChangePolicyNoBackfill {
+	if len(spansToBackfill) == 0 {
 		return nil
 	}
 
@@ -379,7 +471,7 @@ func copyFromSourceToSinkUntilTableEvent(
 				// The logic currently doesn't make this clean.
 				resolved := e.Resolved()
 				frontier.Forward(resolved.Span, resolved.Timestamp)
-				return sink.AddResolved(ctx, resolved.Span, resolved.Timestamp, false)
+				return sink.AddResolved(ctx, resolved.Span, resolved.Timestamp, jobspb.ResolvedSpan_NONE)
 			default:
 				log.Fatal(ctx, "unknown event type")
 				return nil
@@ -415,6 +507,7 @@ func makeTablefeedConfig(cfg Config) schemafeed.Config {
 		Clock:              cfg.Clock,
 		Settings:           cfg.Settings,
 		Targets:            cfg.Targets,
+		DatabaseID:         cfg.DatabaseID,
 		LeaseManager:       cfg.LeaseMgr,
 		SchemaChangeEvents: cfg.SchemaChangeEvents,
 		InitialHighWater:   cfg.InitialHighWater,
@@ -77,17 +77,21 @@ func TestKVFeed(t *testing.T) {
 		name               string
 		needsInitialScan   bool
 		withDiff           bool
+		initialScanOnly    bool
 		schemaChangeEvents changefeedbase.SchemaChangeEventClass
 		schemaChangePolicy changefeedbase.SchemaChangePolicy
 		initialHighWater   hlc.Timestamp
 		spans              []roachpb.Span
+		checkpoint         []roachpb.Span
 		events             []roachpb.RangeFeedEvent
 
 		descs []*tabledesc.Immutable
 
-		expScans  []hlc.Timestamp
-		expEvents int
-		expErrRE  string
+		expScans []hlc.Timestamp
+		// expScanSpans, if set, are the spans scanned by each of expScans.
+		expScanSpans [][]roachpb.Span
+		expEvents    int
+		expErrRE     string
 	}
 	runTest := func(t *testing.T, tc testCase) {
 		settings := cluster.MakeTestingClusterSettings()
@@ -111,9 +115,9 @@ func TestKVFeed(t *testing.T) {
 		})
 		ref := rawEventFeed(tc.events)
 		tf := newRawTableFeed(tc.descs, tc.initialHighWater)
-		f := newKVFeed(buf, tc.spans,
+		f := newKVFeed(buf, tc.spans, tc.checkpoint,
 			tc.schemaChangeEvents, tc.schemaChangePolicy,
-			tc.needsInitialScan, tc.withDiff,
+			tc.needsInitialScan, tc.withDiff, tc.initialScanOnly,
 			tc.initialHighWater,
 			keys.SystemSQLCodec,
 			&tf, sf, rangefeedFactory(ref.run), bufferFactory)
@@ -124,10 +128,13 @@ func TestKVFeed(t *testing.T) {
 		})
 		testG := ctxgroup.WithContext(ctx)
 		testG.GoCtx(func(ctx context.Context) error {
-			for expScans := tc.expScans; len(expScans) > 0; expScans = expScans[1:] {
+			for i, expScan := range tc.expScans {
 				scan := <-scans
-				assert.Equal(t, expScans[0], scan.Timestamp)
+				assert.Equal(t, expScan, scan.Timestamp)
 				assert.Equal(t, tc.withDiff, scan.WithDiff)
+				if tc.expScanSpans != nil {
+					assert.Equal(t, tc.expScanSpans[i], scan.Spans)
+				}
 			}
 			return nil
 		})
@@ -139,7 +146,7 @@ func TestKVFeed(t *testing.T) {
 			return nil
 		})
 		// Wait for the feed to fail rather than canceling it.
-		if tc.schemaChangePolicy == changefeedbase.OptSchemaChangePolicyStop {
+		if tc.schemaChangePolicy == changefeedbase.OptSchemaChangePolicyStop || tc.initialScanOnly {
 			testG.Go(func() error {
 				_ = g.Wait()
 				return nil
@@ -173,6 +180,30 @@ func TestKVFeed(t *testing.T) {
 			},
 			expEvents: 1,
 		},
+		{
+			name:               "checkpoint - partial initial scan",
+			schemaChangeEvents: changefeedbase.OptSchemaChangeEventClassDefault,
+			schemaChangePolicy: changefeedbase.OptSchemaChangePolicyBackfill,
+			needsInitialScan:   true,
+			initialHighWater:   ts(2),
+			spans: []roachpb.Span{
+				tableSpan(42),
+				tableSpan(43),
+			},
+			checkpoint: []roachpb.Span{
+				tableSpan(42),
+			},
+			events: []roachpb.RangeFeedEvent{
+				kvEvent(42, "a", "b", ts(3)),
+			},
+			expScans: []hlc.Timestamp{
+				ts(2),
+			},
+			expScanSpans: [][]roachpb.Span{
+				{tableSpan(43)},
+			},
+			expEvents: 1,
+		},
 		{
 			name:               "one table event - backfill",
 			schemaChangeEvents: changefeedbase.OptSchemaChangeEventClassDefault,
@@ -249,6 +280,41 @@ func TestKVFeed(t *testing.T) {
 			expEvents: 2,
 			expErrRE:  "schema change ...",
 		},
+		{
+			name:               "initial scan only",
+			schemaChangeEvents: changefeedbase.OptSchemaChangeEventClassDefault,
+			schemaChangePolicy: changefeedbase.OptSchemaChangePolicyBackfill,
+			needsInitialScan:   true,
+			initialScanOnly:    true,
+			initialHighWater:   ts(2),
+			spans: []roachpb.Span{
+				tableSpan(42),
+			},
+			events: []roachpb.RangeFeedEvent{
+				kvEvent(42, "a", "b", ts(3)),
+			},
+			expScans: []hlc.Timestamp{
+				ts(2),
+			},
+			// Only the boundary; the rangefeed is never started.
+			expEvents: 1,
+			expErrRE:  "initial scan complete",
+		},
+		{
+			name:               "initial scan only - resumed after scan",
+			schemaChangeEvents: changefeedbase.OptSchemaChangeEventClassDefault,
+			schemaChangePolicy: changefeedbase.OptSchemaChangePolicyBackfill,
+			initialScanOnly:    true,
+			initialHighWater:   ts(2),
+			spans: []roachpb.Span{
+				tableSpan(42),
+			},
+			events: []roachpb.RangeFeedEvent{
+				kvEvent(42, "a", "b", ts(3)),
+			},
+			expEvents: 1,
+			expErrRE:  "initial scan complete",
+		},
 	} {
 		t.Run(tc.name, func(t *testing.T) {
 			runTest(t, tc)
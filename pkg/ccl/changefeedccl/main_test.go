@@ -20,6 +20,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
 	"github.com/cockroachdb/cockroach/pkg/testutils/testcluster"
 	"github.com/cockroachdb/cockroach/pkg/util/randutil"
+	"go.opencensus.io/stats/view"
 )
 
 func TestMain(m *testing.M) {
@@ -28,6 +29,10 @@ func TestMain(m *testing.M) {
 	randutil.SeedForTests()
 	serverutils.InitTestServerFactory(server.TestServerFactory)
 	serverutils.InitTestClusterFactory(testcluster.TestClusterFactory)
+	// The OpenCensus stats worker linked in by the Google Cloud clients is started
+	// by an init function. Wait for it to be running so that the leak check of
+	// the first test does not report it.
+	view.SetReportingPeriod(0)
 	os.Exit(m.Run())
 }
 
@@ -17,6 +17,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/metric"
+	"github.com/cockroachdb/cockroach/pkg/util/mon"
 	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
 	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
 )
@@ -149,6 +150,18 @@ var (
 		Measurement: "Nanoseconds",
 		Unit:        metric.Unit_NANOSECONDS,
 	}
+	metaChangefeedMemoryCurrentBytes = metric.Metadata{
+		Name:        "changefeed.memory.current_bytes",
+		Help:        "Memory currently used to buffer changes by all feeds",
+		Measurement: "Memory",
+		Unit:        metric.Unit_BYTES,
+	}
+	metaChangefeedMemoryMaxFeedBytes = metric.Metadata{
+		Name:        "changefeed.memory.max_feed_bytes",
+		Help:        "Largest memory usage of any running feed",
+		Measurement: "Memory",
+		Unit:        metric.Unit_BYTES,
+	}
 )
 
 // Metrics are for production monitoring of changefeeds.
@@ -171,8 +184,16 @@ type Metrics struct {
 		syncutil.Mutex
 		id       int
 		resolved map[int]hlc.Timestamp
+		// memMonitors contains the memory monitor of every running change
+		// aggregator, keyed by an id handed out by trackMemMonitor.
+		memMonitors map[int]*mon.BytesMonitor
 	}
 	MaxBehindNanos *metric.Gauge
+
+	// MemoryCurrentBytes is updated by the memory monitors of all running
+	// change aggregators.
+	MemoryCurrentBytes *metric.Gauge
+	MemoryMaxFeedBytes *metric.Gauge
 }
 
 // MetricStruct implements the metric.Struct interface.
@@ -193,8 +214,10 @@ func MakeMetrics(histogramWindow time.Duration) metric.Struct {
 		EmitNanos:          metric.NewCounter(metaChangefeedEmitNanos),
 		FlushNanos:         metric.NewCounter(metaChangefeedFlushNanos),
 		Running:            metric.NewGauge(metaChangefeedRunning),
+		MemoryCurrentBytes: metric.NewGauge(metaChangefeedMemoryCurrentBytes),
 	}
 	m.mu.resolved = make(map[int]hlc.Timestamp)
+	m.mu.memMonitors = make(map[int]*mon.BytesMonitor)
 	m.mu.id = 1 // start the first id at 1 so we can detect initialization
 	m.MaxBehindNanos = metric.NewFunctionalGauge(metaChangefeedMaxBehindNanos, func() int64 {
 		now := timeutil.Now()
@@ -208,9 +231,36 @@ func MakeMetrics(histogramWindow time.Duration) metric.Struct {
 		m.mu.Unlock()
 		return maxBehind.Nanoseconds()
 	})
+	m.MemoryMaxFeedBytes = metric.NewFunctionalGauge(metaChangefeedMemoryMaxFeedBytes, func() int64 {
+		var maxBytes int64
+		m.mu.Lock()
+		for _, mm := range m.mu.memMonitors {
+			if b := mm.AllocBytes(); b > maxBytes {
+				maxBytes = b
+			}
+		}
+		m.mu.Unlock()
+		return maxBytes
+	})
 	return m
 }
 
+// trackMemMonitor registers the memory monitor of a running change aggregator
+// so that it's considered by `changefeed.memory.max_feed_bytes`. The returned
+// function de-registers it.
+func (m *Metrics) trackMemMonitor(mm *mon.BytesMonitor) (untrack func()) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	id := m.mu.id
+	m.mu.id++
+	m.mu.memMonitors[id] = mm
+	return func() {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		delete(m.mu.memMonitors, id)
+	}
+}
+
 func init() {
 	jobs.MakeChangefeedMetricsHook = MakeMetrics
 }
@@ -9,6 +9,7 @@
 package changefeedccl
 
 import (
+	"strings"
 	"testing"
 	"unicode/utf8"
 
@@ -91,3 +92,35 @@ func TestSQLNameToAvroName(t *testing.T) {
 	// We don't produce capital letters in escapes but check them anyway.
 	require.Equal(t, `/`, KafkaNameToSQLName(`_u2F_`))
 }
+
+func TestSQLNameToPubsubName(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	tests := []struct {
+		sql, pubsub string
+	}{
+		{`foo`, `foo`},
+		{`abcdefghijklmnopqrstuvwxyz`, `abcdefghijklmnopqrstuvwxyz`},
+		{`ABCDEFGHIJKLMNOPQRSTUVWXYZ`, `ABCDEFGHIJKLMNOPQRSTUVWXYZ`},
+		{`0123456789_-.~+%`, `0123456789_-.~+%`},
+		{`!`, `_u0021_`},
+		{`!@#$%^&*()`, `_u0021__u0040__u0023__u0024_%_u005e__u0026__u002a__u0028__u0029_`},
+		{`foo!`, `foo_u0021_`},
+		{`foo!bar`, `foo_u0021_bar`},
+		{`foo_u0021_bar`, `foo_u005f__u0075__u0030__u0030__u0032__u0031__u005f_bar`},
+		{`/`, `_u002f_`},
+		{`☃`, `_u2603_`},
+		{"\x00", `_u0000_`},
+		{string(rune(utf8.MaxRune)), `_u0010ffff_`},
+	}
+	for i, test := range tests {
+		if p := SQLNameToPubsubName(test.sql); p != test.pubsub {
+			t.Errorf(`%d: %s did not escape to %s got %s`, i, test.sql, test.pubsub, p)
+		}
+		if s := PubsubNameToSQLName(test.pubsub); s != test.sql {
+			t.Errorf(`%d: %s did not unescape to %s got %s`, i, test.pubsub, test.sql, s)
+		}
+	}
+	require.Len(t, SQLNameToPubsubName(strings.Repeat(`a`, 300)), 255)
+}
@@ -16,17 +16,20 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/catalogkv"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descs"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/hydratedtables"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/lease"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/tabledesc"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/typedesc"
 	"github.com/cockroachdb/cockroach/pkg/sql/row"
 	"github.com/cockroachdb/cockroach/pkg/sql/rowenc"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/util"
 	"github.com/cockroachdb/cockroach/pkg/util/encoding"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/errors"
 )
 
 // rowFetcherCache maintains a cache of single table RowFetchers. Given a key
@@ -84,41 +87,21 @@ func (c *rowFetcherCache) TableDescForKey(
 		// Retrieve the target TableDescriptor from the lease manager. No caching
 		// is attempted because the lease manager does its own caching.
 		desc, _, err := c.leaseMgr.Acquire(ctx, ts, tableID)
-		if err != nil {
+		if errors.Is(err, catalog.ErrDescriptorDropped) {
+			// Tables in a watched database are dropped without failing the
+			// changefeed, which may still have to emit the rows written to them
+			// before they were dropped. Those rows are decoded with the descriptor
+			// as of their timestamp, which the lease manager doesn't lease anymore.
+			if tableDesc, err = c.droppedTableDescAt(ctx, tableID, ts); err != nil {
+				return nil, MarkRetryableError(err)
+			}
+		} else if err != nil {
 			// Manager can return all kinds of errors during chaos, but based on
 			// its usage, none of them should ever be terminal.
 			return nil, MarkRetryableError(err)
-		}
-		// Immediately release the lease, since we only need it for the exact
-		// timestamp requested.
-		if err := c.leaseMgr.Release(desc); err != nil {
+		} else if tableDesc, err = c.leasedTableDesc(ctx, desc, tableID, ts); err != nil {
 			return nil, err
 		}
-		tableDesc = desc.(*tabledesc.Immutable)
-		if tableDesc.ContainsUserDefinedTypes() {
-			// If the table contains user defined types, then use the descs.Collection
-			// to retrieve a TableDescriptor with type metadata hydrated. We open a
-			// transaction here only because the descs.Collection needs one to get
-			// a read timestamp. We do this lookup again behind a conditional to avoid
-			// allocating any transaction metadata if the table has user defined types.
-			// This can be bypassed once (#53751) is fixed. Once the descs.Collection can
-			// take in a read timestamp rather than a whole transaction, we can use the
-			// descs.Collection directly here.
-			// TODO (SQL Schema): #53751.
-			if err := c.db.Txn(ctx, func(ctx context.Context, txn *kv.Txn) error {
-				txn.SetFixedTimestamp(ctx, ts)
-				var err error
-				tableDesc, err = c.collection.GetTableVersionByID(ctx, txn, tableID, tree.ObjectLookupFlagsWithRequired())
-				return err
-			}); err != nil {
-				// Manager can return all kinds of errors during chaos, but based on
-				// its usage, none of them should ever be terminal.
-				return nil, MarkRetryableError(err)
-			}
-			// Immediately release the lease, since we only need it for the exact
-			// timestamp requested.
-			c.collection.ReleaseAll(ctx)
-		}
 
 		// Skip over the column data.
 		for ; skippedCols < len(tableDesc.PrimaryIndex.ColumnIDs); skippedCols++ {
@@ -139,6 +122,67 @@ func (c *rowFetcherCache) TableDescForKey(
 	return tableDesc, nil
 }
 
+// leasedTableDesc returns the table descriptor leased by TableDescForKey, with
+// its types hydrated, and releases the lease.
+func (c *rowFetcherCache) leasedTableDesc(
+	ctx context.Context, desc catalog.Descriptor, tableID descpb.ID, ts hlc.Timestamp,
+) (*tabledesc.Immutable, error) {
+	// Immediately release the lease, since we only need it for the exact
+	// timestamp requested.
+	if err := c.leaseMgr.Release(desc); err != nil {
+		return nil, err
+	}
+	tableDesc := desc.(*tabledesc.Immutable)
+	if tableDesc.ContainsUserDefinedTypes() {
+		// If the table contains user defined types, then use the descs.Collection
+		// to retrieve a TableDescriptor with type metadata hydrated. We open a
+		// transaction here only because the descs.Collection needs one to get
+		// a read timestamp. We do this lookup again behind a conditional to avoid
+		// allocating any transaction metadata if the table has user defined types.
+		// This can be bypassed once (#53751) is fixed. Once the descs.Collection can
+		// take in a read timestamp rather than a whole transaction, we can use the
+		// descs.Collection directly here.
+		// TODO (SQL Schema): #53751.
+		if err := c.db.Txn(ctx, func(ctx context.Context, txn *kv.Txn) error {
+			txn.SetFixedTimestamp(ctx, ts)
+			var err error
+			tableDesc, err = c.collection.GetTableVersionByID(ctx, txn, tableID, tree.ObjectLookupFlagsWithRequired())
+			return err
+		}); err != nil {
+			// Manager can return all kinds of errors during chaos, but based on
+			// its usage, none of them should ever be terminal.
+			return nil, MarkRetryableError(err)
+		}
+		// Immediately release the lease, since we only need it for the exact
+		// timestamp requested.
+		c.collection.ReleaseAll(ctx)
+	}
+
+	return tableDesc, nil
+}
+
+// droppedTableDescAt reads the descriptor of a dropped table as of ts, with its
+// types hydrated.
+func (c *rowFetcherCache) droppedTableDescAt(
+	ctx context.Context, tableID descpb.ID, ts hlc.Timestamp,
+) (*tabledesc.Immutable, error) {
+	var tableDesc *tabledesc.Immutable
+	err := c.db.Txn(ctx, func(ctx context.Context, txn *kv.Txn) error {
+		txn.SetFixedTimestamp(ctx, ts)
+		var err error
+		if tableDesc, err = catalogkv.MustGetTableDescByID(ctx, txn, c.codec, tableID); err != nil {
+			return err
+		}
+		if !tableDesc.ContainsUserDefinedTypes() {
+			return nil
+		}
+		defer c.collection.ReleaseAll(ctx)
+		return typedesc.HydrateTypesInTableDescriptor(
+			ctx, tableDesc.TableDesc(), descs.NewDistSQLTypeResolver(c.collection, txn))
+	})
+	return tableDesc, err
+}
+
 func (c *rowFetcherCache) RowFetcherForTableDesc(
 	tableDesc *tabledesc.Immutable,
 ) (*row.Fetcher, error) {
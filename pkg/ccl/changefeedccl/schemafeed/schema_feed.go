@@ -40,7 +40,8 @@ import (
 // too hard. Each registered queue would have a start time. You'd scan from the
 // earliest and just ingest the relevant descriptors.
 
-// TableEvent represents a change to a table descriptor.
+// TableEvent represents a change to a table descriptor. Before is nil if the
+// table was added to a watched database.
 type TableEvent struct {
 	Before, After *tabledesc.Immutable
 }
@@ -57,6 +58,11 @@ type Config struct {
 	Settings *cluster.Settings
 	Targets  jobspb.ChangefeedTargets
 
+	// DatabaseID, if set, is the database watched by the changefeed. Tables
+	// created in it and targets dropped from it are then emitted as events
+	// instead of being ignored or failing the feed, respectively.
+	DatabaseID descpb.ID
+
 	// SchemaChangeEvents controls the class of events which are emitted by this
 	// SchemaFeed.
 	SchemaChangeEvents changefeedbase.SchemaChangeEventClass
@@ -95,8 +101,10 @@ type SchemaFeed struct {
 	clock    *hlc.Clock
 	settings *cluster.Settings
 	targets  jobspb.ChangefeedTargets
-	leaseMgr *lease.Manager
-	mu       struct {
+	// databaseID is the watched database, if any. See Config.DatabaseID.
+	databaseID descpb.ID
+	leaseMgr   *lease.Manager
+	mu         struct {
 		syncutil.Mutex
 
 		started bool
@@ -200,12 +208,13 @@ type tableHistoryWaiter struct {
 func New(cfg Config) *SchemaFeed {
 	// TODO(ajwerner): validate config.
 	m := &SchemaFeed{
-		filter:   schemaChangeEventFilters[cfg.SchemaChangeEvents],
-		db:       cfg.DB,
-		clock:    cfg.Clock,
-		settings: cfg.Settings,
-		targets:  cfg.Targets,
-		leaseMgr: cfg.LeaseManager,
+		filter:     schemaChangeEventFilters[cfg.SchemaChangeEvents],
+		db:         cfg.DB,
+		clock:      cfg.Clock,
+		settings:   cfg.Settings,
+		targets:    cfg.Targets,
+		databaseID: cfg.DatabaseID,
+		leaseMgr:   cfg.LeaseManager,
 	}
 	m.mu.previousTableVersion = make(map[descpb.ID]*tabledesc.Immutable)
 	m.mu.highWater = cfg.InitialHighWater
@@ -261,6 +270,20 @@ func (tf *SchemaFeed) primeInitialTableDescs(ctx context.Context) error {
 		txn.SetFixedTimestamp(ctx, initialTableDescTs)
 		// Note that all targets are currently guaranteed to be tables.
 		for tableID := range tf.targets {
+			if tf.databaseID != descpb.InvalidID {
+				// The targets of a changefeed on a database include the tables
+				// added at the high-water's successor, which don't exist yet or
+				// aren't public. Those are picked up as added tables instead.
+				desc, err := catalogkv.GetDescriptorByID(ctx, txn, keys.SystemSQLCodec, tableID,
+					catalogkv.Immutable, catalogkv.TableDescriptorKind, false /* required */)
+				if err != nil {
+					return err
+				}
+				if desc != nil && desc.Public() {
+					initialDescs = append(initialDescs, desc)
+				}
+				continue
+			}
 			tableDesc, err := catalogkv.MustGetTableDescByID(ctx, txn, keys.SystemSQLCodec, tableID)
 			if err != nil {
 				return err
@@ -278,6 +301,11 @@ func (tf *SchemaFeed) primeInitialTableDescs(ctx context.Context) error {
 	for _, desc := range initialDescs {
 		tbl := desc.(*tabledesc.Immutable)
 		tf.mu.typeDeps.ingestTable(tbl)
+		if tf.databaseID != descpb.InvalidID {
+			// Record the initial tables of a watched database so that they
+			// aren't mistaken for added ones.
+			tf.mu.previousTableVersion[tbl.ID] = tbl
+		}
 	}
 	tf.mu.Unlock()
 
@@ -479,6 +507,9 @@ func formatDesc(desc *tabledesc.Immutable) string {
 }
 
 func formatEvent(e TableEvent) string {
+	if e.Before == nil {
+		return fmt.Sprintf("added %v", formatDesc(e.After))
+	}
 	return fmt.Sprintf("%v->%v", formatDesc(e.Before), formatDesc(e.After))
 }
 
@@ -496,6 +527,15 @@ func (tf *SchemaFeed) validateDescriptor(
 		// manager to acquire the freshest version of the type.
 		return tf.leaseMgr.AcquireFreshestFromStore(ctx, desc.ID)
 	case *tabledesc.Immutable:
+		if tf.databaseID != descpb.InvalidID {
+			if e, handled := tf.databaseTableEventLocked(desc); handled {
+				if e != (TableEvent{}) {
+					log.Infof(ctx, "validate %v", formatEvent(e))
+					tf.addEventLocked(e, earliestTsBeingIngested)
+				}
+				return nil
+			}
+		}
 		if err := changefeedbase.ValidateTable(tf.targets, desc); err != nil {
 			return err
 		}
@@ -530,17 +570,7 @@ func (tf *SchemaFeed) validateDescriptor(
 				return err
 			}
 			if !shouldFilter {
-				// Only sort the tail of the events from earliestTsBeingIngested.
-				// The head could already have been handed out and sorting is not
-				// stable.
-				idxToSort := sort.Search(len(tf.mu.events), func(i int) bool {
-					return !tf.mu.events[i].After.ModificationTime.Less(earliestTsBeingIngested)
-				})
-				tf.mu.events = append(tf.mu.events, e)
-				toSort := tf.mu.events[idxToSort:]
-				sort.Slice(toSort, func(i, j int) bool {
-					return descLess(toSort[i].After, toSort[j].After)
-				})
+				tf.addEventLocked(e, earliestTsBeingIngested)
 			}
 		}
 		// Add the types used by the table into the dependency tracker.
@@ -552,6 +582,53 @@ func (tf *SchemaFeed) validateDescriptor(
 	}
 }
 
+// addEventLocked adds a table event to the queue.
+func (tf *SchemaFeed) addEventLocked(e TableEvent, earliestTsBeingIngested hlc.Timestamp) {
+	// Only sort the tail of the events from earliestTsBeingIngested.
+	// The head could already have been handed out and sorting is not
+	// stable.
+	idxToSort := sort.Search(len(tf.mu.events), func(i int) bool {
+		return !tf.mu.events[i].After.ModificationTime.Less(earliestTsBeingIngested)
+	})
+	tf.mu.events = append(tf.mu.events, e)
+	toSort := tf.mu.events[idxToSort:]
+	sort.Slice(toSort, func(i, j int) bool {
+		return descLess(toSort[i].After, toSort[j].After)
+	})
+}
+
+// databaseTableEventLocked handles the versions of tables in a watched
+// database which add a table to or drop a table from the changefeed. It returns
+// the event to emit, if any, and whether the descriptor was handled. Versions
+// which aren't handled are validated like those of any other target.
+func (tf *SchemaFeed) databaseTableEventLocked(desc *tabledesc.Immutable) (TableEvent, bool) {
+	lastVersion, seen := tf.mu.previousTableVersion[desc.ID]
+	if !seen {
+		// Tables are added once they become public. Views and sequences can't
+		// be watched, so they're never added.
+		if !desc.Public() || desc.IsView() || desc.IsSequence() || desc.IsVirtualTable() {
+			return TableEvent{}, true
+		}
+		tf.mu.typeDeps.ingestTable(desc)
+		tf.mu.previousTableVersion[desc.ID] = desc
+		return TableEvent{After: desc}, true
+	}
+	if _, isTarget := tf.targets[desc.ID]; !isTarget {
+		// The table was added but the changefeed hasn't been restarted to watch
+		// it yet, so there's nothing to validate.
+		return TableEvent{}, true
+	}
+	if !desc.Dropped() {
+		return TableEvent{}, false
+	}
+	if desc.ModificationTime.LessEq(lastVersion.ModificationTime) || lastVersion.Dropped() {
+		return TableEvent{}, true
+	}
+	tf.mu.typeDeps.purgeTable(lastVersion)
+	tf.mu.previousTableVersion[desc.ID] = desc
+	return TableEvent{Before: lastVersion, After: desc}, true
+}
+
 func (tf *SchemaFeed) fetchDescriptorVersions(
 	ctx context.Context, db *kv.DB, startTS, endTS hlc.Timestamp,
 ) ([]catalog.Descriptor, error) {
@@ -607,14 +684,23 @@ func (tf *SchemaFeed) fetchDescriptorVersions(
 
 				origName, isTable := tf.targets[descpb.ID(id)]
 				isType := tf.mu.typeDeps.containsType(descpb.ID(id))
+				// Every table in a watched database is interesting, but that
+				// can only be determined once the descriptor is unmarshaled.
+				watchingDatabase := tf.databaseID != descpb.InvalidID
 				// Check if the descriptor is an interesting table or type.
-				if !(isTable || isType) {
+				if !(isTable || isType || watchingDatabase) {
 					// Uninteresting descriptor.
 					continue
 				}
 
 				unsafeValue := it.UnsafeValue()
 				if unsafeValue == nil {
+					if watchingDatabase {
+						// Tables in a watched database are removed from the
+						// changefeed when they're dropped, before their descriptor
+						// is deleted.
+						continue
+					}
 					name := origName.StatementTimeName
 					if name == "" {
 						name = fmt.Sprintf("desc(%d)", id)
@@ -630,8 +716,11 @@ func (tf *SchemaFeed) fetchDescriptorVersions(
 				}
 
 				if tableDesc := descpb.TableFromDescriptor(&desc, k.Timestamp); tableDesc != nil {
+					if !isTable && tableDesc.ParentID != tf.databaseID {
+						continue
+					}
 					descs = append(descs, tabledesc.NewImmutable(*tableDesc))
-				} else if typeDesc := descpb.TypeFromDescriptor(&desc, k.Timestamp); typeDesc != nil {
+				} else if typeDesc := descpb.TypeFromDescriptor(&desc, k.Timestamp); typeDesc != nil && isType {
 					descs = append(descs, typedesc.NewImmutable(*typeDesc))
 				}
 			}
@@ -0,0 +1,364 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package changefeedccl
+
+import (
+	"context"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/ccl/changefeedccl/changefeedbase"
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/colinfo"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/schemaexpr"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/rowenc"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/transform"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/errors"
+)
+
+// selectRejectFlags are the expressions that aren't allowed in the SELECT
+// clause of a changefeed. Every row is evaluated independently as it changes,
+// so neither aggregation nor subqueries make sense. Volatile functions are
+// rejected because a row that is emitted more than once (which changefeeds are
+// allowed to do) would not be guaranteed to produce the same result.
+const selectRejectFlags = tree.RejectSpecial | tree.RejectSubqueries | tree.RejectVolatileFunctions
+
+// projectedRow is the result of evaluating the target list of a CREATE
+// CHANGEFEED ... AS SELECT statement against a changed row.
+type projectedRow struct {
+	colNames []string
+	datums   tree.Datums
+}
+
+// asJSONEntries returns the projected columns as a map from column name to
+// JSON value, for use by the jsonEncoder.
+func (r *projectedRow) asJSONEntries() (map[string]interface{}, error) {
+	entries := make(map[string]interface{}, len(r.colNames))
+	for i, colName := range r.colNames {
+		var err error
+		if entries[colName], err = tree.AsJSON(r.datums[i], time.UTC); err != nil {
+			return nil, err
+		}
+	}
+	return entries, nil
+}
+
+// selectRowContainer is a tree.IndexedVarContainer over a row as decoded by
+// the row.Fetcher, which has one datum per public column of the table.
+type selectRowContainer struct {
+	cols  []descpb.ColumnDescriptor
+	row   rowenc.EncDatumRow
+	alloc *rowenc.DatumAlloc
+}
+
+var _ tree.IndexedVarContainer = &selectRowContainer{}
+
+// IndexedVarEval implements the tree.IndexedVarContainer interface.
+func (c *selectRowContainer) IndexedVarEval(idx int, _ *tree.EvalContext) (tree.Datum, error) {
+	datum := &c.row[idx]
+	if err := datum.EnsureDecoded(c.cols[idx].Type, c.alloc); err != nil {
+		return nil, err
+	}
+	return datum.Datum, nil
+}
+
+// IndexedVarResolvedType implements the tree.IndexedVarContainer interface.
+func (c *selectRowContainer) IndexedVarResolvedType(idx int) *types.T {
+	return c.cols[idx].Type
+}
+
+// IndexedVarNodeFormatter implements the tree.IndexedVarContainer interface.
+func (c *selectRowContainer) IndexedVarNodeFormatter(idx int) tree.NodeFormatter {
+	n := tree.Name(c.cols[idx].Name)
+	return &n
+}
+
+// compiledSelect is the SELECT clause of a changefeed, resolved and
+// type-checked against one version of the table descriptor.
+type compiledSelect struct {
+	ivars    selectRowContainer
+	colNames []string
+	exprs    []tree.TypedExpr
+	// filter is nil if the SELECT clause has no WHERE clause.
+	filter tree.TypedExpr
+}
+
+// compileSelect resolves the column references in the given SELECT clause
+// against the public columns of the table and type-checks its target list and
+// WHERE clause. This is done both when the changefeed is planned, to reject
+// invalid statements, and whenever a new version of the table descriptor is
+// seen while the changefeed runs.
+func compileSelect(
+	ctx context.Context,
+	evalCtx *tree.EvalContext,
+	semaCtx *tree.SemaContext,
+	desc catalog.TableDescriptor,
+	sel *tree.SelectClause,
+) (*compiledSelect, error) {
+	if len(sel.From.Tables) != 1 {
+		return nil, errors.AssertionFailedf(`expected a single table in %s`, tree.AsString(sel))
+	}
+	tableExpr := sel.From.Tables[0]
+	// The SELECT clause comes straight from the grammar at planning time, but
+	// it's been round-tripped through the parser when the changefeed runs.
+	if aliased, ok := tableExpr.(*tree.AliasedTableExpr); ok {
+		tableExpr = aliased.Expr
+	}
+	tn, ok := tableExpr.(*tree.TableName)
+	if !ok {
+		return nil, errors.AssertionFailedf(`expected a table name in %s`, tree.AsString(sel))
+	}
+
+	cols := desc.GetPublicColumns()
+	c := &compiledSelect{ivars: selectRowContainer{cols: cols}}
+	ivarHelper := tree.MakeIndexedVarHelper(&c.ivars, len(cols))
+	source := colinfo.NewSourceInfoForSingleTable(
+		*tn, colinfo.ResultColumnsFromColDescs(desc.GetID(), cols),
+	)
+
+	// We need to save and restore the previous values of these fields in
+	// semaCtx because it may be the planner's.
+	defer semaCtx.Properties.Restore(semaCtx.Properties)
+	defer func(ivars tree.IndexedVarContainer) { semaCtx.IVarContainer = ivars }(semaCtx.IVarContainer)
+	semaCtx.IVarContainer = &c.ivars
+
+	var txCtx transform.ExprTransformContext
+	typeCheck := func(expr tree.Expr, required *types.T, op string) (tree.TypedExpr, error) {
+		var v schemaexpr.NameResolutionVisitor
+		expr, err := schemaexpr.ResolveNamesUsingVisitor(
+			&v, expr, source, ivarHelper, evalCtx.SessionData.SearchPath)
+		if err != nil {
+			return nil, err
+		}
+		semaCtx.Properties.Require(`CHANGEFEED`, selectRejectFlags)
+		typedExpr, err := tree.TypeCheckAndRequire(ctx, expr, semaCtx, required, op)
+		if err != nil {
+			return nil, err
+		}
+		return txCtx.NormalizeExpr(evalCtx, typedExpr)
+	}
+
+	for _, target := range sel.Exprs {
+		if vn, ok := target.Expr.(tree.VarName); ok {
+			vn, err := vn.NormalizeVarName()
+			if err != nil {
+				return nil, err
+			}
+			switch vn.(type) {
+			case tree.UnqualifiedStar, *tree.AllColumnsSelector:
+				if target.As != `` {
+					return nil, errors.Errorf(`"%s" cannot be aliased`, tree.AsString(vn))
+				}
+				for i := range cols {
+					c.colNames = append(c.colNames, cols[i].Name)
+					c.exprs = append(c.exprs, ivarHelper.IndexedVar(i))
+				}
+				continue
+			}
+		}
+		colName, err := tree.GetRenderColName(evalCtx.SessionData.SearchPath, target)
+		if err != nil {
+			return nil, err
+		}
+		typedExpr, err := typeCheck(target.Expr, types.Any, `SELECT`)
+		if err != nil {
+			return nil, err
+		}
+		c.colNames = append(c.colNames, colName)
+		c.exprs = append(c.exprs, typedExpr)
+	}
+
+	if sel.Where != nil {
+		var err error
+		if c.filter, err = typeCheck(sel.Where.Expr, types.Bool, `WHERE`); err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+// selectEvaluator filters and projects the changed rows of a CREATE CHANGEFEED
+// ... AS SELECT statement.
+//
+// A row is emitted if its new value matches the WHERE clause. Deletions only
+// have the primary key of the row, so the WHERE clause can't be evaluated
+// against them. Without the `diff` option, they're always emitted. With it,
+// they're emitted only if the previous value of the row matched. Also with
+// `diff`, an update that makes a previously matching row no longer match is
+// emitted as a deletion, so consumers can tell that it left the result set.
+//
+// It is not concurrency-safe.
+type selectEvaluator struct {
+	evalCtx  *tree.EvalContext
+	semaCtx  tree.SemaContext
+	sel      *tree.SelectClause
+	withDiff bool
+
+	// compiled is keyed by the ID and version of a table descriptor.
+	compiled map[tableIDAndVersion]*compiledSelect
+	alloc    rowenc.DatumAlloc
+}
+
+func newSelectEvaluator(
+	evalCtx *tree.EvalContext, details jobspb.ChangefeedDetails,
+) (*selectEvaluator, error) {
+	stmt, err := parser.ParseOne(details.Select)
+	if err != nil {
+		return nil, err
+	}
+	sel, ok := stmt.AST.(*tree.Select)
+	if !ok {
+		return nil, errors.AssertionFailedf(`expected a SELECT statement: %s`, details.Select)
+	}
+	clause, ok := sel.Select.(*tree.SelectClause)
+	if !ok {
+		return nil, errors.AssertionFailedf(`expected a SELECT clause: %s`, details.Select)
+	}
+	e := &selectEvaluator{
+		evalCtx:  evalCtx,
+		semaCtx:  tree.MakeSemaContext(),
+		sel:      clause,
+		compiled: make(map[tableIDAndVersion]*compiledSelect),
+	}
+	_, e.withDiff = details.Opts[changefeedbase.OptDiff]
+	return e, nil
+}
+
+func (e *selectEvaluator) compiledFor(
+	ctx context.Context, desc catalog.TableDescriptor,
+) (*compiledSelect, error) {
+	key := makeTableIDAndVersion(desc.GetID(), desc.GetVersion())
+	if c, ok := e.compiled[key]; ok {
+		return c, nil
+	}
+	c, err := compileSelect(ctx, e.evalCtx, &e.semaCtx, desc, e.sel)
+	if err != nil {
+		return nil, errors.Wrapf(err, `evaluating the SELECT clause of table %s (version %d)`,
+			desc.GetName(), desc.GetVersion())
+	}
+	c.ivars.alloc = &e.alloc
+	e.compiled[key] = c
+	return c, nil
+}
+
+// matches returns whether the given row passes the WHERE clause.
+func (e *selectEvaluator) matches(
+	ctx context.Context, desc catalog.TableDescriptor, row rowenc.EncDatumRow,
+) (bool, error) {
+	c, err := e.compiledFor(ctx, desc)
+	if err != nil {
+		return false, err
+	}
+	if c.filter == nil {
+		return true, nil
+	}
+	c.ivars.row = row
+	e.evalCtx.PushIVarContainer(&c.ivars)
+	defer e.evalCtx.PopIVarContainer()
+	return schemaexpr.RunFilter(c.filter, e.evalCtx)
+}
+
+// project evaluates the target list against the given row.
+func (e *selectEvaluator) project(
+	ctx context.Context, desc catalog.TableDescriptor, row rowenc.EncDatumRow,
+) (*projectedRow, error) {
+	c, err := e.compiledFor(ctx, desc)
+	if err != nil {
+		return nil, err
+	}
+	c.ivars.row = row
+	e.evalCtx.PushIVarContainer(&c.ivars)
+	defer e.evalCtx.PopIVarContainer()
+	projected := &projectedRow{colNames: c.colNames, datums: make(tree.Datums, len(c.exprs))}
+	for i, expr := range c.exprs {
+		if projected.datums[i], err = expr.Eval(e.evalCtx); err != nil {
+			return nil, err
+		}
+	}
+	return projected, nil
+}
+
+// evalRow decides whether the given row is emitted and, if so, fills in its
+// projections.
+func (e *selectEvaluator) evalRow(ctx context.Context, row *encodeRow) (emit bool, _ error) {
+	var matches, prevMatches bool
+	if !row.deleted {
+		var err error
+		if matches, err = e.matches(ctx, row.tableDesc, row.datums); err != nil {
+			return false, err
+		}
+	}
+	hasPrev := row.prevDatums != nil && !row.prevDeleted
+	if hasPrev {
+		var err error
+		if prevMatches, err = e.matches(ctx, row.prevTableDesc, row.prevDatums); err != nil {
+			return false, err
+		}
+	}
+
+	switch {
+	case row.deleted:
+		emit = !e.withDiff || prevMatches
+	case matches:
+		emit = true
+	case e.withDiff && prevMatches:
+		row.deleted = true
+		emit = true
+	}
+	if !emit {
+		return false, nil
+	}
+
+	if !row.deleted {
+		var err error
+		if row.projection, err = e.project(ctx, row.tableDesc, row.datums); err != nil {
+			return false, err
+		}
+	}
+	if hasPrev {
+		var err error
+		if row.prevProjection, err = e.project(ctx, row.prevTableDesc, row.prevDatums); err != nil {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// filterRows wraps a closure returned by kvsToRows, dropping the changed rows
+// that are not emitted and projecting the rest. Resolved spans are passed
+// through untouched.
+func (e *selectEvaluator) filterRows(
+	inputFn func(context.Context) ([]emitEntry, error),
+) func(context.Context) ([]emitEntry, error) {
+	return func(ctx context.Context) ([]emitEntry, error) {
+		entries, err := inputFn(ctx)
+		if err != nil {
+			return nil, err
+		}
+		// Filter in place; inputFn reuses the backing array anyway.
+		filtered := entries[:0]
+		for _, entry := range entries {
+			if entry.row.datums != nil {
+				emit, err := e.evalRow(ctx, &entry.row)
+				if err != nil {
+					return nil, err
+				}
+				if !emit {
+					continue
+				}
+			}
+			filtered = append(filtered, entry)
+		}
+		return filtered, nil
+	}
+}
@@ -37,6 +37,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/builtins"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/storage/cloud"
+	"github.com/cockroachdb/cockroach/pkg/storage/cloudimpl"
 	"github.com/cockroachdb/cockroach/pkg/util/bufalloc"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/humanizeutil"
@@ -110,29 +111,15 @@ func getSink(
 			}
 		}
 		q.Del(changefeedbase.SinkParamSkipTLSVerify)
-		if caCertHex := q.Get(changefeedbase.SinkParamCACert); caCertHex != `` {
-			// TODO(dan): There's a straightforward and unambiguous transformation
-			// between the base 64 encoding defined in RFC 4648 and the URL variant
-			// defined in the same RFC: simply replace all `+` with `-` and `/` with
-			// `_`. Consider always doing this for the user and accepting either
-			// variant.
-			if cfg.caCert, err = base64.StdEncoding.DecodeString(caCertHex); err != nil {
-				return nil, errors.Errorf(`param %s must be base 64 encoded: %s`, changefeedbase.SinkParamCACert, err)
-			}
+		if cfg.caCert, err = consumeBase64Param(q, changefeedbase.SinkParamCACert); err != nil {
+			return nil, err
 		}
-		q.Del(changefeedbase.SinkParamCACert)
-		if clientCertHex := q.Get(changefeedbase.SinkParamClientCert); clientCertHex != `` {
-			if cfg.clientCert, err = base64.StdEncoding.DecodeString(clientCertHex); err != nil {
-				return nil, errors.Errorf(`param %s must be base 64 encoded: %s`, changefeedbase.SinkParamClientCert, err)
-			}
+		if cfg.clientCert, err = consumeBase64Param(q, changefeedbase.SinkParamClientCert); err != nil {
+			return nil, err
 		}
-		q.Del(changefeedbase.SinkParamClientCert)
-		if clientKeyHex := q.Get(changefeedbase.SinkParamClientKey); clientKeyHex != `` {
-			if cfg.clientKey, err = base64.StdEncoding.DecodeString(clientKeyHex); err != nil {
-				return nil, errors.Errorf(`param %s must be base 64 encoded: %s`, changefeedbase.SinkParamClientKey, err)
-			}
+		if cfg.clientKey, err = consumeBase64Param(q, changefeedbase.SinkParamClientKey); err != nil {
+			return nil, err
 		}
-		q.Del(changefeedbase.SinkParamClientKey)
 
 		saslParam := q.Get(changefeedbase.SinkParamSASLEnabled)
 		q.Del(changefeedbase.SinkParamSASLEnabled)
@@ -200,6 +187,48 @@ func getSink(
 				opts, timestampOracle, makeExternalStorageFromURI, user,
 			)
 		}
+	case isWebhookSink(u):
+		var cfg webhookSinkConfig
+		cfg.topicPrefix = q.Get(changefeedbase.SinkParamTopicPrefix)
+		q.Del(changefeedbase.SinkParamTopicPrefix)
+		if tlsVerifyBool := q.Get(changefeedbase.SinkParamSkipTLSVerify); tlsVerifyBool != `` {
+			var err error
+			if cfg.tlsSkipVerify, err = strconv.ParseBool(tlsVerifyBool); err != nil {
+				return nil, errors.Errorf(`param %s must be a bool: %s`, changefeedbase.SinkParamSkipTLSVerify, err)
+			}
+		}
+		q.Del(changefeedbase.SinkParamSkipTLSVerify)
+		if cfg.caCert, err = consumeBase64Param(q, changefeedbase.SinkParamCACert); err != nil {
+			return nil, err
+		}
+		if cfg.clientCert, err = consumeBase64Param(q, changefeedbase.SinkParamClientCert); err != nil {
+			return nil, err
+		}
+		if cfg.clientKey, err = consumeBase64Param(q, changefeedbase.SinkParamClientKey); err != nil {
+			return nil, err
+		}
+		// All sink parameters have been consumed above, so none of them are sent
+		// along to the endpoint.
+		u.RawQuery = ``
+		makeSink = func() (Sink, error) {
+			return makeWebhookSink(cfg, u, opts)
+		}
+	case isPubsubSink(u):
+		var cfg pubsubSinkConfig
+		cfg.projectID = u.Host
+		cfg.topicPrefix = q.Get(changefeedbase.SinkParamTopicPrefix)
+		q.Del(changefeedbase.SinkParamTopicPrefix)
+		cfg.region = q.Get(changefeedbase.SinkParamRegion)
+		q.Del(changefeedbase.SinkParamRegion)
+		cfg.emulatorHost = q.Get(changefeedbase.SinkParamEmulatorHost)
+		q.Del(changefeedbase.SinkParamEmulatorHost)
+		cfg.auth = q.Get(cloudimpl.AuthParam)
+		q.Del(cloudimpl.AuthParam)
+		cfg.credentials = q.Get(cloudimpl.CredentialsParam)
+		q.Del(cloudimpl.CredentialsParam)
+		makeSink = func() (Sink, error) {
+			return makePubsubSink(ctx, cfg, targets)
+		}
 	case u.Scheme == changefeedbase.SinkSchemeExperimentalSQL:
 		// Swap the changefeed prefix for the sql connection one that sqlSink
 		// expects.
@@ -230,6 +259,26 @@ func getSink(
 	return s, nil
 }
 
+// consumeBase64Param decodes the base 64 encoded value of the given sink
+// parameter, if it is set, and removes the parameter from the query.
+func consumeBase64Param(q url.Values, param string) ([]byte, error) {
+	encoded := q.Get(param)
+	q.Del(param)
+	if encoded == `` {
+		return nil, nil
+	}
+	// TODO(dan): There's a straightforward and unambiguous transformation
+	// between the base 64 encoding defined in RFC 4648 and the URL variant
+	// defined in the same RFC: simply replace all `+` with `-` and `/` with
+	// `_`. Consider always doing this for the user and accepting either
+	// variant.
+	decoded, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, errors.Errorf(`param %s must be base 64 encoded: %s`, param, err)
+	}
+	return decoded, nil
+}
+
 // errorWrapperSink delegates to another sink and marks all returned errors as
 // retryable. During changefeed setup, we use the sink once without this to
 // verify configuration, but in the steady state, no sink error should be
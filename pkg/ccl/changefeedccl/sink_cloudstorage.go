@@ -25,11 +25,16 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/rowenc"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
 	"github.com/cockroachdb/cockroach/pkg/storage/cloud"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/parquet"
 	"github.com/cockroachdb/errors"
 	"github.com/google/btree"
+	"github.com/linkedin/goavro/v2"
 )
 
 func isCloudStorageSink(u *url.URL) bool {
@@ -57,6 +62,24 @@ type cloudStorageSinkFile struct {
 	codec   io.WriteCloser
 	rawSize int
 	buf     bytes.Buffer
+
+	// rowTypes and rowDatums are used to decode each row of a format=parquet
+	// or format=avro file before it's added to the file.
+	rowTypes  []*types.T
+	rowDatums tree.Datums
+
+	// parquet buffers the rows of a format=parquet file, which are only
+	// written to buf when the file is flushed.
+	parquet *parquet.Writer
+
+	// ocf writes the rows of a format=avro file to buf as an object container
+	// file. Rows are accumulated in ocfBlock and appended to ocf a block at a
+	// time; ocfBlockSize is the encoded size of the rows in ocfBlock.
+	ocf          *goavro.OCFWriter
+	ocfSchema    *avroDataRecord
+	ocfRow       rowenc.EncDatumRow
+	ocfBlock     []interface{}
+	ocfBlockSize int
 }
 
 var _ io.Writer = &cloudStorageSinkFile{}
@@ -162,9 +185,16 @@ func (f *cloudStorageSinkFile) Write(p []byte) (int, error) {
 // by a given `<sink_id>` and <session_id> is a unique identifying string for the job
 // session running the `changeAggregator` that owns this sink.
 //
-// `<ext>` implies the format of the file: currently the only option is
-// `ndjson`, which means a text file conforming to the "Newline Delimited JSON"
-// spec.
+// `<ext>` implies the format of the file: either `ndjson`, which means a text
+// file conforming to the "Newline Delimited JSON" spec, `parquet` for
+// format=parquet changefeeds, or `avro` for format=avro changefeeds, which are
+// written as Avro Object Container Files. Parquet and Avro files embed their
+// schema and have a column (or record field) for every column of the table,
+// followed by `__crdb__event_type` (`upsert` or `delete`) and, with the
+// `updated` option, `__crdb__updated`. Since all rows in a file have the same
+// schema version, a schema change always starts a new file. Parquet row groups
+// are sized to match `file_size`, so a file generally consists of a single row
+// group.
 //
 // This naming convention of data files is carefully chosen in order to preserve
 // the external ordering guarantees of CDC. Naming output files in this fashion
@@ -186,7 +216,7 @@ func (f *cloudStorageSinkFile) Write(p []byte) (int, error) {
 // deleted, included in hive queries, etc). A typical user of cloudStorageSink
 // would periodically do exactly this.
 //
-// Still TODO is writing out data schemas, Avro support, bounding memory usage.
+// Still TODO is writing out data schemas for ndjson files, bounding memory usage.
 //
 // Now what follows is a proof of why the above is correct even in the presence
 // of multiple job restarts. We begin by establishing some terminology and by
@@ -277,6 +307,16 @@ type cloudStorageSink struct {
 
 	compression string
 
+	// format is the format=... of the changefeed. The files of format=parquet
+	// and format=avro changefeeds embed the schema of their rows, which is
+	// derived from the table being written, and compression is applied within
+	// these files instead of to the files as a whole.
+	format             changefeedbase.FormatType
+	updatedColumn      bool
+	rowAlloc           rowenc.DatumAlloc
+	parquetCompression parquet.CompressionCodec
+	avroCompression    string
+
 	es cloud.ExternalStorage
 
 	// These are fields to track information needed to output files based on the naming
@@ -330,7 +370,8 @@ func makeCloudStorageSink(
 		s.dataFilePartition = timestampOracle.inclusiveLowerBoundTS().GoTime().Format(s.partitionFormat)
 	}
 
-	switch changefeedbase.FormatType(opts[changefeedbase.OptFormat]) {
+	s.format = changefeedbase.FormatType(opts[changefeedbase.OptFormat])
+	switch s.format {
 	case changefeedbase.OptFormatJSON:
 		// TODO(dan): It seems like these should be on the encoder, but that
 		// would require a bit of refactoring.
@@ -339,6 +380,13 @@ func makeCloudStorageSink(
 			_, err := w.Write([]byte{'\n'})
 			return err
 		}
+	case changefeedbase.OptFormatParquet:
+		s.ext = `.parquet`
+		_, s.updatedColumn = opts[changefeedbase.OptUpdatedTimestamps]
+	case changefeedbase.OptFormatAvro:
+		s.ext = `.avro`
+		s.avroCompression = goavro.CompressionNullLabel
+		_, s.updatedColumn = opts[changefeedbase.OptUpdatedTimestamps]
 	default:
 		return nil, errors.Errorf(`this sink is incompatible with %s=%s`,
 			changefeedbase.OptFormat, opts[changefeedbase.OptFormat])
@@ -356,11 +404,27 @@ func makeCloudStorageSink(
 	}
 
 	if codec, ok := opts[changefeedbase.OptCompression]; ok && codec != "" {
-		if strings.EqualFold(codec, "gzip") {
-			s.compression = sinkCompressionGzip
-			s.ext = s.ext + ".gz"
-		} else {
-			return nil, errors.Errorf(`unsupported compression codec %q`, codec)
+		switch s.format {
+		case changefeedbase.OptFormatParquet:
+			var err error
+			if s.parquetCompression, err = parquet.CompressionCodecFromString(codec); err != nil {
+				return nil, err
+			}
+		case changefeedbase.OptFormatAvro:
+			switch strings.ToLower(codec) {
+			case `none`:
+			case goavro.CompressionDeflateLabel, goavro.CompressionSnappyLabel:
+				s.avroCompression = strings.ToLower(codec)
+			default:
+				return nil, errors.Errorf(`unsupported compression codec %q`, codec)
+			}
+		default:
+			if strings.EqualFold(codec, "gzip") {
+				s.compression = sinkCompressionGzip
+				s.ext = s.ext + ".gz"
+			} else {
+				return nil, errors.Errorf(`unsupported compression codec %q`, codec)
+			}
 		}
 	}
 
@@ -401,18 +465,120 @@ func (s *cloudStorageSink) EmitRow(
 	file := s.getOrCreateFile(table.GetName(), table.GetVersion())
 
 	// TODO(dan): Memory monitoring for this
-	if _, err := file.Write(value); err != nil {
-		return err
+	var size int64
+	switch s.format {
+	case changefeedbase.OptFormatParquet:
+		if err := s.addParquetRow(file, table, value); err != nil {
+			return err
+		}
+		size = file.parquet.BufferedSize()
+	case changefeedbase.OptFormatAvro:
+		if err := s.addAvroRow(file, table, value); err != nil {
+			return err
+		}
+		size = int64(file.buf.Len() + file.ocfBlockSize)
+	default:
+		if _, err := file.Write(value); err != nil {
+			return err
+		}
+		if err := s.recordDelimFn(file); err != nil {
+			return err
+		}
+		size = int64(file.buf.Len())
+	}
+
+	if size > s.targetMaxFileSize {
+		if err := s.flushTopicVersions(ctx, file.topic, file.schemaID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addParquetRow decodes a value encoded by containerFileEncoder and buffers it
+// in the parquet writer of file, creating the writer on first use.
+func (s *cloudStorageSink) addParquetRow(
+	file *cloudStorageSinkFile, table catalog.TableDescriptor, value []byte,
+) error {
+	if file.parquet == nil {
+		names, typs := containerFileColumns(table, s.updatedColumn)
+		sch, err := parquet.NewSchema(names, typs)
+		if err != nil {
+			return err
+		}
+		if file.parquet, err = parquet.NewWriter(sch, &file.buf,
+			parquet.WithCompression(s.parquetCompression),
+			parquet.WithMaxRowGroupSize(s.targetMaxFileSize),
+		); err != nil {
+			return err
+		}
+		file.rowTypes = typs
+		file.rowDatums = make(tree.Datums, len(typs))
 	}
-	if err := s.recordDelimFn(file); err != nil {
+	if err := decodeContainerFileRow(&s.rowAlloc, file.rowTypes, value, file.rowDatums); err != nil {
 		return err
 	}
+	file.rawSize += len(value)
+	return file.parquet.AddRow(file.rowDatums)
+}
 
-	if int64(file.buf.Len()) > s.targetMaxFileSize {
-		if err := s.flushTopicVersions(ctx, file.topic, file.schemaID); err != nil {
+// avroBlockSize is the approximate size of the rows buffered before they're
+// appended to an avro object container file as a block.
+const avroBlockSize = 64 << 10
+
+// addAvroRow decodes a value encoded by containerFileEncoder and buffers it in
+// the next block of file's object container file, creating the container
+// file, whose header embeds the avro schema of the table, on first use.
+func (s *cloudStorageSink) addAvroRow(
+	file *cloudStorageSinkFile, table catalog.TableDescriptor, value []byte,
+) error {
+	if file.ocf == nil {
+		schema, err := containerFileToAvroSchema(table, s.updatedColumn)
+		if err != nil {
+			return err
+		}
+		if file.ocf, err = goavro.NewOCFWriter(goavro.OCFConfig{
+			W:               &file.buf,
+			Codec:           schema.codec,
+			CompressionName: s.avroCompression,
+		}); err != nil {
 			return err
 		}
+		file.ocfSchema = schema
+		_, file.rowTypes = containerFileColumns(table, s.updatedColumn)
+		file.rowDatums = make(tree.Datums, len(file.rowTypes))
+		file.ocfRow = make(rowenc.EncDatumRow, len(file.rowTypes))
+	}
+	if err := decodeContainerFileRow(&s.rowAlloc, file.rowTypes, value, file.rowDatums); err != nil {
+		return err
+	}
+	for i := range file.rowDatums {
+		file.ocfRow[i] = rowenc.DatumToEncDatum(file.rowTypes[i], file.rowDatums[i])
+	}
+	native, err := file.ocfSchema.nativeFromRow(file.ocfRow)
+	if err != nil {
+		return err
+	}
+	file.ocfBlock = append(file.ocfBlock, native)
+	file.ocfBlockSize += len(value)
+	file.rawSize += len(value)
+	if file.ocfBlockSize >= avroBlockSize {
+		return file.flushAvroBlock()
+	}
+	return nil
+}
+
+// flushAvroBlock appends the buffered rows of a format=avro file to its object
+// container file.
+func (f *cloudStorageSinkFile) flushAvroBlock() error {
+	if len(f.ocfBlock) == 0 {
+		return nil
+	}
+	if err := f.ocf.Append(f.ocfBlock); err != nil {
+		return err
 	}
+	f.ocfBlock = f.ocfBlock[:0]
+	f.ocfBlockSize = 0
 	return nil
 }
 
@@ -491,6 +657,12 @@ func (s *cloudStorageSink) Flush(ctx context.Context) error {
 	}
 	s.files.Clear(true /* addNodesToFreeList */)
 
+	// The changeFrontier's sink doesn't have an oracle, but it only emits
+	// resolved timestamps, which are written out synchronously.
+	if s.timestampOracle == nil {
+		return nil
+	}
+
 	// Record the least resolved timestamp being tracked in the frontier as of this point,
 	// to use for naming files until the next `Flush()`. See comment on cloudStorageSink
 	// for an overview of the naming convention and proof of correctness.
@@ -507,6 +679,19 @@ func (s *cloudStorageSink) flushFile(ctx context.Context, file *cloudStorageSink
 		return nil
 	}
 
+	// Parquet files are only written to the buffer once they're closed, and
+	// avro object container files have the rows of their last block buffered.
+	if file.parquet != nil {
+		if err := file.parquet.Close(); err != nil {
+			return err
+		}
+	}
+	if file.ocf != nil {
+		if err := file.flushAvroBlock(); err != nil {
+			return err
+		}
+	}
+
 	// If the file is written via compression codec, close the codec to ensure it
 	// has flushed to the underlying buffer.
 	if file.codec != nil {
@@ -29,6 +29,9 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/tabledesc"
+	"github.com/cockroachdb/cockroach/pkg/sql/rowenc"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
 	"github.com/cockroachdb/cockroach/pkg/storage/cloud"
 	"github.com/cockroachdb/cockroach/pkg/storage/cloudimpl"
 	"github.com/cockroachdb/cockroach/pkg/testutils"
@@ -36,6 +39,8 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/cockroach/pkg/util/span"
+	goparquet "github.com/fraugster/parquet-go"
+	"github.com/linkedin/goavro/v2"
 	"github.com/stretchr/testify/require"
 )
 
@@ -536,4 +541,201 @@ func TestCloudStorageSink(t *testing.T) {
 			"w1\n",
 		}, slurpDir(t, dir))
 	})
+
+	t.Run(`parquet`, func(t *testing.T) {
+		t1 := tabledesc.NewImmutable(descpb.TableDescriptor{
+			Name: `t1`,
+			Columns: []descpb.ColumnDescriptor{
+				{ID: 1, Name: `a`, Type: types.Int},
+				{ID: 2, Name: `b`, Type: types.String, Nullable: true},
+			},
+		})
+		parquetOpts := map[string]string{
+			changefeedbase.OptFormat:            string(changefeedbase.OptFormatParquet),
+			changefeedbase.OptEnvelope:          string(changefeedbase.OptEnvelopeWrapped),
+			changefeedbase.OptKeyInValue:        ``,
+			changefeedbase.OptUpdatedTimestamps: ``,
+			changefeedbase.OptCompression:       `snappy`,
+		}
+		pe, err := newContainerFileEncoder(parquetOpts)
+		require.NoError(t, err)
+		emit := func(s Sink, a int, b tree.Datum, deleted bool) {
+			row := encodeRow{
+				datums: rowenc.EncDatumRow{
+					rowenc.DatumToEncDatum(types.Int, tree.NewDInt(tree.DInt(a))),
+					rowenc.DatumToEncDatum(types.String, b),
+				},
+				updated:   ts(int64(a)),
+				deleted:   deleted,
+				tableDesc: t1,
+			}
+			value, err := pe.EncodeValue(ctx, row)
+			require.NoError(t, err)
+			require.NoError(t, s.EmitRow(ctx, t1, noKey, value, row.updated))
+		}
+		readParquet := func(t *testing.T, contents string) []map[string]interface{} {
+			r, err := goparquet.NewFileReader(strings.NewReader(contents))
+			require.NoError(t, err)
+			var rows []map[string]interface{}
+			for i := int64(0); i < r.NumRows(); i++ {
+				row, err := r.NextRow()
+				require.NoError(t, err)
+				rows = append(rows, row)
+			}
+			return rows
+		}
+
+		testSpan := roachpb.Span{Key: []byte("a"), EndKey: []byte("b")}
+		sf := span.MakeFrontier(testSpan)
+		timestampOracle := &changeAggregatorLowerBoundOracle{sf: sf}
+		dir := `parquet`
+		s, err := makeCloudStorageSink(ctx, `nodelocal://0/`+dir, 1, unlimitedFileSize, settings,
+			parquetOpts, timestampOracle, externalStorageFromURI, user)
+		require.NoError(t, err)
+
+		emit(s, 1, tree.NewDString(`x`), false)
+		emit(s, 2, tree.DNull, true)
+		require.NoError(t, s.Flush(ctx))
+		files := slurpDir(t, dir)
+		require.Len(t, files, 1)
+		require.Equal(t, []map[string]interface{}{
+			{
+				`a`:                          int64(1),
+				`b`:                          []byte(`x`),
+				containerFileEventTypeColumn: []byte(containerFileEventTypeUpsert),
+				containerFileUpdatedColumn:   []byte(`1.0000000000`),
+			},
+			{
+				`a`:                          int64(2),
+				containerFileEventTypeColumn: []byte(containerFileEventTypeDelete),
+				containerFileUpdatedColumn:   []byte(`2.0000000000`),
+			},
+		}, readParquet(t, files[0]))
+
+		// A file is flushed as soon as its buffered size exceeds file_size.
+		dir = `parquet-file-size`
+		s, err = makeCloudStorageSink(ctx, `nodelocal://0/`+dir, 1, 1 /* targetMaxFileSize */, settings,
+			parquetOpts, timestampOracle, externalStorageFromURI, user)
+		require.NoError(t, err)
+		emit(s, 3, tree.NewDString(`y`), false)
+		emit(s, 4, tree.NewDString(`z`), false)
+		files = slurpDir(t, dir)
+		require.Len(t, files, 2)
+		for i, f := range files {
+			rows := readParquet(t, f)
+			require.Len(t, rows, 1)
+			require.Equal(t, int64(i+3), rows[0][`a`])
+		}
+	})
+	t.Run(`avro`, func(t *testing.T) {
+		t1 := tabledesc.NewImmutable(descpb.TableDescriptor{
+			Name: `t1`,
+			Columns: []descpb.ColumnDescriptor{
+				{ID: 1, Name: `a`, Type: types.Int},
+				{ID: 2, Name: `b`, Type: types.String, Nullable: true},
+			},
+		})
+		avroOpts := map[string]string{
+			changefeedbase.OptFormat:            string(changefeedbase.OptFormatAvro),
+			changefeedbase.OptEnvelope:          string(changefeedbase.OptEnvelopeWrapped),
+			changefeedbase.OptKeyInValue:        ``,
+			changefeedbase.OptUpdatedTimestamps: ``,
+			changefeedbase.OptCompression:       `deflate`,
+		}
+		e, err := getEncoder(avroOpts, `experimental-nodelocal://0/`)
+		require.NoError(t, err)
+		emit := func(s Sink, a int, b tree.Datum, deleted bool) {
+			row := encodeRow{
+				datums: rowenc.EncDatumRow{
+					rowenc.DatumToEncDatum(types.Int, tree.NewDInt(tree.DInt(a))),
+					rowenc.DatumToEncDatum(types.String, b),
+				},
+				updated:   ts(int64(a)),
+				deleted:   deleted,
+				tableDesc: t1,
+			}
+			for len(row.datums) < len(t1.GetPublicColumns()) {
+				row.datums = append(row.datums, rowenc.DatumToEncDatum(types.Bool, tree.DNull))
+			}
+			value, err := e.EncodeValue(ctx, row)
+			require.NoError(t, err)
+			require.NoError(t, s.EmitRow(ctx, t1, noKey, value, row.updated))
+		}
+		readOCF := func(t *testing.T, contents string) (string, []interface{}) {
+			r, err := goavro.NewOCFReader(strings.NewReader(contents))
+			require.NoError(t, err)
+			require.Equal(t, goavro.CompressionDeflateLabel, r.CompressionName())
+			var rows []interface{}
+			for r.Scan() {
+				row, err := r.Read()
+				require.NoError(t, err)
+				rows = append(rows, row)
+			}
+			require.NoError(t, r.Err())
+			return r.Codec().Schema(), rows
+		}
+		str := func(s string) interface{} { return goavro.Union(`string`, s) }
+
+		testSpan := roachpb.Span{Key: []byte("a"), EndKey: []byte("b")}
+		sf := span.MakeFrontier(testSpan)
+		timestampOracle := &changeAggregatorLowerBoundOracle{sf: sf}
+		dir := `avro`
+		s, err := makeCloudStorageSink(ctx, `nodelocal://0/`+dir, 1, unlimitedFileSize, settings,
+			avroOpts, timestampOracle, externalStorageFromURI, user)
+		require.NoError(t, err)
+
+		emit(s, 1, tree.NewDString(`x`), false)
+		emit(s, 2, tree.DNull, true)
+		// A new schema version of the table starts a new file.
+		t1 = tabledesc.NewImmutable(descpb.TableDescriptor{
+			Name:    `t1`,
+			Version: 1,
+			Columns: []descpb.ColumnDescriptor{
+				{ID: 1, Name: `a`, Type: types.Int},
+				{ID: 2, Name: `b`, Type: types.String, Nullable: true},
+				{ID: 3, Name: `c`, Type: types.Bool, Nullable: true},
+			},
+		})
+		emit(s, 3, tree.NewDString(`y`), false)
+		require.NoError(t, s.Flush(ctx))
+		files := slurpDir(t, dir)
+		require.Len(t, files, 2)
+
+		schema, rows := readOCF(t, files[0])
+		require.NotContains(t, schema, `"c"`)
+		require.Equal(t, []interface{}{
+			map[string]interface{}{
+				`a`:                          goavro.Union(`long`, int64(1)),
+				`b`:                          str(`x`),
+				containerFileEventTypeColumn: str(containerFileEventTypeUpsert),
+				containerFileUpdatedColumn:   str(`1.0000000000`),
+			},
+			map[string]interface{}{
+				`a`:                          goavro.Union(`long`, int64(2)),
+				`b`:                          nil,
+				containerFileEventTypeColumn: str(containerFileEventTypeDelete),
+				containerFileUpdatedColumn:   str(`2.0000000000`),
+			},
+		}, rows)
+		schema, rows = readOCF(t, files[1])
+		require.Contains(t, schema, `"c"`)
+		require.Len(t, rows, 1)
+		require.Equal(t, str(`y`), rows[0].(map[string]interface{})[`b`])
+		require.Nil(t, rows[0].(map[string]interface{})[`c`])
+
+		// A file is flushed as soon as its buffered size exceeds file_size.
+		dir = `avro-file-size`
+		s, err = makeCloudStorageSink(ctx, `nodelocal://0/`+dir, 1, 1 /* targetMaxFileSize */, settings,
+			avroOpts, timestampOracle, externalStorageFromURI, user)
+		require.NoError(t, err)
+		emit(s, 4, tree.NewDString(`z`), false)
+		emit(s, 5, tree.NewDString(`z`), false)
+		files = slurpDir(t, dir)
+		require.Len(t, files, 2)
+		for i, f := range files {
+			_, rows := readOCF(t, f)
+			require.Len(t, rows, 1)
+			require.Equal(t, goavro.Union(`long`, int64(i+4)), rows[0].(map[string]interface{})[`a`])
+		}
+	})
 }
@@ -0,0 +1,418 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package changefeedccl
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/ccl/changefeedccl/changefeedbase"
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/storage/cloudimpl"
+	"github.com/cockroachdb/cockroach/pkg/util/bufalloc"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/errors"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+	pubsubpb "google.golang.org/genproto/googleapis/pubsub/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/oauth"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	pubsubScope           = `https://www.googleapis.com/auth/pubsub`
+	pubsubDefaultEndpoint = `pubsub.googleapis.com:443`
+	// pubsubMaxBatchMessages and pubsubMaxBatchBytes bound the size of a single
+	// Publish request. The service rejects requests with more than 1000
+	// messages or 10MB of data, so the byte limit leaves some headroom for the
+	// per-message overhead.
+	pubsubMaxBatchMessages = 1000
+	pubsubMaxBatchBytes    = 8 << 20
+	// pubsubMaxOrderingKeyBytes is the longest ordering key the service
+	// accepts.
+	pubsubMaxOrderingKeyBytes = 1024
+	pubsubRequestTimeout      = 30 * time.Second
+)
+
+func isPubsubSink(u *url.URL) bool {
+	return u.Scheme == changefeedbase.SinkSchemeGCPubsub
+}
+
+type pubsubSinkConfig struct {
+	projectID   string
+	topicPrefix string
+	region      string
+	// emulatorHost, if set, is the host:port of a Pub/Sub emulator. The
+	// emulator is connected to without TLS or credentials.
+	emulatorHost string
+	auth         string
+	credentials  string
+}
+
+// pubsubMessage is a message waiting to be published to topic.
+type pubsubMessage struct {
+	topic string
+	msg   *pubsubpb.PubsubMessage
+}
+
+func (m pubsubMessage) size() int {
+	return len(m.msg.Data) + len(m.msg.OrderingKey)
+}
+
+// pubsubSink emits to Google Cloud Pub/Sub asynchronously. It is not
+// concurrency-safe; all calls to Emit and Flush should be from the same
+// goroutine.
+//
+// Rows are published with an ordering key derived from their primary key. A
+// single worker publishes the queued messages in order, batching whatever has
+// accumulated while the previous request was in flight, so messages with the
+// same ordering key are never in flight in two requests at once. As with
+// kafkaSink, Flush waits for every message emitted before it to be
+// acknowledged, which is what makes it safe to emit a resolved timestamp
+// afterward.
+type pubsubSink struct {
+	cfg    pubsubSinkConfig
+	conn   *grpc.ClientConn
+	client pubsubpb.PublisherClient
+	// topics maps each declared topic name to its full resource name.
+	topics map[string]string
+
+	msgCh        chan pubsubMessage
+	stopWorkerCh chan struct{}
+	cancelWorker context.CancelFunc
+	worker       sync.WaitGroup
+	scratch      bufalloc.ByteAllocator
+
+	// Only synchronized between the client goroutine and the worker goroutine.
+	mu struct {
+		syncutil.Mutex
+		inflight int64
+		flushErr error
+		flushCh  chan struct{}
+	}
+}
+
+func makePubsubSink(
+	ctx context.Context, cfg pubsubSinkConfig, targets jobspb.ChangefeedTargets,
+) (Sink, error) {
+	if cfg.projectID == `` {
+		return nil, errors.Errorf(`a project id is required, e.g. %s://<project>`,
+			changefeedbase.SinkSchemeGCPubsub)
+	}
+
+	endpoint := pubsubDefaultEndpoint
+	if cfg.region != `` {
+		endpoint = cfg.region + `-` + pubsubDefaultEndpoint
+	}
+	var dialOpts []grpc.DialOption
+	if cfg.emulatorHost != `` {
+		endpoint = cfg.emulatorHost
+		dialOpts = append(dialOpts, grpc.WithInsecure())
+	} else {
+		ts, err := pubsubTokenSource(ctx, cfg)
+		if err != nil {
+			return nil, err
+		}
+		dialOpts = append(dialOpts,
+			grpc.WithTransportCredentials(credentials.NewClientTLSFromCert(nil /* cp */, ``)),
+			grpc.WithPerRPCCredentials(oauth.TokenSource{TokenSource: ts}),
+		)
+	}
+	conn, err := grpc.DialContext(ctx, endpoint, dialOpts...)
+	if err != nil {
+		return nil, pgerror.Wrapf(err, pgcode.CannotConnectNow,
+			`connecting to pubsub: %s`, endpoint)
+	}
+	sink := &pubsubSink{
+		cfg:    cfg,
+		conn:   conn,
+		client: pubsubpb.NewPublisherClient(conn),
+		topics: make(map[string]string),
+	}
+	for _, t := range targets {
+		topic := cfg.topicPrefix + SQLNameToPubsubName(t.StatementTimeName)
+		sink.topics[topic] = fmt.Sprintf(`projects/%s/topics/%s`, cfg.projectID, topic)
+	}
+	if err := sink.ensureTopics(ctx); err != nil {
+		_ = conn.Close()
+		return nil, pgerror.Wrapf(err, pgcode.CannotConnectNow,
+			`connecting to pubsub: %s`, endpoint)
+	}
+
+	sink.start()
+	return sink, nil
+}
+
+// pubsubTokenSource returns the oauth token source described by the AUTH and
+// CREDENTIALS sink parameters, which are interpreted the same way as they are
+// for gs:// storage URIs.
+func pubsubTokenSource(ctx context.Context, cfg pubsubSinkConfig) (oauth2.TokenSource, error) {
+	switch cfg.auth {
+	case ``, cloudimpl.AuthParamImplicit:
+		if cfg.credentials != `` {
+			return nil, errors.Errorf(`%s requires %s=%s`,
+				cloudimpl.CredentialsParam, cloudimpl.AuthParam, cloudimpl.AuthParamSpecified)
+		}
+		return google.DefaultTokenSource(ctx, pubsubScope)
+	case cloudimpl.AuthParamSpecified:
+		if cfg.credentials == `` {
+			return nil, errors.Errorf(`%s is set to '%s', but %s is not set`,
+				cloudimpl.AuthParam, cloudimpl.AuthParamSpecified, cloudimpl.CredentialsParam)
+		}
+		decodedKey, err := base64.StdEncoding.DecodeString(cfg.credentials)
+		if err != nil {
+			return nil, errors.Wrapf(err, `decoding value of %s`, cloudimpl.CredentialsParam)
+		}
+		jwtConfig, err := google.JWTConfigFromJSON(decodedKey, pubsubScope)
+		if err != nil {
+			return nil, errors.Wrap(err, `creating pubsub oauth token source from specified credentials`)
+		}
+		return jwtConfig.TokenSource(ctx), nil
+	default:
+		return nil, errors.Errorf(`unsupported value %s for %s`, cfg.auth, cloudimpl.AuthParam)
+	}
+}
+
+// ensureTopics creates any declared topics that don't exist yet. Unlike kafka,
+// Pub/Sub doesn't auto-create topics on publish.
+func (s *pubsubSink) ensureTopics(ctx context.Context) error {
+	for _, name := range s.topics {
+		if err := func() error {
+			ctx, cancel := context.WithTimeout(ctx, pubsubRequestTimeout)
+			defer cancel()
+			_, err := s.client.GetTopic(ctx, &pubsubpb.GetTopicRequest{Topic: name})
+			if status.Code(err) != codes.NotFound {
+				return err
+			}
+			_, err = s.client.CreateTopic(ctx, &pubsubpb.Topic{Name: name})
+			if status.Code(err) == codes.AlreadyExists {
+				// Created concurrently, likely by another node.
+				return nil
+			}
+			return err
+		}(); err != nil {
+			return errors.Wrapf(err, `creating topic %s`, name)
+		}
+	}
+	return nil
+}
+
+func (s *pubsubSink) start() {
+	var workerCtx context.Context
+	workerCtx, s.cancelWorker = context.WithCancel(context.Background())
+	s.msgCh = make(chan pubsubMessage, pubsubMaxBatchMessages)
+	s.stopWorkerCh = make(chan struct{})
+	s.worker.Add(1)
+	go s.workerLoop(workerCtx)
+}
+
+// Close implements the Sink interface.
+func (s *pubsubSink) Close() error {
+	close(s.stopWorkerCh)
+	s.cancelWorker()
+	s.worker.Wait()
+	return s.conn.Close()
+}
+
+// EmitRow implements the Sink interface.
+func (s *pubsubSink) EmitRow(
+	ctx context.Context, table catalog.TableDescriptor, key, value []byte, updated hlc.Timestamp,
+) error {
+	topic := s.cfg.topicPrefix + SQLNameToPubsubName(table.GetName())
+	if _, ok := s.topics[topic]; !ok {
+		return errors.Errorf(`cannot emit to undeclared topic: %s`, topic)
+	}
+
+	msg := &pubsubpb.PubsubMessage{
+		Data:        value,
+		OrderingKey: pubsubOrderingKey(key),
+	}
+	return s.emitMessage(ctx, pubsubMessage{topic: topic, msg: msg})
+}
+
+// EmitResolvedTimestamp implements the Sink interface.
+func (s *pubsubSink) EmitResolvedTimestamp(
+	ctx context.Context, encoder Encoder, resolved hlc.Timestamp,
+) error {
+	for topic := range s.topics {
+		payload, err := encoder.EncodeResolvedTimestamp(ctx, topic, resolved)
+		if err != nil {
+			return err
+		}
+		s.scratch, payload = s.scratch.Copy(payload, 0 /* extraCap */)
+
+		// Resolved timestamps have no ordering key, so subscribers with message
+		// ordering enabled receive them independently of any row.
+		msg := &pubsubpb.PubsubMessage{Data: payload}
+		if err := s.emitMessage(ctx, pubsubMessage{topic: topic, msg: msg}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Flush implements the Sink interface.
+func (s *pubsubSink) Flush(ctx context.Context) error {
+	flushCh := make(chan struct{}, 1)
+
+	s.mu.Lock()
+	inflight := s.mu.inflight
+	flushErr := s.mu.flushErr
+	s.mu.flushErr = nil
+	immediateFlush := inflight == 0 || flushErr != nil
+	if !immediateFlush {
+		s.mu.flushCh = flushCh
+	}
+	s.mu.Unlock()
+
+	if immediateFlush {
+		return flushErr
+	}
+
+	if log.V(1) {
+		log.Infof(ctx, "flush waiting for %d inflight messages", inflight)
+	}
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-flushCh:
+		s.mu.Lock()
+		flushErr := s.mu.flushErr
+		s.mu.flushErr = nil
+		s.mu.Unlock()
+		return flushErr
+	}
+}
+
+func (s *pubsubSink) emitMessage(ctx context.Context, msg pubsubMessage) error {
+	s.mu.Lock()
+	s.mu.inflight++
+	inflight := s.mu.inflight
+	s.mu.Unlock()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case s.msgCh <- msg:
+	}
+
+	if log.V(2) {
+		log.Infof(ctx, "emitted %d inflight records to pubsub", inflight)
+	}
+	return nil
+}
+
+func (s *pubsubSink) workerLoop(ctx context.Context) {
+	defer s.worker.Done()
+
+	var batch []pubsubMessage
+	// next is a message that was received but didn't fit in the last batch.
+	var next *pubsubMessage
+	for {
+		batch = batch[:0]
+		batchBytes := 0
+		if next != nil {
+			batch = append(batch, *next)
+			batchBytes += next.size()
+			next = nil
+		} else {
+			select {
+			case <-s.stopWorkerCh:
+				return
+			case msg := <-s.msgCh:
+				batch = append(batch, msg)
+				batchBytes += msg.size()
+			}
+		}
+		// Pick up anything else that was queued while the previous request was
+		// in flight.
+	drain:
+		for len(batch) < pubsubMaxBatchMessages {
+			select {
+			case msg := <-s.msgCh:
+				if batchBytes+msg.size() > pubsubMaxBatchBytes {
+					next = &msg
+					break drain
+				}
+				batch = append(batch, msg)
+				batchBytes += msg.size()
+			default:
+				break drain
+			}
+		}
+
+		err := s.publish(ctx, batch)
+
+		s.mu.Lock()
+		if err != nil && s.mu.flushErr == nil {
+			s.mu.flushErr = err
+		}
+		s.mu.inflight -= int64(len(batch))
+		if s.mu.inflight == 0 && s.mu.flushCh != nil {
+			s.mu.flushCh <- struct{}{}
+			s.mu.flushCh = nil
+		}
+		s.mu.Unlock()
+	}
+}
+
+// publish sends one Publish request per topic in the batch and waits for all
+// of them to be acknowledged. Messages keep their relative order within each
+// topic, which is all ordering keys need since a key always maps to the same
+// topic.
+func (s *pubsubSink) publish(ctx context.Context, batch []pubsubMessage) error {
+	var reqs []*pubsubpb.PublishRequest
+	byTopic := make(map[string]*pubsubpb.PublishRequest)
+	for _, m := range batch {
+		req, ok := byTopic[m.topic]
+		if !ok {
+			req = &pubsubpb.PublishRequest{Topic: s.topics[m.topic]}
+			byTopic[m.topic] = req
+			reqs = append(reqs, req)
+		}
+		req.Messages = append(req.Messages, m.msg)
+	}
+	for _, req := range reqs {
+		if err := func() error {
+			ctx, cancel := context.WithTimeout(ctx, pubsubRequestTimeout)
+			defer cancel()
+			_, err := s.client.Publish(ctx, req)
+			return err
+		}(); err != nil {
+			return errors.Wrapf(err, `publishing to %s`, req.Topic)
+		}
+	}
+	return nil
+}
+
+// pubsubOrderingKey returns the ordering key for a row with the given encoded
+// primary key. Keys longer than Pub/Sub allows are replaced by their hash,
+// which still maps every row with the same primary key to the same ordering
+// key.
+func pubsubOrderingKey(key []byte) string {
+	if len(key) <= pubsubMaxOrderingKeyBytes {
+		return string(key)
+	}
+	h := sha256.Sum256(key)
+	return hex.EncodeToString(h[:])
+}
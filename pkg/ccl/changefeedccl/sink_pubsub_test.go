@@ -0,0 +1,195 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package changefeedccl
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/tabledesc"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/stretchr/testify/require"
+	pubsubpb "google.golang.org/genproto/googleapis/pubsub/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// fakePubsubServer implements the parts of the Pub/Sub publisher API that the
+// emulator does and that pubsubSink uses.
+type fakePubsubServer struct {
+	pubsubpb.UnimplementedPublisherServer
+
+	mu struct {
+		syncutil.Mutex
+		topics map[string]struct{}
+		// published is the ordered list of messages published to each topic,
+		// rendered as `<ordering key>:<data>`.
+		published map[string][]string
+		failures  int
+	}
+}
+
+func (s *fakePubsubServer) CreateTopic(
+	_ context.Context, req *pubsubpb.Topic,
+) (*pubsubpb.Topic, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.mu.topics[req.Name]; ok {
+		return nil, status.Error(codes.AlreadyExists, `topic already exists`)
+	}
+	s.mu.topics[req.Name] = struct{}{}
+	return req, nil
+}
+
+func (s *fakePubsubServer) GetTopic(
+	_ context.Context, req *pubsubpb.GetTopicRequest,
+) (*pubsubpb.Topic, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.mu.topics[req.Topic]; !ok {
+		return nil, status.Error(codes.NotFound, `topic not found`)
+	}
+	return &pubsubpb.Topic{Name: req.Topic}, nil
+}
+
+func (s *fakePubsubServer) Publish(
+	_ context.Context, req *pubsubpb.PublishRequest,
+) (*pubsubpb.PublishResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.mu.topics[req.Topic]; !ok {
+		return nil, status.Error(codes.NotFound, `topic not found`)
+	}
+	if s.mu.failures > 0 {
+		s.mu.failures--
+		return nil, status.Error(codes.Unavailable, `boom`)
+	}
+	resp := &pubsubpb.PublishResponse{}
+	for _, m := range req.Messages {
+		s.mu.published[req.Topic] = append(s.mu.published[req.Topic], m.OrderingKey+`:`+string(m.Data))
+		resp.MessageIds = append(resp.MessageIds, strconv.Itoa(len(s.mu.published[req.Topic])))
+	}
+	return resp, nil
+}
+
+func (s *fakePubsubServer) popPublished() map[string][]string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	published := s.mu.published
+	s.mu.published = make(map[string][]string)
+	return published
+}
+
+func TestPubsubSink(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	table := func(name string) *tabledesc.Immutable {
+		return tabledesc.NewImmutable(descpb.TableDescriptor{Name: name})
+	}
+
+	fake := &fakePubsubServer{}
+	fake.mu.topics = map[string]struct{}{
+		// This one already exists, the other one has to be created.
+		`projects/p/topics/pre_bar`: {},
+	}
+	fake.mu.published = make(map[string][]string)
+	ln, err := net.Listen(`tcp`, `127.0.0.1:0`)
+	require.NoError(t, err)
+	srv := grpc.NewServer()
+	pubsubpb.RegisterPublisherServer(srv, fake)
+	go func() { _ = srv.Serve(ln) }()
+	defer srv.Stop()
+
+	ctx := context.Background()
+	cfg := pubsubSinkConfig{
+		projectID:    `p`,
+		topicPrefix:  `pre_`,
+		emulatorHost: ln.Addr().String(),
+	}
+	targets := jobspb.ChangefeedTargets{
+		0: jobspb.ChangefeedTarget{StatementTimeName: `foo!`},
+		1: jobspb.ChangefeedTarget{StatementTimeName: `bar`},
+	}
+	s, err := makePubsubSink(ctx, cfg, targets)
+	require.NoError(t, err)
+	defer func() { require.NoError(t, s.Close()) }()
+	sink := s.(*pubsubSink)
+
+	fake.mu.Lock()
+	require.Contains(t, fake.mu.topics, `projects/p/topics/pre_foo_u0021_`)
+	fake.mu.Unlock()
+
+	// Empty
+	require.NoError(t, sink.Flush(ctx))
+	require.Empty(t, fake.popPublished())
+
+	// Undeclared topic
+	require.EqualError(t, sink.EmitRow(ctx, table(`nope`), nil, nil, zeroTS),
+		`cannot emit to undeclared topic: pre_nope`)
+
+	// Rows are published with their key as the ordering key, in order, and are
+	// all acknowledged by the time Flush returns.
+	for i := 0; i < 2*pubsubMaxBatchMessages; i++ {
+		key := []byte(`[` + strconv.Itoa(i%3) + `]`)
+		require.NoError(t, sink.EmitRow(ctx, table(`foo!`), key, []byte(strconv.Itoa(i)), zeroTS))
+	}
+	require.NoError(t, sink.EmitRow(ctx, table(`bar`), []byte(`[1]`), []byte(`b`), zeroTS))
+	require.NoError(t, sink.Flush(ctx))
+	published := fake.popPublished()
+	require.Len(t, published[`projects/p/topics/pre_foo_u0021_`], 2*pubsubMaxBatchMessages)
+	for i, m := range published[`projects/p/topics/pre_foo_u0021_`] {
+		require.Equal(t, `[`+strconv.Itoa(i%3)+`]:`+strconv.Itoa(i), m)
+	}
+	require.Equal(t, []string{`[1]:b`}, published[`projects/p/topics/pre_bar`])
+
+	// Resolved timestamps go to every topic, without an ordering key.
+	var e testEncoder
+	require.NoError(t, sink.EmitResolvedTimestamp(ctx, e, hlc.Timestamp{WallTime: 1}))
+	require.NoError(t, sink.Flush(ctx))
+	require.Equal(t, map[string][]string{
+		`projects/p/topics/pre_foo_u0021_`: {`:0.000000001,0`},
+		`projects/p/topics/pre_bar`:        {`:0.000000001,0`},
+	}, fake.popPublished())
+
+	// Errors are returned from the next Flush, which then starts over.
+	fake.mu.Lock()
+	fake.mu.failures = 1
+	fake.mu.Unlock()
+	require.NoError(t, sink.EmitRow(ctx, table(`bar`), []byte(`[2]`), []byte(`c`), zeroTS))
+	require.Regexp(t, `publishing to projects/p/topics/pre_bar: .*boom`, sink.Flush(ctx))
+	require.NoError(t, sink.Flush(ctx))
+	require.NoError(t, sink.EmitRow(ctx, table(`bar`), []byte(`[2]`), []byte(`c`), zeroTS))
+	require.NoError(t, sink.Flush(ctx))
+	require.Equal(t, map[string][]string{
+		`projects/p/topics/pre_bar`: {`[2]:c`},
+	}, fake.popPublished())
+}
+
+func TestPubsubOrderingKey(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	require.Equal(t, `[1, "a"]`, pubsubOrderingKey([]byte(`[1, "a"]`)))
+
+	long := []byte(`["` + strings.Repeat(`a`, pubsubMaxOrderingKeyBytes) + `"]`)
+	k := pubsubOrderingKey(long)
+	require.Len(t, k, 64)
+	require.Equal(t, k, pubsubOrderingKey(long))
+	require.NotEqual(t, k, pubsubOrderingKey(append(long, ' ')))
+}
@@ -0,0 +1,231 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package changefeedccl
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	gojson "encoding/json"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/ccl/changefeedccl/changefeedbase"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/httputil"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/retry"
+	"github.com/cockroachdb/errors"
+)
+
+const (
+	webhookSinkContentType = `application/json`
+	// webhookSinkMaxBatchSize is the number of row messages that are buffered
+	// before they are sent in a single POST request.
+	webhookSinkMaxBatchSize = 128
+	// webhookSinkMaxAttempts is the number of times a POST request is attempted
+	// before the sink gives up and returns the error. Like every other sink
+	// error in the steady state, it is then marked as retryable by
+	// errorWrapperSink.
+	webhookSinkMaxAttempts = 5
+	webhookSinkTimeout     = 3 * time.Second
+)
+
+func isWebhookSink(u *url.URL) bool {
+	return u.Scheme == changefeedbase.SinkSchemeWebhookHTTPS
+}
+
+type webhookSinkConfig struct {
+	topicPrefix   string
+	tlsSkipVerify bool
+	caCert        []byte
+	clientCert    []byte
+	clientKey     []byte
+}
+
+// webhookSinkPayload is the body of every row POST request made by the
+// webhook sink. All messages in a payload are from the same topic.
+type webhookSinkPayload struct {
+	Payload []gojson.RawMessage `json:"payload"`
+	Length  int                 `json:"length"`
+	Topic   string              `json:"topic"`
+}
+
+// webhookSink emits to an HTTPS endpoint. Row messages are buffered and sent
+// as a JSON array in a single POST request once webhookSinkMaxBatchSize
+// messages have been buffered, the topic changes, or Flush is called. Resolved
+// timestamps are sent as individual POST requests, after every buffered row
+// has been acknowledged, so a resolved timestamp is never delivered before a
+// row it covers.
+//
+// It is not concurrency-safe; all calls to Emit and Flush should be from the
+// same goroutine.
+type webhookSink struct {
+	url         string
+	topicPrefix string
+	client      *httputil.Client
+	retryOpts   retry.Options
+
+	batchTopic string
+	batch      []gojson.RawMessage
+}
+
+func makeWebhookSink(cfg webhookSinkConfig, u *url.URL, opts map[string]string) (Sink, error) {
+	switch changefeedbase.FormatType(opts[changefeedbase.OptFormat]) {
+	case changefeedbase.OptFormatJSON:
+	default:
+		return nil, errors.Errorf(`this sink is incompatible with %s=%s`,
+			changefeedbase.OptFormat, opts[changefeedbase.OptFormat])
+	}
+	switch changefeedbase.EnvelopeType(opts[changefeedbase.OptEnvelope]) {
+	case changefeedbase.OptEnvelopeWrapped:
+	default:
+		return nil, errors.Errorf(`this sink is incompatible with %s=%s`,
+			changefeedbase.OptEnvelope, opts[changefeedbase.OptEnvelope])
+	}
+
+	// The webhook- prefix only exists to distinguish the sink from the
+	// experimental-https cloud storage sink.
+	sinkURL := *u
+	sinkURL.Scheme = strings.TrimPrefix(u.Scheme, `webhook-`)
+
+	tlsConfig := &tls.Config{InsecureSkipVerify: cfg.tlsSkipVerify}
+	if cfg.caCert != nil {
+		caCertPool, err := x509.SystemCertPool()
+		if err != nil {
+			return nil, errors.Wrap(err, `could not load system root CA pool`)
+		}
+		if caCertPool == nil {
+			caCertPool = x509.NewCertPool()
+		}
+		if !caCertPool.AppendCertsFromPEM(cfg.caCert) {
+			return nil, errors.Errorf(`failed to parse %s`, changefeedbase.SinkParamCACert)
+		}
+		tlsConfig.RootCAs = caCertPool
+	}
+	if cfg.clientCert != nil {
+		if cfg.clientKey == nil {
+			return nil, errors.Errorf(`%s requires %s to be set`, changefeedbase.SinkParamClientCert, changefeedbase.SinkParamClientKey)
+		}
+		cert, err := tls.X509KeyPair(cfg.clientCert, cfg.clientKey)
+		if err != nil {
+			return nil, errors.Errorf(`invalid client certificate data provided: %s`, err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	} else if cfg.clientKey != nil {
+		return nil, errors.Errorf(`%s requires %s to be set`, changefeedbase.SinkParamClientKey, changefeedbase.SinkParamClientCert)
+	}
+
+	sink := &webhookSink{
+		url:         sinkURL.String(),
+		topicPrefix: cfg.topicPrefix,
+		client: &httputil.Client{Client: &http.Client{
+			Timeout: webhookSinkTimeout,
+			Transport: &http.Transport{
+				DialContext:     (&net.Dialer{Timeout: webhookSinkTimeout}).DialContext,
+				TLSClientConfig: tlsConfig,
+			},
+		}},
+		retryOpts: retry.Options{
+			InitialBackoff: 500 * time.Millisecond,
+			MaxBackoff:     10 * time.Second,
+			Multiplier:     2,
+		},
+	}
+	return sink, nil
+}
+
+// EmitRow implements the Sink interface.
+func (s *webhookSink) EmitRow(
+	ctx context.Context, table catalog.TableDescriptor, _, value []byte, _ hlc.Timestamp,
+) error {
+	topic := s.topicPrefix + table.GetName()
+	if len(s.batch) > 0 && topic != s.batchTopic {
+		if err := s.flushBatch(ctx); err != nil {
+			return err
+		}
+	}
+	s.batchTopic = topic
+	s.batch = append(s.batch, append(gojson.RawMessage(nil), value...))
+	if len(s.batch) >= webhookSinkMaxBatchSize {
+		return s.flushBatch(ctx)
+	}
+	return nil
+}
+
+// EmitResolvedTimestamp implements the Sink interface.
+func (s *webhookSink) EmitResolvedTimestamp(
+	ctx context.Context, encoder Encoder, resolved hlc.Timestamp,
+) error {
+	if err := s.flushBatch(ctx); err != nil {
+		return err
+	}
+	var noTopic string
+	payload, err := encoder.EncodeResolvedTimestamp(ctx, noTopic, resolved)
+	if err != nil {
+		return err
+	}
+	return s.post(ctx, payload)
+}
+
+// Flush implements the Sink interface.
+func (s *webhookSink) Flush(ctx context.Context) error {
+	return s.flushBatch(ctx)
+}
+
+func (s *webhookSink) flushBatch(ctx context.Context) error {
+	if len(s.batch) == 0 {
+		return nil
+	}
+	body, err := gojson.Marshal(webhookSinkPayload{
+		Payload: s.batch,
+		Length:  len(s.batch),
+		Topic:   s.batchTopic,
+	})
+	if err != nil {
+		return err
+	}
+	if err := s.post(ctx, body); err != nil {
+		return err
+	}
+	s.batch = s.batch[:0]
+	return nil
+}
+
+// post sends body to the webhook endpoint, retrying with backoff on network
+// errors and non-2xx responses.
+func (s *webhookSink) post(ctx context.Context, body []byte) error {
+	return retry.WithMaxAttempts(ctx, s.retryOpts, webhookSinkMaxAttempts, func() error {
+		resp, err := s.client.Post(ctx, s.url, webhookSinkContentType, bytes.NewReader(body))
+		if err != nil {
+			log.Warningf(ctx, "posting to webhook sink: %v", err)
+			return errors.Wrap(err, `posting to webhook sink`)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+			respBody, _ := ioutil.ReadAll(resp.Body)
+			err := errors.Errorf(`webhook sink returned %s: %s`, resp.Status, respBody)
+			log.Warningf(ctx, "%v", err)
+			return err
+		}
+		return nil
+	})
+}
+
+// Close implements the Sink interface.
+func (s *webhookSink) Close() error {
+	s.client.CloseIdleConnections()
+	return nil
+}
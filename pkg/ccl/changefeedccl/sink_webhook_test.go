@@ -0,0 +1,183 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package changefeedccl
+
+import (
+	"context"
+	gojson "encoding/json"
+	"encoding/pem"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/ccl/changefeedccl/changefeedbase"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/tabledesc"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhookSink(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	table := func(name string) *tabledesc.Immutable {
+		return tabledesc.NewImmutable(descpb.TableDescriptor{Name: name})
+	}
+
+	var mu struct {
+		syncutil.Mutex
+		bodies   []string
+		failures int
+	}
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+		mu.Lock()
+		defer mu.Unlock()
+		if mu.failures > 0 {
+			mu.failures--
+			http.Error(w, `boom`, http.StatusServiceUnavailable)
+			return
+		}
+		mu.bodies = append(mu.bodies, string(body))
+	}))
+	defer srv.Close()
+	popBodies := func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		bodies := mu.bodies
+		mu.bodies = nil
+		return bodies
+	}
+
+	sinkURL, err := url.Parse(srv.URL)
+	require.NoError(t, err)
+	sinkURL.Scheme = changefeedbase.SinkSchemeWebhookHTTPS
+	cfg := webhookSinkConfig{
+		caCert: pem.EncodeToMemory(&pem.Block{Type: `CERTIFICATE`, Bytes: srv.Certificate().Raw}),
+	}
+	opts := map[string]string{
+		changefeedbase.OptFormat:   string(changefeedbase.OptFormatJSON),
+		changefeedbase.OptEnvelope: string(changefeedbase.OptEnvelopeWrapped),
+	}
+
+	ctx := context.Background()
+	s, err := makeWebhookSink(cfg, sinkURL, opts)
+	require.NoError(t, err)
+	defer func() { require.NoError(t, s.Close()) }()
+	sink := s.(*webhookSink)
+	sink.retryOpts.InitialBackoff = time.Millisecond
+	sink.retryOpts.MaxBackoff = time.Millisecond
+
+	// Empty
+	require.NoError(t, sink.Flush(ctx))
+	require.Empty(t, popBodies())
+
+	// With one row, nothing is sent until Flush is called.
+	require.NoError(t, sink.EmitRow(ctx, table(`foo`), nil, []byte(`{"after":{"a":1}}`), zeroTS))
+	require.Empty(t, popBodies())
+	require.NoError(t, sink.Flush(ctx))
+	require.Equal(t, []string{
+		`{"payload":[{"after":{"a":1}}],"length":1,"topic":"foo"}`,
+	}, popBodies())
+
+	// A full batch is sent without a Flush.
+	for i := 0; i < webhookSinkMaxBatchSize; i++ {
+		require.NoError(t, sink.EmitRow(ctx, table(`foo`), nil, []byte(strconv.Itoa(i)), zeroTS))
+	}
+	bodies := popBodies()
+	require.Len(t, bodies, 1)
+	var payload webhookSinkPayload
+	require.NoError(t, gojson.Unmarshal([]byte(bodies[0]), &payload))
+	require.Equal(t, webhookSinkMaxBatchSize, payload.Length)
+
+	// A change of topic sends the batch so far.
+	require.NoError(t, sink.EmitRow(ctx, table(`foo`), nil, []byte(`1`), zeroTS))
+	require.NoError(t, sink.EmitRow(ctx, table(`bar`), nil, []byte(`2`), zeroTS))
+	require.NoError(t, sink.Flush(ctx))
+	require.Equal(t, []string{
+		`{"payload":[1],"length":1,"topic":"foo"}`,
+		`{"payload":[2],"length":1,"topic":"bar"}`,
+	}, popBodies())
+
+	// Resolved timestamps are sent after any buffered rows.
+	var e testEncoder
+	require.NoError(t, sink.EmitRow(ctx, table(`foo`), nil, []byte(`3`), zeroTS))
+	require.NoError(t, sink.EmitResolvedTimestamp(ctx, e, hlc.Timestamp{WallTime: 1}))
+	require.Equal(t, []string{
+		`{"payload":[3],"length":1,"topic":"foo"}`,
+		`0.000000001,0`,
+	}, popBodies())
+
+	// The topic prefix is prepended to the table name.
+	sink.topicPrefix = `prefix_`
+	require.NoError(t, sink.EmitRow(ctx, table(`foo`), nil, []byte(`6`), zeroTS))
+	require.NoError(t, sink.Flush(ctx))
+	require.Equal(t, []string{`{"payload":[6],"length":1,"topic":"prefix_foo"}`}, popBodies())
+	sink.topicPrefix = ``
+
+	// Transient errors are retried.
+	mu.Lock()
+	mu.failures = webhookSinkMaxAttempts - 1
+	mu.Unlock()
+	require.NoError(t, sink.EmitRow(ctx, table(`foo`), nil, []byte(`4`), zeroTS))
+	require.NoError(t, sink.Flush(ctx))
+	require.Equal(t, []string{`{"payload":[4],"length":1,"topic":"foo"}`}, popBodies())
+
+	// Persistent errors are returned.
+	mu.Lock()
+	mu.failures = webhookSinkMaxAttempts
+	mu.Unlock()
+	require.NoError(t, sink.EmitRow(ctx, table(`foo`), nil, []byte(`5`), zeroTS))
+	require.Regexp(t, `503 Service Unavailable: boom`, sink.Flush(ctx))
+	require.Empty(t, popBodies())
+}
+
+func TestWebhookSinkConfig(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	sinkURL, err := url.Parse(`webhook-https://localhost:1234/changes`)
+	require.NoError(t, err)
+
+	_, err = makeWebhookSink(webhookSinkConfig{}, sinkURL, map[string]string{
+		changefeedbase.OptFormat:   string(changefeedbase.OptFormatAvro),
+		changefeedbase.OptEnvelope: string(changefeedbase.OptEnvelopeWrapped),
+	})
+	require.EqualError(t, err, `this sink is incompatible with format=experimental_avro`)
+
+	_, err = makeWebhookSink(webhookSinkConfig{}, sinkURL, map[string]string{
+		changefeedbase.OptFormat:   string(changefeedbase.OptFormatJSON),
+		changefeedbase.OptEnvelope: string(changefeedbase.OptEnvelopeKeyOnly),
+	})
+	require.EqualError(t, err, `this sink is incompatible with envelope=key_only`)
+
+	_, err = makeWebhookSink(webhookSinkConfig{caCert: []byte(`garbage`)}, sinkURL, map[string]string{
+		changefeedbase.OptFormat:   string(changefeedbase.OptFormatJSON),
+		changefeedbase.OptEnvelope: string(changefeedbase.OptEnvelopeWrapped),
+	})
+	require.EqualError(t, err, `failed to parse ca_cert`)
+
+	s, err := makeWebhookSink(webhookSinkConfig{topicPrefix: `prefix_`}, sinkURL, map[string]string{
+		changefeedbase.OptFormat:   string(changefeedbase.OptFormatJSON),
+		changefeedbase.OptEnvelope: string(changefeedbase.OptEnvelopeWrapped),
+	})
+	require.NoError(t, err)
+	require.Equal(t, `https://localhost:1234/changes`, s.(*webhookSink).url)
+	require.Equal(t, `prefix_`, s.(*webhookSink).topicPrefix)
+	require.NoError(t, s.Close())
+}
@@ -17,8 +17,12 @@ type TestingKnobs struct {
 	// AfterSinkFlush is called after a sink flush operation has returned without
 	// error.
 	AfterSinkFlush func() error
-	// MemBufferCapacity, if non-zero, overrides memBufferDefaultCapacity.
+	// MemBufferCapacity, if non-zero, overrides the memory limit of each change
+	// aggregator.
 	MemBufferCapacity int64
+	// OnAggregatorMemLimit is called with the memory limit of each change
+	// aggregator when it starts.
+	OnAggregatorMemLimit func(limit int64)
 }
 
 // ModuleTestingKnobs is part of the base.ModuleTestingKnobs interface.
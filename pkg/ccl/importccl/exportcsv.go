@@ -13,8 +13,11 @@ import (
 	"compress/gzip"
 	"context"
 	"fmt"
+	"io"
+	"path"
 	"strings"
 
+	"github.com/DataDog/zstd"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/colinfo"
 	"github.com/cockroachdb/cockroach/pkg/sql/execinfra"
 	"github.com/cockroachdb/cockroach/pkg/sql/execinfrapb"
@@ -22,6 +25,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/rowexec"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/storage/cloud"
 	"github.com/cockroachdb/cockroach/pkg/storage/cloudimpl"
 	"github.com/cockroachdb/cockroach/pkg/util/encoding/csv"
 	"github.com/cockroachdb/cockroach/pkg/util/tracing"
@@ -31,25 +35,53 @@ import (
 const exportFilePatternPart = "%part%"
 const exportFilePatternDefault = exportFilePatternPart + ".csv"
 
+// exportDefaultPartition names the directory of the rows whose value of a
+// partition column is NULL or empty, as Hive does.
+const exportDefaultPartition = "__HIVE_DEFAULT_PARTITION__"
+
 // csvExporter data structure to augment the compression
 // and csv writer, encapsulating the internals to make
 // exporting oblivious for the consumers
 type csvExporter struct {
-	compressor *gzip.Writer
+	codec      execinfrapb.FileCompression
+	comma      rune
+	compressor io.WriteCloser
 	buf        *bytes.Buffer
 	csvWriter  *csv.Writer
 }
 
 // Write append record to csv file
 func (c *csvExporter) Write(record []string) error {
+	if c.csvWriter == nil {
+		c.init()
+	}
 	return c.csvWriter.Write(record)
 }
 
+// init creates the writers of a new file. The compressor holds resources until
+// it is closed, so it's only created once there is a row to write.
+func (c *csvExporter) init() {
+	switch c.codec {
+	case execinfrapb.FileCompression_Gzip:
+		c.compressor = gzip.NewWriter(c.buf)
+	case execinfrapb.FileCompression_Zstd:
+		c.compressor = zstd.NewWriter(c.buf)
+	}
+	if c.compressor != nil {
+		c.csvWriter = c.newCSVWriter(c.compressor)
+	} else {
+		c.csvWriter = c.newCSVWriter(c.buf)
+	}
+}
+
 // Close closes the compressor writer which
-// appends archive footers
+// appends archive footers. It can be called more than once, and the next file
+// gets a new compressor.
 func (c *csvExporter) Close() error {
-	if c.compressor != nil {
-		return c.compressor.Close()
+	compressor := c.compressor
+	c.compressor, c.csvWriter = nil, nil
+	if compressor != nil {
+		return compressor.Close()
 	}
 	return nil
 }
@@ -57,20 +89,20 @@ func (c *csvExporter) Close() error {
 // Flush flushes both csv and compressor writer if
 // initialized
 func (c *csvExporter) Flush() error {
+	if c.csvWriter == nil {
+		return nil
+	}
 	c.csvWriter.Flush()
-	if c.compressor != nil {
-		return c.compressor.Flush()
+	if gz, ok := c.compressor.(*gzip.Writer); ok {
+		return gz.Flush()
 	}
 	return nil
 }
 
-// ResetBuffer resets the buffer and compressor state.
+// ResetBuffer resets the buffer for the next file, once the current one is
+// closed.
 func (c *csvExporter) ResetBuffer() {
 	c.buf.Reset()
-	if c.compressor != nil {
-		// Brings compressor to its initial state
-		c.compressor.Reset(c.buf)
-	}
 }
 
 // Bytes results in the slice of bytes with compressed content
@@ -78,7 +110,8 @@ func (c *csvExporter) Bytes() []byte {
 	return c.buf.Bytes()
 }
 
-// Len returns length of the buffer with content
+// Len returns length of the buffer with content. The content buffered by the
+// csv writer and the compressor is not counted until they flush it.
 func (c *csvExporter) Len() int {
 	return c.buf.Len()
 }
@@ -90,38 +123,77 @@ func (c *csvExporter) FileName(spec execinfrapb.CSVWriterSpec, part string) stri
 	}
 
 	fileName := strings.Replace(pattern, exportFilePatternPart, part, -1)
-	// TODO: add suffix based on compressor type
-	if c.compressor != nil {
+	switch c.codec {
+	case execinfrapb.FileCompression_Gzip:
 		fileName += ".gz"
+	case execinfrapb.FileCompression_Zstd:
+		fileName += ".zst"
 	}
 	return fileName
 }
 
+func (c *csvExporter) newCSVWriter(w io.Writer) *csv.Writer {
+	csvWriter := csv.NewWriter(w)
+	if c.comma != 0 {
+		csvWriter.Comma = c.comma
+	}
+	return csvWriter
+}
+
 func newCSVExporter(sp execinfrapb.CSVWriterSpec) *csvExporter {
-	buf := bytes.NewBuffer([]byte{})
-	var exporter *csvExporter
-	switch sp.CompressionCodec {
-	case execinfrapb.FileCompression_Gzip:
-		{
-			writer := gzip.NewWriter(buf)
-			exporter = &csvExporter{
-				compressor: writer,
-				buf:        buf,
-				csvWriter:  csv.NewWriter(writer),
-			}
+	return &csvExporter{
+		codec: sp.CompressionCodec,
+		comma: sp.Options.Comma,
+		buf:   bytes.NewBuffer([]byte{}),
+	}
+}
+
+// csvPartition holds the file being written for the rows of a partition of
+// the export, or of the whole export if it isn't partitioned.
+type csvPartition struct {
+	// dir is the directory of the partition relative to the destination, or
+	// empty if the export isn't partitioned.
+	dir    string
+	writer *csvExporter
+	// rows is the number of rows written to the current file.
+	rows int64
+	// chunk is the number of files written for the partition so far.
+	chunk int
+}
+
+// partitionDir returns the directory, relative to the destination, of the
+// rows with the given values of the partition columns. It is laid out like
+// Hive does: a nested directory named <col>=<value> for each column.
+func partitionDir(colNames []string, partitionCols []uint32, values []string) string {
+	var b strings.Builder
+	for i, col := range partitionCols {
+		if i > 0 {
+			b.WriteByte('/')
 		}
-	default:
-		{
-			exporter = &csvExporter{
-				buf:       buf,
-				csvWriter: csv.NewWriter(buf),
-			}
+		b.WriteString(escapePartitionPathName(colNames[col]))
+		b.WriteByte('=')
+		if values[i] == "" {
+			b.WriteString(exportDefaultPartition)
+		} else {
+			b.WriteString(escapePartitionPathName(values[i]))
 		}
 	}
-	if sp.Options.Comma != 0 {
-		exporter.csvWriter.Comma = sp.Options.Comma
+	return b.String()
+}
+
+// escapePartitionPathName escapes the characters of a column name or value
+// which can't appear in a directory name of a partition, the same way as Hive.
+func escapePartitionPathName(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c < 0x20 || c == 0x7f || strings.IndexByte(`"#%'*/:=?\{[]^`, c) >= 0 {
+			fmt.Fprintf(&b, "%%%02X", c)
+		} else {
+			b.WriteByte(c)
+		}
 	}
-	return exporter
+	return b.String()
 }
 
 func newCSVWriterProcessor(
@@ -176,8 +248,6 @@ func (sp *csvWriter) Run(ctx context.Context) {
 
 		alloc := &rowenc.DatumAlloc{}
 
-		writer := newCSVExporter(sp.spec)
-
 		var nullsAs string
 		if sp.spec.Options.NullEncoding != nil {
 			nullsAs = *sp.spec.Options.NullEncoding
@@ -185,82 +255,73 @@ func (sp *csvWriter) Run(ctx context.Context) {
 		f := tree.NewFmtCtx(tree.FmtExport)
 		defer f.Close()
 
-		csvRow := make([]string, len(typs))
+		// The partition columns are not written to the files, as their values
+		// are in the names of the directories.
+		partitionIdx := make([]int, len(typs))
+		for i := range partitionIdx {
+			partitionIdx[i] = -1
+		}
+		for i, col := range sp.spec.PartitionCols {
+			partitionIdx[col] = i
+		}
+		csvRow := make([]string, 0, len(typs)-len(sp.spec.PartitionCols))
+		partitionVals := make([]string, len(sp.spec.PartitionCols))
+
+		// The rows are sorted on the partition columns, so the partitions are
+		// written one at a time. Only the number of files written is kept for
+		// the partitions which are done: NULL and empty values share a
+		// directory, so a partition may come back after others.
+		var p *csvPartition
+		chunks := make(map[string]int)
+		defer func() {
+			if p != nil {
+				_ = p.writer.Close()
+			}
+		}()
 
-		chunk := 0
-		done := false
-		for {
-			var rows int64
-			writer.ResetBuffer()
-			for {
-				if sp.spec.ChunkRows > 0 && rows >= sp.spec.ChunkRows {
-					break
-				}
-				row, err := input.NextRow()
+		// The export store is only opened once there is a file to write to it.
+		var es cloud.ExternalStorage
+		defer func() {
+			if es != nil {
+				es.Close()
+			}
+		}()
+
+		// writeFile writes the file of the partition, and emits a row for it.
+		writeFile := func(p *csvPartition) error {
+			if err := p.writer.Flush(); err != nil {
+				return errors.Wrap(err, "failed to flush csv writer")
+			}
+
+			if es == nil {
+				conf, err := cloudimpl.ExternalStorageConfFromURI(sp.spec.Destination, sp.spec.User())
 				if err != nil {
 					return err
 				}
-				if row == nil {
-					done = true
-					break
-				}
-				rows++
-
-				for i, ed := range row {
-					if ed.IsNull() {
-						if sp.spec.Options.NullEncoding != nil {
-							csvRow[i] = nullsAs
-							continue
-						} else {
-							return errors.New("NULL value encountered during EXPORT, " +
-								"use `WITH nullas` to specify the string representation of NULL")
-						}
-					}
-					if err := ed.EnsureDecoded(typs[i], alloc); err != nil {
-						return err
-					}
-					ed.Datum.Format(f)
-					csvRow[i] = f.String()
-					f.Reset()
-				}
-				if err := writer.Write(csvRow); err != nil {
+				if es, err = sp.flowCtx.Cfg.ExternalStorage(ctx, conf); err != nil {
 					return err
 				}
 			}
-			if rows < 1 {
-				break
-			}
-			if err := writer.Flush(); err != nil {
-				return errors.Wrap(err, "failed to flush csv writer")
-			}
-
-			conf, err := cloudimpl.ExternalStorageConfFromURI(sp.spec.Destination, sp.spec.User())
-			if err != nil {
-				return err
-			}
-			es, err := sp.flowCtx.Cfg.ExternalStorage(ctx, conf)
-			if err != nil {
-				return err
-			}
-			defer es.Close()
 
 			nodeID, err := sp.flowCtx.EvalCtx.NodeID.OptionalNodeIDErr(47970)
 			if err != nil {
 				return err
 			}
 
-			part := fmt.Sprintf("n%d.%d", nodeID, chunk)
-			chunk++
-			filename := writer.FileName(sp.spec, part)
+			part := fmt.Sprintf("n%d.%d", nodeID, p.chunk)
+			p.chunk++
+			filename := p.writer.FileName(sp.spec, part)
+			if p.dir != "" {
+				filename = path.Join(p.dir, filename)
+			}
 			// Close writer to ensure buffer and any compression footer is flushed.
-			err = writer.Close()
-			if err != nil {
+			if err := p.writer.Close(); err != nil {
 				return errors.Wrapf(err, "failed to close exporting writer")
 			}
 
-			size := writer.Len()
+			size := p.writer.Len()
 
-			if err := es.WriteFile(ctx, filename, bytes.NewReader(writer.Bytes())); err != nil {
+			if err := es.WriteFile(ctx, filename, bytes.NewReader(p.writer.Bytes())); err != nil {
 				return err
 			}
 			res := rowenc.EncDatumRow{
@@ -270,7 +331,7 @@ func (sp *csvWriter) Run(ctx context.Context) {
 				),
 				rowenc.DatumToEncDatum(
 					types.Int,
-					tree.NewDInt(tree.DInt(rows)),
+					tree.NewDInt(tree.DInt(p.rows)),
 				),
 				rowenc.DatumToEncDatum(
 					types.Int,
@@ -287,11 +348,76 @@ func (sp *csvWriter) Run(ctx context.Context) {
 				// another error... so do we really need another one?
 				return errors.New("unexpected closure of consumer")
 			}
-			if done {
+			p.rows = 0
+			p.writer.ResetBuffer()
+			return nil
+		}
+
+		for {
+			row, err := input.NextRow()
+			if err != nil {
+				return err
+			}
+			if row == nil {
 				break
 			}
+
+			csvRow = csvRow[:0]
+			for i, ed := range row {
+				if err := ed.EnsureDecoded(typs[i], alloc); err != nil {
+					return err
+				}
+				if idx := partitionIdx[i]; idx >= 0 {
+					partitionVals[idx] = ""
+					if !ed.IsNull() {
+						ed.Datum.Format(f)
+						partitionVals[idx] = f.String()
+						f.Reset()
+					}
+					continue
+				}
+				if ed.IsNull() {
+					if sp.spec.Options.NullEncoding != nil {
+						csvRow = append(csvRow, nullsAs)
+						continue
+					} else {
+						return errors.New("NULL value encountered during EXPORT, " +
+							"use `WITH nullas` to specify the string representation of NULL")
+					}
+				}
+				ed.Datum.Format(f)
+				csvRow = append(csvRow, f.String())
+				f.Reset()
+			}
+
+			dir := partitionDir(sp.spec.ColNames, sp.spec.PartitionCols, partitionVals)
+			if p == nil {
+				p = &csvPartition{dir: dir, writer: newCSVExporter(sp.spec)}
+			} else if p.dir != dir {
+				if p.rows > 0 {
+					if err := writeFile(p); err != nil {
+						return err
+					}
+				}
+				chunks[p.dir] = p.chunk
+				p.dir, p.chunk = dir, chunks[dir]
+			}
+			if err := p.writer.Write(csvRow); err != nil {
+				return err
+			}
+			p.rows++
+
+			if (sp.spec.ChunkRows > 0 && p.rows >= sp.spec.ChunkRows) ||
+				(sp.spec.MaxFileSize > 0 && int64(p.writer.Len()) >= sp.spec.MaxFileSize) {
+				if err := writeFile(p); err != nil {
+					return err
+				}
+			}
 		}
 
+		if p != nil && p.rows > 0 {
+			return writeFile(p)
+		}
 		return nil
 	}()
 
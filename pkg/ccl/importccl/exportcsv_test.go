@@ -16,9 +16,11 @@ import (
 	"io"
 	"io/ioutil"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"testing"
 
+	"github.com/DataDog/zstd"
 	"github.com/cockroachdb/cockroach/pkg/base"
 	"github.com/cockroachdb/cockroach/pkg/config"
 	"github.com/cockroachdb/cockroach/pkg/config/zonepb"
@@ -372,6 +374,93 @@ func TestExportOrderCompressed(t *testing.T) {
 	}
 }
 
+func TestExportPartitioned(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+	dir, cleanupDir := testutils.TempDir(t)
+	defer cleanupDir()
+
+	srv, db, _ := serverutils.StartServer(t, base.TestServerArgs{ExternalIODir: dir})
+	defer srv.Stopper().Stop(context.Background())
+	sqlDB := sqlutils.MakeSQLRunner(db)
+
+	sqlDB.Exec(t, `CREATE TABLE foo (i INT PRIMARY KEY, region STRING, y INT)`)
+	sqlDB.Exec(t, `INSERT INTO foo VALUES
+		(1, 'east', 2020), (2, 'west', 2020), (3, 'east', 2021), (4, 'east', 2020), (5, NULL, 2021), (6, 'a/b', 2021)`)
+
+	sqlDB.Exec(t, `EXPORT INTO CSV 'nodelocal://0/part' PARTITION BY (region, y)
+		WITH compression = 'zstd' FROM SELECT * FROM foo ORDER BY i`)
+
+	read := func(dest, partition, part string) string {
+		compressed := readFileByGlob(t, filepath.Join(dir, dest, partition, "export*-"+part+".csv.zst"))
+		r := zstd.NewReader(bytes.NewReader(compressed))
+		defer r.Close()
+		content, err := ioutil.ReadAll(r)
+		require.NoError(t, err)
+		return string(content)
+	}
+	require.Equal(t, "1\n4\n", read("part", "region=east/y=2020", "n1.0"))
+	require.Equal(t, "3\n", read("part", "region=east/y=2021", "n1.0"))
+	require.Equal(t, "2\n", read("part", "region=west/y=2020", "n1.0"))
+	require.Equal(t, "5\n", read("part", "region=__HIVE_DEFAULT_PARTITION__/y=2021", "n1.0"))
+	require.Equal(t, "6\n", read("part", "region=a%2Fb/y=2021", "n1.0"))
+
+	// The rows are written one partition at a time, in the order of the
+	// partition columns. The NULL and empty regions share a directory, which
+	// comes back after another partition, and every file is compressed
+	// separately.
+	sqlDB.Exec(t, `INSERT INTO foo VALUES (7, '', 2021), (8, NULL, 2022)`)
+	sqlDB.Exec(t, `EXPORT INTO CSV 'nodelocal://0/chunked' PARTITION BY (region, y)
+		WITH compression = 'zstd', chunk_rows = '1' FROM SELECT * FROM foo ORDER BY i DESC`)
+	require.Equal(t, "4\n", read("chunked", "region=east/y=2020", "n1.0"))
+	require.Equal(t, "1\n", read("chunked", "region=east/y=2020", "n1.1"))
+	require.Equal(t, "5\n", read("chunked", "region=__HIVE_DEFAULT_PARTITION__/y=2021", "n1.0"))
+	require.Equal(t, "7\n", read("chunked", "region=__HIVE_DEFAULT_PARTITION__/y=2021", "n1.1"))
+	require.Equal(t, "8\n", read("chunked", "region=__HIVE_DEFAULT_PARTITION__/y=2022", "n1.0"))
+
+	sqlDB.ExpectErr(t, `PARTITION BY column "z" is not an exported column`,
+		`EXPORT INTO CSV 'nodelocal://0/part' PARTITION BY (z) FROM TABLE foo`)
+	sqlDB.ExpectErr(t, `EXPORT cannot partition by all the exported columns`,
+		`EXPORT INTO CSV 'nodelocal://0/part' PARTITION BY (region) FROM SELECT region FROM foo`)
+	sqlDB.ExpectErr(t, `PARTITION BY is not supported with format PARQUET`,
+		`EXPORT INTO PARQUET 'nodelocal://0/part' PARTITION BY (region) FROM TABLE foo`)
+	sqlDB.ExpectErr(t, `unsupported compression codec zstd`,
+		`EXPORT INTO PARQUET 'nodelocal://0/part' WITH compression = 'zstd' FROM TABLE foo`)
+}
+
+func TestExportMaxFileSize(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+	dir, cleanupDir := testutils.TempDir(t)
+	defer cleanupDir()
+
+	srv, db, _ := serverutils.StartServer(t, base.TestServerArgs{ExternalIODir: dir})
+	defer srv.Stopper().Stop(context.Background())
+	sqlDB := sqlutils.MakeSQLRunner(db)
+
+	sqlDB.Exec(t, `CREATE TABLE foo (i INT PRIMARY KEY, s STRING)`)
+	sqlDB.Exec(t, `INSERT INTO foo SELECT i, repeat('x', 100) FROM generate_series(1, 100) AS g(i)`)
+
+	rows := sqlDB.QueryStr(t, `EXPORT INTO CSV 'nodelocal://0/size' WITH max_file_size = '1KiB'
+		FROM SELECT * FROM foo ORDER BY i`)
+	var total int
+	for _, row := range rows {
+		n, err := strconv.Atoi(row[1])
+		require.NoError(t, err)
+		total += n
+		size, err := strconv.Atoi(row[2])
+		require.NoError(t, err)
+		// The size of a file is only known once the rows buffered by the csv
+		// writer, up to 4KiB, are flushed, which may take it past the limit.
+		require.Less(t, size, 1024+4096+110)
+	}
+	require.Greater(t, len(rows), 1)
+	require.Equal(t, 100, total)
+
+	sqlDB.ExpectErr(t, `invalid max file size`,
+		`EXPORT INTO CSV 'nodelocal://0/size' WITH max_file_size = '0' FROM TABLE foo`)
+}
+
 func TestExportShow(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 	defer log.Scope(t).Close(t)
@@ -0,0 +1,131 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package importccl_test
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/testcluster"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	goparquet "github.com/fraugster/parquet-go"
+	"github.com/stretchr/testify/require"
+)
+
+// readParquetFile returns the schema and all the rows of an exported Parquet
+// file.
+func readParquetFile(t *testing.T, path string) (string, []map[string]interface{}) {
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+
+	r, err := goparquet.NewFileReader(f)
+	require.NoError(t, err)
+	var rows []map[string]interface{}
+	for {
+		row, err := r.NextRow()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		rows = append(rows, row)
+	}
+	return r.GetSchemaDefinition().String(), rows
+}
+
+func TestExportParquet(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	dir, cleanup := testutils.TempDir(t)
+	defer cleanup()
+
+	tc := testcluster.StartTestCluster(
+		t, 1, base.TestClusterArgs{ServerArgs: base.TestServerArgs{ExternalIODir: dir}})
+	defer tc.Stopper().Stop(ctx)
+
+	db := sqlutils.MakeSQLRunner(tc.Conns[0])
+	db.Exec(t, `CREATE TABLE t (
+		id INT PRIMARY KEY,
+		d DECIMAL(10, 2),
+		ts TIMESTAMPTZ,
+		a STRING[],
+		j JSONB
+	)`)
+	db.Exec(t, `INSERT INTO t VALUES
+		(1, 1.5, '2021-01-01 00:00:00+00', ARRAY['x', 'y'], '{"k": 1}'),
+		(2, NULL, NULL, NULL, NULL),
+		(3, -2.25, '1970-01-01 00:00:01+00', ARRAY[], '[]')`)
+
+	t.Run("types", func(t *testing.T) {
+		rows := db.QueryStr(t, `EXPORT INTO PARQUET 'nodelocal://0/types' FROM SELECT * FROM t ORDER BY id`)
+		require.Len(t, rows, 1)
+		require.Regexp(t, `^export.*-n1\.0\.parquet$`, rows[0][0])
+		require.Equal(t, "3", rows[0][1])
+
+		schema, got := readParquetFile(t, filepath.Join(dir, "types", rows[0][0]))
+		require.Contains(t, schema, `optional int64 id (INT(64, true));`)
+		require.Contains(t, schema, `optional binary d (DECIMAL(10, 2));`)
+		require.Contains(t, schema, `optional int64 ts (TIMESTAMP(MICROS, true));`)
+		require.Contains(t, schema, `optional group a (LIST) {`)
+		require.Contains(t, schema, `optional binary element (STRING);`)
+		require.Contains(t, schema, `optional binary j (JSON);`)
+
+		require.Len(t, got, 3)
+		require.Equal(t, map[string]interface{}{
+			"id": int64(1),
+			"d":  []byte{0, 0x96},
+			"ts": int64(1609459200000000),
+			"a": map[string]interface{}{"list": []map[string]interface{}{
+				{"element": []byte("x")}, {"element": []byte("y")},
+			}},
+			"j": []byte(`{"k": 1}`),
+		}, got[0])
+		require.Equal(t, map[string]interface{}{"id": int64(2)}, got[1])
+		require.Equal(t, int64(3), got[2]["id"])
+		require.Equal(t, []byte{0xff, 0x1f}, got[2]["d"])
+		require.Equal(t, int64(1000000), got[2]["ts"])
+		require.Equal(t, []byte(`[]`), got[2]["j"])
+	})
+
+	t.Run("chunks", func(t *testing.T) {
+		rows := db.QueryStr(t, `EXPORT INTO PARQUET 'nodelocal://0/chunks'
+			WITH chunk_rows = '2', compression = 'snappy' FROM SELECT id FROM t ORDER BY id`)
+		require.Len(t, rows, 2)
+		var total int
+		for _, row := range rows {
+			n, err := strconv.Atoi(row[1])
+			require.NoError(t, err)
+			total += n
+			_, got := readParquetFile(t, filepath.Join(dir, "chunks", row[0]))
+			require.Len(t, got, n)
+		}
+		require.Equal(t, 3, total)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		db.ExpectErr(t, `option "delimiter" is not supported with format PARQUET`,
+			`EXPORT INTO PARQUET 'nodelocal://0/err' WITH delimiter = '|' FROM SELECT * FROM t`)
+		db.ExpectErr(t, `option "nullas" is not supported with format PARQUET`,
+			`EXPORT INTO PARQUET 'nodelocal://0/err' WITH nullas = '' FROM SELECT * FROM t`)
+		db.ExpectErr(t, `unsupported compression codec snappy`,
+			`EXPORT INTO CSV 'nodelocal://0/err' WITH compression = 'snappy' FROM SELECT * FROM t`)
+		db.ExpectErr(t, `duplicate column name "id"`,
+			`EXPORT INTO PARQUET 'nodelocal://0/err' FROM SELECT id, id FROM t`)
+	})
+}
@@ -0,0 +1,454 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package importccl
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/diskmap"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/tabledesc"
+	"github.com/cockroachdb/cockroach/pkg/sql/execinfra"
+	"github.com/cockroachdb/cockroach/pkg/sql/execinfrapb"
+	"github.com/cockroachdb/cockroach/pkg/sql/row"
+	"github.com/cockroachdb/cockroach/pkg/storage"
+	"github.com/cockroachdb/cockroach/pkg/storage/cloud"
+	"github.com/cockroachdb/cockroach/pkg/storage/cloudimpl"
+	"github.com/cockroachdb/cockroach/pkg/util/encoding"
+	"github.com/cockroachdb/cockroach/pkg/util/encoding/csv"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/mon"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+)
+
+// dryRunMaxReportedRows is the maximum number of rejected rows of an input
+// file which are written to the report of a dry run. Only the number of the
+// rows after them is reported.
+const dryRunMaxReportedRows = 10000
+
+// dryRunDuplicatesReport is the name of the file under the report URI to which
+// the rows which violate a unique constraint are reported.
+const dryRunDuplicatesReport = "duplicates.csv"
+
+// dryRun holds the state shared by the files read by a processor of an IMPORT
+// which only validates its input: the rows are converted to KVs as usual, but
+// the KVs are counted instead of ingested, and the rows which fail to convert
+// are reported to files under the report URI.
+//
+// The keys in unique indexes of the converted rows are recorded, along with the
+// position of their row, in a map on temporary storage. Once the input is read
+// they are sent, sorted, to the coordinator of the import, which merges them
+// with those of the other processors to find the rows which violate a unique
+// constraint (see dryRunDuplicates).
+type dryRun struct {
+	report string
+	// files holds the URIs of the input files, stripped of credentials, by
+	// which the rejected rows are reported.
+	files map[int32]string
+	codec keys.SQLCodec
+
+	diskMonitor *mon.BytesMonitor
+	mu          struct {
+		syncutil.Mutex
+		keys    diskmap.SortedDiskMap
+		writer  diskmap.SortedDiskMapBatchWriter
+		diskAcc mon.BoundAccount
+	}
+}
+
+// rowPosition is the position of a row in the input files.
+type rowPosition struct {
+	source int32
+	row    int64
+}
+
+func newDryRun(
+	ctx context.Context, flowCtx *execinfra.FlowCtx, spec *execinfrapb.ReadImportDataSpec,
+) (*dryRun, error) {
+	d := &dryRun{
+		report: spec.Format.DryRunReport,
+		files:  make(map[int32]string, len(spec.Uri)),
+		codec:  flowCtx.Codec(),
+	}
+	for id, file := range spec.Uri {
+		clean, err := cloudimpl.SanitizeExternalStorageURI(file, nil /* extraParams */)
+		if err != nil {
+			return nil, err
+		}
+		d.files[id] = clean
+	}
+	d.diskMonitor = execinfra.NewMonitor(ctx, flowCtx.Cfg.DiskMonitor, "import-dry-run-disk")
+	d.mu.keys = flowCtx.Cfg.TempStorage.NewSortedDiskMap()
+	d.mu.writer = d.mu.keys.NewBatchWriter()
+	d.mu.diskAcc = d.diskMonitor.MakeBoundAccount()
+	return d, nil
+}
+
+// close releases the temporary storage of the recorded keys.
+func (d *dryRun) close(ctx context.Context) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.mu.writer != nil {
+		if err := d.mu.writer.Close(ctx); err != nil {
+			log.Warningf(ctx, "closing dry run keys: %v", err)
+		}
+		d.mu.writer = nil
+	}
+	d.mu.keys.Close(ctx)
+	d.mu.diskAcc.Close(ctx)
+	d.diskMonitor.Stop(ctx)
+}
+
+// encodeDryRunKey encodes a key in a unique index and the position of the row
+// it was converted from, such that the encodings sort by key and then by
+// position.
+func encodeDryRunKey(key roachpb.Key, pos rowPosition) []byte {
+	b := encoding.EncodeBytesAscending(nil, key)
+	b = encoding.EncodeVarintAscending(b, int64(pos.source))
+	return encoding.EncodeVarintAscending(b, pos.row)
+}
+
+func decodeDryRunKey(b []byte) (roachpb.Key, rowPosition, error) {
+	var key []byte
+	var source int64
+	var pos rowPosition
+	b, key, err := encoding.DecodeBytesAscending(b, nil)
+	if err != nil {
+		return nil, pos, err
+	}
+	if b, source, err = encoding.DecodeVarintAscending(b); err != nil {
+		return nil, pos, err
+	}
+	pos.source = int32(source)
+	if _, pos.row, err = encoding.DecodeVarintAscending(b); err != nil {
+		return nil, pos, err
+	}
+	return key, pos, nil
+}
+
+// recordKeys records the keys in unique indexes of the table among the KVs of
+// a row.
+func (d *dryRun) recordKeys(
+	ctx context.Context,
+	tableDesc *tabledesc.Immutable,
+	source int32,
+	rowNum int64,
+	kvs []roachpb.KeyValue,
+) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for _, kv := range kvs {
+		_, _, indexID, err := d.codec.DecodeIndexPrefix(kv.Key)
+		if err != nil {
+			return err
+		}
+		idx, err := tableDesc.FindIndexByID(descpb.IndexID(indexID))
+		if err != nil {
+			return err
+		}
+		if !idx.Unique {
+			continue
+		}
+		k := encodeDryRunKey(kv.Key, rowPosition{source: source, row: rowNum})
+		if err := d.mu.diskAcc.Grow(ctx, int64(len(k))); err != nil {
+			return err
+		}
+		if err := d.mu.writer.Put(k, nil /* v */); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sendKeys calls fn with each of the recorded keys, as encoded by
+// encodeDryRunKey, in sorted order. No key can be recorded afterwards.
+func (d *dryRun) sendKeys(ctx context.Context, fn func([]byte) error) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if err := d.mu.writer.Close(ctx); err != nil {
+		return err
+	}
+	d.mu.writer = nil
+	it := d.mu.keys.NewIterator()
+	defer it.Close()
+	for it.Rewind(); ; it.Next() {
+		if ok, err := it.Valid(); err != nil {
+			return err
+		} else if !ok {
+			return nil
+		}
+		if err := fn(append([]byte(nil), it.UnsafeKey()...)); err != nil {
+			return err
+		}
+	}
+}
+
+// reportLine formats a line of the report for a row of a file, or for the
+// file as a whole if rowNum is 0.
+func (d *dryRun) reportLine(source int32, rowNum int64, msg string) string {
+	return formatReportLine(d.files[source], rowNum, msg)
+}
+
+func formatReportLine(file string, rowNum int64, msg string) string {
+	var row string
+	if rowNum > 0 {
+		row = strconv.FormatInt(rowNum, 10)
+	}
+	var buf strings.Builder
+	w := csv.NewWriter(&buf)
+	// Writing to a strings.Builder can't fail.
+	_ = w.Write([]string{file, row, msg})
+	w.Flush()
+	return buf.String()
+}
+
+// writeReport writes the lines for the rejected rows of an input file, which
+// are received on rejected until it is closed, to a file under the report URI.
+// Nothing is written if no row was rejected.
+func (d *dryRun) writeReport(
+	ctx context.Context,
+	source int32,
+	rejected <-chan string,
+	makeExternalStorage cloud.ExternalStorageFactory,
+	user security.SQLUsername,
+) error {
+	var buf bytes.Buffer
+	buf.WriteString("file,row,error\n")
+	var numRejected int64
+	for line := range rejected {
+		numRejected++
+		if numRejected <= dryRunMaxReportedRows {
+			buf.WriteString(line)
+		}
+	}
+	if numRejected == 0 {
+		return nil
+	}
+	if omitted := numRejected - dryRunMaxReportedRows; omitted > 0 {
+		buf.WriteString(d.reportLine(source, 0, fmt.Sprintf("%d more rows rejected", omitted)))
+	}
+
+	conf, err := cloudimpl.ExternalStorageConfFromURI(d.report, user)
+	if err != nil {
+		return err
+	}
+	es, err := makeExternalStorage(ctx, conf)
+	if err != nil {
+		return err
+	}
+	defer es.Close()
+	return es.WriteFile(ctx, fmt.Sprintf("rejected-%d.csv", source), bytes.NewReader(buf.Bytes()))
+}
+
+// dryRunDuplicates finds the rows of a dry run which violate a unique
+// constraint, on the coordinator of the import. It is passed the keys recorded
+// by every processor in a single stream sorted by encodeDryRunKey, so all the
+// rows with the same key are next to each other, ordered by their position in
+// the input files; every one of them but the first is rejected.
+//
+// The rejected rows are kept in a map on temporary storage, where a row which
+// has the same key as another one in more than one unique index appears only
+// once, until they are reported.
+type dryRunDuplicates struct {
+	report string
+	files  []string
+	codec  keys.SQLCodec
+	tables map[descpb.ID]*tabledesc.Immutable
+
+	lastKey roachpb.Key
+	lastPos rowPosition
+
+	diskMonitor *mon.BytesMonitor
+	diskAcc     mon.BoundAccount
+	rejected    diskmap.SortedDiskMap
+	writer      diskmap.SortedDiskMapBatchWriter
+}
+
+func newDryRunDuplicates(
+	ctx context.Context,
+	execCfg *sql.ExecutorConfig,
+	report string,
+	files []string,
+	tables []jobspb.ImportDetails_Table,
+) (*dryRunDuplicates, error) {
+	d := &dryRunDuplicates{
+		report: report,
+		files:  make([]string, len(files)),
+		codec:  execCfg.Codec,
+		tables: make(map[descpb.ID]*tabledesc.Immutable, len(tables)),
+	}
+	for i, file := range files {
+		clean, err := cloudimpl.SanitizeExternalStorageURI(file, nil /* extraParams */)
+		if err != nil {
+			return nil, err
+		}
+		d.files[i] = clean
+	}
+	for _, table := range tables {
+		d.tables[table.Desc.ID] = tabledesc.NewImmutable(*table.Desc)
+	}
+	d.diskMonitor = execinfra.NewMonitor(ctx, execCfg.DistSQLSrv.DiskMonitor, "import-dry-run-duplicates")
+	d.diskAcc = d.diskMonitor.MakeBoundAccount()
+	d.rejected = execCfg.DistSQLSrv.TempStorage.NewSortedDiskMap()
+	d.writer = d.rejected.NewBatchWriter()
+	return d, nil
+}
+
+// close releases the temporary storage of the rejected rows.
+func (d *dryRunDuplicates) close(ctx context.Context) {
+	if d.writer != nil {
+		if err := d.writer.Close(ctx); err != nil {
+			log.Warningf(ctx, "closing dry run duplicates: %v", err)
+		}
+	}
+	d.rejected.Close(ctx)
+	d.diskAcc.Close(ctx)
+	d.diskMonitor.Stop(ctx)
+}
+
+// add receives the next key sent by the processors.
+func (d *dryRunDuplicates) add(ctx context.Context, encoded []byte) error {
+	key, pos, err := decodeDryRunKey(encoded)
+	if err != nil {
+		return err
+	}
+	if !d.lastKey.Equal(key) {
+		d.lastKey, d.lastPos = key, pos
+		return nil
+	}
+	_, tableID, indexID, err := d.codec.DecodeIndexPrefix(key)
+	if err != nil {
+		return err
+	}
+	var index string
+	if table, ok := d.tables[descpb.ID(tableID)]; ok {
+		if idx, err := table.FindIndexByID(descpb.IndexID(indexID)); err == nil {
+			index = idx.Name
+		}
+	}
+	msg := fmt.Sprintf("duplicate key value violates unique constraint %q: same key as row %d of %s",
+		index, d.lastPos.row, d.files[d.lastPos.source])
+
+	k := encoding.EncodeVarintAscending(nil, int64(pos.source))
+	k = encoding.EncodeVarintAscending(k, pos.row)
+	if err := d.diskAcc.Grow(ctx, int64(len(k)+len(msg))); err != nil {
+		return err
+	}
+	return d.writer.Put(k, []byte(msg))
+}
+
+// writeReport writes the rejected rows, ordered by their position in the
+// input, to a file under the report URI and returns how many there are.
+// Nothing is written if no row was rejected.
+func (d *dryRunDuplicates) writeReport(
+	ctx context.Context, makeExternalStorage cloud.ExternalStorageFactory, user security.SQLUsername,
+) (int64, error) {
+	if err := d.writer.Close(ctx); err != nil {
+		return 0, err
+	}
+	d.writer = nil
+
+	var buf bytes.Buffer
+	buf.WriteString("file,row,error\n")
+	var numRejected int64
+	it := d.rejected.NewIterator()
+	defer it.Close()
+	for it.Rewind(); ; it.Next() {
+		if ok, err := it.Valid(); err != nil {
+			return 0, err
+		} else if !ok {
+			break
+		}
+		numRejected++
+		if numRejected > dryRunMaxReportedRows {
+			continue
+		}
+		b, source, err := encoding.DecodeVarintAscending(it.UnsafeKey())
+		if err != nil {
+			return 0, err
+		}
+		_, rowNum, err := encoding.DecodeVarintAscending(b)
+		if err != nil {
+			return 0, err
+		}
+		buf.WriteString(formatReportLine(d.files[source], rowNum, string(it.UnsafeValue())))
+	}
+	if numRejected == 0 {
+		return 0, nil
+	}
+	if omitted := numRejected - dryRunMaxReportedRows; omitted > 0 {
+		buf.WriteString(formatReportLine("", 0, fmt.Sprintf("%d more rows rejected", omitted)))
+	}
+
+	conf, err := cloudimpl.ExternalStorageConfFromURI(d.report, user)
+	if err != nil {
+		return 0, err
+	}
+	es, err := makeExternalStorage(ctx, conf)
+	if err != nil {
+		return 0, err
+	}
+	defer es.Close()
+	if err := es.WriteFile(ctx, dryRunDuplicatesReport, bytes.NewReader(buf.Bytes())); err != nil {
+		return 0, err
+	}
+	return numRejected, nil
+}
+
+// countKvs drains kvs from the channel until it closes, like ingestKvs, but
+// only counts the rows and index entries they contain instead of ingesting
+// them.
+func countKvs(
+	ctx context.Context,
+	spec *execinfrapb.ReadImportDataSpec,
+	progCh chan execinfrapb.RemoteProducerMetadata_BulkProcessorProgress,
+	kvCh <-chan row.KVBatch,
+) (*roachpb.BulkOpSummary, error) {
+	var counter storage.RowCounter
+	fractions := make(map[int32]float32, len(spec.Uri))
+	lastProgress := timeutil.Now()
+	for kvBatch := range kvCh {
+		for _, kv := range kvBatch.KVs {
+			if err := counter.Count(kv.Key); err != nil {
+				return nil, err
+			}
+			counter.DataSize += int64(len(kv.Key) + len(kv.Value.RawBytes))
+		}
+		fractions[kvBatch.Source] = kvBatch.Progress
+
+		if timeutil.Since(lastProgress) < 10*time.Second {
+			continue
+		}
+		// Dry runs start over when they are resumed, so only the fraction of
+		// each file that was read is reported.
+		var prog execinfrapb.RemoteProducerMetadata_BulkProcessorProgress
+		prog.CompletedFraction = make(map[int32]float32, len(fractions))
+		for file, fraction := range fractions {
+			prog.CompletedFraction[file] = fraction
+		}
+		select {
+		case progCh <- prog:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		lastProgress = timeutil.Now()
+	}
+	return &counter.BulkOpSummary, nil
+}
@@ -31,6 +31,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/errorutil/unimplemented"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
 	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
 	"github.com/cockroachdb/cockroach/pkg/util/tracing"
 	"github.com/cockroachdb/errors"
@@ -80,6 +81,16 @@ func (cp *readImportDataProcessor) Run(ctx context.Context) {
 	defer span.Finish()
 	defer cp.output.ProducerDone()
 
+	var dryRun *dryRun
+	if cp.spec.Format.DryRunReport != "" {
+		var err error
+		if dryRun, err = newDryRun(ctx, cp.flowCtx, &cp.spec); err != nil {
+			cp.output.Push(nil, &execinfrapb.ProducerMetadata{Err: err})
+			return
+		}
+		defer dryRun.close(ctx)
+	}
+
 	progCh := make(chan execinfrapb.RemoteProducerMetadata_BulkProcessorProgress)
 
 	var summary *roachpb.BulkOpSummary
@@ -88,7 +99,7 @@ func (cp *readImportDataProcessor) Run(ctx context.Context) {
 	// which is closed only after the go routine returns.
 	go func() {
 		defer close(progCh)
-		summary, err = runImport(ctx, cp.flowCtx, &cp.spec, progCh)
+		summary, err = runImport(ctx, cp.flowCtx, &cp.spec, dryRun, progCh)
 	}()
 
 	for prog := range progCh {
@@ -113,6 +124,23 @@ func (cp *readImportDataProcessor) Run(ctx context.Context) {
 		rowenc.DatumToEncDatum(types.Bytes, tree.NewDBytes(tree.DBytes(countsBytes))),
 		rowenc.DatumToEncDatum(types.Bytes, tree.NewDBytes(tree.DBytes([]byte{}))),
 	}, nil)
+
+	// The keys recorded by a dry run follow the summary, in the second column
+	// and in sorted order, so that the coordinator can merge the keys of all
+	// the processors to find duplicates.
+	if dryRun != nil {
+		if err := dryRun.sendKeys(ctx, func(key []byte) error {
+			if cp.output.Push(rowenc.EncDatumRow{
+				rowenc.DatumToEncDatum(types.Bytes, tree.NewDBytes(tree.DBytes([]byte{}))),
+				rowenc.DatumToEncDatum(types.Bytes, tree.NewDBytes(tree.DBytes(key))),
+			}, nil) != execinfra.NeedMoreRows {
+				return errors.New("the keys of the dry run are no longer consumed")
+			}
+			return nil
+		}); err != nil {
+			cp.output.Push(nil, &execinfrapb.ProducerMetadata{Err: err})
+		}
+	}
 }
 
 func makeInputConverter(
@@ -193,6 +221,12 @@ func makeInputConverter(
 		return newAvroInputReader(
 			kvCh, singleTable, spec.Format.Avro, spec.WalltimeNanos,
 			int(spec.ReaderParallelism), evalCtx)
+	case roachpb.IOFileFormat_NDJSON:
+		return newNDJSONInputReader(spec.Format.NDJSON, kvCh, spec.WalltimeNanos,
+			int(spec.ReaderParallelism), singleTable, singleTableTargetCols, evalCtx)
+	case roachpb.IOFileFormat_Parquet:
+		return newParquetInputReader(spec.Format.Parquet, kvCh, spec.WalltimeNanos,
+			int(spec.ReaderParallelism), singleTable, singleTableTargetCols, evalCtx)
 	default:
 		return nil, errors.Errorf(
 			"Requested IMPORT format (%d) not supported by this node", spec.Format.Format)
@@ -257,34 +291,42 @@ func ingestKvs(
 	// Setup progress tracking:
 	//  - offsets maps source file IDs to offsets in the slices below.
 	//  - writtenRow contains LastRow of batch most recently added to the buffer.
+	//  - writtenOffset contains LastOffset of the same batch.
 	//  - writtenFraction contains % of the input finished as of last batch.
 	//  - pkFlushedRow contains `writtenRow` as of the last pk adder flush.
 	//  - idxFlushedRow contains `writtenRow` as of the last index adder flush.
-	// In pkFlushedRow, idxFlushedRow and writtenFaction values are written via
-	// `atomic` so the progress reporting go goroutine can read them.
+	//  - pkFlushedOffset and idxFlushedOffset likewise contain `writtenOffset`.
+	// writtenFaction values are written via `atomic`, and the flushed rows and
+	// offsets under flushedMu, so the progress reporting go goroutine can read
+	// them. A row and its offset are read together so that they always match.
 	writtenRow := make([]int64, len(spec.Uri))
+	writtenOffset := make([]int64, len(spec.Uri))
 	writtenFraction := make([]uint32, len(spec.Uri))
 
+	var flushedMu syncutil.Mutex
 	pkFlushedRow := make([]int64, len(spec.Uri))
 	idxFlushedRow := make([]int64, len(spec.Uri))
+	pkFlushedOffset := make([]int64, len(spec.Uri))
+	idxFlushedOffset := make([]int64, len(spec.Uri))
 
 	// When the PK adder flushes, everything written has been flushed, so we set
 	// pkFlushedRow to writtenRow. Additionally if the indexAdder is empty then we
 	// can treat it as flushed as well (in case we're not adding anything to it).
 	pkIndexAdder.SetOnFlush(func() {
-		for i, emitted := range writtenRow {
-			atomic.StoreInt64(&pkFlushedRow[i], emitted)
-		}
+		flushedMu.Lock()
+		defer flushedMu.Unlock()
+		copy(pkFlushedRow, writtenRow)
+		copy(pkFlushedOffset, writtenOffset)
 		if indexAdder.IsEmpty() {
-			for i, emitted := range writtenRow {
-				atomic.StoreInt64(&idxFlushedRow[i], emitted)
-			}
+			copy(idxFlushedRow, writtenRow)
+			copy(idxFlushedOffset, writtenOffset)
 		}
 	})
 	indexAdder.SetOnFlush(func() {
-		for i, emitted := range writtenRow {
-			atomic.StoreInt64(&idxFlushedRow[i], emitted)
-		}
+		flushedMu.Lock()
+		defer flushedMu.Unlock()
+		copy(idxFlushedRow, writtenRow)
+		copy(idxFlushedOffset, writtenOffset)
 	})
 
 	// offsets maps input file ID to a slot in our progress tracking slices.
@@ -298,19 +340,24 @@ func ingestKvs(
 	pushProgress := func() {
 		var prog execinfrapb.RemoteProducerMetadata_BulkProcessorProgress
 		prog.ResumePos = make(map[int32]int64)
+		prog.ResumeOffset = make(map[int32]int64)
 		prog.CompletedFraction = make(map[int32]float32)
+		flushedMu.Lock()
 		for file, offset := range offsets {
-			pk := atomic.LoadInt64(&pkFlushedRow[offset])
-			idx := atomic.LoadInt64(&idxFlushedRow[offset])
 			// On resume we'll be able to skip up the last row for which both the
-			// PK and index adders have flushed KVs.
-			if idx > pk {
-				prog.ResumePos[file] = pk
-			} else {
-				prog.ResumePos[file] = idx
+			// PK and index adders have flushed KVs, and to seek to its offset in
+			// the input if it is known.
+			row, inputOffset := pkFlushedRow[offset], pkFlushedOffset[offset]
+			if idx := idxFlushedRow[offset]; idx < row {
+				row, inputOffset = idx, idxFlushedOffset[offset]
+			}
+			prog.ResumePos[file] = row
+			if inputOffset > 0 {
+				prog.ResumeOffset[file] = inputOffset
 			}
 			prog.CompletedFraction[file] = math.Float32frombits(atomic.LoadUint32(&writtenFraction[offset]))
 		}
+		flushedMu.Unlock()
 		progCh <- prog
 	}
 
@@ -386,6 +433,7 @@ func ingestKvs(
 			}
 			offset := offsets[kvBatch.Source]
 			writtenRow[offset] = kvBatch.LastRow
+			writtenOffset[offset] = kvBatch.LastOffset
 			atomic.StoreUint32(&writtenFraction[offset], math.Float32bits(kvBatch.Progress))
 			if flowCtx.Cfg.TestingKnobs.BulkAdderFlushesEveryBatch {
 				_ = pkIndexAdder.Flush(ctx)
@@ -124,8 +124,8 @@ func TestConverterFlushesBatches(t *testing.T) {
 				group := ctxgroup.WithContext(ctx)
 				group.Go(func() error {
 					defer close(kvCh)
-					return conv.readFiles(ctx, testCase.inputs, nil, converterSpec.Format,
-						externalStorageFactory, security.RootUserName())
+					return conv.readFiles(ctx, testCase.inputs, nil, nil, converterSpec.Format,
+						nil /* dryRun */, externalStorageFactory, security.RootUserName())
 				})
 
 				lastBatch := 0
@@ -418,7 +418,7 @@ func TestImportHonorsResumePosition(t *testing.T) {
 					}
 				}()
 
-				_, err := runImport(ctx, flowCtx, spec, progCh)
+				_, err := runImport(ctx, flowCtx, spec, nil /* dryRun */, progCh)
 
 				if err != nil {
 					t.Fatal(err)
@@ -439,6 +439,113 @@ func TestImportHonorsResumePosition(t *testing.T) {
 	}
 }
 
+func TestImportHonorsResumeOffset(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	batchSize := 13
+	defer row.TestingSetDatumRowConverterBatchSize(batchSize)()
+
+	pkBulkAdder := &doNothingKeyAdder{}
+	ctx := context.Background()
+
+	evalCtx := tree.MakeTestingEvalContext(nil)
+	flowCtx := &execinfra.FlowCtx{
+		EvalCtx: &evalCtx,
+		Cfg: &execinfra.ServerConfig{
+			Settings:        &cluster.Settings{},
+			ExternalStorage: externalStorageFactory,
+			BulkAdder: func(
+				_ context.Context, _ *kv.DB, _ hlc.Timestamp,
+				opts kvserverbase.BulkAdderOptions) (kvserverbase.BulkAdder, error) {
+				if opts.Name == "pkAdder" {
+					return pkBulkAdder, nil
+				}
+				return &doNothingKeyAdder{}, nil
+			},
+			TestingKnobs: execinfra.TestingKnobs{
+				BulkAdderFlushesEveryBatch: true,
+			},
+		},
+	}
+
+	// In this test, we import each file once from the start to collect the
+	// offsets reported alongside resume positions, and then check that
+	// resuming from an offset produces the same keys as resuming from the
+	// same position without one. Compressed files exercise skipping to an
+	// offset in the decompressed stream, rather than seeking to it.
+	testSpecs := []testSpec{
+		newTestSpec(ctx, t, csvFormat(), "testdata/csv/data-0"),
+		newTestSpec(ctx, t, csvFormat(), "testdata/csv/data-0.gz"),
+		newTestSpec(ctx, t, csvFormat(), "testdata/csv/data-0.bz2"),
+		newTestSpec(ctx, t, mysqlOutFormat(), "testdata/mysqlout/csv-ish/simple.txt"),
+	}
+
+	importKeys := func(
+		t *testing.T, spec *execinfrapb.ReadImportDataSpec,
+	) ([]roachpb.Key, []execinfrapb.RemoteProducerMetadata_BulkProcessorProgress) {
+		keys := &observedKeys{}
+		pkBulkAdder.onKeyAdd = func(k roachpb.Key) {
+			keys.Lock()
+			keys.keys = append(keys.keys, k)
+			keys.Unlock()
+		}
+		var progress []execinfrapb.RemoteProducerMetadata_BulkProcessorProgress
+		progCh := make(chan execinfrapb.RemoteProducerMetadata_BulkProcessorProgress)
+		progDone := make(chan struct{})
+		go func() {
+			defer close(progDone)
+			for prog := range progCh {
+				progress = append(progress, prog)
+			}
+		}()
+		_, err := runImport(ctx, flowCtx, spec, nil /* dryRun */, progCh)
+		close(progCh)
+		<-progDone
+		if err != nil {
+			t.Fatal(err)
+		}
+		keys.Lock()
+		defer keys.Unlock()
+		sort.Slice(keys.keys, func(i int, j int) bool {
+			return keys.keys[i].Compare(keys.keys[j]) < 0
+		})
+		return keys.keys, progress
+	}
+
+	for _, testCase := range testSpecs {
+		spec := testCase.getConverterSpec()
+		t.Run(testCase.inputs[0], func(t *testing.T) {
+			spec.ResumePos, spec.ResumeOffset = nil, nil
+			allKeys, progress := importKeys(t, spec)
+
+			// Pick a resume point in the middle of the file.
+			var resumePos, resumeOffset int64
+			for _, prog := range progress {
+				if prog.ResumePos[0] >= int64(len(allKeys)/2) {
+					resumePos, resumeOffset = prog.ResumePos[0], prog.ResumeOffset[0]
+					break
+				}
+			}
+			require.NotZero(t, resumeOffset, "no offset reported in %v", progress)
+
+			spec.ResumePos = map[int32]int64{0: resumePos}
+			expected, _ := importKeys(t, spec)
+			require.NotEmpty(t, expected)
+			require.Less(t, len(expected), len(allKeys))
+
+			spec.ResumeOffset = map[int32]int64{0: resumeOffset}
+			resumed, progress := importKeys(t, spec)
+			require.Equal(t, expected, resumed)
+			for _, prog := range progress {
+				if prog.ResumePos[0] != eofOffset {
+					require.GreaterOrEqual(t, prog.ResumeOffset[0], resumeOffset)
+				}
+			}
+		})
+	}
+}
+
 type duplicateKeyErrorAdder struct {
 	doNothingKeyAdder
 }
@@ -495,7 +602,7 @@ func TestImportHandlesDuplicateKVs(t *testing.T) {
 				}
 			}()
 
-			_, err := runImport(ctx, flowCtx, spec, progCh)
+			_, err := runImport(ctx, flowCtx, spec, nil /* dryRun */, progCh)
 			require.True(t, errors.HasType(err, &kvserverbase.DuplicateKeyError{}))
 		})
 	}
@@ -43,6 +43,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/tabledesc"
 	"github.com/cockroachdb/cockroach/pkg/sql/execinfrapb"
 	"github.com/cockroachdb/cockroach/pkg/sql/gcjob"
+	"github.com/cockroachdb/cockroach/pkg/sql/lexbase"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
@@ -80,13 +81,20 @@ const (
 	importOptionSkipFKs          = "skip_foreign_keys"
 	importOptionDisableGlobMatch = "disable_glob_matching"
 	importOptionSaveRejected     = "experimental_save_rejected"
+	// Validate the rows without importing them, reporting the rejected ones
+	// to files under the given URI.
+	importOptionDryRun = "dry_run"
 
 	pgCopyDelimiter = "delimiter"
 	pgCopyNull      = "nullif"
 
 	optMaxRowSize = "max_row_size"
 
-	// Turn on strict validation when importing avro records.
+	// Turn on strict validation when importing avro records. The option is
+	// shared with NDJSON, where it rejects documents that leave a column without
+	// a value or, when columns are mapped from top-level keys, that have keys
+	// not naming a column; and with parquet, where it rejects files whose
+	// columns do not map one-to-one onto the target table.
 	avroStrict = "strict_validation"
 	// Default input format is assumed to be OCF (object container file).
 	// This default can be changed by specified either of these options.
@@ -99,6 +107,12 @@ const (
 	avroSchema    = "schema"
 	avroSchemaURI = "schema_uri"
 
+	// Comma separated list of column=path pairs, which specify where the value
+	// of a column is found in each NDJSON document.
+	ndjsonColumnPaths = "column_paths"
+	// JSONB column into which each NDJSON document is loaded whole.
+	ndjsonDocumentColumn = "document_column"
+
 	// RunningStatusImportBundleParseSchema indicates to the user that a bundle format
 	// schema is being parsed
 	runningStatusImportBundleParseSchema jobs.RunningStatus = "parsing schema on Import Bundle"
@@ -121,6 +135,7 @@ var importOptionExpectValues = map[string]sql.KVStringOptValidate{
 	importOptionDecompress:   sql.KVStringOptRequireValue,
 	importOptionOversample:   sql.KVStringOptRequireValue,
 	importOptionSaveRejected: sql.KVStringOptRequireNoValue,
+	importOptionDryRun:       sql.KVStringOptRequireValue,
 
 	importOptionSkipFKs:          sql.KVStringOptRequireNoValue,
 	importOptionDisableGlobMatch: sql.KVStringOptRequireNoValue,
@@ -133,6 +148,9 @@ var importOptionExpectValues = map[string]sql.KVStringOptValidate{
 	avroRecordsSeparatedBy: sql.KVStringOptRequireValue,
 	avroBinRecords:         sql.KVStringOptRequireNoValue,
 	avroJSONRecords:        sql.KVStringOptRequireNoValue,
+
+	ndjsonColumnPaths:    sql.KVStringOptRequireValue,
+	ndjsonDocumentColumn: sql.KVStringOptRequireValue,
 }
 
 func makeStringSet(opts ...string) map[string]struct{} {
@@ -146,7 +164,7 @@ func makeStringSet(opts ...string) map[string]struct{} {
 // Options common to all formats.
 var allowedCommonOptions = makeStringSet(
 	importOptionSSTSize, importOptionDecompress, importOptionOversample,
-	importOptionSaveRejected, importOptionDisableGlobMatch)
+	importOptionSaveRejected, importOptionDisableGlobMatch, importOptionDryRun)
 
 // Format specific allowed options.
 var avroAllowedOptions = makeStringSet(
@@ -163,6 +181,10 @@ var mysqlOutAllowedOptions = makeStringSet(
 var mysqlDumpAllowedOptions = makeStringSet(importOptionSkipFKs)
 var pgCopyAllowedOptions = makeStringSet(pgCopyDelimiter, pgCopyNull, optMaxRowSize)
 var pgDumpAllowedOptions = makeStringSet(optMaxRowSize, importOptionSkipFKs)
+var ndjsonAllowedOptions = makeStringSet(
+	ndjsonColumnPaths, ndjsonDocumentColumn, avroStrict, optMaxRowSize, csvRowLimit,
+)
+var parquetAllowedOptions = makeStringSet(avroStrict, csvRowLimit)
 
 // DROP is required because the target table needs to be take offline during
 // IMPORT INTO.
@@ -174,6 +196,8 @@ var allowedIntoFormats = map[string]struct{}{
 	"AVRO":      {},
 	"DELIMITED": {},
 	"PGCOPY":    {},
+	"NDJSON":    {},
+	"PARQUET":   {},
 }
 
 // featureImportEnabled is used to enable and disable the IMPORT feature.
@@ -220,6 +244,13 @@ func importJobDescription(
 		val := importOptionExpectValues[k] == sql.KVStringOptRequireValue
 		val = val || (importOptionExpectValues[k] == sql.KVStringOptAny && len(v) > 0)
 		if val {
+			if k == importOptionDryRun {
+				clean, err := cloudimpl.SanitizeExternalStorageURI(v, nil /* extraParams */)
+				if err != nil {
+					return "", err
+				}
+				v = clean
+			}
 			opt.Value = tree.NewDString(v)
 		}
 		stmt.Options = append(stmt.Options, opt)
@@ -602,6 +633,20 @@ func importPlanHook(
 			if err != nil {
 				return err
 			}
+		case "NDJSON":
+			if err = validateFormatOptions(importStmt.FileFormat, opts, ndjsonAllowedOptions); err != nil {
+				return err
+			}
+			if err := parseNDJSONOptions(opts, &format); err != nil {
+				return err
+			}
+		case "PARQUET":
+			if err = validateFormatOptions(importStmt.FileFormat, opts, parquetAllowedOptions); err != nil {
+				return err
+			}
+			if err := parseParquetOptions(opts, &format); err != nil {
+				return err
+			}
 		default:
 			return unimplemented.Newf("import.format", "unsupported import format: %q", importStmt.FileFormat)
 		}
@@ -645,6 +690,29 @@ func importPlanHook(
 			}
 		}
 
+		if report, ok := opts[importOptionDryRun]; ok {
+			switch format.Format {
+			case roachpb.IOFileFormat_Mysqldump, roachpb.IOFileFormat_PgDump:
+				return pgerror.Newf(pgcode.FeatureNotSupported,
+					"%s is not supported for %s imports", importOptionDryRun, importStmt.FileFormat)
+			}
+			if format.SaveRejected {
+				return errors.Errorf("%s cannot be used with %s", importOptionSaveRejected, importOptionDryRun)
+			}
+			hasExplicitAuth, uriScheme, err := cloudimpl.AccessIsWithExplicitAuth(report)
+			if err != nil {
+				return err
+			}
+			if !hasExplicitAuth {
+				err := p.RequireAdminRole(ctx,
+					fmt.Sprintf("IMPORT with a %s report to the specified %s URI", importOptionDryRun, uriScheme))
+				if err != nil {
+					return err
+				}
+			}
+			format.DryRunReport = report
+		}
+
 		var tableDetails []jobspb.ImportDetails_Table
 		var tableDescs []*tabledesc.Mutable // parallel with tableDetails
 		jobDesc, err := importJobDescription(p, importStmt, nil, filenamePatterns, opts)
@@ -690,10 +758,25 @@ func importPlanHook(
 				return pgerror.New(pgcode.FeatureNotSupported, "Cannot use IMPORT INTO with interleaved tables")
 			}
 
+			// IMPORT INTO writes the imported rows directly, without going
+			// through the mutation path which calls the row-level triggers.
+			if len(found.Triggers) > 0 {
+				return errors.WithHint(
+					pgerror.Newf(pgcode.FeatureNotSupported,
+						"cannot use IMPORT INTO with table %q because it has triggers", found.Name),
+					"drop the triggers, or use INSERT instead.")
+			}
+
 			// Validate target columns.
 			var intoCols []string
 			var isTargetCol = make(map[string]bool)
-			for _, name := range importStmt.IntoCols {
+			intoColNames := importStmt.IntoCols
+			// NDJSON documents which are loaded whole only populate the document
+			// column, and leave the others to their defaults.
+			if len(intoColNames) == 0 && format.NDJSON.DocumentColumn != "" {
+				intoColNames = tree.NameList{tree.Name(format.NDJSON.DocumentColumn)}
+			}
+			for _, name := range intoColNames {
 				active, err := found.FindActiveColumnsByNames(tree.NameList{name})
 				if err != nil {
 					return errors.Wrap(err, "verifying target columns")
@@ -843,7 +926,9 @@ func importPlanHook(
 		var spansToProtect []roachpb.Span
 		codec := p.(sql.PlanHookState).ExecCfg().Codec
 		for i := range tableDetails {
-			if td := &tableDetails[i]; !td.IsNew {
+			// A dry run doesn't write to the tables it imports into, so there is
+			// nothing to revert them to.
+			if td := &tableDetails[i]; !td.IsNew && format.DryRunReport == "" {
 				spansToProtect = append(spansToProtect, tableDescs[i].TableSpan(codec))
 			}
 		}
@@ -982,6 +1067,72 @@ func parseAvroOptions(
 	return nil
 }
 
+func parseNDJSONOptions(opts map[string]string, format *roachpb.IOFileFormat) error {
+	format.Format = roachpb.IOFileFormat_NDJSON
+	_, format.NDJSON.StrictMode = opts[avroStrict]
+	_, format.SaveRejected = opts[importOptionSaveRejected]
+
+	_, haveColumnPaths := opts[ndjsonColumnPaths]
+	_, haveDocumentColumn := opts[ndjsonDocumentColumn]
+	if haveColumnPaths && haveDocumentColumn {
+		return errors.Errorf("only one of the %s or %s options can be set", ndjsonColumnPaths, ndjsonDocumentColumn)
+	}
+	if haveColumnPaths {
+		paths, err := parseNDJSONColumnPaths(opts[ndjsonColumnPaths])
+		if err != nil {
+			return pgerror.Wrapf(err, pgcode.Syntax, "invalid %s value", ndjsonColumnPaths)
+		}
+		for i := range paths {
+			paths[i].Column = lexbase.NormalizeName(paths[i].Column)
+		}
+		format.NDJSON.ColumnPaths = paths
+	}
+	if haveDocumentColumn {
+		format.NDJSON.DocumentColumn = lexbase.NormalizeName(opts[ndjsonDocumentColumn])
+	}
+
+	if override, ok := opts[csvRowLimit]; ok {
+		rowLimit, err := strconv.Atoi(override)
+		if err != nil {
+			return pgerror.Wrapf(err, pgcode.Syntax, "invalid numeric %s value", csvRowLimit)
+		}
+		if rowLimit <= 0 {
+			return pgerror.Newf(pgcode.Syntax, "%s must be > 0", csvRowLimit)
+		}
+		format.NDJSON.RowLimit = int64(rowLimit)
+	}
+
+	format.NDJSON.MaxRowSize = defaultScanBuffer
+	if override, ok := opts[optMaxRowSize]; ok {
+		sz, err := humanizeutil.ParseBytes(override)
+		if err != nil {
+			return err
+		}
+		if sz < 1 || sz > math.MaxInt32 {
+			return errors.Errorf("%s out of range: %d", override, sz)
+		}
+		format.NDJSON.MaxRowSize = int32(sz)
+	}
+	return nil
+}
+
+func parseParquetOptions(opts map[string]string, format *roachpb.IOFileFormat) error {
+	format.Format = roachpb.IOFileFormat_Parquet
+	_, format.Parquet.StrictMode = opts[avroStrict]
+
+	if override, ok := opts[csvRowLimit]; ok {
+		rowLimit, err := strconv.Atoi(override)
+		if err != nil {
+			return pgerror.Wrapf(err, pgcode.Syntax, "invalid numeric %s value", csvRowLimit)
+		}
+		if rowLimit <= 0 {
+			return pgerror.Newf(pgcode.Syntax, "%s must be > 0", csvRowLimit)
+		}
+		format.Parquet.RowLimit = int64(rowLimit)
+	}
+	return nil
+}
+
 type importResumer struct {
 	job      *jobs.Job
 	settings *cluster.Settings
@@ -1145,7 +1296,11 @@ func (r *importResumer) prepareTableDescsForIngestion(
 			var newTableDescs []jobspb.ImportDetails_Table
 			var desc *descpb.TableDescriptor
 			for i, table := range details.Tables {
-				if !table.IsNew {
+				if !table.IsNew && details.Format.DryRunReport != "" {
+					// A dry run doesn't write to the tables it imports into, so they
+					// stay online.
+					importDetails.Tables[i] = table
+				} else if !table.IsNew {
 					desc, err = prepareExistingTableDescForIngestion(ctx, txn, descsCol, table.Desc)
 					if err != nil {
 						return err
@@ -1417,7 +1572,20 @@ func (r *importResumer) Resume(
 		}
 	}
 
-	res, err := sql.DistIngest(ctx, p, r.job, tables, files, format, details.Walltime, r.testingKnobs.alwaysFlushJobProgress)
+	var duplicates *dryRunDuplicates
+	var dryRunKeyFn func(context.Context, []byte) error
+	if format.DryRunReport != "" {
+		var err error
+		duplicates, err = newDryRunDuplicates(ctx, p.ExecCfg(), format.DryRunReport, files, details.Tables)
+		if err != nil {
+			return err
+		}
+		defer duplicates.close(ctx)
+		dryRunKeyFn = duplicates.add
+	}
+
+	res, err := sql.DistIngest(ctx, p, r.job, tables, files, format, details.Walltime,
+		r.testingKnobs.alwaysFlushJobProgress, dryRunKeyFn)
 	if err != nil {
 		return err
 	}
@@ -1433,13 +1601,26 @@ func (r *importResumer) Resume(
 			r.res.IndexEntries += count
 		}
 	}
+	if duplicates != nil {
+		// The rows which violate a unique constraint were counted by the
+		// readers, which could not know about them.
+		rejected, err := duplicates.writeReport(ctx, p.ExecCfg().DistSQLSrv.ExternalStorage, p.User())
+		if err != nil {
+			return err
+		}
+		r.res.Rows -= rejected
+	}
 	if r.testingKnobs.afterImport != nil {
 		if err := r.testingKnobs.afterImport(r.res); err != nil {
 			return err
 		}
 	}
 
-	if err := r.publishTables(ctx, p.ExecCfg()); err != nil {
+	if details.Format.DryRunReport != "" {
+		if err := r.discardDryRunTables(ctx, p.ExecCfg()); err != nil {
+			return err
+		}
+	} else if err := r.publishTables(ctx, p.ExecCfg()); err != nil {
 		return err
 	}
 	// TODO(ajwerner): Should this actually return the error? At this point we've
@@ -1561,6 +1742,27 @@ func (r *importResumer) publishTables(ctx context.Context, execCfg *sql.Executor
 	return nil
 }
 
+// discardDryRunTables drops the tables created by a dry run, as they were only
+// created to convert the rows to KVs, which were never ingested into them.
+func (r *importResumer) discardDryRunTables(ctx context.Context, execCfg *sql.ExecutorConfig) error {
+	details := r.job.Details().(jobspb.ImportDetails)
+	var hasNewTables bool
+	for _, tbl := range details.Tables {
+		hasNewTables = hasNewTables || tbl.IsNew
+	}
+	if !hasNewTables {
+		return nil
+	}
+	log.Event(ctx, "dropping tables created by dry run")
+
+	lm, ie, db := execCfg.LeaseManager, execCfg.InternalExecutor, execCfg.DB
+	return descs.Txn(ctx, execCfg.Settings, lm, ie, db, func(
+		ctx context.Context, txn *kv.Txn, descsCol *descs.Collection,
+	) error {
+		return r.dropTables(ctx, txn, descsCol, execCfg)
+	})
+}
+
 // OnFailOrCancel is part of the jobs.Resumer interface. Removes data that has
 // been committed from a import that has failed or been canceled. It does this
 // by adding the table descriptors in DROP state, which causes the schema change
@@ -1612,10 +1814,13 @@ func (r *importResumer) dropTables(
 		return nil
 	}
 
+	// A dry run ingests no data, and leaves the tables it imports into online.
+	dryRun := details.Format.DryRunReport != ""
+
 	var revert []*tabledesc.Immutable
 	var empty []*tabledesc.Immutable
 	for _, tbl := range details.Tables {
-		if !tbl.IsNew {
+		if !tbl.IsNew && !dryRun {
 			desc, err := descsCol.GetMutableTableVersionByID(ctx, tbl.Desc.ID, txn)
 			if err != nil {
 				return err
@@ -1658,6 +1863,9 @@ func (r *importResumer) dropTables(
 	dropTime := int64(1)
 	tablesToGC := make([]descpb.ID, 0, len(details.Tables))
 	for _, tbl := range details.Tables {
+		if !tbl.IsNew && dryRun {
+			continue
+		}
 		newTableDesc, err := descsCol.GetMutableTableVersionByID(ctx, tbl.Desc.ID, txn)
 		if err != nil {
 			return err
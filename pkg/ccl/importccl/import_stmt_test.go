@@ -12,6 +12,7 @@ import (
 	"bytes"
 	"context"
 	gosql "database/sql"
+	"encoding/csv"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -20,6 +21,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"os"
 	"path/filepath"
 	"regexp"
 	"strconv"
@@ -48,6 +50,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/stats"
 	"github.com/cockroachdb/cockroach/pkg/sql/tests"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
 	"github.com/cockroachdb/cockroach/pkg/storage/cloudimpl"
 	"github.com/cockroachdb/cockroach/pkg/testutils"
 	"github.com/cockroachdb/cockroach/pkg/testutils/jobutils"
@@ -60,6 +63,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/parquet"
 	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
 	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
 	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
@@ -3090,6 +3094,20 @@ func TestImportIntoCSV(t *testing.T) {
 			fmt.Sprintf(`IMPORT INTO child (parent_id, child_id) CSV DATA (%s)`, testFiles.files[0]))
 	})
 
+	// IMPORT INTO bypasses the mutation path, so it rejects tables with
+	// row-level triggers.
+	t.Run("import-into-rejects-tables-with-triggers", func(t *testing.T) {
+		sqlDB.Exec(t, `CREATE TABLE t (a INT)`)
+		sqlDB.Exec(t, `CREATE FUNCTION f(x INT) RETURNS INT LANGUAGE SQL AS 'SELECT x'`)
+		sqlDB.Exec(t, `CREATE TRIGGER trig AFTER INSERT ON t FOR EACH ROW EXECUTE FUNCTION f(new.a)`)
+		defer sqlDB.Exec(t, `DROP FUNCTION f`)
+		defer sqlDB.Exec(t, `DROP TABLE t`)
+
+		sqlDB.ExpectErr(
+			t, `cannot use IMPORT INTO with table "t" because it has triggers`,
+			fmt.Sprintf(`IMPORT INTO t (a) CSV DATA (%s)`, testFiles.files[0]))
+	})
+
 	// This tests that consecutive imports from unique data sources into an
 	// existing table without an explicit PK, do not overwrite each other. It
 	// exercises the row_id generation in IMPORT.
@@ -5575,6 +5593,418 @@ func TestImportAvro(t *testing.T) {
 	})
 }
 
+func TestImportNDJSON(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	baseDir, cleanup := testutils.TempDir(t)
+	defer cleanup()
+	tc := testcluster.StartTestCluster(
+		t, 1, base.TestClusterArgs{ServerArgs: base.TestServerArgs{ExternalIODir: baseDir}})
+	defer tc.Stopper().Stop(ctx)
+	sqlDB := sqlutils.MakeSQLRunner(tc.Conns[0])
+
+	writeFile := func(name, data string) string {
+		require.NoError(t, ioutil.WriteFile(filepath.Join(baseDir, name), []byte(data), 0644))
+		return fmt.Sprintf("nodelocal://0/%s", name)
+	}
+	users := writeFile("users.ndjson", `{"id": 1, "Name": "alice", "tags": ["a", "b"], "address": {"city": "nyc"}}
+
+{"id": 2, "name": "bob", "score": 1.5, "address": {"city": "sf", "zip": "94107"}}
+{"id": 3, "name": null, "extra": true}
+`)
+	bad := writeFile("bad.ndjson", `{"id": 1, "name": "alice"}
+{"id": "two", "name": "bob"}
+not json
+{"id": 4, "name": "dan"}
+`)
+	extra := writeFile("extra.ndjson", `{"id": 1, "name": "alice", "extra": true}
+`)
+
+	tests := []struct {
+		name     string
+		create   string
+		sql      string
+		args     []interface{}
+		expected [][]string
+		err      string
+	}{
+		{
+			name:   "top-level-keys",
+			create: `CREATE TABLE t (id INT PRIMARY KEY, name STRING, score DECIMAL, tags STRING[], address JSONB)`,
+			sql:    `IMPORT INTO t NDJSON DATA ($1)`,
+			args:   []interface{}{users},
+			expected: [][]string{
+				{"1", "alice", "NULL", "{a,b}", `{"city": "nyc"}`},
+				{"2", "bob", "1.5", "NULL", `{"city": "sf", "zip": "94107"}`},
+				{"3", "NULL", "NULL", "NULL", "NULL"},
+			},
+		},
+		{
+			name:   "target-columns",
+			create: `CREATE TABLE t (id INT PRIMARY KEY, name STRING, note STRING DEFAULT 'none')`,
+			sql:    `IMPORT INTO t (id, name) NDJSON DATA ($1)`,
+			args:   []interface{}{users},
+			expected: [][]string{
+				{"1", "alice", "none"},
+				{"2", "bob", "none"},
+				{"3", "NULL", "none"},
+			},
+		},
+		{
+			name:   "column-paths",
+			create: `CREATE TABLE t (id INT PRIMARY KEY, city STRING, zip STRING, first_tag STRING)`,
+			sql:    `IMPORT INTO t NDJSON DATA ($1) WITH column_paths = 'city=$.address.city, zip=address.zip, first_tag=$.tags[0]'`,
+			args:   []interface{}{users},
+			expected: [][]string{
+				{"1", "nyc", "NULL", "a"},
+				{"2", "sf", "94107", "NULL"},
+				{"3", "NULL", "NULL", "NULL"},
+			},
+		},
+		{
+			name:   "document-column",
+			create: `CREATE TABLE t (id INT PRIMARY KEY DEFAULT unique_rowid(), doc JSONB)`,
+			sql:    `IMPORT INTO t NDJSON DATA ($1) WITH document_column = 'doc'`,
+			args:   []interface{}{users},
+			expected: [][]string{
+				{`{"Name": "alice", "address": {"city": "nyc"}, "id": 1, "tags": ["a", "b"]}`},
+				{`{"address": {"city": "sf", "zip": "94107"}, "id": 2, "name": "bob", "score": 1.5}`},
+				{`{"extra": true, "id": 3, "name": null}`},
+			},
+		},
+		{
+			name:   "document-column-must-be-jsonb",
+			create: `CREATE TABLE t (id INT PRIMARY KEY DEFAULT unique_rowid(), doc STRING)`,
+			sql:    `IMPORT INTO t NDJSON DATA ($1) WITH document_column = 'doc'`,
+			args:   []interface{}{users},
+			err:    `document column "doc" must be of type JSONB`,
+		},
+		{
+			name:   "column-paths-and-document-column",
+			create: `CREATE TABLE t (id INT PRIMARY KEY, doc JSONB)`,
+			sql:    `IMPORT INTO t NDJSON DATA ($1) WITH document_column = 'doc', column_paths = 'id=$.id'`,
+			args:   []interface{}{users},
+			err:    `only one of the column_paths or document_column options can be set`,
+		},
+		{
+			name:   "strict-errors-on-missing-keys",
+			create: `CREATE TABLE t (id INT PRIMARY KEY, name STRING, score DECIMAL, tags STRING[], address JSONB)`,
+			sql:    `IMPORT INTO t NDJSON DATA ($1) WITH strict_validation`,
+			args:   []interface{}{users},
+			err:    `document has no value for column score`,
+		},
+		{
+			name:   "strict-errors-on-extra-keys",
+			create: `CREATE TABLE t (id INT PRIMARY KEY, name STRING)`,
+			sql:    `IMPORT INTO t NDJSON DATA ($1) WITH strict_validation`,
+			args:   []interface{}{extra},
+			err:    `could not find column for key extra`,
+		},
+		{
+			name:   "bad-rows",
+			create: `CREATE TABLE t (id INT PRIMARY KEY, name STRING)`,
+			sql:    `IMPORT INTO t NDJSON DATA ($1)`,
+			args:   []interface{}{bad},
+			err:    `error parsing row 2`,
+		},
+		{
+			name:     "bad-rows-rejected",
+			create:   `CREATE TABLE t (id INT PRIMARY KEY, name STRING)`,
+			sql:      `IMPORT INTO t NDJSON DATA ($1) WITH experimental_save_rejected`,
+			args:     []interface{}{bad},
+			expected: [][]string{{"1", "alice"}, {"4", "dan"}},
+		},
+		{
+			name:     "row-limit",
+			create:   `CREATE TABLE t (id INT PRIMARY KEY, name STRING)`,
+			sql:      `IMPORT INTO t NDJSON DATA ($1) WITH row_limit = '2'`,
+			args:     []interface{}{users},
+			expected: [][]string{{"1", "alice"}, {"2", "bob"}},
+		},
+		{
+			name:     "import-table",
+			sql:      `IMPORT TABLE t (id INT PRIMARY KEY, name STRING) NDJSON DATA ($1)`,
+			args:     []interface{}{users},
+			expected: [][]string{{"1", "alice"}, {"2", "bob"}, {"3", "NULL"}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			sqlDB.Exec(t, `DROP TABLE IF EXISTS t`)
+			if test.create != "" {
+				sqlDB.Exec(t, test.create)
+			}
+			if test.err != "" {
+				sqlDB.ExpectErr(t, test.err, test.sql, test.args...)
+				return
+			}
+			sqlDB.Exec(t, test.sql, test.args...)
+			query := `SELECT * FROM t ORDER BY id`
+			if strings.Contains(test.create, "doc JSONB") {
+				query = `SELECT doc FROM t ORDER BY (doc->>'id')::INT`
+			}
+			sqlDB.CheckQueryResults(t, query, test.expected)
+		})
+	}
+}
+
+func TestImportParquet(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	baseDir, cleanup := testutils.TempDir(t)
+	defer cleanup()
+	tc := testcluster.StartTestCluster(
+		t, 1, base.TestClusterArgs{ServerArgs: base.TestServerArgs{ExternalIODir: baseDir}})
+	defer tc.Stopper().Stop(ctx)
+	sqlDB := sqlutils.MakeSQLRunner(tc.Conns[0])
+
+	// Write a file with several row groups, so that they're read concurrently.
+	const numRows = 10
+	sch, err := parquet.NewSchema(
+		[]string{"id", "Name", "score", "ts", "tags"},
+		[]*types.T{types.Int, types.String, types.MakeDecimal(10, 2), types.TimestampTZ, types.StringArray},
+	)
+	require.NoError(t, err)
+	var buf bytes.Buffer
+	w, err := parquet.NewWriter(sch, &buf, parquet.WithMaxRowGroupSize(64))
+	require.NoError(t, err)
+	for i := 1; i <= numRows; i++ {
+		score, err := tree.ParseDDecimal(fmt.Sprintf("%d.25", i))
+		require.NoError(t, err)
+		row := tree.Datums{
+			tree.NewDInt(tree.DInt(i)),
+			tree.NewDString(fmt.Sprintf("user%d", i)),
+			score,
+			tree.MustMakeDTimestampTZ(time.Date(2021, 1, i, 0, 0, 0, 0, time.UTC), time.Microsecond),
+			tree.DNull,
+		}
+		if i == 5 {
+			row[1] = tree.DNull
+		}
+		if i%2 == 0 {
+			tags := tree.NewDArray(types.String)
+			require.NoError(t, tags.Append(tree.NewDString("a")))
+			require.NoError(t, tags.Append(tree.NewDString("b")))
+			row[4] = tags
+		}
+		require.NoError(t, w.AddRow(row))
+	}
+	require.NoError(t, w.Close())
+	r, err := parquet.NewReader(buf.Bytes())
+	require.NoError(t, err)
+	require.Greater(t, r.NumRowGroups(), 1)
+	require.NoError(t, ioutil.WriteFile(filepath.Join(baseDir, "data.parquet"), buf.Bytes(), 0644))
+	data := "nodelocal://0/data.parquet"
+
+	allRows := func(row func(i int, name string) []string) [][]string {
+		var res [][]string
+		for i := 1; i <= numRows; i++ {
+			name := fmt.Sprintf("user%d", i)
+			if i == 5 {
+				name = "NULL"
+			}
+			res = append(res, row(i, name))
+		}
+		return res
+	}
+
+	tests := []struct {
+		name     string
+		create   string
+		sql      string
+		query    string
+		expected [][]string
+		err      string
+	}{
+		{
+			name:   "all-columns",
+			create: `CREATE TABLE t (id INT PRIMARY KEY, name STRING, score DECIMAL(10,2), ts TIMESTAMPTZ, tags STRING[])`,
+			sql:    `IMPORT INTO t PARQUET DATA ($1)`,
+			query:  `SELECT id, name, score, ts::DATE::STRING, tags FROM t ORDER BY id`,
+			expected: allRows(func(i int, name string) []string {
+				tags := "NULL"
+				if i%2 == 0 {
+					tags = "{a,b}"
+				}
+				return []string{
+					fmt.Sprint(i), name, fmt.Sprintf("%d.25", i), fmt.Sprintf("2021-01-%02d", i), tags,
+				}
+			}),
+		},
+		{
+			name:   "type-conversion",
+			create: `CREATE TABLE t (id STRING PRIMARY KEY, score FLOAT, ts DATE)`,
+			sql:    `IMPORT INTO t PARQUET DATA ($1)`,
+			query:  `SELECT id, score, ts::STRING FROM t WHERE id IN ('1', '2') ORDER BY id`,
+			expected: [][]string{
+				{"1", "1.25", "2021-01-01"},
+				{"2", "2.25", "2021-01-02"},
+			},
+		},
+		{
+			name:   "target-columns",
+			create: `CREATE TABLE t (id INT PRIMARY KEY, name STRING, note STRING DEFAULT 'none')`,
+			sql:    `IMPORT INTO t (id, name) PARQUET DATA ($1)`,
+			expected: allRows(func(i int, name string) []string {
+				return []string{fmt.Sprint(i), name, "none"}
+			}),
+		},
+		{
+			name:   "missing-columns-are-null",
+			create: `CREATE TABLE t (id INT PRIMARY KEY, note STRING)`,
+			sql:    `IMPORT INTO t PARQUET DATA ($1)`,
+			expected: allRows(func(i int, _ string) []string {
+				return []string{fmt.Sprint(i), "NULL"}
+			}),
+		},
+		{
+			name:   "strict-errors-on-missing-columns",
+			create: `CREATE TABLE t (id INT PRIMARY KEY, name STRING, score DECIMAL, ts TIMESTAMPTZ, tags STRING[], note STRING)`,
+			sql:    `IMPORT INTO t PARQUET DATA ($1) WITH strict_validation`,
+			err:    `column note was not set in the parquet import`,
+		},
+		{
+			name:   "strict-errors-on-extra-columns",
+			create: `CREATE TABLE t (id INT PRIMARY KEY, name STRING)`,
+			sql:    `IMPORT INTO t PARQUET DATA ($1) WITH strict_validation`,
+			err:    `could not find column for parquet column score`,
+		},
+		{
+			name:   "list-into-scalar",
+			create: `CREATE TABLE t (id INT PRIMARY KEY, tags STRING)`,
+			sql:    `IMPORT INTO t PARQUET DATA ($1)`,
+			err:    `cannot convert parquet LIST column "tags" to STRING`,
+		},
+		{
+			name:     "row-limit",
+			create:   `CREATE TABLE t (id INT PRIMARY KEY, name STRING)`,
+			sql:      `IMPORT INTO t PARQUET DATA ($1) WITH row_limit = '3'`,
+			expected: [][]string{{"1", "user1"}, {"2", "user2"}, {"3", "user3"}},
+		},
+		{
+			name: "import-table",
+			sql:  `IMPORT TABLE t (id INT PRIMARY KEY, name STRING) PARQUET DATA ($1)`,
+			expected: allRows(func(i int, name string) []string {
+				return []string{fmt.Sprint(i), name}
+			}),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			sqlDB.Exec(t, `DROP TABLE IF EXISTS t`)
+			if test.create != "" {
+				sqlDB.Exec(t, test.create)
+			}
+			if test.err != "" {
+				sqlDB.ExpectErr(t, test.err, test.sql, data)
+				return
+			}
+			sqlDB.Exec(t, test.sql, data)
+			query := test.query
+			if query == "" {
+				query = `SELECT * FROM t ORDER BY id`
+			}
+			sqlDB.CheckQueryResults(t, query, test.expected)
+		})
+	}
+}
+
+func TestImportDryRun(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	// The files of an import are spread over the nodes, so duplicate rows in
+	// different files are read on different nodes.
+	const nodes = 3
+	ctx := context.Background()
+	baseDir, cleanup := testutils.TempDir(t)
+	defer cleanup()
+	tc := testcluster.StartTestCluster(
+		t, nodes, base.TestClusterArgs{ServerArgs: base.TestServerArgs{ExternalIODir: baseDir}})
+	defer tc.Stopper().Stop(ctx)
+	sqlDB := sqlutils.MakeSQLRunner(tc.Conns[0])
+
+	writeFile := func(name, data string) string {
+		require.NoError(t, ioutil.WriteFile(filepath.Join(baseDir, name), []byte(data), 0644))
+		return fmt.Sprintf("nodelocal://0/%s", name)
+	}
+	readReport := func(t *testing.T, dir string, name string) [][]string {
+		data, err := ioutil.ReadFile(filepath.Join(baseDir, dir, name))
+		require.NoError(t, err)
+		records, err := csv.NewReader(bytes.NewReader(data)).ReadAll()
+		require.NoError(t, err)
+		require.Equal(t, []string{"file", "row", "error"}, records[0])
+		return records[1:]
+	}
+	importRows := func(t *testing.T, query string, args ...interface{}) int {
+		var unused interface{}
+		var rows int
+		sqlDB.QueryRow(t, query, args...).Scan(&unused, &unused, &unused, &rows, &unused, &unused)
+		return rows
+	}
+
+	first := writeFile("first.csv", "1,a\n2,b\nthree,c\n4,a\n")
+	second := writeFile("second.csv", "5,e\n2,f\n")
+
+	t.Run("new-table", func(t *testing.T) {
+		rows := importRows(t, `IMPORT TABLE t (id INT PRIMARY KEY, s STRING UNIQUE) CSV DATA ($1)
+			WITH dry_run = 'nodelocal://0/new-table'`, first)
+		require.Equal(t, 2, rows)
+
+		report := readReport(t, "new-table", "rejected-0.csv")
+		require.Len(t, report, 1)
+		require.Equal(t, []string{first, "3"}, report[0][:2])
+		require.Contains(t, report[0][2], `could not parse "three" as type int`)
+		require.Equal(t, [][]string{{first, "4",
+			`duplicate key value violates unique constraint "t_s_key": same key as row 1 of ` + first,
+		}}, readReport(t, "new-table", "duplicates.csv"))
+
+		// The table created for the dry run is dropped.
+		sqlDB.ExpectErr(t, `relation "t" does not exist`, `SELECT * FROM t`)
+	})
+
+	t.Run("across-files", func(t *testing.T) {
+		rows := importRows(t, `IMPORT TABLE t (id INT PRIMARY KEY, s STRING) CSV DATA ($1, $2)
+			WITH dry_run = 'nodelocal://0/across-files'`, first, second)
+		// Of the rows with id 2, the one in the second file is rejected.
+		require.Equal(t, 4, rows)
+
+		report := readReport(t, "across-files", "rejected-0.csv")
+		require.Len(t, report, 1)
+		require.Equal(t, []string{first, "3"}, report[0][:2])
+		require.Equal(t, [][]string{{second, "2",
+			`duplicate key value violates unique constraint "primary": same key as row 2 of ` + first,
+		}}, readReport(t, "across-files", "duplicates.csv"))
+		_, err := os.Stat(filepath.Join(baseDir, "across-files", "rejected-1.csv"))
+		require.True(t, os.IsNotExist(err))
+	})
+
+	t.Run("into", func(t *testing.T) {
+		sqlDB.Exec(t, `CREATE TABLE u (id INT PRIMARY KEY, s STRING)`)
+		sqlDB.Exec(t, `INSERT INTO u VALUES (10, 'j')`)
+		rows := importRows(t, `IMPORT INTO u CSV DATA ($1) WITH dry_run = 'nodelocal://0/into'`, second)
+		require.Equal(t, 2, rows)
+
+		// Nothing is imported, and the table stays online.
+		sqlDB.CheckQueryResults(t, `SELECT * FROM u`, [][]string{{"10", "j"}})
+		_, err := os.Stat(filepath.Join(baseDir, "into"))
+		require.True(t, os.IsNotExist(err))
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		sqlDB.ExpectErr(t, "dry_run is not supported for PGDUMP imports",
+			`IMPORT PGDUMP ($1) WITH dry_run = 'nodelocal://0/invalid'`, first)
+		sqlDB.ExpectErr(t, "experimental_save_rejected cannot be used with dry_run",
+			`IMPORT INTO u CSV DATA ($1) WITH dry_run = 'nodelocal://0/invalid', experimental_save_rejected`, first)
+	})
+}
+
 // TestImportClientDisconnect ensures that an import job can complete even if
 // the client connection which started it closes. This test uses a helper
 // subprocess to force a closed client connection without needing to rely
@@ -5688,6 +6118,8 @@ func TestDisallowsInvalidFormatOptions(t *testing.T) {
 	addOpts(mysqlOutAllowedOptions)
 	addOpts(pgDumpAllowedOptions)
 	addOpts(pgCopyAllowedOptions)
+	addOpts(ndjsonAllowedOptions)
+	addOpts(parquetAllowedOptions)
 
 	// Helper to pick num options from the set of allowed and the set
 	// of all other options.  Returns generated options plus a flag indicating
@@ -5734,6 +6166,8 @@ func TestDisallowsInvalidFormatOptions(t *testing.T) {
 		{"mysqldump", mysqlDumpAllowedOptions},
 		{"pgdump", pgDumpAllowedOptions},
 		{"pgcopy", pgCopyAllowedOptions},
+		{"ndjson", ndjsonAllowedOptions},
+		{"parquet", parquetAllowedOptions},
 	}
 
 	for _, tc := range tests {
@@ -14,6 +14,8 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"math/big"
+	"time"
 	"unicode/utf8"
 
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
@@ -26,6 +28,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/types"
 	"github.com/cockroachdb/cockroach/pkg/storage/cloud"
 	"github.com/cockroachdb/cockroach/pkg/util/ctxgroup"
+	"github.com/cockroachdb/cockroach/pkg/util/timeofday"
 	"github.com/linkedin/goavro/v2"
 )
 
@@ -36,7 +39,9 @@ import (
 // data types, this method concerns itself only with the primitive avro types,
 // which include:
 //   null, boolean, int (32), long (64), float (32), double (64),
-//   bytes, string, and arrays of the above.
+//   bytes, string, and arrays of the above,
+// as well as the date, time, timestamp and decimal logical types, which are
+// decoded by goavro as time.Time, time.Duration and *big.Rat values.
 //
 // Avro record is, essentially, a key->value mapping from field name to field value.
 // A field->value mapping may be represented directly (i.e. the
@@ -88,6 +93,28 @@ func nativeToDatum(
 		// We allow strings to be specified for any column, as
 		// long as we can convert the string value to the target type.
 		return rowenc.ParseDatumStringAs(targetT, v, evalCtx)
+	case time.Time:
+		// The date and timestamp logical types.
+		var err error
+		switch targetT.Family() {
+		case types.DateFamily:
+			d, err = tree.NewDDateFromTime(v)
+		case types.TimestampFamily:
+			d, err = tree.MakeDTimestamp(v, time.Microsecond)
+		case types.TimestampTZFamily:
+			d, err = tree.MakeDTimestampTZ(v, time.Microsecond)
+		}
+		if err != nil {
+			return nil, err
+		}
+	case time.Duration:
+		// The time logical types.
+		if targetT.Family() == types.TimeFamily {
+			d = tree.MakeDTime(timeofday.TimeOfDay(v / time.Microsecond))
+		}
+	case *big.Rat:
+		// The decimal logical type.
+		return rowenc.ParseDatumStringAs(targetT, ratToDecimalString(v), evalCtx)
 	case map[string]interface{}:
 		for _, aT := range avroT {
 			// The value passed in is an avro schema.  Extract
@@ -132,6 +159,23 @@ func nativeToDatum(
 	return d, nil
 }
 
+// maxAvroDecimalScale bounds the number of digits after the decimal point with
+// which a decoded avro decimal is formatted.
+const maxAvroDecimalScale = 100
+
+// ratToDecimalString formats the value of an avro decimal, which goavro decodes
+// as a fraction whose denominator divides a power of ten, as an exact decimal.
+func ratToDecimalString(r *big.Rat) string {
+	scale := 0
+	pow, ten := big.NewInt(1), big.NewInt(10)
+	var rem big.Int
+	for scale < maxAvroDecimalScale && rem.Mod(pow, r.Denom()).Sign() != 0 {
+		pow.Mul(pow, ten)
+		scale++
+	}
+	return r.FloatString(scale)
+}
+
 // A mapping from supported types.Family to the list of avro
 // type names that can be used to construct our target type.
 var familyToAvroT = map[types.Family][]string{
@@ -145,18 +189,19 @@ var familyToAvroT = map[types.Family][]string{
 	// Arrays can be specified as avro array type, or we can try parsing string.
 	types.ArrayFamily: {"array", "string"},
 
-	// Families we can try to convert using string conversion.
+	// Families we can try to convert using string conversion, or from the
+	// avro logical types that represent them.
 	types.UuidFamily:           {"string"},
-	types.DateFamily:           {"string"},
-	types.TimeFamily:           {"string"},
+	types.DateFamily:           {"int.date", "string"},
+	types.TimeFamily:           {"long.time-micros", "int.time-millis", "string"},
 	types.IntervalFamily:       {"string"},
-	types.TimestampTZFamily:    {"string"},
-	types.TimestampFamily:      {"string"},
+	types.TimestampTZFamily:    {"long.timestamp-micros", "long.timestamp-millis", "string"},
+	types.TimestampFamily:      {"long.timestamp-micros", "long.timestamp-millis", "string"},
 	types.CollatedStringFamily: {"string"},
 	types.INetFamily:           {"string"},
 	types.JsonFamily:           {"string"},
 	types.BitFamily:            {"string"},
-	types.DecimalFamily:        {"string"},
+	types.DecimalFamily:        {"bytes.decimal", "string"},
 	types.EnumFamily:           {"string"},
 }
 
@@ -479,11 +524,14 @@ func (a *avroInputReader) readFiles(
 	ctx context.Context,
 	dataFiles map[int32]string,
 	resumePos map[int32]int64,
+	resumeOffset map[int32]int64,
 	format roachpb.IOFileFormat,
+	dryRun *dryRun,
 	makeExternalStorage cloud.ExternalStorageFactory,
 	user security.SQLUsername,
 ) error {
-	return readInputFiles(ctx, dataFiles, resumePos, format, a.readFile, makeExternalStorage, user)
+	return readInputFiles(ctx, dataFiles, resumePos, resumeOffset, format, a.readFile,
+		makeExternalStorage, user, dryRun)
 }
 
 func (a *avroInputReader) readFile(
@@ -499,6 +547,7 @@ func (a *avroInputReader) readFile(
 		skip:     resumePos,
 		rejected: rejected,
 		rowLimit: a.opts.RowLimit,
+		dryRun:   input.dryRun,
 	}
 	return runParallelImport(ctx, a.importContext, fileCtx, producer, consumer)
 }
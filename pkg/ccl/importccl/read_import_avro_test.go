@@ -14,9 +14,11 @@ import (
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
+	"math/big"
 	"math/rand"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/cockroachdb/cockroach/pkg/keys"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
@@ -26,6 +28,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/parser"
 	"github.com/cockroachdb/cockroach/pkg/sql/row"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
 	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/linkedin/goavro/v2"
@@ -449,6 +452,54 @@ func TestHandlesArrayData(t *testing.T) {
 	require.EqualValues(t, 10, rowIdx)
 }
 
+func TestHandlesLogicalTypes(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	codec, err := goavro.NewCodec(`{"type": "record", "name": "r", "fields": [
+		{"name": "d", "type": ["null", {"type": "int", "logicalType": "date"}]},
+		{"name": "t", "type": ["null", {"type": "long", "logicalType": "time-micros"}]},
+		{"name": "ts", "type": ["null", {"type": "long", "logicalType": "timestamp-micros"}]},
+		{"name": "dec", "type": ["null", {"type": "bytes", "logicalType": "decimal", "precision": 10, "scale": 3}]}
+	]}`)
+	require.NoError(t, err)
+	ts := time.Date(2021, 1, 2, 3, 4, 5, 678000, time.UTC)
+	rat := big.NewRat(-12345, 1000)
+	buf, err := codec.BinaryFromNative(nil, map[string]interface{}{
+		"d":   goavro.Union("int.date", ts),
+		"t":   goavro.Union("long.time-micros", 90*time.Minute),
+		"ts":  goavro.Union("long.timestamp-micros", ts),
+		"dec": goavro.Union("bytes.decimal", rat),
+	})
+	require.NoError(t, err)
+	native, _, err := codec.NativeFromBinary(buf)
+	require.NoError(t, err)
+	record := native.(map[string]interface{})
+
+	evalCtx := tree.MakeTestingEvalContext(cluster.MakeTestingClusterSettings())
+	for _, tc := range []struct {
+		field    string
+		typ      *types.T
+		expected string
+	}{
+		{"d", types.Date, `'2021-01-02'`},
+		{"t", types.Time, `'01:30:00'`},
+		{"ts", types.Timestamp, `'2021-01-02 03:04:05.000678'`},
+		{"ts", types.TimestampTZ, `'2021-01-02 03:04:05.000678+00:00'`},
+		{"dec", types.Decimal, `-12.345`},
+		{"dec", types.MakeDecimal(10, 3), `-12.345`},
+	} {
+		t.Run(tc.typ.String(), func(t *testing.T) {
+			d, err := nativeToDatum(record[tc.field], tc.typ, familyToAvroT[tc.typ.Family()], &evalCtx)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, d.String())
+		})
+	}
+
+	_, err = nativeToDatum(record["ts"], types.Date, familyToAvroT[types.DateFamily], &evalCtx)
+	require.Error(t, err)
+}
+
 type limitAvroStream struct {
 	avro       *avroInputReader
 	limit      int
@@ -20,7 +20,6 @@ import (
 	"net/url"
 	"runtime"
 	"strings"
-	"sync/atomic"
 	"time"
 
 	"github.com/cockroachdb/cockroach/pkg/kv"
@@ -38,14 +37,18 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/storage/cloudimpl"
 	"github.com/cockroachdb/cockroach/pkg/util/ctxgroup"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
 	"github.com/cockroachdb/cockroach/pkg/util/tracing"
 	"github.com/cockroachdb/errors"
 )
 
+// runImport reads the input files of the spec and ingests the rows in them,
+// or, if dryRun is set, only records their keys in unique indexes.
 func runImport(
 	ctx context.Context,
 	flowCtx *execinfra.FlowCtx,
 	spec *execinfrapb.ReadImportDataSpec,
+	dryRun *dryRun,
 	progCh chan execinfrapb.RemoteProducerMetadata_BulkProcessorProgress,
 ) (*roachpb.BulkOpSummary, error) {
 	// Used to send ingested import rows to the KV layer.
@@ -86,12 +89,18 @@ func runImport(
 		defer close(kvCh)
 		ctx, span := tracing.ChildSpan(ctx, "readImportFiles")
 		defer span.Finish()
+		resumePos, resumeOffset := spec.ResumePos, spec.ResumeOffset
+		if spec.Format.DryRunReport != "" {
+			// A dry run starts over when it is resumed, as it needs to see every
+			// row to find the ones which violate unique constraints.
+			resumePos, resumeOffset = nil, nil
+		}
 		var inputs map[int32]string
-		if spec.ResumePos != nil {
+		if resumePos != nil {
 			// Filter out files that were completely processed.
 			inputs = make(map[int32]string)
 			for id, name := range spec.Uri {
-				if seek, ok := spec.ResumePos[id]; !ok || seek < math.MaxInt64 {
+				if seek, ok := resumePos[id]; !ok || seek < math.MaxInt64 {
 					inputs[id] = name
 				}
 			}
@@ -99,15 +108,19 @@ func runImport(
 			inputs = spec.Uri
 		}
 
-		return conv.readFiles(ctx, inputs, spec.ResumePos, spec.Format, flowCtx.Cfg.ExternalStorage,
-			spec.User())
+		return conv.readFiles(ctx, inputs, resumePos, resumeOffset, spec.Format, dryRun,
+			flowCtx.Cfg.ExternalStorage, spec.User())
 	})
 
 	// Ingest the KVs that the producer group emitted to the chan and the row result
 	// at the end is one row containing an encoded BulkOpSummary.
 	var summary *roachpb.BulkOpSummary
 	group.GoCtx(func(ctx context.Context) error {
-		summary, err = ingestKvs(ctx, flowCtx, spec, progCh, kvCh)
+		if spec.Format.DryRunReport != "" {
+			summary, err = countKvs(ctx, spec, progCh, kvCh)
+		} else {
+			summary, err = ingestKvs(ctx, flowCtx, spec, progCh, kvCh)
+		}
 		if err != nil {
 			return err
 		}
@@ -143,14 +156,24 @@ type readFileFunc func(context.Context, *fileReader, int32, int64, chan string)
 // bytes must be read of the input files, and reports the percent of bytes read
 // among all dataFiles. If any Size() fails for any file, then progress is
 // reported only after each file has been read.
+//
+// When a file is resumed and resumeOffset records where in the decompressed
+// file its next row starts, formats which support it skip directly to that
+// offset, instead of reading and discarding the rows before it one by one.
+//
+// In a dry run, the rows which fail to convert are reported to files under the
+// URI of the report, one per input file, and the keys in unique indexes of the
+// others are recorded in dryRun.
 func readInputFiles(
 	ctx context.Context,
 	dataFiles map[int32]string,
 	resumePos map[int32]int64,
+	resumeOffset map[int32]int64,
 	format roachpb.IOFileFormat,
 	fileFunc readFileFunc,
 	makeExternalStorage cloud.ExternalStorageFactory,
 	user security.SQLUsername,
+	dryRun *dryRun,
 ) error {
 	done := ctx.Done()
 
@@ -198,7 +221,11 @@ func readInputFiles(
 			}
 			defer raw.Close()
 
-			src := &fileReader{total: fileSizes[dataFileIndex], counter: byteCounter{r: raw}}
+			src := &fileReader{
+				total:   fileSizes[dataFileIndex],
+				counter: byteCounter{r: raw},
+				dryRun:  dryRun,
+			}
 			decompressed, err := decompressingReader(&src.counter, dataFile, format.Compression)
 			if err != nil {
 				return err
@@ -206,14 +233,29 @@ func readInputFiles(
 			defer decompressed.Close()
 			src.Reader = decompressed
 
+			if offset := resumeOffset[dataFileIndex]; offset > 0 && resumePos[dataFileIndex] > 0 &&
+				formatHasResumeOffsets(format.Format) {
+				compressed := guessCompressionFromName(dataFile, format.Compression) != roachpb.IOFileFormat_None
+				if err := src.skip(raw, compressed, offset); err != nil {
+					return errors.Wrapf(err, "%s", dataFile)
+				}
+				log.Infof(ctx, "resuming input file %d from row %d at offset %d",
+					dataFileIndex, resumePos[dataFileIndex], offset)
+			}
+
 			var rejected chan string
 			if (format.Format == roachpb.IOFileFormat_CSV && format.SaveRejected) ||
-				(format.Format == roachpb.IOFileFormat_MysqlOutfile && format.SaveRejected) {
+				(format.Format == roachpb.IOFileFormat_MysqlOutfile && format.SaveRejected) ||
+				(format.Format == roachpb.IOFileFormat_NDJSON && format.SaveRejected) ||
+				dryRun != nil {
 				rejected = make(chan string)
 			}
 			if rejected != nil {
 				grp := ctxgroup.WithContext(ctx)
 				grp.GoCtx(func(ctx context.Context) error {
+					if dryRun != nil {
+						return dryRun.writeReport(ctx, dataFileIndex, rejected, makeExternalStorage, user)
+					}
 					var buf []byte
 					var countRejected int64
 					for s := range rejected {
@@ -331,6 +373,31 @@ type fileReader struct {
 	io.Reader
 	total   int64
 	counter byteCounter
+	// offset is the position in the decompressed input from which Reader
+	// reads, which is not 0 if the input was resumed from an offset.
+	offset int64
+	// dryRun is set if the rows of the input are only validated.
+	dryRun *dryRun
+}
+
+// skip advances the reader to offset in the decompressed input. If the input
+// is not compressed and raw supports it, this seeks to offset. Otherwise the
+// input up to offset is read and discarded, which still saves parsing and
+// converting the rows in it.
+func (f *fileReader) skip(raw io.Reader, compressed bool, offset int64) error {
+	if s, ok := raw.(io.Seeker); ok && !compressed {
+		if _, err := s.Seek(offset, io.SeekStart); err != nil {
+			return err
+		}
+		f.counter.n = offset
+	} else if n, err := io.CopyN(ioutil.Discard, f.Reader, offset); err != nil {
+		if errors.Is(err, io.EOF) {
+			return errors.Errorf("resume offset %d is past the end of the input (%d bytes)", offset, n)
+		}
+		return err
+	}
+	f.offset = offset
+	return nil
 }
 
 func (f fileReader) ReadFraction() float32 {
@@ -343,7 +410,8 @@ func (f fileReader) ReadFraction() float32 {
 type inputConverter interface {
 	start(group ctxgroup.Group)
 	readFiles(ctx context.Context, dataFiles map[int32]string, resumePos map[int32]int64,
-		format roachpb.IOFileFormat, makeExternalStorage cloud.ExternalStorageFactory, user security.SQLUsername) error
+		resumeOffset map[int32]int64, format roachpb.IOFileFormat,
+		dryRun *dryRun, makeExternalStorage cloud.ExternalStorageFactory, user security.SQLUsername) error
 }
 
 // formatHasNamedColumns returns true if the data in the input files can be
@@ -351,6 +419,8 @@ type inputConverter interface {
 func formatHasNamedColumns(format roachpb.IOFileFormat_FileFormat) bool {
 	switch format {
 	case roachpb.IOFileFormat_Avro,
+		roachpb.IOFileFormat_NDJSON,
+		roachpb.IOFileFormat_Parquet,
 		roachpb.IOFileFormat_Mysqldump,
 		roachpb.IOFileFormat_PgDump:
 		return true
@@ -358,6 +428,19 @@ func formatHasNamedColumns(format roachpb.IOFileFormat_FileFormat) bool {
 	return false
 }
 
+// formatHasResumeOffsets returns true if the row producer of the format
+// reports the offset of its rows in the input, so that reading the input can
+// be resumed from the offset of a row.
+func formatHasResumeOffsets(format roachpb.IOFileFormat_FileFormat) bool {
+	switch format {
+	case roachpb.IOFileFormat_CSV,
+		roachpb.IOFileFormat_MysqlOutfile,
+		roachpb.IOFileFormat_NDJSON:
+		return true
+	}
+	return false
+}
+
 func isMultiTableFormat(format roachpb.IOFileFormat_FileFormat) bool {
 	switch format {
 	case roachpb.IOFileFormat_Mysqldump,
@@ -420,8 +503,10 @@ type parallelImportContext struct {
 type importFileContext struct {
 	source   int32       // Source is where the row data in the batch came from.
 	skip     int64       // Number of records to skip
+	offset   int64       // Offset of the input, if it starts past the records to skip.
 	rejected chan string // Channel for reporting corrupt "rows"
 	rowLimit int64       // Number of records to process before we stop importing from a file.
+	dryRun   *dryRun     // Set if the rows are only validated, not imported.
 }
 
 // handleCorruptRow reports an error encountered while processing a row
@@ -430,7 +515,11 @@ func handleCorruptRow(ctx context.Context, fileCtx *importFileContext, err error
 	log.Errorf(ctx, "%+v", err)
 
 	if rowErr := (*importRowError)(nil); errors.As(err, &rowErr) && fileCtx.rejected != nil {
-		fileCtx.rejected <- rowErr.row + "\n"
+		if fileCtx.dryRun != nil {
+			fileCtx.rejected <- fileCtx.dryRun.reportLine(fileCtx.source, rowErr.rowNum, rowErr.err.Error())
+		} else {
+			fileCtx.rejected <- rowErr.row + "\n"
+		}
 		return nil
 	}
 
@@ -474,6 +563,17 @@ type importRowProducer interface {
 	Progress() float32
 }
 
+// importRowOffsetProducer is an importRowProducer which also reports where its
+// rows end in the input, so that reading the input can be resumed after any
+// of them.
+type importRowOffsetProducer interface {
+	importRowProducer
+
+	// Offset returns the number of bytes of input consumed by the rows scanned
+	// so far, which is where the row following the current one starts.
+	Offset() int64
+}
+
 // importRowConsumer consumes the data produced by the importRowProducer.
 // Implementations of this interface do not need to be thread safe.
 type importRowConsumer interface {
@@ -483,7 +583,10 @@ type importRowConsumer interface {
 
 // batch represents batch of data to convert.
 type batch struct {
-	data     []interface{}
+	data []interface{}
+	// offsets holds the offset in the input just past each row of data, or
+	// is nil if the producer doesn't report offsets.
+	offsets  []int64
 	startPos int64
 	progress float32
 }
@@ -538,12 +641,15 @@ func runParallelImport(
 		parallelism = runtime.NumCPU()
 	}
 
-	minEmited := make([]int64, parallelism)
+	minEmitted := &emittedRows{
+		rows:    make([]int64, parallelism),
+		offsets: make([]int64, parallelism),
+	}
 	group.GoCtx(func(ctx context.Context) error {
 		ctx, span := tracing.ChildSpan(ctx, "inputconverter")
 		defer span.Finish()
 		return ctxgroup.GroupWorkers(ctx, parallelism, func(ctx context.Context, id int) error {
-			return importer.importWorker(ctx, id, consumer, importCtx, fileCtx, minEmited)
+			return importer.importWorker(ctx, id, consumer, importCtx, fileCtx, minEmitted)
 		})
 	})
 
@@ -552,6 +658,11 @@ func runParallelImport(
 		defer close(importer.recordCh)
 		var numSkipped int64
 		var count int64
+		if fileCtx.offset > 0 {
+			// The input starts right after the records to skip.
+			count, numSkipped = fileCtx.skip, fileCtx.skip
+		}
+		offsetProducer, _ := producer.(importRowOffsetProducer)
 		for producer.Scan() {
 			// Skip rows if needed.
 			count++
@@ -578,7 +689,11 @@ func runParallelImport(
 				continue
 			}
 
-			if err := importer.add(ctx, data, count, producer.Progress); err != nil {
+			var offset int64
+			if offsetProducer != nil {
+				offset = fileCtx.offset + offsetProducer.Offset()
+			}
+			if err := importer.add(ctx, data, count, offset, producer.Progress); err != nil {
 				return err
 			}
 		}
@@ -592,14 +707,18 @@ func runParallelImport(
 	return group.Wait()
 }
 
-// Adds data to the current batch, flushing batches as needed.
+// Adds data to the current batch, flushing batches as needed. offset is the
+// offset in the input just past data, or 0 if it isn't known.
 func (p *parallelImporter) add(
-	ctx context.Context, data interface{}, pos int64, progress func() float32,
+	ctx context.Context, data interface{}, pos int64, offset int64, progress func() float32,
 ) error {
 	if len(p.b.data) == 0 {
 		p.b.startPos = pos
 	}
 	p.b.data = append(p.b.data, data)
+	if offset > 0 {
+		p.b.offsets = append(p.b.offsets, offset)
+	}
 
 	if len(p.b.data) == p.batchSize {
 		p.b.progress = progress()
@@ -641,7 +760,7 @@ func (p *parallelImporter) importWorker(
 	consumer importRowConsumer,
 	importCtx *parallelImportContext,
 	fileCtx *importFileContext,
-	minEmitted []int64,
+	minEmitted *emittedRows,
 ) error {
 	conv, err := makeDatumConverter(ctx, importCtx, fileCtx)
 	if err != nil {
@@ -651,18 +770,29 @@ func (p *parallelImporter) importWorker(
 		panic("uninitialized session data")
 	}
 
-	var rowNum int64
+	var rowNum, rowOffset int64
 	timestamp := timestampAfterEpoch(importCtx.walltime)
 
 	conv.CompletedRowFn = func() int64 {
-		m := emittedRowLowWatermark(workerID, rowNum, minEmitted)
+		m, offset := minEmitted.lowWatermark(workerID, rowNum, rowOffset)
+		conv.KvBatch.LastOffset = offset
 		return m
 	}
+	if fileCtx.dryRun != nil {
+		conv.CheckRowFn = func(kvs []roachpb.KeyValue) error {
+			return fileCtx.dryRun.recordKeys(ctx, importCtx.tableDesc, fileCtx.source, rowNum, kvs)
+		}
+	}
 
 	for batch := range p.recordCh {
 		conv.KvBatch.Progress = batch.progress
+		hasOffsets := len(batch.offsets) == len(batch.data)
 		for batchIdx, record := range batch.data {
 			rowNum = batch.startPos + int64(batchIdx)
+			rowOffset = 0
+			if hasOffsets {
+				rowOffset = batch.offsets[batchIdx]
+			}
 			if err := consumer.FillDatums(record, rowNum, conv); err != nil {
 				if err = handleCorruptRow(ctx, fileCtx, err); err != nil {
 					return err
@@ -672,26 +802,40 @@ func (p *parallelImporter) importWorker(
 
 			rowIndex := int64(timestamp) + rowNum
 			if err := conv.Row(ctx, conv.KvBatch.Source, rowIndex); err != nil {
-				return newImportRowError(err, fmt.Sprintf("%v", record), rowNum)
+				err = newImportRowError(err, fmt.Sprintf("%v", record), rowNum)
+				if fileCtx.dryRun == nil {
+					return err
+				}
+				if err = handleCorruptRow(ctx, fileCtx, err); err != nil {
+					return err
+				}
 			}
 		}
 	}
 	return conv.SendBatch(ctx)
 }
 
-// Updates emitted row for the specified worker and returns
-// low watermark for the emitted rows across all workers.
-func emittedRowLowWatermark(workerID int, emittedRow int64, minEmitted []int64) int64 {
-	atomic.StoreInt64(&minEmitted[workerID], emittedRow)
+// emittedRows tracks the last row for which each worker emitted KVs, along
+// with the offset in the input just past it.
+type emittedRows struct {
+	syncutil.Mutex
+	rows    []int64
+	offsets []int64
+}
 
-	for i := 0; i < len(minEmitted); i++ {
-		if i != workerID {
-			w := atomic.LoadInt64(&minEmitted[i])
-			if w < emittedRow {
-				emittedRow = w
-			}
+// Updates emitted row and offset for the specified worker and returns
+// low watermark for the emitted rows across all workers, along with
+// its offset.
+func (e *emittedRows) lowWatermark(workerID int, emittedRow, offset int64) (int64, int64) {
+	e.Lock()
+	defer e.Unlock()
+	e.rows[workerID], e.offsets[workerID] = emittedRow, offset
+
+	for i, w := range e.rows {
+		if w < emittedRow {
+			emittedRow, offset = w, e.offsets[i]
 		}
 	}
 
-	return emittedRow
+	return emittedRow, offset
 }
@@ -68,11 +68,14 @@ func (c *csvInputReader) readFiles(
 	ctx context.Context,
 	dataFiles map[int32]string,
 	resumePos map[int32]int64,
+	resumeOffset map[int32]int64,
 	format roachpb.IOFileFormat,
+	dryRun *dryRun,
 	makeExternalStorage cloud.ExternalStorageFactory,
 	user security.SQLUsername,
 ) error {
-	return readInputFiles(ctx, dataFiles, resumePos, format, c.readFile, makeExternalStorage, user)
+	return readInputFiles(ctx, dataFiles, resumePos, resumeOffset, format, c.readFile,
+		makeExternalStorage, user, dryRun)
 }
 
 func (c *csvInputReader) readFile(
@@ -87,8 +90,10 @@ func (c *csvInputReader) readFile(
 	fileCtx := &importFileContext{
 		source:   inputIdx,
 		skip:     resumePos,
+		offset:   input.offset,
 		rejected: rejected,
 		rowLimit: c.opts.RowLimit,
+		dryRun:   input.dryRun,
 	}
 
 	return runParallelImport(ctx, c.importCtx, fileCtx, producer, consumer)
@@ -105,7 +110,7 @@ type csvRowProducer struct {
 	numExpectedColumns int
 }
 
-var _ importRowProducer = &csvRowProducer{}
+var _ importRowOffsetProducer = &csvRowProducer{}
 
 // Scan() implements importRowProducer interface.
 func (p *csvRowProducer) Scan() bool {
@@ -163,6 +168,11 @@ func (p *csvRowProducer) Progress() float32 {
 	return p.progress()
 }
 
+// Offset() implements importRowOffsetProducer interface.
+func (p *csvRowProducer) Offset() int64 {
+	return p.csv.InputOffset()
+}
+
 type csvRowConsumer struct {
 	importCtx *parallelImportContext
 	opts      *roachpb.CSVOptions
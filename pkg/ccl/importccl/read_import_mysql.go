@@ -89,11 +89,14 @@ func (m *mysqldumpReader) readFiles(
 	ctx context.Context,
 	dataFiles map[int32]string,
 	resumePos map[int32]int64,
+	resumeOffset map[int32]int64,
 	format roachpb.IOFileFormat,
+	dryRun *dryRun,
 	makeExternalStorage cloud.ExternalStorageFactory,
 	user security.SQLUsername,
 ) error {
-	return readInputFiles(ctx, dataFiles, resumePos, format, m.readFile, makeExternalStorage, user)
+	return readInputFiles(ctx, dataFiles, resumePos, resumeOffset, format, m.readFile,
+		makeExternalStorage, user, dryRun)
 }
 
 func (m *mysqldumpReader) readFile(
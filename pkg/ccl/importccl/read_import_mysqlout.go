@@ -62,11 +62,14 @@ func (d *mysqloutfileReader) readFiles(
 	ctx context.Context,
 	dataFiles map[int32]string,
 	resumePos map[int32]int64,
+	resumeOffset map[int32]int64,
 	format roachpb.IOFileFormat,
+	dryRun *dryRun,
 	makeExternalStorage cloud.ExternalStorageFactory,
 	user security.SQLUsername,
 ) error {
-	return readInputFiles(ctx, dataFiles, resumePos, format, d.readFile, makeExternalStorage, user)
+	return readInputFiles(ctx, dataFiles, resumePos, resumeOffset, format, d.readFile,
+		makeExternalStorage, user, dryRun)
 }
 
 type delimitedProducer struct {
@@ -77,9 +80,11 @@ type delimitedProducer struct {
 	row       []rune
 	err       error
 	eof       bool
+	// offset is the number of bytes of input consumed by Scan.
+	offset int64
 }
 
-var _ importRowProducer = &delimitedProducer{}
+var _ importRowOffsetProducer = &delimitedProducer{}
 
 // Scan implements importRowProducer
 func (d *delimitedProducer) Scan() bool {
@@ -119,8 +124,9 @@ func (d *delimitedProducer) Scan() bool {
 			if d.err != nil {
 				return false
 			}
-			r = rune(raw)
+			r, w = rune(raw), 1
 		}
+		d.offset += int64(w)
 
 		if r == d.opts.RowSeparator && !nextLiteral && !fieldEnclosed {
 			return true
@@ -162,6 +168,11 @@ func (d *delimitedProducer) Progress() float32 {
 	return d.input.ReadFraction()
 }
 
+// Offset implements importRowOffsetProducer
+func (d *delimitedProducer) Offset() int64 {
+	return d.offset
+}
+
 type delimitedConsumer struct {
 	opts *roachpb.MySQLOutfileOptions
 }
@@ -358,8 +369,10 @@ func (d *mysqloutfileReader) readFile(
 	fileCtx := &importFileContext{
 		source:   inputIdx,
 		skip:     resumePos,
+		offset:   input.offset,
 		rejected: rejected,
 		rowLimit: d.opts.RowLimit,
+		dryRun:   input.dryRun,
 	}
 
 	return runParallelImport(ctx, d.importCtx, fileCtx, producer, consumer)
@@ -0,0 +1,435 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package importccl
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/tabledesc"
+	"github.com/cockroachdb/cockroach/pkg/sql/lexbase"
+	"github.com/cockroachdb/cockroach/pkg/sql/row"
+	"github.com/cockroachdb/cockroach/pkg/sql/rowenc"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/storage/cloud"
+	"github.com/cockroachdb/cockroach/pkg/util/ctxgroup"
+	"github.com/cockroachdb/cockroach/pkg/util/json"
+	"github.com/cockroachdb/errors"
+)
+
+type ndjsonInputReader struct {
+	importCtx *parallelImportContext
+	opts      roachpb.NDJSONOptions
+}
+
+var _ inputConverter = &ndjsonInputReader{}
+
+func newNDJSONInputReader(
+	opts roachpb.NDJSONOptions,
+	kvCh chan row.KVBatch,
+	walltime int64,
+	parallelism int,
+	tableDesc *tabledesc.Immutable,
+	targetCols tree.NameList,
+	evalCtx *tree.EvalContext,
+) (*ndjsonInputReader, error) {
+	// Verify that the columns the options refer to exist, so that a typo doesn't
+	// silently import nulls.
+	if opts.DocumentColumn != "" {
+		col, err := tableDesc.FindActiveColumnByName(opts.DocumentColumn)
+		if err != nil {
+			return nil, err
+		}
+		if col.Type.Family() != types.JsonFamily {
+			return nil, errors.Errorf(
+				"document column %q must be of type JSONB, not %s", col.Name, col.Type.SQLString())
+		}
+	}
+	for _, cp := range opts.ColumnPaths {
+		if _, err := tableDesc.FindActiveColumnByName(cp.Column); err != nil {
+			return nil, err
+		}
+	}
+
+	return &ndjsonInputReader{
+		importCtx: &parallelImportContext{
+			walltime:   walltime,
+			numWorkers: parallelism,
+			evalCtx:    evalCtx,
+			tableDesc:  tableDesc,
+			targetCols: targetCols,
+			kvCh:       kvCh,
+		},
+		opts: opts,
+	}, nil
+}
+
+func (n *ndjsonInputReader) start(group ctxgroup.Group) {}
+
+func (n *ndjsonInputReader) readFiles(
+	ctx context.Context,
+	dataFiles map[int32]string,
+	resumePos map[int32]int64,
+	resumeOffset map[int32]int64,
+	format roachpb.IOFileFormat,
+	dryRun *dryRun,
+	makeExternalStorage cloud.ExternalStorageFactory,
+	user security.SQLUsername,
+) error {
+	return readInputFiles(ctx, dataFiles, resumePos, resumeOffset, format, n.readFile,
+		makeExternalStorage, user, dryRun)
+}
+
+func (n *ndjsonInputReader) readFile(
+	ctx context.Context, input *fileReader, inputIdx int32, resumePos int64, rejected chan string,
+) error {
+	producer, consumer := newNDJSONPipeline(n, input)
+
+	fileCtx := &importFileContext{
+		source:   inputIdx,
+		skip:     resumePos,
+		offset:   input.offset,
+		rejected: rejected,
+		rowLimit: n.opts.RowLimit,
+		dryRun:   input.dryRun,
+	}
+	return runParallelImport(ctx, n.importCtx, fileCtx, producer, consumer)
+}
+
+func newNDJSONPipeline(
+	n *ndjsonInputReader, input *fileReader,
+) (*ndjsonRowProducer, *ndjsonRowConsumer) {
+	maxRowSize := int(n.opts.MaxRowSize)
+	if maxRowSize <= 0 {
+		maxRowSize = defaultScanBuffer
+	}
+	scanner := bufio.NewScanner(input)
+	scanner.Buffer(make([]byte, 0, 64<<10), maxRowSize)
+
+	producer := &ndjsonRowProducer{
+		scanner:    scanner,
+		maxRowSize: maxRowSize,
+		progress:   func() float32 { return input.ReadFraction() },
+	}
+	scanner.Split(func(data []byte, atEOF bool) (int, []byte, error) {
+		advance, token, err := bufio.ScanLines(data, atEOF)
+		producer.offset += int64(advance)
+		return advance, token, err
+	})
+
+	var paths map[string][]string
+	if len(n.opts.ColumnPaths) > 0 {
+		paths = make(map[string][]string, len(n.opts.ColumnPaths))
+		for _, cp := range n.opts.ColumnPaths {
+			paths[cp.Column] = cp.Path
+		}
+	}
+	// The datum converter orders the row like the target columns, if any.
+	colIdx := make(map[string]int)
+	if targetCols := n.importCtx.targetCols; len(targetCols) > 0 {
+		for i, name := range targetCols {
+			colIdx[string(name)] = i
+		}
+	} else {
+		for i, col := range n.importCtx.tableDesc.VisibleColumns() {
+			colIdx[col.Name] = i
+		}
+	}
+	consumer := &ndjsonRowConsumer{
+		opts:   &n.opts,
+		colIdx: colIdx,
+		paths:  paths,
+	}
+	return producer, consumer
+}
+
+// ndjsonRowProducer produces the documents of a newline-delimited JSON file,
+// one per line. Blank lines are ignored.
+type ndjsonRowProducer struct {
+	scanner    *bufio.Scanner
+	maxRowSize int
+	line       string
+	err        error
+	progress   func() float32
+	// offset is the number of bytes of input consumed by the scanner.
+	offset int64
+}
+
+var _ importRowOffsetProducer = &ndjsonRowProducer{}
+
+// Scan implements importRowProducer interface.
+func (p *ndjsonRowProducer) Scan() bool {
+	for p.scanner.Scan() {
+		line := bytes.TrimSpace(p.scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		// The scanner's buffer is reused, so copy the line before it's handed
+		// over to the consumer workers.
+		p.line = string(line)
+		return true
+	}
+	p.err = p.scanner.Err()
+	if errors.Is(p.err, bufio.ErrTooLong) {
+		p.err = errors.Wrapf(p.err,
+			"document exceeds the maximum row size of %d bytes; see the %s option", p.maxRowSize, optMaxRowSize)
+	}
+	return false
+}
+
+// Err implements importRowProducer interface.
+func (p *ndjsonRowProducer) Err() error {
+	return p.err
+}
+
+// Skip implements importRowProducer interface.
+func (p *ndjsonRowProducer) Skip() error {
+	return nil
+}
+
+// Row implements importRowProducer interface.
+func (p *ndjsonRowProducer) Row() (interface{}, error) {
+	return p.line, nil
+}
+
+// Progress implements importRowProducer interface.
+func (p *ndjsonRowProducer) Progress() float32 {
+	return p.progress()
+}
+
+// Offset implements importRowOffsetProducer interface.
+func (p *ndjsonRowProducer) Offset() int64 {
+	return p.offset
+}
+
+// ndjsonRowConsumer converts documents to datums, either by loading each
+// document whole into a column, or by extracting the value of each column
+// from a location in the document.
+type ndjsonRowConsumer struct {
+	opts *roachpb.NDJSONOptions
+	// colIdx maps the names of the target columns to their index in the row.
+	colIdx map[string]int
+	// paths maps columns to the path of their value. Columns which aren't in
+	// paths are read from the top-level key of the same name.
+	paths map[string][]string
+}
+
+var _ importRowConsumer = &ndjsonRowConsumer{}
+
+// FillDatums implements importRowConsumer interface.
+func (c *ndjsonRowConsumer) FillDatums(
+	row interface{}, rowNum int64, conv *row.DatumRowConverter,
+) error {
+	line := row.(string)
+	doc, err := json.ParseJSON(line)
+	if err != nil {
+		return newImportRowError(err, line, rowNum)
+	}
+	if err := c.convertDocument(doc, conv); err != nil {
+		return newImportRowError(err, line, rowNum)
+	}
+	return nil
+}
+
+func (c *ndjsonRowConsumer) convertDocument(doc json.JSON, conv *row.DatumRowConverter) error {
+	if c.opts.DocumentColumn != "" {
+		for i, col := range conv.VisibleCols {
+			if col.Name == c.opts.DocumentColumn {
+				conv.Datums[i] = tree.NewDJSON(doc)
+			} else {
+				conv.Datums[i] = tree.DNull
+			}
+		}
+		return nil
+	}
+
+	if len(c.paths) > 0 {
+		for i, col := range conv.VisibleCols {
+			path, ok := c.paths[col.Name]
+			if !ok {
+				path = []string{col.Name}
+			}
+			val, err := json.FetchPath(doc, path)
+			if err != nil {
+				return err
+			}
+			if err := c.setDatum(conv, i, val); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	// Map top-level keys to columns.
+	if doc.Type() != json.ObjectJSONType {
+		return errors.New("expected a JSON object")
+	}
+	for i := range conv.VisibleCols {
+		conv.Datums[i] = nil
+	}
+	iter, err := doc.ObjectIter()
+	if err != nil {
+		return err
+	}
+	for iter.Next() {
+		i, ok := c.colIdx[lexbase.NormalizeName(iter.Key())]
+		if !ok {
+			if c.opts.StrictMode {
+				return errors.Errorf("could not find column for key %s", iter.Key())
+			}
+			continue
+		}
+		if err := c.setDatum(conv, i, iter.Value()); err != nil {
+			return err
+		}
+	}
+	for i := range conv.VisibleCols {
+		if conv.Datums[i] == nil {
+			if err := c.setDatum(conv, i, nil); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// setDatum sets the datum of the i-th column to the given value, which is nil
+// if the document has no value for the column.
+func (c *ndjsonRowConsumer) setDatum(conv *row.DatumRowConverter, i int, val json.JSON) error {
+	col := conv.VisibleCols[i]
+	if val == nil && c.opts.StrictMode {
+		return errors.Errorf("document has no value for column %s", col.Name)
+	}
+	datum, err := jsonToDatum(val, conv.VisibleColTypes[i], conv.EvalCtx)
+	if err != nil {
+		return errors.Wrapf(err, "parse %q as %s", col.Name, col.Type.SQLString())
+	}
+	conv.Datums[i] = datum
+	return nil
+}
+
+// jsonToDatum converts a JSON value to a datum of the target type. JSONB
+// columns hold the value itself, while scalars are parsed from their text as
+// a string column would be.
+func jsonToDatum(j json.JSON, targetT *types.T, evalCtx *tree.EvalContext) (tree.Datum, error) {
+	if j == nil || j.Type() == json.NullJSONType {
+		return tree.DNull, nil
+	}
+	if targetT.Family() == types.JsonFamily {
+		return tree.NewDJSON(j), nil
+	}
+
+	switch j.Type() {
+	case json.StringJSONType:
+		s, err := j.AsText()
+		if err != nil {
+			return nil, err
+		}
+		return rowenc.ParseDatumStringAs(targetT, *s, evalCtx)
+	case json.ArrayJSONType:
+		if targetT.Family() != types.ArrayFamily {
+			return nil, errors.Errorf("cannot convert JSON array to %s", targetT)
+		}
+		arr := tree.NewDArray(targetT.ArrayContents())
+		for i := 0; i < j.Len(); i++ {
+			elt, err := j.FetchValIdx(i)
+			if err != nil {
+				return nil, err
+			}
+			eltDatum, err := jsonToDatum(elt, targetT.ArrayContents(), evalCtx)
+			if err != nil {
+				return nil, err
+			}
+			if err := arr.Append(eltDatum); err != nil {
+				return nil, err
+			}
+		}
+		return arr, nil
+	case json.ObjectJSONType:
+		return nil, errors.Errorf("cannot convert JSON object to %s", targetT)
+	default:
+		// Numbers and booleans.
+		return rowenc.ParseDatumStringAs(targetT, j.String(), evalCtx)
+	}
+}
+
+// parseNDJSONColumnPaths parses a comma separated list of column=path pairs,
+// where each path is in the syntax accepted by parseNDJSONPath.
+func parseNDJSONColumnPaths(s string) ([]roachpb.NDJSONOptions_ColumnPath, error) {
+	var res []roachpb.NDJSONOptions_ColumnPath
+	seen := make(map[string]struct{})
+	for _, pair := range strings.Split(s, ",") {
+		eq := strings.IndexByte(pair, '=')
+		if eq < 0 {
+			return nil, errors.Errorf("expected column=path, found %q", strings.TrimSpace(pair))
+		}
+		col := strings.TrimSpace(pair[:eq])
+		if col == "" {
+			return nil, errors.Errorf("missing column name in %q", strings.TrimSpace(pair))
+		}
+		if _, ok := seen[col]; ok {
+			return nil, errors.Errorf("column %q is mapped more than once", col)
+		}
+		seen[col] = struct{}{}
+		path, err := parseNDJSONPath(pair[eq+1:])
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid path for column %q", col)
+		}
+		res = append(res, roachpb.NDJSONOptions_ColumnPath{Column: col, Path: path})
+	}
+	return res, nil
+}
+
+// parseNDJSONPath parses a path into the value of a document, such as
+// $.user.emails[0], into the list of keys and indexes which make it up. The
+// leading $ is optional, and $ alone denotes the whole document.
+func parseNDJSONPath(s string) ([]string, error) {
+	s = strings.TrimSpace(s)
+	if strings.HasPrefix(s, "$") {
+		s = s[1:]
+	} else {
+		s = "." + s
+	}
+	var path []string
+	for len(s) > 0 {
+		switch s[0] {
+		case '.':
+			s = s[1:]
+			end := strings.IndexAny(s, ".[")
+			if end < 0 {
+				end = len(s)
+			}
+			if end == 0 {
+				return nil, errors.New("empty key")
+			}
+			path = append(path, s[:end])
+			s = s[end:]
+		case '[':
+			end := strings.IndexByte(s, ']')
+			if end < 0 {
+				return nil, errors.New("unterminated array index")
+			}
+			idx := s[1:end]
+			if _, err := strconv.Atoi(idx); err != nil {
+				return nil, errors.Errorf("invalid array index %q", idx)
+			}
+			path = append(path, idx)
+			s = s[end+1:]
+		default:
+			return nil, errors.Errorf("unexpected character %q", s[0])
+		}
+	}
+	return path, nil
+}
@@ -0,0 +1,62 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package importccl
+
+import (
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseNDJSONColumnPaths(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	for _, tc := range []struct {
+		in       string
+		expected []roachpb.NDJSONOptions_ColumnPath
+		err      string
+	}{
+		{
+			in: "a=$.x",
+			expected: []roachpb.NDJSONOptions_ColumnPath{
+				{Column: "a", Path: []string{"x"}},
+			},
+		},
+		{
+			in: " a = x.y[2].z , b=$ ,c=$[0]",
+			expected: []roachpb.NDJSONOptions_ColumnPath{
+				{Column: "a", Path: []string{"x", "y", "2", "z"}},
+				{Column: "b"},
+				{Column: "c", Path: []string{"0"}},
+			},
+		},
+		{in: "a", err: `expected column=path, found "a"`},
+		{in: "=$.x", err: `missing column name`},
+		{in: "a=$.x,a=$.y", err: `column "a" is mapped more than once`},
+		{in: "a=$.", err: `invalid path for column "a": empty key`},
+		{in: "a=$.x[1", err: `unterminated array index`},
+		{in: "a=$.x[one]", err: `invalid array index "one"`},
+		{in: "a=$x", err: `unexpected character 'x'`},
+	} {
+		t.Run(tc.in, func(t *testing.T) {
+			paths, err := parseNDJSONColumnPaths(tc.in)
+			if tc.err != "" {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tc.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, paths)
+		})
+	}
+}
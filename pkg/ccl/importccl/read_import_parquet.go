@@ -0,0 +1,398 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package importccl
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/tabledesc"
+	"github.com/cockroachdb/cockroach/pkg/sql/lexbase"
+	"github.com/cockroachdb/cockroach/pkg/sql/row"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/storage/cloud"
+	"github.com/cockroachdb/cockroach/pkg/util/ctxgroup"
+	"github.com/cockroachdb/cockroach/pkg/util/mon"
+	"github.com/cockroachdb/cockroach/pkg/util/parquet"
+	"github.com/cockroachdb/errors"
+)
+
+type parquetInputReader struct {
+	importCtx *parallelImportContext
+	opts      roachpb.ParquetOptions
+}
+
+var _ inputConverter = &parquetInputReader{}
+
+func newParquetInputReader(
+	opts roachpb.ParquetOptions,
+	kvCh chan row.KVBatch,
+	walltime int64,
+	parallelism int,
+	tableDesc *tabledesc.Immutable,
+	targetCols tree.NameList,
+	evalCtx *tree.EvalContext,
+) (*parquetInputReader, error) {
+	return &parquetInputReader{
+		importCtx: &parallelImportContext{
+			walltime:   walltime,
+			numWorkers: parallelism,
+			evalCtx:    evalCtx,
+			tableDesc:  tableDesc,
+			targetCols: targetCols,
+			kvCh:       kvCh,
+		},
+		opts: opts,
+	}, nil
+}
+
+func (p *parquetInputReader) start(group ctxgroup.Group) {}
+
+func (p *parquetInputReader) readFiles(
+	ctx context.Context,
+	dataFiles map[int32]string,
+	resumePos map[int32]int64,
+	resumeOffset map[int32]int64,
+	format roachpb.IOFileFormat,
+	dryRun *dryRun,
+	makeExternalStorage cloud.ExternalStorageFactory,
+	user security.SQLUsername,
+) error {
+	return readInputFiles(ctx, dataFiles, resumePos, resumeOffset, format, p.readFile,
+		makeExternalStorage, user, dryRun)
+}
+
+// readFile imports a parquet file. The metadata which describes the layout of
+// a parquet file is at its end, and external storage can only be read
+// sequentially, so the file is loaded into memory whole, accounted against the
+// memory monitor of the flow. Its row groups are then read concurrently, while
+// the rows are handed to the consumer workers in the order in which they
+// appear in the file.
+func (p *parquetInputReader) readFile(
+	ctx context.Context, input *fileReader, inputIdx int32, resumePos int64, rejected chan string,
+) error {
+	acc := p.importCtx.evalCtx.Mon.MakeBoundAccount()
+	defer acc.Close(ctx)
+	data, err := readAllAccounted(ctx, input, &acc)
+	if err != nil {
+		return err
+	}
+	reader, err := parquet.NewReader(data)
+	if err != nil {
+		return err
+	}
+	consumer, columns, err := newParquetRowConsumer(reader, p.importCtx, p.opts.StrictMode)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	producer := newParquetRowProducer(ctx, reader, columns, resumePos, p.importCtx.numWorkers)
+	fileCtx := &importFileContext{
+		source:   inputIdx,
+		skip:     resumePos,
+		rejected: rejected,
+		rowLimit: p.opts.RowLimit,
+		dryRun:   input.dryRun,
+	}
+
+	group := ctxgroup.WithContext(ctx)
+	group.GoCtx(producer.readRowGroups)
+	group.GoCtx(func(ctx context.Context) error {
+		// Stop reading row groups once the import of the file is over, which
+		// may be before all of them are consumed if there is a row limit.
+		defer cancel()
+		return runParallelImport(ctx, p.importCtx, fileCtx, producer, consumer)
+	})
+	return group.Wait()
+}
+
+// readAllAccounted reads r until EOF, reserving the memory of the returned
+// buffer in acc as it grows.
+func readAllAccounted(ctx context.Context, r io.Reader, acc *mon.BoundAccount) ([]byte, error) {
+	const minReadSize = 64 << 10
+	var buf []byte
+	for {
+		if cap(buf)-len(buf) < minReadSize {
+			newCap := 2*cap(buf) + minReadSize
+			if err := acc.Grow(ctx, int64(newCap-cap(buf))); err != nil {
+				return nil, errors.Wrap(err, "reading parquet file")
+			}
+			newBuf := make([]byte, len(buf), newCap)
+			copy(newBuf, buf)
+			buf = newBuf
+		}
+		n, err := r.Read(buf[len(buf):cap(buf)])
+		buf = buf[:len(buf)+n]
+		if err == io.EOF {
+			return buf, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// parquetRowGroup holds the rows of a row group, or the error encountered
+// reading it.
+type parquetRowGroup struct {
+	rows []parquet.Row
+	err  error
+}
+
+// parquetRowProducer produces the rows of a parquet file. Row groups are read
+// ahead of the one being produced by readRowGroups, with at most one group
+// per worker held in memory at a time.
+type parquetRowProducer struct {
+	ctx     context.Context
+	reader  *parquet.Reader
+	columns []string
+	// firstGroup is the first row group which contains rows that are not
+	// skipped; the ones before it are not read at all.
+	firstGroup int
+	// tokens bounds the number of row groups read ahead. A token is acquired
+	// before reading a group and released once all its rows are produced.
+	tokens chan struct{}
+	// groups receives the contents of each row group once it has been read.
+	groups []chan parquetRowGroup
+
+	group    int
+	rows     []parquet.Row
+	numRows  int64
+	pos      int64
+	scanned  int64
+	finished bool
+	err      error
+}
+
+var _ importRowProducer = &parquetRowProducer{}
+
+func newParquetRowProducer(
+	ctx context.Context, reader *parquet.Reader, columns []string, skip int64, parallelism int,
+) *parquetRowProducer {
+	if parallelism <= 0 {
+		parallelism = 1
+	}
+	groups := make([]chan parquetRowGroup, reader.NumRowGroups())
+	for i := range groups {
+		groups[i] = make(chan parquetRowGroup, 1)
+	}
+	firstGroup := 0
+	for ; firstGroup < len(groups); firstGroup++ {
+		n := reader.RowGroupNumRows(firstGroup)
+		if n > skip {
+			break
+		}
+		skip -= n
+	}
+	return &parquetRowProducer{
+		ctx:        ctx,
+		reader:     reader,
+		columns:    columns,
+		firstGroup: firstGroup,
+		tokens:     make(chan struct{}, parallelism),
+		groups:     groups,
+		group:      -1,
+	}
+}
+
+// readRowGroups reads the row groups from firstGroup onwards, using one worker
+// per token, until all of them are read or ctx is canceled.
+func (p *parquetRowProducer) readRowGroups(ctx context.Context) error {
+	todo := make(chan int)
+	group := ctxgroup.WithContext(ctx)
+	group.GoCtx(func(ctx context.Context) error {
+		defer close(todo)
+		for i := p.firstGroup; i < len(p.groups); i++ {
+			select {
+			case p.tokens <- struct{}{}:
+			case <-ctx.Done():
+				return nil
+			}
+			select {
+			case todo <- i:
+			case <-ctx.Done():
+				return nil
+			}
+		}
+		return nil
+	})
+	group.GoCtx(func(ctx context.Context) error {
+		return ctxgroup.GroupWorkers(ctx, cap(p.tokens), func(ctx context.Context, _ int) error {
+			for i := range todo {
+				// Errors are reported to the producer, which stops the import.
+				rows, err := p.reader.ReadRowGroup(i, p.columns...)
+				p.groups[i] <- parquetRowGroup{rows: rows, err: err}
+			}
+			return nil
+		})
+	})
+	return group.Wait()
+}
+
+// Scan implements importRowProducer interface.
+func (p *parquetRowProducer) Scan() bool {
+	for p.pos == p.numRows {
+		if p.finished {
+			return false
+		}
+		if p.group >= p.firstGroup {
+			// Let the next row group be read.
+			<-p.tokens
+		}
+		p.group++
+		p.rows, p.pos, p.numRows = nil, 0, 0
+		if p.group == len(p.groups) {
+			p.finished = true
+			return false
+		}
+		if p.group < p.firstGroup {
+			// All the rows of this group are skipped, so it isn't read.
+			p.numRows = p.reader.RowGroupNumRows(p.group)
+			continue
+		}
+		select {
+		case g := <-p.groups[p.group]:
+			if g.err != nil {
+				p.err = g.err
+				p.finished = true
+				return false
+			}
+			p.rows, p.numRows = g.rows, int64(len(g.rows))
+		case <-p.ctx.Done():
+			p.err = p.ctx.Err()
+			p.finished = true
+			return false
+		}
+	}
+	p.pos++
+	p.scanned++
+	return true
+}
+
+// Err implements importRowProducer interface.
+func (p *parquetRowProducer) Err() error {
+	return p.err
+}
+
+// Skip implements importRowProducer interface.
+func (p *parquetRowProducer) Skip() error {
+	return nil
+}
+
+// Row implements importRowProducer interface.
+func (p *parquetRowProducer) Row() (interface{}, error) {
+	return p.rows[p.pos-1], nil
+}
+
+// Progress implements importRowProducer interface.
+func (p *parquetRowProducer) Progress() float32 {
+	if total := p.reader.NumRows(); total > 0 {
+		return float32(p.scanned) / float32(total)
+	}
+	return 1
+}
+
+// parquetRowConsumer converts the rows of a parquet file to datums. Columns
+// of the file are mapped to the target columns by name.
+type parquetRowConsumer struct {
+	// decoders holds the decoder for each target column, in the order of the
+	// row, or nil for columns which are absent from the file.
+	decoders []*parquet.Decoder
+}
+
+var _ importRowConsumer = &parquetRowConsumer{}
+
+// newParquetRowConsumer returns a consumer for the rows of the file, as well
+// as the names of the columns of the file which it needs to be read.
+func newParquetRowConsumer(
+	reader *parquet.Reader, importCtx *parallelImportContext, strict bool,
+) (*parquetRowConsumer, []string, error) {
+	// The datum converter orders the row like the target columns, if any.
+	var names []string
+	var colTypes []*types.T
+	if targetCols := importCtx.targetCols; len(targetCols) > 0 {
+		for _, name := range targetCols {
+			col, err := importCtx.tableDesc.FindActiveColumnByName(string(name))
+			if err != nil {
+				return nil, nil, err
+			}
+			names = append(names, col.Name)
+			colTypes = append(colTypes, col.Type)
+		}
+	} else {
+		for _, col := range importCtx.tableDesc.VisibleColumns() {
+			names = append(names, col.Name)
+			colTypes = append(colTypes, col.Type)
+		}
+	}
+	colIdx := make(map[string]int, len(names))
+	for i, name := range names {
+		colIdx[name] = i
+	}
+
+	decoders := make([]*parquet.Decoder, len(names))
+	fileCols := make([]string, len(names))
+	var columns []string
+	for _, fileCol := range reader.ColumnNames() {
+		i, ok := colIdx[lexbase.NormalizeName(fileCol)]
+		if !ok {
+			if strict {
+				return nil, nil, errors.Errorf("could not find column for parquet column %s", fileCol)
+			}
+			continue
+		}
+		if fileCols[i] != "" {
+			return nil, nil, errors.Errorf(
+				"parquet columns %s and %s both map to column %s", fileCols[i], fileCol, names[i])
+		}
+		dec, err := reader.NewDecoder(fileCol, colTypes[i])
+		if err != nil {
+			return nil, nil, err
+		}
+		decoders[i] = dec
+		fileCols[i] = fileCol
+		columns = append(columns, fileCol)
+	}
+	if strict {
+		for i, dec := range decoders {
+			if dec == nil {
+				return nil, nil, errors.Errorf("column %s was not set in the parquet import", names[i])
+			}
+		}
+	}
+	if len(columns) == 0 {
+		return nil, nil, errors.New("none of the columns of the parquet file map to a column of the table")
+	}
+	return &parquetRowConsumer{decoders: decoders}, columns, nil
+}
+
+// FillDatums implements importRowConsumer interface.
+func (c *parquetRowConsumer) FillDatums(
+	row interface{}, rowNum int64, conv *row.DatumRowConverter,
+) error {
+	r := row.(parquet.Row)
+	for i, dec := range c.decoders {
+		if dec == nil {
+			conv.Datums[i] = tree.DNull
+			continue
+		}
+		datum, err := dec.Decode(r, conv.EvalCtx)
+		if err != nil {
+			return newImportRowError(err, fmt.Sprintf("%v", r), rowNum)
+		}
+		conv.Datums[i] = datum
+	}
+	return nil
+}
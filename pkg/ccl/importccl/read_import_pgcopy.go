@@ -68,11 +68,14 @@ func (d *pgCopyReader) readFiles(
 	ctx context.Context,
 	dataFiles map[int32]string,
 	resumePos map[int32]int64,
+	resumeOffset map[int32]int64,
 	format roachpb.IOFileFormat,
+	dryRun *dryRun,
 	makeExternalStorage cloud.ExternalStorageFactory,
 	user security.SQLUsername,
 ) error {
-	return readInputFiles(ctx, dataFiles, resumePos, format, d.readFile, makeExternalStorage, user)
+	return readInputFiles(ctx, dataFiles, resumePos, resumeOffset, format, d.readFile,
+		makeExternalStorage, user, dryRun)
 }
 
 type postgreStreamCopy struct {
@@ -367,6 +370,7 @@ func (d *pgCopyReader) readFile(
 		source:   inputIdx,
 		skip:     resumePos,
 		rejected: rejected,
+		dryRun:   input.dryRun,
 	}
 
 	return runParallelImport(ctx, d.importCtx, fileCtx, producer, consumer)
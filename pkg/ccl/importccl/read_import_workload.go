@@ -117,7 +117,9 @@ func (w *workloadReader) readFiles(
 	ctx context.Context,
 	dataFiles map[int32]string,
 	_ map[int32]int64,
+	_ map[int32]int64,
 	_ roachpb.IOFileFormat,
+	_ *dryRun,
 	_ cloud.ExternalStorageFactory,
 	_ security.SQLUsername,
 ) error {
@@ -196,6 +196,7 @@ func evalExport(
 			Exported:   summary,
 			Sha512:     checksum,
 			LocalityKV: localityKV,
+			FileSize:   int64(len(data)),
 		}
 
 		if exportStore != nil {
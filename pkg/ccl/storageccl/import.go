@@ -22,6 +22,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/settings"
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/storage"
+	"github.com/cockroachdb/cockroach/pkg/storage/cloud"
 	"github.com/cockroachdb/cockroach/pkg/util/humanizeutil"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/cockroach/pkg/util/retry"
@@ -157,37 +158,9 @@ func evalImport(ctx context.Context, cArgs batcheval.CommandArgs) (*roachpb.Impo
 			}
 		}()
 
-		const maxAttempts = 3
-		var fileContents []byte
-		if err := retry.WithMaxAttempts(ctx, base.DefaultRetryOptions(), maxAttempts, func() error {
-			f, err := dir.ReadFile(ctx, file.Path)
-			if err != nil {
-				return err
-			}
-			defer f.Close()
-			fileContents, err = ioutil.ReadAll(f)
-			return err
-		}); err != nil {
-			return nil, errors.Wrapf(err, "fetching %q", file.Path)
-		}
-		dataSize := int64(len(fileContents))
-		log.Eventf(ctx, "fetched file (%s)", humanizeutil.IBytes(dataSize))
-
-		if args.Encryption != nil {
-			fileContents, err = DecryptFile(fileContents, args.Encryption.Key)
-			if err != nil {
-				return nil, err
-			}
-		}
-
-		if len(file.Sha512) > 0 {
-			checksum, err := SHA512ChecksumData(fileContents)
-			if err != nil {
-				return nil, err
-			}
-			if !bytes.Equal(checksum, file.Sha512) {
-				return nil, errors.Errorf("checksum mismatch for %s", file.Path)
-			}
+		fileContents, err := ReadImportFile(ctx, dir, file, args.Encryption)
+		if err != nil {
+			return nil, err
 		}
 
 		iter, err := storage.NewMemSSTIterator(fileContents, false)
@@ -274,3 +247,48 @@ func evalImport(ctx context.Context, cArgs batcheval.CommandArgs) (*roachpb.Impo
 	log.Event(ctx, "done")
 	return &roachpb.ImportResponse{Imported: batcher.GetSummary()}, nil
 }
+
+// ReadImportFile fetches the given backup file from external storage,
+// decrypting it with the given options, if any, and checking it against its
+// checksum. It returns the contents of the file, which is an SST.
+func ReadImportFile(
+	ctx context.Context,
+	dir cloud.ExternalStorage,
+	file roachpb.ImportRequest_File,
+	encryption *roachpb.FileEncryptionOptions,
+) ([]byte, error) {
+	const maxAttempts = 3
+	var fileContents []byte
+	if err := retry.WithMaxAttempts(ctx, base.DefaultRetryOptions(), maxAttempts, func() error {
+		f, err := dir.ReadFile(ctx, file.Path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		fileContents, err = ioutil.ReadAll(f)
+		return err
+	}); err != nil {
+		return nil, errors.Wrapf(err, "fetching %q", file.Path)
+	}
+	dataSize := int64(len(fileContents))
+	log.Eventf(ctx, "fetched file (%s)", humanizeutil.IBytes(dataSize))
+
+	if encryption != nil {
+		var err error
+		fileContents, err = DecryptFile(fileContents, encryption.Key)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if len(file.Sha512) > 0 {
+		checksum, err := SHA512ChecksumData(fileContents)
+		if err != nil {
+			return nil, err
+		}
+		if !bytes.Equal(checksum, file.Sha512) {
+			return nil, errors.Errorf("checksum mismatch for %s", file.Path)
+		}
+	}
+	return fileContents, nil
+}
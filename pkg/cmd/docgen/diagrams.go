@@ -336,6 +336,13 @@ var specs = []stmtSpec{
 		replace: map[string]string{"relation_expr": "table_name", "alter_table_cmds": "'ADD' 'CONSTRAINT' constraint_name constraint_elem opt_validate_behavior"},
 		unlink:  []string{"table_name"},
 	},
+	{
+		name:    "alter_changefeed_stmt",
+		inline:  []string{"alter_changefeed_cmds", "alter_changefeed_cmd", "opt_table"},
+		replace: map[string]string{"a_expr": "job_id"},
+		unlink:  []string{"job_id"},
+		nosplit: true,
+	},
 	{
 		name:   "alter_column",
 		stmt:   "alter_onetable_stmt",
@@ -489,6 +496,10 @@ var specs = []stmtSpec{
 		replace: map[string]string{"	stmt": "	'CREATE' 'TABLE' table_name '(' ( column_def ( ',' column_def )* ) ( 'CONSTRAINT' constraint_name | ) 'CHECK' '(' check_expr ')' ( table_constraints | ) ')'"},
 		unlink: []string{"table_name", "check_expr", "table_constraints"},
 	},
+	{
+		name: "close_cursor",
+		stmt: "close_cursor_stmt",
+	},
 	{
 		name:   "column_def",
 		stmt:   "column_def",
@@ -526,6 +537,17 @@ var specs = []stmtSpec{
 		replace: map[string]string{"a_expr": "job_id"},
 		unlink:  []string{"job_id"},
 	},
+	{
+		name:   "compact_backup",
+		stmt:   "compact_backup_stmt",
+		inline: []string{"opt_with_options"},
+		replace: map[string]string{
+			"list_of_string_or_placeholder_opt_list": "location ( | '(' location ( ',' location )* ')' )",
+			"'FROM' string_or_placeholder 'IN'":      "'FROM' subdirectory 'IN'",
+			"'TO' string_or_placeholder":             "'TO' destination",
+		},
+		unlink: []string{"location", "subdirectory", "destination"},
+	},
 	{
 		name:   "create_as_col_qual_list",
 		inline: []string{"create_as_col_qualification", "create_as_col_qualification_elem"},
@@ -561,9 +583,19 @@ var specs = []stmtSpec{
 			"name":                         "option",
 			"'SCONST'":                     "option",
 			"'=' string_or_placeholder":    "'=' value"},
+		regreplace: map[string]string{
+			"database_option": "database_name"},
 		exclude: []*regexp.Regexp{
 			regexp.MustCompile("'OPTIONS'")},
-		unlink: []string{"table_name", "sink", "option", "value"},
+		unlink: []string{"table_name", "database_name", "sink", "option", "value"},
+	},
+	{
+		name:    "create_function",
+		stmt:    "create_func_stmt",
+		inline:  []string{"opt_func_arg_list", "func_arg_list", "func_arg", "opt_setof", "func_option_list", "func_option"},
+		replace: map[string]string{"db_object_name": "function_name", "type_function_name": "argument_name", "'SCONST'": "function_body"},
+		unlink:  []string{"function_name", "argument_name", "function_body"},
+		nosplit: true,
 	},
 	{
 		name:   "create_index_stmt",
@@ -639,6 +671,14 @@ var specs = []stmtSpec{
 		inline:  []string{"opt_table_elem_list", "table_elem_list", "table_elem", "opt_table_with", "opt_create_table_on_commit"},
 		nosplit: true,
 	},
+	{
+		name:    "create_trigger",
+		stmt:    "create_trigger_stmt",
+		inline:  []string{"trigger_action_time", "trigger_event_list", "trigger_event", "opt_each", "trigger_func_kind", "opt_expr_list", "expr_list"},
+		replace: map[string]string{"'CREATE' 'TRIGGER' name": "'CREATE' 'TRIGGER' trigger_name", "func_name": "function_name", "a_expr": "argument"},
+		unlink:  []string{"trigger_name", "function_name", "argument"},
+		nosplit: true,
+	},
 	{
 		name: "create_type",
 		stmt: "create_type_stmt",
@@ -656,6 +696,11 @@ var specs = []stmtSpec{
 			"string_or_placeholder  'PASSWORD'": "name 'PASSWORD'",
 			"'PASSWORD' string_or_placeholder":  "'PASSWORD' password"},
 	},
+	{
+		name:   "declare_cursor",
+		stmt:   "declare_cursor_stmt",
+		inline: []string{"opt_scroll", "opt_hold"},
+	},
 	{
 		name: "default_value_column_level",
 		stmt: "stmt_block",
@@ -715,6 +760,14 @@ var specs = []stmtSpec{
 		inline: []string{"opt_drop_behavior"},
 		match:  []*regexp.Regexp{regexp.MustCompile("'DROP' 'DATABASE'")},
 	},
+	{
+		name:    "drop_function",
+		stmt:    "drop_func_stmt",
+		inline:  []string{"func_obj_list", "func_obj", "opt_func_arg_list", "func_arg_list", "func_arg"},
+		replace: map[string]string{"db_object_name": "function_name", "type_function_name": "argument_name"},
+		unlink:  []string{"function_name", "argument_name"},
+		nosplit: true,
+	},
 	{
 		name:   "drop_index",
 		stmt:   "drop_index_stmt",
@@ -745,6 +798,13 @@ var specs = []stmtSpec{
 		inline: []string{"opt_drop_behavior", "table_name_list"},
 		match:  []*regexp.Regexp{regexp.MustCompile("'DROP' 'TABLE'")},
 	},
+	{
+		name:    "drop_trigger",
+		stmt:    "drop_trigger_stmt",
+		inline:  []string{"opt_drop_behavior"},
+		replace: map[string]string{"name 'ON'": "trigger_name 'ON'"},
+		unlink:  []string{"trigger_name"},
+	},
 	{
 		name:    "drop_type",
 		stmt:    "drop_type_stmt",
@@ -824,6 +884,13 @@ var specs = []stmtSpec{
 		},
 		unlink: []string{"CSV", "file_location"},
 	},
+	{
+		name:    "fetch_cursor",
+		stmt:    "fetch_cursor_stmt",
+		inline:  []string{"cursor_movement_specifier", "opt_from_or_in", "from_or_in"},
+		replace: map[string]string{"signed_iconst64": "count"},
+		unlink:  []string{"count"},
+	},
 	{
 		name:   "family_def",
 		inline: []string{"name_list"},
@@ -952,6 +1019,13 @@ var specs = []stmtSpec{
 		replace: map[string]string{"	stmt": "	'CREATE' 'TABLE' table_name '(' column_name column_type 'NOT NULL' ( column_constraints | ) ( ',' ( column_def ( ',' column_def )* ) | ) ( table_constraints | ) ')' ')'"},
 		unlink: []string{"table_name", "column_name", "column_type", "table_constraints"},
 	},
+	{
+		name:    "move_cursor",
+		stmt:    "move_cursor_stmt",
+		inline:  []string{"cursor_movement_specifier", "opt_from_or_in", "from_or_in"},
+		replace: map[string]string{"signed_iconst64": "count"},
+		unlink:  []string{"count"},
+	},
 	{
 		name: "opt_interleave",
 	},
@@ -1191,6 +1265,13 @@ var specs = []stmtSpec{
 			regexp.MustCompile("'SET' 'CLUSTER'"),
 		},
 	},
+	{
+		name:    "set_constraints",
+		stmt:    "set_constraints_stmt",
+		inline:  []string{"name_list"},
+		replace: map[string]string{"name": "constraint_name"},
+		unlink:  []string{"constraint_name"},
+	},
 	{
 		name: "set_transaction",
 		stmt: "nonpreparable_set_stmt",
@@ -1475,6 +1556,16 @@ var specs = []stmtSpec{
 		replace: map[string]string{"alter_table_cmds": "'VALIDATE' 'CONSTRAINT' constraint_name", "relation_expr": "table_name"},
 		unlink:  []string{"constraint_name", "table_name"},
 	},
+	{
+		name:   "verify_backup",
+		stmt:   "verify_backup_stmt",
+		inline: []string{"opt_with_options"},
+		replace: map[string]string{
+			"list_of_string_or_placeholder_opt_list": "location ( | '(' location ( ',' location )* ')' )",
+			"'FROM' string_or_placeholder 'IN'":      "'FROM' subdirectory 'IN'",
+		},
+		unlink: []string{"location", "subdirectory"},
+	},
 	{
 		name:   "window_definition",
 		inline: []string{"window_specification"},
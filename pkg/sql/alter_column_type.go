@@ -75,6 +75,14 @@ func AlterColumnType(
 			)
 		}
 	}
+	if err := params.p.checkNoDependentFunctions(
+		ctx, tableDesc, "column", col.Name, "alter type of", refersToColumn(col.ID),
+	); err != nil {
+		return err
+	}
+	if err := checkNoDependentTriggers(tableDesc, "column", col.Name, "alter type of", col.ID); err != nil {
+		return err
+	}
 
 	typ, err := tree.ResolveType(ctx, t.ToType, params.p.semaCtx.GetTypeResolver())
 	if err != nil {
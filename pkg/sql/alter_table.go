@@ -188,10 +188,15 @@ func (n *alterTableNode) startExec(params runParams) error {
 					}
 					continue
 				}
+				// The index of a DEFERRABLE constraint is not unique: uniqueness is
+				// enforced by checks which can be deferred until the end of the
+				// transaction.
 				idx := descpb.IndexDescriptor{
-					Name:             string(d.Name),
-					Unique:           true,
-					StoreColumnNames: d.Storing.ToStrings(),
+					Name:              string(d.Name),
+					Unique:            d.Deferrability == tree.ConstraintNotDeferrable,
+					DeferrableUnique:  d.Deferrability != tree.ConstraintNotDeferrable,
+					InitiallyDeferred: d.Deferrability == tree.DeferrableInitiallyDeferred,
+					StoreColumnNames:  d.Storing.ToStrings(),
 				}
 				if err := idx.FillColumns(d.Columns); err != nil {
 					return err
@@ -402,6 +407,37 @@ func (n *alterTableNode) startExec(params runParams) error {
 				}
 			}
 
+			// The same goes for functions.
+			fnIDs := functionIDs(n.tableDesc, refersToColumn(colToDrop.ID))
+			if err := params.p.canRemoveDependentFunctions(
+				params.ctx, "column", string(t.Column), fnIDs, t.DropBehavior,
+			); err != nil {
+				return err
+			}
+			if err := params.p.dropDependentFunctions(
+				params.ctx, fnIDs, fmt.Sprintf("removing function dependent on column %q which is being dropped",
+					colToDrop.ColName()),
+			); err != nil {
+				return err
+			}
+			for _, id := range fnIDs {
+				n.tableDesc.DependedOnByFunctions = removeMatchingReferences(n.tableDesc.DependedOnByFunctions, id)
+			}
+
+			// The same goes for triggers.
+			if t.DropBehavior != tree.DropCascade {
+				if err := checkNoDependentTriggers(
+					n.tableDesc, "column", string(t.Column), "drop", colToDrop.ID,
+				); err != nil {
+					return err
+				}
+			}
+			if err := params.p.removeTriggers(
+				params.ctx, n.tableDesc, triggerRefersToColumn(colToDrop.ID),
+			); err != nil {
+				return err
+			}
+
 			// We cannot remove this column if there are computed columns that use it.
 			computedColValidator := schemaexpr.MakeComputedColumnValidator(
 				params.ctx,
@@ -75,6 +75,12 @@ func (p *planner) AlterTableSetSchema(
 			"set schema on",
 		)
 	}
+	// The same goes for functions.
+	if err := p.checkNoDependentFunctions(
+		ctx, tableDesc, tableDesc.TypeName(), tableDesc.Name, "set schema on", nil, /* pred */
+	); err != nil {
+		return nil, err
+	}
 
 	return &alterTableSetSchemaNode{
 		newSchema: string(n.Schema),
@@ -1342,6 +1342,9 @@ func (sc *SchemaChanger) validateForwardIndexes(
 					return err
 				}
 				idxLen = int64(tree.MustBeDInt(row[0]))
+				if idx.DeferrableUnique {
+					return validateDeferrableUnique(ctx, desc, idx, ie, txn)
+				}
 				return nil
 			}); err != nil {
 				return err
@@ -1550,6 +1553,9 @@ func runSchemaChangesInTxn(
 
 	// Collect constraint mutations to process later.
 	var constraintAdditionMutations []descpb.DescriptorMutation
+	// Collect the indexes of DEFERRABLE unique constraints, which are not
+	// unique and must be validated once all the mutations are applied.
+	var deferrableUniqueIndexes []*descpb.IndexDescriptor
 
 	// We use a range loop here as the processing of some mutations
 	// such as the primary key swap mutations result in queueing more
@@ -1578,6 +1584,9 @@ func runSchemaChangesInTxn(
 				if err := indexBackfillInTxn(ctx, planner.Txn(), planner.EvalContext(), planner.SemaCtx(), immutDesc, traceKV); err != nil {
 					return err
 				}
+				if idx := m.GetIndex(); idx.DeferrableUnique {
+					deferrableUniqueIndexes = append(deferrableUniqueIndexes, idx)
+				}
 
 			case *descpb.DescriptorMutation_Constraint:
 				// This is processed later. Do not proceed to MakeMutationComplete.
@@ -1698,6 +1707,14 @@ func runSchemaChangesInTxn(
 	// Clear all the mutations except for adding constraints.
 	tableDesc.Mutations = constraintAdditionMutations
 
+	for _, idx := range deferrableUniqueIndexes {
+		if err := validateDeferrableUniqueInTxn(
+			ctx, planner.Descriptors().LeaseManager(), planner.EvalContext(), tableDesc, planner.txn, idx,
+		); err != nil {
+			return err
+		}
+	}
+
 	// Now that the table descriptor is in a valid state with all column and index
 	// mutations applied, it can be used for validating check/FK constraints.
 	for _, m := range constraintAdditionMutations {
@@ -1863,6 +1880,37 @@ func validateFkInTxn(
 	return validateForeignKey(ctx, tableDesc, fk, ie, txn, evalCtx.Codec)
 }
 
+// validateDeferrableUniqueInTxn validates a DEFERRABLE unique constraint
+// within the provided transaction. If the provided table descriptor version is
+// newer than the cluster version, it will be used in the InternalExecutor that
+// performs the validation query.
+//
+// It operates entirely on the current goroutine and is thus able to
+// reuse an existing kv.Txn safely.
+func validateDeferrableUniqueInTxn(
+	ctx context.Context,
+	leaseMgr *lease.Manager,
+	evalCtx *tree.EvalContext,
+	tableDesc *tabledesc.Mutable,
+	txn *kv.Txn,
+	idx *descpb.IndexDescriptor,
+) error {
+	ie := evalCtx.InternalExecutor.(*InternalExecutor)
+	if tableDesc.Version > tableDesc.ClusterVersion.Version {
+		newTc := descs.NewCollection(evalCtx.Settings, leaseMgr, nil /* hydratedTables */)
+		// pretend that the schema has been modified.
+		if err := newTc.AddUncommittedDescriptor(tableDesc); err != nil {
+			return err
+		}
+
+		ie.tcModifier = newTc
+		defer func() {
+			ie.tcModifier = nil
+		}()
+	}
+	return validateDeferrableUnique(ctx, tableDesc, idx, ie, txn)
+}
+
 // columnBackfillInTxn backfills columns for all mutation columns in
 // the mutation list.
 //
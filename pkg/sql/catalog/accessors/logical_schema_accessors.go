@@ -191,6 +191,20 @@ func (l *LogicalSchemaAccessor) getPhysicalObjectDesc(
 			return nil, err
 		}
 		return table, err
+	case tree.FunctionObject:
+		funcName := tree.MakeQualifiedFunctionName(db, schema, object)
+		if flags.RequireMutable {
+			fn, err := l.tc.GetMutableFunctionDescriptor(ctx, txn, &funcName, flags)
+			if fn == nil {
+				return nil, err
+			}
+			return fn, err
+		}
+		fn, err := l.tc.GetFunctionVersion(ctx, txn, &funcName, flags)
+		if fn == nil {
+			return nil, err
+		}
+		return fn, err
 	default:
 		return nil, errors.AssertionFailedf("unknown desired object kind %d", flags.DesiredObjectKind)
 	}
@@ -170,6 +170,18 @@ func (desc *IndexDescriptor) IsValidReferencedIndex(referencedColIDs ColumnIDs)
 	return desc.Unique && !desc.IsPartial() && ColumnIDs(desc.ColumnIDs).Equals(referencedColIDs)
 }
 
+// Deferrability returns whether the checks of the unique constraint backed by
+// the index can be deferred until the end of the transaction.
+func (desc *IndexDescriptor) Deferrability() tree.ConstraintDeferrability {
+	switch {
+	case desc.InitiallyDeferred:
+		return tree.DeferrableInitiallyDeferred
+	case desc.DeferrableUnique:
+		return tree.DeferrableInitiallyImmediate
+	}
+	return tree.ConstraintNotDeferrable
+}
+
 // HasOldStoredColumns returns whether the index has stored columns in the old
 // format (data encoded the same way as if they were in an implicit column).
 func (desc *IndexDescriptor) HasOldStoredColumns() bool {
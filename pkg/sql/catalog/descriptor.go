@@ -194,6 +194,15 @@ type TypeDescriptorResolver interface {
 	GetTypeDescriptor(ctx context.Context, id descpb.ID) (tree.TypeName, TypeDescriptor, error)
 }
 
+// FunctionDescriptor will eventually be called funcdesc.Descriptor.
+// It is implemented by funcdesc.Immutable.
+type FunctionDescriptor interface {
+	Descriptor
+	FunctionDesc() *descpb.FunctionDescriptor
+	// Volatility returns the tree.Volatility of the function.
+	Volatility() tree.Volatility
+}
+
 // FilterDescriptorState inspects the state of a given descriptor and returns an
 // error if the state is anything but public. The error describes the state of
 // the descriptor.
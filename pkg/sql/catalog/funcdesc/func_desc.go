@@ -0,0 +1,397 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+// Package funcdesc contains the concrete implementations of
+// catalog.FunctionDescriptor.
+package funcdesc
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
+	"github.com/cockroachdb/errors"
+	"github.com/cockroachdb/redact"
+)
+
+var _ catalog.FunctionDescriptor = (*Immutable)(nil)
+var _ catalog.FunctionDescriptor = (*Mutable)(nil)
+var _ catalog.MutableDescriptor = (*Mutable)(nil)
+
+// Immutable wraps a Function descriptor and provides methods on it.
+type Immutable struct {
+	descpb.FunctionDescriptor
+
+	// isUncommittedVersion is set to true if this descriptor was created from
+	// a copy of a Mutable with an uncommitted version.
+	isUncommittedVersion bool
+}
+
+// Mutable is a mutable reference to a FunctionDescriptor.
+type Mutable struct {
+	Immutable
+
+	ClusterVersion *Immutable
+}
+
+var _ redact.SafeMessager = (*Immutable)(nil)
+
+// SafeMessage makes Immutable a SafeMessager.
+func (desc *Immutable) SafeMessage() string {
+	return formatSafeMessage("funcdesc.Immutable", desc)
+}
+
+// SafeMessage makes Mutable a SafeMessager.
+func (desc *Mutable) SafeMessage() string {
+	return formatSafeMessage("funcdesc.Mutable", desc)
+}
+
+func formatSafeMessage(typeName string, desc catalog.FunctionDescriptor) string {
+	var buf redact.StringBuilder
+	buf.Printf(typeName + ": {")
+	catalog.FormatSafeDescriptorProperties(&buf, desc)
+	buf.Printf("}")
+	return buf.String()
+}
+
+// NewMutableExisting returns a Mutable from the given function descriptor with
+// the cluster version also set to the descriptor. This is for functions that
+// already exist.
+func NewMutableExisting(desc descpb.FunctionDescriptor) *Mutable {
+	return &Mutable{
+		Immutable:      makeImmutable(*protoutil.Clone(&desc).(*descpb.FunctionDescriptor)),
+		ClusterVersion: NewImmutable(desc),
+	}
+}
+
+// NewImmutable makes a new Function descriptor.
+func NewImmutable(desc descpb.FunctionDescriptor) *Immutable {
+	m := makeImmutable(desc)
+	return &m
+}
+
+func makeImmutable(desc descpb.FunctionDescriptor) Immutable {
+	return Immutable{FunctionDescriptor: desc}
+}
+
+// NewCreatedMutable returns a Mutable from the given FunctionDescriptor with
+// the cluster version being the zero function. This is for a function that is
+// created within the current transaction.
+func NewCreatedMutable(desc descpb.FunctionDescriptor) *Mutable {
+	return &Mutable{
+		Immutable: makeImmutable(desc),
+	}
+}
+
+// IsUncommittedVersion implements the Descriptor interface.
+func (desc *Immutable) IsUncommittedVersion() bool {
+	return desc.isUncommittedVersion
+}
+
+// GetAuditMode implements the DescriptorProto interface.
+func (desc *Immutable) GetAuditMode() descpb.TableDescriptor_AuditMode {
+	return descpb.TableDescriptor_DISABLED
+}
+
+// TypeName implements the DescriptorProto interface.
+func (desc *Immutable) TypeName() string {
+	return "function"
+}
+
+// FunctionDesc implements the FunctionDescriptor interface.
+func (desc *Immutable) FunctionDesc() *descpb.FunctionDescriptor {
+	return &desc.FunctionDescriptor
+}
+
+// Volatility implements the FunctionDescriptor interface.
+func (desc *Immutable) Volatility() tree.Volatility {
+	switch desc.FunctionDescriptor.Volatility {
+	case descpb.FunctionDescriptor_IMMUTABLE:
+		return tree.VolatilityImmutable
+	case descpb.FunctionDescriptor_STABLE:
+		return tree.VolatilityStable
+	default:
+		return tree.VolatilityVolatile
+	}
+}
+
+// VolatilityToProto converts a tree.Volatility into the representation stored
+// in the descriptor. Unspecified volatility maps to VOLATILE.
+func VolatilityToProto(v tree.Volatility) descpb.FunctionDescriptor_Volatility {
+	switch v {
+	case tree.VolatilityImmutable, tree.VolatilityLeakProof:
+		return descpb.FunctionDescriptor_IMMUTABLE
+	case tree.VolatilityStable:
+		return descpb.FunctionDescriptor_STABLE
+	default:
+		return descpb.FunctionDescriptor_VOLATILE
+	}
+}
+
+// Public implements the Descriptor interface.
+func (desc *Immutable) Public() bool {
+	return desc.State == descpb.DescriptorState_PUBLIC
+}
+
+// Adding implements the Descriptor interface.
+func (desc *Immutable) Adding() bool {
+	return false
+}
+
+// Offline implements the Descriptor interface.
+func (desc *Immutable) Offline() bool {
+	return desc.State == descpb.DescriptorState_OFFLINE
+}
+
+// Dropped implements the Descriptor interface.
+func (desc *Immutable) Dropped() bool {
+	return desc.State == descpb.DescriptorState_DROP
+}
+
+// DescriptorProto wraps a FunctionDescriptor in a Descriptor.
+func (desc *Immutable) DescriptorProto() *descpb.Descriptor {
+	return &descpb.Descriptor{
+		Union: &descpb.Descriptor_Function{
+			Function: &desc.FunctionDescriptor,
+		},
+	}
+}
+
+// NameResolutionResult implements the ObjectDescriptor interface.
+func (desc *Immutable) NameResolutionResult() {}
+
+// Validate performs validation on the FunctionDescriptor.
+func (desc *Immutable) Validate(ctx context.Context, dg catalog.DescGetter) error {
+	// Validate local properties of the descriptor.
+	if err := catalog.ValidateName(desc.Name, "function"); err != nil {
+		return err
+	}
+	if desc.ID == descpb.InvalidID {
+		return errors.AssertionFailedf("invalid ID %d", errors.Safe(desc.ID))
+	}
+	if desc.ParentID == descpb.InvalidID {
+		return errors.AssertionFailedf("invalid parentID %d", errors.Safe(desc.ParentID))
+	}
+	if desc.ReturnType == nil {
+		return errors.AssertionFailedf("function %q has no return type", desc.Name)
+	}
+	for i := range desc.Args {
+		if desc.Args[i].Type == nil {
+			return errors.AssertionFailedf("argument %d of function %q has no type", i+1, desc.Name)
+		}
+	}
+	if desc.Body == "" {
+		return errors.AssertionFailedf("function %q has no body", desc.Name)
+	}
+	if err := desc.Privileges.Validate(desc.ID, privilege.Function); err != nil {
+		return err
+	}
+
+	// Validate all cross references on the descriptor.
+
+	// Buffer all the requested requests and error checks together to run at once.
+	var checks []func(got catalog.Descriptor) error
+	var reqs []descpb.ID
+
+	// Validate the parentID.
+	reqs = append(reqs, desc.ParentID)
+	checks = append(checks, func(got catalog.Descriptor) error {
+		if _, isDB := got.(catalog.DatabaseDescriptor); !isDB {
+			return errors.AssertionFailedf("parentID %d does not exist", errors.Safe(desc.ParentID))
+		}
+		return nil
+	})
+
+	// Validate the parentSchemaID.
+	if desc.ParentSchemaID != keys.PublicSchemaID {
+		reqs = append(reqs, desc.ParentSchemaID)
+		checks = append(checks, func(got catalog.Descriptor) error {
+			if _, isSchema := got.(catalog.SchemaDescriptor); !isSchema {
+				return errors.AssertionFailedf("parentSchemaID %d does not exist", errors.Safe(desc.ParentSchemaID))
+			}
+			return nil
+		})
+	}
+
+	// Validate that the dependencies and dependents exist.
+	if !desc.Dropped() {
+		for _, id := range desc.DependsOn {
+			id := id
+			reqs = append(reqs, id)
+			checks = append(checks, func(got catalog.Descriptor) error {
+				switch got.(type) {
+				case catalog.TableDescriptor, catalog.FunctionDescriptor:
+					return nil
+				}
+				return errors.AssertionFailedf("depends-on descriptor %d does not exist", errors.Safe(id))
+			})
+		}
+		for _, id := range desc.DependedOnBy {
+			id := id
+			reqs = append(reqs, id)
+			checks = append(checks, func(got catalog.Descriptor) error {
+				if _, isFunc := got.(catalog.FunctionDescriptor); !isFunc {
+					return errors.AssertionFailedf("depended-on-by function %d does not exist", errors.Safe(id))
+				}
+				return nil
+			})
+		}
+		for _, id := range desc.DependedOnByTables {
+			id := id
+			reqs = append(reqs, id)
+			checks = append(checks, func(got catalog.Descriptor) error {
+				if _, isTable := got.(catalog.TableDescriptor); !isTable {
+					return errors.AssertionFailedf("depended-on-by table %d does not exist", errors.Safe(id))
+				}
+				return nil
+			})
+		}
+	}
+
+	descs, err := dg.GetDescs(ctx, reqs)
+	if err != nil {
+		return err
+	}
+
+	// For each result in the batch, apply the corresponding check.
+	for i := range checks {
+		if err := checks[i](descs[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SetDrainingNames implements the MutableDescriptor interface.
+func (desc *Mutable) SetDrainingNames(names []descpb.NameInfo) {
+	desc.DrainingNames = names
+}
+
+// AddDrainingName adds a draining name to the function's list of draining
+// names.
+func (desc *Mutable) AddDrainingName(name descpb.NameInfo) {
+	desc.DrainingNames = append(desc.DrainingNames, name)
+}
+
+// MaybeIncrementVersion implements the MutableDescriptor interface.
+func (desc *Mutable) MaybeIncrementVersion() {
+	// Already incremented, no-op.
+	if desc.ClusterVersion == nil || desc.Version == desc.ClusterVersion.Version+1 {
+		return
+	}
+	desc.Version++
+	desc.ModificationTime = hlc.Timestamp{}
+}
+
+// OriginalName implements the MutableDescriptor interface.
+func (desc *Mutable) OriginalName() string {
+	if desc.ClusterVersion == nil {
+		return ""
+	}
+	return desc.ClusterVersion.Name
+}
+
+// OriginalID implements the MutableDescriptor interface.
+func (desc *Mutable) OriginalID() descpb.ID {
+	if desc.ClusterVersion == nil {
+		return descpb.InvalidID
+	}
+	return desc.ClusterVersion.ID
+}
+
+// OriginalVersion implements the MutableDescriptor interface.
+func (desc *Mutable) OriginalVersion() descpb.DescriptorVersion {
+	if desc.ClusterVersion == nil {
+		return 0
+	}
+	return desc.ClusterVersion.Version
+}
+
+// ImmutableCopy implements the MutableDescriptor interface.
+func (desc *Mutable) ImmutableCopy() catalog.Descriptor {
+	imm := NewImmutable(*protoutil.Clone(desc.FunctionDesc()).(*descpb.FunctionDescriptor))
+	imm.isUncommittedVersion = desc.IsUncommittedVersion()
+	return imm
+}
+
+// IsNew implements the MutableDescriptor interface.
+func (desc *Mutable) IsNew() bool {
+	return desc.ClusterVersion == nil
+}
+
+// SetPublic implements the MutableDescriptor interface.
+func (desc *Mutable) SetPublic() {
+	desc.State = descpb.DescriptorState_PUBLIC
+	desc.OfflineReason = ""
+}
+
+// SetDropped implements the MutableDescriptor interface.
+func (desc *Mutable) SetDropped() {
+	desc.State = descpb.DescriptorState_DROP
+	desc.OfflineReason = ""
+}
+
+// SetOffline implements the MutableDescriptor interface.
+func (desc *Mutable) SetOffline(reason string) {
+	desc.State = descpb.DescriptorState_OFFLINE
+	desc.OfflineReason = reason
+}
+
+// IsUncommittedVersion implements the Descriptor interface.
+func (desc *Mutable) IsUncommittedVersion() bool {
+	return desc.IsNew() || desc.GetVersion() != desc.ClusterVersion.GetVersion()
+}
+
+// AddDependedOnBy adds a new dependent function ID to the descriptor.
+func (desc *Mutable) AddDependedOnBy(id descpb.ID) {
+	for _, dep := range desc.DependedOnBy {
+		if dep == id {
+			return
+		}
+	}
+	desc.DependedOnBy = append(desc.DependedOnBy, id)
+}
+
+// AddDependedOnByTable adds the ID of a table with a trigger which calls the
+// function to the descriptor.
+func (desc *Mutable) AddDependedOnByTable(id descpb.ID) {
+	for _, dep := range desc.DependedOnByTables {
+		if dep == id {
+			return
+		}
+	}
+	desc.DependedOnByTables = append(desc.DependedOnByTables, id)
+}
+
+// RemoveDependedOnByTable removes the ID of a table with a trigger which calls
+// the function from the descriptor.
+func (desc *Mutable) RemoveDependedOnByTable(id descpb.ID) {
+	for i, dep := range desc.DependedOnByTables {
+		if dep == id {
+			desc.DependedOnByTables = append(desc.DependedOnByTables[:i], desc.DependedOnByTables[i+1:]...)
+			return
+		}
+	}
+}
+
+// RemoveDependedOnBy removes the dependent function ID from the descriptor.
+func (desc *Mutable) RemoveDependedOnBy(id descpb.ID) {
+	for i, dep := range desc.DependedOnBy {
+		if dep == id {
+			desc.DependedOnBy = append(desc.DependedOnBy[:i], desc.DependedOnBy[i+1:]...)
+			return
+		}
+	}
+}
@@ -293,8 +293,10 @@ func SanitizeVarFreeExpr(
 	// which uses the properties field.
 	defer semaCtx.Properties.Restore(semaCtx.Properties)
 
-	// Ensure that the expression doesn't contain special functions.
-	flags := tree.RejectSpecial
+	// Ensure that the expression doesn't contain special functions. Stored
+	// expressions do not track dependencies on user-defined functions, so those
+	// are rejected as well.
+	flags := tree.RejectSpecial | tree.RejectUserDefinedFunctions
 
 	switch maxVolatility {
 	case tree.VolatilityImmutable:
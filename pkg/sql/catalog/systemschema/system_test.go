@@ -18,6 +18,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/dbdesc"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/funcdesc"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/schemadesc"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/tabledesc"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/typedesc"
@@ -32,6 +33,7 @@ func TestShouldSplitAtDesc(t *testing.T) {
 		dbdesc.NewInitial(42, "db", security.AdminRoleName()):                                         false,
 		typedesc.NewCreatedMutable(descpb.TypeDescriptor{}):                                           false,
 		schemadesc.NewImmutable(descpb.SchemaDescriptor{}):                                            false,
+		funcdesc.NewImmutable(descpb.FunctionDescriptor{}):                                            false,
 	} {
 		var rawDesc roachpb.Value
 		require.NoError(t, rawDesc.SetProto(inner.DescriptorProto()))
@@ -363,7 +363,7 @@ func allocateIndexName(tableDesc *Mutable, idx *descpb.IndexDescriptor) {
 	segments := make([]string, 0, len(idx.ColumnNames)+2)
 	segments = append(segments, tableDesc.Name)
 	segments = append(segments, idx.ColumnNames...)
-	if idx.Unique {
+	if idx.Unique || idx.DeferrableUnique {
 		segments = append(segments, "key")
 	} else {
 		segments = append(segments, "idx")
@@ -1614,6 +1614,30 @@ func (desc *Immutable) validateCrossReferences(ctx context.Context, dg catalog.D
 	}
 	// TODO(dan): Also validate SharedPrefixLen in the interleaves.
 
+	// Check that the functions which depend on this table exist.
+	for i := range desc.DependedOnByFunctions {
+		id := desc.DependedOnByFunctions[i].ID
+		fn, err := dg.GetDesc(ctx, id)
+		if err != nil {
+			return errors.Wrapf(err, "invalid function back reference %d", errors.Safe(id))
+		}
+		if _, isFunc := fn.(catalog.FunctionDescriptor); !isFunc {
+			return errors.AssertionFailedf("depended-on-by function %d does not exist", errors.Safe(id))
+		}
+	}
+
+	// Check that the functions called by the triggers on this table exist.
+	for i := range desc.Triggers {
+		id := desc.Triggers[i].FunctionID
+		fn, err := dg.GetDesc(ctx, id)
+		if err != nil {
+			return errors.Wrapf(err, "invalid trigger function reference %d", errors.Safe(id))
+		}
+		if _, isFunc := fn.(catalog.FunctionDescriptor); !isFunc {
+			return errors.AssertionFailedf("trigger function %d does not exist", errors.Safe(id))
+		}
+	}
+
 	// Validate the all types present in the descriptor exist. typeMap caches
 	// accesses to TypeDescriptors, and is wrapped by getType.
 	// TODO(ajwerner): generalize this to a cached implementation of the
@@ -1772,6 +1796,10 @@ func (desc *Immutable) ValidateTable(ctx context.Context) error {
 			return err
 		}
 
+		if err := desc.validateTriggers(columnIDs); err != nil {
+			return err
+		}
+
 		if err := desc.validateTableIndexes(columnNames); err != nil {
 			return err
 		}
@@ -1999,6 +2027,34 @@ func (desc *Immutable) validateCheckConstraints(columnIDs map[descpb.ColumnID]st
 	return nil
 }
 
+// validateTriggers validates that the triggers have unique names, fire on at
+// least one event and only refer to existing columns.
+func (desc *Immutable) validateTriggers(columnIDs map[descpb.ColumnID]string) error {
+	names := make(map[string]struct{}, len(desc.Triggers))
+	for i := range desc.Triggers {
+		trig := &desc.Triggers[i]
+		if err := catalog.ValidateName(trig.Name, "trigger"); err != nil {
+			return err
+		}
+		if _, ok := names[trig.Name]; ok {
+			return errors.AssertionFailedf("duplicate trigger name: %q", trig.Name)
+		}
+		names[trig.Name] = struct{}{}
+		if !trig.OnInsert && !trig.OnUpdate && !trig.OnDelete {
+			return errors.AssertionFailedf("trigger %q does not fire on any event", trig.Name)
+		}
+		if trig.Call == "" {
+			return errors.AssertionFailedf("trigger %q has no function call", trig.Name)
+		}
+		for _, colID := range trig.ColumnIDs {
+			if _, ok := columnIDs[colID]; !ok {
+				return errors.AssertionFailedf("trigger %q contains unknown column \"%d\"", trig.Name, colID)
+			}
+		}
+	}
+	return nil
+}
+
 // validateTableIndexes validates that indexes are well formed. Checks include
 // validating the columns involved in the index, verifying the index names and
 // IDs are unique, and the family of the primary key is 0. This does not check
@@ -2977,6 +3033,18 @@ func (desc *Immutable) FindFKByName(name string) (*descpb.ForeignKeyConstraint,
 	return nil, fmt.Errorf("fk %q does not exist", name)
 }
 
+// FindTriggerByName returns the trigger on the table with the given name, if
+// any. Returns a pointer to the trigger in the TableDescriptor, so that callers
+// can use returned values to modify the TableDesc.
+func (desc *Immutable) FindTriggerByName(name string) (*descpb.TableDescriptor_Trigger, bool) {
+	for i := range desc.Triggers {
+		if desc.Triggers[i].Name == name {
+			return &desc.Triggers[i], true
+		}
+	}
+	return nil, false
+}
+
 // IsInterleaved returns true if any part of this this table is interleaved with
 // another table's data.
 func (desc *Immutable) IsInterleaved() bool {
@@ -94,7 +94,9 @@ var validationMap = []struct {
 			"DependedOnBy": {
 				status: todoIAmKnowinglyAddingTechDebt,
 				reason: "initial import: TODO(features): add validation"},
-			"MutationJobs": {status: thisFieldReferencesNoObjects},
+			"DependedOnByFunctions": {status: iSolemnlySwearThisFieldIsValidated},
+			"Triggers":              {status: iSolemnlySwearThisFieldIsValidated},
+			"MutationJobs":          {status: thisFieldReferencesNoObjects},
 			"SequenceOpts": {status: todoIAmKnowinglyAddingTechDebt,
 				reason: "initial import: TODO(features): add validation"},
 			"DropTime": {status: thisFieldReferencesNoObjects},
@@ -147,6 +149,8 @@ var validationMap = []struct {
 			"Disabled":          {status: thisFieldReferencesNoObjects},
 			"GeoConfig":         {status: thisFieldReferencesNoObjects},
 			"Predicate":         {status: iSolemnlySwearThisFieldIsValidated},
+			"DeferrableUnique":  {status: thisFieldReferencesNoObjects},
+			"InitiallyDeferred": {status: thisFieldReferencesNoObjects},
 		},
 	},
 	{
@@ -190,6 +194,8 @@ var validationMap = []struct {
 			"OnDelete":          {status: thisFieldReferencesNoObjects},
 			"OnUpdate":          {status: thisFieldReferencesNoObjects},
 			"Match":             {status: thisFieldReferencesNoObjects},
+			"Deferrable":        {status: thisFieldReferencesNoObjects},
+			"InitiallyDeferred": {status: thisFieldReferencesNoObjects},
 		},
 	},
 	{
@@ -314,6 +314,50 @@ func formatValues(colNames []string, values tree.Datums) string {
 	return pairs.String()
 }
 
+// validateDeferrableUnique verifies that there are no duplicate keys in the
+// columns of a DEFERRABLE unique constraint. The index backing such a
+// constraint is not unique, so it does not prevent them.
+//
+// It operates entirely on the current goroutine and is thus able to
+// reuse an existing client.Txn safely.
+func validateDeferrableUnique(
+	ctx context.Context,
+	tableDesc catalog.TableDescriptor,
+	idx *descpb.IndexDescriptor,
+	ie *InternalExecutor,
+	txn *kv.Txn,
+) error {
+	var columns, notNull bytes.Buffer
+	for i := range idx.ColumnNames {
+		if i > 0 {
+			columns.WriteString(", ")
+			notNull.WriteString(" AND ")
+		}
+		name := tree.NameString(idx.ColumnNames[i])
+		columns.WriteString(name)
+		fmt.Fprintf(&notNull, "%s IS NOT NULL", name)
+	}
+	// Force the primary index so that the optimizer does not use the index
+	// being validated.
+	query := fmt.Sprintf(
+		`SELECT %[1]s FROM [%[2]d AS t]@[%[3]d] WHERE %[4]s GROUP BY %[1]s HAVING count(*) > 1 LIMIT 1`,
+		columns.String(), tableDesc.GetID(), tableDesc.GetPrimaryIndexID(), notNull.String(),
+	)
+	log.Infof(ctx, "validating unique constraint %q with query %q", idx.Name, query)
+
+	values, err := ie.QueryRow(ctx, "validate unique constraint", txn, query)
+	if err != nil {
+		return err
+	}
+	if values.Len() > 0 {
+		return pgerror.WithConstraintName(pgerror.Newf(pgcode.UniqueViolation,
+			"duplicate key value violates unique constraint %q: %s already exists",
+			idx.Name, formatValues(idx.ColumnNames, values),
+		), idx.Name)
+	}
+	return nil
+}
+
 // checkSet contains a subset of checks, as ordinals into
 // Immutable.ActiveChecks. These checks have boolean columns
 // produced as input to mutations, indicating the result of evaluating the
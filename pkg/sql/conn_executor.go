@@ -626,6 +626,7 @@ func (s *Server) newConnExecutor(
 		portals:   make(map[string]PreparedPortal),
 	}
 	ex.extraTxnState.prepStmtsNamespaceMemAcc = ex.sessionMon.MakeBoundAccount()
+	ex.extraTxnState.deferredConstraints.acc = ex.sessionMon.MakeBoundAccount()
 	ex.extraTxnState.descCollection = descs.MakeCollection(
 		s.cfg.LeaseManager, s.cfg.Settings, sd, s.cfg.HydratedTables)
 	ex.extraTxnState.txnRewindPos = -1
@@ -847,6 +848,10 @@ func (ex *connExecutor) close(ctx context.Context, closeType closeType) {
 	}
 
 	if closeType != panicClose {
+		// Close all cursors. Cursors declared WITH HOLD have survived the
+		// rollback above.
+		ex.closeAllCursors(ctx)
+
 		// Close all statements and prepared portals.
 		ex.extraTxnState.prepStmtsNamespace.resetTo(
 			ctx, prepStmtNamespace{}, &ex.extraTxnState.prepStmtsNamespaceMemAcc,
@@ -855,6 +860,7 @@ func (ex *connExecutor) close(ctx context.Context, closeType closeType) {
 			ctx, prepStmtNamespace{}, &ex.extraTxnState.prepStmtsNamespaceMemAcc,
 		)
 		ex.extraTxnState.prepStmtsNamespaceMemAcc.Close(ctx)
+		ex.extraTxnState.deferredConstraints.close(ctx)
 	}
 
 	if ex.sessionTracing.Enabled() {
@@ -931,6 +937,11 @@ type connExecutor struct {
 	// if traceSessionEventLogEnabled; it is used by ex.sessionEventf()
 	eventLog trace.EventLog
 
+	// sqlCursors contains the cursors created by DECLARE. Cursors declared WITH
+	// HOLD outlive the txn that created them; all others are closed when it
+	// finishes.
+	sqlCursors cursorMap
+
 	// extraTxnState groups fields scoped to a SQL txn that are not handled by
 	// ex.state, above. The rule of thumb is that, if the state influences state
 	// transitions, it should live in state, otherwise it can live here.
@@ -1018,6 +1029,12 @@ type connExecutor struct {
 		// going to restore this snapshot.
 		savepointsAtTxnRewindPos savepointStack
 
+		// deferredConstraints holds the modes set with SET CONSTRAINTS and the
+		// violations of deferred constraints, which are checked again before the
+		// transaction commits. Unlike most of extraTxnState, it is kept when the
+		// transaction restarts; see deferredConstraints.
+		deferredConstraints deferredConstraints
+
 		// transactionStatementIDs tracks all statement IDs that make up the current
 		// transaction. It's length is bound by the TxnStatsNumStmtIDsToRecord
 		// cluster setting.
@@ -1230,9 +1247,12 @@ func (ex *connExecutor) resetExtraTxnState(ctx context.Context, ev txnEvent) err
 		delete(ex.extraTxnState.prepStmtsNamespace.portals, name)
 	}
 
+	ex.resetCursorsForTxnEnd(ctx, ev)
+
 	switch ev {
 	case txnCommit, txnRollback:
 		ex.extraTxnState.savepoints.clear()
+		ex.extraTxnState.deferredConstraints.reset(ctx)
 		// After txn is finished, we need to call onTxnFinish (if it's non-nil).
 		if ex.extraTxnState.onTxnFinish != nil {
 			ex.extraTxnState.onTxnFinish(ev)
@@ -2169,6 +2189,10 @@ func (ex *connExecutor) initPlanner(ctx context.Context, p *planner) {
 	p.sessionDataMutator = ex.dataMutator
 	p.noticeSender = nil
 	p.preparedStatements = ex.getPrepStmtsAccessor()
+	p.sqlCursors = connExCursorsAccessor{ex: ex}
+	if ex.executorType == executorTypeExec {
+		p.deferredConstraints = &ex.extraTxnState.deferredConstraints
+	}
 
 	p.queryCacheSession.Init()
 	p.optPlanningCtx.init(p)
@@ -2188,6 +2212,7 @@ func (ex *connExecutor) resetPlanner(
 	p.semaCtx.AsOfTimestamp = nil
 	p.semaCtx.Annotations = nil
 	p.semaCtx.TypeResolver = p
+	p.semaCtx.FunctionResolver = p
 
 	ex.resetEvalCtx(&p.extendedEvalCtx, txn, stmtTS)
 
@@ -619,6 +619,22 @@ func (ex *connExecutor) execStmtInOpenState(
 	p.stmt = stmt
 	p.cancelChecker = cancelchecker.NewCancelChecker(ctx)
 	p.autoCommit = os.ImplicitTxn.Get() && !ex.server.cfg.TestingKnobs.DisableAutoCommit
+
+	switch ast.(type) {
+	case *tree.DeclareCursor, *tree.FetchCursor, *tree.MoveCursor, *tree.CloseCursor:
+		// Cursor statements operate on session state and are not planned; see
+		// sql_cursor.go.
+		if err := ex.execCursorStmt(ctx, p, os.ImplicitTxn.Get(), res); err != nil {
+			return makeErrEvent(err)
+		}
+		return nil, nil, nil
+	}
+	// The reads of the cursors are refreshed along with those of the statement
+	// if the txn is pushed.
+	if err := ex.mergeCursorsIntoRoot(ctx); err != nil {
+		return makeErrEvent(err)
+	}
+
 	if err := ex.dispatchToExecutionEngine(ctx, p, res); err != nil {
 		return nil, nil, err
 	}
@@ -698,6 +714,17 @@ func (ex *connExecutor) commitSQLTransaction(
 func (ex *connExecutor) commitSQLTransactionInternal(
 	ctx context.Context, ast tree.Statement,
 ) error {
+	if err := recheckDeferredConstraints(
+		ctx, ex.server.cfg.InternalExecutor, ex.state.mu.txn,
+		ex.extraTxnState.deferredConstraints.takePending(),
+	); err != nil {
+		return err
+	}
+
+	if err := ex.prepareCursorsForCommit(ctx); err != nil {
+		return err
+	}
+
 	if err := validatePrimaryKeys(&ex.extraTxnState.descCollection); err != nil {
 		return err
 	}
@@ -0,0 +1,215 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sql
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/server/telemetry"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/catalogkv"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/dbdesc"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/funcdesc"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/tabledesc"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlerrors"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqltelemetry"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+)
+
+// createFunctionNode represents a CREATE FUNCTION statement.
+type createFunctionNode struct {
+	// n is the CREATE FUNCTION statement as built by the optimizer: the
+	// function name is fully qualified, the argument and return types are
+	// resolved, and the body refers to fully qualified names.
+	n      *tree.CreateFunction
+	dbDesc *dbdesc.Immutable
+
+	// planDeps tracks which tables and views the function body depends on.
+	planDeps planDependencies
+	// funcDeps contains the IDs of the user-defined functions called by the
+	// function body.
+	funcDeps []descpb.ID
+}
+
+// ReadingOwnWrites implements the planNodeReadingOwnWrites interface.
+// This is because CREATE FUNCTION performs multiple KV operations on
+// descriptors and expects to see its own writes.
+func (n *createFunctionNode) ReadingOwnWrites() {}
+
+func (n *createFunctionNode) startExec(params runParams) error {
+	telemetry.Inc(sqltelemetry.SchemaChangeCreateCounter("function"))
+
+	p := params.p
+	funcName := tree.MakeQualifiedFunctionName(
+		n.n.FuncName.Catalog(), n.n.FuncName.Schema(), n.n.FuncName.Object(),
+	)
+	log.VEventf(params.ctx, 2, "dependencies for function %s:\n%s", &funcName, n.planDeps.String())
+
+	if n.dbDesc.ID == keys.SystemDatabaseID {
+		return pgerror.New(pgcode.InsufficientPrivilege,
+			"cannot create a function in the system database")
+	}
+
+	// Check that the function does not refer to tables in other databases or
+	// to temporary tables, which would outlive the function.
+	for _, dep := range n.planDeps {
+		if dbID := dep.desc.ParentID; dbID != n.dbDesc.ID && dbID != keys.SystemDatabaseID {
+			return pgerror.New(pgcode.FeatureNotSupported,
+				"the function cannot refer to other databases")
+		}
+		if dep.desc.Temporary {
+			return pgerror.Newf(pgcode.FeatureNotSupported,
+				"the function cannot refer to temporary table %q", dep.desc.Name)
+		}
+	}
+
+	// Get the ID of the schema the function is being created in, and check
+	// for name collisions.
+	schemaID, err := p.getSchemaIDForCreate(params.ctx, params.ExecCfg().Codec, n.dbDesc.ID, funcName.Schema())
+	if err != nil {
+		return err
+	}
+	if err := p.canCreateOnSchema(
+		params.ctx, schemaID, n.dbDesc.ID, p.User(), skipCheckPublicSchema); err != nil {
+		return err
+	}
+	if schemaID != keys.PublicSchemaID {
+		sqltelemetry.IncrementUserDefinedSchemaCounter(sqltelemetry.UserDefinedSchemaUsedByObject)
+	}
+	funcKey := catalogkv.MakeObjectNameKey(
+		params.ctx, params.ExecCfg().Settings, n.dbDesc.ID, schemaID, funcName.Object(),
+	)
+	exists, collided, err := catalogkv.LookupObjectID(
+		params.ctx, p.txn, params.ExecCfg().Codec, n.dbDesc.ID, schemaID, funcName.Object())
+	if err == nil && exists {
+		desc, err := catalogkv.GetAnyDescriptorByID(params.ctx, p.txn, params.ExecCfg().Codec, collided, catalogkv.Immutable)
+		if err != nil {
+			return sqlerrors.WrapErrorWhileConstructingObjectAlreadyExistsErr(err)
+		}
+		return sqlerrors.MakeObjectAlreadyExistsError(desc.DescriptorProto(), funcName.String())
+	}
+	if err != nil {
+		return err
+	}
+
+	id, err := catalogkv.GenerateUniqueDescID(params.ctx, params.ExecCfg().DB, params.ExecCfg().Codec)
+	if err != nil {
+		return err
+	}
+
+	// Like types, functions do not inherit privileges from the database, but
+	// having USAGE on the parent schema gives USAGE on the function.
+	privs := descpb.NewDefaultPrivilegeDescriptor(p.User())
+	resolvedSchema, err := p.Descriptors().ResolveSchemaByID(params.ctx, p.Txn(), schemaID)
+	if err != nil {
+		return err
+	}
+	inheritUsagePrivilegeFromSchema(resolvedSchema, privs)
+	privs.Grant(p.User(), privilege.List{privilege.ALL})
+
+	args := make([]descpb.FunctionDescriptor_Argument, len(n.n.Args))
+	for i := range n.n.Args {
+		args[i] = descpb.FunctionDescriptor_Argument{
+			Name: string(n.n.Args[i].Name),
+			Type: n.n.Args[i].Type.(*types.T),
+		}
+	}
+	desc := funcdesc.NewCreatedMutable(descpb.FunctionDescriptor{
+		Name:           funcName.Object(),
+		ID:             id,
+		Version:        1,
+		Privileges:     privs,
+		ParentID:       n.dbDesc.ID,
+		ParentSchemaID: schemaID,
+		Args:           args,
+		ReturnType:     n.n.ReturnType.(*types.T),
+		ReturnsSet:     n.n.ReturnsSet,
+		Volatility:     funcdesc.VolatilityToProto(n.n.Volatility),
+		Body:           n.n.Body,
+	})
+	for depID := range n.planDeps {
+		desc.DependsOn = append(desc.DependsOn, depID)
+	}
+	desc.DependsOn = append(desc.DependsOn, n.funcDeps...)
+
+	if err := p.createDescriptorWithID(
+		params.ctx,
+		funcKey.Key(params.ExecCfg().Codec),
+		id,
+		desc,
+		params.EvalContext().Settings,
+		tree.AsStringWithFQNames(n.n, params.Ann()),
+	); err != nil {
+		return err
+	}
+
+	// Persist the back-references in all referenced table descriptors.
+	for depID, updated := range n.planDeps {
+		backRefMutable := p.Descriptors().GetUncommittedTableByID(depID)
+		if backRefMutable == nil {
+			backRefMutable = tabledesc.NewExistingMutable(*updated.desc.TableDesc())
+		}
+		for _, dep := range updated.deps {
+			// The ID of the function was not known when the dependencies were
+			// collected.
+			dep.ID = id
+			backRefMutable.DependedOnByFunctions = append(backRefMutable.DependedOnByFunctions, dep)
+		}
+		if err := p.writeSchemaChange(
+			params.ctx,
+			backRefMutable,
+			descpb.InvalidMutationID,
+			fmt.Sprintf("updating function reference %q in table %s(%d)", &funcName,
+				updated.desc.Name, updated.desc.ID,
+			),
+		); err != nil {
+			return err
+		}
+	}
+
+	// Persist the back-references in all called functions.
+	for _, depID := range n.funcDeps {
+		fnDesc, err := p.Descriptors().GetMutableFunctionVersionByID(params.ctx, p.txn, depID)
+		if err != nil {
+			return err
+		}
+		fnDesc.AddDependedOnBy(id)
+		if err := p.writeFunctionDesc(params.ctx, fnDesc); err != nil {
+			return err
+		}
+	}
+
+	// Log Create Function event. This is an auditable log event and is
+	// recorded in the same transaction as the function descriptor update.
+	return MakeEventLogger(p.ExecCfg()).InsertEventRecord(
+		params.ctx,
+		p.txn,
+		EventLogCreateFunction,
+		int32(id),
+		int32(params.extendedEvalCtx.NodeID.SQLInstanceID()),
+		struct {
+			FunctionName string
+			Statement    string
+			User         string
+		}{funcName.FQString(), tree.AsStringWithFQNames(n.n, params.Ann()), p.User().Normalized()},
+	)
+}
+
+func (*createFunctionNode) Next(runParams) (bool, error) { return false, nil }
+func (*createFunctionNode) Values() tree.Datums          { return tree.Datums{} }
+func (*createFunctionNode) Close(context.Context)        {}
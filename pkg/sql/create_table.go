@@ -823,6 +823,8 @@ func ResolveFK(
 		OnDelete:            descpb.ForeignKeyReferenceActionValue[d.Actions.Delete],
 		OnUpdate:            descpb.ForeignKeyReferenceActionValue[d.Actions.Update],
 		Match:               descpb.CompositeKeyMatchMethodValue[d.Match],
+		Deferrable:          d.Deferrability != tree.ConstraintNotDeferrable,
+		InitiallyDeferred:   d.Deferrability == tree.DeferrableInitiallyDeferred,
 	}
 
 	if ts == NewTable {
@@ -1462,11 +1464,19 @@ func NewTableDesc(
 					"unique constraints without an index are not yet supported",
 				)
 			}
+			if d.Deferrability != tree.ConstraintNotDeferrable && d.Predicate != nil {
+				return nil, errDeferrablePartialUnique
+			}
+			// The index of a DEFERRABLE constraint is not unique: uniqueness is
+			// enforced by checks which can be deferred until the end of the
+			// transaction.
 			idx := descpb.IndexDescriptor{
-				Name:             string(d.Name),
-				Unique:           true,
-				StoreColumnNames: d.Storing.ToStrings(),
-				Version:          indexEncodingVersion,
+				Name:              string(d.Name),
+				Unique:            d.Deferrability == tree.ConstraintNotDeferrable,
+				DeferrableUnique:  d.Deferrability != tree.ConstraintNotDeferrable,
+				InitiallyDeferred: d.Deferrability == tree.DeferrableInitiallyDeferred,
+				StoreColumnNames:  d.Storing.ToStrings(),
+				Version:           indexEncodingVersion,
 			}
 			if d.Sharded != nil {
 				if n.Interleave != nil && d.PrimaryKey {
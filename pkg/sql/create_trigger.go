@@ -0,0 +1,210 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sql
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/server/telemetry"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/catalogkv"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/tabledesc"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqltelemetry"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+)
+
+type createTriggerNode struct {
+	n         *tree.CreateTrigger
+	tableDesc *tabledesc.Mutable
+	trigger   descpb.TableDescriptor_Trigger
+}
+
+// CreateTrigger creates a row-level trigger on a table.
+// Privileges: CREATE on table.
+//   Notes: postgres requires TRIGGER on the table and EXECUTE on the function.
+func (p *planner) CreateTrigger(ctx context.Context, n *tree.CreateTrigger) (planNode, error) {
+	tn := n.Table.ToTableName()
+	tableDesc, err := p.ResolveMutableTableDescriptor(ctx, &tn, true /* required */, tree.ResolveRequireTableDesc)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.CheckPrivilege(ctx, tableDesc, privilege.CREATE); err != nil {
+		return nil, err
+	}
+	if tableDesc.Temporary {
+		return nil, pgerror.Newf(pgcode.FeatureNotSupported,
+			"cannot create a trigger on temporary table %q", tableDesc.Name)
+	}
+	if _, ok := tableDesc.FindTriggerByName(string(n.Name)); ok {
+		return nil, pgerror.Newf(pgcode.DuplicateObject,
+			"trigger %q for relation %q already exists", n.Name, tableDesc.Name)
+	}
+
+	trigger := descpb.TableDescriptor_Trigger{Name: string(n.Name)}
+	if n.ActionTime == tree.TriggerAfter {
+		trigger.ActionTime = descpb.TableDescriptor_Trigger_AFTER
+	}
+	for _, event := range n.Events {
+		switch event {
+		case tree.TriggerInsert:
+			trigger.OnInsert = true
+		case tree.TriggerUpdate:
+			trigger.OnUpdate = true
+		case tree.TriggerDelete:
+			trigger.OnDelete = true
+		}
+	}
+
+	trigger.FunctionID, trigger.Call, trigger.ColumnIDs, err = p.resolveTriggerCall(ctx, tableDesc, n.Func)
+	if err != nil {
+		return nil, err
+	}
+
+	return &createTriggerNode{n: n, tableDesc: tableDesc, trigger: trigger}, nil
+}
+
+// resolveTriggerCall type-checks the call to the function of a trigger, in
+// which the arguments can refer to the new and old values of the columns of
+// the table as new.<column> and old.<column>. It returns the ID of the called
+// function, the serialized call with the fully qualified name of the function,
+// and the IDs of the columns referenced by the call.
+func (p *planner) resolveTriggerCall(
+	ctx context.Context, tableDesc *tabledesc.Mutable, call *tree.FuncExpr,
+) (_ descpb.ID, _ string, colIDs []descpb.ColumnID, _ error) {
+	// Replace the references to the columns with NULLs of the column types, so
+	// that the call can be type-checked.
+	v := func(expr tree.Expr) (recurse bool, newExpr tree.Expr, _ error) {
+		name, ok := expr.(*tree.UnresolvedName)
+		if !ok {
+			return true, expr, nil
+		}
+		if name.Star || name.NumParts != 2 || (name.Parts[1] != "new" && name.Parts[1] != "old") {
+			return false, nil, pgerror.Newf(pgcode.InvalidObjectDefinition,
+				"trigger arguments can only refer to columns as NEW.<column> or OLD.<column>, not %s",
+				tree.ErrString(name))
+		}
+		col, err := tableDesc.FindActiveColumnByName(name.Parts[0])
+		if err != nil {
+			return false, nil, err
+		}
+		found := false
+		for _, id := range colIDs {
+			found = found || id == col.ID
+		}
+		if !found {
+			colIDs = append(colIDs, col.ID)
+		}
+		return false, &tree.CastExpr{Expr: tree.DNull, Type: col.Type, SyntaxMode: tree.CastShort}, nil
+	}
+	expr, err := tree.SimpleVisit(call, v)
+	if err != nil {
+		return 0, "", nil, err
+	}
+
+	defer p.semaCtx.Properties.Restore(p.semaCtx.Properties)
+	p.semaCtx.Properties.Require("trigger", tree.RejectSpecial|tree.RejectSubqueries)
+	typedExpr, err := tree.TypeCheck(ctx, expr, &p.semaCtx, types.Any)
+	if err != nil {
+		return 0, "", nil, err
+	}
+	funcExpr, ok := typedExpr.(*tree.FuncExpr)
+	if !ok || funcExpr.ResolvedOverload() == nil || funcExpr.ResolvedOverload().UDF == nil {
+		return 0, "", nil, pgerror.Newf(pgcode.WrongObjectType,
+			"function %s is not a user-defined function", tree.ErrString(&call.Func))
+	}
+	udf := funcExpr.ResolvedOverload().UDF
+	if udf.ReturnsSet {
+		return 0, "", nil, pgerror.Newf(pgcode.InvalidObjectDefinition,
+			"set-returning function %s cannot be used in a trigger", udf.QualifiedName)
+	}
+
+	// The call refers to the function by its fully qualified name, like the
+	// bodies of functions refer to the objects they depend on.
+	fn, err := p.Descriptors().GetFunctionVersionByID(
+		ctx, p.txn, descpb.ID(udf.ID), tree.ObjectLookupFlags{CommonLookupFlags: p.CommonLookupFlags(true /* required */)},
+	)
+	if err != nil {
+		return 0, "", nil, err
+	}
+	if fn.ParentID != tableDesc.ParentID {
+		return 0, "", nil, pgerror.Newf(pgcode.FeatureNotSupported,
+			"the function of a trigger cannot be in another database than the table")
+	}
+
+	// The call is stored with the fully qualified name of the function, and
+	// the arguments as they were written.
+	stored := tree.FuncExpr{
+		Func:  tree.ResolvableFunctionReference{FunctionReference: udf.QualifiedName},
+		Exprs: call.Exprs,
+	}
+	return descpb.ID(udf.ID), tree.AsStringWithFlags(&stored, tree.FmtParsable), colIDs, nil
+}
+
+// ReadingOwnWrites implements the planNodeReadingOwnWrites interface.
+// This is because CREATE TRIGGER performs multiple KV operations on
+// descriptors and expects to see its own writes.
+func (n *createTriggerNode) ReadingOwnWrites() {}
+
+func (n *createTriggerNode) startExec(params runParams) error {
+	telemetry.Inc(sqltelemetry.SchemaChangeCreateCounter("trigger"))
+
+	p := params.p
+	tableDesc := n.tableDesc
+	tableDesc.Triggers = append(tableDesc.Triggers, n.trigger)
+	if err := tableDesc.Validate(
+		params.ctx, catalogkv.NewOneLevelUncachedDescGetter(p.txn, p.ExecCfg().Codec),
+	); err != nil {
+		return err
+	}
+	if err := p.writeSchemaChange(
+		params.ctx, tableDesc, descpb.InvalidMutationID, tree.AsStringWithFQNames(n.n, params.Ann()),
+	); err != nil {
+		return err
+	}
+
+	// Persist the back-reference in the called function.
+	fnDesc, err := p.Descriptors().GetMutableFunctionVersionByID(params.ctx, p.txn, n.trigger.FunctionID)
+	if err != nil {
+		return err
+	}
+	fnDesc.AddDependedOnByTable(tableDesc.ID)
+	if err := p.writeFunctionDesc(params.ctx, fnDesc); err != nil {
+		return err
+	}
+
+	// Log Create Trigger event. This is an auditable log event and is
+	// recorded in the same transaction as the table descriptor update.
+	tn, err := p.getQualifiedTableName(params.ctx, tableDesc)
+	if err != nil {
+		return err
+	}
+	return MakeEventLogger(p.ExecCfg()).InsertEventRecord(
+		params.ctx,
+		p.txn,
+		EventLogCreateTrigger,
+		int32(tableDesc.ID),
+		int32(params.extendedEvalCtx.NodeID.SQLInstanceID()),
+		struct {
+			TableName   string
+			TriggerName string
+			Statement   string
+			User        string
+		}{tn.FQString(), n.n.Name.String(), tree.AsStringWithFQNames(n.n, params.Ann()), p.User().Normalized()},
+	)
+}
+
+func (*createTriggerNode) Next(runParams) (bool, error) { return false, nil }
+func (*createTriggerNode) Values() tree.Datums          { return tree.Datums{} }
+func (*createTriggerNode) Close(context.Context)        {}
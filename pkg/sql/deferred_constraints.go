@@ -0,0 +1,338 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sql
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/cockroachdb/cockroach/pkg/kv"
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/cat"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/exec"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgnotice"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
+	"github.com/cockroachdb/cockroach/pkg/util/mon"
+)
+
+// deferredConstraints holds the state of the DEFERRABLE constraints in a SQL
+// transaction: the modes set with SET CONSTRAINTS, and the violations of
+// deferred constraints which must be checked again before the transaction
+// commits.
+//
+// Violations are checked again against the data visible to the transaction at
+// that point, so it is harmless to keep violations recorded by statements that
+// were later rolled back; they are only reported if they still exist.
+type deferredConstraints struct {
+	// allSet is true if SET CONSTRAINTS ALL was used, in which case
+	// allDeferred is the mode it set.
+	allSet      bool
+	allDeferred bool
+	// byName holds the modes set with SET CONSTRAINTS for specific
+	// constraints. They take precedence over the mode set for ALL.
+	byName map[string]bool
+
+	// pending holds the deferred violations of each constraint, in the order in
+	// which the constraints were first violated. byConstraint indexes it by
+	// constraint.
+	pending      []*pendingChecks
+	byConstraint map[string]*pendingChecks
+
+	// acc accounts for the memory used by the deferred violations.
+	acc mon.BoundAccount
+}
+
+// pendingChecks holds the violations of a foreign key or unique constraint which
+// were deferred until the end of the transaction. They are checked again
+// together.
+type pendingChecks struct {
+	check *exec.DeferrableCheck
+	// keyVals holds the key values of each violation, in the order in which
+	// they were found, and errs the error that was generated for it; the error
+	// is returned if the violation still exists when it is checked again.
+	keyVals []tree.Datums
+	errs    []error
+	// seen is used to avoid recording the same violation twice.
+	seen map[string]struct{}
+}
+
+// isDeferred returns whether violations of the given constraint should be
+// deferred until the end of the transaction.
+func (dc *deferredConstraints) isDeferred(check *exec.DeferrableCheck) bool {
+	if deferred, ok := dc.byName[check.Name]; ok {
+		return deferred
+	}
+	if dc.allSet {
+		return dc.allDeferred
+	}
+	return check.InitiallyDeferred
+}
+
+// addPending records a deferred violation.
+func (dc *deferredConstraints) addPending(
+	ctx context.Context, check *exec.DeferrableCheck, keyVals tree.Datums, err error,
+) error {
+	constraintKey := fmt.Sprintf("%d/%s", check.Table, check.Name)
+	p, ok := dc.byConstraint[constraintKey]
+	if !ok {
+		if err := dc.acc.Grow(ctx, int64(len(constraintKey))); err != nil {
+			return err
+		}
+		if dc.byConstraint == nil {
+			dc.byConstraint = make(map[string]*pendingChecks)
+		}
+		p = &pendingChecks{check: check, seen: make(map[string]struct{})}
+		dc.byConstraint[constraintKey] = p
+		dc.pending = append(dc.pending, p)
+	}
+	key := keyVals.String()
+	if _, ok := p.seen[key]; ok {
+		return nil
+	}
+	size := int64(len(key)) + tree.SizeOfDatums
+	for _, d := range keyVals {
+		size += int64(d.Size())
+	}
+	if err := dc.acc.Grow(ctx, size); err != nil {
+		return err
+	}
+	p.seen[key] = struct{}{}
+	p.keyVals = append(p.keyVals, keyVals)
+	p.errs = append(p.errs, err)
+	return nil
+}
+
+// setMode implements SET CONSTRAINTS. It returns the deferred violations of
+// the constraints that are now immediate, which must be checked right away;
+// they are no longer pending.
+func (dc *deferredConstraints) setMode(names tree.NameList, deferred bool) []*pendingChecks {
+	if names == nil {
+		dc.allSet, dc.allDeferred = true, deferred
+		dc.byName = nil
+	} else {
+		if dc.byName == nil {
+			dc.byName = make(map[string]bool, len(names))
+		}
+		for _, name := range names {
+			dc.byName[string(name)] = deferred
+		}
+	}
+	if deferred {
+		return nil
+	}
+	var immediate []*pendingChecks
+	remaining := dc.pending[:0]
+	for _, p := range dc.pending {
+		if dc.isDeferred(p.check) {
+			remaining = append(remaining, p)
+		} else {
+			immediate = append(immediate, p)
+		}
+	}
+	dc.pending = remaining
+	for k, p := range dc.byConstraint {
+		if !dc.isDeferred(p.check) {
+			delete(dc.byConstraint, k)
+		}
+	}
+	return immediate
+}
+
+// takePending returns all the deferred violations and forgets them. Their
+// memory remains accounted for until the end of the transaction.
+func (dc *deferredConstraints) takePending() []*pendingChecks {
+	pending := dc.pending
+	dc.pending = nil
+	dc.byConstraint = nil
+	return pending
+}
+
+// reset clears the state at the end of a transaction.
+func (dc *deferredConstraints) reset(ctx context.Context) {
+	dc.acc.Clear(ctx)
+	*dc = deferredConstraints{acc: dc.acc}
+}
+
+// close releases the memory account.
+func (dc *deferredConstraints) close(ctx context.Context) {
+	dc.acc.Close(ctx)
+}
+
+// recheckBatchSize is the maximum number of violations of a constraint that
+// are checked again by a single query.
+const recheckBatchSize = 256
+
+// recheckDeferredConstraints checks whether the given deferred violations still
+// exist, and returns the error of the first one that does. The violations of
+// each constraint are checked by one query per batch of recheckBatchSize.
+func recheckDeferredConstraints(
+	ctx context.Context, ie *InternalExecutor, txn *kv.Txn, pending []*pendingChecks,
+) error {
+	for _, p := range pending {
+		for start := 0; start < len(p.keyVals); start += recheckBatchSize {
+			end := start + recheckBatchSize
+			if end > len(p.keyVals) {
+				end = len(p.keyVals)
+			}
+			query, args := p.recheckQuery(p.keyVals[start:end])
+			row, err := ie.QueryRowEx(
+				ctx, "deferred-constraint-check", txn,
+				sessiondata.InternalExecutorOverride{User: security.RootUserName()},
+				query, args...,
+			)
+			if err != nil {
+				return err
+			}
+			if row != nil {
+				return p.errs[start+int(tree.MustBeDInt(row[0]))]
+			}
+		}
+	}
+	return nil
+}
+
+// recheckQuery returns a query, and its arguments, which produces the index
+// (in keyVals) of the first violation that still exists.
+//
+// For a foreign key, that is a violation for which some row of the origin table
+// has the violating key values and no row of the referenced table matches them.
+// The same query works for violations found by inserts into the origin table
+// and by deletions from the referenced table. The origin columns are compared
+// with IS NOT DISTINCT FROM so that a MATCH FULL violation involving NULLs is
+// found again; the referenced columns are compared with = since a key with
+// NULLs never has a match.
+//
+// For a unique constraint, that is a violation whose key values are those of
+// more than one row of the table. The key values of a uniqueness violation
+// never contain NULLs.
+//
+// NULL key values are written as literals, since their placeholders would have
+// no type.
+func (p *pendingChecks) recheckQuery(keyVals []tree.Datums) (string, []interface{}) {
+	var buf bytes.Buffer
+	numCols := len(p.check.Columns)
+	// writeTableRef writes a numeric table reference which names the
+	// constraint columns k1, k2, etc.
+	writeTableRef := func(tableID cat.StableID, colIDs []cat.StableID, alias string) {
+		fmt.Fprintf(&buf, "[%d(", tableID)
+		for i, id := range colIDs {
+			if i > 0 {
+				buf.WriteString(", ")
+			}
+			fmt.Fprintf(&buf, "%d", id)
+		}
+		fmt.Fprintf(&buf, ") AS %s(", alias)
+		for i := range colIDs {
+			if i > 0 {
+				buf.WriteString(", ")
+			}
+			fmt.Fprintf(&buf, "k%d", i+1)
+		}
+		buf.WriteString(")]")
+	}
+	// writeCond writes the comparison of the constraint columns of the given
+	// alias with those of the violation.
+	writeCond := func(alias, op string) {
+		for i := 0; i < numCols; i++ {
+			if i > 0 {
+				buf.WriteString(" AND ")
+			}
+			fmt.Fprintf(&buf, "%s.k%d %s v.k%d", alias, i+1, op, i+1)
+		}
+	}
+	var args []interface{}
+	buf.WriteString("SELECT v.i FROM (VALUES ")
+	for i, vals := range keyVals {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		fmt.Fprintf(&buf, "(%d", i)
+		for _, d := range vals {
+			if d == tree.DNull {
+				buf.WriteString(", NULL")
+			} else {
+				args = append(args, d)
+				fmt.Fprintf(&buf, ", $%d", len(args))
+			}
+		}
+		buf.WriteString(")")
+	}
+	buf.WriteString(") AS v(i")
+	for i := 0; i < numCols; i++ {
+		fmt.Fprintf(&buf, ", k%d", i+1)
+	}
+	if p.check.IsUnique() {
+		buf.WriteString(") WHERE (SELECT count(*) FROM ")
+		writeTableRef(p.check.Table, p.check.Columns, "c")
+		buf.WriteString(" WHERE ")
+		writeCond("c", "=")
+		buf.WriteString(") > 1 ORDER BY v.i LIMIT 1")
+		return buf.String(), args
+	}
+	buf.WriteString(") WHERE EXISTS (SELECT 1 FROM ")
+	writeTableRef(p.check.Table, p.check.Columns, "c")
+	buf.WriteString(" WHERE ")
+	writeCond("c", "IS NOT DISTINCT FROM")
+	buf.WriteString(") AND NOT EXISTS (SELECT 1 FROM ")
+	writeTableRef(p.check.ReferencedTable, p.check.ReferencedColumns, "p")
+	buf.WriteString(" WHERE ")
+	writeCond("p", "=")
+	buf.WriteString(") ORDER BY v.i LIMIT 1")
+	return buf.String(), args
+}
+
+// errDeferrablePartialUnique is returned for partial UNIQUE constraints marked
+// DEFERRABLE. The uniqueness checks of deferrable constraints do not take the
+// predicate of the constraint into account.
+var errDeferrablePartialUnique = pgerror.New(
+	pgcode.FeatureNotSupported, "partial UNIQUE constraints cannot be marked DEFERRABLE",
+)
+
+// SetConstraints implements the SET CONSTRAINTS statement.
+func (p *planner) SetConstraints(ctx context.Context, n *tree.SetConstraints) (planNode, error) {
+	for _, name := range n.Names {
+		row, err := p.ExecCfg().InternalExecutor.QueryRowEx(
+			ctx, "set-constraints", p.txn,
+			sessiondata.InternalExecutorOverride{
+				User:     security.RootUserName(),
+				Database: p.CurrentDatabase(),
+			},
+			`SELECT bool_or(condeferrable) FROM pg_catalog.pg_constraint WHERE conname = $1`,
+			string(name),
+		)
+		if err != nil {
+			return nil, err
+		}
+		if row == nil || row[0] == tree.DNull {
+			return nil, pgerror.Newf(pgcode.UndefinedObject,
+				"constraint %q does not exist", string(name))
+		}
+		if row[0] != tree.DBoolTrue {
+			return nil, pgerror.Newf(pgcode.WrongObjectType,
+				"constraint %q is not deferrable", string(name))
+		}
+	}
+	if p.deferredConstraints == nil || p.extendedEvalCtx.TxnImplicit {
+		p.BufferClientNotice(ctx, pgnotice.NewWithSeverityf(
+			"WARNING", "SET CONSTRAINTS can only be used in transaction blocks",
+		))
+		return newZeroNode(nil /* columns */), nil
+	}
+	immediate := p.deferredConstraints.setMode(n.Names, n.Deferred)
+	if err := recheckDeferredConstraints(ctx, p.ExecCfg().InternalExecutor, p.txn, immediate); err != nil {
+		return nil, err
+	}
+	return newZeroNode(nil /* columns */), nil
+}
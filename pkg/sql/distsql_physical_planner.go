@@ -3514,7 +3514,8 @@ func (dsp *DistSQLPlanner) createPlanForWindow(
 }
 
 // createPlanForExport creates a physical plan for EXPORT.
-// We add a new stage of CSVWriter processors to the input plan.
+// We add a new stage of CSVWriter or ParquetWriter processors to the input
+// plan.
 func (dsp *DistSQLPlanner) createPlanForExport(
 	planCtx *PlanningCtx, n *exportNode,
 ) (*PhysicalPlan, error) {
@@ -3523,13 +3524,55 @@ func (dsp *DistSQLPlanner) createPlanForExport(
 		return nil, err
 	}
 
-	core := execinfrapb.ProcessorCoreUnion{CSVWriter: &execinfrapb.CSVWriterSpec{
-		Destination:      n.destination,
-		NamePattern:      n.fileNamePattern,
-		Options:          n.csvOpts,
-		ChunkRows:        int64(n.chunkRows),
-		CompressionCodec: n.fileCompression,
-	}}
+	var core execinfrapb.ProcessorCoreUnion
+	switch n.format {
+	case exportFormatParquet:
+		core.ParquetWriter = &execinfrapb.ParquetWriterSpec{
+			Destination:      n.destination,
+			NamePattern:      n.fileNamePattern,
+			ColNames:         n.colNames,
+			ChunkRows:        int64(n.chunkRows),
+			CompressionCodec: n.fileCompression,
+		}
+	default:
+		core.CSVWriter = &execinfrapb.CSVWriterSpec{
+			Destination:      n.destination,
+			NamePattern:      n.fileNamePattern,
+			Options:          n.csvOpts,
+			ChunkRows:        int64(n.chunkRows),
+			CompressionCodec: n.fileCompression,
+			MaxFileSize:      n.maxFileSize,
+		}
+		if len(n.partitionCols) > 0 {
+			core.CSVWriter.ColNames = n.colNames
+			for _, ord := range n.partitionCols {
+				core.CSVWriter.PartitionCols = append(core.CSVWriter.PartitionCols, uint32(ord))
+			}
+			// The writers write one partition at a time, so their input is
+			// sorted on the partition columns. The rows of a partition keep the
+			// order of the query.
+			partitionOrdering := make(colinfo.ColumnOrdering, len(n.partitionCols))
+			for i, ord := range n.partitionCols {
+				partitionOrdering[i] = colinfo.ColumnOrderInfo{ColIdx: ord, Direction: encoding.Ascending}
+			}
+			ordering := execinfrapb.ConvertToMappedSpecOrdering(partitionOrdering, plan.PlanToStreamColMap)
+			var sorted util.FastIntSet
+			for _, c := range ordering.Columns {
+				sorted.Add(int(c.ColIdx))
+			}
+			for _, c := range plan.MergeOrdering.Columns {
+				if !sorted.Contains(int(c.ColIdx)) {
+					ordering.Columns = append(ordering.Columns, c)
+				}
+			}
+			plan.AddNoGroupingStage(
+				execinfrapb.ProcessorCoreUnion{Sorter: &execinfrapb.SorterSpec{OutputOrdering: ordering}},
+				execinfrapb.PostProcessSpec{},
+				plan.GetResultTypes(),
+				ordering,
+			)
+		}
+	}
 
 	resTypes := make([]*types.T, len(colinfo.ExportColumns))
 	for i := range colinfo.ExportColumns {
@@ -3539,7 +3582,7 @@ func (dsp *DistSQLPlanner) createPlanForExport(
 		core, execinfrapb.PostProcessSpec{}, resTypes, execinfrapb.Ordering{},
 	)
 
-	// The CSVWriter produces the same columns as the EXPORT statement.
+	// The writer produces the same columns as the EXPORT statement.
 	plan.PlanToStreamColMap = identityMap(plan.PlanToStreamColMap, len(colinfo.ExportColumns))
 	return plan, nil
 }
@@ -31,6 +31,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
 	"github.com/cockroachdb/logtags"
 )
 
@@ -71,36 +72,40 @@ func (b *RowResultWriter) Err() error {
 	return b.err
 }
 
-// callbackResultWriter is a rowResultWriter that runs a callback function
+// CallbackResultWriter is a rowResultWriter that runs a callback function
 // on AddRow.
-type callbackResultWriter struct {
+type CallbackResultWriter struct {
 	fn           func(ctx context.Context, row tree.Datums) error
 	rowsAffected int
 	err          error
 }
 
-var _ rowResultWriter = &callbackResultWriter{}
+var _ rowResultWriter = &CallbackResultWriter{}
 
-// newCallbackResultWriter creates a new callbackResultWriter.
-func newCallbackResultWriter(
+// NewCallbackResultWriter creates a new CallbackResultWriter.
+func NewCallbackResultWriter(
 	fn func(ctx context.Context, row tree.Datums) error,
-) *callbackResultWriter {
-	return &callbackResultWriter{fn: fn}
+) *CallbackResultWriter {
+	return &CallbackResultWriter{fn: fn}
 }
 
-func (c *callbackResultWriter) IncrementRowsAffected(n int) {
+// IncrementRowsAffected is part of the rowResultWriter interface.
+func (c *CallbackResultWriter) IncrementRowsAffected(n int) {
 	c.rowsAffected += n
 }
 
-func (c *callbackResultWriter) AddRow(ctx context.Context, row tree.Datums) error {
+// AddRow is part of the rowResultWriter interface.
+func (c *CallbackResultWriter) AddRow(ctx context.Context, row tree.Datums) error {
 	return c.fn(ctx, row)
 }
 
-func (c *callbackResultWriter) SetError(err error) {
+// SetError is part of the rowResultWriter interface.
+func (c *CallbackResultWriter) SetError(err error) {
 	c.err = err
 }
 
-func (c *callbackResultWriter) Err() error {
+// Err is part of the rowResultWriter interface.
+func (c *CallbackResultWriter) Err() error {
 	return c.err
 }
 
@@ -132,6 +137,7 @@ func makeImportReaderSpecs(
 				WalltimeNanos: walltime,
 				Uri:           make(map[int32]string),
 				ResumePos:     make(map[int32]int64),
+				ResumeOffset:  make(map[int32]int64),
 				UserProto:     user.EncodeProto(),
 			}
 			inputSpecs = append(inputSpecs, spec)
@@ -141,6 +147,9 @@ func makeImportReaderSpecs(
 		if importProgress.ResumePos != nil {
 			inputSpecs[n].ResumePos[int32(i)] = importProgress.ResumePos[int32(i)]
 		}
+		if len(importProgress.ResumeOffset) > i {
+			inputSpecs[n].ResumeOffset[int32(i)] = importProgress.ResumeOffset[i]
+		}
 	}
 
 	for i := range inputSpecs {
@@ -181,6 +190,10 @@ func presplitTableBoundaries(
 // reader processes on many nodes that each read and ingest their assigned files
 // and then send back a summary of what they ingested. The combined summary is
 // returned.
+//
+// In a dry run, the readers also send back the keys in unique indexes of the
+// rows they read, and dryRunKeyFn is called with each of them, in sorted order
+// across all the readers.
 func DistIngest(
 	ctx context.Context,
 	execCtx JobExecContext,
@@ -190,6 +203,7 @@ func DistIngest(
 	format roachpb.IOFileFormat,
 	walltime int64,
 	alwaysFlushProgress bool,
+	dryRunKeyFn func(ctx context.Context, key []byte) error,
 ) (roachpb.BulkOpSummary, error) {
 	ctx = logtags.AddTag(ctx, "import-distsql-ingest", nil)
 
@@ -200,7 +214,6 @@ func DistIngest(
 	if err != nil {
 		return roachpb.BulkOpSummary{}, err
 	}
-
 	inputSpecs := makeImportReaderSpecs(job, tables, from, format, nodes, walltime, execCtx.User())
 
 	p := planCtx.NewPhysicalPlan()
@@ -211,12 +224,20 @@ func DistIngest(
 		corePlacement[i].NodeID = nodes[i]
 		corePlacement[i].Core.ReadImport = inputSpecs[i]
 	}
+	// The readers of a dry run emit their keys sorted on the second column, and
+	// their summary before them with an empty second column.
+	var ordering execinfrapb.Ordering
+	if format.DryRunReport != "" {
+		ordering.Columns = []execinfrapb.Ordering_Column{
+			{ColIdx: 1, Direction: execinfrapb.Ordering_Column_ASC},
+		}
+	}
 	p.AddNoInputStage(
 		corePlacement,
 		execinfrapb.PostProcessSpec{},
 		// The direct-ingest readers will emit a binary encoded BulkOpSummary.
 		[]*types.T{types.Bytes, types.Bytes},
-		execinfrapb.Ordering{},
+		ordering,
 	)
 
 	p.PlanToStreamColMap = []int{0, 1}
@@ -228,13 +249,18 @@ func DistIngest(
 			prog := details.(*jobspb.Progress_Import).Import
 			prog.ReadProgress = make([]float32, len(from))
 			prog.ResumePos = make([]int64, len(from))
+			prog.ResumeOffset = make([]int64, len(from))
 			return 0.0
 		},
 	); err != nil {
 		return roachpb.BulkOpSummary{}, err
 	}
 
+	// The row and offset from which each file can be resumed are updated
+	// together under resumeMu, so that they always match.
+	var resumeMu syncutil.Mutex
 	rowProgress := make([]int64, len(from))
+	offsetProgress := make([]int64, len(from))
 	fractionProgress := make([]uint32, len(from))
 
 	updateJobProgress := func() error {
@@ -242,9 +268,10 @@ func DistIngest(
 			func(ctx context.Context, details jobspb.ProgressDetails) float32 {
 				var overall float32
 				prog := details.(*jobspb.Progress_Import).Import
-				for i := range rowProgress {
-					prog.ResumePos[i] = atomic.LoadInt64(&rowProgress[i])
-				}
+				resumeMu.Lock()
+				copy(prog.ResumePos, rowProgress)
+				copy(prog.ResumeOffset, offsetProgress)
+				resumeMu.Unlock()
 				for i := range fractionProgress {
 					fileProgress := math.Float32frombits(atomic.LoadUint32(&fractionProgress[i]))
 					prog.ReadProgress[i] = fileProgress
@@ -257,9 +284,12 @@ func DistIngest(
 
 	metaFn := func(_ context.Context, meta *execinfrapb.ProducerMetadata) error {
 		if meta.BulkProcessorProgress != nil {
+			resumeMu.Lock()
 			for i, v := range meta.BulkProcessorProgress.ResumePos {
-				atomic.StoreInt64(&rowProgress[i], v)
+				rowProgress[i] = v
+				offsetProgress[i] = meta.BulkProcessorProgress.ResumeOffset[i]
 			}
+			resumeMu.Unlock()
 			for i, v := range meta.BulkProcessorProgress.CompletedFraction {
 				atomic.StoreUint32(&fractionProgress[i], math.Float32bits(v))
 			}
@@ -272,7 +302,10 @@ func DistIngest(
 	}
 
 	var res roachpb.BulkOpSummary
-	rowResultWriter := newCallbackResultWriter(func(ctx context.Context, row tree.Datums) error {
+	rowResultWriter := NewCallbackResultWriter(func(ctx context.Context, row tree.Datums) error {
+		if key := []byte(*row[1].(*tree.DBytes)); len(key) > 0 {
+			return dryRunKeyFn(ctx, key)
+		}
 		var counts roachpb.BulkOpSummary
 		if err := protoutil.Unmarshal([]byte(*row[0].(*tree.DBytes)), &counts); err != nil {
 			return err
@@ -1134,9 +1134,10 @@ func (dsp *DistSQLPlanner) planAndRunPostquery(
 	dsp.FinalizePlan(postqueryPlanCtx, postqueryPhysPlan)
 
 	postqueryRecv := recv.clone()
-	// TODO(yuzefovich): at the moment, errOnlyResultWriter is sufficient here,
-	// but it may not be the case when we support cascades through the optimizer.
+	// Postqueries are only run for their side effects; the rows produced by
+	// some of them (e.g. AFTER triggers) are discarded.
 	postqueryRecv.resultWriter = &errOnlyResultWriter{}
+	postqueryRecv.discardRows = true
 	dsp.Run(postqueryPlanCtx, planner.txn, postqueryPhysPlan, postqueryRecv, evalCtx, nil /* finishedSetupFn */)()
 	if postqueryRecv.commErr != nil {
 		return postqueryRecv.commErr
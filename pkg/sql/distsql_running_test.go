@@ -133,7 +133,7 @@ func TestDistSQLRunningInAbortedTxn(t *testing.T) {
 		}
 
 		// Create and run a DistSQL plan.
-		rw := newCallbackResultWriter(func(ctx context.Context, row tree.Datums) error {
+		rw := NewCallbackResultWriter(func(ctx context.Context, row tree.Datums) error {
 			return nil
 		})
 		recv := MakeDistSQLReceiver(
@@ -197,7 +197,7 @@ func TestDistSQLReceiverErrorRanking(t *testing.T) {
 	txn := kv.NewTxn(ctx, db, s.NodeID())
 
 	// We're going to use a rowResultWriter to which only errors will be passed.
-	rw := newCallbackResultWriter(nil /* fn */)
+	rw := NewCallbackResultWriter(nil /* fn */)
 	recv := MakeDistSQLReceiver(
 		ctx,
 		rw,
@@ -24,6 +24,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/catalogkv"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/funcdesc"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/typedesc"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
@@ -157,6 +158,12 @@ func ExamineDescriptors(
 				problemsFound = true
 				fmt.Fprint(stdout, reportMsg(desc, "%s", err))
 			}
+		case catalog.FunctionDescriptor:
+			fn := funcdesc.NewImmutable(*d.FunctionDesc())
+			if err := fn.Validate(ctx, descGetter); err != nil {
+				problemsFound = true
+				fmt.Fprint(stdout, reportMsg(desc, "%s", err))
+			}
 		case catalog.SchemaDescriptor:
 			// parent schema id is always 0.
 			parentSchemaExists = true
@@ -318,6 +325,8 @@ func reportMsg(desc catalog.Descriptor, format string, args ...interface{}) stri
 	switch desc.(type) {
 	case catalog.TypeDescriptor:
 		header = "    Type"
+	case catalog.FunctionDescriptor:
+		header = "Function"
 	case catalog.TableDescriptor:
 		header = "   Table"
 	case catalog.SchemaDescriptor:
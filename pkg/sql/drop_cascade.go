@@ -17,6 +17,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/dbdesc"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/funcdesc"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/resolver"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/tabledesc"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/typedesc"
@@ -34,6 +35,7 @@ type dropCascadeState struct {
 	td                      []toDelete
 	allTableObjectsToDelete []*tabledesc.Mutable
 	typesToDelete           []*typedesc.Mutable
+	functionsToDelete       []functionToDrop
 
 	droppedNames []string
 }
@@ -134,8 +136,49 @@ func (d *dropCascadeState) resolveCollectedObjects(
 			}
 			d.td = append(d.td, toDelete{objName, tbDesc})
 		} else {
-			// If we couldn't resolve objName as a table, try a type.
+			// If we couldn't resolve objName as a table, try a function.
 			found, desc, err := p.LookupObject(
+				ctx,
+				tree.ObjectLookupFlags{
+					CommonLookupFlags: tree.CommonLookupFlags{
+						Required:       false,
+						RequireMutable: true,
+						IncludeOffline: true,
+					},
+					DesiredObjectKind: tree.FunctionObject,
+				},
+				objName.Catalog(),
+				objName.Schema(),
+				objName.Object(),
+			)
+			if err != nil {
+				return err
+			}
+			if found {
+				fnDesc, ok := desc.(*funcdesc.Mutable)
+				if !ok {
+					return errors.AssertionFailedf(
+						"descriptor for %q is not Mutable",
+						objName.Object(),
+					)
+				}
+				if err := p.canModifyFunction(ctx, fnDesc); err != nil {
+					return err
+				}
+				// Recursively check permissions on all dependent functions, since
+				// some may be in different schemas.
+				if err := p.canRemoveDependentFunctions(
+					ctx, "function", fnDesc.Name, fnDesc.DependedOnBy, tree.DropCascade,
+				); err != nil {
+					return err
+				}
+				fnName := tree.MakeQualifiedFunctionName(objName.Catalog(), objName.Schema(), objName.Object())
+				d.functionsToDelete = append(d.functionsToDelete, functionToDrop{name: &fnName, desc: fnDesc})
+				continue
+			}
+
+			// Otherwise, try a type.
+			found, desc, err = p.LookupObject(
 				ctx,
 				tree.ObjectLookupFlags{
 					CommonLookupFlags: tree.CommonLookupFlags{
@@ -201,6 +244,21 @@ func (d *dropCascadeState) dropAllCollectedObjects(ctx context.Context, p *plann
 		d.droppedNames = append(d.droppedNames, toDel.tn.FQString())
 	}
 
+	// Delete all of the collected functions which have not been dropped along
+	// with the tables they depend on.
+	for _, toDel := range d.functionsToDelete {
+		fnDesc, err := p.Descriptors().GetMutableFunctionVersionByID(ctx, p.txn, toDel.desc.ID)
+		if err != nil {
+			return err
+		}
+		if !fnDesc.Dropped() {
+			if err := p.dropFunctionImpl(ctx, fnDesc, "dropping function "+toDel.name.FQString()); err != nil {
+				return err
+			}
+		}
+		d.droppedNames = append(d.droppedNames, toDel.name.FQString())
+	}
+
 	// Now delete all of the types.
 	for _, typ := range d.typesToDelete {
 		// Drop the types. Note that we set queueJob to be false because the types
@@ -0,0 +1,457 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sql
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cockroachdb/cockroach/pkg/server/telemetry"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/funcdesc"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/resolver"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/tabledesc"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlerrors"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqltelemetry"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/errors"
+)
+
+type dropFunctionNode struct {
+	n *tree.DropFunction
+	// toDrop contains the functions to drop, including the functions that
+	// depend on them if the drop behavior is CASCADE, along with their fully
+	// qualified names.
+	toDrop []functionToDrop
+	ids    map[descpb.ID]struct{}
+}
+
+type functionToDrop struct {
+	name *tree.FunctionName
+	desc *funcdesc.Mutable
+}
+
+// Use to satisfy the linter.
+var _ planNode = &dropFunctionNode{n: nil}
+
+// DropFunction drops user-defined functions.
+// Privileges: ownership of the function.
+func (p *planner) DropFunction(ctx context.Context, n *tree.DropFunction) (planNode, error) {
+	node := &dropFunctionNode{n: n, ids: make(map[descpb.ID]struct{})}
+	for i := range n.Functions {
+		fnName, fn, err := p.resolveFunctionForDrop(ctx, &n.Functions[i], !n.IfExists)
+		if err != nil {
+			return nil, err
+		}
+		if fn == nil {
+			continue
+		}
+		if err := node.collect(ctx, p, fnName, fn); err != nil {
+			return nil, err
+		}
+	}
+
+	// Unless CASCADE was specified, the dropped functions may only be depended
+	// on by functions which are dropped as well.
+	if n.DropBehavior != tree.DropCascade {
+		for _, toDrop := range node.toDrop {
+			var dependents []descpb.ID
+			for _, id := range toDrop.desc.DependedOnBy {
+				if _, ok := node.ids[id]; !ok {
+					dependents = append(dependents, id)
+				}
+			}
+			if err := p.canRemoveDependentFunctions(
+				ctx, "function", toDrop.name.String(), dependents, n.DropBehavior,
+			); err != nil {
+				return nil, err
+			}
+			if err := p.checkNoTriggersCallFunction(ctx, toDrop.desc, toDrop.name); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if len(node.toDrop) == 0 {
+		return newZeroNode(nil /* columns */), nil
+	}
+	return node, nil
+}
+
+// collect adds the given function to the functions to drop. If the drop
+// behavior is CASCADE, the functions which depend on it are added as well.
+func (n *dropFunctionNode) collect(
+	ctx context.Context, p *planner, fnName *tree.FunctionName, fn *funcdesc.Mutable,
+) error {
+	if _, ok := n.ids[fn.ID]; ok {
+		return nil
+	}
+	if err := p.canModifyFunction(ctx, fn); err != nil {
+		return err
+	}
+	n.ids[fn.ID] = struct{}{}
+	n.toDrop = append(n.toDrop, functionToDrop{name: fnName, desc: fn})
+	if n.n.DropBehavior != tree.DropCascade {
+		return nil
+	}
+	for _, id := range fn.DependedOnBy {
+		dependent, err := p.Descriptors().GetMutableFunctionVersionByID(ctx, p.txn, id)
+		if err != nil {
+			return err
+		}
+		if dependent.Dropped() {
+			continue
+		}
+		dependentName, err := p.getFunctionName(ctx, dependent)
+		if err != nil {
+			return err
+		}
+		if err := n.collect(ctx, p, dependentName, dependent); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// resolveFunctionForDrop resolves the function named in a DROP FUNCTION
+// statement. If an argument list is given, it must match the arguments of the
+// function.
+func (p *planner) resolveFunctionForDrop(
+	ctx context.Context, fo *tree.FuncObj, required bool,
+) (*tree.FunctionName, *funcdesc.Mutable, error) {
+	fnName, fn, err := resolver.ResolveMutableFunction(ctx, p, fo.FuncName, required)
+	if err != nil || fn == nil || fo.Args == nil {
+		return fnName, fn, err
+	}
+	matches := len(fo.Args) == len(fn.Args)
+	for i := 0; matches && i < len(fo.Args); i++ {
+		typ, err := tree.ResolveType(ctx, fo.Args[i].Type, p.semaCtx.GetTypeResolver())
+		if err != nil {
+			return nil, nil, err
+		}
+		matches = typ.Equivalent(fn.Args[i].Type)
+	}
+	if !matches {
+		if required {
+			return nil, nil, pgerror.Newf(pgcode.UndefinedFunction,
+				"function %s does not exist", tree.AsString(fo))
+		}
+		return nil, nil, nil
+	}
+	return fnName, fn, nil
+}
+
+// canModifyFunction returns an error if the user cannot modify or drop the
+// given function.
+func (p *planner) canModifyFunction(ctx context.Context, desc *funcdesc.Mutable) error {
+	hasAdmin, err := p.HasAdminRole(ctx)
+	if err != nil {
+		return err
+	}
+	if hasAdmin {
+		return nil
+	}
+
+	hasOwnership, err := p.HasOwnership(ctx, desc)
+	if err != nil {
+		return err
+	}
+	if !hasOwnership {
+		return pgerror.Newf(pgcode.InsufficientPrivilege,
+			"must be owner of function %s", tree.Name(desc.GetName()))
+	}
+	return nil
+}
+
+// ReadingOwnWrites implements the planNodeReadingOwnWrites interface.
+// This is because DROP FUNCTION performs multiple KV operations on descriptors
+// and expects to see its own writes.
+func (n *dropFunctionNode) ReadingOwnWrites() {}
+
+func (n *dropFunctionNode) startExec(params runParams) error {
+	telemetry.Inc(sqltelemetry.SchemaChangeDropCounter("function"))
+
+	for _, toDrop := range n.toDrop {
+		// Dependent functions may already have been dropped along with the
+		// functions they depend on, so the descriptor is looked up again.
+		fn, err := params.p.Descriptors().GetMutableFunctionVersionByID(params.ctx, params.p.txn, toDrop.desc.ID)
+		if err != nil {
+			return err
+		}
+		if !fn.Dropped() {
+			if err := params.p.dropFunctionImpl(
+				params.ctx, fn, tree.AsStringWithFQNames(n.n, params.Ann()),
+			); err != nil {
+				return err
+			}
+		}
+		// Log a Drop Function event. This is an auditable log event and is
+		// recorded in the same transaction as the function descriptor update.
+		if err := MakeEventLogger(params.extendedEvalCtx.ExecCfg).InsertEventRecord(
+			params.ctx,
+			params.p.txn,
+			EventLogDropFunction,
+			int32(toDrop.desc.ID),
+			int32(params.extendedEvalCtx.NodeID.SQLInstanceID()),
+			struct {
+				FunctionName string
+				Statement    string
+				User         string
+			}{toDrop.name.FQString(), tree.AsStringWithFQNames(n.n, params.Ann()), params.p.User().Normalized()},
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (*dropFunctionNode) Next(runParams) (bool, error) { return false, nil }
+func (*dropFunctionNode) Values() tree.Datums          { return tree.Datums{} }
+func (*dropFunctionNode) Close(context.Context)        {}
+
+// dropFunctionImpl does the work of dropping a function, along with the
+// functions that depend on it. The back-references to the function are
+// removed, and the descriptor is marked as dropped; it is deleted by the
+// queued schema change job once the name of the function has been drained.
+func (p *planner) dropFunctionImpl(ctx context.Context, fn *funcdesc.Mutable, jobDesc string) error {
+	if fn.Dropped() {
+		return errors.Errorf("function %q is already being dropped", fn.Name)
+	}
+	// Mark the function as dropped first, so that the dependent functions do
+	// not update its back-references.
+	fn.SetDropped()
+	if err := p.writeFunctionDesc(ctx, fn); err != nil {
+		return err
+	}
+
+	// Drop the dependent functions. The caller is responsible for checking
+	// that this is allowed.
+	if err := p.dropDependentFunctions(ctx, fn.DependedOnBy, "dropping dependent function"); err != nil {
+		return err
+	}
+	fn.DependedOnBy = nil
+
+	// Drop the triggers which call the function. The caller is responsible for
+	// checking that this is allowed.
+	for _, tableID := range fn.DependedOnByTables {
+		tableDesc, err := p.Descriptors().GetMutableTableVersionByID(ctx, tableID, p.txn)
+		if err != nil {
+			return errors.Wrapf(err, "error resolving dependent table ID %d", tableID)
+		}
+		if tableDesc.Dropped() {
+			continue
+		}
+		if err := p.removeTriggers(ctx, tableDesc, func(trig *descpb.TableDescriptor_Trigger) bool {
+			return trig.FunctionID == fn.ID
+		}); err != nil {
+			return err
+		}
+		if err := p.writeSchemaChange(
+			ctx, tableDesc, descpb.InvalidMutationID,
+			fmt.Sprintf("dropping triggers calling function %s from table %s(%d)",
+				fn.Name, tableDesc.Name, tableDesc.ID),
+		); err != nil {
+			return err
+		}
+	}
+	fn.DependedOnByTables = nil
+
+	// Remove the back-references from the tables and functions which this
+	// function depends on.
+	for _, depID := range fn.DependsOn {
+		dep, err := p.Descriptors().GetMutableDescriptorByID(ctx, depID, p.txn)
+		if err != nil {
+			return errors.Wrapf(err, "error resolving dependency ID %d", depID)
+		}
+		// The dependency may also be getting dropped, in which case the
+		// references don't need to be removed.
+		if dep.Dropped() {
+			continue
+		}
+		switch t := dep.(type) {
+		case *tabledesc.Mutable:
+			t.DependedOnByFunctions = removeMatchingReferences(t.DependedOnByFunctions, fn.ID)
+			if err := p.writeSchemaChange(
+				ctx, t, descpb.InvalidMutationID,
+				fmt.Sprintf("removing references for function %s from table %s(%d)",
+					fn.Name, t.Name, t.ID),
+			); err != nil {
+				return err
+			}
+		case *funcdesc.Mutable:
+			t.RemoveDependedOnBy(fn.ID)
+			if err := p.writeFunctionDesc(ctx, t); err != nil {
+				return err
+			}
+		default:
+			return errors.AssertionFailedf("unexpected dependency %d of function %q: %T", depID, fn.Name, dep)
+		}
+	}
+	fn.DependsOn = nil
+
+	fn.AddDrainingName(descpb.NameInfo{
+		ParentID:       fn.ParentID,
+		ParentSchemaID: fn.ParentSchemaID,
+		Name:           fn.Name,
+	})
+	return p.writeFunctionDescChange(ctx, fn, jobDesc)
+}
+
+// canRemoveDependentFunctions returns an error if the given functions, which
+// depend on the object being dropped, cannot be dropped along with it.
+func (p *planner) canRemoveDependentFunctions(
+	ctx context.Context, typeName, objName string, fnIDs []descpb.ID, behavior tree.DropBehavior,
+) error {
+	for _, id := range fnIDs {
+		fn, err := p.Descriptors().GetMutableFunctionVersionByID(ctx, p.txn, id)
+		if err != nil {
+			return err
+		}
+		if fn.Dropped() {
+			continue
+		}
+		if behavior != tree.DropCascade {
+			return p.dependentFunctionError(ctx, typeName, objName, fn, "drop")
+		}
+		if err := p.canModifyFunction(ctx, fn); err != nil {
+			return err
+		}
+		// The functions which depend on the function are dropped as well.
+		if err := p.canRemoveDependentFunctions(
+			ctx, "function", fn.Name, fn.DependedOnBy, behavior,
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// dropDependentFunctions drops the given functions, which depend on an object
+// being dropped, if they have not been dropped yet.
+func (p *planner) dropDependentFunctions(
+	ctx context.Context, fnIDs []descpb.ID, jobDesc string,
+) error {
+	for _, id := range fnIDs {
+		fn, err := p.Descriptors().GetMutableFunctionVersionByID(ctx, p.txn, id)
+		if err != nil {
+			return err
+		}
+		if fn.Dropped() {
+			continue
+		}
+		if err := p.dropFunctionImpl(ctx, fn, jobDesc); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// dependentFunctionError returns an error for an operation which is not
+// allowed on an object because the given function depends on it.
+func (p *planner) dependentFunctionError(
+	ctx context.Context, typeName, objName string, fn *funcdesc.Mutable, op string,
+) error {
+	fnName, err := p.getFunctionName(ctx, fn)
+	if err != nil {
+		log.Warningf(ctx, "unable to retrieve name of function %d: %v", fn.ID, err)
+		return sqlerrors.NewDependentObjectErrorf(
+			"cannot %s %s %q because a function depends on it",
+			op, typeName, objName)
+	}
+	return errors.WithHintf(
+		sqlerrors.NewDependentObjectErrorf("cannot %s %s %q because function %q depends on it",
+			op, typeName, objName, fnName.FQString()),
+		"you can drop %s instead.", fnName.FQString())
+}
+
+// checkNoTriggersCallFunction returns an error if the given function is called
+// by any trigger.
+func (p *planner) checkNoTriggersCallFunction(
+	ctx context.Context, fn *funcdesc.Mutable, fnName *tree.FunctionName,
+) error {
+	for _, tableID := range fn.DependedOnByTables {
+		tableDesc, err := p.Descriptors().GetMutableTableVersionByID(ctx, tableID, p.txn)
+		if err != nil {
+			return errors.Wrapf(err, "error resolving dependent table ID %d", tableID)
+		}
+		if tableDesc.Dropped() {
+			continue
+		}
+		for i := range tableDesc.Triggers {
+			if trig := &tableDesc.Triggers[i]; trig.FunctionID == fn.ID {
+				return errors.WithHintf(
+					sqlerrors.NewDependentObjectErrorf(
+						"cannot drop function %q because trigger %q on relation %q depends on it",
+						fnName.FQString(), trig.Name, tableDesc.Name),
+					"you can drop the trigger instead.")
+			}
+		}
+	}
+	return nil
+}
+
+// checkNoDependentFunctions returns an error if any of the functions which
+// depend on the given table satisfy the given predicate. This is used to
+// disallow operations which would invalidate the body of the functions, which
+// refers to the table and its columns by name.
+func (p *planner) checkNoDependentFunctions(
+	ctx context.Context,
+	desc *tabledesc.Mutable,
+	typeName, objName, op string,
+	pred func(ref *descpb.TableDescriptor_Reference) bool,
+) error {
+	for i := range desc.DependedOnByFunctions {
+		ref := &desc.DependedOnByFunctions[i]
+		if pred != nil && !pred(ref) {
+			continue
+		}
+		fn, err := p.Descriptors().GetMutableFunctionVersionByID(ctx, p.txn, ref.ID)
+		if err != nil {
+			return err
+		}
+		if fn.Dropped() {
+			continue
+		}
+		return p.dependentFunctionError(ctx, typeName, objName, fn, op)
+	}
+	return nil
+}
+
+// functionIDs returns the IDs of the functions which depend on the given
+// table and satisfy the given predicate, if any.
+func functionIDs(
+	desc *tabledesc.Mutable, pred func(ref *descpb.TableDescriptor_Reference) bool,
+) []descpb.ID {
+	var ids []descpb.ID
+	for i := range desc.DependedOnByFunctions {
+		ref := &desc.DependedOnByFunctions[i]
+		if pred == nil || pred(ref) {
+			ids = append(ids, ref.ID)
+		}
+	}
+	return ids
+}
+
+// refersToColumn returns a predicate for checkNoDependentFunctions and
+// functionIDs which matches the references to the given column.
+func refersToColumn(colID descpb.ColumnID) func(ref *descpb.TableDescriptor_Reference) bool {
+	return func(ref *descpb.TableDescriptor_Reference) bool {
+		for _, id := range ref.ColumnIDs {
+			if id == colID {
+				return true
+			}
+		}
+		return false
+	}
+}
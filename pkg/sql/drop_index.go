@@ -250,7 +250,7 @@ func (p *planner) dropIndexByName(
 		return nil
 	}
 
-	if idx.Unique && behavior != tree.DropCascade && constraintBehavior != ignoreIdxConstraint && !idx.CreatedExplicitly {
+	if (idx.Unique || idx.DeferrableUnique) && behavior != tree.DropCascade && constraintBehavior != ignoreIdxConstraint && !idx.CreatedExplicitly {
 		return errors.WithHint(
 			pgerror.Newf(pgcode.DependentObjectsStillExist,
 				"index %q is in use as unique constraint", idx.Name),
@@ -436,6 +436,22 @@ func (p *planner) dropIndexByName(
 		}
 	}
 
+	// The same goes for functions.
+	fnIDs := functionIDs(tableDesc, func(ref *descpb.TableDescriptor_Reference) bool {
+		return ref.IndexID == idx.ID
+	})
+	if err := p.canRemoveDependentFunctions(ctx, "index", idx.Name, fnIDs, behavior); err != nil {
+		return err
+	}
+	if err := p.dropDependentFunctions(
+		ctx, fnIDs, fmt.Sprintf("removing function dependent on index %q which is being dropped", idx.Name),
+	); err != nil {
+		return err
+	}
+	for _, id := range fnIDs {
+		tableDesc.DependedOnByFunctions = removeMatchingReferences(tableDesc.DependedOnByFunctions, id)
+	}
+
 	// Overwriting tableDesc.Index may mess up with the idx object we collected above. Make a copy.
 	idxCopy := *idx
 	idx = &idxCopy
@@ -47,6 +47,11 @@ func (p *planner) DropSequence(ctx context.Context, n *tree.DropSequence) (planN
 		if depErr := p.sequenceDependencyError(ctx, droppedDesc); depErr != nil {
 			return nil, depErr
 		}
+		if err := p.canRemoveDependentFunctions(
+			ctx, droppedDesc.TypeName(), droppedDesc.Name, functionIDs(droppedDesc, nil /* pred */), n.DropBehavior,
+		); err != nil {
+			return nil, err
+		}
 
 		td = append(td, toDelete{tn, droppedDesc})
 	}
@@ -113,6 +118,12 @@ func (p *planner) dropSequenceImpl(
 	if err := removeSequenceOwnerIfExists(ctx, p, seqDesc.ID, seqDesc.GetSequenceOpts()); err != nil {
 		return err
 	}
+	if err := p.dropDependentFunctions(
+		ctx, functionIDs(seqDesc, nil /* pred */), "dropping dependent function",
+	); err != nil {
+		return err
+	}
+	seqDesc.DependedOnByFunctions = nil
 	return p.initiateDropTable(ctx, seqDesc, queueJob, jobDesc, true /* drainName */)
 }
 
@@ -87,6 +87,11 @@ func (p *planner) DropTable(ctx context.Context, n *tree.DropTable) (planNode, e
 				}
 			}
 		}
+		if err := p.canRemoveDependentFunctions(
+			ctx, droppedDesc.TypeName(), droppedDesc.Name, functionIDs(droppedDesc, nil /* pred */), n.DropBehavior,
+		); err != nil {
+			return nil, err
+		}
 		if err := p.canRemoveAllTableOwnedSequences(ctx, droppedDesc, n.DropBehavior); err != nil {
 			return nil, err
 		}
@@ -336,6 +341,22 @@ func (p *planner) dropTableImpl(
 		droppedViews = append(droppedViews, viewDesc.Name)
 	}
 
+	// Drop all functions that depend on this table.
+	if err := p.dropDependentFunctions(
+		ctx, functionIDs(tableDesc, nil /* pred */), "dropping dependent function",
+	); err != nil {
+		return droppedViews, err
+	}
+	tableDesc.DependedOnByFunctions = nil
+
+	// Drop the triggers on this table, removing the back-references from the
+	// functions they call.
+	if err := p.removeTriggers(ctx, tableDesc, func(*descpb.TableDescriptor_Trigger) bool {
+		return true
+	}); err != nil {
+		return droppedViews, err
+	}
+
 	err := p.removeTableComments(ctx, tableDesc)
 	if err != nil {
 		return droppedViews, err
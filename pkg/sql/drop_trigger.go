@@ -0,0 +1,179 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sql
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/server/telemetry"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/tabledesc"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlerrors"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqltelemetry"
+	"github.com/cockroachdb/errors"
+)
+
+type dropTriggerNode struct {
+	n         *tree.DropTrigger
+	tableDesc *tabledesc.Mutable
+}
+
+// DropTrigger drops a trigger.
+// Privileges: CREATE on table.
+//   Notes: postgres requires ownership of the table.
+func (p *planner) DropTrigger(ctx context.Context, n *tree.DropTrigger) (planNode, error) {
+	tn := n.Table.ToTableName()
+	tableDesc, err := p.ResolveMutableTableDescriptor(ctx, &tn, !n.IfExists, tree.ResolveRequireTableDesc)
+	if err != nil {
+		return nil, err
+	}
+	if tableDesc == nil {
+		return newZeroNode(nil /* columns */), nil
+	}
+	if err := p.CheckPrivilege(ctx, tableDesc, privilege.CREATE); err != nil {
+		return nil, err
+	}
+	if _, ok := tableDesc.FindTriggerByName(string(n.Name)); !ok {
+		if n.IfExists {
+			return newZeroNode(nil /* columns */), nil
+		}
+		return nil, pgerror.Newf(pgcode.UndefinedObject,
+			"trigger %q for table %q does not exist", n.Name, tableDesc.Name)
+	}
+	return &dropTriggerNode{n: n, tableDesc: tableDesc}, nil
+}
+
+// ReadingOwnWrites implements the planNodeReadingOwnWrites interface.
+// This is because DROP TRIGGER performs multiple KV operations on descriptors
+// and expects to see its own writes.
+func (n *dropTriggerNode) ReadingOwnWrites() {}
+
+func (n *dropTriggerNode) startExec(params runParams) error {
+	telemetry.Inc(sqltelemetry.SchemaChangeDropCounter("trigger"))
+
+	p := params.p
+	if err := p.removeTriggers(
+		params.ctx, n.tableDesc, func(trig *descpb.TableDescriptor_Trigger) bool {
+			return trig.Name == string(n.n.Name)
+		},
+	); err != nil {
+		return err
+	}
+	if err := p.writeSchemaChange(
+		params.ctx, n.tableDesc, descpb.InvalidMutationID, tree.AsStringWithFQNames(n.n, params.Ann()),
+	); err != nil {
+		return err
+	}
+
+	// Log Drop Trigger event. This is an auditable log event and is recorded
+	// in the same transaction as the table descriptor update.
+	tn, err := p.getQualifiedTableName(params.ctx, n.tableDesc)
+	if err != nil {
+		return err
+	}
+	return MakeEventLogger(p.ExecCfg()).InsertEventRecord(
+		params.ctx,
+		p.txn,
+		EventLogDropTrigger,
+		int32(n.tableDesc.ID),
+		int32(params.extendedEvalCtx.NodeID.SQLInstanceID()),
+		struct {
+			TableName   string
+			TriggerName string
+			Statement   string
+			User        string
+		}{tn.FQString(), n.n.Name.String(), tree.AsStringWithFQNames(n.n, params.Ann()), p.User().Normalized()},
+	)
+}
+
+func (*dropTriggerNode) Next(runParams) (bool, error) { return false, nil }
+func (*dropTriggerNode) Values() tree.Datums          { return tree.Datums{} }
+func (*dropTriggerNode) Close(context.Context)        {}
+
+// checkNoDependentTriggers returns an error if any trigger on the given table
+// refers to the given column. This is used to disallow operations which would
+// invalidate the calls of the triggers, which refer to the columns by name.
+func checkNoDependentTriggers(
+	tableDesc *tabledesc.Mutable, typeName, objName, op string, colID descpb.ColumnID,
+) error {
+	refersToCol := triggerRefersToColumn(colID)
+	for i := range tableDesc.Triggers {
+		if trig := &tableDesc.Triggers[i]; refersToCol(trig) {
+			return errors.WithHintf(
+				sqlerrors.NewDependentObjectErrorf("cannot %s %s %q because trigger %q depends on it",
+					op, typeName, objName, trig.Name),
+				"you can drop the trigger instead.")
+		}
+	}
+	return nil
+}
+
+// triggerRefersToColumn returns a predicate for removeTriggers which matches
+// the triggers that refer to the given column.
+func triggerRefersToColumn(colID descpb.ColumnID) func(trig *descpb.TableDescriptor_Trigger) bool {
+	return func(trig *descpb.TableDescriptor_Trigger) bool {
+		for _, id := range trig.ColumnIDs {
+			if id == colID {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// removeTriggers removes the triggers of the given table which satisfy the
+// given predicate, along with the back-references from the functions they
+// call, unless the functions are also called by the remaining triggers. The
+// caller is responsible for writing the table descriptor.
+func (p *planner) removeTriggers(
+	ctx context.Context,
+	tableDesc *tabledesc.Mutable,
+	pred func(trig *descpb.TableDescriptor_Trigger) bool,
+) error {
+	var removed []descpb.ID
+	var remaining []descpb.TableDescriptor_Trigger
+	for i := range tableDesc.Triggers {
+		if trig := &tableDesc.Triggers[i]; pred(trig) {
+			removed = append(removed, trig.FunctionID)
+		} else {
+			remaining = append(remaining, *trig)
+		}
+	}
+	tableDesc.Triggers = remaining
+
+	for _, fnID := range removed {
+		stillUsed := false
+		for i := range tableDesc.Triggers {
+			stillUsed = stillUsed || tableDesc.Triggers[i].FunctionID == fnID
+		}
+		if stillUsed {
+			continue
+		}
+		fn, err := p.Descriptors().GetMutableFunctionVersionByID(ctx, p.txn, fnID)
+		if err != nil {
+			return err
+		}
+		// The function may be getting dropped, in which case the references
+		// don't need to be removed.
+		if fn.Dropped() {
+			continue
+		}
+		fn.RemoveDependedOnByTable(tableDesc.ID)
+		if err := p.writeFunctionDesc(ctx, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -68,6 +68,11 @@ func (p *planner) DropView(ctx context.Context, n *tree.DropView) (planNode, err
 				return nil, err
 			}
 		}
+		if err := p.canRemoveDependentFunctions(
+			ctx, droppedDesc.TypeName(), droppedDesc.Name, functionIDs(droppedDesc, nil /* pred */), n.DropBehavior,
+		); err != nil {
+			return nil, err
+		}
 	}
 
 	if len(td) == 0 {
@@ -228,6 +233,12 @@ func (p *planner) dropViewImpl(
 			cascadeDroppedViews = append(cascadeDroppedViews, cascadedViews...)
 			cascadeDroppedViews = append(cascadeDroppedViews, dependentDesc.Name)
 		}
+		if err := p.dropDependentFunctions(
+			ctx, functionIDs(viewDesc, nil /* pred */), "dropping dependent function",
+		); err != nil {
+			return cascadeDroppedViews, err
+		}
+		viewDesc.DependedOnByFunctions = nil
 	}
 
 	// Remove any references to types that this view has.
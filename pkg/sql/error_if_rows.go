@@ -26,6 +26,10 @@ type errorIfRowsNode struct {
 	// produced.
 	mkErr exec.MkErrFn
 
+	// deferrable is set if the error can be deferred until the end of the
+	// transaction; see exec.DeferrableCheck.
+	deferrable *exec.DeferrableCheck
+
 	nexted bool
 }
 
@@ -43,9 +47,25 @@ func (n *errorIfRowsNode) Next(params runParams) (bool, error) {
 	if err != nil {
 		return false, err
 	}
-	if ok {
+	if !ok {
+		return false, nil
+	}
+	dc := params.p.deferredConstraints
+	if n.deferrable == nil || dc == nil || params.extendedEvalCtx.TxnImplicit ||
+		!dc.isDeferred(n.deferrable) {
 		return false, n.mkErr(n.plan.Values())
 	}
+	// The constraint is deferred: remember all the violations, to be checked
+	// again when the transaction commits.
+	for ok {
+		row := n.plan.Values()
+		if err := dc.addPending(params.ctx, n.deferrable, n.deferrable.KeyVals(row), n.mkErr(row)); err != nil {
+			return false, err
+		}
+		if ok, err = n.plan.Next(params); err != nil {
+			return false, err
+		}
+	}
 	return false, nil
 }
 
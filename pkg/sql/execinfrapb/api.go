@@ -51,11 +51,26 @@ func (m *BackupDataSpec) User() security.SQLUsername {
 	return m.UserProto.Decode()
 }
 
+// User accesses the user field.
+func (m *VerifyBackupDataSpec) User() security.SQLUsername {
+	return m.UserProto.Decode()
+}
+
+// User accesses the user field.
+func (m *CompactBackupDataSpec) User() security.SQLUsername {
+	return m.UserProto.Decode()
+}
+
 // User accesses the user field.
 func (m *CSVWriterSpec) User() security.SQLUsername {
 	return m.UserProto.Decode()
 }
 
+// User accesses the user field.
+func (m *ParquetWriterSpec) User() security.SQLUsername {
+	return m.UserProto.Decode()
+}
+
 // User accesses the user field.
 func (m *ReadImportDataSpec) User() security.SQLUsername {
 	return m.UserProto.Decode()
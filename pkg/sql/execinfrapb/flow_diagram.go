@@ -453,6 +453,21 @@ func (s *CSVWriterSpec) summary() (string, []string) {
 	return "CSVWriter", []string{s.Destination}
 }
 
+// summary implements the diagramCellType interface.
+func (s *ParquetWriterSpec) summary() (string, []string) {
+	return "ParquetWriter", []string{s.Destination}
+}
+
+// summary implements the diagramCellType interface.
+func (s *VerifyBackupDataSpec) summary() (string, []string) {
+	return "VerifyBackupData", []string{fmt.Sprintf("Files: %d", len(s.Files))}
+}
+
+// summary implements the diagramCellType interface.
+func (s *CompactBackupDataSpec) summary() (string, []string) {
+	return "CompactBackupData", []string{fmt.Sprintf("Spans: %d", len(s.Entries))}
+}
+
 // summary implements the diagramCellType interface.
 func (s *BulkRowWriterSpec) summary() (string, []string) {
 	return "BulkRowWriterSpec", []string{}
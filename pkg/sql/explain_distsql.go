@@ -118,7 +118,7 @@ func (n *explainDistSQLNode) startExec(params runParams) error {
 		planCtx.planner.curPlan.subqueryPlans = n.plan.subqueryPlans
 
 		// Discard rows that are returned.
-		rw := newCallbackResultWriter(func(ctx context.Context, row tree.Datums) error {
+		rw := NewCallbackResultWriter(func(ctx context.Context, row tree.Datums) error {
 			return nil
 		})
 		execCfg := params.p.ExecCfg()
@@ -188,7 +188,7 @@ func (n *explainDistSQLNode) startExec(params runParams) error {
 		newParams.extendedEvalCtx = newEvalCtx
 
 		// Discard rows that are returned.
-		rw := newCallbackResultWriter(func(ctx context.Context, row tree.Datums) error {
+		rw := NewCallbackResultWriter(func(ctx context.Context, row tree.Datums) error {
 			return nil
 		})
 		execCfg := newParams.p.ExecCfg()
@@ -246,7 +246,7 @@ func (n *explainDistSQLNode) startExec(params runParams) error {
 		planCtx.planner.curPlan.checkPlans = n.plan.checkPlans
 
 		// Discard rows that are returned.
-		rw := newCallbackResultWriter(func(ctx context.Context, row tree.Datums) error {
+		rw := NewCallbackResultWriter(func(ctx context.Context, row tree.Datums) error {
 			return nil
 		})
 		execCfg := params.p.ExecCfg()
@@ -25,6 +25,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/util"
+	"github.com/cockroachdb/cockroach/pkg/util/humanizeutil"
 	"github.com/cockroachdb/errors"
 )
 
@@ -33,6 +34,12 @@ type exportNode struct {
 
 	source planNode
 
+	// format is the file format of the export, either exportFormatCSV or
+	// exportFormatParquet.
+	format string
+	// colNames are the names of the columns produced by source.
+	colNames []string
+
 	// destination represents the destination URI for the export,
 	// typically a directory
 	destination string
@@ -42,6 +49,12 @@ type exportNode struct {
 	csvOpts         roachpb.CSVOptions
 	chunkRows       int
 	fileCompression execinfrapb.FileCompression
+	// maxFileSize is the number of compressed bytes after which a file is
+	// rolled over, or 0 if there is no limit.
+	maxFileSize int64
+	// partitionCols are the ordinals of the columns by whose values the rows
+	// are laid out in directories, if any.
+	partitionCols []int
 }
 
 func (e *exportNode) startExec(params runParams) error {
@@ -66,6 +79,7 @@ const (
 	exportOptionChunkRows   = "chunk_rows"
 	exportOptionFileName    = "filename"
 	exportOptionCompression = "compression"
+	exportOptionMaxFileSize = "max_file_size"
 )
 
 var exportOptionExpectValues = map[string]KVStringOptValidate{
@@ -74,12 +88,19 @@ var exportOptionExpectValues = map[string]KVStringOptValidate{
 	exportOptionFileName:    KVStringOptRequireValue,
 	exportOptionNullAs:      KVStringOptRequireValue,
 	exportOptionCompression: KVStringOptRequireValue,
+	exportOptionMaxFileSize: KVStringOptRequireValue,
 }
 
+const (
+	exportFormatCSV     = "CSV"
+	exportFormatParquet = "PARQUET"
+)
+
 const exportChunkRowsDefault = 100000
 const exportFilePatternPart = "%part%"
-const exportFilePatternDefault = exportFilePatternPart + ".csv"
 const exportCompressionCodec = "gzip"
+const exportCSVCompressionCodecZstd = "zstd"
+const exportParquetCompressionCodecSnappy = "snappy"
 
 // featureExportEnabled is used to enable and disable the EXPORT feature.
 var featureExportEnabled = settings.RegisterPublicBoolSetting(
@@ -89,7 +110,11 @@ var featureExportEnabled = settings.RegisterPublicBoolSetting(
 
 // ConstructExport is part of the exec.Factory interface.
 func (ef *execFactory) ConstructExport(
-	input exec.Node, fileName tree.TypedExpr, fileFormat string, options []exec.KVOption,
+	input exec.Node,
+	fileName tree.TypedExpr,
+	fileFormat string,
+	options []exec.KVOption,
+	partitionCols []exec.NodeColumnOrdinal,
 ) (exec.Node, error) {
 	if !featureExportEnabled.Get(&ef.planner.ExecCfg().Settings.SV) {
 		return nil, pgerror.Newf(pgcode.OperatorIntervention,
@@ -107,7 +132,7 @@ func (ef *execFactory) ConstructExport(
 		return nil, errors.Errorf("EXPORT cannot be used inside a transaction")
 	}
 
-	if fileFormat != "CSV" {
+	if fileFormat != exportFormatCSV && fileFormat != exportFormatParquet {
 		return nil, errors.Errorf("unsupported export format: %q", fileFormat)
 	}
 
@@ -126,6 +151,19 @@ func (ef *execFactory) ConstructExport(
 		return nil, err
 	}
 
+	if fileFormat == exportFormatParquet {
+		for _, opt := range []string{exportOptionDelimiter, exportOptionNullAs, exportOptionMaxFileSize} {
+			if _, ok := optVals[opt]; ok {
+				return nil, pgerror.Newf(pgcode.InvalidParameterValue,
+					"option %q is not supported with format %s", opt, fileFormat)
+			}
+		}
+		if len(partitionCols) > 0 {
+			return nil, pgerror.Newf(pgcode.FeatureNotSupported,
+				"PARTITION BY is not supported with format %s", fileFormat)
+		}
+	}
+
 	csvOpts := roachpb.CSVOptions{}
 
 	if override, ok := optVals[exportOptionDelimiter]; ok {
@@ -139,7 +177,23 @@ func (ef *execFactory) ConstructExport(
 		csvOpts.NullEncoding = &override
 	}
 
+	var maxFileSize int64
+	if override, ok := optVals[exportOptionMaxFileSize]; ok {
+		maxFileSize, err = humanizeutil.ParseBytes(override)
+		if err != nil {
+			return nil, pgerror.WithCandidateCode(err, pgcode.InvalidParameterValue)
+		}
+		if maxFileSize < 1 {
+			return nil, pgerror.New(pgcode.InvalidParameterValue, "invalid max file size")
+		}
+	}
+
 	chunkRows := exportChunkRowsDefault
+	if maxFileSize > 0 {
+		// Files are rolled over by size rather than by number of rows, unless
+		// both limits are specified.
+		chunkRows = 0
+	}
 	if override, ok := optVals[exportOptionChunkRows]; ok {
 		chunkRows, err = strconv.Atoi(override)
 		if err != nil {
@@ -156,6 +210,10 @@ func (ef *execFactory) ConstructExport(
 	if name, ok := optVals[exportOptionCompression]; ok && len(name) != 0 {
 		if strings.EqualFold(name, exportCompressionCodec) {
 			codec = execinfrapb.FileCompression_Gzip
+		} else if fileFormat == exportFormatCSV && strings.EqualFold(name, exportCSVCompressionCodecZstd) {
+			codec = execinfrapb.FileCompression_Zstd
+		} else if fileFormat == exportFormatParquet && strings.EqualFold(name, exportParquetCompressionCodecSnappy) {
+			codec = execinfrapb.FileCompression_Snappy
 		} else {
 			return nil, pgerror.Newf(pgcode.InvalidParameterValue,
 				"unsupported compression codec %s", name)
@@ -163,14 +221,29 @@ func (ef *execFactory) ConstructExport(
 	}
 
 	exportID := ef.planner.stmt.QueryID.String()
-	namePattern := fmt.Sprintf("export%s-%s", exportID, exportFilePatternDefault)
+	namePattern := fmt.Sprintf("export%s-%s.%s", exportID, exportFilePatternPart, strings.ToLower(fileFormat))
+
+	source := input.(planNode)
+	cols := planColumns(source)
+	colNames := make([]string, len(cols))
+	for i := range cols {
+		colNames[i] = cols[i].Name
+	}
+	var partitionOrds []int
+	for _, ord := range partitionCols {
+		partitionOrds = append(partitionOrds, int(ord))
+	}
 
 	return &exportNode{
-		source:          input.(planNode),
+		source:          source,
+		format:          fileFormat,
+		colNames:        colNames,
 		destination:     string(*destination),
 		fileNamePattern: namePattern,
 		csvOpts:         csvOpts,
 		chunkRows:       chunkRows,
 		fileCompression: codec,
+		maxFileSize:     maxFileSize,
+		partitionCols:   partitionOrds,
 	}, nil
 }
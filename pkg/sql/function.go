@@ -0,0 +1,315 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sql
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/cockroachdb/cockroach/pkg/jobs"
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/kv"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/funcdesc"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/resolver"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/mon"
+	"github.com/cockroachdb/errors"
+)
+
+// ResolveFunction implements the tree.FunctionReferenceResolver interface.
+// The function is looked up using the search path of the planner.
+func (p *planner) ResolveFunction(
+	ctx context.Context, name *tree.UnresolvedName, _ sessiondata.SearchPath,
+) (*tree.FunctionDefinition, error) {
+	if p.txn == nil || name.Star {
+		return nil, nil
+	}
+	un, err := name.ToUnresolvedObjectName(tree.NoAnnotation)
+	if err != nil {
+		return nil, err
+	}
+	lookupFlags := tree.ObjectLookupFlags{
+		CommonLookupFlags: p.CommonLookupFlags(false /* required */),
+		DesiredObjectKind: tree.FunctionObject,
+	}
+	desc, prefix, err := resolver.ResolveExistingObject(ctx, p, un, lookupFlags)
+	if err != nil || desc == nil {
+		return nil, err
+	}
+	fn := desc.(*funcdesc.Immutable)
+	fnName := tree.MakeQualifiedFunctionName(prefix.Catalog(), prefix.Schema(), un.Object())
+
+	// Disallow cross-database function resolution, like for types.
+	if p.contextDatabaseID != descpb.InvalidID && fn.ParentID != p.contextDatabaseID {
+		return nil, pgerror.Newf(
+			pgcode.FeatureNotSupported, "cross database function references are not supported: %s", fnName.String())
+	}
+
+	// Ensure that the user can access the target schema.
+	if err := p.canResolveDescUnderSchema(ctx, fn.GetParentSchemaID(), fn); err != nil {
+		return nil, err
+	}
+
+	return makeUserDefinedFunctionDefinition(&fnName, fn), nil
+}
+
+// makeUserDefinedFunctionDefinition creates the definition of the given
+// user-defined function. Calls to the function which are not inlined by the
+// optimizer run the body of the function with the internal executor, in the
+// transaction of the calling statement.
+func makeUserDefinedFunctionDefinition(
+	name *tree.FunctionName, desc *funcdesc.Immutable,
+) *tree.FunctionDefinition {
+	argTypes := make(tree.ArgTypes, len(desc.Args))
+	for i := range desc.Args {
+		argTypes[i].Name = desc.Args[i].Name
+		if argTypes[i].Name == "" {
+			argTypes[i].Name = fmt.Sprintf("$%d", i+1)
+		}
+		argTypes[i].Typ = desc.Args[i].Type
+	}
+	udf := &tree.UDFDefinition{
+		ID:            int64(desc.ID),
+		QualifiedName: tree.NewUnresolvedName(name.Catalog(), name.Schema(), name.Object()),
+		Body:          desc.Body,
+		ReturnsSet:    desc.ReturnsSet,
+	}
+	body := &udfBody{udf: udf}
+	props := tree.FunctionProperties{
+		// The body of the function is run using the internal executor of the
+		// gateway.
+		DistsqlBlocklist: true,
+		NullableArgs:     true,
+		Class:            tree.NormalClass,
+	}
+	o := tree.Overload{
+		Types:      argTypes,
+		ReturnType: tree.FixedReturnType(desc.ReturnType),
+		Volatility: desc.Volatility(),
+		Info:       "Calculates the user-defined function " + name.FQString() + ".",
+		UDF:        udf,
+	}
+	if desc.ReturnsSet {
+		props.Class = tree.GeneratorClass
+		o.Generator = func(evalCtx *tree.EvalContext, args tree.Datums) (tree.ValueGenerator, error) {
+			return &udfValueGenerator{evalCtx: evalCtx, body: body, typ: desc.ReturnType, args: args}, nil
+		}
+	} else {
+		o.Fn = func(evalCtx *tree.EvalContext, args tree.Datums) (tree.Datum, error) {
+			rows, err := body.run(evalCtx.Context, evalCtx, evalCtx.Txn, args)
+			if err != nil || len(rows) == 0 {
+				return tree.DNull, err
+			}
+			return rows[0][0], nil
+		}
+	}
+	return tree.NewUserDefinedFunctionDefinition(name.Object(), &props, &o)
+}
+
+// maxUDFDepth is the maximum nesting depth of calls to user-defined functions,
+// which call each other when the body of a function calls another one or
+// modifies a table with triggers.
+const maxUDFDepth = 32
+
+// udfDepthKey is the context key of the nesting depth of the calls to
+// user-defined functions.
+type udfDepthKey struct{}
+
+// udfBody is the body of a user-defined function, which is parsed on its first
+// call. The plan of the body is prepared with its argument types on every
+// call, which reuses the memo kept in the query cache.
+type udfBody struct {
+	udf *tree.UDFDefinition
+
+	once   sync.Once
+	parsed parser.Statement
+	err    error
+}
+
+// run runs the body of the user-defined function with the given arguments,
+// and returns the resulting rows.
+func (b *udfBody) run(
+	ctx context.Context, evalCtx *tree.EvalContext, txn *kv.Txn, args tree.Datums,
+) ([]tree.Datums, error) {
+	ie, ok := evalCtx.InternalExecutor.(*InternalExecutor)
+	if !ok {
+		return nil, errors.AssertionFailedf("cannot call user-defined function %s without an internal executor",
+			b.udf.QualifiedName)
+	}
+	depth, _ := ctx.Value(udfDepthKey{}).(int)
+	if depth >= maxUDFDepth {
+		return nil, errors.WithHintf(
+			pgerror.Newf(pgcode.StatementTooComplex, "stack depth limit exceeded"),
+			"Calls to user-defined functions are nested more than %d levels deep, "+
+				"e.g. through a trigger which calls a function that modifies its table.", maxUDFDepth,
+		)
+	}
+	ctx = context.WithValue(ctx, udfDepthKey{}, depth+1)
+
+	b.once.Do(func() {
+		b.parsed, b.err = parser.ParseOne(b.udf.Body)
+	})
+	if b.err != nil {
+		return nil, b.err
+	}
+	// The arguments which the body does not refer to are not passed, since the
+	// body may not have placeholders for them.
+	qargs := make([]interface{}, b.parsed.NumPlaceholders)
+	for i := range qargs {
+		qargs[i] = args[i]
+	}
+	rows, err := ie.queryParsed(
+		ctx, "udf", txn, ie.maybeRootSessionDataOverride("udf"), b.parsed, qargs...,
+	)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error calling function %s", b.udf.QualifiedName)
+	}
+	return rows, nil
+}
+
+// udfValueGenerator is the generator of a user-defined function which returns
+// a set of values. The rows are accounted for in the memory monitor of the
+// calling statement until the generator is closed.
+type udfValueGenerator struct {
+	evalCtx *tree.EvalContext
+	body    *udfBody
+	typ     *types.T
+	args    tree.Datums
+
+	rows   []tree.Datums
+	rowIdx int
+	acc    mon.BoundAccount
+}
+
+var _ tree.ValueGenerator = &udfValueGenerator{}
+
+// ResolvedType implements the tree.ValueGenerator interface.
+func (g *udfValueGenerator) ResolvedType() *types.T { return g.typ }
+
+// Start implements the tree.ValueGenerator interface.
+func (g *udfValueGenerator) Start(ctx context.Context, txn *kv.Txn) (err error) {
+	g.rowIdx = -1
+	rows, err := g.body.run(ctx, g.evalCtx, txn, g.args)
+	if err != nil {
+		return err
+	}
+	g.acc = g.evalCtx.Mon.MakeBoundAccount()
+	size := int64(len(rows)) * tree.SizeOfDatums
+	for _, row := range rows {
+		for _, d := range row {
+			size += int64(d.Size())
+		}
+	}
+	if err := g.acc.Grow(ctx, size); err != nil {
+		return err
+	}
+	g.rows = rows
+	return nil
+}
+
+// Next implements the tree.ValueGenerator interface.
+func (g *udfValueGenerator) Next(context.Context) (bool, error) {
+	g.rowIdx++
+	return g.rowIdx < len(g.rows), nil
+}
+
+// Values implements the tree.ValueGenerator interface.
+func (g *udfValueGenerator) Values() (tree.Datums, error) {
+	return g.rows[g.rowIdx][:1], nil
+}
+
+// Close implements the tree.ValueGenerator interface.
+func (g *udfValueGenerator) Close() {
+	g.rows = nil
+	g.acc.Close(g.evalCtx.Context)
+}
+
+// writeFunctionDesc writes the given function descriptor in the current
+// transaction.
+func (p *planner) writeFunctionDesc(ctx context.Context, desc *funcdesc.Mutable) error {
+	b := p.txn.NewBatch()
+	if err := p.Descriptors().WriteDescToBatch(
+		ctx, p.extendedEvalCtx.Tracing.KVTracingEnabled(), desc, b,
+	); err != nil {
+		return err
+	}
+	return p.txn.Run(ctx, b)
+}
+
+// writeFunctionDescChange writes the given function descriptor, and queues a
+// schema change job which waits for the new version of the descriptor to be
+// adopted by all nodes. The job also drains the old names of the function, and
+// deletes the descriptor if the function was dropped.
+func (p *planner) writeFunctionDescChange(
+	ctx context.Context, desc *funcdesc.Mutable, jobDesc string,
+) error {
+	job, jobExists := p.extendedEvalCtx.SchemaChangeJobCache[desc.ID]
+	if jobExists {
+		// Update it.
+		if err := job.WithTxn(p.txn).SetDescription(ctx,
+			func(ctx context.Context, desc string) (string, error) {
+				return desc + "; " + jobDesc, nil
+			},
+		); err != nil {
+			return err
+		}
+		log.Infof(ctx, "job %d: updated with for change on function %d", *job.ID(), desc.ID)
+	} else {
+		// Or, create a new job.
+		jobRecord := jobs.Record{
+			Description:   jobDesc,
+			Username:      p.User(),
+			DescriptorIDs: descpb.IDs{desc.ID},
+			Details: jobspb.SchemaChangeDetails{
+				DescID: desc.ID,
+				// The version distinction for database jobs doesn't matter for
+				// function jobs.
+				FormatVersion: jobspb.DatabaseJobFormatVersion,
+			},
+			Progress: jobspb.SchemaChangeProgress{},
+		}
+		newJob, err := p.extendedEvalCtx.QueueJob(jobRecord)
+		if err != nil {
+			return err
+		}
+		p.extendedEvalCtx.SchemaChangeJobCache[desc.ID] = newJob
+		log.Infof(ctx, "queued new schema change job %d for function %d", *newJob.ID(), desc.ID)
+	}
+
+	return p.writeFunctionDesc(ctx, desc)
+}
+
+// getFunctionName returns the fully qualified name of the given function.
+func (p *planner) getFunctionName(
+	ctx context.Context, desc catalog.FunctionDescriptor,
+) (*tree.FunctionName, error) {
+	dbDesc, err := p.Descriptors().GetDatabaseVersionByID(
+		ctx, p.txn, desc.GetParentID(), p.CommonLookupFlags(true /* required */),
+	)
+	if err != nil {
+		return nil, err
+	}
+	sc, err := p.Descriptors().ResolveSchemaByID(ctx, p.txn, desc.GetParentSchemaID())
+	if err != nil {
+		return nil, err
+	}
+	name := tree.MakeQualifiedFunctionName(dbDesc.GetName(), sc.Name, desc.GetName())
+	return &name, nil
+}
@@ -240,13 +240,30 @@ func (ie *InternalExecutor) queryInternal(
 	stmt string,
 	qargs ...interface{},
 ) ([]tree.Datums, colinfo.ResultColumns, error) {
-	res, err := ie.execInternal(ctx, opName, txn, sessionDataOverride, stmt, qargs...)
+	res, err := ie.execInternal(ctx, opName, txn, sessionDataOverride, stmt, nil /* parsed */, qargs...)
 	if err != nil {
 		return nil, nil, err
 	}
 	return res.rows, res.cols, res.err
 }
 
+// queryParsed is like QueryEx, but runs a statement which was already parsed,
+// so that a statement which is run many times is only parsed once.
+func (ie *InternalExecutor) queryParsed(
+	ctx context.Context,
+	opName string,
+	txn *kv.Txn,
+	session sessiondata.InternalExecutorOverride,
+	parsed parser.Statement,
+	qargs ...interface{},
+) ([]tree.Datums, error) {
+	res, err := ie.execInternal(ctx, opName, txn, session, parsed.SQL, &parsed, qargs...)
+	if err != nil {
+		return nil, err
+	}
+	return res.rows, res.err
+}
+
 // QueryRow is like Query, except it returns a single row, or nil if not row is
 // found, or an error if more that one row is returned.
 //
@@ -311,7 +328,7 @@ func (ie *InternalExecutor) ExecEx(
 	stmt string,
 	qargs ...interface{},
 ) (int, error) {
-	res, err := ie.execInternal(ctx, opName, txn, session, stmt, qargs...)
+	res, err := ie.execInternal(ctx, opName, txn, session, stmt, nil /* parsed */, qargs...)
 	if err != nil {
 		return 0, err
 	}
@@ -374,6 +391,7 @@ func (ie *InternalExecutor) execInternal(
 	txn *kv.Txn,
 	sessionDataOverride sessiondata.InternalExecutorOverride,
 	stmt string,
+	parsedStmt *parser.Statement,
 	qargs ...interface{},
 ) (retRes result, retErr error) {
 	ctx = logtags.AddTag(ctx, "intExec", opName)
@@ -414,9 +432,14 @@ func (ie *InternalExecutor) execInternal(
 
 	timeReceived := timeutil.Now()
 	parseStart := timeReceived
-	parsed, err := parser.ParseOne(stmt)
-	if err != nil {
-		return result{}, err
+	var parsed parser.Statement
+	if parsedStmt != nil {
+		parsed = *parsedStmt
+	} else {
+		var err error
+		if parsed, err = parser.ParseOne(stmt); err != nil {
+			return result{}, err
+		}
 	}
 	parseEnd := timeutil.Now()
 
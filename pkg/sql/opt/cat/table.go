@@ -131,6 +131,13 @@ type Table interface {
 	// Unique returns the ith unique constraint defined on this table, where
 	// i < UniqueCount.
 	Unique(i int) UniqueConstraint
+
+	// TriggerCount returns the number of row-level triggers on the table.
+	TriggerCount() int
+
+	// Trigger returns the ith trigger, where i < TriggerCount. Triggers with the
+	// same action time are called in this order.
+	Trigger(i int) Trigger
 }
 
 // CheckConstraint contains the SQL text and the validity status for a check
@@ -145,6 +152,34 @@ type CheckConstraint struct {
 	Validated  bool
 }
 
+// Trigger contains the definition of a row-level trigger on a table. Triggers
+// call a user-defined function for each row which is inserted, updated or
+// deleted by a statement. For example, this trigger calls log(a) for every
+// row inserted into the table:
+//
+//   CREATE TRIGGER t_log AFTER INSERT ON t FOR EACH ROW EXECUTE FUNCTION log(new.a)
+//
+type Trigger struct {
+	Name       tree.Name
+	ActionTime tree.TriggerActionTime
+	Events     tree.TriggerEvents
+
+	// Call is the SQL text of the call of the trigger function. The function
+	// name is fully qualified, and the arguments refer to the columns of the new
+	// and old rows as new.<column> and old.<column>.
+	Call string
+}
+
+// FiresOn returns true if the trigger fires on the given event.
+func (t *Trigger) FiresOn(event tree.TriggerEvent) bool {
+	for _, e := range t.Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
 // TableStatistic is an interface to a table statistic. Each statistic is
 // associated with a set of columns.
 type TableStatistic interface {
@@ -244,6 +279,10 @@ type ForeignKeyConstraint interface {
 	// UpdateReferenceAction returns the action to be performed if the foreign key
 	// constraint would be violated by an update.
 	UpdateReferenceAction() tree.ReferenceAction
+
+	// Deferrability returns whether the checking of the constraint can be
+	// deferred until the end of the transaction, and whether it is by default.
+	Deferrability() tree.ConstraintDeferrability
 }
 
 // UniqueConstraint represents a uniqueness constraint. UniqueConstraints may
@@ -277,4 +316,9 @@ type UniqueConstraint interface {
 	// cannot make any assumptions about the data. An unvalidated constraint still
 	// needs to be enforced on new mutations.
 	Validated() bool
+
+	// Deferrability returns whether the checking of the constraint can be
+	// deferred until the end of the transaction, and whether it is by default.
+	// Only constraints without an index can be deferred.
+	Deferrability() tree.ConstraintDeferrability
 }
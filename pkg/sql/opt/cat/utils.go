@@ -166,10 +166,15 @@ func FormatTable(cat Catalog, tab Table, tp treeprinter.Node) {
 		if tab.Unique(i).WithoutIndex() {
 			withoutIndexStr = "WITHOUT INDEX "
 		}
+		var deferrableStr string
+		if d := tab.Unique(i).Deferrability(); d != tree.ConstraintNotDeferrable {
+			deferrableStr = " " + d.String()
+		}
 		child.Childf(
-			"UNIQUE %s%s",
+			"UNIQUE %s%s%s",
 			withoutIndexStr,
 			formatCols(tab, tab.Unique(i).ColumnCount(), tab.Unique(i).ColumnOrdinal),
+			deferrableStr,
 		)
 	}
 
@@ -288,6 +293,10 @@ func formatCatalogFKRef(
 		fmt.Fprintf(&extra, " ON DELETE %s", action.String())
 	}
 
+	if d := fkRef.Deferrability(); d != tree.ConstraintNotDeferrable {
+		fmt.Fprintf(&extra, " %s", d)
+	}
+
 	tp.Childf(
 		"%s %s FOREIGN KEY %v %s REFERENCES %v %s%s",
 		title,
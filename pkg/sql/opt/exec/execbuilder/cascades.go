@@ -272,7 +272,20 @@ func (cb *cascadeBuilder) planCascade(
 		}
 	}
 
-	o.Memo().SetRoot(relExpr, &physical.Required{})
+	// Cascades which are not mutations (AFTER triggers) are run for the side
+	// effects of the expressions they project, so their output columns are
+	// required in order to prevent them from being pruned.
+	var required physical.Required
+	if !opt.IsMutationOp(relExpr) {
+		cols := relExpr.Relational().OutputCols
+		for col, ok := cols.Next(0); ok; col, ok = cols.Next(col + 1) {
+			required.Presentation = append(required.Presentation, opt.AliasedColumn{
+				Alias: md.ColumnMeta(col).Alias,
+				ID:    col,
+			})
+		}
+	}
+	o.Memo().SetRoot(relExpr, &required)
 
 	// 3. Optimize the expression.
 	optimizedExpr, err := o.Optimize()
@@ -113,12 +113,18 @@ func (b *Builder) buildInsert(ins *memo.InsertExpr) (execPlan, error) {
 		ep.outputCols = mutationOutputColMap(ins)
 	}
 
-	// TODO(rytaft): build unique checks.
+	if err := b.buildUniqueChecks(ins.UniqueChecks); err != nil {
+		return execPlan{}, err
+	}
 
 	if err := b.buildFKChecks(ins.FKChecks); err != nil {
 		return execPlan{}, err
 	}
 
+	if err := b.buildFKCascades(ins.WithID, ins.FKCascades); err != nil {
+		return execPlan{}, err
+	}
+
 	return ep, nil
 }
 
@@ -136,6 +142,16 @@ func (b *Builder) tryBuildFastPathInsert(ins *memo.InsertExpr) (_ execPlan, ok b
 		return execPlan{}, false, nil
 	}
 
+	//  - there are no AFTER triggers, which are planned as cascades;
+	if len(ins.FKCascades) > 0 {
+		return execPlan{}, false, nil
+	}
+
+	//  - there are no uniqueness checks;
+	if len(ins.UniqueChecks) > 0 {
+		return execPlan{}, false, nil
+	}
+
 	//  - the input is Values with at most mutations.MaxBatchSize, and there are no
 	//    subqueries;
 	//    (note that mutations.MaxBatchSize() is a quantity of keys in the batch
@@ -150,6 +166,7 @@ func (b *Builder) tryBuildFastPathInsert(ins *memo.InsertExpr) (_ execPlan, ok b
 	tab := md.Table(ins.Table)
 
 	//  - there are no self-referencing foreign keys;
+	//  - there are no DEFERRABLE foreign keys;
 	//  - all FK checks can be performed using direct lookups into unique indexes.
 	fkChecks := make([]exec.InsertFastPathFKCheck, len(ins.FKChecks))
 	for i := range ins.FKChecks {
@@ -159,6 +176,11 @@ func (b *Builder) tryBuildFastPathInsert(ins *memo.InsertExpr) (_ execPlan, ok b
 			return execPlan{}, false, nil
 		}
 		fk := tab.OutboundForeignKey(c.FKOrdinal)
+		if fk.Deferrability() != tree.ConstraintNotDeferrable {
+			// The violations of a deferrable FK may have to be remembered rather
+			// than reported, which the fast path does not support.
+			return execPlan{}, false, nil
+		}
 		lookupJoin, isLookupJoin := c.Check.(*memo.LookupJoinExpr)
 		if !isLookupJoin || lookupJoin.JoinType != opt.AntiJoinOp {
 			// Not a lookup anti-join.
@@ -335,7 +357,9 @@ func (b *Builder) buildUpdate(upd *memo.UpdateExpr) (execPlan, error) {
 		return execPlan{}, err
 	}
 
-	// TODO(rytaft): build unique checks.
+	if err := b.buildUniqueChecks(upd.UniqueChecks); err != nil {
+		return execPlan{}, err
+	}
 
 	if err := b.buildFKChecks(upd.FKChecks); err != nil {
 		return execPlan{}, err
@@ -419,7 +443,9 @@ func (b *Builder) buildUpsert(ups *memo.UpsertExpr) (execPlan, error) {
 		return execPlan{}, err
 	}
 
-	// TODO(rytaft): build unique checks.
+	if err := b.buildUniqueChecks(ups.UniqueChecks); err != nil {
+		return execPlan{}, err
+	}
 
 	if err := b.buildFKChecks(ups.FKChecks); err != nil {
 		return execPlan{}, err
@@ -554,6 +580,7 @@ func (b *Builder) tryBuildDeleteRange(del *memo.DeleteExpr) (_ execPlan, ok bool
 // of a row cascades into deleting all interleaved rows with the same prefix.
 // More specifically, the following conditions must apply:
 //  - none of the tables in the hierarchy have secondary indexes;
+//  - none of the tables in the hierarchy have triggers;
 //  - none of the tables in the hierarchy are referenced by any tables outside
 //    the hierarchy;
 //  - all foreign key references between tables in the hierarchy have columns
@@ -577,6 +604,12 @@ func (b *Builder) tryBuildDeleteRangeOnInterleaving(
 			return execPlan{}, false, nil
 		}
 
+		// Triggers must fire for every deleted row, so the rows have to be
+		// fetched.
+		if currTab.TriggerCount() > 0 {
+			return execPlan{}, false, nil
+		}
+
 		currIdx := currTab.Index(cat.PrimaryIndex)
 		for i, n := 0, currIdx.InterleavedByCount(); i < n; i++ {
 			// We don't care about the index ID because we bail if any of the tables
@@ -767,6 +800,104 @@ func mutationOutputColMap(mutation memo.RelExpr) opt.ColMap {
 	return colMap
 }
 
+func (b *Builder) buildUniqueChecks(checks memo.UniqueChecksExpr) error {
+	md := b.mem.Metadata()
+	for i := range checks {
+		c := &checks[i]
+		// Construct the query that returns uniqueness violations.
+		query, err := b.buildRelational(c.Check)
+		if err != nil {
+			return err
+		}
+		// Wrap the query in an error node. The KeyCols of the check are the
+		// columns of the constraint followed by the primary key columns; only the
+		// former are part of the key values.
+		uc := md.Table(c.Table).Unique(c.CheckOrdinal)
+		keyVals := func(row tree.Datums) tree.Datums {
+			keyVals := make(tree.Datums, uc.ColumnCount())
+			for i := range keyVals {
+				keyVals[i] = row[query.getNodeColumnOrdinal(c.KeyCols[i])]
+			}
+			return keyVals
+		}
+		mkErr := func(row tree.Datums) error {
+			return mkUniqueCheckErr(md, c, keyVals(row))
+		}
+		deferrable := makeDeferrableUniqueCheck(md, c, keyVals)
+		node, err := b.factory.ConstructErrorIfRows(query.root, mkErr, deferrable)
+		if err != nil {
+			return err
+		}
+		b.checks = append(b.checks, node)
+	}
+	return nil
+}
+
+// makeDeferrableUniqueCheck returns the information needed to defer the given
+// check until the end of the transaction, or nil if the constraint is not
+// DEFERRABLE.
+func makeDeferrableUniqueCheck(
+	md *opt.Metadata, c *memo.UniqueChecksItem, keyVals func(tree.Datums) tree.Datums,
+) *exec.DeferrableCheck {
+	tab := md.Table(c.Table)
+	uc := tab.Unique(c.CheckOrdinal)
+	deferrability := uc.Deferrability()
+	if deferrability == tree.ConstraintNotDeferrable {
+		return nil
+	}
+	d := &exec.DeferrableCheck{
+		Name:              uc.Name(),
+		InitiallyDeferred: deferrability == tree.DeferrableInitiallyDeferred,
+		Table:             tab.ID(),
+		Columns:           make([]cat.StableID, uc.ColumnCount()),
+		KeyVals:           keyVals,
+	}
+	for i := range d.Columns {
+		d.Columns[i] = tab.Column(uc.ColumnOrdinal(tab, i)).ColID()
+	}
+	return d
+}
+
+// mkUniqueCheckErr generates a user-friendly error describing a uniqueness
+// violation. The keyVals are the values that correspond to the
+// cat.UniqueConstraint columns.
+func mkUniqueCheckErr(md *opt.Metadata, c *memo.UniqueChecksItem, keyVals tree.Datums) error {
+	tab := md.Table(c.Table)
+	uc := tab.Unique(c.CheckOrdinal)
+
+	// Generate an error of the form:
+	//   ERROR:  duplicate key value violates unique constraint "foo"
+	//   DETAIL: Key (k)=(2) already exists.
+	var msg, details bytes.Buffer
+	msg.WriteString("duplicate key value violates unique constraint ")
+	lexbase.EncodeEscapedSQLIdent(&msg, uc.Name())
+
+	details.WriteString("Key (")
+	for i := 0; i < uc.ColumnCount(); i++ {
+		if i > 0 {
+			details.WriteString(", ")
+		}
+		col := tab.Column(uc.ColumnOrdinal(tab, i))
+		details.WriteString(string(col.ColName()))
+	}
+	details.WriteString(")=(")
+	for i, d := range keyVals {
+		if i > 0 {
+			details.WriteString(", ")
+		}
+		details.WriteString(d.String())
+	}
+	details.WriteString(") already exists.")
+
+	return errors.WithDetail(
+		pgerror.WithConstraintName(
+			pgerror.Newf(pgcode.UniqueViolation, "%s", msg.String()),
+			uc.Name(),
+		),
+		details.String(),
+	)
+}
+
 func (b *Builder) buildFKChecks(checks memo.FKChecksExpr) error {
 	md := b.mem.Metadata()
 	for i := range checks {
@@ -777,14 +908,18 @@ func (b *Builder) buildFKChecks(checks memo.FKChecksExpr) error {
 			return err
 		}
 		// Wrap the query in an error node.
-		mkErr := func(row tree.Datums) error {
+		keyVals := func(row tree.Datums) tree.Datums {
 			keyVals := make(tree.Datums, len(c.KeyCols))
 			for i, col := range c.KeyCols {
 				keyVals[i] = row[query.getNodeColumnOrdinal(col)]
 			}
-			return mkFKCheckErr(md, c, keyVals)
+			return keyVals
 		}
-		node, err := b.factory.ConstructErrorIfRows(query.root, mkErr)
+		mkErr := func(row tree.Datums) error {
+			return mkFKCheckErr(md, c, keyVals(row))
+		}
+		deferrable := makeDeferrableFKCheck(md, c, keyVals)
+		node, err := b.factory.ConstructErrorIfRows(query.root, mkErr, deferrable)
 		if err != nil {
 			return err
 		}
@@ -793,6 +928,48 @@ func (b *Builder) buildFKChecks(checks memo.FKChecksExpr) error {
 	return nil
 }
 
+// makeDeferrableFKCheck returns the information needed to defer the given
+// check until the end of the transaction, or nil if the constraint is not
+// DEFERRABLE. As in Postgres, RESTRICT actions are never deferred, even if the
+// constraint is DEFERRABLE.
+func makeDeferrableFKCheck(
+	md *opt.Metadata, c *memo.FKChecksItem, keyVals func(tree.Datums) tree.Datums,
+) *exec.DeferrableCheck {
+	origin := md.TableMeta(c.OriginTable).Table
+	referenced := md.TableMeta(c.ReferencedTable).Table
+	var fk cat.ForeignKeyConstraint
+	if c.FKOutbound {
+		fk = origin.OutboundForeignKey(c.FKOrdinal)
+	} else {
+		fk = referenced.InboundForeignKey(c.FKOrdinal)
+		action := fk.UpdateReferenceAction()
+		if c.OpName == "delete" {
+			action = fk.DeleteReferenceAction()
+		}
+		if action == tree.Restrict {
+			return nil
+		}
+	}
+	deferrability := fk.Deferrability()
+	if deferrability == tree.ConstraintNotDeferrable {
+		return nil
+	}
+	d := &exec.DeferrableCheck{
+		Name:              fk.Name(),
+		InitiallyDeferred: deferrability == tree.DeferrableInitiallyDeferred,
+		Table:             origin.ID(),
+		Columns:           make([]cat.StableID, fk.ColumnCount()),
+		ReferencedTable:   referenced.ID(),
+		ReferencedColumns: make([]cat.StableID, fk.ColumnCount()),
+		KeyVals:           keyVals,
+	}
+	for i := range d.Columns {
+		d.Columns[i] = origin.Column(fk.OriginColumnOrdinal(origin, i)).ColID()
+		d.ReferencedColumns[i] = referenced.Column(fk.ReferencedColumnOrdinal(referenced, i)).ColID()
+	}
+	return d
+}
+
 // mkFKCheckErr generates a user-friendly error describing a foreign key
 // violation. The keyVals are the values that correspond to the
 // cat.ForeignKeyConstraint columns.
@@ -278,7 +278,7 @@ func (b *Builder) buildFunction(
 			return nil, err
 		}
 	}
-	funcRef := tree.WrapFunction(fn.Name)
+	funcRef := tree.WrapFunctionOverload(fn.Name, fn.Properties, fn.Overload)
 	return tree.NewTypedFuncExpr(
 		funcRef,
 		0, /* aggQualifier */
@@ -69,6 +69,12 @@ func (b *Builder) buildCreateView(cv *memo.CreateViewExpr) (execPlan, error) {
 	return execPlan{root: root}, err
 }
 
+func (b *Builder) buildCreateFunction(cf *memo.CreateFunctionExpr) (execPlan, error) {
+	schema := b.mem.Metadata().Schema(cf.Schema)
+	root, err := b.factory.ConstructCreateFunction(schema, cf.Syntax, cf.Deps, cf.FuncDeps)
+	return execPlan{root: root}, err
+}
+
 func (b *Builder) buildExplainOpt(explain *memo.ExplainExpr) (execPlan, error) {
 	fmtFlags := memo.ExprFmtHideAll
 	switch {
@@ -308,11 +314,17 @@ func (b *Builder) buildExport(export *memo.ExportExpr) (execPlan, error) {
 		}
 	}
 
+	partitionCols := make([]exec.NodeColumnOrdinal, len(export.PartitionCols))
+	for i, c := range export.PartitionCols {
+		partitionCols[i] = input.getNodeColumnOrdinal(c)
+	}
+
 	node, err := b.factory.ConstructExport(
 		input.root,
 		fileName,
 		export.FileFormat,
 		opts,
+		partitionCols,
 	)
 	if err != nil {
 		return execPlan{}, err
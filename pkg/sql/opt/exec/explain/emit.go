@@ -250,6 +250,7 @@ var nodeNames = [...]string{
 	cancelSessionsOp:       "cancel sessions",
 	controlJobsOp:          "control jobs",
 	controlSchedulesOp:     "control schedules",
+	createFunctionOp:       "create function",
 	createTableOp:          "create table",
 	createTableAsOp:        "create table as",
 	createViewOp:           "create view",
@@ -662,6 +663,7 @@ func (e *emitter) emitNodeAttributes(n *Node) error {
 		explainOp,
 		explainPlanOp,
 		showTraceOp,
+		createFunctionOp,
 		createTableOp,
 		createTableAsOp,
 		createViewOp,
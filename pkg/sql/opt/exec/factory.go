@@ -258,6 +258,38 @@ type InsertFastPathFKCheck struct {
 // relevant row.
 type MkErrFn func(tree.Datums) error
 
+// DeferrableCheck describes a foreign key or uniqueness check whose violations
+// can be deferred until the end of the transaction. Each violation is then
+// remembered by its key values and checked again before the transaction
+// commits.
+type DeferrableCheck struct {
+	// Name is the name of the constraint, as used by SET CONSTRAINTS.
+	Name string
+
+	// InitiallyDeferred is true if violations are deferred unless SET
+	// CONSTRAINTS says otherwise.
+	InitiallyDeferred bool
+
+	// Table and Columns identify the table on which the constraint is defined
+	// and its columns; for a foreign key, that is the referencing side.
+	Table   cat.StableID
+	Columns []cat.StableID
+
+	// ReferencedTable and ReferencedColumns identify the referenced side of a
+	// foreign key. They are not set for a uniqueness check.
+	ReferencedTable   cat.StableID
+	ReferencedColumns []cat.StableID
+
+	// KeyVals returns the values of the constraint's columns, in the order of
+	// Columns, for a row returned by the check query.
+	KeyVals func(tree.Datums) tree.Datums
+}
+
+// IsUnique returns true if the check is a uniqueness check.
+func (c *DeferrableCheck) IsUnique() bool {
+	return c.ReferencedColumns == nil
+}
+
 // ExplainFactory is an extension of Factory used when constructing a plan that
 // can be explained. It allows annotation of nodes with extra information.
 type ExplainFactory interface {
@@ -554,7 +554,7 @@ func (c *CustomFuncs) FoldFunction(
 	for i := range exprs {
 		exprs[i] = memo.ExtractConstDatum(args[i])
 	}
-	funcRef := tree.WrapFunction(private.Name)
+	funcRef := tree.WrapFunctionOverload(private.Name, private.Properties, private.Overload)
 	fn := tree.NewTypedFuncExpr(
 		funcRef,
 		0, /* aggQualifier */
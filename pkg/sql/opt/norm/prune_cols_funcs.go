@@ -67,6 +67,10 @@ func (c *CustomFuncs) NeededMutationCols(
 	if private.CanaryCol != 0 {
 		cols.Add(private.CanaryCol)
 	}
+	for i := range private.FKCascades {
+		addCols(private.FKCascades[i].OldValues)
+		addCols(private.FKCascades[i].NewValues)
+	}
 
 	if private.WithID != 0 {
 		for i := range uniqueChecks {
@@ -141,6 +145,19 @@ func neededMutationFetchCols(
 		}
 	}
 
+	// Retain any FetchCols that are passed to cascades (e.g. the old values of
+	// rows which are passed to AFTER triggers).
+	var cascadeCols opt.ColSet
+	for i := range private.FKCascades {
+		cascadeCols.UnionWith(private.FKCascades[i].OldValues.ToSet())
+		cascadeCols.UnionWith(private.FKCascades[i].NewValues.ToSet())
+	}
+	for ord, col := range private.FetchCols {
+		if col != 0 && cascadeCols.Contains(col) {
+			cols.Add(tabMeta.MetaID.ColumnID(ord))
+		}
+	}
+
 	switch op {
 	case opt.UpdateOp, opt.UpsertOp:
 		// Determine set of target table columns that need to be updated.
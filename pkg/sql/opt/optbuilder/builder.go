@@ -117,6 +117,17 @@ type Builder struct {
 	trackViewDeps bool
 	viewDeps      opt.ViewDeps
 
+	// If set, we are processing the body of a user-defined function. Like for
+	// view definitions, catalog caches are disabled and certain statements are
+	// disallowed. The data source dependencies of the function are collected in
+	// viewDeps, and the user-defined functions it calls in funcDeps.
+	insideFuncDef bool
+	funcDeps      opt.FuncDeps
+
+	// funcArgs resolves the names of the arguments of the user-defined function
+	// whose body is being built, if any.
+	funcArgs *funcArgNames
+
 	// If set, the data source names in the AST are rewritten to the fully
 	// qualified version (after resolution). Used to construct the strings for
 	// CREATE VIEW and CREATE TABLE AS queries.
@@ -248,14 +259,27 @@ func (b *Builder) buildStmtAtRoot(
 func (b *Builder) buildStmt(
 	stmt tree.Statement, desiredTypes []*types.T, inScope *scope,
 ) (outScope *scope) {
-	if b.insideViewDef {
-		// A blocklist of statements that can't be used from inside a view.
+	if b.insideViewDef || b.insideFuncDef {
+		// A blocklist of statements that can't be used from inside a view or a
+		// function.
+		kind := "view"
+		if b.insideFuncDef {
+			kind = "function"
+		}
 		switch stmt := stmt.(type) {
-		case *tree.Delete, *tree.Insert, *tree.Update, *tree.CreateTable, *tree.CreateView,
+		case *tree.Delete, *tree.Insert, *tree.Update:
+			// Functions can modify data.
+			if b.insideViewDef {
+				panic(pgerror.Newf(
+					pgcode.Syntax, "%s cannot be used inside a %s definition", stmt.StatementTag(), kind,
+				))
+			}
+
+		case *tree.CreateTable, *tree.CreateView, *tree.CreateFunction,
 			*tree.Split, *tree.Unsplit, *tree.Relocate,
 			*tree.ControlJobs, *tree.ControlSchedules, *tree.CancelQueries, *tree.CancelSessions:
 			panic(pgerror.Newf(
-				pgcode.Syntax, "%s cannot be used inside a view definition", stmt.StatementTag(),
+				pgcode.Syntax, "%s cannot be used inside a %s definition", stmt.StatementTag(), kind,
 			))
 		}
 	}
@@ -288,6 +312,9 @@ func (b *Builder) buildStmt(
 	case *tree.CreateView:
 		return b.buildCreateView(stmt, inScope)
 
+	case *tree.CreateFunction:
+		return b.buildCreateFunction(stmt, inScope)
+
 	case *tree.Explain:
 		return b.buildExplain(stmt, inScope)
 
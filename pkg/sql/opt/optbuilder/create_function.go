@@ -0,0 +1,269 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package optbuilder
+
+import (
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/memo"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util/errorutil/unimplemented"
+	"github.com/cockroachdb/errors"
+)
+
+func (b *Builder) buildCreateFunction(
+	cf *tree.CreateFunction, inScope *scope,
+) (outScope *scope) {
+	b.DisableMemoReuse = true
+	tn := cf.FuncName.ToTableName()
+	sch, resName := b.resolveSchemaForCreate(&tn)
+	schID := b.factory.Metadata().AddSchema(sch)
+	funcName, err := tree.NewUnresolvedObjectName(
+		3, /* numParts */
+		[3]string{cf.FuncName.Object(), resName.Schema(), resName.Catalog()},
+		tree.NoAnnotation,
+	)
+	if err != nil {
+		panic(err)
+	}
+
+	args := make(tree.FuncArgs, len(cf.Args))
+	argTypes := make([]*types.T, len(cf.Args))
+	argNames := make(map[tree.Name]int, len(cf.Args))
+	for i := range cf.Args {
+		argTypes[i] = b.resolveFunctionType(cf.Args[i].Type)
+		if name := cf.Args[i].Name; name != "" {
+			if _, ok := argNames[name]; ok {
+				panic(pgerror.Newf(pgcode.InvalidFunctionDefinition,
+					"parameter name %q used more than once", name))
+			}
+			argNames[name] = i
+		}
+		args[i] = tree.FuncArg{Name: cf.Args[i].Name, Type: argTypes[i]}
+	}
+	retType := b.resolveFunctionType(cf.ReturnType)
+
+	stmts, err := parser.Parse(cf.Body)
+	if err != nil {
+		panic(err)
+	}
+	if len(stmts) != 1 {
+		panic(unimplemented.New("create function body",
+			"function bodies must contain exactly one statement"))
+	}
+	body := stmts[0].AST
+	switch body.(type) {
+	case *tree.Select:
+	case *tree.Insert, *tree.Update, *tree.Delete:
+		// Functions which modify data must be VOLATILE. The result of the
+		// function is produced by the RETURNING clause.
+		if cf.Volatility != 0 && cf.Volatility != tree.VolatilityVolatile {
+			panic(pgerror.Newf(pgcode.FeatureNotSupported,
+				"%s is not allowed in a non-volatile function", body.StatementTag()))
+		}
+	default:
+		panic(unimplemented.Newf("create function body",
+			"function bodies can only contain a SELECT, INSERT, UPSERT, UPDATE or DELETE statement, not %s",
+			body.StatementTag()))
+	}
+
+	// References to the arguments in the body are replaced by typed
+	// placeholders, which are filled in with the argument values when the
+	// function is called. The references by position are replaced first; the
+	// references by name once the body is built, since they depend on the
+	// columns that are in scope.
+	v := funcArgReplacer{argTypes: argTypes}
+	newBody, _ := tree.WalkStmt(&v, body)
+	if v.err != nil {
+		panic(v.err)
+	}
+	body = newBody
+	b.funcArgs = &funcArgNames{
+		argTypes: argTypes,
+		argNames: argNames,
+		resolved: make(map[tree.Expr]tree.Expr),
+	}
+
+	// We build the body to:
+	//  - check the statement semantically,
+	//  - get the fully resolved names into the AST, and
+	//  - collect the function dependencies in b.viewDeps and b.funcDeps.
+	// The result is not otherwise used.
+	b.insideFuncDef = true
+	b.trackViewDeps = true
+	b.qualifyDataSourceNamesInAST = true
+	defer func(placeholders tree.PlaceholderInfo, keepPlaceholders bool) {
+		b.insideFuncDef = false
+		b.funcArgs = nil
+		b.trackViewDeps = false
+		b.viewDeps = nil
+		b.funcDeps = nil
+		b.qualifyDataSourceNamesInAST = false
+		b.semaCtx.Placeholders = placeholders
+		b.KeepPlaceholders = keepPlaceholders
+	}(b.semaCtx.Placeholders, b.KeepPlaceholders)
+
+	// The placeholders in the body are typed according to the arguments, and
+	// must not be replaced by the values of the placeholders of the enclosing
+	// statement.
+	b.semaCtx.Placeholders = tree.PlaceholderInfo{}
+	if err := b.semaCtx.Placeholders.Init(len(argTypes), nil /* typeHints */); err != nil {
+		panic(err)
+	}
+	copy(b.semaCtx.Placeholders.Types, argTypes)
+	b.KeepPlaceholders = true
+
+	b.pushWithFrame()
+	defScope := b.buildStmtAtRoot(body, []*types.T{retType}, inScope)
+	b.popWithFrame(defScope)
+
+	p := defScope.makePhysicalProps().Presentation
+	if len(p) != 1 {
+		panic(errors.WithDetail(
+			pgerror.Newf(pgcode.InvalidFunctionDefinition,
+				"return type mismatch in function declared to return %s", retType.SQLString()),
+			"Final statement must return exactly one column.",
+		))
+	}
+	if colType := b.factory.Metadata().ColumnMeta(p[0].ID).Type; !colType.Equivalent(retType) &&
+		colType.Family() != types.UnknownFamily {
+		panic(errors.WithDetailf(
+			pgerror.Newf(pgcode.InvalidFunctionDefinition,
+				"return type mismatch in function declared to return %s", retType.SQLString()),
+			"Actual return type is %s.", colType.SQLString(),
+		))
+	}
+
+	body, _ = tree.WalkStmt(b.funcArgs, body)
+
+	syntax := *cf
+	syntax.FuncName = funcName
+	syntax.Args = args
+	syntax.ReturnType = retType
+	syntax.Body = tree.AsStringWithFlags(body, tree.FmtParsable)
+
+	outScope = b.allocScope()
+	outScope.expr = b.factory.ConstructCreateFunction(
+		&memo.CreateFunctionPrivate{
+			Schema:   schID,
+			Syntax:   &syntax,
+			Deps:     b.viewDeps,
+			FuncDeps: b.funcDeps,
+		},
+	)
+	return outScope
+}
+
+// resolveFunctionType resolves the type of an argument or of the result of a
+// user-defined function.
+func (b *Builder) resolveFunctionType(ref tree.ResolvableTypeReference) *types.T {
+	typ, err := tree.ResolveType(b.ctx, ref, b.semaCtx.GetTypeResolver())
+	if err != nil {
+		panic(err)
+	}
+	if typ.UserDefined() ||
+		(typ.Family() == types.ArrayFamily && typ.ArrayContents().UserDefined()) {
+		panic(unimplemented.New("create function user-defined type",
+			"user-defined types cannot be used as argument or return types of functions"))
+	}
+	return typ
+}
+
+// funcArgReplacer replaces references to the arguments of a user-defined
+// function by position in its body with placeholders that are cast to the
+// argument types. References by name are resolved while the body is built
+// (see funcArgNames).
+type funcArgReplacer struct {
+	argTypes []*types.T
+	err      error
+}
+
+var _ tree.Visitor = &funcArgReplacer{}
+
+// VisitPre is part of the tree.Visitor interface.
+func (v *funcArgReplacer) VisitPre(expr tree.Expr) (recurse bool, newExpr tree.Expr) {
+	if v.err != nil {
+		return false, expr
+	}
+	if t, ok := expr.(*tree.Placeholder); ok {
+		if int(t.Idx) >= len(v.argTypes) {
+			v.err = pgerror.Newf(pgcode.UndefinedParameter, "there is no parameter %s", t)
+			return false, expr
+		}
+		return false, makeFuncArg(int(t.Idx), v.argTypes[t.Idx])
+	}
+	return true, expr
+}
+
+// VisitPost is part of the tree.Visitor interface.
+func (*funcArgReplacer) VisitPost(expr tree.Expr) tree.Expr { return expr }
+
+// funcArgNames resolves the references to the arguments of a user-defined
+// function by name in its body. Like in Postgres, a column with the same name
+// as an argument takes precedence over it, so a name only refers to an
+// argument if it does not resolve to a column. The names which were resolved
+// to arguments while building the body are then replaced in the body with
+// placeholders that are cast to the argument types.
+type funcArgNames struct {
+	argTypes []*types.T
+	argNames map[tree.Name]int
+	resolved map[tree.Expr]tree.Expr
+}
+
+var _ tree.Visitor = &funcArgNames{}
+
+// VisitPre is part of the tree.Visitor interface.
+func (v *funcArgNames) VisitPre(expr tree.Expr) (recurse bool, newExpr tree.Expr) {
+	switch expr.(type) {
+	case *tree.UnresolvedName, *tree.ColumnItem:
+		if arg, ok := v.resolved[expr]; ok {
+			return false, arg
+		}
+	}
+	return true, expr
+}
+
+// VisitPost is part of the tree.Visitor interface.
+func (*funcArgNames) VisitPost(expr tree.Expr) tree.Expr { return expr }
+
+// resolveFuncArg returns the reference to the argument of the user-defined
+// function being built which the given column reference refers to, or nil if
+// it does not refer to an argument. expr is the expression of the body which
+// the reference was normalized from, and is recorded so that it can be
+// replaced once the body is built.
+func (b *Builder) resolveFuncArg(expr tree.Expr, col *tree.ColumnItem, inScope *scope) tree.Expr {
+	v := b.funcArgs
+	if v == nil || col.TableName != nil {
+		return nil
+	}
+	idx, ok := v.argNames[col.ColumnName]
+	if !ok {
+		return nil
+	}
+	if _, err := col.Resolve(b.ctx, inScope); err == nil || pgerror.GetPGCode(err) != pgcode.UndefinedColumn {
+		return nil
+	}
+	arg := makeFuncArg(idx, v.argTypes[idx])
+	v.resolved[expr] = arg
+	return arg
+}
+
+// makeFuncArg returns a reference to the argument of a user-defined function
+// with the given index and type.
+func makeFuncArg(idx int, typ *types.T) tree.Expr {
+	return &tree.ParenExpr{Expr: &tree.CastExpr{
+		Expr:       &tree.Placeholder{Idx: tree.PlaceholderIdx(idx)},
+		Type:       typ,
+		SyntaxMode: tree.CastShort,
+	}}
+}
@@ -12,7 +12,11 @@ package optbuilder
 
 import (
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/colinfo"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt"
 	"github.com/cockroachdb/cockroach/pkg/sql/opt/memo"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/props/physical"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/types"
 )
@@ -32,6 +36,8 @@ func (b *Builder) buildExport(export *tree.Export, inScope *scope) (outScope *sc
 		texpr, emptyScope, nil /* outScope */, nil /* outCol */, nil, /* colRefs */
 	)
 	options := b.buildKVOptions(export.Options, emptyScope)
+	props := inputScope.makePhysicalProps()
+	partitionCols := b.resolveExportPartitionCols(export.PartitionBy, props.Presentation)
 
 	outScope = inScope.push()
 	b.synthesizeResultColumns(outScope, colinfo.ExportColumns)
@@ -40,14 +46,53 @@ func (b *Builder) buildExport(export *tree.Export, inScope *scope) (outScope *sc
 		fileName,
 		options,
 		&memo.ExportPrivate{
-			FileFormat: export.FileFormat,
-			Columns:    colsToColList(outScope.cols),
-			Props:      inputScope.makePhysicalProps(),
+			FileFormat:    export.FileFormat,
+			Columns:       colsToColList(outScope.cols),
+			Props:         props,
+			PartitionCols: partitionCols,
 		},
 	)
 	return outScope
 }
 
+// resolveExportPartitionCols resolves the names in the PARTITION BY clause of
+// an EXPORT statement to the exported columns of the query.
+func (b *Builder) resolveExportPartitionCols(
+	names tree.NameList, presentation physical.Presentation,
+) opt.ColList {
+	if len(names) == 0 {
+		return nil
+	}
+	cols := make(opt.ColList, len(names))
+	for i, name := range names {
+		for j := range presentation {
+			if presentation[j].Alias != string(name) {
+				continue
+			}
+			if cols[i] != 0 {
+				panic(pgerror.Newf(pgcode.AmbiguousColumn,
+					"PARTITION BY column %q is ambiguous", tree.ErrString(&names[i])))
+			}
+			cols[i] = presentation[j].ID
+		}
+		if cols[i] == 0 {
+			panic(pgerror.Newf(pgcode.UndefinedColumn,
+				"PARTITION BY column %q is not an exported column", tree.ErrString(&names[i])))
+		}
+		for _, prev := range cols[:i] {
+			if prev == cols[i] {
+				panic(pgerror.Newf(pgcode.DuplicateColumn,
+					"PARTITION BY column %q specified more than once", tree.ErrString(&names[i])))
+			}
+		}
+	}
+	if len(cols) == len(presentation) {
+		panic(pgerror.New(pgcode.InvalidParameterValue,
+			"EXPORT cannot partition by all the exported columns"))
+	}
+	return cols
+}
+
 func (b *Builder) buildKVOptions(opts tree.KVOptions, inScope *scope) memo.KVOptionsExpr {
 	res := make(memo.KVOptionsExpr, len(opts))
 	for i := range opts {
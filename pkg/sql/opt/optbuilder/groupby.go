@@ -96,6 +96,20 @@ type groupby struct {
 	// projects that expression.
 	groupStrs groupByStrSet
 
+	// groupingSets contains the columns of each grouping set, when GROUPING
+	// SETS, ROLLUP or CUBE produced more than one set. It is nil otherwise.
+	groupingSets []opt.ColSet
+
+	// groupingSetCol is an extra grouping column which holds the ordinal (in
+	// groupingSets) of the grouping set that each row belongs to. It is 0 if
+	// there is only one grouping set.
+	groupingSetCol opt.ColumnID
+
+	// inputRowCol is true for the rows of the input, and NULL for the row that
+	// stands in for an empty input in the grouping sets with no columns, which
+	// the aggregates ignore. It is 0 if there are no such grouping sets.
+	inputRowCol opt.ColumnID
+
 	// buildingGroupingCols is true while the grouping columns are being built.
 	// It is used to ensure that the builder does not throw a grouping error
 	// prematurely.
@@ -127,18 +141,27 @@ func (g *groupby) hasNonCommutativeAggregates() bool {
 	return false
 }
 
+// numGroupingCols returns the number of grouping columns, including the
+// grouping set column (if any).
+func (g *groupby) numGroupingCols() int {
+	if g.groupingSetCol != 0 {
+		return len(g.groupStrs) + 1
+	}
+	return len(g.groupStrs)
+}
+
 // groupingCols returns the columns in the aggInScope corresponding to grouping
 // columns.
 func (g *groupby) groupingCols() []scopeColumn {
 	// Grouping cols are always clustered at the end of the column list.
-	return g.aggInScope.cols[len(g.aggInScope.cols)-len(g.groupStrs):]
+	return g.aggInScope.cols[len(g.aggInScope.cols)-g.numGroupingCols():]
 }
 
 // getAggregateArgCols returns the columns in the aggInScope corresponding to
 // arguments to aggregate functions. If the aggregate has a filter, the column
 // corresponding to the filter's input will immediately follow the arguments.
 func (g *groupby) aggregateArgCols() []scopeColumn {
-	return g.aggInScope.cols[:len(g.aggInScope.cols)-len(g.groupStrs)]
+	return g.aggInScope.cols[:len(g.aggInScope.cols)-g.numGroupingCols()]
 }
 
 // getAggregateResultCols returns the columns in the aggOutScope corresponding
@@ -350,6 +373,14 @@ func (b *Builder) buildAggregation(having opt.ScalarExpr, fromScope *scope) (out
 		groupingColSet.Add(groupingCols[i].id)
 	}
 
+	// With multiple grouping sets, each input row is repeated once per grouping
+	// set. The grouping set column distinguishes the copies, and the grouping
+	// columns that are not part of a set are masked to NULL in its copy (see
+	// buildGroupingSets).
+	if g.groupingSetCol != 0 {
+		fromScope.expr = b.constructGroupingSetInput(g, fromScope.expr.(memo.RelExpr))
+	}
+
 	// If there are any aggregates that are ordering sensitive, build the
 	// aggregations as window functions over each group.
 	if g.hasNonCommutativeAggregates() {
@@ -362,6 +393,7 @@ func (b *Builder) buildAggregation(having opt.ScalarExpr, fromScope *scope) (out
 	haveOrderingSensitiveAgg := false
 	aggCols := g.aggregateResultCols()
 	argCols := g.aggregateArgCols()
+	filterCols := make([]opt.ColumnID, len(aggInfos))
 	var fromCols opt.ColSet
 	if b.subquery != nil {
 		// Only calculate the set of fromScope columns if it will be used below.
@@ -392,17 +424,11 @@ func (b *Builder) buildAggregation(having opt.ScalarExpr, fromScope *scope) (out
 			aggCols[i].scalar = b.factory.ConstructAggDistinct(aggCols[i].scalar)
 		}
 
-		// Wrap the aggregate function or the AggDistinct in an AggFilter operator
-		// if FILTER (WHERE ...) was specified in the query.
-		// TODO(justin): add a norm rule to push these filters below GroupBy where
-		// possible.
 		if agg.filter != nil {
 			// Column containing filter expression is always after the argument
 			// columns (which have already been processed).
-			colID := argCols[0].id
+			filterCols[i] = argCols[0].id
 			argCols = argCols[1:]
-			variable := b.factory.ConstructVariable(colID)
-			aggCols[i].scalar = b.factory.ConstructAggFilter(aggCols[i].scalar, variable)
 		}
 
 		if agg.isOrderingSensitive() {
@@ -427,7 +453,20 @@ func (b *Builder) buildAggregation(having opt.ScalarExpr, fromScope *scope) (out
 
 	// Construct the pre-projection, which renders the grouping columns and the
 	// aggregate arguments, as well as any additional order by columns.
+	g.passThroughInputRowCol()
 	b.constructProjectForScope(fromScope, g.aggInScope)
+	g.aggInScope.expr = b.constructInputRowFilters(g, g.aggInScope.expr.(memo.RelExpr), filterCols)
+
+	// Wrap the aggregate functions or the AggDistincts in an AggFilter operator
+	// if FILTER (WHERE ...) was specified in the query.
+	// TODO(justin): add a norm rule to push these filters below GroupBy where
+	// possible.
+	for i := range aggCols {
+		if filterCols[i] != 0 {
+			variable := b.factory.ConstructVariable(filterCols[i])
+			aggCols[i].scalar = b.factory.ConstructAggFilter(aggCols[i].scalar, variable)
+		}
+	}
 
 	g.aggOutScope.expr = b.constructGroupBy(
 		g.aggInScope.expr.(memo.RelExpr),
@@ -506,15 +545,363 @@ func (b *Builder) buildGroupingList(
 	// used in an aggregate function`. The builder cannot know whether there is
 	// a grouping error until the grouping columns are fully built.
 	g.buildingGroupingCols = true
+	sets := []opt.ColSet{{}}
 	for _, e := range groupBy {
-		b.buildGrouping(e, selects, projectionsScope, fromScope, g.aggInScope)
+		// The grouping sets of the GROUP BY clause are the cross product of the
+		// grouping sets of its items.
+		itemSets := b.buildGroupingSetItem(e, selects, projectionsScope, fromScope)
+		if len(sets)*len(itemSets) > maxGroupingSets {
+			panic(errTooManyGroupingSets)
+		}
+		product := make([]opt.ColSet, 0, len(sets)*len(itemSets))
+		for _, left := range sets {
+			for _, right := range itemSets {
+				product = append(product, left.Union(right))
+			}
+		}
+		sets = product
 	}
 	g.buildingGroupingCols = false
+
+	if len(sets) > 1 {
+		b.buildGroupingSets(sets, fromScope)
+	}
+}
+
+// maxGroupingSets is the maximum number of grouping sets that a GROUP BY
+// clause can expand to.
+const maxGroupingSets = 4096
+
+// maxCubeElements is the maximum number of elements in a CUBE, which expands
+// to 2^n grouping sets.
+const maxCubeElements = 12
+
+var errTooManyGroupingSets = pgerror.Newf(pgcode.ProgramLimitExceeded,
+	"too many grouping sets present (maximum %d)", maxGroupingSets)
+
+// buildGroupingSetItem builds the grouping columns for an item of a GROUP BY
+// clause and returns the grouping sets it expands to. A plain expression
+// expands to a single set; ROLLUP, CUBE and GROUPING SETS expand as follows:
+//
+//   ROLLUP (a, b)               => (a, b), (a), ()
+//   CUBE (a, b)                 => (a, b), (a), (b), ()
+//   GROUPING SETS (a, ROLLUP(b)) => (a), (b), ()
+//
+func (b *Builder) buildGroupingSetItem(
+	groupBy tree.Expr, selects tree.SelectExprs, projectionsScope, fromScope *scope,
+) []opt.ColSet {
+	gs, ok := groupBy.(*tree.GroupingSet)
+	if !ok {
+		cols := b.buildGrouping(groupBy, selects, projectionsScope, fromScope, fromScope.groupby.aggInScope)
+		return []opt.ColSet{cols}
+	}
+
+	var sets []opt.ColSet
+	switch gs.Type {
+	case tree.RollupGroupingSet, tree.CubeGroupingSet:
+		elems := make([]opt.ColSet, len(gs.Exprs))
+		for i, e := range gs.Exprs {
+			elems[i] = b.buildGrouping(e, selects, projectionsScope, fromScope, fromScope.groupby.aggInScope)
+		}
+		if gs.Type == tree.RollupGroupingSet {
+			// A ROLLUP expands to each prefix of its elements, longest first.
+			for n := len(elems); n >= 0; n-- {
+				var set opt.ColSet
+				for i := 0; i < n; i++ {
+					set.UnionWith(elems[i])
+				}
+				sets = append(sets, set)
+			}
+			break
+		}
+		// A CUBE expands to each subset of its elements. The first element
+		// corresponds to the most significant bit of the mask, so the sets are
+		// produced in the same order as Postgres.
+		if len(elems) > maxCubeElements {
+			panic(pgerror.Newf(pgcode.ProgramLimitExceeded,
+				"CUBE is limited to %d elements", maxCubeElements))
+		}
+		for mask := (1 << len(elems)) - 1; mask >= 0; mask-- {
+			var set opt.ColSet
+			for i := range elems {
+				if mask&(1<<(len(elems)-1-i)) != 0 {
+					set.UnionWith(elems[i])
+				}
+			}
+			sets = append(sets, set)
+		}
+
+	case tree.GroupingSetsGroupingSet:
+		for _, e := range gs.Exprs {
+			sets = append(sets, b.buildGroupingSetItem(e, selects, projectionsScope, fromScope)...)
+			if len(sets) > maxGroupingSets {
+				panic(errTooManyGroupingSets)
+			}
+		}
+
+	default:
+		panic(errors.AssertionFailedf("unknown grouping set type %s", gs.Type))
+	}
+	return sets
+}
+
+// buildGroupingSets sets up the aggregation to compute several grouping sets
+// at once. It adds a grouping set column to the grouping columns and replaces
+// each grouping column that is not part of every set with an expression that
+// is NULL for the sets that do not contain it. For example:
+//
+//   SELECT a, b, sum(c) FROM t GROUP BY ROLLUP (a, b)
+//
+// is built as an aggregation over the grouping columns
+//
+//   grouping_set,
+//   CASE WHEN grouping_set IN (0, 1) THEN a ELSE NULL END,
+//   CASE WHEN grouping_set IN (0) THEN b ELSE NULL END
+//
+// where each input row is repeated for every value of grouping_set (see
+// buildAggregation).
+//
+// Like a GROUP BY (), a grouping set with no columns produces a row even when
+// the input is empty (see constructGroupingSetInput).
+func (b *Builder) buildGroupingSets(sets []opt.ColSet, fromScope *scope) {
+	g := fromScope.groupby
+	md := b.factory.Metadata()
+
+	// The grouping set column is added after the other grouping columns.
+	g.groupingSetCol = md.AddColumn("grouping_set", types.Int)
+	g.aggInScope.cols = append(g.aggInScope.cols, scopeColumn{
+		typ: types.Int,
+		id:  g.groupingSetCol,
+	})
+	groupingCols := g.groupingCols()
+	groupingCols = groupingCols[:len(groupingCols)-1]
+
+	// The aggInScope columns may have been reallocated since the groupStrs
+	// were populated, so point them at the current columns.
+	for str, col := range g.groupStrs {
+		for i := range groupingCols {
+			if groupingCols[i].id == col.id {
+				g.groupStrs[str] = &groupingCols[i]
+				break
+			}
+		}
+	}
+
+	var inAllSets opt.ColSet
+	for i := range groupingCols {
+		inAllSets.Add(groupingCols[i].id)
+	}
+	for i := range sets {
+		inAllSets.IntersectionWith(sets[i])
+	}
+
+	for i := range groupingCols {
+		col := &groupingCols[i]
+		if inAllSets.Contains(col.id) {
+			continue
+		}
+		var inSets memo.ScalarListExpr
+		for j := range sets {
+			if sets[j].Contains(col.id) {
+				inSets = append(inSets, b.factory.ConstructConstVal(tree.NewDInt(tree.DInt(j)), types.Int))
+			}
+		}
+		inSetTypes := make([]*types.T, len(inSets))
+		for j := range inSetTypes {
+			inSetTypes[j] = types.Int
+		}
+		val := col.scalar
+		if val == nil {
+			val = b.factory.ConstructVariable(col.id)
+		}
+		masked := b.factory.ConstructCase(
+			memo.TrueSingleton,
+			memo.ScalarListExpr{
+				b.factory.ConstructWhen(
+					b.factory.ConstructIn(
+						b.factory.ConstructVariable(g.groupingSetCol),
+						b.factory.ConstructTuple(inSets, types.MakeTuple(inSetTypes)),
+					),
+					val,
+				),
+			},
+			b.factory.ConstructNull(col.typ),
+		)
+		oldID := col.id
+		b.populateSynthesizedColumn(col, masked)
+		for j := range sets {
+			if sets[j].Contains(oldID) {
+				sets[j].Remove(oldID)
+				sets[j].Add(col.id)
+			}
+		}
+	}
+	g.groupingSets = sets
+}
+
+// constructGroupingSetInput repeats each row of the input once per grouping
+// set, with the grouping set column holding the ordinal of the set. If there
+// are grouping sets with no columns, they produce a row even when the input is
+// empty: the grouping sets are left joined with the input, and the row that
+// stands in for the empty input is kept for those sets only. The aggregates
+// ignore that row, since it has a NULL inputRowCol (see
+// constructInputRowFilters).
+func (b *Builder) constructGroupingSetInput(g *groupby, input memo.RelExpr) memo.RelExpr {
+	var emptySets memo.ScalarListExpr
+	for i := range g.groupingSets {
+		if g.groupingSets[i].Empty() {
+			emptySets = append(emptySets, b.factory.ConstructConstVal(tree.NewDInt(tree.DInt(i)), types.Int))
+		}
+	}
+	if len(emptySets) == 0 {
+		return b.factory.ConstructInnerJoin(
+			input, b.constructGroupingSetValues(g), memo.TrueFilter, memo.EmptyJoinPrivate,
+		)
+	}
+
+	g.inputRowCol = b.factory.Metadata().AddColumn("input_row", types.Bool)
+	input = b.factory.ConstructProject(
+		input,
+		memo.ProjectionsExpr{b.factory.ConstructProjectionsItem(memo.TrueSingleton, g.inputRowCol)},
+		input.Relational().OutputCols,
+	)
+	join := b.factory.ConstructLeftJoin(
+		b.constructGroupingSetValues(g), input, memo.TrueFilter, memo.EmptyJoinPrivate,
+	)
+	emptySetTypes := make([]*types.T, len(emptySets))
+	for i := range emptySetTypes {
+		emptySetTypes[i] = types.Int
+	}
+	filter := b.factory.ConstructOr(
+		b.factory.ConstructIsNot(
+			b.factory.ConstructVariable(g.inputRowCol), b.factory.ConstructNull(types.Bool),
+		),
+		b.factory.ConstructIn(
+			b.factory.ConstructVariable(g.groupingSetCol),
+			b.factory.ConstructTuple(emptySets, types.MakeTuple(emptySetTypes)),
+		),
+	)
+	return b.factory.ConstructSelect(join, memo.FiltersExpr{b.factory.ConstructFiltersItem(filter)})
+}
+
+// passThroughInputRowCol adds the inputRowCol (if any) to the aggInScope, so
+// that it is passed through by the pre-projection.
+func (g *groupby) passThroughInputRowCol() {
+	if g.inputRowCol != 0 {
+		g.aggInScope.extraCols = append(g.aggInScope.extraCols, scopeColumn{
+			name: "input_row",
+			typ:  types.Bool,
+			id:   g.inputRowCol,
+		})
+	}
+}
+
+// constructInputRowFilters makes each aggregate ignore the row that stands in
+// for an empty input in the grouping sets with no columns, by filtering it on
+// the inputRowCol. filterCols holds the FILTER column of each aggregate, or 0;
+// it is updated in place, and the columns which combine a FILTER with the
+// inputRowCol are projected on top of the input.
+func (b *Builder) constructInputRowFilters(
+	g *groupby, input memo.RelExpr, filterCols []opt.ColumnID,
+) memo.RelExpr {
+	if g.inputRowCol == 0 {
+		return input
+	}
+	var projections memo.ProjectionsExpr
+	for i := range filterCols {
+		if filterCols[i] == 0 {
+			filterCols[i] = g.inputRowCol
+			continue
+		}
+		filter := b.factory.ConstructAnd(
+			b.factory.ConstructVariable(filterCols[i]), b.factory.ConstructVariable(g.inputRowCol),
+		)
+		filterCols[i] = b.factory.Metadata().AddColumn("input_row_filter", types.Bool)
+		projections = append(projections, b.factory.ConstructProjectionsItem(filter, filterCols[i]))
+	}
+	if len(projections) == 0 {
+		return input
+	}
+	return b.factory.ConstructProject(input, projections, input.Relational().OutputCols)
 }
 
+// constructGroupingSetValues constructs a Values expression with a row for
+// each grouping set, which produces the grouping set column.
+func (b *Builder) constructGroupingSetValues(g *groupby) memo.RelExpr {
+	tupleTyp := types.MakeTuple([]*types.T{types.Int})
+	rows := make(memo.ScalarListExpr, len(g.groupingSets))
+	for i := range rows {
+		rows[i] = b.factory.ConstructTuple(
+			memo.ScalarListExpr{b.factory.ConstructConstVal(tree.NewDInt(tree.DInt(i)), types.Int)},
+			tupleTyp,
+		)
+	}
+	return b.factory.ConstructValues(rows, &memo.ValuesPrivate{
+		Cols: opt.ColList{g.groupingSetCol},
+		ID:   b.factory.Metadata().NextUniqueID(),
+	})
+}
+
+// maxGroupingArgs is the maximum number of arguments to grouping(), since the
+// result is a bit mask stored in an INT4 in Postgres.
+const maxGroupingArgs = 31
+
+// buildGroupingFunction builds a call to grouping(), which returns a bit mask
+// with a bit set for each argument that is not part of the grouping set of the
+// current row. The last argument corresponds to the least significant bit.
+func (b *Builder) buildGroupingFunction(
+	f *tree.FuncExpr, inScope, outScope *scope, outCol *scopeColumn,
+) opt.ScalarExpr {
+	if len(f.Exprs) > maxGroupingArgs {
+		panic(pgerror.Newf(pgcode.TooManyArguments,
+			"grouping() must have fewer than %d arguments", maxGroupingArgs+1))
+	}
+	g := inScope.groupby
+	if !inScope.inGroupingContext() || inScope.inAgg || g.buildingGroupingCols {
+		panic(errGroupingArgs)
+	}
+	cols := make([]opt.ColumnID, len(f.Exprs))
+	for i, e := range f.Exprs {
+		col, ok := g.groupStrs[symbolicExprStr(e.(tree.TypedExpr))]
+		if !ok {
+			panic(errGroupingArgs)
+		}
+		cols[i] = col.id
+	}
+
+	var out opt.ScalarExpr
+	if g.groupingSetCol == 0 {
+		// There is a single grouping set which contains all the arguments.
+		out = b.factory.ConstructConstVal(tree.NewDInt(0), types.Int)
+	} else {
+		whens := make(memo.ScalarListExpr, len(g.groupingSets))
+		for i, set := range g.groupingSets {
+			var mask int64
+			for _, col := range cols {
+				mask <<= 1
+				if !set.Contains(col) {
+					mask |= 1
+				}
+			}
+			whens[i] = b.factory.ConstructWhen(
+				b.factory.ConstructConstVal(tree.NewDInt(tree.DInt(i)), types.Int),
+				b.factory.ConstructConstVal(tree.NewDInt(tree.DInt(mask)), types.Int),
+			)
+		}
+		out = b.factory.ConstructCase(
+			b.factory.ConstructVariable(g.groupingSetCol), whens, b.factory.ConstructNull(types.Int),
+		)
+	}
+	return b.finishBuildScalar(f, out, inScope, outScope, outCol)
+}
+
+var errGroupingArgs = pgerror.New(pgcode.Grouping,
+	"arguments to grouping() must be grouping expressions of the associated query level")
+
 // buildGrouping builds a set of memo groups that represent a GROUP BY
 // expression. The expression (or expressions, if we have a star) is added to
-// groupStrs and to the aggInScope.
+// groupStrs and to the aggInScope. Returns the set of grouping columns that
+// the expression refers to.
 //
 //
 // groupBy          The given GROUP BY expression.
@@ -528,7 +915,7 @@ func (b *Builder) buildGroupingList(
 //                  as the aggregate function arguments.
 func (b *Builder) buildGrouping(
 	groupBy tree.Expr, selects tree.SelectExprs, projectionsScope, fromScope, aggInScope *scope,
-) {
+) opt.ColSet {
 	// Unwrap parenthesized expressions like "((a))" to "a".
 	groupBy = tree.StripParens(groupBy)
 	alias := ""
@@ -622,11 +1009,13 @@ func (b *Builder) buildGrouping(
 	exprs = flattenTuples(exprs)
 
 	// Finally, build each of the GROUP BY columns.
+	var cols opt.ColSet
 	for _, e := range exprs {
 		// If a grouping column has already been added, don't add it again.
 		// GROUP BY a, a is semantically equivalent to GROUP BY a.
 		exprStr := symbolicExprStr(e)
-		if _, ok := fromScope.groupby.groupStrs[exprStr]; ok {
+		if col, ok := fromScope.groupby.groupStrs[exprStr]; ok {
+			cols.Add(col.id)
 			continue
 		}
 
@@ -636,7 +1025,9 @@ func (b *Builder) buildGrouping(
 		col := b.addColumn(aggInScope, alias, e)
 		b.buildScalar(e, fromScope, aggInScope, col, nil)
 		fromScope.groupby.groupStrs[exprStr] = col
+		cols.Add(col.id)
 	}
+	return cols
 }
 
 // buildAggArg builds a scalar expression which is used as an input in some form
@@ -884,6 +1275,10 @@ func isSQLFn(def *tree.FunctionDefinition) bool {
 	return def.Class == tree.SQLClass
 }
 
+func isGroupingFn(def *tree.FunctionDefinition) bool {
+	return def.Name == "grouping"
+}
+
 func newGroupingError(name *tree.Name) error {
 	return pgerror.Newf(pgcode.Grouping,
 		"column \"%s\" must appear in the GROUP BY clause or be used in an aggregate function",
@@ -894,8 +1289,14 @@ func newGroupingError(name *tree.Name) error {
 // allowImplicitGroupingColumn returns true if col is part of a table and the
 // the groupby metadata indicates that we are grouping on the entire PK of that
 // table. In that case, we can allow col as an "implicit" grouping column, even
-// if it is not specified in the query.
+// if it is not specified in the query. This is not allowed with multiple
+// grouping sets.
 func (b *Builder) allowImplicitGroupingColumn(colID opt.ColumnID, g *groupby) bool {
+	if g.groupingSetCol != 0 {
+		// The column would not be masked in the grouping sets that do not
+		// contain the PK.
+		return false
+	}
 	md := b.factory.Metadata()
 	colMeta := md.ColumnMeta(colID)
 	if colMeta.Table == 0 {
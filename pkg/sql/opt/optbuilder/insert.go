@@ -211,6 +211,11 @@ func (b *Builder) buildInsert(ins *tree.Insert, inScope *scope) (outScope *scope
 			// DO NOTHING clause is not present.
 			b.checkPrivilege(depName, tab, privilege.UPDATE)
 		}
+
+		if tab.TriggerCount() > 0 {
+			panic(unimplemented.NewWithIssuef(28296,
+				"UPSERT and INSERT ... ON CONFLICT are not supported on tables with triggers"))
+		}
 	}
 
 	var mb mutationBuilder
@@ -641,6 +646,9 @@ func (mb *mutationBuilder) buildInsert(returning tree.ReturningExprs) {
 	// check constraint, refer to the correct columns.
 	mb.disambiguateColumns()
 
+	// Call any BEFORE triggers, which may skip some of the rows.
+	mb.buildBeforeTriggers(tree.TriggerInsert)
+
 	// Keep a reference to the scope before the check constraint columns are
 	// projected. We use this scope when projecting the partial index put
 	// columns because the check columns are not in-scope for those expressions.
@@ -656,6 +664,8 @@ func (mb *mutationBuilder) buildInsert(returning tree.ReturningExprs) {
 
 	mb.buildFKChecksForInsert()
 
+	mb.buildAfterTriggers(tree.TriggerInsert)
+
 	private := mb.makeMutationPrivate(returning != nil)
 	mb.outScope.expr = mb.b.factory.ConstructInsert(
 		mb.outScope.expr, mb.uniqueChecks, mb.fkChecks, private,
@@ -1090,6 +1100,8 @@ func (mb *mutationBuilder) buildUpsert(returning tree.ReturningExprs) {
 	// added to the partial index.
 	mb.projectPartialIndexPutCols(preCheckScope)
 
+	mb.buildUniqueChecksForUpsert()
+
 	mb.buildFKChecksForUpsert()
 
 	private := mb.makeMutationPrivate(returning != nil)
@@ -22,6 +22,25 @@ import (
 
 // buildUniqueChecksForInsert builds uniqueness check queries for an insert.
 func (mb *mutationBuilder) buildUniqueChecksForInsert() {
+	mb.buildUniqueChecks(func(uniqueOrdinal int) bool { return true })
+}
+
+// buildUniqueChecksForUpdate builds uniqueness check queries for an update.
+// Only the constraints whose columns are updated need to be checked.
+func (mb *mutationBuilder) buildUniqueChecksForUpdate() {
+	mb.buildUniqueChecks(mb.uniqueColsUpdated)
+}
+
+// buildUniqueChecksForUpsert builds uniqueness check queries for an upsert.
+func (mb *mutationBuilder) buildUniqueChecksForUpsert() {
+	mb.buildUniqueChecks(func(uniqueOrdinal int) bool { return true })
+}
+
+// buildUniqueChecks builds a uniqueness check query for each unique constraint
+// without an index for which needCheck returns true. The insertion check works
+// for updates and upserts too, since it simply checks that the unique columns
+// of the new or updated rows do not match those of any other row.
+func (mb *mutationBuilder) buildUniqueChecks(needCheck func(uniqueOrdinal int) bool) {
 	uniqueCount := mb.tab.UniqueCount()
 	if uniqueCount == 0 {
 		// No relevant unique checks.
@@ -33,7 +52,7 @@ func (mb *mutationBuilder) buildUniqueChecksForInsert() {
 	needChecks := false
 	i := 0
 	for ; i < uniqueCount; i++ {
-		if mb.tab.Unique(i).WithoutIndex() {
+		if mb.tab.Unique(i).WithoutIndex() && needCheck(i) {
 			needChecks = true
 			break
 		}
@@ -50,13 +69,25 @@ func (mb *mutationBuilder) buildUniqueChecksForInsert() {
 	for ; i < uniqueCount; i++ {
 		// If this constraint is already enforced by an index we don't need to plan
 		// a check.
-		if mb.tab.Unique(i).WithoutIndex() && h.init(mb, i) {
+		if mb.tab.Unique(i).WithoutIndex() && needCheck(i) && h.init(mb, i) {
 			mb.uniqueChecks = append(mb.uniqueChecks, h.buildInsertionCheck())
 		}
 	}
 	telemetry.Inc(sqltelemetry.UniqueChecksUseCounter)
 }
 
+// uniqueColsUpdated returns true if any of the columns of the given unique
+// constraint are updated.
+func (mb *mutationBuilder) uniqueColsUpdated(uniqueOrdinal int) bool {
+	uc := mb.tab.Unique(uniqueOrdinal)
+	for i, n := 0, uc.ColumnCount(); i < n; i++ {
+		if mb.updateColIDs[uc.ColumnOrdinal(mb.tab, i)] != 0 {
+			return true
+		}
+	}
+	return false
+}
+
 // uniqueCheckHelper is a type associated with a single unique constraint and
 // is used to build the "leaves" of a unique check expression, namely the
 // WithScan of the mutation input and the Scan of the table.
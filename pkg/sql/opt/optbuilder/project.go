@@ -186,6 +186,10 @@ func (b *Builder) resolveColRef(e tree.Expr, inScope *scope) tree.TypedExpr {
 	unresolved, ok := e.(*tree.UnresolvedName)
 	if ok && !unresolved.Star && unresolved.NumParts == 1 {
 		colName := unresolved.Parts[0]
+		col := &tree.ColumnItem{ColumnName: tree.Name(colName)}
+		if arg := b.resolveFuncArg(unresolved, col, inScope); arg != nil {
+			return inScope.resolveType(arg, types.Any)
+		}
 		_, srcMeta, _, err := inScope.FindSourceProvidingColumn(b.ctx, tree.Name(colName))
 		if err != nil {
 			panic(err)
@@ -392,6 +392,9 @@ func (b *Builder) buildScalar(
 		}
 		out = b.factory.ConstructTuple(els, t.ResolvedType())
 
+	case *inlinedFuncArg:
+		out = t.scalar
+
 	case *subquery:
 		out, _ = b.buildSingleRowSubquery(t, inScope)
 		// Perform correctness checks on the outer cols, update colRefs and
@@ -484,10 +487,7 @@ func (b *Builder) buildFunction(
 		}
 	}
 
-	def, err := f.Func.Resolve(b.semaCtx.SearchPath)
-	if err != nil {
-		panic(err)
-	}
+	def := b.resolveFunction(&f.Func)
 
 	if isAggregate(def) {
 		panic(errors.AssertionFailedf("aggregate function should have been replaced"))
@@ -497,11 +497,21 @@ func (b *Builder) buildFunction(
 		panic(errors.AssertionFailedf("window function should have been replaced"))
 	}
 
+	if isGroupingFn(def) {
+		return b.buildGroupingFunction(f, inScope, outScope, outCol)
+	}
+
 	args := make(memo.ScalarListExpr, len(f.Exprs))
 	for i, pexpr := range f.Exprs {
 		args[i] = b.buildScalar(pexpr.(tree.TypedExpr), inScope, nil, nil, colRefs)
 	}
 
+	if o := f.ResolvedOverload(); o != nil && o.UDF != nil {
+		if inlined := b.tryInlineUDF(f, o, args); inlined != nil {
+			return b.finishBuildScalar(f, inlined, inScope, outScope, outCol)
+		}
+	}
+
 	// Construct a private FuncOpDef that refers to a resolved function overload.
 	out = b.factory.ConstructFunction(args, &memo.FunctionPrivate{
 		Name:       def.Name,
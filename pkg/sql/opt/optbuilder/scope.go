@@ -907,9 +907,17 @@ func (s *scope) VisitPre(expr tree.Expr) (recurse bool, newExpr tree.Expr) {
 		if err != nil {
 			panic(err)
 		}
+		if col, ok := vn.(*tree.ColumnItem); ok {
+			if arg := s.builder.resolveFuncArg(t, col, s); arg != nil {
+				return true, arg
+			}
+		}
 		return s.VisitPre(vn)
 
 	case *tree.ColumnItem:
+		if arg := s.builder.resolveFuncArg(t, t, s); arg != nil {
+			return true, arg
+		}
 		colI, err := t.Resolve(s.builder.ctx, s)
 		if err != nil {
 			panic(err)
@@ -917,10 +925,7 @@ func (s *scope) VisitPre(expr tree.Expr) (recurse bool, newExpr tree.Expr) {
 		return false, colI.(*scopeColumn)
 
 	case *tree.FuncExpr:
-		def, err := t.Func.Resolve(s.builder.semaCtx.SearchPath)
-		if err != nil {
-			panic(err)
-		}
+		def := s.builder.resolveFunction(&t.Func)
 
 		if isGenerator(def) && s.replaceSRFs {
 			expr = s.replaceSRF(t, def)
@@ -98,9 +98,7 @@ func (b *Builder) buildZip(exprs tree.Exprs, inScope *scope) (outScope *scope) {
 
 		var def *tree.FunctionDefinition
 		if funcExpr, ok := texpr.(*tree.FuncExpr); ok {
-			if def, err = funcExpr.Func.Resolve(b.semaCtx.SearchPath); err != nil {
-				panic(err)
-			}
+			def = b.resolveFunction(&funcExpr.Func)
 		}
 
 		var outCol *scopeColumn
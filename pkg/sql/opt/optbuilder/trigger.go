@@ -0,0 +1,326 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package optbuilder
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/opt"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/cat"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/memo"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/props"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+)
+
+// Row-level triggers call a user-defined function for each row that is
+// inserted, updated or deleted in a table. The arguments of the call can refer
+// to the new values of the row as new.<column>, and to the old values of the
+// row as old.<column>. References to the values which do not exist for the
+// event (old values for INSERT, new values for DELETE) are NULL.
+//
+// BEFORE triggers are projected on top of the input to the mutation, which is
+// filtered so that rows for which the trigger function returns NULL are not
+// mutated:
+//
+//   insert t
+//    └── project
+//         └── select
+//              ├── project
+//              │    ├── values
+//              │    └── projections
+//              │         └── f(column1, NULL) [as=trigger1]
+//              └── filters
+//                   └── trigger1 IS NOT NULL
+//
+// AFTER triggers are run once the mutation has completed, in the same way as
+// foreign key cascades: the mutation input is buffered, and the trigger is
+// planned as a projection of the call over a WithScan of the buffer (see
+// afterTriggerBuilder).
+//
+// Both kinds of triggers are part of the mutation statement, so they run in
+// its transaction.
+
+// buildBeforeTriggers projects a call to each BEFORE trigger of the target
+// table which fires on the given event, and filters out the rows for which
+// the trigger function returns NULL. The triggers are called in the order in
+// which they are defined on the table.
+//
+// Assumes that mb.outScope contains the values of all columns of the table.
+func (mb *mutationBuilder) buildBeforeTriggers(event tree.TriggerEvent) {
+	for i, n := 0, mb.tab.TriggerCount(); i < n; i++ {
+		trig := mb.tab.Trigger(i)
+		if trig.ActionTime != tree.TriggerBefore || !trig.FiresOn(event) {
+			continue
+		}
+
+		colOrds, newCols, oldCols := mb.triggerRowCols(event)
+		alias := fmt.Sprintf("trigger%d", i+1)
+		var triggerCol opt.ColumnID
+		mb.outScope, triggerCol = mb.b.buildTriggerCall(
+			mb.outScope, mb.tab, &trig, colOrds, newCols, oldCols, alias,
+		)
+
+		f := mb.b.factory
+		filtered := f.ConstructSelect(
+			mb.outScope.expr,
+			memo.FiltersExpr{f.ConstructFiltersItem(
+				f.ConstructIsNot(f.ConstructVariable(triggerCol), memo.NullSingleton),
+			)},
+		)
+
+		// Project away the result of the call, so that it is not passed to the
+		// mutation along with the columns of the table.
+		projectionsScope := mb.outScope.replace()
+		var passthrough opt.ColSet
+		for _, col := range mb.outScope.cols {
+			if col.id != triggerCol {
+				col.scalar = nil
+				projectionsScope.cols = append(projectionsScope.cols, col)
+				passthrough.Add(col.id)
+			}
+		}
+		projectionsScope.expr = f.ConstructProject(filtered, nil /* projections */, passthrough)
+		mb.outScope = projectionsScope
+	}
+}
+
+// buildAfterTriggers adds a cascade for each AFTER trigger of the target table
+// which fires on the given event. The cascades are run after the mutation, in
+// the order in which the triggers are defined on the table.
+//
+// Assumes that mb.outScope.expr is the input to the mutation.
+func (mb *mutationBuilder) buildAfterTriggers(event tree.TriggerEvent) {
+	for i, n := 0, mb.tab.TriggerCount(); i < n; i++ {
+		trig := mb.tab.Trigger(i)
+		if trig.ActionTime != tree.TriggerAfter || !trig.FiresOn(event) {
+			continue
+		}
+
+		mb.ensureWithID()
+		colOrds, newCols, oldCols := mb.triggerRowCols(event)
+		mb.cascades = append(mb.cascades, memo.FKCascade{
+			FKName: trig.Name.String(),
+			Builder: &afterTriggerBuilder{
+				mutatedTable:   mb.tab,
+				triggerOrdinal: i,
+				colOrds:        colOrds,
+			},
+			WithID:    mb.withID,
+			OldValues: oldCols,
+			NewValues: newCols,
+		})
+	}
+}
+
+// triggerRowCols returns the ordinals of the public columns of the target
+// table, along with the input columns which hold the new and old values of
+// those columns for the given event. newCols is nil for DELETE, and oldCols is
+// nil for INSERT.
+func (mb *mutationBuilder) triggerRowCols(
+	event tree.TriggerEvent,
+) (colOrds []int, newCols, oldCols opt.ColList) {
+	for ord, n := 0, mb.tab.ColumnCount(); ord < n; ord++ {
+		if mb.tab.Column(ord).Kind() != cat.Ordinary {
+			continue
+		}
+		var newCol, oldCol opt.ColumnID
+		switch event {
+		case tree.TriggerInsert:
+			newCol = mb.insertColIDs[ord]
+		case tree.TriggerUpdate:
+			oldCol = mb.fetchColIDs[ord]
+			if newCol = mb.updateColIDs[ord]; newCol == 0 {
+				newCol = oldCol
+			}
+		case tree.TriggerDelete:
+			oldCol = mb.fetchColIDs[ord]
+		}
+		if (event != tree.TriggerDelete && newCol == 0) || (event != tree.TriggerInsert && oldCol == 0) {
+			continue
+		}
+		colOrds = append(colOrds, ord)
+		if event != tree.TriggerDelete {
+			newCols = append(newCols, newCol)
+		}
+		if event != tree.TriggerInsert {
+			oldCols = append(oldCols, oldCol)
+		}
+	}
+	return colOrds, newCols, oldCols
+}
+
+// buildTriggerCall builds a projection of the call to the function of the
+// given trigger on top of inScope, and returns the new scope along with the
+// column which holds the result of the call.
+//
+// newCols and oldCols are the columns of inScope which hold the new and old
+// values of the table columns with the given ordinals. Either can be nil, in
+// which case the corresponding references in the call are NULL.
+func (b *Builder) buildTriggerCall(
+	inScope *scope,
+	tab cat.Table,
+	trig *cat.Trigger,
+	colOrds []int,
+	newCols, oldCols opt.ColList,
+	alias string,
+) (outScope *scope, col opt.ColumnID) {
+	expr, err := parser.ParseExpr(trig.Call)
+	if err != nil {
+		panic(err)
+	}
+
+	md := b.factory.Metadata()
+	newTable := tree.MakeUnqualifiedTableName("new")
+	oldTable := tree.MakeUnqualifiedTableName("old")
+
+	// Build a scope in which the columns of the row are named new.<column> and
+	// old.<column>.
+	rowScope := b.allocScope()
+	addRowCols := func(table tree.TableName, cols opt.ColList) {
+		for i, id := range cols {
+			rowScope.cols = append(rowScope.cols, scopeColumn{
+				name:  tab.Column(colOrds[i]).ColName(),
+				table: table,
+				typ:   md.ColumnMeta(id).Type,
+				id:    id,
+			})
+		}
+	}
+	addRowCols(newTable, newCols)
+	addRowCols(oldTable, oldCols)
+
+	// Replace the references to the missing values with NULLs.
+	var missing tree.Name
+	switch {
+	case newCols == nil:
+		missing = newTable.ObjectName
+	case oldCols == nil:
+		missing = oldTable.ObjectName
+	}
+	if missing != "" {
+		expr, err = tree.SimpleVisit(expr, func(expr tree.Expr) (bool, tree.Expr, error) {
+			name, ok := expr.(*tree.UnresolvedName)
+			if !ok || name.Star || name.NumParts != 2 || tree.Name(name.Parts[1]) != missing {
+				return true, expr, nil
+			}
+			for _, ord := range colOrds {
+				if c := tab.Column(ord); c.ColName() == tree.Name(name.Parts[0]) {
+					return false, &tree.CastExpr{
+						Expr: tree.DNull, Type: c.DatumType(), SyntaxMode: tree.CastShort,
+					}, nil
+				}
+			}
+			return false, expr, nil
+		})
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	texpr := rowScope.resolveType(expr, types.Any)
+
+	outScope = inScope.replace()
+	outScope.appendColumnsFromScope(inScope)
+	scopeCol := b.addColumn(outScope, alias, texpr)
+	b.buildScalar(texpr, rowScope, outScope, scopeCol, nil)
+	b.constructProjectForScope(inScope, outScope)
+
+	// The result of the call cannot be referenced by name, so that it does not
+	// conflict with the columns of the table.
+	scopeCol.clearName()
+	return outScope, scopeCol.id
+}
+
+// afterTriggerBuilder is a memo.CascadeBuilder implementation for AFTER
+// triggers.
+//
+// It provides a method to build a query which calls the trigger function for
+// each mutated row, equivalent to a query like:
+//
+//   SELECT f(new.a, old.a) FROM original_mutation_input
+//
+// The rows are read from the buffered mutation input with a WithScan:
+//
+//   project
+//    ├── columns: trigger1:5
+//    ├── with-scan &1
+//    │    ├── columns: a:3 a:4
+//    │    └── mapping:
+//    │         ├──  a_new:2 => a:3
+//    │         └──  t.a:1 => a:4
+//    └── projections
+//         └── f(a:3, a:4) [as=trigger1:5]
+//
+type afterTriggerBuilder struct {
+	mutatedTable cat.Table
+	// triggerOrdinal is the ordinal of the trigger on the mutated table (can be
+	// passed to mutatedTable.Trigger).
+	triggerOrdinal int
+	// colOrds are the ordinals of the table columns whose new and old values
+	// are passed in newValues and oldValues.
+	colOrds []int
+}
+
+var _ memo.CascadeBuilder = &afterTriggerBuilder{}
+
+// Build is part of the memo.CascadeBuilder interface.
+func (tb *afterTriggerBuilder) Build(
+	ctx context.Context,
+	semaCtx *tree.SemaContext,
+	evalCtx *tree.EvalContext,
+	catalog cat.Catalog,
+	factoryI interface{},
+	binding opt.WithID,
+	bindingProps *props.Relational,
+	oldValues, newValues opt.ColList,
+) (_ memo.RelExpr, err error) {
+	return buildCascadeHelper(ctx, semaCtx, evalCtx, catalog, factoryI, func(b *Builder) memo.RelExpr {
+		trig := tb.mutatedTable.Trigger(tb.triggerOrdinal)
+
+		md := b.factory.Metadata()
+		inCols := make(opt.ColList, 0, len(newValues)+len(oldValues))
+		inCols = append(inCols, newValues...)
+		inCols = append(inCols, oldValues...)
+		outCols := make(opt.ColList, len(inCols))
+		for i := range outCols {
+			c := md.ColumnMeta(inCols[i])
+			outCols[i] = md.AddColumn(c.Alias, c.Type)
+		}
+
+		// Construct a dummy operator as the binding.
+		md.AddWithBinding(binding, b.factory.ConstructFakeRel(&memo.FakeRelPrivate{
+			Props: bindingProps,
+		}))
+		inScope := b.allocScope()
+		inScope.expr = b.factory.ConstructWithScan(&memo.WithScanPrivate{
+			With:    binding,
+			InCols:  inCols,
+			OutCols: outCols,
+			ID:      md.NextUniqueID(),
+		})
+
+		var newCols, oldCols opt.ColList
+		if len(newValues) > 0 {
+			newCols = outCols[:len(newValues)]
+		}
+		if len(oldValues) > 0 {
+			oldCols = outCols[len(newValues):]
+		}
+		outScope, _ := b.buildTriggerCall(
+			inScope, tb.mutatedTable, &trig, tb.colOrds, newCols, oldCols,
+			fmt.Sprintf("trigger%d", tb.triggerOrdinal+1),
+		)
+		return outScope.expr
+	})
+}
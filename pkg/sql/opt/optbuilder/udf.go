@@ -0,0 +1,185 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package optbuilder
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/opt"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/cat"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/memo"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/props"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util/errorutil/unimplemented"
+)
+
+// resolveFunction resolves the given function reference, which can refer to a
+// builtin or a user-defined function.
+func (b *Builder) resolveFunction(fn *tree.ResolvableFunctionReference) *tree.FunctionDefinition {
+	def, err := fn.ResolveWith(b.ctx, b.semaCtx.SearchPath, b.semaCtx.FunctionResolver)
+	if err != nil {
+		panic(err)
+	}
+	if def.IsUserDefined() {
+		if b.insideViewDef {
+			panic(unimplemented.New("view user-defined function",
+				"user-defined functions cannot be used in views"))
+		}
+		// The definition of the function is not versioned along with the data
+		// sources in the metadata, so the memo cannot be reused.
+		b.DisableMemoReuse = true
+		if b.insideFuncDef {
+			id := cat.StableID(def.Definition[0].(*tree.Overload).UDF.ID)
+			if !b.funcDeps.Contains(id) {
+				b.funcDeps = append(b.funcDeps, id)
+			}
+		}
+	}
+	return def
+}
+
+// inlinedFuncArg is an argument of a user-defined function that is being
+// inlined. It replaces the references to the argument in the function body,
+// and is built as the already built argument expression.
+type inlinedFuncArg struct {
+	tree.TypedExpr
+
+	scalar opt.ScalarExpr
+}
+
+// Walk is part of the tree.Expr interface.
+func (a *inlinedFuncArg) Walk(v tree.Visitor) tree.Expr {
+	return a
+}
+
+// TypeCheck is part of the tree.Expr interface.
+func (a *inlinedFuncArg) TypeCheck(
+	_ context.Context, _ *tree.SemaContext, desired *types.T,
+) (tree.TypedExpr, error) {
+	return a, nil
+}
+
+// Variable is part of the tree.VariableExpr interface. This prevents the
+// argument from being evaluated during normalization.
+func (*inlinedFuncArg) Variable() {}
+
+// tryInlineUDF attempts to inline the body of a call to a user-defined
+// function, so that it is optimized along with the rest of the query rather
+// than being executed separately for every call. Only functions whose body is
+// a single SELECT of an expression, without any other clause, are inlined.
+// Volatile arguments are only inlined if they are referenced exactly once in
+// the body. The body expression is returned, or nil if the function cannot be
+// inlined.
+func (b *Builder) tryInlineUDF(
+	f *tree.FuncExpr, o *tree.Overload, args memo.ScalarListExpr,
+) opt.ScalarExpr {
+	if o.UDF.ReturnsSet {
+		return nil
+	}
+	stmt, err := parser.ParseOne(o.UDF.Body)
+	if err != nil {
+		panic(err)
+	}
+	sel, ok := stmt.AST.(*tree.Select)
+	if !ok || sel.With != nil || sel.OrderBy != nil || sel.Limit != nil || sel.Locking != nil {
+		return nil
+	}
+	clause, ok := sel.Select.(*tree.SelectClause)
+	if !ok || clause.Distinct || len(clause.DistinctOn) != 0 || len(clause.Exprs) != 1 ||
+		len(clause.From.Tables) != 0 || clause.Where != nil || clause.GroupBy != nil ||
+		clause.Having != nil || clause.Window != nil || clause.TableSelect {
+		return nil
+	}
+
+	// Replace the references to the arguments with the built arguments, and
+	// check that the expression is simple enough to be inlined.
+	v := udfInliner{b: b, f: f, args: args, refs: make([]int, len(args))}
+	body, _ := tree.WalkExpr(&v, clause.Exprs[0].Expr)
+	if v.notInlinable {
+		return nil
+	}
+	for i := range args {
+		var p props.Shared
+		memo.BuildSharedProps(args[i], &p)
+		if p.VolatilitySet.HasVolatile() && v.refs[i] != 1 {
+			return nil
+		}
+	}
+
+	texpr := b.allocScope().resolveType(body, o.FixedReturnType())
+	out := b.buildScalar(texpr, b.allocScope(), nil /* outScope */, nil /* outCol */, nil /* colRefs */)
+
+	// The body of a function that is declared stable or immutable may not be
+	// inlined if it is more volatile than that, since the optimizer would then
+	// treat it according to its actual volatility.
+	var p props.Shared
+	memo.BuildSharedProps(out, &p)
+	if (p.VolatilitySet.HasVolatile() && o.Volatility < tree.VolatilityVolatile) ||
+		(p.VolatilitySet.HasStable() && o.Volatility < tree.VolatilityStable) {
+		return nil
+	}
+	if typ := f.ResolvedType(); !out.DataType().Identical(typ) {
+		out = b.factory.ConstructCast(out, typ)
+	}
+	return out
+}
+
+// udfInliner replaces the placeholders in the body of a user-defined function
+// with the arguments of the function call, and determines whether the body can
+// be inlined.
+type udfInliner struct {
+	b    *Builder
+	f    *tree.FuncExpr
+	args memo.ScalarListExpr
+
+	// refs counts the number of references to each argument.
+	refs []int
+
+	// notInlinable is set if the body contains expressions which prevent
+	// inlining.
+	notInlinable bool
+}
+
+var _ tree.Visitor = &udfInliner{}
+
+// VisitPre is part of the tree.Visitor interface.
+func (v *udfInliner) VisitPre(expr tree.Expr) (recurse bool, newExpr tree.Expr) {
+	switch t := expr.(type) {
+	case *tree.Placeholder:
+		if int(t.Idx) >= len(v.args) {
+			v.notInlinable = true
+			return false, expr
+		}
+		v.refs[t.Idx]++
+		return false, &inlinedFuncArg{
+			TypedExpr: v.f.Exprs[t.Idx].(tree.TypedExpr),
+			scalar:    v.args[t.Idx],
+		}
+
+	case *tree.Subquery:
+		v.notInlinable = true
+		return false, expr
+
+	case *tree.FuncExpr:
+		// Aggregate, window and generator functions would change the meaning of
+		// the enclosing query.
+		if def := v.b.resolveFunction(&t.Func); def.Class != tree.NormalClass || t.WindowDef != nil {
+			v.notInlinable = true
+			return false, expr
+		}
+	}
+	return true, expr
+}
+
+// VisitPost is part of the tree.Visitor interface.
+func (*udfInliner) VisitPost(expr tree.Expr) tree.Expr { return expr }
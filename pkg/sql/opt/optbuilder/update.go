@@ -323,6 +323,9 @@ func (mb *mutationBuilder) buildUpdate(returning tree.ReturningExprs) {
 	// check constraint, refer to the correct columns.
 	mb.disambiguateColumns()
 
+	// Call any BEFORE triggers, which may skip some of the rows.
+	mb.buildBeforeTriggers(tree.TriggerUpdate)
+
 	// Keep a reference to the scope before the check constraint columns are
 	// projected. We use this scope when projecting the partial index put
 	// columns because the check columns are not in-scope for those expressions.
@@ -333,8 +336,12 @@ func (mb *mutationBuilder) buildUpdate(returning tree.ReturningExprs) {
 	// Add partial index put boolean columns to the input.
 	mb.projectPartialIndexPutCols(preCheckScope)
 
+	mb.buildUniqueChecksForUpdate()
+
 	mb.buildFKChecksForUpdate()
 
+	mb.buildAfterTriggers(tree.TriggerUpdate)
+
 	private := mb.makeMutationPrivate(returning != nil)
 	for _, col := range mb.extraAccessibleCols {
 		if col.id != 0 {
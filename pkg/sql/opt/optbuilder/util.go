@@ -52,6 +52,9 @@ func (b *Builder) expandStar(
 	if b.insideViewDef {
 		panic(unimplemented.NewWithIssue(10028, "views do not currently support * expressions"))
 	}
+	if b.insideFuncDef {
+		panic(unimplemented.New("function *", "functions do not currently support * expressions"))
+	}
 	switch t := expr.(type) {
 	case *tree.TupleStar:
 		texpr := inScope.resolveType(t.Expr, types.Any)
@@ -540,6 +543,17 @@ func (b *Builder) resolveTableForMutation(
 		panic(pgerror.Newf(pgcode.WrongObjectType, "cannot mutate materialized view %q", tab.Name()))
 	}
 
+	// A function which mutates a table depends on all of its columns.
+	if b.trackViewDeps {
+		dep := opt.ViewDep{DataSource: tab}
+		for i, n := 0, tab.ColumnCount(); i < n; i++ {
+			if tab.Column(i).Kind() == cat.Ordinary {
+				dep.ColumnOrdinals.Add(i)
+			}
+		}
+		b.viewDeps = append(b.viewDeps, dep)
+	}
+
 	return tab, depName, alias, columns
 }
 
@@ -579,8 +593,8 @@ func (b *Builder) resolveDataSource(
 	tn *tree.TableName, priv privilege.Kind,
 ) (cat.DataSource, cat.DataSourceName) {
 	var flags cat.Flags
-	if b.insideViewDef {
-		// Avoid taking table leases when we're creating a view.
+	if b.insideViewDef || b.insideFuncDef {
+		// Avoid taking table leases when we're creating a view or a function.
 		flags.AvoidDescriptorCaches = true
 	}
 	ds, resName, err := b.catalog.ResolveDataSource(b.ctx, flags, tn)
@@ -603,8 +617,8 @@ func (b *Builder) resolveDataSource(
 // error.
 func (b *Builder) resolveDataSourceRef(ref *tree.TableRef, priv privilege.Kind) cat.DataSource {
 	var flags cat.Flags
-	if b.insideViewDef {
-		// Avoid taking table leases when we're creating a view.
+	if b.insideViewDef || b.insideFuncDef {
+		// Avoid taking table leases when we're creating a view or a function.
 		flags.AvoidDescriptorCaches = true
 	}
 	ds, _, err := b.catalog.ResolveDataSourceByID(b.ctx, flags, cat.StableID(ref.TableID))
@@ -241,6 +241,7 @@ func (b *Builder) buildAggregationAsWindow(
 	// Construct the pre-projection, which renders the grouping columns and the
 	// aggregate arguments, as well as any additional order by columns.
 	g.aggInScope.appendColumnsFromScope(fromScope)
+	g.passThroughInputRowCol()
 	b.constructProjectForScope(fromScope, g.aggInScope)
 
 	// Build the arguments, partitions and orderings for each aggregate.
@@ -266,7 +267,7 @@ func (b *Builder) buildAggregationAsWindow(
 	}
 
 	// Initialize the aggregate expression.
-	aggregateExpr := g.aggInScope.expr
+	aggregateExpr := b.constructInputRowFilters(g, g.aggInScope.expr.(memo.RelExpr), filterCols)
 
 	// frames accumulates the set of distinct window frames we're computing over
 	// so that we can group functions over the same partition and ordering.
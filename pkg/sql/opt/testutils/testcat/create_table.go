@@ -184,7 +184,12 @@ func (tc *Catalog) CreateTable(stmt *tree.CreateTable) *Table {
 		switch def := def.(type) {
 		case *tree.UniqueConstraintTableDef:
 			if def.WithoutIndex {
-				tab.addUniqueConstraint(def.Name, def.Columns, def.WithoutIndex)
+				tab.addUniqueConstraint(def.Name, def.Columns, def.WithoutIndex, def.Deferrability)
+			} else if def.Deferrability != tree.ConstraintNotDeferrable {
+				// As in the real catalog, a DEFERRABLE unique constraint is backed by
+				// a non-unique index and enforced by unique checks.
+				tab.addIndex(&def.IndexTableDef, nonUniqueIndex)
+				tab.addUniqueConstraint(def.Name, def.Columns, true /* withoutIndex */, def.Deferrability)
 			} else if !def.PrimaryKey {
 				tab.addIndex(&def.IndexTableDef, uniqueIndex)
 			}
@@ -202,6 +207,7 @@ func (tc *Catalog) CreateTable(stmt *tree.CreateTable) *Table {
 						def.Unique.ConstraintName,
 						tree.IndexElemList{{Column: def.Name}},
 						def.Unique.WithoutIndex,
+						tree.ConstraintNotDeferrable,
 					)
 				} else {
 					tab.addIndex(
@@ -446,13 +452,17 @@ func (tc *Catalog) resolveFK(tab *Table, d *tree.ForeignKeyConstraintTableDef) {
 		matchMethod:              d.Match,
 		deleteAction:             d.Actions.Delete,
 		updateAction:             d.Actions.Update,
+		deferrability:            d.Deferrability,
 	}
 	tab.outboundFKs = append(tab.outboundFKs, fk)
 	targetTable.inboundFKs = append(targetTable.inboundFKs, fk)
 }
 
 func (tt *Table) addUniqueConstraint(
-	name tree.Name, columns tree.IndexElemList, withoutIndex bool,
+	name tree.Name,
+	columns tree.IndexElemList,
+	withoutIndex bool,
+	deferrability tree.ConstraintDeferrability,
 ) {
 	cols := make([]int, len(columns))
 	for i, c := range columns {
@@ -474,6 +484,7 @@ func (tt *Table) addUniqueConstraint(
 		columnOrdinals: cols,
 		withoutIndex:   withoutIndex,
 		validated:      true,
+		deferrability:  deferrability,
 	}
 	tt.uniqueConstraints = append(tt.uniqueConstraints, u)
 }
@@ -530,7 +541,9 @@ func (tt *Table) addIndexWithVersion(
 ) *Index {
 	// Add a unique constraint if this is a primary or unique index.
 	if typ != nonUniqueIndex {
-		tt.addUniqueConstraint(def.Name, def.Columns, false /* withoutIndex */)
+		tt.addUniqueConstraint(
+			def.Name, def.Columns, false /* withoutIndex */, tree.ConstraintNotDeferrable,
+		)
 	}
 
 	idx := &Index{
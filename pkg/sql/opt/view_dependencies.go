@@ -57,3 +57,17 @@ func (dep ViewDep) GetColumnNames() ([]string, bool) {
 
 	return nil, false
 }
+
+// FuncDeps contains the IDs of the user-defined functions that are called by
+// the body of a user-defined function.
+type FuncDeps []cat.StableID
+
+// Contains returns true if the given function ID is part of the dependencies.
+func (deps FuncDeps) Contains(id cat.StableID) bool {
+	for _, dep := range deps {
+		if dep == id {
+			return true
+		}
+	}
+	return false
+}
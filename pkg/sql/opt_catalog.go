@@ -598,11 +598,18 @@ type optTable struct {
 	outboundFKs []optForeignKeyConstraint
 	inboundFKs  []optForeignKeyConstraint
 
+	// uniqueConstraints holds the DEFERRABLE unique constraints of the table,
+	// which are enforced by unique checks rather than by their (non-unique)
+	// index.
+	uniqueConstraints []optUniqueConstraint
+
 	// checkConstraints is the set of check constraints for this table. It
 	// can be different from desc's constraints because of synthesized
 	// constraints for user defined types.
 	checkConstraints []cat.CheckConstraint
 
+	triggers []cat.Trigger
+
 	// colMap is a mapping from unique ColumnID to column ordinal within the
 	// table. This is a common lookup that needs to be fast.
 	colMap catalog.TableColMap
@@ -762,6 +769,8 @@ func newOptTable(
 			match:             fk.Match,
 			deleteAction:      fk.OnDelete,
 			updateAction:      fk.OnUpdate,
+			deferrable:        fk.Deferrable,
+			initiallyDeferred: fk.InitiallyDeferred,
 		})
 	}
 	for i := range ot.desc.InboundFKs {
@@ -776,9 +785,35 @@ func newOptTable(
 			match:             fk.Match,
 			deleteAction:      fk.OnDelete,
 			updateAction:      fk.OnUpdate,
+			deferrable:        fk.Deferrable,
+			initiallyDeferred: fk.InitiallyDeferred,
 		})
 	}
 
+	// Deferrable unique constraints are enforced as soon as their index starts
+	// being added, so that the rows written while the index is backfilled are
+	// checked too. They are only validated once their index is public.
+	addUniqueConstraint := func(idx *descpb.IndexDescriptor, validated bool) {
+		if idx.DeferrableUnique {
+			ot.uniqueConstraints = append(ot.uniqueConstraints, optUniqueConstraint{
+				name:              idx.Name,
+				table:             ot.ID(),
+				columns:           idx.ColumnIDs,
+				validated:         validated,
+				initiallyDeferred: idx.InitiallyDeferred,
+			})
+		}
+	}
+	for i := range ot.desc.Indexes {
+		addUniqueConstraint(&ot.desc.Indexes[i], true /* validated */)
+	}
+	for i := range ot.desc.Mutations {
+		m := &ot.desc.Mutations[i]
+		if idx := m.GetIndex(); idx != nil && m.Direction == descpb.DescriptorMutation_ADD {
+			addUniqueConstraint(idx, false /* validated */)
+		}
+	}
+
 	ot.primaryFamily.init(ot, &desc.Families[0])
 	ot.families = make([]optFamily, len(desc.Families)-1)
 	for i := range ot.families {
@@ -821,6 +856,28 @@ func newOptTable(
 	}
 	ot.checkConstraints = append(ot.checkConstraints, synthesizedChecks...)
 
+	ot.triggers = make([]cat.Trigger, len(ot.desc.Triggers))
+	for i := range ot.desc.Triggers {
+		trig := &ot.desc.Triggers[i]
+		ot.triggers[i] = cat.Trigger{
+			Name:       tree.Name(trig.Name),
+			ActionTime: tree.TriggerBefore,
+			Call:       trig.Call,
+		}
+		if trig.ActionTime == descpb.TableDescriptor_Trigger_AFTER {
+			ot.triggers[i].ActionTime = tree.TriggerAfter
+		}
+		if trig.OnInsert {
+			ot.triggers[i].Events = append(ot.triggers[i].Events, tree.TriggerInsert)
+		}
+		if trig.OnUpdate {
+			ot.triggers[i].Events = append(ot.triggers[i].Events, tree.TriggerUpdate)
+		}
+		if trig.OnDelete {
+			ot.triggers[i].Events = append(ot.triggers[i].Events, tree.TriggerDelete)
+		}
+	}
+
 	// Add stats last, now that other metadata is initialized.
 	if stats != nil {
 		ot.stats = make([]optTableStat, len(stats))
@@ -1040,14 +1097,24 @@ func (ot *optTable) InboundForeignKey(i int) cat.ForeignKeyConstraint {
 
 // UniqueCount is part of the cat.Table interface.
 func (ot *optTable) UniqueCount() int {
-	// TODO(rytaft): return the number of unique constraints (both with and
-	//  without indexes).
-	return 0
+	// TODO(rytaft): also return the unique constraints which are enforced by
+	//  unique indexes.
+	return len(ot.uniqueConstraints)
 }
 
 // Unique is part of the cat.Table interface.
 func (ot *optTable) Unique(i int) cat.UniqueConstraint {
-	panic(errors.AssertionFailedf("unique constraint [%d] does not exist", i))
+	return &ot.uniqueConstraints[i]
+}
+
+// TriggerCount is part of the cat.Table interface.
+func (ot *optTable) TriggerCount() int {
+	return len(ot.triggers)
+}
+
+// Trigger is part of the cat.Table interface.
+func (ot *optTable) Trigger(i int) cat.Trigger {
+	return ot.triggers[i]
 }
 
 // lookupColumnOrdinal returns the ordinal of the column with the given ID. A
@@ -1456,6 +1523,9 @@ type optForeignKeyConstraint struct {
 	match        descpb.ForeignKeyReference_Match
 	deleteAction descpb.ForeignKeyReference_Action
 	updateAction descpb.ForeignKeyReference_Action
+
+	deferrable        bool
+	initiallyDeferred bool
 }
 
 var _ cat.ForeignKeyConstraint = &optForeignKeyConstraint{}
@@ -1527,6 +1597,78 @@ func (fk *optForeignKeyConstraint) UpdateReferenceAction() tree.ReferenceAction
 	return descpb.ForeignKeyReferenceActionType[fk.updateAction]
 }
 
+// Deferrability is part of the cat.ForeignKeyConstraint interface.
+func (fk *optForeignKeyConstraint) Deferrability() tree.ConstraintDeferrability {
+	switch {
+	case fk.initiallyDeferred:
+		return tree.DeferrableInitiallyDeferred
+	case fk.deferrable:
+		return tree.DeferrableInitiallyImmediate
+	default:
+		return tree.ConstraintNotDeferrable
+	}
+}
+
+// optUniqueConstraint implements cat.UniqueConstraint for the DEFERRABLE unique
+// constraints, which are backed by a non-unique index.
+type optUniqueConstraint struct {
+	name    string
+	table   cat.StableID
+	columns []descpb.ColumnID
+
+	validated         bool
+	initiallyDeferred bool
+}
+
+var _ cat.UniqueConstraint = &optUniqueConstraint{}
+
+// Name is part of the cat.UniqueConstraint interface.
+func (u *optUniqueConstraint) Name() string {
+	return u.name
+}
+
+// TableID is part of the cat.UniqueConstraint interface.
+func (u *optUniqueConstraint) TableID() cat.StableID {
+	return u.table
+}
+
+// ColumnCount is part of the cat.UniqueConstraint interface.
+func (u *optUniqueConstraint) ColumnCount() int {
+	return len(u.columns)
+}
+
+// ColumnOrdinal is part of the cat.UniqueConstraint interface.
+func (u *optUniqueConstraint) ColumnOrdinal(tab cat.Table, i int) int {
+	if tab.ID() != u.table {
+		panic(errors.AssertionFailedf(
+			"invalid table %d passed to ColumnOrdinal (expected %d)",
+			tab.ID(), u.table,
+		))
+	}
+
+	ord, _ := tab.(*optTable).lookupColumnOrdinal(u.columns[i])
+	return ord
+}
+
+// WithoutIndex is part of the cat.UniqueConstraint interface. The index of a
+// deferrable unique constraint does not enforce it.
+func (u *optUniqueConstraint) WithoutIndex() bool {
+	return true
+}
+
+// Validated is part of the cat.UniqueConstraint interface.
+func (u *optUniqueConstraint) Validated() bool {
+	return u.validated
+}
+
+// Deferrability is part of the cat.UniqueConstraint interface.
+func (u *optUniqueConstraint) Deferrability() tree.ConstraintDeferrability {
+	if u.initiallyDeferred {
+		return tree.DeferrableInitiallyDeferred
+	}
+	return tree.DeferrableInitiallyImmediate
+}
+
 // optVirtualTable is similar to optTable but is used with virtual tables.
 type optVirtualTable struct {
 	desc *tabledesc.Immutable
@@ -1821,6 +1963,16 @@ func (ot *optVirtualTable) Unique(i int) cat.UniqueConstraint {
 	panic(errors.AssertionFailedf("no unique constraints"))
 }
 
+// TriggerCount is part of the cat.Table interface.
+func (ot *optVirtualTable) TriggerCount() int {
+	return 0
+}
+
+// Trigger is part of the cat.Table interface.
+func (ot *optVirtualTable) Trigger(i int) cat.Trigger {
+	panic(errors.AssertionFailedf("no triggers"))
+}
+
 // optVirtualIndex is a dummy implementation of cat.Index for the indexes
 // reported by a virtual table. The index assumes that table column 0 is a dummy
 // PK column.
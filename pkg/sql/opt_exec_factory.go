@@ -1637,6 +1637,49 @@ func (ef *execFactory) ConstructCreateView(
 	deps opt.ViewDeps,
 ) (exec.Node, error) {
 
+	planDeps, err := makePlanDependencies(deps)
+	if err != nil {
+		return nil, err
+	}
+
+	return &createViewNode{
+		viewName:     viewName,
+		ifNotExists:  ifNotExists,
+		replace:      replace,
+		materialized: materialized,
+		persistence:  persistence,
+		viewQuery:    viewQuery,
+		dbDesc:       schema.(*optSchema).database,
+		columns:      columns,
+		planDeps:     planDeps,
+	}, nil
+}
+
+// ConstructCreateFunction is part of the exec.Factory interface.
+func (ef *execFactory) ConstructCreateFunction(
+	schema cat.Schema, cf *tree.CreateFunction, deps opt.ViewDeps, funcDeps opt.FuncDeps,
+) (exec.Node, error) {
+	planDeps, err := makePlanDependencies(deps)
+	if err != nil {
+		return nil, err
+	}
+	funcIDs := make([]descpb.ID, len(funcDeps))
+	for i := range funcDeps {
+		funcIDs[i] = descpb.ID(funcDeps[i])
+	}
+
+	return &createFunctionNode{
+		n:        cf,
+		dbDesc:   schema.(*optSchema).database,
+		planDeps: planDeps,
+		funcDeps: funcIDs,
+	}, nil
+}
+
+// makePlanDependencies converts the dependencies of a view or function
+// definition into references that can be stored in the descriptors of the
+// referenced tables.
+func makePlanDependencies(deps opt.ViewDeps) (planDependencies, error) {
 	planDeps := make(planDependencies, len(deps))
 	for _, d := range deps {
 		desc, err := getDescForDataSource(d.DataSource)
@@ -1659,18 +1702,7 @@ func (ef *execFactory) ConstructCreateView(
 		entry.deps = append(entry.deps, ref)
 		planDeps[desc.ID] = entry
 	}
-
-	return &createViewNode{
-		viewName:     viewName,
-		ifNotExists:  ifNotExists,
-		replace:      replace,
-		materialized: materialized,
-		persistence:  persistence,
-		viewQuery:    viewQuery,
-		dbDesc:       schema.(*optSchema).database,
-		columns:      columns,
-		planDeps:     planDeps,
-	}, nil
+	return planDeps, nil
 }
 
 // ConstructSequenceSelect is part of the exec.Factory interface.
@@ -1687,11 +1719,12 @@ func (ef *execFactory) ConstructSaveTable(
 
 // ConstructErrorIfRows is part of the exec.Factory interface.
 func (ef *execFactory) ConstructErrorIfRows(
-	input exec.Node, mkErr exec.MkErrFn,
+	input exec.Node, mkErr exec.MkErrFn, deferrable *exec.DeferrableCheck,
 ) (exec.Node, error) {
 	return &errorIfRowsNode{
-		plan:  input.(planNode),
-		mkErr: mkErr,
+		plan:       input.(planNode),
+		mkErr:      mkErr,
+		deferrable: deferrable,
 	}, nil
 }
 
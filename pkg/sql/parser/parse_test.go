@@ -224,6 +224,11 @@ func TestParse(t *testing.T) {
 		{`CREATE TABLE a (b INT8, c STRING, FOREIGN KEY (b) REFERENCES other MATCH FULL ON DELETE CASCADE ON UPDATE SET NULL)`},
 		{`CREATE TABLE a (b INT8, c STRING, FOREIGN KEY (b) REFERENCES other MATCH FULL ON DELETE SET NULL ON UPDATE RESTRICT)`},
 		{`CREATE TABLE a (b INT8, c STRING, FOREIGN KEY (b, c) REFERENCES other MATCH FULL)`},
+		{`CREATE TABLE a (b INT8, c STRING, FOREIGN KEY (b) REFERENCES other DEFERRABLE)`},
+		{`CREATE TABLE a (b INT8, c STRING, FOREIGN KEY (b) REFERENCES other ON DELETE CASCADE DEFERRABLE INITIALLY DEFERRED)`},
+		{`CREATE TABLE a (b INT8 REFERENCES other DEFERRABLE INITIALLY DEFERRED, c STRING)`},
+		{`CREATE TABLE a (b INT8, UNIQUE WITHOUT INDEX (b) DEFERRABLE INITIALLY DEFERRED)`},
+		{`ALTER TABLE a ADD CONSTRAINT fk FOREIGN KEY (b) REFERENCES other (c) DEFERRABLE`},
 		{`CREATE TABLE a (b INT8, c STRING, FOREIGN KEY (b, c) REFERENCES other (x, y) MATCH FULL)`},
 		{`CREATE TABLE a (b INT8, c STRING, FOREIGN KEY (b, c) REFERENCES other)`},
 		{`CREATE TABLE a (b INT8, c STRING, FOREIGN KEY (b, c) REFERENCES other (x, y))`},
@@ -409,6 +414,11 @@ func TestParse(t *testing.T) {
 		{`CREATE TYPE a.b AS ENUM ('a', 'b', 'c')`},
 		{`CREATE TYPE a.b.c AS ENUM ('a', 'b', 'c')`},
 
+		{`CREATE FUNCTION f() RETURNS INT8 LANGUAGE SQL AS 'SELECT 1'`},
+		{`CREATE FUNCTION a.b.f(x INT8, STRING) RETURNS INT8 LANGUAGE SQL IMMUTABLE AS 'SELECT x + 1'`},
+		{`CREATE FUNCTION f(x INT8) RETURNS SETOF INT8 LANGUAGE SQL STABLE AS 'SELECT a FROM t WHERE b = x'`},
+		{`CREATE FUNCTION f(a t) RETURNS t LANGUAGE SQL VOLATILE AS e'SELECT \'a\''`},
+
 		{`DROP SCHEMA a`},
 		{`DROP SCHEMA a, b`},
 		{`DROP SCHEMA IF EXISTS a, b, c`},
@@ -435,6 +445,18 @@ func TestParse(t *testing.T) {
 		{`DROP TYPE IF EXISTS db.sc.a, sc.a CASCADE`},
 		{`DROP TYPE IF EXISTS db.sc.a, sc.a RESTRICT`},
 
+		{`DROP FUNCTION f`},
+		{`DROP FUNCTION f()`},
+		{`DROP FUNCTION a.b.f(INT8, STRING), g`},
+		{`DROP FUNCTION IF EXISTS f(x INT8) CASCADE`},
+		{`DROP FUNCTION f RESTRICT`},
+
+		{`CREATE TRIGGER a BEFORE INSERT ON t FOR EACH ROW EXECUTE FUNCTION f()`},
+		{`CREATE TRIGGER a AFTER INSERT OR UPDATE OR DELETE ON db.sc.t FOR EACH ROW EXECUTE FUNCTION sc.f(new.a, old.b + 1, 'c')`},
+		{`DROP TRIGGER a ON t`},
+		{`DROP TRIGGER IF EXISTS a ON db.sc.t CASCADE`},
+		{`DROP TRIGGER a ON t RESTRICT`},
+
 		{`DELETE FROM a`},
 		{`EXPLAIN DELETE FROM a`},
 		{`DELETE FROM a.b`},
@@ -743,6 +765,24 @@ func TestParse(t *testing.T) {
 		{`DEALLOCATE a`},
 		{`DEALLOCATE ALL`},
 
+		{`DECLARE a CURSOR FOR SELECT 1`},
+		{`DECLARE a SCROLL CURSOR FOR SELECT * FROM t`},
+		{`DECLARE a NO SCROLL CURSOR WITH HOLD FOR SELECT * FROM t ORDER BY k`},
+		{`DECLARE "a b" CURSOR WITH HOLD FOR VALUES (1), (2)`},
+		{`FETCH 1 a`},
+		{`FETCH -1 a`},
+		{`FETCH 0 a`},
+		{`FETCH ALL a`},
+		{`FETCH BACKWARD ALL a`},
+		{`FETCH FIRST a`},
+		{`FETCH LAST a`},
+		{`FETCH ABSOLUTE -3 a`},
+		{`FETCH RELATIVE 2 a`},
+		{`MOVE 5 a`},
+		{`MOVE BACKWARD ALL a`},
+		{`CLOSE a`},
+		{`CLOSE ALL`},
+
 		// Tables are the default, but can also be specified with
 		// GRANT x ON TABLE y. However, the stringer does not output TABLE.
 		{`GRANT SELECT ON TABLE foo TO root`},
@@ -1111,6 +1151,13 @@ func TestParse(t *testing.T) {
 		{`SELECT 1 FROM t GROUP BY a`},
 		{`SELECT 1 FROM t GROUP BY a, b`},
 		{`SELECT 1 FROM t GROUP BY ()`},
+		{`SELECT 1 FROM t GROUP BY ROLLUP (a, b)`},
+		{`SELECT 1 FROM t GROUP BY a, ROLLUP (b)`},
+		{`SELECT 1 FROM t GROUP BY CUBE (a)`},
+		{`SELECT 1 FROM t GROUP BY CUBE (a, b), c`},
+		{`SELECT 1 FROM t GROUP BY GROUPING SETS ((a, b), (a), ())`},
+		{`SELECT 1 FROM t GROUP BY GROUPING SETS (a, ROLLUP (b, c), CUBE (d))`},
+		{`SELECT grouping(a, b) FROM t GROUP BY ROLLUP (a, b)`},
 		{`SELECT sum(x ORDER BY y) FROM t`},
 		{`SELECT sum(x ORDER BY y, z) FROM t`},
 
@@ -1259,6 +1306,10 @@ func TestParse(t *testing.T) {
 		{`SET a = 3.0`},
 		{`SET a = $1`},
 		{`SET a = off`},
+		{`SET CONSTRAINTS ALL DEFERRED`},
+		{`SET CONSTRAINTS ALL IMMEDIATE`},
+		{`SET CONSTRAINTS a, b DEFERRED`},
+		{`SET CONSTRAINTS a IMMEDIATE`},
 		{`SET TRANSACTION READ ONLY`},
 		{`SET TRANSACTION READ WRITE`},
 		{`SET TRANSACTION ISOLATION LEVEL SERIALIZABLE`},
@@ -1649,6 +1700,8 @@ func TestParse(t *testing.T) {
 		{`SHOW BACKUP RANGES 'bar'`},
 		{`SHOW BACKUP FILES 'bar'`},
 		{`SHOW BACKUP FILES 'bar' WITH foo = 'bar'`},
+		{`SHOW BACKUP DIFF 'foo' AND 'bar'`},
+		{`SHOW BACKUP DIFF $1 AND $2 WITH foo = 'bar'`},
 
 		{`SHOW BACKUPS IN 'bar'`},
 		{`SHOW BACKUPS IN $1`},
@@ -1677,6 +1730,10 @@ func TestParse(t *testing.T) {
 		{`RESTORE TABLE foo FROM $4 IN $1, $2, 'bar'`},
 		{`RESTORE TABLE foo, baz FROM 'bar'`},
 		{`RESTORE TABLE foo, baz FROM 'bar' AS OF SYSTEM TIME '1'`},
+		{`RESTORE TABLE foo AS foo_restored FROM 'bar'`},
+		{`RESTORE TABLE db.foo AS db.foo_restored FROM $1, $2 AS OF SYSTEM TIME '1'`},
+		{`RESTORE TABLE db.sc.foo AS db.sc.foo_restored FROM 'abc' IN $1, $2`},
+		{`RESTORE TABLE foo AS foo_restored FROM 'bar' WITH skip_missing_foreign_keys`},
 
 		{`RESTORE DATABASE foo FROM 'bar'`},
 		{`EXPLAIN RESTORE DATABASE foo FROM 'bar'`},
@@ -1694,6 +1751,18 @@ func TestParse(t *testing.T) {
 		{`BACKUP TABLE foo TO 'bar' WITH revision_history, detached`},
 		{`RESTORE TABLE foo FROM 'bar' WITH skip_missing_foreign_keys, skip_missing_sequences, detached`},
 
+		{`VERIFY BACKUP FROM 'bar'`},
+		{`VERIFY BACKUP FROM $1, $2`},
+		{`VERIFY BACKUP FROM ($1, $2), ($3, $4)`},
+		{`VERIFY BACKUP FROM 'foo' IN 'bar'`},
+		{`VERIFY BACKUP FROM $1 IN $2, $3 WITH check_contents`},
+		{`VERIFY BACKUP FROM 'bar' WITH check_contents, encryption_passphrase = 'secret', detached`},
+		{`EXPLAIN VERIFY BACKUP FROM 'bar'`},
+		{`COMPACT BACKUP FROM 'foo', 'bar' TO 'baz'`},
+		{`COMPACT BACKUP FROM ($1, $2), ($3, $4) TO $5`},
+		{`COMPACT BACKUP FROM $1 IN $2, $3 TO $4 WITH encryption_passphrase = 'secret', detached`},
+		{`EXPLAIN COMPACT BACKUP FROM 'foo' TO 'bar'`},
+
 		{`IMPORT TABLE foo CREATE USING 'nodelocal://0/some/file' CSV DATA ('path/to/some/file', $1) WITH temp = 'path/to/temp'`},
 		{`EXPLAIN IMPORT TABLE foo CREATE USING 'nodelocal://0/some/file' CSV DATA ('path/to/some/file', $1) WITH temp = 'path/to/temp'`},
 		{`IMPORT TABLE foo CREATE USING 'nodelocal://0/some/file' DELIMITED DATA ('path/to/some/file', $1)`},
@@ -1710,6 +1779,8 @@ func TestParse(t *testing.T) {
 		{`EXPORT INTO CSV 'a' FROM SELECT * FROM a`},
 		{`EXPORT INTO CSV 's3://my/path/%part%.csv' WITH delimiter = '|' FROM TABLE a`},
 		{`EXPORT INTO CSV 's3://my/path/%part%.csv' WITH delimiter = '|' FROM SELECT a, sum(b) FROM c WHERE d = 1 ORDER BY sum(b) DESC LIMIT 10`},
+		{`EXPORT INTO CSV 's3://my/path' PARTITION BY (a) FROM TABLE a`},
+		{`EXPORT INTO CSV 's3://my/path' PARTITION BY (a, b) WITH compression = 'zstd', max_file_size = '64MiB' FROM SELECT * FROM a`},
 
 		{`SET ROW (1, true, NULL)`},
 
@@ -1717,11 +1788,21 @@ func TestParse(t *testing.T) {
 		{`EXPLAIN CREATE CHANGEFEED FOR TABLE foo INTO 'sink'`},
 		{`CREATE CHANGEFEED FOR TABLE foo, db.bar, schema.db.foo INTO 'sink'`},
 		{`CREATE CHANGEFEED FOR TABLE foo INTO 'sink'`},
+		{`CREATE CHANGEFEED FOR DATABASE foo INTO 'sink'`},
+		{`EXPERIMENTAL CHANGEFEED FOR DATABASE foo`},
 		// TODO(dan): Implement.
 		// {`CREATE CHANGEFEED FOR TABLE foo VALUES FROM (1) TO (2) INTO 'sink'`},
 		// {`CREATE CHANGEFEED FOR TABLE foo PARTITION bar, baz INTO 'sink'`},
-		// {`CREATE CHANGEFEED FOR DATABASE foo INTO 'sink'`},
 		{`CREATE CHANGEFEED FOR TABLE foo INTO 'sink' WITH bar = 'baz'`},
+		{`CREATE CHANGEFEED INTO 'sink' AS SELECT a, b FROM foo`},
+		{`CREATE CHANGEFEED INTO 'sink' WITH bar = 'baz' AS SELECT * FROM db.foo WHERE status = 'active'`},
+		{`EXPERIMENTAL CHANGEFEED AS SELECT a + 1 AS b FROM foo WHERE a > 0`},
+		{`EXPERIMENTAL CHANGEFEED WITH bar AS SELECT * FROM foo`},
+		{`ALTER CHANGEFEED 123 ADD TABLE foo`},
+		{`ALTER CHANGEFEED $1 DROP TABLE db.foo`},
+		{`ALTER CHANGEFEED 123 SET resolved = '10s'`},
+		{`ALTER CHANGEFEED 123 SET diff`},
+		{`ALTER CHANGEFEED 123 ADD TABLE foo, DROP TABLE bar, SET sink = 'sink', SET resolved = '10s'`},
 
 		// Regression for #15926
 		{`SELECT * FROM ((t1 NATURAL JOIN t2 WITH ORDINALITY AS o1)) WITH ORDINALITY AS o2`},
@@ -1785,6 +1866,12 @@ func TestParse2(t *testing.T) {
 			`CREATE TABLE a (b INT8, CONSTRAINT foo UNIQUE (b) INTERLEAVE IN PARENT c (d))`},
 		{`CREATE TABLE a (UNIQUE INDEX (b) PARTITION BY LIST (c) (PARTITION d VALUES IN (1)))`,
 			`CREATE TABLE a (UNIQUE (b) PARTITION BY LIST (c) (PARTITION d VALUES IN (1)))`},
+		{`CREATE FUNCTION f(x int) RETURNS int AS 'SELECT x' IMMUTABLE LANGUAGE sql`,
+			`CREATE FUNCTION f(x INT8) RETURNS INT8 LANGUAGE SQL IMMUTABLE AS 'SELECT x'`},
+		{`CREATE FUNCTION f() RETURNS setof string LANGUAGE 'SQL' AS 'SELECT ''a'''`,
+			`CREATE FUNCTION f() RETURNS SETOF STRING LANGUAGE SQL AS e'SELECT \'a\''`},
+		{`CREATE TRIGGER a AFTER delete ON t FOR ROW EXECUTE PROCEDURE f(NEW.a)`,
+			`CREATE TRIGGER a AFTER DELETE ON t FOR EACH ROW EXECUTE FUNCTION f(new.a)`},
 		{`CREATE INDEX ON a (b) COVERING (c)`, `CREATE INDEX ON a (b) STORING (c)`},
 		{`CREATE INDEX ON a (b) INCLUDE (c)`, `CREATE INDEX ON a (b) STORING (c)`},
 
@@ -2254,6 +2341,24 @@ $function$`,
 		{`DEALLOCATE PREPARE ALL`,
 			`DEALLOCATE ALL`},
 
+		{`DECLARE a CURSOR WITHOUT HOLD FOR SELECT 1`, `DECLARE a CURSOR FOR SELECT 1`},
+		{`FETCH a`, `FETCH 1 a`},
+		{`FETCH FROM a`, `FETCH 1 a`},
+		{`FETCH NEXT a`, `FETCH 1 a`},
+		{`FETCH NEXT FROM a`, `FETCH 1 a`},
+		{`FETCH PRIOR IN a`, `FETCH -1 a`},
+		{`FETCH FORWARD a`, `FETCH 1 a`},
+		{`FETCH FORWARD 3 FROM a`, `FETCH 3 a`},
+		{`FETCH FORWARD ALL IN a`, `FETCH ALL a`},
+		{`FETCH BACKWARD a`, `FETCH -1 a`},
+		{`FETCH BACKWARD 3 a`, `FETCH -3 a`},
+		{`FETCH FIRST FROM a`, `FETCH FIRST a`},
+		{`FETCH ABSOLUTE 2 FROM a`, `FETCH ABSOLUTE 2 a`},
+		{`FETCH next`, `FETCH 1 next`},
+		{`FETCH FORWARD FROM forward`, `FETCH 1 forward`},
+		{`MOVE NEXT IN a`, `MOVE 1 a`},
+		{`MOVE LAST FROM a`, `MOVE LAST a`},
+
 		{`CANCEL JOB a`, `CANCEL JOBS VALUES (a)`},
 		{`EXPLAIN CANCEL JOB a`, `EXPLAIN CANCEL JOBS VALUES (a)`},
 		{`CANCEL JOBS FOR SCHEDULE a`, `CANCEL JOBS FOR SCHEDULES VALUES (a)`},
@@ -2448,6 +2553,9 @@ $function$`,
 			`RESTORE TABLE foo FROM $1, $2, 'bar'`},
 		{`RESTORE FROM $1, $2, 'bar'`,
 			`RESTORE FROM $1, $2, 'bar'`},
+		{`VERIFY BACKUP FROM bar`,
+			`VERIFY BACKUP FROM 'bar'`},
+		{`VERIFY BACKUP FROM ($1)`, `VERIFY BACKUP FROM $1`},
 		{`RESTORE foo, baz FROM 'bar'`,
 			`RESTORE TABLE foo, baz FROM 'bar'`},
 		{`RESTORE foo, baz FROM 'bar' AS OF SYSTEM TIME '1'`,
@@ -2464,13 +2572,15 @@ $function$`,
 			`BACKUP TABLE foo TO 'bar' WITH revision_history, detached, kms=('foo', 'bar')`},
 
 		{`RESTORE foo FROM 'bar' WITH OPTIONS (encryption_passphrase='secret', into_db='baz',
-skip_missing_foreign_keys, skip_missing_sequences, skip_missing_sequence_owners, skip_missing_views, detached)`,
-			`RESTORE TABLE foo FROM 'bar' WITH encryption_passphrase='secret', into_db='baz', skip_missing_foreign_keys, skip_missing_sequence_owners, skip_missing_sequences, skip_missing_views, detached`},
+skip_missing_foreign_keys, skip_missing_sequences, skip_missing_sequence_owners, skip_missing_views, skip_triggers, detached)`,
+			`RESTORE TABLE foo FROM 'bar' WITH encryption_passphrase='secret', into_db='baz', skip_missing_foreign_keys, skip_missing_sequence_owners, skip_missing_sequences, skip_missing_views, skip_triggers, detached`},
 		{`RESTORE foo FROM 'bar' WITH ENCRYPTION_PASSPHRASE = 'secret', INTO_DB=baz,
 SKIP_MISSING_FOREIGN_KEYS, SKIP_MISSING_SEQUENCES, SKIP_MISSING_SEQUENCE_OWNERS, SKIP_MISSING_VIEWS`,
 			`RESTORE TABLE foo FROM 'bar' WITH encryption_passphrase='secret', into_db='baz', skip_missing_foreign_keys, skip_missing_sequence_owners, skip_missing_sequences, skip_missing_views`},
 
 		{`CREATE CHANGEFEED FOR foo INTO 'sink'`, `CREATE CHANGEFEED FOR TABLE foo INTO 'sink'`},
+		{`CREATE CHANGEFEED AS SELECT a FROM foo`, `EXPERIMENTAL CHANGEFEED AS SELECT a FROM foo`},
+		{`ALTER CHANGEFEED 123 ADD foo, DROP bar`, `ALTER CHANGEFEED 123 ADD TABLE foo, DROP TABLE bar`},
 
 		{`GRANT SELECT ON foo TO root`,
 			`GRANT SELECT ON TABLE foo TO root`},
@@ -2545,6 +2655,18 @@ SKIP_MISSING_FOREIGN_KEYS, SKIP_MISSING_SEQUENCES, SKIP_MISSING_SEQUENCE_OWNERS,
 		{`SHOW GRANTS ON role.*`, `SHOW GRANTS ON TABLE role.*`},
 
 		// Foreign Keys
+		{
+			`CREATE TABLE a (b INT8, FOREIGN KEY (b) REFERENCES other INITIALLY DEFERRED)`,
+			`CREATE TABLE a (b INT8, FOREIGN KEY (b) REFERENCES other DEFERRABLE INITIALLY DEFERRED)`,
+		},
+		{
+			`CREATE TABLE a (b INT8, FOREIGN KEY (b) REFERENCES other DEFERRABLE INITIALLY IMMEDIATE)`,
+			`CREATE TABLE a (b INT8, FOREIGN KEY (b) REFERENCES other DEFERRABLE)`,
+		},
+		{
+			`CREATE TABLE a (b INT8, c INT8 REFERENCES foo INITIALLY IMMEDIATE)`,
+			`CREATE TABLE a (b INT8, c INT8 REFERENCES foo)`,
+		},
 		{
 			`CREATE TABLE a (b INT8, c INT8 REFERENCES foo MATCH SIMPLE)`,
 			`CREATE TABLE a (b INT8, c INT8 REFERENCES foo)`,
@@ -3112,7 +3234,7 @@ func TestUnimplementedSyntax(t *testing.T) {
 		{`CREATE DEFAULT CONVERSION a`, 0, `create def conv`, ``},
 		{`CREATE FOREIGN DATA WRAPPER a`, 0, `create fdw`, ``},
 		{`CREATE FOREIGN TABLE a`, 0, `create foreign table`, ``},
-		{`CREATE FUNCTION a`, 17511, `create`, ``},
+		{`CREATE FUNCTION a() RETURNS INT LANGUAGE plpgsql AS 'SELECT 1'`, 17511, `create function language plpgsql`, ``},
 		{`CREATE OR REPLACE FUNCTION a`, 17511, `create`, ``},
 		{`CREATE LANGUAGE a`, 17511, `create language a`, ``},
 		{`CREATE OPERATOR a`, 0, `create operator`, ``},
@@ -3122,7 +3244,10 @@ func TestUnimplementedSyntax(t *testing.T) {
 		{`CREATE SUBSCRIPTION a`, 0, `create subscription`, ``},
 		{`CREATE TABLESPACE a`, 54113, `create tablespace`, ``},
 		{`CREATE TEXT SEARCH a`, 7821, `create text`, ``},
-		{`CREATE TRIGGER a`, 28296, `create`, ``},
+		{`CREATE TRIGGER a AFTER INSERT ON t FOR EACH STATEMENT EXECUTE FUNCTION f()`, 28296, `statement`, ``},
+		{`CREATE TRIGGER a AFTER INSERT ON t FOR EACH ROW WHEN (NEW.a > 1) EXECUTE FUNCTION f()`, 28296, `when`, ``},
+		{`CREATE TRIGGER a AFTER UPDATE OF a ON t FOR EACH ROW EXECUTE FUNCTION f()`, 28296, `update of`, ``},
+		{`CREATE TRIGGER a AFTER TRUNCATE ON t FOR EACH ROW EXECUTE FUNCTION f()`, 28296, `truncate`, ``},
 
 		{`DROP ACCESS METHOD a`, 0, `drop access method`, ``},
 		{`DROP AGGREGATE a`, 0, `drop aggregate`, ``},
@@ -3133,7 +3258,6 @@ func TestUnimplementedSyntax(t *testing.T) {
 		{`DROP EXTENSION a`, 0, `drop extension a`, ``},
 		{`DROP FOREIGN TABLE a`, 0, `drop foreign table`, ``},
 		{`DROP FOREIGN DATA WRAPPER a`, 0, `drop fdw`, ``},
-		{`DROP FUNCTION a`, 17511, `drop `, ``},
 		{`DROP LANGUAGE a`, 17511, `drop language a`, ``},
 		{`DROP OPERATOR a`, 0, `drop operator`, ``},
 		{`DROP PUBLICATION a`, 0, `drop publication`, ``},
@@ -3141,14 +3265,12 @@ func TestUnimplementedSyntax(t *testing.T) {
 		{`DROP SERVER a`, 0, `drop server`, ``},
 		{`DROP SUBSCRIPTION a`, 0, `drop subscription`, ``},
 		{`DROP TEXT SEARCH a`, 7821, `drop text`, ``},
-		{`DROP TRIGGER a`, 28296, `drop`, ``},
 
 		{`DISCARD PLANS`, 0, `discard plans`, ``},
 		{`DISCARD SEQUENCES`, 0, `discard sequences`, ``},
 		{`DISCARD TEMP`, 0, `discard temp`, ``},
 		{`DISCARD TEMPORARY`, 0, `discard temp`, ``},
 
-		{`SET CONSTRAINTS foo`, 0, `set constraints`, ``},
 		{`SET LOCAL foo = bar`, 32562, ``, ``},
 		{`SET foo FROM CURRENT`, 0, `set from current`, ``},
 
@@ -3164,14 +3286,6 @@ func TestUnimplementedSyntax(t *testing.T) {
 		{`CREATE TABLE a(b INT8 REFERENCES c(x) MATCH PARTIAL`, 20305, `match partial`, ``},
 		{`CREATE TABLE a(b INT8, FOREIGN KEY (b) REFERENCES c(x) MATCH PARTIAL)`, 20305, `match partial`, ``},
 
-		{`CREATE TABLE a(b INT8, FOREIGN KEY (b) REFERENCES c(x) DEFERRABLE)`, 31632, `deferrable`, ``},
-		{`CREATE TABLE a(b INT8, FOREIGN KEY (b) REFERENCES c(x) INITIALLY DEFERRED)`, 31632, `initially deferred`, ``},
-		{`CREATE TABLE a(b INT8, FOREIGN KEY (b) REFERENCES c(x) INITIALLY IMMEDIATE)`, 31632, `initially immediate`, ``},
-		{`CREATE TABLE a(b INT8, FOREIGN KEY (b) REFERENCES c(x) DEFERRABLE INITIALLY DEFERRED)`, 31632, `initially deferred`, ``},
-		{`CREATE TABLE a(b INT8, FOREIGN KEY (b) REFERENCES c(x) DEFERRABLE INITIALLY IMMEDIATE)`, 31632, `initially immediate`, ``},
-		{`CREATE TABLE a(b INT8, UNIQUE (b) DEFERRABLE)`, 31632, `deferrable`, ``},
-		{`CREATE TABLE a(b INT8, CHECK (b > 0) DEFERRABLE)`, 31632, `deferrable`, ``},
-
 		{`CREATE TABLE a (LIKE b INCLUDING COMMENTS)`, 47071, `like table`, ``},
 		{`CREATE TABLE a (LIKE b INCLUDING IDENTITY)`, 47071, `like table`, ``},
 		{`CREATE TABLE a (LIKE b INCLUDING STATISTICS)`, 47071, `like table`, ``},
@@ -3231,16 +3345,10 @@ func TestUnimplementedSyntax(t *testing.T) {
 		{`SELECT a(b) 'c'`, 0, `a(...) SCONST`, ``},
 		{`SELECT (a,b) OVERLAPS (c,d)`, 0, `overlaps`, ``},
 		{`SELECT UNIQUE (SELECT b)`, 0, `UNIQUE predicate`, ``},
-		{`SELECT GROUPING (a,b,c)`, 0, `d_expr grouping`, ``},
 		{`SELECT a(VARIADIC b)`, 0, `variadic`, ``},
 		{`SELECT a(b, c, VARIADIC b)`, 0, `variadic`, ``},
 		{`SELECT TREAT (a AS INT8)`, 0, `treat`, ``},
 
-		{`SELECT 1 FROM t GROUP BY ROLLUP (b)`, 46280, `rollup`, ``},
-		{`SELECT 1 FROM t GROUP BY a, ROLLUP (b)`, 46280, `rollup`, ``},
-		{`SELECT 1 FROM t GROUP BY CUBE (b)`, 46280, `cube`, ``},
-		{`SELECT 1 FROM t GROUP BY GROUPING SETS (b)`, 46280, `grouping sets`, ``},
-
 		{`SELECT a FROM t ORDER BY a NULLS LAST`, 6224, ``, ``},
 		{`SELECT a FROM t ORDER BY a ASC NULLS LAST`, 6224, ``, ``},
 		{`SELECT a FROM t ORDER BY a DESC NULLS FIRST`, 6224, ``, ``},
@@ -257,6 +257,7 @@ var pgCatalog = virtualSchema{
 		catconstants.PgCatalogStatActivityTableID:        pgCatalogStatActivityTable,
 		catconstants.PgCatalogSecurityLabelTableID:       pgCatalogSecurityLabelTable,
 		catconstants.PgCatalogSharedSecurityLabelTableID: pgCatalogSharedSecurityLabelTable,
+		catconstants.PgCatalogCursorsTableID:             pgCatalogCursorsTable,
 	},
 	// Postgres's catalogs are ill-defined when there is no current
 	// database set. Simply reject any attempts to use them in that
@@ -757,6 +758,8 @@ func populateTableConstraints(
 		consrc := tree.DNull
 		conbin := tree.DNull
 		condef := tree.DNull
+		condeferrable := tree.DBoolFalse
+		condeferred := tree.DBoolFalse
 
 		// Determine constraint kind-specific fields.
 		var err error
@@ -788,6 +791,8 @@ func populateTableConstraints(
 				conindid = h.IndexOid(con.ReferencedTable.ID, idx.ID)
 			}
 			confrelid = tableOid(con.ReferencedTable.ID)
+			condeferrable = tree.MakeDBool(tree.DBool(con.FK.Deferrable))
+			condeferred = tree.MakeDBool(tree.DBool(con.FK.InitiallyDeferred))
 			if r, ok := fkActionMap[con.FK.OnUpdate]; ok {
 				confupdtype = r
 			}
@@ -833,6 +838,9 @@ func populateTableConstraints(
 				}
 				f.WriteString(fmt.Sprintf(" WHERE (%s)", pred))
 			}
+			f.FormatNode(con.Index.Deferrability())
+			condeferrable = tree.MakeDBool(tree.DBool(con.Index.DeferrableUnique))
+			condeferred = tree.MakeDBool(tree.DBool(con.Index.InitiallyDeferred))
 			condef = tree.NewDString(f.CloseAndGetString())
 
 		case descpb.ConstraintTypeCheck:
@@ -859,8 +867,8 @@ func populateTableConstraints(
 			dNameOrNull(conName), // conname
 			namespaceOid,         // connamespace
 			contype,              // contype
-			tree.DBoolFalse,      // condeferrable
-			tree.DBoolFalse,      // condeferred
+			condeferrable,        // condeferrable
+			condeferred,          // condeferred
 			tree.MakeDBool(tree.DBool(!con.Unvalidated)), // convalidated
 			tblOid,         // conrelid
 			oidZero,        // contypid
@@ -1045,6 +1053,34 @@ https://www.postgresql.org/docs/9.6/catalog-pg-conversion.html`,
 	},
 }
 
+// pgCatalogCursorsTable implements the pg_cursors table.
+// The statement field differs in that it uses the parsed version
+// of the DECLARE statement.
+var pgCatalogCursorsTable = virtualSchemaTable{
+	comment: `open cursors
+https://www.postgresql.org/docs/13/view-pg-cursors.html`,
+	schema: vtable.PGCatalogCursors,
+	populate: func(ctx context.Context, p *planner, dbContext *dbdesc.Immutable, addRow func(...tree.Datum) error) error {
+		for _, c := range p.sqlCursors.List() {
+			ts, err := tree.MakeDTimestampTZ(c.createdAt, time.Microsecond)
+			if err != nil {
+				return err
+			}
+			if err := addRow(
+				tree.NewDString(string(c.name)),
+				tree.NewDString(c.stmt),
+				tree.MakeDBool(tree.DBool(c.hold)),
+				tree.DBoolFalse, // is_binary
+				tree.MakeDBool(tree.DBool(c.scroll)),
+				ts,
+			); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+}
+
 var pgCatalogDatabaseTable = virtualSchemaTable{
 	comment: `available databases (incomplete)
 https://www.postgresql.org/docs/9.5/catalog-pg-database.html`,
@@ -1428,11 +1464,14 @@ https://www.postgresql.org/docs/9.5/catalog-pg-index.html`,
 					if err != nil {
 						return err
 					}
+					// The index of a DEFERRABLE unique constraint is unique, but it is
+					// not checked immediately.
+					isUnique := index.Unique || index.DeferrableUnique
 					return addRow(
 						h.IndexOid(table.GetID(), index.ID), // indexrelid
 						tableOid,                            // indrelid
 						tree.NewDInt(tree.DInt(len(index.ColumnNames))), // indnatts
-						tree.MakeDBool(tree.DBool(index.Unique)),        // indisunique
+						tree.MakeDBool(tree.DBool(isUnique)),            // indisunique
 						tree.MakeDBool(tree.DBool(isPrimary)),           // indisprimary
 						tree.DBoolFalse,                                 // indisexclusion
 						tree.MakeDBool(tree.DBool(index.Unique)),        // indimmediate
@@ -1496,7 +1535,7 @@ func indexDefFromDescriptor(
 	indexDef := tree.CreateIndex{
 		Name:     tree.Name(index.Name),
 		Table:    tree.MakeTableName(tree.Name(db.GetName()), tree.Name(table.GetName())),
-		Unique:   index.Unique,
+		Unique:   index.Unique || index.DeferrableUnique,
 		Columns:  make(tree.IndexElemList, len(index.ColumnNames)),
 		Storing:  make(tree.NameList, len(index.StoreColumnNames)),
 		Inverted: index.Type == descpb.IndexDescriptor_INVERTED,
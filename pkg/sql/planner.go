@@ -155,6 +155,14 @@ type planner struct {
 
 	preparedStatements preparedStatementsAccessor
 
+	// sqlCursors gives access to the cursors of the session.
+	sqlCursors sqlCursorsAccessor
+
+	// deferredConstraints gives access to the state of the DEFERRABLE
+	// constraints in the session's transaction. It is nil for internal
+	// planners, in which case constraints are always checked immediately.
+	deferredConstraints *deferredConstraints
+
 	// avoidCachedDescriptors, when true, instructs all code that
 	// accesses table/view descriptors to force reading the descriptors
 	// within the transaction. This is necessary to read descriptors
@@ -308,6 +316,7 @@ func newInternalPlanner(
 	p.semaCtx = tree.MakeSemaContext()
 	p.semaCtx.SearchPath = sd.SearchPath
 	p.semaCtx.TypeResolver = p
+	p.semaCtx.FunctionResolver = p
 
 	plannerMon := mon.NewUnlimitedMonitor(ctx,
 		fmt.Sprintf("internal-planner.%s.%s", user, opName),
@@ -115,6 +115,14 @@ func (p *planner) renameColumn(
 			)
 		}
 	}
+	if err := p.checkNoDependentFunctions(
+		ctx, tableDesc, "column", oldName.String(), "rename", refersToColumn(col.ID),
+	); err != nil {
+		return false, err
+	}
+	if err := checkNoDependentTriggers(tableDesc, "column", oldName.String(), "rename", col.ID); err != nil {
+		return false, err
+	}
 	if *oldName == *newName {
 		// Noop.
 		return false, nil
@@ -146,9 +146,35 @@ func (n *renameDatabaseNode) startExec(params runParams) error {
 				return err
 			}
 			if tbDesc == nil {
+				// The body of a function refers to the objects it depends on by
+				// their fully qualified names.
+				fnName := tree.MakeQualifiedFunctionName(dbDesc.GetName(), schema, tbNames[i].Object())
+				fnDesc, err := p.Descriptors().GetFunctionVersion(
+					ctx, p.txn, &fnName, tree.ObjectLookupFlags{CommonLookupFlags: lookupFlags},
+				)
+				if err != nil {
+					return err
+				}
+				if fnDesc != nil && len(fnDesc.DependsOn) > 0 {
+					return errors.WithHintf(
+						sqlerrors.NewDependentObjectErrorf(
+							"cannot rename database because function %q depends on objects in it",
+							fnName.String()),
+						"you can drop %q instead", fnName.String())
+				}
 				continue
 			}
 
+			// The triggers on a table refer to their functions by their fully
+			// qualified names.
+			if triggers := tbDesc.TableDesc().Triggers; len(triggers) > 0 {
+				return errors.WithHintf(
+					sqlerrors.NewDependentObjectErrorf(
+						"cannot rename database because trigger %q on relation %q refers to objects in it",
+						triggers[0].Name, tbDesc.GetName()),
+					"you can drop the trigger instead.")
+			}
+
 			if err := tbDesc.ForeachDependedOnBy(func(dependedOn *descpb.TableDescriptor_Reference) error {
 				dependentDesc, err := catalogkv.MustGetTableDescByID(ctx, p.txn, p.ExecCfg().Codec, dependedOn.ID)
 				if err != nil {
@@ -81,6 +81,12 @@ func (n *renameIndexNode) startExec(params runParams) error {
 			ctx, "index", n.n.Index.Index.String(), tableDesc.ParentID, tableRef.ID, "rename",
 		)
 	}
+	if err := p.checkNoDependentFunctions(
+		ctx, tableDesc, "index", n.n.Index.Index.String(), "rename",
+		func(ref *descpb.TableDescriptor_Reference) bool { return ref.IndexID == idx.ID },
+	); err != nil {
+		return err
+	}
 
 	if n.n.NewName == "" {
 		return errEmptyIndexName
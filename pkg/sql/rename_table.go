@@ -81,6 +81,12 @@ func (p *planner) RenameTable(ctx context.Context, n *tree.RenameTable) (planNod
 			tableDesc.ParentID, tableDesc.DependedOnBy[0].ID, "rename",
 		)
 	}
+	// The same goes for functions.
+	if err := p.checkNoDependentFunctions(
+		ctx, tableDesc, tableDesc.TypeName(), oldTn.String(), "rename", nil, /* pred */
+	); err != nil {
+		return nil, err
+	}
 
 	return &renameTableNode{n: n, oldTn: &oldTn, newTn: &newTn, tableDesc: tableDesc}, nil
 }
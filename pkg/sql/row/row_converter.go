@@ -186,6 +186,9 @@ type KVBatch struct {
 	Source int32
 	// LastRow is the index of the last converted row in source in this batch.
 	LastRow int64
+	// LastOffset is the byte offset in source just past LastRow, or 0 if it is
+	// not known.
+	LastOffset int64
 	// Progress represents the fraction of the input that generated this row.
 	Progress float32
 	// KVs is the actual converted KV data.
@@ -223,6 +226,10 @@ type DatumRowConverter struct {
 	// FractionFn is used to set the progress header in KVBatches.
 	CompletedRowFn func() int64
 	FractionFn     func() float32
+	// CheckRowFn, if set, is called with the KVs of each row once they are
+	// generated. If it returns an error, the KVs are discarded and Row returns
+	// the error.
+	CheckRowFn func([]roachpb.KeyValue) error
 }
 
 var kvDatumRowConverterBatchSize = util.ConstantWithMetamorphicTestValue(
@@ -425,6 +432,7 @@ func (c *DatumRowConverter) Row(ctx context.Context, sourceID int32, rowIndex in
 	// TODO(mgartner): Add partial index IDs to ignoreIndexes that we should
 	// not delete entries from.
 	var pm PartialIndexUpdateHelper
+	rowStart := len(c.KvBatch.KVs)
 	if err := c.ri.InsertRow(
 		ctx,
 		KVInserter(func(kv roachpb.KeyValue) {
@@ -436,8 +444,15 @@ func (c *DatumRowConverter) Row(ctx context.Context, sourceID int32, rowIndex in
 		true,  /* ignoreConflicts */
 		false, /* traceKV */
 	); err != nil {
+		c.KvBatch.KVs = c.KvBatch.KVs[:rowStart]
 		return errors.Wrap(err, "insert row")
 	}
+	if c.CheckRowFn != nil {
+		if err := c.CheckRowFn(c.KvBatch.KVs[rowStart:]); err != nil {
+			c.KvBatch.KVs = c.KvBatch.KVs[:rowStart]
+			return err
+		}
+	}
 	// If our batch is full, flush it and start a new one.
 	if len(c.KvBatch.KVs) >= kvDatumRowConverterBatchSize {
 		if err := c.SendBatch(ctx); err != nil {
@@ -92,7 +92,13 @@ func newProjectSetProcessor(
 		processorID,
 		output,
 		nil, /* memMonitor */
-		execinfra.ProcStateOpts{InputsToDrain: []execinfra.RowSource{ps.input}},
+		execinfra.ProcStateOpts{
+			InputsToDrain: []execinfra.RowSource{ps.input},
+			TrailingMetaCallback: func(context.Context) []execinfrapb.ProducerMetadata {
+				ps.close()
+				return nil
+			},
+		},
 	); err != nil {
 		return nil, err
 	}
@@ -150,10 +156,12 @@ func (ps *projectSetProcessor) nextInputRow() (
 			if gen == nil {
 				gen = builtins.EmptyGenerator()
 			}
+			// The generator of the previous row is done.
+			ps.closeGenerator(i)
+			ps.gens[i] = gen
 			if err := gen.Start(ps.Ctx, ps.FlowCtx.Txn); err != nil {
 				return nil, nil, err
 			}
-			ps.gens[i] = gen
 		}
 		ps.done[i] = false
 	}
@@ -279,10 +287,28 @@ func (ps *projectSetProcessor) toEncDatum(d tree.Datum, colIdx int) rowenc.EncDa
 	return rowenc.DatumToEncDatum(ctyp, d)
 }
 
+// closeGenerator closes the i-th generator, if any, which releases its
+// resources.
+func (ps *projectSetProcessor) closeGenerator(i int) {
+	if ps.gens[i] != nil {
+		ps.gens[i].Close()
+		ps.gens[i] = nil
+	}
+}
+
+// close closes the generators and the processor.
+func (ps *projectSetProcessor) close() {
+	if ps.InternalClose() {
+		for i := range ps.gens {
+			ps.closeGenerator(i)
+		}
+	}
+}
+
 // ConsumerClosed is part of the RowSource interface.
 func (ps *projectSetProcessor) ConsumerClosed() {
 	// The consumer is done, Next() will not be called again.
-	ps.InternalClose()
+	ps.close()
 }
 
 // ChildCount is part of the execinfra.OpNode interface.
@@ -4515,6 +4515,29 @@ may increase either contention or retry errors, or both.`,
 			Volatility: tree.VolatilityImmutable,
 		},
 	),
+
+	// grouping is replaced by the optimizer with an expression over the
+	// grouping set being computed, so it is never evaluated directly.
+	"grouping": makeBuiltin(
+		tree.FunctionProperties{
+			Category:     categoryComparison,
+			NullableArgs: true,
+		},
+		tree.Overload{
+			Types: tree.VariadicType{
+				VarType: types.Any,
+			},
+			ReturnType: tree.FixedReturnType(types.Int),
+			Fn: func(ctx *tree.EvalContext, args tree.Datums) (tree.Datum, error) {
+				return nil, pgerror.New(pgcode.Grouping,
+					"grouping() can only be used with GROUP BY")
+			},
+			Info: "Returns a bit mask indicating which of the arguments are not included " +
+				"in the current grouping set. The rightmost argument corresponds to the " +
+				"least significant bit.",
+			Volatility: tree.VolatilityImmutable,
+		},
+	),
 }
 
 var lengthImpls = func(incBitOverload bool) builtinDefinition {
@@ -113,6 +113,7 @@ type RestoreOptions struct {
 	SkipMissingSequences      bool
 	SkipMissingSequenceOwners bool
 	SkipMissingViews          bool
+	SkipTriggers              bool
 	Detached                  bool
 }
 
@@ -126,6 +127,10 @@ type Restore struct {
 	AsOf               AsOfClause
 	Options            RestoreOptions
 	Subdir             Expr
+	// NewTableName is set by the parser when the SQL query is of the form
+	// `RESTORE TABLE t AS t_new FROM ...`, in which case the single table in
+	// Targets is restored under this name.
+	NewTableName *UnresolvedObjectName
 }
 
 var _ Statement = &Restore{}
@@ -136,6 +141,11 @@ func (node *Restore) Format(ctx *FmtCtx) {
 	if node.DescriptorCoverage == RequestedDescriptors {
 		ctx.FormatNode(&node.Targets)
 		ctx.WriteString(" ")
+		if node.NewTableName != nil {
+			ctx.WriteString("AS ")
+			ctx.FormatNode(node.NewTableName)
+			ctx.WriteString(" ")
+		}
 	}
 	ctx.WriteString("FROM ")
 	if node.Subdir != nil {
@@ -158,6 +168,69 @@ func (node *Restore) Format(ctx *FmtCtx) {
 	}
 }
 
+// VerifyBackup represents a VERIFY BACKUP statement.
+type VerifyBackup struct {
+	From []StringOrPlaceholderOptList
+	// Subdir may be set by the parser when the SQL query is of the form
+	// `VERIFY BACKUP FROM 'subdir' IN ...`.
+	Subdir  Expr
+	Options KVOptions
+}
+
+var _ Statement = &VerifyBackup{}
+
+// Format implements the NodeFormatter interface.
+func (node *VerifyBackup) Format(ctx *FmtCtx) {
+	ctx.WriteString("VERIFY BACKUP FROM ")
+	if node.Subdir != nil {
+		ctx.FormatNode(node.Subdir)
+		ctx.WriteString(" IN ")
+	}
+	for i := range node.From {
+		if i > 0 {
+			ctx.WriteString(", ")
+		}
+		ctx.FormatNode(&node.From[i])
+	}
+	if len(node.Options) > 0 {
+		ctx.WriteString(" WITH ")
+		ctx.FormatNode(&node.Options)
+	}
+}
+
+// CompactBackup represents a COMPACT BACKUP statement.
+type CompactBackup struct {
+	From []StringOrPlaceholderOptList
+	// Subdir may be set by the parser when the SQL query is of the form
+	// `COMPACT BACKUP FROM 'subdir' IN ...`.
+	Subdir  Expr
+	To      Expr
+	Options KVOptions
+}
+
+var _ Statement = &CompactBackup{}
+
+// Format implements the NodeFormatter interface.
+func (node *CompactBackup) Format(ctx *FmtCtx) {
+	ctx.WriteString("COMPACT BACKUP FROM ")
+	if node.Subdir != nil {
+		ctx.FormatNode(node.Subdir)
+		ctx.WriteString(" IN ")
+	}
+	for i := range node.From {
+		if i > 0 {
+			ctx.WriteString(", ")
+		}
+		ctx.FormatNode(&node.From[i])
+	}
+	ctx.WriteString(" TO ")
+	ctx.FormatNode(node.To)
+	if len(node.Options) > 0 {
+		ctx.WriteString(" WITH ")
+		ctx.FormatNode(&node.Options)
+	}
+}
+
 // KVOption is a key-value option.
 type KVOption struct {
 	Key   Name
@@ -317,6 +390,11 @@ func (o *RestoreOptions) Format(ctx *FmtCtx) {
 		ctx.WriteString("skip_missing_views")
 	}
 
+	if o.SkipTriggers {
+		maybeAddSep()
+		ctx.WriteString("skip_triggers")
+	}
+
 	if o.Detached {
 		maybeAddSep()
 		ctx.WriteString("detached")
@@ -376,6 +454,14 @@ func (o *RestoreOptions) CombineWith(other *RestoreOptions) error {
 		o.SkipMissingViews = other.SkipMissingViews
 	}
 
+	if o.SkipTriggers {
+		if other.SkipTriggers {
+			return errors.New("skip_triggers specified multiple times")
+		}
+	} else {
+		o.SkipTriggers = other.SkipTriggers
+	}
+
 	if o.Detached {
 		if other.Detached {
 			return errors.New("detached option specified multiple times")
@@ -394,6 +480,7 @@ func (o RestoreOptions) IsDefault() bool {
 		o.SkipMissingSequences == options.SkipMissingSequences &&
 		o.SkipMissingSequenceOwners == options.SkipMissingSequenceOwners &&
 		o.SkipMissingViews == options.SkipMissingViews &&
+		o.SkipTriggers == options.SkipTriggers &&
 		cmp.Equal(o.DecryptionKMSURI, options.DecryptionKMSURI) &&
 		o.EncryptionPassphrase == options.EncryptionPassphrase &&
 		o.IntoDB == options.IntoDB &&
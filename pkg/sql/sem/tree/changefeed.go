@@ -15,12 +15,19 @@ type CreateChangefeed struct {
 	Targets TargetList
 	SinkURI Expr
 	Options KVOptions
+	// Select, if set, is the projection and filter of a CREATE CHANGEFEED ...
+	// AS SELECT statement. Targets then holds the single table it selects from.
+	Select *SelectClause
 }
 
 var _ Statement = &CreateChangefeed{}
 
 // Format implements the NodeFormatter interface.
 func (node *CreateChangefeed) Format(ctx *FmtCtx) {
+	if node.Select != nil {
+		node.formatWithSelect(ctx)
+		return
+	}
 	if node.SinkURI != nil {
 		ctx.WriteString("CREATE ")
 	} else {
@@ -39,3 +46,99 @@ func (node *CreateChangefeed) Format(ctx *FmtCtx) {
 		ctx.FormatNode(&node.Options)
 	}
 }
+
+// formatWithSelect formats a CREATE CHANGEFEED ... AS SELECT statement. The
+// targets are implied by the FROM clause of the selection, so they're not
+// formatted separately.
+func (node *CreateChangefeed) formatWithSelect(ctx *FmtCtx) {
+	if node.SinkURI != nil {
+		ctx.WriteString("CREATE CHANGEFEED INTO ")
+		ctx.FormatNode(node.SinkURI)
+	} else {
+		ctx.WriteString("EXPERIMENTAL CHANGEFEED")
+	}
+	if node.Options != nil {
+		ctx.WriteString(" WITH ")
+		ctx.FormatNode(&node.Options)
+	}
+	ctx.WriteString(" AS ")
+	ctx.FormatNode(node.Select)
+}
+
+// AlterChangefeed represents an ALTER CHANGEFEED statement.
+type AlterChangefeed struct {
+	Jobs Expr
+	Cmds AlterChangefeedCmds
+}
+
+var _ Statement = &AlterChangefeed{}
+
+// Format implements the NodeFormatter interface.
+func (node *AlterChangefeed) Format(ctx *FmtCtx) {
+	ctx.WriteString("ALTER CHANGEFEED ")
+	ctx.FormatNode(node.Jobs)
+	ctx.FormatNode(&node.Cmds)
+}
+
+// AlterChangefeedCmds represents a list of changefeed alterations.
+type AlterChangefeedCmds []AlterChangefeedCmd
+
+// Format implements the NodeFormatter interface.
+func (node *AlterChangefeedCmds) Format(ctx *FmtCtx) {
+	for i, n := range *node {
+		if i > 0 {
+			ctx.WriteString(",")
+		}
+		ctx.FormatNode(n)
+	}
+}
+
+// AlterChangefeedCmd represents a changefeed modification operation.
+type AlterChangefeedCmd interface {
+	NodeFormatter
+	// Placeholder function to ensure that only desired types
+	// (AlterChangefeed*) conform to the AlterChangefeedCmd interface.
+	alterChangefeedCmd()
+}
+
+func (*AlterChangefeedAddTarget) alterChangefeedCmd()  {}
+func (*AlterChangefeedDropTarget) alterChangefeedCmd() {}
+func (*AlterChangefeedSetOption) alterChangefeedCmd()  {}
+
+var _ AlterChangefeedCmd = &AlterChangefeedAddTarget{}
+var _ AlterChangefeedCmd = &AlterChangefeedDropTarget{}
+var _ AlterChangefeedCmd = &AlterChangefeedSetOption{}
+
+// AlterChangefeedAddTarget represents an ADD <table> command.
+type AlterChangefeedAddTarget struct {
+	Table TablePattern
+}
+
+// Format implements the NodeFormatter interface.
+func (node *AlterChangefeedAddTarget) Format(ctx *FmtCtx) {
+	ctx.WriteString(" ADD TABLE ")
+	ctx.FormatNode(node.Table)
+}
+
+// AlterChangefeedDropTarget represents a DROP <table> command.
+type AlterChangefeedDropTarget struct {
+	Table TablePattern
+}
+
+// Format implements the NodeFormatter interface.
+func (node *AlterChangefeedDropTarget) Format(ctx *FmtCtx) {
+	ctx.WriteString(" DROP TABLE ")
+	ctx.FormatNode(node.Table)
+}
+
+// AlterChangefeedSetOption represents a SET <option> [= <value>] command.
+type AlterChangefeedSetOption struct {
+	Option KVOption
+}
+
+// Format implements the NodeFormatter interface.
+func (node *AlterChangefeedSetOption) Format(ctx *FmtCtx) {
+	ctx.WriteString(" SET ")
+	opts := KVOptions{node.Option}
+	ctx.FormatNode(&opts)
+}
@@ -396,6 +396,7 @@ type ColumnTableDef struct {
 		ConstraintName Name
 		Actions        ReferenceActions
 		Match          CompositeKeyMatchMethod
+		Deferrability  ConstraintDeferrability
 	}
 	Computed struct {
 		Computed bool
@@ -524,6 +525,7 @@ func NewColumnTableDef(
 			d.References.ConstraintName = c.Name
 			d.References.Actions = t.Actions
 			d.References.Match = t.Match
+			d.References.Deferrability = t.Deferrability
 		case *ColumnComputedDef:
 			d.Computed.Computed = true
 			d.Computed.Expr = t.Expr
@@ -635,6 +637,7 @@ func (node *ColumnTableDef) Format(ctx *FmtCtx) {
 			ctx.WriteString(node.References.Match.String())
 		}
 		ctx.FormatNode(&node.References.Actions)
+		ctx.FormatNode(node.References.Deferrability)
 	}
 	if node.IsComputed() {
 		ctx.WriteString(" AS (")
@@ -735,10 +738,11 @@ type ColumnCheckConstraint struct {
 
 // ColumnFKConstraint represents a FK-constaint on a column.
 type ColumnFKConstraint struct {
-	Table   TableName
-	Col     Name // empty-string means use PK
-	Actions ReferenceActions
-	Match   CompositeKeyMatchMethod
+	Table         TableName
+	Col           Name // empty-string means use PK
+	Actions       ReferenceActions
+	Match         CompositeKeyMatchMethod
+	Deferrability ConstraintDeferrability
 }
 
 // ColumnComputedDef represents the description of a computed column.
@@ -825,8 +829,9 @@ func (*CheckConstraintTableDef) constraintTableDef()      {}
 // TABLE statement.
 type UniqueConstraintTableDef struct {
 	IndexTableDef
-	PrimaryKey   bool
-	WithoutIndex bool
+	PrimaryKey    bool
+	WithoutIndex  bool
+	Deferrability ConstraintDeferrability
 }
 
 // SetName implements the TableDef interface.
@@ -866,6 +871,7 @@ func (node *UniqueConstraintTableDef) Format(ctx *FmtCtx) {
 	if node.PartitionBy != nil {
 		ctx.FormatNode(node.PartitionBy)
 	}
+	ctx.FormatNode(node.Deferrability)
 	if node.Predicate != nil {
 		ctx.WriteString(" WHERE ")
 		ctx.FormatNode(node.Predicate)
@@ -939,14 +945,53 @@ func (c CompositeKeyMatchMethod) String() string {
 	return compositeKeyMatchMethodName[c]
 }
 
+// ConstraintDeferrability describes whether the checking of a foreign key or
+// unique constraint can be deferred until the end of the transaction.
+type ConstraintDeferrability int
+
+// The values for ConstraintDeferrability.
+const (
+	// ConstraintNotDeferrable constraints are checked at the end of each
+	// statement.
+	ConstraintNotDeferrable ConstraintDeferrability = iota
+	// DeferrableInitiallyImmediate constraints are checked at the end of each
+	// statement, unless SET CONSTRAINTS defers them.
+	DeferrableInitiallyImmediate
+	// DeferrableInitiallyDeferred constraints are checked at the end of the
+	// transaction, unless SET CONSTRAINTS makes them immediate.
+	DeferrableInitiallyDeferred
+)
+
+var constraintDeferrabilityName = [...]string{
+	ConstraintNotDeferrable:      "NOT DEFERRABLE",
+	DeferrableInitiallyImmediate: "DEFERRABLE INITIALLY IMMEDIATE",
+	DeferrableInitiallyDeferred:  "DEFERRABLE INITIALLY DEFERRED",
+}
+
+func (d ConstraintDeferrability) String() string {
+	return constraintDeferrabilityName[d]
+}
+
+// Format implements the NodeFormatter interface. Nothing is written for
+// ConstraintNotDeferrable, which is the default.
+func (d ConstraintDeferrability) Format(ctx *FmtCtx) {
+	switch d {
+	case DeferrableInitiallyImmediate:
+		ctx.WriteString(" DEFERRABLE")
+	case DeferrableInitiallyDeferred:
+		ctx.WriteString(" DEFERRABLE INITIALLY DEFERRED")
+	}
+}
+
 // ForeignKeyConstraintTableDef represents a FOREIGN KEY constraint in the AST.
 type ForeignKeyConstraintTableDef struct {
-	Name     Name
-	Table    TableName
-	FromCols NameList
-	ToCols   NameList
-	Actions  ReferenceActions
-	Match    CompositeKeyMatchMethod
+	Name          Name
+	Table         TableName
+	FromCols      NameList
+	ToCols        NameList
+	Actions       ReferenceActions
+	Match         CompositeKeyMatchMethod
+	Deferrability ConstraintDeferrability
 }
 
 // Format implements the NodeFormatter interface.
@@ -974,6 +1019,7 @@ func (node *ForeignKeyConstraintTableDef) Format(ctx *FmtCtx) {
 	}
 
 	ctx.FormatNode(&node.Actions)
+	ctx.FormatNode(node.Deferrability)
 }
 
 // SetName implements the ConstraintTableDef interface.
@@ -1312,12 +1358,13 @@ func (node *CreateTable) HoistConstraints() {
 					targetCol = append(targetCol, col.References.Col)
 				}
 				node.Defs = append(node.Defs, &ForeignKeyConstraintTableDef{
-					Table:    *col.References.Table,
-					FromCols: NameList{col.Name},
-					ToCols:   targetCol,
-					Name:     col.References.ConstraintName,
-					Actions:  col.References.Actions,
-					Match:    col.References.Match,
+					Table:         *col.References.Table,
+					FromCols:      NameList{col.Name},
+					ToCols:        targetCol,
+					Name:          col.References.ConstraintName,
+					Actions:       col.References.Actions,
+					Match:         col.References.Match,
+					Deferrability: col.References.Deferrability,
 				})
 				col.References.Table = nil
 			}
@@ -1704,6 +1751,190 @@ func (node *CreateView) Format(ctx *FmtCtx) {
 	ctx.FormatNode(node.AsSource)
 }
 
+// FuncArg represents an argument of a CREATE FUNCTION statement. The name is
+// optional.
+type FuncArg struct {
+	Name Name
+	Type ResolvableTypeReference
+}
+
+// Format implements the NodeFormatter interface.
+func (node *FuncArg) Format(ctx *FmtCtx) {
+	if node.Name != "" {
+		ctx.FormatNode(&node.Name)
+		ctx.WriteByte(' ')
+	}
+	ctx.FormatTypeReference(node.Type)
+}
+
+// FuncArgs represents a list of function arguments.
+type FuncArgs []FuncArg
+
+// Format implements the NodeFormatter interface.
+func (node *FuncArgs) Format(ctx *FmtCtx) {
+	for i := range *node {
+		if i > 0 {
+			ctx.WriteString(", ")
+		}
+		ctx.FormatNode(&(*node)[i])
+	}
+}
+
+// CreateFunction represents a CREATE FUNCTION statement.
+type CreateFunction struct {
+	FuncName   *UnresolvedObjectName
+	Args       FuncArgs
+	ReturnType ResolvableTypeReference
+	ReturnsSet bool
+	// Volatility is zero if no volatility was specified, in which case the
+	// function is VOLATILE.
+	Volatility Volatility
+	// Body is the SQL statement which defines the function.
+	Body string
+}
+
+var _ Statement = &CreateFunction{}
+
+// Format implements the NodeFormatter interface.
+func (node *CreateFunction) Format(ctx *FmtCtx) {
+	ctx.WriteString("CREATE FUNCTION ")
+	ctx.FormatNode(node.FuncName)
+	ctx.WriteByte('(')
+	ctx.FormatNode(&node.Args)
+	ctx.WriteString(") RETURNS ")
+	if node.ReturnsSet {
+		ctx.WriteString("SETOF ")
+	}
+	ctx.FormatTypeReference(node.ReturnType)
+	ctx.WriteString(" LANGUAGE SQL")
+	if node.Volatility != 0 {
+		ctx.WriteByte(' ')
+		ctx.WriteString(strings.ToUpper(node.Volatility.String()))
+	}
+	ctx.WriteString(" AS ")
+	if ctx.flags.HasFlags(FmtAnonymize) {
+		ctx.WriteByte('_')
+	} else {
+		lex.EncodeSQLString(&ctx.Buffer, node.Body)
+	}
+}
+
+// FunctionOptions holds the options of a CREATE FUNCTION statement. It is
+// only used while parsing.
+type FunctionOptions struct {
+	Language   string
+	Volatility Volatility
+	Body       *string
+}
+
+// Merge groups two sets of function options together.
+// Used in the parser.
+func (node *FunctionOptions) Merge(other FunctionOptions) error {
+	if other.Language != "" {
+		if node.Language != "" {
+			return pgerror.New(pgcode.Syntax, "conflicting or redundant options: LANGUAGE")
+		}
+		node.Language = other.Language
+	}
+	if other.Volatility != 0 {
+		if node.Volatility != 0 {
+			return pgerror.New(pgcode.Syntax, "conflicting or redundant options: volatility")
+		}
+		node.Volatility = other.Volatility
+	}
+	if other.Body != nil {
+		if node.Body != nil {
+			return pgerror.New(pgcode.Syntax, "conflicting or redundant options: AS")
+		}
+		node.Body = other.Body
+	}
+	return nil
+}
+
+// TriggerActionTime specifies whether a trigger fires before or after the
+// operation on a row.
+type TriggerActionTime int
+
+const (
+	// TriggerBefore is used for triggers which fire before the row is
+	// modified.
+	TriggerBefore TriggerActionTime = iota
+	// TriggerAfter is used for triggers which fire after the row is modified.
+	TriggerAfter
+)
+
+// String implements the fmt.Stringer interface.
+func (t TriggerActionTime) String() string {
+	if t == TriggerAfter {
+		return "AFTER"
+	}
+	return "BEFORE"
+}
+
+// TriggerEvent is an operation which causes a trigger to fire.
+type TriggerEvent int
+
+const (
+	// TriggerInsert fires a trigger on INSERT.
+	TriggerInsert TriggerEvent = iota
+	// TriggerUpdate fires a trigger on UPDATE.
+	TriggerUpdate
+	// TriggerDelete fires a trigger on DELETE.
+	TriggerDelete
+)
+
+var triggerEventName = [...]string{
+	TriggerInsert: "INSERT",
+	TriggerUpdate: "UPDATE",
+	TriggerDelete: "DELETE",
+}
+
+// String implements the fmt.Stringer interface.
+func (e TriggerEvent) String() string {
+	return triggerEventName[e]
+}
+
+// TriggerEvents is a list of trigger events.
+type TriggerEvents []TriggerEvent
+
+// Format implements the NodeFormatter interface.
+func (node *TriggerEvents) Format(ctx *FmtCtx) {
+	for i, e := range *node {
+		if i > 0 {
+			ctx.WriteString(" OR ")
+		}
+		ctx.WriteString(e.String())
+	}
+}
+
+// CreateTrigger represents a CREATE TRIGGER statement. Only row-level triggers
+// are supported.
+type CreateTrigger struct {
+	Name       Name
+	ActionTime TriggerActionTime
+	Events     TriggerEvents
+	Table      *UnresolvedObjectName
+	// Func is the call of the trigger function. Its arguments can refer to the
+	// columns of the new and old rows as NEW.<column> and OLD.<column>.
+	Func *FuncExpr
+}
+
+var _ Statement = &CreateTrigger{}
+
+// Format implements the NodeFormatter interface.
+func (node *CreateTrigger) Format(ctx *FmtCtx) {
+	ctx.WriteString("CREATE TRIGGER ")
+	ctx.FormatNode(&node.Name)
+	ctx.WriteByte(' ')
+	ctx.WriteString(node.ActionTime.String())
+	ctx.WriteByte(' ')
+	ctx.FormatNode(&node.Events)
+	ctx.WriteString(" ON ")
+	ctx.FormatNode(node.Table)
+	ctx.WriteString(" FOR EACH ROW EXECUTE FUNCTION ")
+	ctx.FormatNode(node.Func)
+}
+
 // RefreshMaterializedView represents a REFRESH MATERIALIZED VIEW statement.
 type RefreshMaterializedView struct {
 	Name              *UnresolvedObjectName
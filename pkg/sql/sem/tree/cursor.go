@@ -0,0 +1,176 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package tree
+
+import "strconv"
+
+// DeclareCursor represents a DECLARE statement.
+type DeclareCursor struct {
+	Name   Name
+	Select *Select
+	Scroll CursorScrollOption
+	Hold   bool
+}
+
+// Format implements the NodeFormatter interface.
+func (node *DeclareCursor) Format(ctx *FmtCtx) {
+	ctx.WriteString("DECLARE ")
+	ctx.FormatNode(&node.Name)
+	ctx.WriteString(" ")
+	if node.Scroll != UnspecifiedScroll {
+		ctx.WriteString(node.Scroll.String())
+		ctx.WriteString(" ")
+	}
+	ctx.WriteString("CURSOR ")
+	if node.Hold {
+		ctx.WriteString("WITH HOLD ")
+	}
+	ctx.WriteString("FOR ")
+	ctx.FormatNode(node.Select)
+}
+
+// CursorScrollOption represents the scroll option, if one was given, for a
+// DECLARE statement.
+type CursorScrollOption int8
+
+const (
+	// UnspecifiedScroll represents no SCROLL option having been given. In
+	// CockroachDB, this is treated as NO SCROLL.
+	UnspecifiedScroll CursorScrollOption = iota
+	// Scroll represents the SCROLL option, which allows the cursor to move
+	// backward.
+	Scroll
+	// NoScroll represents the NO SCROLL option.
+	NoScroll
+)
+
+func (o CursorScrollOption) String() string {
+	switch o {
+	case Scroll:
+		return "SCROLL"
+	case NoScroll:
+		return "NO SCROLL"
+	}
+	return ""
+}
+
+// FetchType represents the direction of a FETCH or MOVE statement.
+type FetchType int8
+
+const (
+	// FetchNormal represents a FETCH or MOVE that moves Count rows relative to
+	// the current position, backward if Count is negative. It is used for
+	// NEXT, PRIOR, FORWARD, BACKWARD and a bare count.
+	FetchNormal FetchType = iota
+	// FetchRelative represents RELATIVE Count, which returns the single row
+	// Count rows away from the current position.
+	FetchRelative
+	// FetchAbsolute represents ABSOLUTE Count, which returns the single row at
+	// position Count, counting from the end if Count is negative.
+	FetchAbsolute
+	// FetchFirst represents FIRST, which is the same as ABSOLUTE 1.
+	FetchFirst
+	// FetchLast represents LAST, which is the same as ABSOLUTE -1.
+	FetchLast
+	// FetchAll represents ALL and FORWARD ALL.
+	FetchAll
+	// FetchBackwardAll represents BACKWARD ALL.
+	FetchBackwardAll
+)
+
+func (o FetchType) String() string {
+	switch o {
+	case FetchRelative:
+		return "RELATIVE"
+	case FetchAbsolute:
+		return "ABSOLUTE"
+	case FetchFirst:
+		return "FIRST"
+	case FetchLast:
+		return "LAST"
+	case FetchAll:
+		return "ALL"
+	case FetchBackwardAll:
+		return "BACKWARD ALL"
+	}
+	return ""
+}
+
+// HasCount returns whether the FetchType takes a count.
+func (o FetchType) HasCount() bool {
+	switch o {
+	case FetchNormal, FetchRelative, FetchAbsolute:
+		return true
+	}
+	return false
+}
+
+// CursorStmt represents the parts shared by FETCH and MOVE statements.
+type CursorStmt struct {
+	Name      Name
+	FetchType FetchType
+	Count     int64
+}
+
+// Format implements the NodeFormatter interface.
+func (node *CursorStmt) Format(ctx *FmtCtx) {
+	if s := node.FetchType.String(); s != "" {
+		ctx.WriteString(s)
+		ctx.WriteString(" ")
+	}
+	if node.FetchType.HasCount() {
+		if ctx.HasFlags(FmtHideConstants) {
+			ctx.WriteByte('0')
+		} else {
+			ctx.WriteString(strconv.FormatInt(node.Count, 10))
+		}
+		ctx.WriteString(" ")
+	}
+	ctx.FormatNode(&node.Name)
+}
+
+// FetchCursor represents a FETCH statement.
+type FetchCursor struct {
+	CursorStmt
+}
+
+// Format implements the NodeFormatter interface.
+func (node *FetchCursor) Format(ctx *FmtCtx) {
+	ctx.WriteString("FETCH ")
+	ctx.FormatNode(&node.CursorStmt)
+}
+
+// MoveCursor represents a MOVE statement.
+type MoveCursor struct {
+	CursorStmt
+}
+
+// Format implements the NodeFormatter interface.
+func (node *MoveCursor) Format(ctx *FmtCtx) {
+	ctx.WriteString("MOVE ")
+	ctx.FormatNode(&node.CursorStmt)
+}
+
+// CloseCursor represents a CLOSE statement.
+type CloseCursor struct {
+	Name Name
+	All  bool
+}
+
+// Format implements the NodeFormatter interface.
+func (node *CloseCursor) Format(ctx *FmtCtx) {
+	ctx.WriteString("CLOSE ")
+	if node.All {
+		ctx.WriteString("ALL")
+	} else {
+		ctx.FormatNode(&node.Name)
+	}
+}
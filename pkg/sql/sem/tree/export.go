@@ -15,7 +15,10 @@ type Export struct {
 	Query      *Select
 	FileFormat string
 	File       Expr
-	Options    KVOptions
+	// PartitionBy lists the columns by whose values the exported rows are
+	// laid out in directories, if any.
+	PartitionBy NameList
+	Options     KVOptions
 }
 
 var _ Statement = &Export{}
@@ -26,6 +29,11 @@ func (node *Export) Format(ctx *FmtCtx) {
 	ctx.WriteString(node.FileFormat)
 	ctx.WriteString(" ")
 	ctx.FormatNode(node.File)
+	if node.PartitionBy != nil {
+		ctx.WriteString(" PARTITION BY (")
+		ctx.FormatNode(&node.PartitionBy)
+		ctx.WriteString(")")
+	}
 	if node.Options != nil {
 		ctx.WriteString(" WITH ")
 		ctx.FormatNode(&node.Options)
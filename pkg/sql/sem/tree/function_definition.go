@@ -155,12 +155,54 @@ func NewFunctionDefinition(
 	}
 }
 
+// NewUserDefinedFunctionDefinition creates the definition of a user-defined
+// function, which has a single overload. Unlike NewFunctionDefinition, no
+// telemetry counter is set up, since the name is not a builtin name.
+func NewUserDefinedFunctionDefinition(
+	name string, props *FunctionProperties, o *Overload,
+) *FunctionDefinition {
+	return &FunctionDefinition{
+		Name:               name,
+		Definition:         []overloadImpl{o},
+		FunctionProperties: *props,
+	}
+}
+
+// UDFDefinition describes a user-defined function. It is set on the Overload
+// of the function, whose Fn or Generator runs the body of the function.
+type UDFDefinition struct {
+	// ID is the ID of the descriptor of the function.
+	ID int64
+	// QualifiedName is the fully qualified name of the function.
+	QualifiedName *UnresolvedName
+	// Body is the SQL statement which defines the function. The arguments are
+	// referenced as placeholders, and all the names in it are fully qualified.
+	Body string
+	// ReturnsSet is true if the function returns a set of values.
+	ReturnsSet bool
+}
+
+// IsUserDefined returns true if the function is a user-defined function.
+func (fd *FunctionDefinition) IsUserDefined() bool {
+	if len(fd.Definition) != 1 {
+		return false
+	}
+	o, ok := fd.Definition[0].(*Overload)
+	return ok && o.UDF != nil
+}
+
 // FunDefs holds pre-allocated FunctionDefinition instances
 // for every builtin function. Initialized by builtins.init().
 var FunDefs map[string]*FunctionDefinition
 
-// Format implements the NodeFormatter interface.
+// Format implements the NodeFormatter interface. User-defined functions are
+// formatted using their fully qualified name, so that the formatted
+// expression refers to the same function regardless of the search path.
 func (fd *FunctionDefinition) Format(ctx *FmtCtx) {
+	if fd.IsUserDefined() {
+		ctx.FormatNode(fd.Definition[0].(*Overload).UDF.QualifiedName)
+		return
+	}
 	ctx.WriteString(fd.Name)
 }
 func (fd *FunctionDefinition) String() string { return AsString(fd) }
@@ -11,8 +11,11 @@
 package tree
 
 import (
+	"context"
 	"fmt"
 
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/errors"
@@ -41,15 +44,34 @@ func (fn *ResolvableFunctionReference) Format(ctx *FmtCtx) {
 func (fn *ResolvableFunctionReference) String() string { return AsString(fn) }
 
 // Resolve checks if the function name is already resolved and
-// resolves it as necessary.
+// resolves it as necessary. Only builtin functions are resolved; see
+// ResolveWith.
 func (fn *ResolvableFunctionReference) Resolve(
 	searchPath sessiondata.SearchPath,
+) (*FunctionDefinition, error) {
+	return fn.ResolveWith(context.Background(), searchPath, nil /* resolver */)
+}
+
+// ResolveWith is like Resolve, but names which do not refer to a builtin
+// function are also looked up as user-defined functions using the given
+// resolver, if it is not nil.
+func (fn *ResolvableFunctionReference) ResolveWith(
+	ctx context.Context, searchPath sessiondata.SearchPath, resolver FunctionReferenceResolver,
 ) (*FunctionDefinition, error) {
 	switch t := fn.FunctionReference.(type) {
 	case *FunctionDefinition:
 		return t, nil
 	case *UnresolvedName:
 		fd, err := t.ResolveFunction(searchPath)
+		if err != nil && resolver != nil && pgerror.GetPGCode(err) == pgcode.UndefinedFunction {
+			udf, udfErr := resolver.ResolveFunction(ctx, t, searchPath)
+			if udfErr != nil {
+				return nil, udfErr
+			}
+			if udf != nil {
+				fd, err = udf, nil
+			}
+		}
 		if err != nil {
 			return nil, err
 		}
@@ -62,6 +84,64 @@ func (fn *ResolvableFunctionReference) Resolve(
 	}
 }
 
+// FunctionReferenceResolver is the interface that provides the ability to
+// resolve names of user-defined functions.
+type FunctionReferenceResolver interface {
+	// ResolveFunction returns the definition of the user-defined function with
+	// the given name, or nil if there is no such function.
+	ResolveFunction(
+		ctx context.Context, name *UnresolvedName, searchPath sessiondata.SearchPath,
+	) (*FunctionDefinition, error)
+}
+
+// FunctionName corresponds to the name of a user-defined function.
+type FunctionName struct {
+	objName
+}
+
+var _ ObjectName = &FunctionName{}
+
+// MakeQualifiedFunctionName returns a new fully qualified function name.
+func MakeQualifiedFunctionName(db, schema, fn string) FunctionName {
+	return FunctionName{objName{
+		ObjectNamePrefix: ObjectNamePrefix{
+			ExplicitCatalog: true,
+			ExplicitSchema:  true,
+			CatalogName:     Name(db),
+			SchemaName:      Name(schema),
+		},
+		ObjectName: Name(fn),
+	}}
+}
+
+// Format implements the NodeFormatter interface.
+func (f *FunctionName) Format(ctx *FmtCtx) {
+	f.ObjectNamePrefix.Format(ctx)
+	if f.ExplicitSchema || ctx.alwaysFormatTablePrefix() {
+		ctx.WriteByte('.')
+	}
+	ctx.FormatNode(&f.ObjectName)
+}
+
+// String implements the Stringer interface.
+func (f *FunctionName) String() string {
+	return AsString(f)
+}
+
+// FQString renders the function name in full, not omitting the prefix
+// schema and catalog names. Suitable for logging, etc.
+func (f *FunctionName) FQString() string {
+	ctx := NewFmtCtx(FmtSimple)
+	ctx.FormatNode(&f.CatalogName)
+	ctx.WriteByte('.')
+	ctx.FormatNode(&f.SchemaName)
+	ctx.WriteByte('.')
+	ctx.FormatNode(&f.ObjectName)
+	return ctx.CloseAndGetString()
+}
+
+func (f *FunctionName) objectName() {}
+
 // WrapFunction creates a new ResolvableFunctionReference
 // holding a pre-resolved function. Helper for grammar rules.
 func WrapFunction(n string) ResolvableFunctionReference {
@@ -72,6 +152,18 @@ func WrapFunction(n string) ResolvableFunctionReference {
 	return ResolvableFunctionReference{fd}
 }
 
+// WrapFunctionOverload is like WrapFunction, but it also supports
+// user-defined functions, which cannot be found by name in FunDefs. For those,
+// a definition holding only the given overload is created.
+func WrapFunctionOverload(
+	n string, props *FunctionProperties, o *Overload,
+) ResolvableFunctionReference {
+	if o != nil && o.UDF != nil {
+		return ResolvableFunctionReference{NewUserDefinedFunctionDefinition(n, props, o)}
+	}
+	return WrapFunction(n)
+}
+
 // FunctionReference is the common interface to UnresolvedName and QualifiedFunctionName.
 type FunctionReference interface {
 	fmt.Stringer
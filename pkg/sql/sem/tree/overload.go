@@ -85,6 +85,9 @@ type Overload struct {
 	// volatility against Postgres's volatility at test time.
 	// This should be used with caution.
 	IgnoreVolatilityCheck bool
+
+	// UDF is set if this is the overload of a user-defined function.
+	UDF *UDFDefinition
 }
 
 // params implements the overloadImpl interface.
@@ -570,6 +570,48 @@ func (node *GroupBy) Format(ctx *FmtCtx) {
 	}
 }
 
+// GroupingSetType is the type of a GroupingSet.
+type GroupingSetType int8
+
+const (
+	// RollupGroupingSet represents ROLLUP (a, b, ...), which is shorthand for
+	// GROUPING SETS ((a, b, ...), ..., (a), ()).
+	RollupGroupingSet GroupingSetType = iota
+	// CubeGroupingSet represents CUBE (a, b, ...), which is shorthand for the
+	// grouping sets of every subset of the given expressions.
+	CubeGroupingSet
+	// GroupingSetsGroupingSet represents GROUPING SETS (...).
+	GroupingSetsGroupingSet
+)
+
+var groupingSetTypeName = [...]string{
+	RollupGroupingSet:       "ROLLUP",
+	CubeGroupingSet:         "CUBE",
+	GroupingSetsGroupingSet: "GROUPING SETS",
+}
+
+func (t GroupingSetType) String() string {
+	return groupingSetTypeName[t]
+}
+
+// GroupingSet represents a ROLLUP, CUBE or GROUPING SETS item in a GROUP BY
+// clause. The items of a ROLLUP or CUBE are expressions; a tuple groups
+// several expressions into a single item. The items of GROUPING SETS are
+// expressions or nested GroupingSets; the empty tuple is the empty grouping
+// set.
+type GroupingSet struct {
+	Type  GroupingSetType
+	Exprs Exprs
+}
+
+// Format implements the NodeFormatter interface.
+func (node *GroupingSet) Format(ctx *FmtCtx) {
+	ctx.WriteString(node.Type.String())
+	ctx.WriteString(" (")
+	ctx.FormatNode(&node.Exprs)
+	ctx.WriteByte(')')
+}
+
 // DistinctOn represents a DISTINCT ON clause.
 type DistinctOn []Expr
 
@@ -79,12 +79,17 @@ const (
 	BackupRangeDetails
 	// BackupFileDetails identifies a SHOW BACKUP FILES statement.
 	BackupFileDetails
+	// BackupDiffDetails identifies a SHOW BACKUP DIFF statement.
+	BackupDiffDetails
 )
 
 // ShowBackup represents a SHOW BACKUP statement.
 type ShowBackup struct {
-	Path                 Expr
-	InCollection         Expr
+	Path         Expr
+	InCollection Expr
+	// DiffPath is the backup that Path is compared against in a SHOW BACKUP
+	// DIFF statement.
+	DiffPath             Expr
 	Details              BackupDetails
 	ShouldIncludeSchemas bool
 	Options              KVOptions
@@ -102,11 +107,17 @@ func (node *ShowBackup) Format(ctx *FmtCtx) {
 		ctx.WriteString("RANGES ")
 	} else if node.Details == BackupFileDetails {
 		ctx.WriteString("FILES ")
+	} else if node.Details == BackupDiffDetails {
+		ctx.WriteString("DIFF ")
 	}
 	if node.ShouldIncludeSchemas {
 		ctx.WriteString("SCHEMAS ")
 	}
 	ctx.FormatNode(node.Path)
+	if node.DiffPath != nil {
+		ctx.WriteString(" AND ")
+		ctx.FormatNode(node.DiffPath)
+	}
 	if node.InCollection != nil {
 		ctx.WriteString(" IN ")
 		ctx.FormatNode(node.InCollection)
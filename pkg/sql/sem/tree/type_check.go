@@ -49,6 +49,10 @@ type SemaContext struct {
 	// TypeResolver manages resolving type names into *types.T's.
 	TypeResolver TypeReferenceResolver
 
+	// FunctionResolver manages resolving the names of user-defined functions.
+	// If it is nil, only builtin functions can be used.
+	FunctionResolver FunctionReferenceResolver
+
 	// AsOfTimestamp denotes the explicit AS OF SYSTEM TIME timestamp for the
 	// query, if any. If the query is not an AS OF SYSTEM TIME query,
 	// AsOfTimestamp is nil.
@@ -144,6 +148,11 @@ const (
 	// RejectSubqueries rejects subqueries in scalar contexts.
 	RejectSubqueries
 
+	// RejectUserDefinedFunctions rejects any use of user-defined functions.
+	// It is used for expressions which are stored in descriptors, since those
+	// do not record a dependency on the functions they use.
+	RejectUserDefinedFunctions
+
 	// RejectSpecial is used in common places like the LIMIT clause.
 	RejectSpecial = RejectAggregates | RejectGenerators | RejectWindowApplications
 )
@@ -746,6 +755,7 @@ var (
 	errInvalidDefaultUsage = pgerror.New(pgcode.Syntax, "DEFAULT can only appear in a VALUES list within INSERT or on the right side of a SET")
 	errInvalidMaxUsage     = pgerror.New(pgcode.Syntax, "MAXVALUE can only appear within a range partition expression")
 	errInvalidMinUsage     = pgerror.New(pgcode.Syntax, "MINVALUE can only appear within a range partition expression")
+	errInvalidGroupingSet  = pgerror.New(pgcode.Syntax, "ROLLUP, CUBE and GROUPING SETS can only appear in a GROUP BY clause")
 	errPrivateFunction     = pgerror.New(pgcode.ReservedName, "function reserved for internal use")
 )
 
@@ -800,6 +810,11 @@ func (sc *SemaContext) checkFunctionUsage(expr *FuncExpr, def *FunctionDefinitio
 		return nil
 	}
 
+	if def.IsUserDefined() && sc.Properties.required.rejectFlags&RejectUserDefinedFunctions != 0 {
+		return pgerror.Newf(pgcode.FeatureNotSupported,
+			"user-defined functions are not allowed in %s", sc.Properties.required.context)
+	}
+
 	if expr.IsWindowFunctionApplication() {
 		if sc.Properties.required.rejectFlags&RejectWindowApplications != 0 {
 			return NewInvalidFunctionUsageError(WindowClass, sc.Properties.required.context)
@@ -890,10 +905,12 @@ func (expr *FuncExpr) TypeCheck(
 	ctx context.Context, semaCtx *SemaContext, desired *types.T,
 ) (TypedExpr, error) {
 	var searchPath sessiondata.SearchPath
+	var resolver FunctionReferenceResolver
 	if semaCtx != nil {
 		searchPath = semaCtx.SearchPath
+		resolver = semaCtx.FunctionResolver
 	}
-	def, err := expr.Func.Resolve(searchPath)
+	def, err := expr.Func.ResolveWith(ctx, searchPath, resolver)
 	if err != nil {
 		return nil, err
 	}
@@ -1368,6 +1385,13 @@ func (expr PartitionMinVal) TypeCheck(
 	return nil, errInvalidMinUsage
 }
 
+// TypeCheck implements the Expr interface.
+func (expr *GroupingSet) TypeCheck(
+	_ context.Context, _ *SemaContext, desired *types.T,
+) (TypedExpr, error) {
+	return nil, errInvalidGroupingSet
+}
+
 // TypeCheck implements the Expr interface.
 func (expr PartitionMaxVal) TypeCheck(
 	_ context.Context, _ *SemaContext, desired *types.T,
@@ -2481,8 +2505,13 @@ func (v *placeholderAnnotationVisitor) VisitPre(expr Expr) (recurse bool, newExp
 				}
 
 			case typeFromHint:
-				// Verify that the annotation is consistent with the type hint.
-				if prevType := v.types[arg.Idx]; !tType.Equivalent(prevType) {
+				if prevType := v.types[arg.Idx]; prevType.Family() == types.UnknownFamily {
+					// An annotation overrides a hint of type unknown, which is given
+					// to NULL arguments (e.g. by the internal executor).
+					v.types[arg.Idx] = tType
+					v.state[arg.Idx] = typeFromAnnotation
+				} else if !tType.Equivalent(prevType) {
+					// Verify that the annotation is consistent with the type hint.
 					v.setErr(arg.Idx, pgerror.Newf(
 						pgcode.DatatypeMismatch,
 						"type annotation around %s conflicts with specified type %s",
@@ -185,6 +185,17 @@ func (expr *CoalesceExpr) Walk(v Visitor) Expr {
 	return ret
 }
 
+// Walk implements the Expr interface.
+func (expr *GroupingSet) Walk(v Visitor) Expr {
+	exprs, changed := walkExprSlice(v, expr.Exprs)
+	if changed {
+		exprCopy := *expr
+		exprCopy.Exprs = exprs
+		return &exprCopy
+	}
+	return expr
+}
+
 // Walk implements the Expr interface.
 func (expr *ComparisonExpr) Walk(v Visitor) Expr {
 	left, changedL := WalkExpr(v, expr.Left)
@@ -1443,6 +1454,13 @@ var _ walkableStmt = &BeginTransaction{}
 // statement by itself. For example, it will not walk into Subquery nodes
 // within a FROM clause or into a JoinCond. Walk's logic is pretty
 // interdependent with the logic for constructing a query plan.
+// WalkStmt walks the expressions of the given statement using the visitor,
+// in the same way as WalkExpr. Statements which do not support walking are
+// returned unchanged.
+func WalkStmt(v Visitor, stmt Statement) (newStmt Statement, changed bool) {
+	return walkStmt(v, stmt)
+}
+
 func walkStmt(v Visitor, stmt Statement) (newStmt Statement, changed bool) {
 	walkable, ok := stmt.(walkableStmt)
 	if !ok {
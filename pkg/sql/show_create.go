@@ -161,11 +161,31 @@ func ShowCreateTable(
 		if idx.ID != desc.GetPrimaryIndex().ID && includeInterleaveClause {
 			// Showing the primary index is handled above.
 			f.WriteString(",\n\t")
-			idxStr, err := catformat.IndexForDisplay(ctx, desc, &descpb.AnonymousTable, idx, &p.RunParams(ctx).p.semaCtx)
-			if err != nil {
-				return "", err
+			if idx.DeferrableUnique {
+				// The index of a DEFERRABLE unique constraint is not unique, so the
+				// constraint is shown instead.
+				f.WriteString("CONSTRAINT ")
+				f.FormatNameP(&idx.Name)
+				f.WriteString(" UNIQUE (")
+				idx.ColNamesFormat(f)
+				f.WriteByte(')')
+				if len(idx.StoreColumnNames) > 0 {
+					f.WriteString(" STORING (")
+					for i := range idx.StoreColumnNames {
+						if i > 0 {
+							f.WriteString(", ")
+						}
+						f.FormatNameP(&idx.StoreColumnNames[i])
+					}
+					f.WriteByte(')')
+				}
+			} else {
+				idxStr, err := catformat.IndexForDisplay(ctx, desc, &descpb.AnonymousTable, idx, &p.RunParams(ctx).p.semaCtx)
+				if err != nil {
+					return "", err
+				}
+				f.WriteString(idxStr)
 			}
-			f.WriteString(idxStr)
 			// Showing the INTERLEAVE and PARTITION BY for the primary index are
 			// handled last.
 
@@ -181,6 +201,8 @@ func ShowCreateTable(
 			); err != nil {
 				return "", err
 			}
+			// DEFERRABLE comes after the PARTITION BY clause of the constraint.
+			f.FormatNode(idx.Deferrability())
 		}
 	}
 
@@ -0,0 +1,786 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sql
+
+import (
+	"context"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/kv"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/colinfo"
+	"github.com/cockroachdb/cockroach/pkg/sql/execinfra"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/memo"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/rowcontainer"
+	"github.com/cockroachdb/cockroach/pkg/sql/rowenc"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util/errorutil/unimplemented"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/mon"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/errors"
+)
+
+// sqlCursor is a cursor created by DECLARE.
+//
+// The query of a cursor is planned when the cursor is declared, but it is only
+// run on the first FETCH or MOVE, and only as far as the cursor is moved: the
+// flow is suspended after every row it produces until the cursor asks for the
+// next one. The flow runs in a leaf txn of the transaction that declared the
+// cursor so that the session's root txn stays free for other statements. The
+// reads of the leaf txn are recorded in the root txn before it runs another
+// statement, so that they are refreshed with its own if it is pushed, and the
+// cursor can't be read once the root txn has moved to another timestamp.
+//
+// Cursors declared WITH HOLD outlive their transaction. When such a
+// transaction commits, the remaining rows of the query are read into a
+// disk-backed row container and the flow is closed. SCROLL cursors store every
+// row they read in the same kind of container so that they can move backward.
+type sqlCursor struct {
+	name tree.Name
+	// stmt is the DECLARE statement that created the cursor.
+	stmt      string
+	cols      colinfo.ResultColumns
+	scroll    bool
+	hold      bool
+	createdAt time.Time
+	// committed is set once the transaction that declared a WITH HOLD cursor
+	// has committed. Cursors that are not committed are closed when their
+	// transaction rolls back or restarts.
+	committed bool
+
+	// flow produces the rows of the cursor. It is nil once the rows have been
+	// materialized or the cursor has been closed.
+	flow *cursorFlow
+
+	// rows stores the rows read from flow, if the cursor is scrollable or has
+	// been materialized. Row i (1-based) is stored at index i-1-rowsOffset.
+	rows       *rowcontainer.DiskBackedIndexedRowContainer
+	rowsOffset int64
+	memMon     *mon.BytesMonitor
+	diskMon    *mon.BytesMonitor
+
+	// numRead is the number of rows read from flow so far, and exhausted is set
+	// once flow has no more rows.
+	numRead   int64
+	exhausted bool
+	// pos is the current position of the cursor, following Postgres: 0 is
+	// before the first row, and numRead+1 is after the last row once the
+	// cursor is exhausted. cur is the row at pos, if any.
+	pos int64
+	cur tree.Datums
+}
+
+// errCursorNotScrollable is returned when a cursor declared without SCROLL is
+// asked to move backward.
+var errCursorNotScrollable = errors.WithHint(
+	pgerror.New(pgcode.ObjectNotInPrerequisiteState, "cursor can only scan forward"),
+	"Declare it with SCROLL option to enable backward scan.",
+)
+
+// readRow reads the next row from the cursor's flow, returning nil once there
+// are no more rows.
+func (c *sqlCursor) readRow(ctx context.Context) (tree.Datums, error) {
+	if c.exhausted {
+		return nil, nil
+	}
+	if readTS := c.flow.root.ReadTimestamp(); !readTS.Equal(c.flow.readTS) {
+		return nil, errors.WithHint(
+			pgerror.Newf(pgcode.SerializationFailure,
+				"cursor %q cannot be read after its transaction was pushed from %s to %s",
+				c.name, c.flow.readTS, readTS),
+			"Retry the transaction.",
+		)
+	}
+	row, err := c.flow.next(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if row == nil {
+		c.exhausted = true
+		return nil, nil
+	}
+	if c.rows != nil {
+		encRow := make(rowenc.EncDatumRow, len(row))
+		for i := range row {
+			encRow[i] = rowenc.DatumToEncDatum(c.cols[i].Typ, row[i])
+		}
+		if err := c.rows.AddRow(ctx, encRow); err != nil {
+			return nil, err
+		}
+	}
+	c.numRead++
+	return row, nil
+}
+
+// storedRow returns row i (1-based) from the cursor's row container.
+func (c *sqlCursor) storedRow(ctx context.Context, i int64) (tree.Datums, error) {
+	if c.rows == nil || i <= c.rowsOffset {
+		return nil, errors.AssertionFailedf("row %d of cursor %q is not stored", i, c.name)
+	}
+	row, err := c.rows.GetRow(ctx, int(i-1-c.rowsOffset))
+	if err != nil {
+		return nil, err
+	}
+	return row.GetDatums(0, len(c.cols))
+}
+
+// seek moves the cursor to position target and returns the row at that
+// position, or nil if target is before the first or after the last row.
+func (c *sqlCursor) seek(ctx context.Context, target int64) (tree.Datums, error) {
+	if target < c.pos && !c.scroll {
+		return nil, errCursorNotScrollable
+	}
+	if target <= 0 {
+		c.pos, c.cur = 0, nil
+		return nil, nil
+	}
+	if target == c.pos {
+		return c.cur, nil
+	}
+	if target > c.numRead {
+		// Read forward until we reach target. Rows that are skipped over are only
+		// kept if the cursor stores its rows.
+		for c.numRead < target {
+			row, err := c.readRow(ctx)
+			if err != nil {
+				return nil, err
+			}
+			if row == nil {
+				c.pos, c.cur = c.numRead+1, nil
+				return nil, nil
+			}
+			c.cur = row
+		}
+		c.pos = target
+		return c.cur, nil
+	}
+	row, err := c.storedRow(ctx, target)
+	if err != nil {
+		return nil, err
+	}
+	c.pos, c.cur = target, row
+	return row, nil
+}
+
+// fetch moves the cursor as specified by a FETCH or MOVE statement, calling
+// emit for every row that the statement returns.
+func (c *sqlCursor) fetch(
+	ctx context.Context, s *tree.CursorStmt, emit func(tree.Datums) error,
+) error {
+	step := func(target int64) (bool, error) {
+		row, err := c.seek(ctx, target)
+		if err != nil || row == nil {
+			return false, err
+		}
+		return true, emit(row)
+	}
+	// absolute moves to the row at position n, counting from the end if n is
+	// negative.
+	absolute := func(n int64) error {
+		if n < 0 {
+			if !c.scroll {
+				return errCursorNotScrollable
+			}
+			for !c.exhausted {
+				if _, err := c.readRow(ctx); err != nil {
+					return err
+				}
+			}
+			n += c.numRead + 1
+			if n < 0 {
+				n = 0
+			}
+		}
+		_, err := step(n)
+		return err
+	}
+
+	switch s.FetchType {
+	case tree.FetchNormal:
+		if s.Count == 0 {
+			_, err := step(c.pos)
+			return err
+		}
+		delta, count := int64(1), s.Count
+		if count < 0 {
+			delta, count = -1, -count
+		}
+		for i := int64(0); i < count; i++ {
+			if ok, err := step(c.pos + delta); err != nil || !ok {
+				return err
+			}
+		}
+	case tree.FetchAll, tree.FetchBackwardAll:
+		delta := int64(1)
+		if s.FetchType == tree.FetchBackwardAll {
+			delta = -1
+		}
+		for {
+			if ok, err := step(c.pos + delta); err != nil || !ok {
+				return err
+			}
+		}
+	case tree.FetchRelative:
+		target := c.pos + s.Count
+		if s.Count > math.MaxInt64-c.pos {
+			target = math.MaxInt64
+		} else if target < 0 {
+			target = 0
+		}
+		_, err := step(target)
+		return err
+	case tree.FetchAbsolute:
+		return absolute(s.Count)
+	case tree.FetchFirst:
+		return absolute(1)
+	case tree.FetchLast:
+		return absolute(-1)
+	default:
+		return errors.AssertionFailedf("unknown fetch type %s", s.FetchType)
+	}
+	return nil
+}
+
+// close releases the resources of the cursor. If mergeIntoRoot is set, the
+// reads performed by the cursor's flow are recorded in the transaction that
+// declared the cursor, which must still be open.
+func (c *sqlCursor) close(ctx context.Context, mergeIntoRoot bool) {
+	if c.flow != nil {
+		c.flow.close(ctx, mergeIntoRoot)
+		c.flow = nil
+	}
+	if c.rows != nil {
+		c.rows.Close(ctx)
+		c.diskMon.Stop(ctx)
+		c.memMon.Stop(ctx)
+		c.rows = nil
+	}
+}
+
+// cursorFlow runs the query of a cursor in its own goroutine, handing each row
+// to the session synchronously: the goroutine blocks after producing a row
+// until the session asks for the next one or closes the flow. Because of this,
+// the root txn is never used concurrently by the session and the flow.
+type cursorFlow struct {
+	// p is the planner that planned the cursor's query. Its txn is the leaf txn
+	// in which the flow runs.
+	p    *planner
+	root *kv.Txn
+	leaf *kv.Txn
+	// readTS is the timestamp at which the leaf txn reads, which is the read
+	// timestamp of the root txn when the cursor was declared.
+	readTS hlc.Timestamp
+	// unmerged is set once the flow has read from the leaf txn, until the
+	// reads are recorded in the root txn.
+	unmerged bool
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	// mon accounts for the memory used by the flow. It is not a child of the
+	// txn's monitor since a cursor can be closed after its txn has finished.
+	mon *mon.BytesMonitor
+
+	started bool
+	done    bool
+	// resume is sent true by the session to ask for the next row, or false to
+	// stop the flow. yield is sent each row, and finally the result of the flow,
+	// by the flow's goroutine.
+	resume chan bool
+	yield  chan cursorFlowResult
+}
+
+type cursorFlowResult struct {
+	row  tree.Datums
+	err  error
+	done bool
+}
+
+// errCursorClosed is returned to the flow of a cursor by its result writer
+// when the cursor is closed before the flow has produced all of its rows.
+var errCursorClosed = errors.New("cursor closed")
+
+// next returns the next row of the flow, or nil once there are no more rows.
+func (f *cursorFlow) next(ctx context.Context) (tree.Datums, error) {
+	if f.done {
+		return nil, nil
+	}
+	f.unmerged = true
+	if !f.started {
+		f.started = true
+		if err := f.p.execCfg.DistSQLSrv.Stopper.RunAsyncTask(f.ctx, "sql-cursor", f.run); err != nil {
+			f.done = true
+			f.p.curPlan.close(ctx)
+			return nil, err
+		}
+	} else {
+		// The flow may have stopped waiting for the session because the server
+		// is shutting down, in which case it is already reporting its result.
+		select {
+		case f.resume <- true:
+		case res := <-f.yield:
+			f.done = true
+			return nil, res.err
+		}
+	}
+	res := <-f.yield
+	if res.done {
+		f.done = true
+		return nil, res.err
+	}
+	return res.row, nil
+}
+
+// close stops the flow, if it is still running.
+func (f *cursorFlow) close(ctx context.Context, mergeIntoRoot bool) {
+	if !f.started {
+		f.p.curPlan.close(ctx)
+	} else if !f.done {
+		select {
+		case f.resume <- false:
+			<-f.yield
+		case <-f.yield:
+		}
+	}
+	f.done = true
+	f.cancel()
+	f.mon.Stop(ctx)
+	if mergeIntoRoot {
+		if err := f.mergeIntoRoot(ctx); err != nil {
+			log.Warningf(ctx, "error merging state of cursor txn: %v", err)
+		}
+	}
+}
+
+// mergeIntoRoot records the reads performed by the flow since it was last
+// merged in the root txn. The flow must not be reading.
+func (f *cursorFlow) mergeIntoRoot(ctx context.Context) error {
+	if !f.unmerged {
+		return nil
+	}
+	tfs, err := f.leaf.GetLeafTxnFinalState(ctx)
+	if err != nil {
+		return err
+	}
+	if err := f.root.UpdateRootWithLeafFinalState(ctx, &tfs); err != nil {
+		return err
+	}
+	f.unmerged = false
+	return nil
+}
+
+// run is the body of the flow's goroutine.
+func (f *cursorFlow) run(ctx context.Context) {
+	w := &cursorRowWriter{f: f}
+	f.runPlan(ctx, w)
+	err := w.Err()
+	if err == nil && !errors.Is(w.commErr, errCursorClosed) {
+		err = w.commErr
+	}
+	f.yield <- cursorFlowResult{err: err, done: true}
+}
+
+func (f *cursorFlow) runPlan(ctx context.Context, w *cursorRowWriter) {
+	p := f.p
+	defer p.curPlan.close(ctx)
+	dsp := p.execCfg.DistSQLPlanner
+	recv := MakeDistSQLReceiver(
+		ctx, w, tree.Rows,
+		p.execCfg.RangeDescriptorCache,
+		f.root,
+		func(ts hlc.Timestamp) {
+			p.execCfg.Clock.Update(ts)
+		},
+		p.ExtendedEvalContext().Tracing,
+	)
+	defer func() {
+		w.commErr = recv.commErr
+		recv.Release()
+	}()
+
+	// Setting up a local flow makes the eval context point to the flow's
+	// monitor, so the eval context is reset to the cursor's monitor before every
+	// flow. The subqueries run one after the other before the main query, so
+	// they can otherwise share the planner's eval context.
+	evalCtx := p.ExtendedEvalContext()
+	evalCtxFactory := func() *extendedEvalContext {
+		evalCtx.Mon = f.mon
+		return evalCtx
+	}
+	if len(p.curPlan.subqueryPlans) != 0 {
+		if !dsp.PlanAndRunSubqueries(ctx, p, evalCtxFactory, p.curPlan.subqueryPlans, recv) {
+			return
+		}
+	}
+	evalCtx = evalCtxFactory()
+	// The flow must run locally: a distributed flow would need leaf txns
+	// created from the root txn, but the flow only has a leaf.
+	planCtx := dsp.NewPlanningCtx(ctx, evalCtx, p, f.leaf, false /* distribute */)
+	planCtx.stmtType = tree.Rows
+	dsp.PlanAndRun(ctx, evalCtx, planCtx, f.leaf, p.curPlan.main, recv)()
+}
+
+// cursorRowWriter is the rowResultWriter of a cursor's flow. It hands every
+// row to the session and waits until the session asks for the next one.
+type cursorRowWriter struct {
+	f       *cursorFlow
+	err     error
+	commErr error
+	closed  bool
+}
+
+var _ rowResultWriter = &cursorRowWriter{}
+
+// AddRow is part of the rowResultWriter interface.
+func (w *cursorRowWriter) AddRow(ctx context.Context, row tree.Datums) error {
+	if w.closed {
+		return errCursorClosed
+	}
+	// The caller may reuse row, so hand over a copy.
+	w.f.yield <- cursorFlowResult{row: append(tree.Datums(nil), row...)}
+	select {
+	case resume := <-w.f.resume:
+		if !resume {
+			w.closed = true
+			return errCursorClosed
+		}
+		return nil
+	case <-w.f.p.execCfg.DistSQLSrv.Stopper.ShouldQuiesce():
+		w.closed = true
+		return errors.New("server is shutting down")
+	}
+}
+
+// IncrementRowsAffected is part of the rowResultWriter interface.
+func (w *cursorRowWriter) IncrementRowsAffected(n int) {}
+
+// SetError is part of the rowResultWriter interface.
+func (w *cursorRowWriter) SetError(err error) {
+	w.err = err
+}
+
+// Err is part of the rowResultWriter interface.
+func (w *cursorRowWriter) Err() error {
+	return w.err
+}
+
+// cursorMap holds the cursors of a session.
+type cursorMap struct {
+	cursors map[tree.Name]*sqlCursor
+}
+
+func (m *cursorMap) get(name tree.Name) (*sqlCursor, error) {
+	c, ok := m.cursors[name]
+	if !ok {
+		return nil, pgerror.Newf(pgcode.InvalidCursorName, "cursor %q does not exist", name)
+	}
+	return c, nil
+}
+
+func (m *cursorMap) add(c *sqlCursor) {
+	if m.cursors == nil {
+		m.cursors = make(map[tree.Name]*sqlCursor)
+	}
+	m.cursors[c.name] = c
+}
+
+// closeCursor closes and removes the cursor with the given name.
+func (m *cursorMap) closeCursor(ctx context.Context, name tree.Name, mergeIntoRoot bool) error {
+	c, err := m.get(name)
+	if err != nil {
+		return err
+	}
+	c.close(ctx, mergeIntoRoot)
+	delete(m.cursors, name)
+	return nil
+}
+
+// closeAll closes and removes all cursors for which the filter returns true.
+func (m *cursorMap) closeAll(
+	ctx context.Context, mergeIntoRoot bool, filter func(*sqlCursor) bool,
+) {
+	for name, c := range m.cursors {
+		if filter == nil || filter(c) {
+			c.close(ctx, mergeIntoRoot)
+			delete(m.cursors, name)
+		}
+	}
+}
+
+// list returns the cursors ordered by name.
+func (m *cursorMap) list() []*sqlCursor {
+	ret := make([]*sqlCursor, 0, len(m.cursors))
+	for _, c := range m.cursors {
+		ret = append(ret, c)
+	}
+	sort.Slice(ret, func(i, j int) bool { return ret[i].name < ret[j].name })
+	return ret
+}
+
+// sqlCursorsAccessor gives the planner access to the cursors of a session.
+type sqlCursorsAccessor interface {
+	// List returns the cursors of the session, ordered by name.
+	List() []*sqlCursor
+	// CloseAll closes all cursors of the session.
+	CloseAll(ctx context.Context)
+}
+
+// connExCursorsAccessor is an implementation of sqlCursorsAccessor that gives
+// access to a connExecutor's cursors.
+type connExCursorsAccessor struct {
+	ex *connExecutor
+}
+
+var _ sqlCursorsAccessor = connExCursorsAccessor{}
+
+// List is part of the sqlCursorsAccessor interface.
+func (a connExCursorsAccessor) List() []*sqlCursor {
+	return a.ex.sqlCursors.list()
+}
+
+// CloseAll is part of the sqlCursorsAccessor interface.
+func (a connExCursorsAccessor) CloseAll(ctx context.Context) {
+	a.ex.closeAllCursors(ctx)
+}
+
+// execCursorStmt executes a DECLARE, FETCH, MOVE or CLOSE statement.
+func (ex *connExecutor) execCursorStmt(
+	ctx context.Context, p *planner, implicitTxn bool, res RestrictedCommandResult,
+) error {
+	switch s := p.stmt.AST.(type) {
+	case *tree.DeclareCursor:
+		return ex.execDeclareCursor(ctx, p, s, implicitTxn)
+	case *tree.FetchCursor:
+		c, err := ex.sqlCursors.get(s.Name)
+		if err != nil {
+			return err
+		}
+		res.SetColumns(ctx, c.cols)
+		return c.fetch(ctx, &s.CursorStmt, func(row tree.Datums) error {
+			return res.AddRow(ctx, row)
+		})
+	case *tree.MoveCursor:
+		c, err := ex.sqlCursors.get(s.Name)
+		if err != nil {
+			return err
+		}
+		var n int
+		err = c.fetch(ctx, &s.CursorStmt, func(tree.Datums) error {
+			n++
+			return nil
+		})
+		res.IncrementRowsAffected(n)
+		return err
+	case *tree.CloseCursor:
+		if s.All {
+			ex.closeAllCursors(ctx)
+			return nil
+		}
+		return ex.sqlCursors.closeCursor(ctx, s.Name, ex.cursorTxnOpen())
+	default:
+		return errors.AssertionFailedf("unexpected cursor statement %T", s)
+	}
+}
+
+// execDeclareCursor plans the query of a DECLARE statement and creates the
+// cursor. The query does not start running until the cursor is first used.
+func (ex *connExecutor) execDeclareCursor(
+	ctx context.Context, p *planner, s *tree.DeclareCursor, implicitTxn bool,
+) error {
+	if implicitTxn && !s.Hold {
+		return pgerror.New(pgcode.NoActiveSQLTransaction,
+			"DECLARE CURSOR can only be used in transaction blocks")
+	}
+	if _, ok := ex.sqlCursors.cursors[s.Name]; ok {
+		return pgerror.Newf(pgcode.DuplicateCursor, "cursor %q already exists", s.Name)
+	}
+	if p.stmt.NumPlaceholders > 0 {
+		return unimplemented.NewWithIssue(41412, "DECLARE CURSOR with placeholders")
+	}
+
+	root := ex.state.mu.txn
+	tis, err := root.GetLeafTxnInputStateOrRejectClient(ctx)
+	if err != nil {
+		return err
+	}
+	leaf := kv.NewLeafTxn(ctx, ex.server.cfg.DB, ex.transitionCtx.nodeIDOrZero, &tis)
+
+	// The flow's context is derived from the transaction's context so that
+	// canceling a FETCH, which cancels the transaction's context, also cancels
+	// the flow.
+	flowCtx, cancel := context.WithCancel(ex.state.Ctx)
+	cp := &planner{execCfg: ex.server.cfg, alloc: &rowenc.DatumAlloc{}}
+	ex.initPlanner(flowCtx, cp)
+	// The query is planned in the root txn, which leases the descriptors it
+	// uses, and then run in the leaf txn.
+	ex.resetPlanner(flowCtx, cp, root, p.ExtendedEvalContext().StmtTimestamp)
+	flowMon := execinfra.NewMonitor(ctx, ex.sessionMon, "cursor-"+string(s.Name))
+	cp.extendedEvalCtx.Mon = flowMon
+	// The flows of the cursor, including those of its subqueries, must run
+	// locally; see runPlan.
+	sd := *ex.sessionData
+	sd.DistSQLMode = sessiondata.DistSQLOff
+	cp.extendedEvalCtx.SessionData = &sd
+	cp.stmt = makeStatement(parser.Statement{
+		AST:            s.Select,
+		SQL:            tree.AsString(s.Select),
+		NumAnnotations: p.stmt.NumAnnotations,
+	}, ex.generateID())
+	cp.semaCtx.Annotations = tree.MakeAnnotations(p.stmt.NumAnnotations)
+	cp.extendedEvalCtx.Placeholders = &cp.semaCtx.Placeholders
+	cp.extendedEvalCtx.Annotations = &cp.semaCtx.Annotations
+	if err := cp.makeOptimizerPlan(ctx); err != nil {
+		cp.curPlan.close(ctx)
+		flowMon.Stop(ctx)
+		cancel()
+		return err
+	}
+	if rel, ok := cp.curPlan.mem.RootExpr().(memo.RelExpr); ok && rel.Relational().CanMutate {
+		cp.curPlan.close(ctx)
+		flowMon.Stop(ctx)
+		cancel()
+		return pgerror.New(pgcode.FeatureNotSupported,
+			"DECLARE CURSOR must not contain data-modifying statements in WITH")
+	}
+
+	cp.txn = leaf
+	cp.extendedEvalCtx.Txn = leaf
+
+	c := &sqlCursor{
+		name:      s.Name,
+		stmt:      tree.AsString(s),
+		cols:      cp.curPlan.main.planColumns(),
+		scroll:    s.Scroll == tree.Scroll,
+		hold:      s.Hold,
+		createdAt: timeutil.Now(),
+		flow: &cursorFlow{
+			p:      cp,
+			root:   root,
+			leaf:   leaf,
+			readTS: leaf.ReadTimestamp(),
+			ctx:    flowCtx,
+			cancel: cancel,
+			mon:    flowMon,
+			resume: make(chan bool),
+			yield:  make(chan cursorFlowResult),
+		},
+	}
+	if c.scroll {
+		ex.initCursorRows(ctx, c)
+	}
+	ex.sqlCursors.add(c)
+	return nil
+}
+
+// memRequiredByCursorRows is the minimum amount of memory (in bytes) given to
+// the row container of a cursor.
+const memRequiredByCursorRows = 100 * 1024
+
+// initCursorRows creates the row container of a cursor. It stores the rows
+// read after the ones that have been read so far.
+func (ex *connExecutor) initCursorRows(ctx context.Context, c *sqlCursor) {
+	name := "cursor-" + string(c.name)
+	distSQLCfg := &ex.server.cfg.DistSQLSrv.ServerConfig
+	limit := execinfra.GetWorkMemLimit(distSQLCfg)
+	if distSQLCfg.TestingKnobs.ForceDiskSpill || limit < memRequiredByCursorRows {
+		// The row container caches the rows that it reads back from disk, so it
+		// needs some memory even once it has spilled.
+		limit = memRequiredByCursorRows
+	}
+	c.memMon = mon.NewMonitorInheritWithLimit(name+"-mem", limit, ex.sessionMon)
+	c.memMon.Start(ctx, ex.sessionMon, mon.BoundAccount{})
+	c.diskMon = execinfra.NewMonitor(ctx, distSQLCfg.DiskMonitor, name+"-disk")
+	typs := make([]*types.T, len(c.cols))
+	for i := range c.cols {
+		typs[i] = c.cols[i].Typ
+	}
+	c.rows = rowcontainer.NewDiskBackedIndexedRowContainer(
+		nil /* ordering */, typs, &c.flow.p.extendedEvalCtx.EvalContext,
+		distSQLCfg.TempStorage, c.memMon, c.diskMon,
+	)
+	c.rowsOffset = c.numRead
+}
+
+// mergeCursorsIntoRoot records the reads performed by the flows of the cursors
+// in the root txn, which is about to run a statement.
+func (ex *connExecutor) mergeCursorsIntoRoot(ctx context.Context) error {
+	for _, c := range ex.sqlCursors.cursors {
+		if c.flow == nil {
+			continue
+		}
+		if err := c.flow.mergeIntoRoot(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// cursorTxnOpen returns whether the transaction in which the session's
+// cursors were declared can still record their reads.
+func (ex *connExecutor) cursorTxnOpen() bool {
+	_, ok := ex.machine.CurState().(stateOpen)
+	return ok
+}
+
+// closeAllCursors closes all cursors of the session.
+func (ex *connExecutor) closeAllCursors(ctx context.Context) {
+	ex.sqlCursors.closeAll(ctx, ex.cursorTxnOpen(), nil /* filter */)
+}
+
+// prepareCursorsForCommit is called before a transaction commits. It reads
+// the remaining rows of the WITH HOLD cursors declared in the transaction and
+// closes all other cursors.
+func (ex *connExecutor) prepareCursorsForCommit(ctx context.Context) error {
+	for _, c := range ex.sqlCursors.list() {
+		if c.flow == nil {
+			continue
+		}
+		if !c.hold {
+			if err := ex.sqlCursors.closeCursor(ctx, c.name, true /* mergeIntoRoot */); err != nil {
+				return err
+			}
+			continue
+		}
+		if c.rows == nil {
+			ex.initCursorRows(ctx, c)
+		}
+		for !c.exhausted {
+			if _, err := c.readRow(ctx); err != nil {
+				return err
+			}
+		}
+		c.flow.close(ctx, true /* mergeIntoRoot */)
+		c.flow = nil
+	}
+	return nil
+}
+
+// resetCursorsForTxnEnd updates the session's cursors at the end of a
+// transaction. Cursors are only left open after their transaction commits, if
+// they were declared WITH HOLD.
+func (ex *connExecutor) resetCursorsForTxnEnd(ctx context.Context, ev txnEvent) {
+	if ev == txnCommit {
+		for _, c := range ex.sqlCursors.cursors {
+			c.committed = true
+		}
+		return
+	}
+	ex.sqlCursors.closeAll(ctx, false /* mergeIntoRoot */, func(c *sqlCursor) bool {
+		return !c.committed
+	})
+}
@@ -0,0 +1,76 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sql_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/stretchr/testify/require"
+)
+
+// TestCursorTxnPushed checks that the reads of a cursor are refreshed along
+// with those of its transaction when the transaction is pushed between the
+// DECLARE and a FETCH, and that the cursor can't be read at its old timestamp
+// afterwards.
+func TestCursorTxnPushed(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	s, db, _ := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop(ctx)
+	sqlDB := sqlutils.MakeSQLRunner(db)
+	sqlDB.Exec(t, `CREATE TABLE t (k INT PRIMARY KEY, v INT)`)
+	sqlDB.Exec(t, `CREATE TABLE u (k INT PRIMARY KEY, v INT)`)
+	sqlDB.Exec(t, `INSERT INTO t VALUES (1, 1), (2, 2)`)
+	sqlDB.Exec(t, `INSERT INTO u VALUES (1, 1)`)
+
+	// pushAfterFetch declares a cursor over t and fetches its first row, then
+	// runs concurrent writes and has the transaction write the row of u they
+	// wrote, which pushes it past them. It returns the error of the write of
+	// the transaction and of the next fetch, if the write succeeded.
+	pushAfterFetch := func(concurrent string) (writeErr, fetchErr error) {
+		tx, err := db.Begin()
+		require.NoError(t, err)
+		defer func() { _ = tx.Rollback() }()
+		_, err = tx.Exec(`DECLARE c CURSOR FOR SELECT * FROM t ORDER BY k`)
+		require.NoError(t, err)
+		var k, v int
+		require.NoError(t, tx.QueryRow(`FETCH 1 c`).Scan(&k, &v))
+		require.Equal(t, 1, k)
+
+		sqlDB.Exec(t, concurrent)
+
+		if _, err := tx.Exec(`UPDATE u SET v = v + 10 WHERE k = 1`); err != nil {
+			return err, nil
+		}
+		return nil, tx.QueryRow(`FETCH 1 c`).Scan(&k, &v)
+	}
+
+	// The rows read by the cursor were written after its timestamp, so the
+	// transaction can't be refreshed past the write to u.
+	writeErr, _ := pushAfterFetch(`UPDATE t SET v = v + 1; UPDATE u SET v = v + 1`)
+	require.True(t, testutils.IsError(writeErr, "restart transaction"), "%v", writeErr)
+
+	// Without a conflict, the transaction is refreshed, but the cursor still
+	// reads at the timestamp it was declared at.
+	writeErr, fetchErr := pushAfterFetch(`UPDATE u SET v = v + 1`)
+	require.NoError(t, writeErr)
+	require.True(t, testutils.IsError(fetchErr,
+		`cursor "c" cannot be read after its transaction was pushed`), "%v", fetchErr)
+}
@@ -246,6 +246,20 @@ CREATE TABLE pg_catalog.pg_conversion (
 	condefault BOOL
 )`
 
+// PGCatalogCursors describes the schema of the pg_catalog.pg_cursors table.
+// The statement field differs in that it uses the parsed version of the
+// DECLARE statement.
+// https://www.postgresql.org/docs/13/view-pg-cursors.html,
+const PGCatalogCursors = `
+CREATE TABLE pg_catalog.pg_cursors (
+	name TEXT,
+	statement TEXT,
+	is_holdable BOOL,
+	is_binary BOOL,
+	is_scrollable BOOL,
+	creation_time TIMESTAMPTZ
+)`
+
 // PGCatalogDatabase describes the schema of the pg_catalog.pg_database table.
 // https://www.postgresql.org/docs/9.5/catalog-pg-database.html,
 const PGCatalogDatabase = `
@@ -0,0 +1,132 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+package cloudimpltests
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/storage/cloud"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/stretchr/testify/require"
+)
+
+func writeFileKMSKey(t *testing.T, dir, name string, key []byte) string {
+	path := filepath.Join(dir, name)
+	require.NoError(t, ioutil.WriteFile(path, key, 0600))
+	return fmt.Sprintf("file://%s", path)
+}
+
+func TestEncryptDecryptFileKMS(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	ctx := context.Background()
+	dir, cleanup := testutils.TempDir(t)
+	defer cleanup()
+	env := testKMSEnv{cluster.NoSettings, &base.ExternalIODirConfig{}}
+
+	for _, keyLen := range []int{16, 24, 32} {
+		t.Run(fmt.Sprintf("aes-%d", keyLen*8), func(t *testing.T) {
+			key := make([]byte, keyLen)
+			for i := range key {
+				key[i] = byte(i)
+			}
+			uri := writeFileKMSKey(t, dir, fmt.Sprintf("key-%d", keyLen), key)
+			testEncryptDecrypt(t, uri, env)
+		})
+	}
+
+	keyA := []byte("0123456789abcdef0123456789abcdef")
+	keyB := []byte("fedcba9876543210fedcba9876543210")
+	uriA := writeFileKMSKey(t, dir, "a", keyA)
+	uriACopy := writeFileKMSKey(t, dir, "a-copy", keyA)
+	uriB := writeFileKMSKey(t, dir, "b", keyB)
+
+	kmsA, err := cloud.KMSFromURI(uriA, &env)
+	require.NoError(t, err)
+	kmsACopy, err := cloud.KMSFromURI(uriACopy, &env)
+	require.NoError(t, err)
+	kmsB, err := cloud.KMSFromURI(uriB, &env)
+	require.NoError(t, err)
+
+	t.Run("master-key-id", func(t *testing.T) {
+		idA, err := kmsA.MasterKeyID()
+		require.NoError(t, err)
+		idACopy, err := kmsACopy.MasterKeyID()
+		require.NoError(t, err)
+		idB, err := kmsB.MasterKeyID()
+		require.NoError(t, err)
+		// The ID identifies the key itself, not the path it was read from.
+		require.Equal(t, idA, idACopy)
+		require.NotEqual(t, idA, idB)
+	})
+
+	t.Run("wrong-key", func(t *testing.T) {
+		ciphertext, err := kmsA.Encrypt(ctx, []byte("hello world"))
+		require.NoError(t, err)
+		_, err = kmsB.Decrypt(ctx, ciphertext)
+		require.True(t, testutils.IsError(err, "maybe incorrect key"), "%v", err)
+		_, err = kmsA.Decrypt(ctx, ciphertext[:4])
+		require.True(t, testutils.IsError(err, "ciphertext is too short"), "%v", err)
+	})
+}
+
+func TestFileKMSInvalid(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	dir, cleanup := testutils.TempDir(t)
+	defer cleanup()
+	env := testKMSEnv{cluster.NoSettings, &base.ExternalIODirConfig{}}
+	validURI := writeFileKMSKey(t, dir, "valid", make([]byte, 32))
+
+	for _, tc := range []struct {
+		name string
+		uri  string
+		env  testKMSEnv
+		err  string
+	}{
+		{
+			name: "bad-length",
+			uri:  writeFileKMSKey(t, dir, "short", []byte("tooshort")),
+			env:  env,
+			err:  "must be 16, 24 or 32 bytes long, found 8 bytes",
+		},
+		{
+			name: "missing",
+			uri:  fmt.Sprintf("file://%s", filepath.Join(dir, "missing")),
+			env:  env,
+			err:  "reading file kms key",
+		},
+		{
+			name: "host",
+			uri:  "file://somehost/key",
+			env:  env,
+			err:  "must not specify a host",
+		},
+		{
+			name: "disallow-implicit",
+			uri:  validURI,
+			env: testKMSEnv{cluster.NoSettings,
+				&base.ExternalIODirConfig{DisableImplicitCredentials: true}},
+			err: "file kms disallowed",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := cloud.KMSFromURI(tc.uri, &tc.env)
+			require.True(t, testutils.IsError(err, tc.err), "%v", err)
+		})
+	}
+}
@@ -0,0 +1,126 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package cloudimpl
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"net/url"
+	"path/filepath"
+
+	"github.com/cockroachdb/cockroach/pkg/storage/cloud"
+	"github.com/cockroachdb/errors"
+)
+
+const fileKMSScheme = "file"
+
+// fileKMS is a KMS backed by an AES key stored in a local file, e.g.
+// file:///path/to/keyfile. It is intended for on-prem deployments and tests
+// that do not have access to a cloud KMS. The key file must be present at the
+// same path on every node, since the job using it may be resumed on any node.
+type fileKMS struct {
+	aead cipher.AEAD
+	// keyID is the hex encoded SHA-256 fingerprint of the key. Using the
+	// fingerprint rather than the path means that the same key is recognized
+	// regardless of where it is mounted, and that a different key placed at the
+	// same path is not.
+	keyID string
+}
+
+var _ cloud.KMS = &fileKMS{}
+
+func init() {
+	cloud.RegisterKMSFromURIFactory(MakeFileKMS, fileKMSScheme)
+}
+
+// MakeFileKMS is the factory method which returns a configured, ready-to-use
+// KMS object backed by a local key file. The file must contain a raw 16, 24 or
+// 32 byte key, selecting AES-128, AES-192 or AES-256 respectively.
+func MakeFileKMS(uri string, env cloud.KMSEnv) (cloud.KMS, error) {
+	kmsURI, err := url.ParseRequestURI(uri)
+	if err != nil {
+		return nil, err
+	}
+	if env.KMSConfig().DisableImplicitCredentials {
+		return nil, errors.New(
+			"file kms disallowed due to --external-io-disable-implicit-credentials flag")
+	}
+	if kmsURI.Host != "" {
+		return nil, errors.Errorf(
+			"file kms URI must not specify a host, found %q: use file:///path/to/keyfile", kmsURI.Host)
+	}
+	if !filepath.IsAbs(kmsURI.Path) {
+		return nil, errors.Errorf("file kms URI must specify an absolute path, found %q", kmsURI.Path)
+	}
+
+	key, err := ioutil.ReadFile(kmsURI.Path)
+	if err != nil {
+		return nil, errors.Wrap(err, "reading file kms key")
+	}
+	switch len(key) {
+	case 16, 24, 32:
+	default:
+		return nil, errors.Errorf(
+			"file kms key %s must be 16, 24 or 32 bytes long, found %d bytes", kmsURI.Path, len(key))
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	fingerprint := sha256.Sum256(key)
+	return &fileKMS{
+		aead:  aead,
+		keyID: hex.EncodeToString(fingerprint[:]),
+	}, nil
+}
+
+// MasterKeyID implements the KMS interface.
+func (k *fileKMS) MasterKeyID() (string, error) {
+	return k.keyID, nil
+}
+
+// Encrypt implements the KMS interface. The returned ciphertext is the random
+// nonce followed by the sealed data.
+func (k *fileKMS) Encrypt(ctx context.Context, data []byte) ([]byte, error) {
+	nonce := make([]byte, k.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, errors.Wrap(err, "generating nonce")
+	}
+	return k.aead.Seal(nonce, nonce, data, nil /* additionalData */), nil
+}
+
+// Decrypt implements the KMS interface.
+func (k *fileKMS) Decrypt(ctx context.Context, data []byte) ([]byte, error) {
+	nonceSize := k.aead.NonceSize()
+	if len(data) < nonceSize {
+		return nil, errors.New("file kms ciphertext is too short")
+	}
+	plaintext, err := k.aead.Open(nil, data[:nonceSize], data[nonceSize:], nil /* additionalData */)
+	if err != nil {
+		return nil, errors.Wrap(err, "file kms failed to decrypt; maybe incorrect key")
+	}
+	return plaintext, nil
+}
+
+// Close implements the KMS interface.
+func (k *fileKMS) Close() error {
+	return nil
+}
@@ -0,0 +1,133 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package cloudimpl
+
+import (
+	"context"
+	"encoding/base64"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/cockroachdb/cockroach/pkg/storage/cloud"
+	"github.com/cockroachdb/errors"
+	"golang.org/x/oauth2/google"
+	kms "google.golang.org/api/cloudkms/v1"
+)
+
+const gcpKMSScheme = "gs"
+
+type gcpKMS struct {
+	kms *kms.ProjectsLocationsKeyRingsCryptoKeysService
+	// customerMasterKeyID is the resource name of the key, i.e.
+	// projects/<project>/locations/<location>/keyRings/<ring>/cryptoKeys/<key>.
+	customerMasterKeyID string
+}
+
+var _ cloud.KMS = &gcpKMS{}
+
+func init() {
+	cloud.RegisterKMSFromURIFactory(MakeGCPKMS, gcpKMSScheme)
+}
+
+// MakeGCPKMS is the factory method which returns a configured, ready-to-use
+// GCP KMS object.
+func MakeGCPKMS(uri string, env cloud.KMSEnv) (cloud.KMS, error) {
+	kmsURI, err := url.ParseRequestURI(uri)
+	if err != nil {
+		return nil, err
+	}
+
+	auth := kmsURI.Query().Get(AuthParam)
+	credentials := kmsURI.Query().Get(CredentialsParam)
+
+	// "specified": the JSON object for authentication is given by the CREDENTIALS param.
+	// "implicit": only use the environment data.
+	// "": default to `specified`.
+	ctx := context.Background()
+	var client *http.Client
+	switch auth {
+	case "", AuthParamSpecified:
+		if credentials == "" {
+			return nil, errors.Errorf(
+				"%s is set to '%s', but %s is not set",
+				AuthParam,
+				AuthParamSpecified,
+				CredentialsParam,
+			)
+		}
+		decodedKey, err := base64.StdEncoding.DecodeString(credentials)
+		if err != nil {
+			return nil, errors.Wrapf(err, "decoding value of %s", CredentialsParam)
+		}
+		source, err := google.JWTConfigFromJSON(decodedKey, kms.CloudPlatformScope)
+		if err != nil {
+			return nil, errors.Wrap(err, "creating GCP KMS oauth token source from specified credentials")
+		}
+		client = source.Client(ctx)
+	case AuthParamImplicit:
+		if env.KMSConfig().DisableImplicitCredentials {
+			return nil, errors.New(
+				"implicit credentials disallowed for gs kms due to --external-io-disable-implicit-credentials flag")
+		}
+		// Use implicit params:
+		// https://godoc.org/golang.org/x/oauth2/google#FindDefaultCredentials
+		client, err = google.DefaultClient(ctx, kms.CloudPlatformScope)
+		if err != nil {
+			return nil, errors.Wrap(err, "creating GCP KMS client from implicit credentials")
+		}
+	default:
+		return nil, errors.Errorf("unsupported value %s for %s", auth, AuthParam)
+	}
+
+	svc, err := kms.New(client)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create google cloud kms client")
+	}
+	return &gcpKMS{
+		kms:                 kms.NewProjectsLocationsKeyRingsCryptoKeysService(svc),
+		customerMasterKeyID: strings.TrimPrefix(kmsURI.Path, "/"),
+	}, nil
+}
+
+// MasterKeyID implements the KMS interface.
+func (k *gcpKMS) MasterKeyID() (string, error) {
+	return k.customerMasterKeyID, nil
+}
+
+// Encrypt implements the KMS interface.
+func (k *gcpKMS) Encrypt(ctx context.Context, data []byte) ([]byte, error) {
+	encryptOutput, err := k.kms.Encrypt(k.customerMasterKeyID, &kms.EncryptRequest{
+		Plaintext: base64.StdEncoding.EncodeToString(data),
+	}).Context(ctx).Do()
+	if err != nil {
+		return nil, err
+	}
+
+	return base64.StdEncoding.DecodeString(encryptOutput.Ciphertext)
+}
+
+// Decrypt implements the KMS interface.
+func (k *gcpKMS) Decrypt(ctx context.Context, data []byte) ([]byte, error) {
+	decryptOutput, err := k.kms.Decrypt(k.customerMasterKeyID, &kms.DecryptRequest{
+		Ciphertext: base64.StdEncoding.EncodeToString(data),
+	}).Context(ctx).Do()
+	if err != nil {
+		return nil, err
+	}
+
+	return base64.StdEncoding.DecodeString(decryptOutput.Plaintext)
+}
+
+// Close implements the KMS interface.
+func (k *gcpKMS) Close() error {
+	return nil
+}
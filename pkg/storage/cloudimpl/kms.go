@@ -28,3 +28,35 @@ func RedactKMSURI(kmsURI string) (string, error) {
 	uri.Path = "/redacted"
 	return uri.String(), nil
 }
+
+// KMSAccessIsWithExplicitAuth checks if the provided KMS URI has explicit
+// authentication, in the same spirit as AccessIsWithExplicitAuth does for
+// ExternalStorage URIs.
+//
+// - file: the key is read from the node's filesystem and so only a super user
+// should be able to use it.
+//
+// - aws: the credentials are specified in the URI unless AUTH=implicit, in
+// which case they come from the node's environment. As for s3, a custom
+// endpoint is not considered explicit auth.
+//
+// - gs: explicit auth requires the credentials to be specified in the URI.
+func KMSAccessIsWithExplicitAuth(kmsURI string) (bool, string, error) {
+	uri, err := url.Parse(kmsURI)
+	if err != nil {
+		return false, "", err
+	}
+	hasExplicitAuth := true
+	switch uri.Scheme {
+	case fileKMSScheme:
+		hasExplicitAuth = false
+	case awsScheme:
+		auth := uri.Query().Get(AuthParam)
+		hasExplicitAuth = auth == "" || auth == AuthParamSpecified
+		hasExplicitAuth = hasExplicitAuth && uri.Query().Get(AWSEndpointParam) == ""
+	case gcpKMSScheme:
+		auth := uri.Query().Get(AuthParam)
+		hasExplicitAuth = auth == "" || auth == AuthParamSpecified
+	}
+	return hasExplicitAuth, uri.Scheme, nil
+}
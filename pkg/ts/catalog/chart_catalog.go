@@ -1043,6 +1043,13 @@ var charts = []sectionDescription{
 					"changefeed.max_behind_nanos",
 				},
 			},
+			{
+				Title: "Memory Usage",
+				Metrics: []string{
+					"changefeed.memory.current_bytes",
+					"changefeed.memory.max_feed_bytes",
+				},
+			},
 			{
 				Title: "Min High Water",
 				Metrics: []string{
@@ -1064,6 +1071,7 @@ var charts = []sectionDescription{
 			{
 				Title: "Total Time Spent",
 				Metrics: []string{
+					"changefeed.buffer_pushback_nanos",
 					"changefeed.emit_nanos",
 					"changefeed.flush_nanos",
 					"changefeed.processing_nanos",
@@ -422,6 +422,31 @@ x,,,
 	}
 }
 
+func TestInputOffset(t *testing.T) {
+	// Each record is followed by the offset at which the next one starts,
+	// including records which span lines and the comments and blank lines
+	// which precede them.
+	input := "a,b\r\n# comment\n\n\"c\nd\",e\nf,g"
+	expected := []int64{5, 24, 27}
+
+	r := NewReader(strings.NewReader(input))
+	r.Comment = '#'
+	var offsets []int64
+	for {
+		_, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		offsets = append(offsets, r.InputOffset())
+	}
+	if !reflect.DeepEqual(offsets, expected) {
+		t.Errorf("InputOffset() after each record:\ngot  %v\nwant %v", offsets, expected)
+	}
+}
+
 // nTimes is an io.Reader which yields the string s n times.
 type nTimes struct {
 	s   string
@@ -0,0 +1,392 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package parquet
+
+import (
+	"encoding/binary"
+	"math"
+	"math/big"
+	"strconv"
+	"time"
+
+	"github.com/cockroachdb/apd/v2"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util/duration"
+	"github.com/cockroachdb/cockroach/pkg/util/timeofday"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil/pgdate"
+	"github.com/cockroachdb/cockroach/pkg/util/uuid"
+	"github.com/cockroachdb/errors"
+	goparquet "github.com/fraugster/parquet-go"
+	"github.com/fraugster/parquet-go/parquet"
+	"github.com/fraugster/parquet-go/parquetschema"
+)
+
+// decodeFn converts a non-NULL value returned by the parquet library for the
+// column it was created for into a datum.
+type decodeFn func(v interface{}, ctx tree.ParseTimeContext) (tree.Datum, error)
+
+// Decoder converts the values of a top-level column of a parquet file into
+// datums of a SQL type. It is safe for concurrent use.
+type Decoder struct {
+	name   string
+	decode decodeFn
+}
+
+// NewDecoder returns a Decoder for the named top-level column of the file,
+// which produces datums of type typ.
+//
+// Each value is first decoded according to the column's logical type, which
+// maps to CockroachDB types much like the reverse of NewSchema; the
+// deprecated converted types, unsigned integers, and the INT96 timestamps
+// written by Spark and Impala are also understood. If the result does not
+// have type typ, timestamps are converted directly and anything else is
+// converted through its text representation, so that for example an INT32
+// column can be imported into a DECIMAL column and a STRING column into a UUID
+// column. LIST columns can only be imported into array columns, and other
+// groups are not supported.
+func (r *Reader) NewDecoder(name string, typ *types.T) (*Decoder, error) {
+	def := r.column(name)
+	if def == nil {
+		return nil, errors.Errorf("no column %q in parquet file", name)
+	}
+	decode, err := newDecodeFn(def, typ)
+	if err != nil {
+		return nil, err
+	}
+	return &Decoder{name: name, decode: decode}, nil
+}
+
+// Decode returns the datum for the Decoder's column of row, which is DNull if
+// the column is NULL.
+func (d *Decoder) Decode(row Row, ctx tree.ParseTimeContext) (tree.Datum, error) {
+	v, ok := row[d.name]
+	if !ok || v == nil {
+		return tree.DNull, nil
+	}
+	datum, err := d.decode(v, ctx)
+	if err != nil {
+		return nil, errors.Wrapf(err, "decoding column %q", d.name)
+	}
+	return datum, nil
+}
+
+func newDecodeFn(def *parquetschema.ColumnDefinition, typ *types.T) (decodeFn, error) {
+	elem := def.SchemaElement
+	if elem.Type == nil {
+		return newListDecodeFn(def, typ)
+	}
+	natural, err := naturalDecodeFn(elem)
+	if err != nil {
+		return nil, err
+	}
+	return func(v interface{}, ctx tree.ParseTimeContext) (tree.Datum, error) {
+		d, err := natural(v)
+		if err != nil {
+			return nil, err
+		}
+		return convertDatum(d, typ, ctx)
+	}, nil
+}
+
+// newListDecodeFn decodes a LIST group, which has the three-level structure
+//
+//   optional group <name> (LIST) {
+//     repeated group list {
+//       optional <element-type> element;
+//     }
+//   }
+//
+// which the parquet library returns as a map containing a slice of maps.
+func newListDecodeFn(def *parquetschema.ColumnDefinition, typ *types.T) (decodeFn, error) {
+	elem := def.SchemaElement
+	isList := (elem.LogicalType != nil && elem.LogicalType.LIST != nil) ||
+		(elem.ConvertedType != nil && *elem.ConvertedType == parquet.ConvertedType_LIST)
+	if !isList {
+		return nil, errors.Errorf("parquet group column %q is not supported", elem.Name)
+	}
+	if len(def.Children) != 1 || len(def.Children[0].Children) != 1 {
+		return nil, errors.Errorf("parquet LIST column %q has an unsupported layout", elem.Name)
+	}
+	if typ.Family() != types.ArrayFamily {
+		return nil, errors.Errorf("cannot convert parquet LIST column %q to %s", elem.Name, typ.SQLString())
+	}
+	listName := def.Children[0].SchemaElement.Name
+	elemDef := def.Children[0].Children[0]
+	elemName := elemDef.SchemaElement.Name
+	decodeElem, err := newDecodeFn(elemDef, typ.ArrayContents())
+	if err != nil {
+		return nil, err
+	}
+	return func(v interface{}, ctx tree.ParseTimeContext) (tree.Datum, error) {
+		group, ok := v.(map[string]interface{})
+		if !ok {
+			return nil, errors.AssertionFailedf("unexpected list value %T", v)
+		}
+		arr := tree.NewDArray(typ.ArrayContents())
+		elems, _ := group[listName].([]map[string]interface{})
+		for _, e := range elems {
+			d := tree.Datum(tree.DNull)
+			if ev, ok := e[elemName]; ok && ev != nil {
+				var err error
+				if d, err = decodeElem(ev, ctx); err != nil {
+					return nil, err
+				}
+			}
+			if err := arr.Append(d); err != nil {
+				return nil, err
+			}
+		}
+		return arr, nil
+	}, nil
+}
+
+// naturalDecodeFn returns a function which decodes the values of a primitive
+// column into datums of the type corresponding to its logical type.
+func naturalDecodeFn(elem *parquet.SchemaElement) (func(interface{}) (tree.Datum, error), error) {
+	logical := elem.LogicalType
+	if logical == nil {
+		logical = parquet.NewLogicalType()
+	}
+	converted := parquet.ConvertedType(-1)
+	if elem.ConvertedType != nil {
+		converted = *elem.ConvertedType
+	}
+	isDecimal := logical.DECIMAL != nil || converted == parquet.ConvertedType_DECIMAL
+	var scale int32
+	if logical.DECIMAL != nil {
+		scale = logical.DECIMAL.Scale
+	} else if elem.Scale != nil {
+		scale = *elem.Scale
+	}
+	// The parquet library returns the values of unsigned columns as uint32 or
+	// uint64.
+	isUnsigned := (logical.INTEGER != nil && !logical.INTEGER.IsSigned) ||
+		converted == parquet.ConvertedType_UINT_8 || converted == parquet.ConvertedType_UINT_16 ||
+		converted == parquet.ConvertedType_UINT_32 || converted == parquet.ConvertedType_UINT_64
+
+	switch *elem.Type {
+	case parquet.Type_BOOLEAN:
+		return func(v interface{}) (tree.Datum, error) {
+			return tree.MakeDBool(tree.DBool(v.(bool))), nil
+		}, nil
+
+	case parquet.Type_INT32:
+		switch {
+		case logical.DATE != nil || converted == parquet.ConvertedType_DATE:
+			return func(v interface{}) (tree.Datum, error) {
+				date, err := pgdate.MakeDateFromUnixEpoch(int64(v.(int32)))
+				if err != nil {
+					return nil, err
+				}
+				return tree.NewDDate(date), nil
+			}, nil
+		case isDecimal:
+			return func(v interface{}) (tree.Datum, error) {
+				return &tree.DDecimal{Decimal: *apd.New(int64(v.(int32)), -scale)}, nil
+			}, nil
+		case logical.TIME != nil || converted == parquet.ConvertedType_TIME_MILLIS:
+			return func(v interface{}) (tree.Datum, error) {
+				return tree.MakeDTime(timeofday.FromInt(int64(v.(int32)) * 1000)), nil
+			}, nil
+		case isUnsigned:
+			return func(v interface{}) (tree.Datum, error) {
+				return tree.NewDInt(tree.DInt(v.(uint32))), nil
+			}, nil
+		default:
+			return func(v interface{}) (tree.Datum, error) {
+				return tree.NewDInt(tree.DInt(v.(int32))), nil
+			}, nil
+		}
+
+	case parquet.Type_INT64:
+		switch {
+		case logical.TIMESTAMP != nil || converted == parquet.ConvertedType_TIMESTAMP_MILLIS ||
+			converted == parquet.ConvertedType_TIMESTAMP_MICROS:
+			unit := time.Microsecond
+			adjusted := true
+			if ts := logical.TIMESTAMP; ts != nil {
+				unit = timeUnit(ts.Unit)
+				adjusted = ts.IsAdjustedToUTC
+			} else if converted == parquet.ConvertedType_TIMESTAMP_MILLIS {
+				unit = time.Millisecond
+			}
+			return func(v interface{}) (tree.Datum, error) {
+				t := timeutil.Unix(0, v.(int64)*int64(unit))
+				if adjusted {
+					return tree.MakeDTimestampTZ(t, time.Microsecond)
+				}
+				return tree.MakeDTimestamp(t, time.Microsecond)
+			}, nil
+		case logical.TIME != nil || converted == parquet.ConvertedType_TIME_MICROS:
+			unit := time.Microsecond
+			if logical.TIME != nil {
+				unit = timeUnit(logical.TIME.Unit)
+			}
+			return func(v interface{}) (tree.Datum, error) {
+				micros := v.(int64) * int64(unit) / int64(time.Microsecond)
+				return tree.MakeDTime(timeofday.FromInt(micros)), nil
+			}, nil
+		case isDecimal:
+			return func(v interface{}) (tree.Datum, error) {
+				return &tree.DDecimal{Decimal: *apd.New(v.(int64), -scale)}, nil
+			}, nil
+		case isUnsigned:
+			return func(v interface{}) (tree.Datum, error) {
+				u := v.(uint64)
+				if u > math.MaxInt64 {
+					return nil, errors.Errorf("unsigned value %d out of range for INT8", u)
+				}
+				return tree.NewDInt(tree.DInt(u)), nil
+			}, nil
+		default:
+			return func(v interface{}) (tree.Datum, error) {
+				return tree.NewDInt(tree.DInt(v.(int64))), nil
+			}, nil
+		}
+
+	case parquet.Type_INT96:
+		// INT96 is a deprecated timestamp representation which is still the
+		// default of Spark.
+		return func(v interface{}) (tree.Datum, error) {
+			return tree.MakeDTimestampTZ(goparquet.Int96ToTime(v.([12]byte)), time.Microsecond)
+		}, nil
+
+	case parquet.Type_FLOAT:
+		return func(v interface{}) (tree.Datum, error) {
+			// Widen through the shortest decimal representation, so that e.g.
+			// 1.1 doesn't become 1.100000023841858.
+			f, err := strconv.ParseFloat(strconv.FormatFloat(float64(v.(float32)), 'g', -1, 32), 64)
+			if err != nil {
+				return nil, err
+			}
+			return tree.NewDFloat(tree.DFloat(f)), nil
+		}, nil
+
+	case parquet.Type_DOUBLE:
+		return func(v interface{}) (tree.Datum, error) {
+			return tree.NewDFloat(tree.DFloat(v.(float64))), nil
+		}, nil
+
+	case parquet.Type_BYTE_ARRAY, parquet.Type_FIXED_LEN_BYTE_ARRAY:
+		switch {
+		case isDecimal:
+			return func(v interface{}) (tree.Datum, error) {
+				return &tree.DDecimal{Decimal: decodeDecimal(v.([]byte), scale)}, nil
+			}, nil
+		case logical.UUID != nil:
+			return func(v interface{}) (tree.Datum, error) {
+				u, err := uuid.FromBytes(v.([]byte))
+				if err != nil {
+					return nil, err
+				}
+				return tree.NewDUuid(tree.DUuid{UUID: u}), nil
+			}, nil
+		case converted == parquet.ConvertedType_INTERVAL:
+			return func(v interface{}) (tree.Datum, error) {
+				b := v.([]byte)
+				if len(b) != 12 {
+					return nil, errors.Errorf("invalid INTERVAL length %d", len(b))
+				}
+				months := int64(binary.LittleEndian.Uint32(b[0:]))
+				days := int64(binary.LittleEndian.Uint32(b[4:]))
+				millis := int64(binary.LittleEndian.Uint32(b[8:]))
+				return &tree.DInterval{
+					Duration: duration.MakeDuration(millis*int64(time.Millisecond), days, months),
+				}, nil
+			}, nil
+		case logical.STRING != nil || logical.ENUM != nil || logical.JSON != nil ||
+			converted == parquet.ConvertedType_UTF8 || converted == parquet.ConvertedType_ENUM ||
+			converted == parquet.ConvertedType_JSON:
+			return func(v interface{}) (tree.Datum, error) {
+				return tree.NewDString(string(v.([]byte))), nil
+			}, nil
+		default:
+			return func(v interface{}) (tree.Datum, error) {
+				return tree.NewDBytes(tree.DBytes(v.([]byte))), nil
+			}, nil
+		}
+	}
+	return nil, errors.Errorf("parquet column %q has unsupported type %s", elem.Name, elem.Type)
+}
+
+// convertDatum converts a decoded datum to type typ.
+func convertDatum(d tree.Datum, typ *types.T, ctx tree.ParseTimeContext) (tree.Datum, error) {
+	if d.ResolvedType().Equivalent(typ) {
+		return d, nil
+	}
+	var s string
+	switch t := d.(type) {
+	case *tree.DTimestamp, *tree.DTimestampTZ:
+		var ts time.Time
+		if dts, ok := t.(*tree.DTimestamp); ok {
+			ts = dts.Time
+		} else {
+			ts = t.(*tree.DTimestampTZ).Time
+		}
+		switch typ.Family() {
+		case types.TimestampFamily:
+			return tree.MakeDTimestamp(ts, time.Microsecond)
+		case types.TimestampTZFamily:
+			return tree.MakeDTimestampTZ(ts, time.Microsecond)
+		case types.DateFamily:
+			return tree.NewDDateFromTime(ts)
+		}
+		s = tree.AsStringWithFlags(d, tree.FmtBareStrings)
+	case *tree.DString:
+		s = string(*t)
+	case *tree.DBytes:
+		// Binary columns without a logical type are often used for text, so
+		// they are treated as such when imported into anything but BYTES.
+		s = string(*t)
+	default:
+		s = tree.AsStringWithFlags(d, tree.FmtBareStrings)
+	}
+	switch typ.Family() {
+	case types.StringFamily:
+		return tree.NewDString(s), nil
+	case types.CollatedStringFamily:
+		return tree.NewDCollatedString(s, typ.Locale(), &tree.CollationEnvironment{})
+	}
+	res, _, err := tree.ParseAndRequireString(typ, s, ctx)
+	if err != nil {
+		return nil, errors.Wrapf(err, "converting %s to %s", d.ResolvedType(), typ)
+	}
+	return res, nil
+}
+
+func timeUnit(u *parquet.TimeUnit) time.Duration {
+	switch {
+	case u.MILLIS != nil:
+		return time.Millisecond
+	case u.NANOS != nil:
+		return time.Nanosecond
+	default:
+		return time.Microsecond
+	}
+}
+
+// decodeDecimal is the inverse of encodeDecimal: it interprets b as a
+// big-endian two's complement integer, which is the unscaled value of the
+// decimal.
+func decodeDecimal(b []byte, scale int32) apd.Decimal {
+	var d apd.Decimal
+	d.Coeff.SetBytes(b)
+	if len(b) > 0 && b[0]&0x80 != 0 {
+		// Subtract 2^(8n) to get the negative value, then take its magnitude.
+		twos := new(big.Int).Lsh(big.NewInt(1), uint(8*len(b)))
+		d.Coeff.Sub(twos, &d.Coeff)
+		d.Negative = true
+	}
+	d.Exponent = -scale
+	return d
+}
@@ -0,0 +1,233 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package parquet
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+
+	"github.com/apache/thrift/lib/go/thrift"
+	"github.com/cockroachdb/errors"
+	goparquet "github.com/fraugster/parquet-go"
+	"github.com/fraugster/parquet-go/parquet"
+	"github.com/fraugster/parquet-go/parquetschema"
+)
+
+var magic = []byte("PAR1")
+
+// Row is a single row read from a parquet file, as returned by the parquet
+// library: a map from column name to value, from which NULL columns are
+// absent.
+type Row = map[string]interface{}
+
+// Reader reads the rows of a parquet file which is held in memory. Each row
+// group of the file can be read independently of the others, so that row
+// groups can be read concurrently.
+type Reader struct {
+	data []byte
+	// footerStart is the offset of the thrift-encoded file metadata in data.
+	footerStart int64
+	meta        *parquet.FileMetaData
+	schema      *parquetschema.SchemaDefinition
+}
+
+// NewReader returns a Reader for the parquet file contained in data.
+func NewReader(data []byte) (*Reader, error) {
+	if len(data) < 2*len(magic)+4 ||
+		!bytes.Equal(data[:len(magic)], magic) || !bytes.Equal(data[len(data)-len(magic):], magic) {
+		return nil, errors.New("not a parquet file")
+	}
+	footerLen := int64(binary.LittleEndian.Uint32(data[len(data)-len(magic)-4:]))
+	footerStart := int64(len(data)-len(magic)-4) - footerLen
+	if footerLen <= 0 || footerStart < int64(len(magic)) {
+		return nil, errors.Errorf("invalid parquet footer length %d", footerLen)
+	}
+	meta := parquet.NewFileMetaData()
+	proto := thrift.NewTCompactProtocol(&thrift.StreamTransport{
+		Reader: bytes.NewReader(data[footerStart : footerStart+footerLen]),
+	})
+	if err := meta.Read(proto); err != nil {
+		return nil, errors.Wrap(err, "reading parquet file metadata")
+	}
+
+	fr, err := goparquet.NewFileReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, errors.Wrap(err, "reading parquet file")
+	}
+	return &Reader{
+		data:        data,
+		footerStart: footerStart,
+		meta:        meta,
+		schema:      fr.GetSchemaDefinition(),
+	}, nil
+}
+
+// NumRows returns the number of rows in the file.
+func (r *Reader) NumRows() int64 {
+	return r.meta.NumRows
+}
+
+// NumRowGroups returns the number of row groups in the file.
+func (r *Reader) NumRowGroups() int {
+	return len(r.meta.RowGroups)
+}
+
+// RowGroupNumRows returns the number of rows in the i'th row group.
+func (r *Reader) RowGroupNumRows(i int) int64 {
+	return r.meta.RowGroups[i].NumRows
+}
+
+// ColumnNames returns the names of the top-level columns of the file.
+func (r *Reader) ColumnNames() []string {
+	children := r.schema.RootColumn.Children
+	names := make([]string, len(children))
+	for i, c := range children {
+		names[i] = c.SchemaElement.Name
+	}
+	return names
+}
+
+func (r *Reader) column(name string) *parquetschema.ColumnDefinition {
+	for _, c := range r.schema.RootColumn.Children {
+		if c.SchemaElement.Name == name {
+			return c
+		}
+	}
+	return nil
+}
+
+// ReadRowGroup reads all rows of the i'th row group. Only the named top-level
+// columns are decoded, or all of them if none are given. It is safe to call
+// ReadRowGroup concurrently.
+func (r *Reader) ReadRowGroup(i int, columns ...string) ([]Row, error) {
+	if i < 0 || i >= len(r.meta.RowGroups) {
+		return nil, errors.AssertionFailedf("row group %d out of range", i)
+	}
+	// The parquet library can only read row groups in order. Rather than
+	// decoding all the ones before i, give it a view of the file whose footer
+	// only lists the i'th row group. Column chunks are addressed by their
+	// offset in the file, which is unchanged.
+	meta := *r.meta
+	meta.RowGroups = []*parquet.RowGroup{r.meta.RowGroups[i]}
+	meta.NumRows = meta.RowGroups[0].NumRows
+	var footer bytes.Buffer
+	if err := meta.Write(thrift.NewTCompactProtocol(&thrift.StreamTransport{Writer: &footer})); err != nil {
+		return nil, errors.Wrap(err, "writing parquet file metadata")
+	}
+	var footerLen [4]byte
+	binary.LittleEndian.PutUint32(footerLen[:], uint32(footer.Len()))
+	footer.Write(footerLen[:])
+	footer.Write(magic)
+
+	src := &splicedReaderAt{head: r.data[:r.footerStart], tail: footer.Bytes()}
+	fr, err := goparquet.NewFileReader(io.NewSectionReader(src, 0, src.size()), columns...)
+	if err != nil {
+		return nil, errors.Wrap(err, "reading parquet file")
+	}
+	rows := make([]Row, 0, meta.NumRows)
+	for {
+		row, err := fr.NextRow()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "reading row group %d", i)
+		}
+		rows = append(rows, row)
+	}
+	if err := r.checkLists(meta.RowGroups[0], rows, columns); err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// checkLists verifies that the LIST columns of rows were read correctly. The
+// parquet library ends a list early when it encounters a NULL element other
+// than the first one, and then reads the rest of the list as the following
+// row. Since that can't be undone, detect it by comparing the number of values
+// which the rows account for in the element column with the number of values
+// in the column chunk: every list element, NULL, or empty list is a value.
+func (r *Reader) checkLists(rg *parquet.RowGroup, rows []Row, columns []string) error {
+	for _, col := range r.schema.RootColumn.Children {
+		name := col.SchemaElement.Name
+		if len(columns) > 0 && !containsString(columns, name) {
+			continue
+		}
+		if len(col.Children) != 1 || len(col.Children[0].Children) != 1 ||
+			col.Children[0].Children[0].SchemaElement.Type == nil {
+			continue
+		}
+		listName := col.Children[0].SchemaElement.Name
+		elemName := col.Children[0].Children[0].SchemaElement.Name
+		var chunk *parquet.ColumnChunk
+		for _, c := range rg.Columns {
+			if p := c.MetaData.PathInSchema; len(p) == 3 &&
+				p[0] == name && p[1] == listName && p[2] == elemName {
+				chunk = c
+			}
+		}
+		if chunk == nil {
+			continue
+		}
+		var numValues int64
+		for _, row := range rows {
+			n := 1
+			if group, ok := row[name].(map[string]interface{}); ok {
+				if elems, ok := group[listName].([]map[string]interface{}); ok && len(elems) > 0 {
+					n = len(elems)
+				}
+			}
+			numValues += int64(n)
+		}
+		if numValues != chunk.MetaData.NumValues {
+			return errors.Errorf(
+				"reading parquet LIST column %q: lists with NULL elements are not supported", name)
+		}
+	}
+	return nil
+}
+
+func containsString(haystack []string, needle string) bool {
+	for _, s := range haystack {
+		if s == needle {
+			return true
+		}
+	}
+	return false
+}
+
+// splicedReaderAt is an io.ReaderAt over the concatenation of two byte
+// slices.
+type splicedReaderAt struct {
+	head, tail []byte
+}
+
+func (s *splicedReaderAt) size() int64 {
+	return int64(len(s.head) + len(s.tail))
+}
+
+// ReadAt implements the io.ReaderAt interface.
+func (s *splicedReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	if off >= s.size() {
+		return 0, io.EOF
+	}
+	var n int
+	if off < int64(len(s.head)) {
+		n = copy(p, s.head[off:])
+		off = int64(len(s.head))
+	}
+	n += copy(p[n:], s.tail[off-int64(len(s.head)):])
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
@@ -0,0 +1,341 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package parquet
+
+import (
+	"bytes"
+	"encoding/binary"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/apache/thrift/lib/go/thrift"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	goparquet "github.com/fraugster/parquet-go"
+	"github.com/fraugster/parquet-go/parquet"
+	"github.com/fraugster/parquet-go/parquetschema"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReaderRoundTrip(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	names := []string{
+		"b", "i2", "i8", "f4", "f8", "dec", "dec_any", "s", "bytes", "u",
+		"d", "t", "ts", "tstz", "j", "arr", "ival", "e",
+	}
+	typs := []*types.T{
+		types.Bool, types.Int2, types.Int, types.Float4, types.Float,
+		types.MakeDecimal(10, 2), types.Decimal, types.String, types.Bytes, types.Uuid,
+		types.Date, types.Time, types.Timestamp, types.TimestampTZ, types.Jsonb,
+		types.StringArray, types.Interval, types.MakeCollatedString(types.String, "de"),
+	}
+	sch, err := NewSchema(names, typs)
+	require.NoError(t, err)
+
+	evalCtx := tree.NewTestingEvalContext(nil)
+	parse := func(typ *types.T, s string) tree.Datum {
+		d, _, err := tree.ParseAndRequireString(typ, s, evalCtx)
+		require.NoError(t, err)
+		return d
+	}
+	arr := tree.NewDArray(types.String)
+	require.NoError(t, arr.Append(tree.DNull))
+	require.NoError(t, arr.Append(tree.NewDString("b")))
+	require.NoError(t, arr.Append(tree.NewDString("c")))
+	collated, err := tree.NewDCollatedString("straße", "de", &tree.CollationEnvironment{})
+	require.NoError(t, err)
+
+	row := tree.Datums{
+		tree.DBoolTrue,
+		tree.NewDInt(7),
+		tree.NewDInt(1 << 40),
+		tree.NewDFloat(1.5),
+		tree.NewDFloat(2.25),
+		parse(types.MakeDecimal(10, 2), `-12.50`),
+		parse(types.Decimal, `1.000001`),
+		tree.NewDString(`hello`),
+		tree.NewDBytes("\x00\x01"),
+		parse(types.Uuid, `6b7d2e2c-4d3d-4d8e-9d6f-2ba1bd1c1a51`),
+		parse(types.Date, `1970-01-11`),
+		parse(types.Time, `00:00:01.5`),
+		parse(types.Timestamp, `2021-01-02 03:04:05.678901`),
+		parse(types.TimestampTZ, `2021-01-02 03:04:05.678901+00`),
+		parse(types.Jsonb, `{"a": [1, 2]}`),
+		arr,
+		parse(types.Interval, `1 day`),
+		collated,
+	}
+	nullRow := make(tree.Datums, len(row))
+	for i := range nullRow {
+		nullRow[i] = tree.DNull
+	}
+
+	var buf bytes.Buffer
+	w, err := NewWriter(sch, &buf)
+	require.NoError(t, err)
+	require.NoError(t, w.AddRow(row))
+	require.NoError(t, w.AddRow(nullRow))
+	require.NoError(t, w.Close())
+
+	r, err := NewReader(buf.Bytes())
+	require.NoError(t, err)
+	require.Equal(t, names, r.ColumnNames())
+	require.Equal(t, int64(2), r.NumRows())
+	require.Equal(t, 1, r.NumRowGroups())
+
+	rows, err := r.ReadRowGroup(0)
+	require.NoError(t, err)
+	require.Len(t, rows, 2)
+	for i, name := range names {
+		dec, err := r.NewDecoder(name, typs[i])
+		require.NoError(t, err)
+
+		got, err := dec.Decode(rows[0], evalCtx)
+		require.NoError(t, err)
+		require.Equal(t, 0, row[i].Compare(evalCtx, got), "%s: expected %s, got %s", name, row[i], got)
+
+		got, err = dec.Decode(rows[1], evalCtx)
+		require.NoError(t, err)
+		require.Equal(t, tree.DNull, got)
+	}
+}
+
+func TestReaderRowGroups(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	const numRows = 1000
+	sch, err := NewSchema([]string{"i", "s"}, []*types.T{types.Int, types.String})
+	require.NoError(t, err)
+	var buf bytes.Buffer
+	w, err := NewWriter(sch, &buf, WithMaxRowGroupSize(512))
+	require.NoError(t, err)
+	for i := 0; i < numRows; i++ {
+		require.NoError(t, w.AddRow(tree.Datums{tree.NewDInt(tree.DInt(i)), tree.NewDString("abcdefgh")}))
+	}
+	require.NoError(t, w.Close())
+
+	r, err := NewReader(buf.Bytes())
+	require.NoError(t, err)
+	require.Greater(t, r.NumRowGroups(), 2)
+
+	// Read every row group concurrently, only decoding one of the columns.
+	groups := make([][]Row, r.NumRowGroups())
+	var wg sync.WaitGroup
+	errs := make([]error, len(groups))
+	for i := range groups {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			groups[i], errs[i] = r.ReadRowGroup(i, "i")
+		}(i)
+	}
+	wg.Wait()
+
+	var next int64
+	for i, rows := range groups {
+		require.NoError(t, errs[i])
+		require.Equal(t, r.RowGroupNumRows(i), int64(len(rows)))
+		for _, row := range rows {
+			require.Equal(t, Row{"i": next}, row)
+			next++
+		}
+	}
+	require.Equal(t, int64(numRows), next)
+
+	_, err = r.ReadRowGroup(len(groups))
+	require.Error(t, err)
+	_, err = NewReader([]byte("not a parquet file"))
+	require.EqualError(t, err, "not a parquet file")
+}
+
+func TestReaderListNullElements(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	sch, err := NewSchema([]string{"arr"}, []*types.T{types.IntArray})
+	require.NoError(t, err)
+	makeArray := func(elems ...tree.Datum) tree.Datum {
+		arr := tree.NewDArray(types.Int)
+		for _, e := range elems {
+			require.NoError(t, arr.Append(e))
+		}
+		return arr
+	}
+	write := func(rows ...tree.Datum) *Reader {
+		var buf bytes.Buffer
+		w, err := NewWriter(sch, &buf)
+		require.NoError(t, err)
+		for _, row := range rows {
+			require.NoError(t, w.AddRow(tree.Datums{row}))
+		}
+		require.NoError(t, w.Close())
+		r, err := NewReader(buf.Bytes())
+		require.NoError(t, err)
+		return r
+	}
+
+	// NULL lists, empty lists and lists starting with NULL are read correctly.
+	r := write(
+		makeArray(tree.NewDInt(1), tree.NewDInt(2)),
+		tree.DNull,
+		makeArray(),
+		makeArray(tree.DNull),
+		makeArray(tree.DNull, tree.NewDInt(3)),
+	)
+	rows, err := r.ReadRowGroup(0)
+	require.NoError(t, err)
+	require.Len(t, rows, 5)
+
+	// A NULL anywhere else is misread by the parquet library, which must be
+	// detected rather than silently shifting the rest of the row group.
+	r = write(makeArray(tree.NewDInt(1), tree.DNull), makeArray(tree.NewDInt(2)))
+	_, err = r.ReadRowGroup(0)
+	require.EqualError(t, err,
+		`reading parquet LIST column "arr": lists with NULL elements are not supported`)
+}
+
+func TestDecoderConversions(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	sd, err := parquetschema.ParseSchemaDefinition(`message spark {
+		optional int96 ts96;
+		optional int32 u32;
+		optional int64 ts_millis (TIMESTAMP_MILLIS);
+		optional int64 ts_nanos (TIMESTAMP(NANOS, false));
+		optional int32 dec (DECIMAL(5, 2));
+		optional binary raw;
+		optional binary s (STRING);
+		optional float f;
+		optional group l (LIST) {
+			repeated group list {
+				optional int64 element;
+			}
+		}
+		optional group nested {
+			optional int64 x;
+		}
+	}`)
+	require.NoError(t, err)
+
+	ts := time.Date(2021, 3, 4, 5, 6, 7, 123456000, time.UTC)
+	var buf bytes.Buffer
+	fw := goparquet.NewFileWriter(&buf, goparquet.WithSchemaDefinition(sd))
+	require.NoError(t, fw.AddData(map[string]interface{}{
+		"ts96":      goparquet.TimeToInt96(ts),
+		"u32":       int32(-1),
+		"ts_millis": ts.UnixNano() / int64(time.Millisecond),
+		"ts_nanos":  ts.UnixNano(),
+		"dec":       int32(-123),
+		"raw":       []byte("42"),
+		"s":         []byte("6b7d2e2c-4d3d-4d8e-9d6f-2ba1bd1c1a51"),
+		"f":         float32(1.1),
+		"l": map[string]interface{}{
+			"list": []map[string]interface{}{{"element": int64(1)}, {"element": int64(2)}},
+		},
+		"nested": map[string]interface{}{"x": int64(1)},
+	}))
+	require.NoError(t, fw.Close())
+
+	// The parquet library can't write unsigned integers, so mark the column as
+	// unsigned after the fact.
+	data := rewriteFooter(t, buf.Bytes(), func(meta *parquet.FileMetaData) {
+		for _, elem := range meta.Schema {
+			if elem.Name == "u32" {
+				elem.ConvertedType = parquet.ConvertedTypePtr(parquet.ConvertedType_UINT_32)
+			}
+		}
+	})
+	r, err := NewReader(data)
+	require.NoError(t, err)
+	rows, err := r.ReadRowGroup(0)
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+
+	evalCtx := tree.NewTestingEvalContext(nil)
+	for _, tc := range []struct {
+		col      string
+		typ      *types.T
+		expected string
+		err      string
+	}{
+		{col: "ts96", typ: types.TimestampTZ, expected: `'2021-03-04 05:06:07.123456+00:00'`},
+		{col: "ts96", typ: types.Timestamp, expected: `'2021-03-04 05:06:07.123456'`},
+		{col: "ts96", typ: types.Date, expected: `'2021-03-04'`},
+		{col: "u32", typ: types.Int, expected: `4294967295`},
+		{col: "u32", typ: types.Decimal, expected: `4294967295`},
+		{col: "ts_millis", typ: types.TimestampTZ, expected: `'2021-03-04 05:06:07.123+00:00'`},
+		{col: "ts_nanos", typ: types.Timestamp, expected: `'2021-03-04 05:06:07.123456'`},
+		{col: "ts_nanos", typ: types.String, expected: `'2021-03-04 05:06:07.123456'`},
+		{col: "dec", typ: types.Decimal, expected: `-1.23`},
+		{col: "dec", typ: types.String, expected: `'-1.23'`},
+		{col: "raw", typ: types.Bytes, expected: `'\x3432'`},
+		{col: "raw", typ: types.Int, expected: `42`},
+		{col: "s", typ: types.Uuid, expected: `'6b7d2e2c-4d3d-4d8e-9d6f-2ba1bd1c1a51'`},
+		{col: "s", typ: types.Int, err: `decoding column "s": converting string to int`},
+		{col: "f", typ: types.Float, expected: `1.1`},
+		{col: "f", typ: types.Decimal, expected: `1.1`},
+		{col: "l", typ: types.IntArray, expected: `ARRAY[1,2]`},
+		{col: "l", typ: types.DecimalArray, expected: `ARRAY[1,2]`},
+		{col: "l", typ: types.Int, err: `cannot convert parquet LIST column "l" to INT8`},
+		{col: "nested", typ: types.Jsonb, err: `parquet group column "nested" is not supported`},
+		{col: "missing", typ: types.Int, err: `no column "missing" in parquet file`},
+	} {
+		t.Run(tc.col+"/"+tc.typ.String(), func(t *testing.T) {
+			dec, err := r.NewDecoder(tc.col, tc.typ)
+			var d tree.Datum
+			if err == nil {
+				d, err = dec.Decode(rows[0], evalCtx)
+			}
+			if tc.err != "" {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tc.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, tree.AsString(d))
+		})
+	}
+}
+
+// rewriteFooter returns a copy of the parquet file data whose metadata has
+// been modified by fn.
+func rewriteFooter(t *testing.T, data []byte, fn func(*parquet.FileMetaData)) []byte {
+	r, err := NewReader(data)
+	require.NoError(t, err)
+	fn(r.meta)
+	var buf bytes.Buffer
+	buf.Write(data[:r.footerStart])
+	proto := thrift.NewTCompactProtocol(&thrift.StreamTransport{Writer: &buf})
+	require.NoError(t, r.meta.Write(proto))
+	var footerLen [4]byte
+	binary.LittleEndian.PutUint32(footerLen[:], uint32(buf.Len()-int(r.footerStart)))
+	buf.Write(footerLen[:])
+	buf.Write(magic)
+	return buf.Bytes()
+}
+
+func TestDecodeDecimal(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	for _, in := range []string{`0`, `1`, `-1`, `127`, `128`, `-128`, `-129`, `1.5`, `-1.5`, `-12345678901234567890.12`} {
+		t.Run(in, func(t *testing.T) {
+			d, err := tree.ParseDDecimal(in)
+			require.NoError(t, err)
+			scale := -d.Exponent
+			b, err := encodeDecimal(&d.Decimal, 40, scale)
+			require.NoError(t, err)
+			got := decodeDecimal(b, scale)
+			require.Equal(t, 0, got.Cmp(&d.Decimal), "expected %s, got %s", d, &got)
+		})
+	}
+}
@@ -0,0 +1,377 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package parquet
+
+import (
+	"math/big"
+
+	"github.com/cockroachdb/apd/v2"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/errors"
+	"github.com/fraugster/parquet-go/parquet"
+	"github.com/fraugster/parquet-go/parquetschema"
+)
+
+// The names of the intermediate group and leaf of a LIST column, as described
+// by the parquet LogicalTypes documentation.
+const (
+	listGroupName   = "list"
+	listElementName = "element"
+)
+
+// encodeFn converts a non-NULL datum into the value expected by the parquet
+// library for the column it was created for.
+type encodeFn func(tree.Datum) (interface{}, error)
+
+// column is the parquet representation of a single SQL column.
+type column struct {
+	name       string
+	typ        *types.T
+	definition *parquetschema.ColumnDefinition
+	encode     encodeFn
+}
+
+// SchemaDefinition describes the parquet schema of a file written by Writer,
+// along with the conversion of each SQL column into its parquet
+// representation.
+type SchemaDefinition struct {
+	cols   []column
+	schema *parquetschema.SchemaDefinition
+}
+
+// NewSchema constructs a SchemaDefinition for the given columns. Every column
+// is optional so that NULLs can be represented. CockroachDB types map to
+// parquet types as follows:
+//
+//   BOOL                  -> boolean
+//   INT2, INT4            -> int32 (INT(16|32, true))
+//   INT8                  -> int64 (INT(64, true))
+//   FLOAT4                -> float
+//   FLOAT8                -> double
+//   DECIMAL(p, s)         -> binary (DECIMAL(p, s))
+//   DECIMAL               -> binary (STRING), since parquet requires a fixed scale
+//   STRING, collated      -> binary (STRING)
+//   BYTES                 -> binary
+//   UUID                  -> fixed_len_byte_array(16) (UUID)
+//   DATE                  -> int32 (DATE)
+//   TIME                  -> int64 (TIME(MICROS, false))
+//   TIMESTAMP             -> int64 (TIMESTAMP(MICROS, false))
+//   TIMESTAMPTZ           -> int64 (TIMESTAMP(MICROS, true))
+//   JSONB                 -> binary (JSON)
+//   ENUM                  -> binary (ENUM)
+//   ARRAY                 -> group (LIST) of the element type
+//
+// All other types are written as binary (STRING) using their text
+// representation.
+func NewSchema(columnNames []string, columnTypes []*types.T) (*SchemaDefinition, error) {
+	if len(columnNames) != len(columnTypes) {
+		return nil, errors.AssertionFailedf(
+			"expected %d column types, got %d", len(columnNames), len(columnTypes))
+	}
+	sch := &SchemaDefinition{cols: make([]column, len(columnNames))}
+	root := &parquetschema.ColumnDefinition{
+		SchemaElement: &parquet.SchemaElement{Name: "root"},
+	}
+	seen := make(map[string]struct{}, len(columnNames))
+	for i := range columnNames {
+		if _, ok := seen[columnNames[i]]; ok {
+			return nil, errors.Errorf("duplicate column name %q", columnNames[i])
+		}
+		seen[columnNames[i]] = struct{}{}
+		col, err := newColumn(columnNames[i], columnTypes[i])
+		if err != nil {
+			return nil, err
+		}
+		sch.cols[i] = col
+		root.Children = append(root.Children, col.definition)
+	}
+	sch.schema = parquetschema.SchemaDefinitionFromColumnDefinition(root)
+	if err := sch.schema.Validate(); err != nil {
+		return nil, errors.NewAssertionErrorWithWrappedErrf(err, "invalid parquet schema")
+	}
+	return sch, nil
+}
+
+// String returns the textual representation of the parquet schema.
+func (sch *SchemaDefinition) String() string {
+	return sch.schema.String()
+}
+
+func newColumn(name string, typ *types.T) (column, error) {
+	col := column{name: name, typ: typ}
+	if typ.Family() == types.ArrayFamily {
+		elem, err := newColumn(listElementName, typ.ArrayContents())
+		if err != nil {
+			return column{}, err
+		}
+		if elem.typ.Family() == types.ArrayFamily {
+			return column{}, errors.Errorf("nested arrays are not supported for column %q", name)
+		}
+		list := &parquetschema.ColumnDefinition{
+			SchemaElement: &parquet.SchemaElement{
+				Name:           listGroupName,
+				RepetitionType: parquet.FieldRepetitionTypePtr(parquet.FieldRepetitionType_REPEATED),
+			},
+			Children: []*parquetschema.ColumnDefinition{elem.definition},
+		}
+		listType := parquet.NewLogicalType()
+		listType.LIST = parquet.NewListType()
+		col.definition = &parquetschema.ColumnDefinition{
+			SchemaElement: &parquet.SchemaElement{
+				Name:           name,
+				RepetitionType: parquet.FieldRepetitionTypePtr(parquet.FieldRepetitionType_OPTIONAL),
+				LogicalType:    listType,
+				ConvertedType:  parquet.ConvertedTypePtr(parquet.ConvertedType_LIST),
+			},
+			Children: []*parquetschema.ColumnDefinition{list},
+		}
+		col.encode = func(d tree.Datum) (interface{}, error) {
+			arr := d.(*tree.DArray)
+			if len(arr.Array) == 0 {
+				// An empty list is a defined group without any repeated entries.
+				return map[string]interface{}{}, nil
+			}
+			elems := make([]map[string]interface{}, len(arr.Array))
+			for i, e := range arr.Array {
+				elems[i] = map[string]interface{}{}
+				if e == tree.DNull {
+					continue
+				}
+				v, err := elem.encode(tree.UnwrapDatum(nil, e))
+				if err != nil {
+					return nil, err
+				}
+				elems[i][listElementName] = v
+			}
+			return map[string]interface{}{listGroupName: elems}, nil
+		}
+		return col, nil
+	}
+
+	elem := &parquet.SchemaElement{
+		Name:           name,
+		RepetitionType: parquet.FieldRepetitionTypePtr(parquet.FieldRepetitionType_OPTIONAL),
+	}
+	col.definition = &parquetschema.ColumnDefinition{SchemaElement: elem}
+	logical := parquet.NewLogicalType()
+
+	switch typ.Family() {
+	case types.BoolFamily:
+		elem.Type = parquet.TypePtr(parquet.Type_BOOLEAN)
+		col.encode = func(d tree.Datum) (interface{}, error) {
+			return bool(*d.(*tree.DBool)), nil
+		}
+
+	case types.IntFamily:
+		logical.INTEGER = &parquet.IntType{BitWidth: int8(typ.Width()), IsSigned: true}
+		elem.LogicalType = logical
+		switch typ.Width() {
+		case 16:
+			elem.Type = parquet.TypePtr(parquet.Type_INT32)
+			elem.ConvertedType = parquet.ConvertedTypePtr(parquet.ConvertedType_INT_16)
+		case 32:
+			elem.Type = parquet.TypePtr(parquet.Type_INT32)
+			elem.ConvertedType = parquet.ConvertedTypePtr(parquet.ConvertedType_INT_32)
+		default:
+			logical.INTEGER.BitWidth = 64
+			elem.Type = parquet.TypePtr(parquet.Type_INT64)
+			elem.ConvertedType = parquet.ConvertedTypePtr(parquet.ConvertedType_INT_64)
+		}
+		if *elem.Type == parquet.Type_INT32 {
+			col.encode = func(d tree.Datum) (interface{}, error) {
+				return int32(*d.(*tree.DInt)), nil
+			}
+		} else {
+			col.encode = func(d tree.Datum) (interface{}, error) {
+				return int64(*d.(*tree.DInt)), nil
+			}
+		}
+
+	case types.FloatFamily:
+		if typ.Width() == 32 {
+			elem.Type = parquet.TypePtr(parquet.Type_FLOAT)
+			col.encode = func(d tree.Datum) (interface{}, error) {
+				return float32(*d.(*tree.DFloat)), nil
+			}
+		} else {
+			elem.Type = parquet.TypePtr(parquet.Type_DOUBLE)
+			col.encode = func(d tree.Datum) (interface{}, error) {
+				return float64(*d.(*tree.DFloat)), nil
+			}
+		}
+
+	case types.DecimalFamily:
+		elem.Type = parquet.TypePtr(parquet.Type_BYTE_ARRAY)
+		if typ.Precision() == 0 {
+			// Without a precision, the scale of each value is unbounded, which
+			// cannot be expressed by the parquet DECIMAL type.
+			setString(elem, logical)
+			col.encode = func(d tree.Datum) (interface{}, error) {
+				return []byte(d.(*tree.DDecimal).String()), nil
+			}
+			break
+		}
+		precision, scale := typ.Precision(), typ.Scale()
+		logical.DECIMAL = &parquet.DecimalType{Precision: precision, Scale: scale}
+		elem.LogicalType = logical
+		elem.ConvertedType = parquet.ConvertedTypePtr(parquet.ConvertedType_DECIMAL)
+		elem.Precision = &precision
+		elem.Scale = &scale
+		col.encode = func(d tree.Datum) (interface{}, error) {
+			return encodeDecimal(&d.(*tree.DDecimal).Decimal, precision, scale)
+		}
+
+	case types.StringFamily, types.CollatedStringFamily:
+		elem.Type = parquet.TypePtr(parquet.Type_BYTE_ARRAY)
+		setString(elem, logical)
+		col.encode = func(d tree.Datum) (interface{}, error) {
+			switch t := d.(type) {
+			case *tree.DString:
+				return []byte(*t), nil
+			case *tree.DCollatedString:
+				return []byte(t.Contents), nil
+			}
+			return nil, errors.AssertionFailedf("unexpected string datum %T", d)
+		}
+
+	case types.BytesFamily:
+		elem.Type = parquet.TypePtr(parquet.Type_BYTE_ARRAY)
+		col.encode = func(d tree.Datum) (interface{}, error) {
+			return []byte(*d.(*tree.DBytes)), nil
+		}
+
+	case types.UuidFamily:
+		elem.Type = parquet.TypePtr(parquet.Type_FIXED_LEN_BYTE_ARRAY)
+		length := int32(16)
+		elem.TypeLength = &length
+		logical.UUID = parquet.NewUUIDType()
+		elem.LogicalType = logical
+		col.encode = func(d tree.Datum) (interface{}, error) {
+			return d.(*tree.DUuid).GetBytes(), nil
+		}
+
+	case types.DateFamily:
+		elem.Type = parquet.TypePtr(parquet.Type_INT32)
+		logical.DATE = parquet.NewDateType()
+		elem.LogicalType = logical
+		elem.ConvertedType = parquet.ConvertedTypePtr(parquet.ConvertedType_DATE)
+		col.encode = func(d tree.Datum) (interface{}, error) {
+			date := d.(*tree.DDate).Date
+			if !date.IsFinite() {
+				return nil, errors.Errorf("cannot represent infinite date %s in parquet", date)
+			}
+			return int32(date.UnixEpochDays()), nil
+		}
+
+	case types.TimeFamily:
+		elem.Type = parquet.TypePtr(parquet.Type_INT64)
+		logical.TIME = &parquet.TimeType{IsAdjustedToUTC: false, Unit: microsTimeUnit()}
+		elem.LogicalType = logical
+		elem.ConvertedType = parquet.ConvertedTypePtr(parquet.ConvertedType_TIME_MICROS)
+		col.encode = func(d tree.Datum) (interface{}, error) {
+			return int64(*d.(*tree.DTime)), nil
+		}
+
+	case types.TimestampFamily, types.TimestampTZFamily:
+		adjusted := typ.Family() == types.TimestampTZFamily
+		elem.Type = parquet.TypePtr(parquet.Type_INT64)
+		logical.TIMESTAMP = &parquet.TimestampType{IsAdjustedToUTC: adjusted, Unit: microsTimeUnit()}
+		elem.LogicalType = logical
+		if adjusted {
+			elem.ConvertedType = parquet.ConvertedTypePtr(parquet.ConvertedType_TIMESTAMP_MICROS)
+		}
+		col.encode = func(d tree.Datum) (interface{}, error) {
+			switch t := d.(type) {
+			case *tree.DTimestamp:
+				return timeutil.ToUnixMicros(t.Time), nil
+			case *tree.DTimestampTZ:
+				return timeutil.ToUnixMicros(t.Time), nil
+			}
+			return nil, errors.AssertionFailedf("unexpected timestamp datum %T", d)
+		}
+
+	case types.JsonFamily:
+		elem.Type = parquet.TypePtr(parquet.Type_BYTE_ARRAY)
+		logical.JSON = parquet.NewJsonType()
+		elem.LogicalType = logical
+		elem.ConvertedType = parquet.ConvertedTypePtr(parquet.ConvertedType_JSON)
+		col.encode = func(d tree.Datum) (interface{}, error) {
+			return []byte(d.(*tree.DJSON).JSON.String()), nil
+		}
+
+	case types.EnumFamily:
+		elem.Type = parquet.TypePtr(parquet.Type_BYTE_ARRAY)
+		logical.ENUM = parquet.NewEnumType()
+		elem.LogicalType = logical
+		elem.ConvertedType = parquet.ConvertedTypePtr(parquet.ConvertedType_ENUM)
+		col.encode = func(d tree.Datum) (interface{}, error) {
+			return []byte(d.(*tree.DEnum).LogicalRep), nil
+		}
+
+	default:
+		elem.Type = parquet.TypePtr(parquet.Type_BYTE_ARRAY)
+		setString(elem, logical)
+		col.encode = func(d tree.Datum) (interface{}, error) {
+			return []byte(tree.AsStringWithFlags(d, tree.FmtExport)), nil
+		}
+	}
+	return col, nil
+}
+
+func setString(elem *parquet.SchemaElement, logical *parquet.LogicalType) {
+	logical.STRING = parquet.NewStringType()
+	elem.LogicalType = logical
+	elem.ConvertedType = parquet.ConvertedTypePtr(parquet.ConvertedType_UTF8)
+}
+
+func microsTimeUnit() *parquet.TimeUnit {
+	unit := parquet.NewTimeUnit()
+	unit.MICROS = parquet.NewMicroSeconds()
+	return unit
+}
+
+// encodeDecimal returns the unscaled value of d at the given scale as a
+// big-endian two's complement integer, which is the representation of the
+// parquet DECIMAL type.
+func encodeDecimal(d *apd.Decimal, precision, scale int32) ([]byte, error) {
+	if d.Form != apd.Finite {
+		return nil, errors.Errorf("cannot represent decimal %s in parquet", d)
+	}
+	var scaled apd.Decimal
+	c := apd.BaseContext.WithPrecision(uint32(precision))
+	if _, err := c.Quantize(&scaled, d, -scale); err != nil {
+		return nil, errors.Wrapf(err, "cannot represent decimal %s as DECIMAL(%d, %d)", d, precision, scale)
+	}
+	unscaled := &scaled.Coeff
+	if !scaled.Negative || unscaled.Sign() == 0 {
+		b := unscaled.Bytes()
+		if len(b) == 0 || b[0]&0x80 != 0 {
+			b = append([]byte{0}, b...)
+		}
+		return b, nil
+	}
+	// The two's complement of -x in n bytes is 2^(8n) - x. One byte more than
+	// the magnitude is always enough to hold the sign bit.
+	n := len(unscaled.Bytes()) + 1
+	twos := new(big.Int).Lsh(big.NewInt(1), uint(8*n))
+	twos.Sub(twos, unscaled)
+	b := twos.Bytes()
+	for len(b) < n {
+		b = append([]byte{0}, b...)
+	}
+	// Trim redundant sign-extension bytes.
+	for len(b) > 1 && b[0] == 0xff && b[1]&0x80 != 0 {
+		b = b[1:]
+	}
+	return b, nil
+}
@@ -0,0 +1,168 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+// Package parquet writes rows of SQL datums to files in the Apache Parquet
+// columnar format.
+package parquet
+
+import (
+	"io"
+	"strings"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/errors"
+	goparquet "github.com/fraugster/parquet-go"
+	"github.com/fraugster/parquet-go/parquet"
+)
+
+// CompressionCodec is the codec used to compress the pages of a parquet file.
+type CompressionCodec int
+
+const (
+	// CompressionNone writes uncompressed pages.
+	CompressionNone CompressionCodec = iota
+	// CompressionGzip compresses pages with gzip.
+	CompressionGzip
+	// CompressionSnappy compresses pages with snappy.
+	CompressionSnappy
+)
+
+// CompressionCodecFromString parses the name of a compression codec, as it
+// would be given in a WITH compression = '...' option.
+func CompressionCodecFromString(s string) (CompressionCodec, error) {
+	switch strings.ToLower(s) {
+	case "", "none":
+		return CompressionNone, nil
+	case "gzip":
+		return CompressionGzip, nil
+	case "snappy":
+		return CompressionSnappy, nil
+	default:
+		return 0, errors.Errorf("unsupported compression codec %q", s)
+	}
+}
+
+func (c CompressionCodec) toParquet() parquet.CompressionCodec {
+	switch c {
+	case CompressionGzip:
+		return parquet.CompressionCodec_GZIP
+	case CompressionSnappy:
+		return parquet.CompressionCodec_SNAPPY
+	default:
+		return parquet.CompressionCodec_UNCOMPRESSED
+	}
+}
+
+// WriterOption configures a Writer.
+type WriterOption func(*writerConfig)
+
+type writerConfig struct {
+	maxRowGroupSize int64
+	compression     CompressionCodec
+	metadata        map[string]string
+}
+
+// WithMaxRowGroupSize sets the approximate size, in uncompressed bytes, at
+// which the buffered rows are flushed to the output as a row group. A value of
+// 0 buffers every row in a single row group until the Writer is closed.
+func WithMaxRowGroupSize(size int64) WriterOption {
+	return func(c *writerConfig) {
+		c.maxRowGroupSize = size
+	}
+}
+
+// WithCompression sets the codec used to compress the pages of the file.
+func WithCompression(codec CompressionCodec) WriterOption {
+	return func(c *writerConfig) {
+		c.compression = codec
+	}
+}
+
+// WithMetadata adds key-value metadata to the file footer.
+func WithMetadata(kv map[string]string) WriterOption {
+	return func(c *writerConfig) {
+		c.metadata = kv
+	}
+}
+
+// Writer writes rows of datums to a parquet file. Rows are buffered in memory
+// and written to the underlying io.Writer a row group at a time; the file is
+// only valid once Close has been called.
+type Writer struct {
+	sch  *SchemaDefinition
+	w    *goparquet.FileWriter
+	rows int64
+}
+
+// NewWriter returns a Writer which writes rows with the given schema to sink.
+func NewWriter(sch *SchemaDefinition, sink io.Writer, opts ...WriterOption) (*Writer, error) {
+	var cfg writerConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	fileOpts := []goparquet.FileWriterOption{
+		goparquet.WithCreator("CockroachDB"),
+		goparquet.WithCompressionCodec(cfg.compression.toParquet()),
+		goparquet.WithMaxRowGroupSize(cfg.maxRowGroupSize),
+		goparquet.WithMetaData(cfg.metadata),
+	}
+	w := goparquet.NewFileWriter(sink, fileOpts...)
+	if err := w.SetSchemaDefinition(sch.schema); err != nil {
+		return nil, errors.NewAssertionErrorWithWrappedErrf(err, "invalid parquet schema")
+	}
+	return &Writer{sch: sch, w: w}, nil
+}
+
+// AddRow buffers a row, whose datums must match the columns of the schema.
+func (w *Writer) AddRow(datums tree.Datums) error {
+	if len(datums) != len(w.sch.cols) {
+		return errors.AssertionFailedf(
+			"expected %d datums, got %d", len(w.sch.cols), len(datums))
+	}
+	row := make(map[string]interface{}, len(datums))
+	for i, d := range datums {
+		if d == tree.DNull {
+			continue
+		}
+		col := &w.sch.cols[i]
+		v, err := col.encode(tree.UnwrapDatum(nil, d))
+		if err != nil {
+			return errors.Wrapf(err, "encoding column %q", col.name)
+		}
+		row[col.name] = v
+	}
+	if err := w.w.AddData(row); err != nil {
+		return errors.Wrap(err, "writing parquet row")
+	}
+	w.rows++
+	return nil
+}
+
+// NumRows returns the number of rows added to the Writer.
+func (w *Writer) NumRows() int64 {
+	return w.rows
+}
+
+// BufferedSize returns an estimate of the size of the file if it were closed
+// now: the bytes already written for flushed row groups plus the
+// uncompressed size of the row group that is still buffered.
+func (w *Writer) BufferedSize() int64 {
+	return w.w.CurrentFileSize() + w.w.CurrentRowGroupSize()
+}
+
+// Close flushes any buffered rows and writes the file footer. The Writer may
+// not be used afterwards. At least one row must have been added, since parquet
+// files cannot be empty.
+func (w *Writer) Close() error {
+	if w.rows == 0 {
+		return errors.AssertionFailedf("cannot close a parquet writer with no rows")
+	}
+	return w.w.Close()
+}
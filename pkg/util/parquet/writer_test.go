@@ -0,0 +1,196 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package parquet
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/cockroachdb/apd/v2"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	goparquet "github.com/fraugster/parquet-go"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriterRoundTrip(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	names := []string{
+		"b", "i2", "i8", "f4", "f8", "dec", "dec_any", "s", "bytes", "u",
+		"d", "t", "ts", "tstz", "j", "arr", "ival",
+	}
+	typs := []*types.T{
+		types.Bool, types.Int2, types.Int, types.Float4, types.Float,
+		types.MakeDecimal(10, 2), types.Decimal, types.String, types.Bytes, types.Uuid,
+		types.Date, types.Time, types.Timestamp, types.TimestampTZ, types.Jsonb,
+		types.IntArray, types.Interval,
+	}
+	sch, err := NewSchema(names, typs)
+	require.NoError(t, err)
+
+	evalCtx := tree.NewTestingEvalContext(nil)
+	parse := func(typ *types.T, s string) tree.Datum {
+		d, _, err := tree.ParseAndRequireString(typ, s, evalCtx)
+		require.NoError(t, err)
+		return d
+	}
+	arr := tree.NewDArray(types.Int)
+	require.NoError(t, arr.Append(tree.NewDInt(1)))
+	require.NoError(t, arr.Append(tree.NewDInt(2)))
+	require.NoError(t, arr.Append(tree.NewDInt(3)))
+
+	ts := parse(types.TimestampTZ, `2021-01-02 03:04:05.678901+00`)
+	row := tree.Datums{
+		tree.DBoolTrue,
+		tree.NewDInt(7),
+		tree.NewDInt(1 << 40),
+		tree.NewDFloat(1.5),
+		tree.NewDFloat(2.25),
+		parse(types.MakeDecimal(10, 2), `-12.5`),
+		parse(types.Decimal, `1.000001`),
+		tree.NewDString(`hello`),
+		tree.NewDBytes("\x00\x01"),
+		parse(types.Uuid, `6b7d2e2c-4d3d-4d8e-9d6f-2ba1bd1c1a51`),
+		parse(types.Date, `1970-01-11`),
+		parse(types.Time, `00:00:01.5`),
+		parse(types.Timestamp, `2021-01-02 03:04:05.678901`),
+		ts,
+		parse(types.Jsonb, `{"a": [1, 2]}`),
+		arr,
+		parse(types.Interval, `1 day`),
+	}
+	nullRow := make(tree.Datums, len(row))
+	for i := range nullRow {
+		nullRow[i] = tree.DNull
+	}
+
+	var buf bytes.Buffer
+	w, err := NewWriter(sch, &buf, WithCompression(CompressionSnappy))
+	require.NoError(t, err)
+	require.NoError(t, w.AddRow(row))
+	require.NoError(t, w.AddRow(nullRow))
+	require.Equal(t, int64(2), w.NumRows())
+	require.NoError(t, w.Close())
+
+	r, err := goparquet.NewFileReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	require.Equal(t, int64(2), r.NumRows())
+
+	got, err := r.NextRow()
+	require.NoError(t, err)
+	require.Equal(t, true, got["b"])
+	require.Equal(t, int32(7), got["i2"])
+	require.Equal(t, int64(1<<40), got["i8"])
+	require.Equal(t, float32(1.5), got["f4"])
+	require.Equal(t, 2.25, got["f8"])
+	// -12.50 is stored as the unscaled value -1250 in two's complement.
+	require.Equal(t, []byte{0xfb, 0x1e}, got["dec"])
+	require.Equal(t, []byte(`1.000001`), got["dec_any"])
+	require.Equal(t, []byte(`hello`), got["s"])
+	require.Equal(t, []byte{0, 1}, got["bytes"])
+	require.Equal(t, row[9].(*tree.DUuid).GetBytes(), got["u"])
+	require.Equal(t, int32(10), got["d"])
+	require.Equal(t, int64(1500000), got["t"])
+	require.Equal(t, timeutil.ToUnixMicros(ts.(*tree.DTimestampTZ).Time), got["ts"])
+	require.Equal(t, got["ts"], got["tstz"])
+	require.Equal(t, []byte(`{"a": [1, 2]}`), got["j"])
+	require.Equal(t, map[string]interface{}{
+		"list": []map[string]interface{}{
+			{"element": int64(1)},
+			{"element": int64(2)},
+			{"element": int64(3)},
+		},
+	}, got["arr"])
+	require.Equal(t, []byte(`1 day`), got["ival"])
+
+	got, err = r.NextRow()
+	require.NoError(t, err)
+	require.Empty(t, got)
+
+	_, err = r.NextRow()
+	require.Equal(t, io.EOF, err)
+}
+
+func TestWriterRowGroups(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	sch, err := NewSchema([]string{"s"}, []*types.T{types.String})
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	w, err := NewWriter(sch, &buf, WithMaxRowGroupSize(64))
+	require.NoError(t, err)
+	for i := 0; i < 100; i++ {
+		require.NoError(t, w.AddRow(tree.Datums{tree.NewDString(`0123456789`)}))
+		require.LessOrEqual(t, w.w.CurrentRowGroupSize(), int64(64))
+	}
+	require.Greater(t, w.BufferedSize(), int64(100*10))
+	require.NoError(t, w.Close())
+
+	r, err := goparquet.NewFileReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	require.Equal(t, int64(100), r.NumRows())
+	require.Greater(t, r.RowGroupCount(), 1)
+}
+
+func TestSchemaErrors(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	_, err := NewSchema([]string{"a", "a"}, []*types.T{types.Int, types.Int})
+	require.EqualError(t, err, `duplicate column name "a"`)
+
+	var buf bytes.Buffer
+	sch, err := NewSchema([]string{"a"}, []*types.T{types.Int})
+	require.NoError(t, err)
+	w, err := NewWriter(sch, &buf)
+	require.NoError(t, err)
+	require.Error(t, w.Close())
+
+	dec := types.MakeDecimal(3, 1)
+	sch, err = NewSchema([]string{"a"}, []*types.T{dec})
+	require.NoError(t, err)
+	w, err = NewWriter(sch, &buf)
+	require.NoError(t, err)
+	nan := &tree.DDecimal{Decimal: apd.Decimal{Form: apd.NaN}}
+	require.Regexp(t, `cannot represent decimal NaN`, w.AddRow(tree.Datums{nan}))
+}
+
+func TestEncodeDecimal(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	for _, tc := range []struct {
+		in       string
+		scale    int32
+		expected []byte
+	}{
+		{`0`, 0, []byte{0}},
+		{`1`, 0, []byte{1}},
+		{`-1`, 0, []byte{0xff}},
+		{`127`, 0, []byte{0x7f}},
+		{`128`, 0, []byte{0, 0x80}},
+		{`-128`, 0, []byte{0x80}},
+		{`-129`, 0, []byte{0xff, 0x7f}},
+		{`1.5`, 2, []byte{0, 0x96}},
+		{`-1.5`, 2, []byte{0xff, 0x6a}},
+	} {
+		t.Run(tc.in, func(t *testing.T) {
+			d, _, err := apd.NewFromString(tc.in)
+			require.NoError(t, err)
+			b, err := encodeDecimal(d, 10, tc.scale)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, b)
+		})
+	}
+}